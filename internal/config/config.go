@@ -1,16 +1,32 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
+	"github.com/gin-gonic/gin"
 	"github.com/pelletier/go-toml/v2"
 )
 
 type Config struct {
 	Server struct {
 		Port string `toml:"port"`
+		Mode string `toml:"mode"` // Gin 运行模式：release / debug / test，默认 release
+		// 受信任的反向代理 IP/CIDR，仅这些来源的 X-Forwarded-For 等请求头会被用于获取客户端 IP，为空时不信任任何代理
+		TrustedProxies []string `toml:"trusted_proxies"`
+		// 受信任的平台：cloudflare（使用 CF-Connecting-IP）/ google（使用 X-Appengine-Remote-Addr），为空表示不使用
+		TrustedPlatform string `toml:"trusted_platform"`
+		// 实例标识，多个面板进程共享同一个 SQLite 数据库文件时用于区分任务与作业租约的持有者，为空时使用主机名
+		NodeID string `toml:"node_id"`
+		// 面板的公网访问地址，如 https://panel.example.com，用于生成 OCI 事件推送地址
+		PublicURL string `toml:"public_url"`
 	} `toml:"server"`
+	CORS struct {
+		// 允许跨域访问的来源，如 "https://panel.example.com"，为空时允许所有来源（不携带凭据）
+		AllowOrigins []string `toml:"allow_origins"`
+	} `toml:"cors"`
 	Web struct {
 		Account  string `toml:"account"`
 		Password string `toml:"password"`
@@ -19,7 +35,9 @@ type Config struct {
 		DSN string `toml:"dsn"`
 	} `toml:"database"`
 	Logging struct {
-		Level string `toml:"level"`
+		Level     string `toml:"level"`
+		AccessLog *bool  `toml:"access_log"` // 是否输出访问日志，默认开启
+		Format    string `toml:"format"`     // 访问日志格式：common / json，默认 common
 	} `toml:"logging"`
 	Passkey struct {
 		RPID      string   `toml:"rp_id"`
@@ -40,3 +58,36 @@ func Load() *Config {
 
 	return &cfg
 }
+
+// GinMode 返回 Gin 运行模式，未配置或无效时使用 release
+func (c *Config) GinMode() string {
+	switch c.Server.Mode {
+	case gin.DebugMode, gin.TestMode:
+		return c.Server.Mode
+	default:
+		return gin.ReleaseMode
+	}
+}
+
+// ApplyProxySettings 按配置设置受信任的代理与平台，使 ClientIP 在反向代理后仍能取得真实客户端 IP
+func (c *Config) ApplyProxySettings(r *gin.Engine) error {
+	switch strings.ToLower(c.Server.TrustedPlatform) {
+	case "":
+	case "cloudflare":
+		r.TrustedPlatform = gin.PlatformCloudflare
+	case "google":
+		r.TrustedPlatform = gin.PlatformGoogleAppEngine
+	default:
+		return fmt.Errorf("unknown trusted platform %q", c.Server.TrustedPlatform)
+	}
+
+	if len(c.Server.TrustedProxies) == 0 {
+		return r.SetTrustedProxies(nil)
+	}
+	return r.SetTrustedProxies(c.Server.TrustedProxies)
+}
+
+// AccessLogEnabled 是否输出访问日志
+func (c *Config) AccessLogEnabled() bool {
+	return c.Logging.AccessLog == nil || *c.Logging.AccessLog
+}
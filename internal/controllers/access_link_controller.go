@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AccessLinkController struct {
+	instanceService *services.InstanceService
+}
+
+func NewAccessLinkController(instanceService *services.InstanceService) *AccessLinkController {
+	return &AccessLinkController{instanceService: instanceService}
+}
+
+type CreateAccessLinkRequest struct {
+	UserId     string   `json:"userId" binding:"required"`
+	InstanceId string   `json:"instanceId" binding:"required"`
+	Name       string   `json:"name"`
+	Actions    []string `json:"actions" binding:"required,min=1"` // start、stop、reboot
+	Hours      int      `json:"hours" binding:"required,min=1"`   // 有效期小时数
+}
+
+type CreateAccessLinkResponse struct {
+	Link  *models.AccessLink `json:"link"`
+	Token string             `json:"token"` // 仅在创建时返回，请妥善保存
+	Path  string             `json:"path"`
+}
+
+// CreateAccessLink 创建限时、限定操作的实例访问链接
+func (ac *AccessLinkController) CreateAccessLink(c *gin.Context) {
+	var req CreateAccessLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	link, token, err := ac.instanceService.CreateAccessLink(req.UserId, req.InstanceId, req.Name, req.Actions, time.Duration(req.Hours)*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(CreateAccessLinkResponse{
+		Link:  link,
+		Token: token,
+		Path:  "/operator?token=" + token,
+	}, "访问链接已创建"))
+}
+
+type ListAccessLinksRequest struct {
+	ConfigID string `json:"configId"` // 为空时返回所有配置
+}
+
+// ListAccessLinks 获取访问链接列表
+func (ac *AccessLinkController) ListAccessLinks(c *gin.Context) {
+	var req ListAccessLinksRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	links, err := services.ListAccessLinks(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取访问链接失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(links, "success"))
+}
+
+type RevokeAccessLinkRequest struct {
+	ID string `json:"id" binding:"required"`
+}
+
+// RevokeAccessLink 撤销访问链接
+func (ac *AccessLinkController) RevokeAccessLink(c *gin.Context) {
+	var req RevokeAccessLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.RevokeAccessLink(req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "访问链接已撤销"))
+}
+
+type OperatorRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// OperatorInfo 访问链接持有者查看实例状态，无需登录
+func (ac *AccessLinkController) OperatorInfo(c *gin.Context) {
+	var req OperatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	view, err := ac.instanceService.GetAccessLinkView(req.Token)
+	if err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse(403, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(view, "success"))
+}
+
+type OperatorActionRequest struct {
+	Token  string `json:"token" binding:"required"`
+	Action string `json:"action" binding:"required"`
+}
+
+// OperatorAction 访问链接持有者执行授权的实例操作，无需登录
+func (ac *AccessLinkController) OperatorAction(c *gin.Context) {
+	var req OperatorActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := ac.instanceService.ExecuteAccessLinkAction(req.Token, req.Action); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "操作已提交"))
+}
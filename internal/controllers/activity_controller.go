@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ActivityController struct{}
+
+func NewActivityController() *ActivityController {
+	return &ActivityController{}
+}
+
+type ActivityRequest struct {
+	Page     int      `json:"page"`
+	PageSize int      `json:"pageSize"`
+	Types    []string `json:"types"` // audit / task / job / security / event，为空时返回全部
+}
+
+// List 分页获取最近动态，合并审计日志、任务结果、作业运行与告警
+func (ac *ActivityController) List(c *gin.Context) {
+	var req ActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	for _, t := range req.Types {
+		if !services.IsActivityType(t) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "不支持的动态类型: "+t))
+			return
+		}
+	}
+
+	feed, err := services.GetActivityFeed(req.Page, req.PageSize, req.Types)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(feed, "success"))
+}
@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type BackupController struct {
+	backupService *services.BackupService
+}
+
+func NewBackupController(backupService *services.BackupService) *BackupController {
+	return &BackupController{backupService: backupService}
+}
+
+type ListBootVolumeBackupsRequest struct {
+	UserId        string `json:"userId" binding:"required"`
+	CompartmentId string `json:"compartmentId"` // 为空时使用租户根区间
+	BootVolumeId  string `json:"bootVolumeId"`  // 为空时列出全部引导卷的备份
+	Region        string `json:"region"`
+}
+
+// ListBootVolumeBackups 列出引导卷备份
+func (bc *BackupController) ListBootVolumeBackups(c *gin.Context) {
+	var req ListBootVolumeBackupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if !services.IsValidCompartmentID(req.CompartmentId) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "区间 OCID 格式无效"))
+		return
+	}
+
+	backups, err := bc.backupService.ListBootVolumeBackups(req.UserId, req.CompartmentId, req.BootVolumeId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(backups, "获取成功"))
+}
+
+type CreateBootVolumeBackupRequest struct {
+	UserId       string `json:"userId" binding:"required"`
+	InstanceId   string `json:"instanceId"`   // 与 bootVolumeId 二选一
+	BootVolumeId string `json:"bootVolumeId"` // 与 instanceId 二选一
+	DisplayName  string `json:"displayName"`  // 为空时使用引导卷名加时间
+	Incremental  bool   `json:"incremental"`
+	Keep         bool   `json:"keep"` // 不被备份保留策略删除
+	Region       string `json:"region"`
+}
+
+// CreateBootVolumeBackup 创建引导卷备份
+func (bc *BackupController) CreateBootVolumeBackup(c *gin.Context) {
+	var req CreateBootVolumeBackupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	backup, err := bc.backupService.CreateBootVolumeBackup(req.UserId, req.Region, services.BootVolumeBackupParams{
+		InstanceID:   req.InstanceId,
+		BootVolumeID: req.BootVolumeId,
+		DisplayName:  req.DisplayName,
+		Incremental:  req.Incremental,
+		Keep:         req.Keep,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(backup, "备份创建中"))
+}
+
+type BootVolumeBackupRequest struct {
+	UserId   string `json:"userId" binding:"required"`
+	BackupId string `json:"backupId" binding:"required"`
+	Region   string `json:"region"`
+}
+
+// DeleteBootVolumeBackup 删除引导卷备份
+func (bc *BackupController) DeleteBootVolumeBackup(c *gin.Context) {
+	var req BootVolumeBackupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := bc.backupService.DeleteBootVolumeBackup(req.UserId, req.BackupId, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "删除成功"))
+}
+
+type RestoreBootVolumeBackupRequest struct {
+	UserId             string  `json:"userId" binding:"required"`
+	BackupId           string  `json:"backupId" binding:"required"`
+	Mode               string  `json:"mode" binding:"required,oneof=volume launch attach"`
+	AvailabilityDomain string  `json:"availabilityDomain"` // volume、launch 必填，可写 AD-1 等后缀
+	DisplayName        string  `json:"displayName"`
+	SizeInGBs          int64   `json:"sizeInGBs" binding:"omitempty,gte=50"`
+	VpusPerGB          int64   `json:"vpusPerGB" binding:"omitempty,gte=10,lte=120"`
+	InstanceId         string  `json:"instanceId"` // attach 必填
+	Shape              string  `json:"shape"`      // launch 必填
+	Ocpus              float32 `json:"ocpus"`
+	MemoryInGBs        float32 `json:"memoryInGBs"`
+	SubnetId           string  `json:"subnetId"` // launch 必填
+	AssignPublicIp     bool    `json:"assignPublicIp"`
+	Region             string  `json:"region"`
+}
+
+// RestoreBootVolumeBackup 从备份恢复引导卷，可选创建新实例或替换已有实例的引导卷
+func (bc *BackupController) RestoreBootVolumeBackup(c *gin.Context) {
+	var req RestoreBootVolumeBackupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	jobID, err := bc.backupService.StartRestore(req.UserId, req.Region, services.BackupRestoreParams{
+		BackupID:           req.BackupId,
+		Mode:               req.Mode,
+		AvailabilityDomain: req.AvailabilityDomain,
+		DisplayName:        req.DisplayName,
+		SizeInGBs:          req.SizeInGBs,
+		VpusPerGB:          req.VpusPerGB,
+		InstanceID:         req.InstanceId,
+		Shape:              req.Shape,
+		Ocpus:              req.Ocpus,
+		MemoryInGBs:        req.MemoryInGBs,
+		SubnetID:           req.SubnetId,
+		AssignPublicIP:     req.AssignPublicIp,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"jobId": jobID}, "恢复任务已启动，请等待完成"))
+}
+
+type RestoreStatusRequest struct {
+	JobId string `json:"jobId" binding:"required"`
+}
+
+// RestoreStatus 查询恢复任务进度
+func (bc *BackupController) RestoreStatus(c *gin.Context) {
+	var req RestoreStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, ok := bc.backupService.GetRestoreJob(req.JobId)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "恢复任务不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "获取成功"))
+}
+
+type ListBackupPoliciesRequest struct {
+	UserId        string `json:"userId" binding:"required"`
+	CompartmentId string `json:"compartmentId"` // 自定义策略所在区间，为空时使用租户根区间
+	Region        string `json:"region"`
+}
+
+// ListBackupPolicies 列出预定义与自定义的卷备份策略
+func (bc *BackupController) ListBackupPolicies(c *gin.Context) {
+	var req ListBackupPoliciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if !services.IsValidCompartmentID(req.CompartmentId) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "区间 OCID 格式无效"))
+		return
+	}
+
+	policies, err := bc.backupService.ListBackupPolicies(req.UserId, req.CompartmentId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(policies, "获取成功"))
+}
+
+type BackupPolicyAssetRequest struct {
+	UserId  string `json:"userId" binding:"required"`
+	AssetId string `json:"assetId" binding:"required"` // 引导卷或块存储卷 OCID
+	Region  string `json:"region"`
+}
+
+// GetBackupPolicy 获取卷当前分配的备份策略，未分配时返回 null
+func (bc *BackupController) GetBackupPolicy(c *gin.Context) {
+	var req BackupPolicyAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	assignment, err := bc.backupService.GetBackupPolicyAssignment(req.UserId, req.AssetId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(assignment, "获取成功"))
+}
+
+type AssignBackupPolicyRequest struct {
+	UserId  string `json:"userId" binding:"required"`
+	AssetId string `json:"assetId" binding:"required"` // 引导卷或块存储卷 OCID
+	Policy  string `json:"policy" binding:"required"`  // 策略 OCID，或 bronze、silver、gold
+	Region  string `json:"region"`
+}
+
+// AssignBackupPolicy 为卷分配备份策略，替换已分配的策略
+func (bc *BackupController) AssignBackupPolicy(c *gin.Context) {
+	var req AssignBackupPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if !services.IsBackupPolicyAsset(req.AssetId) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "只能为引导卷或块存储卷分配备份策略"))
+		return
+	}
+
+	assignment, err := bc.backupService.AssignBackupPolicy(req.UserId, req.AssetId, req.Policy, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(assignment, "分配成功"))
+}
+
+// RemoveBackupPolicy 取消卷的备份策略
+func (bc *BackupController) RemoveBackupPolicy(c *gin.Context) {
+	var req BackupPolicyAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := bc.backupService.RemoveBackupPolicy(req.UserId, req.AssetId, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "已取消备份策略"))
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type BackupRetentionController struct {
+	backupRetentionService *services.BackupRetentionService
+}
+
+func NewBackupRetentionController(backupRetentionService *services.BackupRetentionService) *BackupRetentionController {
+	return &BackupRetentionController{backupRetentionService: backupRetentionService}
+}
+
+type BackupRetentionConfigRequest struct {
+	ConfigID string `json:"configId" binding:"required"`
+}
+
+// GetPolicy 获取配置的备份保留策略，未设置时返回空
+func (bc *BackupRetentionController) GetPolicy(c *gin.Context) {
+	var req BackupRetentionConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	policy, err := services.GetBackupRetentionPolicy(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取策略失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(policy, "success"))
+}
+
+type BackupRetentionRuleRequest struct {
+	ConfigID            string `json:"configId" binding:"required"`
+	KeepCount           int    `json:"keepCount"`  // 每个卷保留的最近备份数，0 表示不限制
+	MaxAgeDays          int    `json:"maxAgeDays"` // 超过该天数的备份被删除，0 表示不限制
+	IncludeBlockVolumes bool   `json:"includeBlockVolumes"`
+}
+
+type SaveBackupRetentionRequest struct {
+	BackupRetentionRuleRequest
+	Enabled bool `json:"enabled"`
+}
+
+// SavePolicy 创建或更新配置的备份保留策略
+func (bc *BackupRetentionController) SavePolicy(c *gin.Context) {
+	var req SaveBackupRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	policy, err := services.SaveBackupRetentionPolicy(req.ConfigID, req.KeepCount, req.MaxAgeDays, req.IncludeBlockVolumes, req.Enabled)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(policy, "策略已保存"))
+}
+
+// DeletePolicy 删除配置的备份保留策略
+func (bc *BackupRetentionController) DeletePolicy(c *gin.Context) {
+	var req BackupRetentionConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.DeleteBackupRetentionPolicy(req.ConfigID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "删除失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "策略已删除"))
+}
+
+// Preview 按请求中的规则预览将被删除的备份，不执行删除
+func (bc *BackupRetentionController) Preview(c *gin.Context) {
+	var req BackupRetentionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	result, err := bc.backupRetentionService.PreviewCleanup(req.ConfigID, req.KeepCount, req.MaxAgeDays, req.IncludeBlockVolumes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, "success"))
+}
+
+// Run 立即执行配置已保存的备份保留策略
+func (bc *BackupRetentionController) Run(c *gin.Context) {
+	var req BackupRetentionConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	result, err := bc.backupRetentionService.RunPolicy(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, "success"))
+}
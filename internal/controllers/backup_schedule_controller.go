@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type BackupScheduleController struct {
+	backupScheduleService *services.BackupScheduleService
+}
+
+func NewBackupScheduleController(backupScheduleService *services.BackupScheduleService) *BackupScheduleController {
+	return &BackupScheduleController{backupScheduleService: backupScheduleService}
+}
+
+type CreateBackupScheduleRequest struct {
+	ConfigID       string `json:"configId" binding:"required"`
+	InstanceID     string `json:"instanceId" binding:"required"`
+	Cron           string `json:"cron" binding:"required"` // 如 "0 3 * * 0" 表示每周日 03:00
+	RetentionCount int    `json:"retentionCount"`          // 为 0 时保留 2 份
+}
+
+// CreateBackupSchedule 创建实例引导卷定时备份计划
+func (bc *BackupScheduleController) CreateBackupSchedule(c *gin.Context) {
+	var req CreateBackupScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	schedule, err := bc.backupScheduleService.CreateBackupSchedule(req.ConfigID, req.InstanceID, req.Cron, req.RetentionCount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(schedule, "计划已创建"))
+}
+
+type ListBackupSchedulesRequest struct {
+	ConfigID   string `json:"configId"`
+	InstanceID string `json:"instanceId"`
+}
+
+// ListBackupSchedules 获取定时备份计划及下次执行时间
+func (bc *BackupScheduleController) ListBackupSchedules(c *gin.Context) {
+	var req ListBackupSchedulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	schedules, err := services.ListBackupSchedules(req.ConfigID, req.InstanceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取计划失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(schedules, "success"))
+}
+
+type UpdateBackupScheduleRequest struct {
+	ID             string `json:"id" binding:"required"`
+	Cron           string `json:"cron" binding:"required"`
+	RetentionCount int    `json:"retentionCount" binding:"required"`
+	Enabled        bool   `json:"enabled"`
+}
+
+// UpdateBackupSchedule 修改定时备份计划
+func (bc *BackupScheduleController) UpdateBackupSchedule(c *gin.Context) {
+	var req UpdateBackupScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.UpdateBackupSchedule(req.ID, req.Cron, req.RetentionCount, req.Enabled); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "计划已更新"))
+}
+
+type DeleteBackupScheduleRequest struct {
+	ID string `json:"id" binding:"required"`
+}
+
+// DeleteBackupSchedule 删除定时备份计划
+func (bc *BackupScheduleController) DeleteBackupSchedule(c *gin.Context) {
+	var req DeleteBackupScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.DeleteBackupSchedule(req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "删除失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "计划已删除"))
+}
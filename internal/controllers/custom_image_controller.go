@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CustomImageController struct {
+	imageService *services.CustomImageService
+}
+
+func NewCustomImageController(imageService *services.CustomImageService) *CustomImageController {
+	return &CustomImageController{imageService: imageService}
+}
+
+type ListCustomImagesRequest struct {
+	UserId        string `json:"userId" binding:"required"`
+	CompartmentId string `json:"compartmentId"` // 为空时使用租户根区间
+	Region        string `json:"region"`
+}
+
+// ListCustomImages 列出自定义镜像
+func (ic *CustomImageController) ListCustomImages(c *gin.Context) {
+	var req ListCustomImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if !services.IsValidCompartmentID(req.CompartmentId) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "区间 OCID 格式无效"))
+		return
+	}
+
+	images, err := ic.imageService.ListCustomImages(req.UserId, req.CompartmentId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(images, "获取成功"))
+}
+
+type CreateCustomImageRequest struct {
+	UserId      string `json:"userId" binding:"required"`
+	InstanceId  string `json:"instanceId" binding:"required"`
+	DisplayName string `json:"displayName"` // 为空时使用实例名加时间
+	Region      string `json:"region"`
+}
+
+// CreateCustomImage 从实例创建自定义镜像
+func (ic *CustomImageController) CreateCustomImage(c *gin.Context) {
+	var req CreateCustomImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	image, err := ic.imageService.CreateFromInstance(req.UserId, req.InstanceId, req.DisplayName, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(image, "镜像创建中"))
+}
+
+type CustomImageRequest struct {
+	UserId  string `json:"userId" binding:"required"`
+	ImageId string `json:"imageId" binding:"required"`
+	Region  string `json:"region"`
+}
+
+// DeleteCustomImage 删除自定义镜像
+func (ic *CustomImageController) DeleteCustomImage(c *gin.Context) {
+	var req CustomImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := ic.imageService.DeleteCustomImage(req.UserId, req.ImageId, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "镜像已删除"))
+}
+
+type ExportCustomImageRequest struct {
+	CustomImageRequest
+	BucketName string `json:"bucketName" binding:"required"`
+	ObjectName string `json:"objectName"` // 为空时使用镜像名称
+	Format     string `json:"format"`     // OCI / QCOW2 / VMDK / VHD / VDI，默认 OCI
+}
+
+// ExportCustomImage 将自定义镜像导出到对象存储
+func (ic *CustomImageController) ExportCustomImage(c *gin.Context) {
+	var req ExportCustomImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if !services.IsImageExportFormat(req.Format) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "不支持的导出格式: "+req.Format))
+		return
+	}
+
+	objectName, err := ic.imageService.ExportCustomImage(req.UserId, req.ImageId, req.BucketName, req.ObjectName, req.Format, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{"objectName": objectName}, "镜像导出中"))
+}
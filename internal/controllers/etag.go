@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// cacheETag 根据缓存版本（配置ID与更新时间）、数据类型及 fields 参数生成弱 ETag
+func cacheETag(c *gin.Context, cache *models.OciConfigCache, kind string) string {
+	version := fmt.Sprintf("%s|%s|%d|%s", kind, cache.ConfigID, cache.UpdateTime.UnixNano(), c.Query("fields"))
+	sum := sha1.Sum([]byte(version))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// notModified 设置 ETag 响应头，If-None-Match 与之匹配时返回 304
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type FavoriteController struct{}
+
+func NewFavoriteController() *FavoriteController {
+	return &FavoriteController{}
+}
+
+// List 获取当前用户收藏的配置与实例
+func (fc *FavoriteController) List(c *gin.Context) {
+	favorites, err := services.ListFavorites(c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(favorites, "success"))
+}
+
+type SetFavoriteRequest struct {
+	TargetType string `json:"targetType" binding:"required,oneof=config instance"`
+	TargetID   string `json:"targetId" binding:"required"`
+	ConfigID   string `json:"configId"` // 收藏实例时必填，为实例所属配置
+	Favorite   bool   `json:"favorite"`
+}
+
+// Set 收藏或取消收藏配置或实例
+func (fc *FavoriteController) Set(c *gin.Context) {
+	var req SetFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if req.TargetType == services.FavoriteTypeInstance && req.ConfigID == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "收藏实例时需要指定 configId"))
+		return
+	}
+
+	if err := services.SetFavorite(c.GetString("username"), req.TargetType, req.TargetID, req.ConfigID, req.Favorite); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	message := "已取消收藏"
+	if req.Favorite {
+		message = "已收藏"
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, message))
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// selectFields 按查询参数 fields（逗号分隔的 JSON 字段名）裁剪列表中的每一项，未指定时原样返回
+// 未知字段会被忽略，如 ?fields=id,displayName,state
+func selectFields(c *gin.Context, list interface{}) interface{} {
+	raw := strings.TrimSpace(c.Query("fields"))
+	if raw == "" {
+		return list
+	}
+	fields := make(map[string]bool)
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields[field] = true
+		}
+	}
+	if len(fields) == 0 {
+		return list
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return list
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return list
+	}
+
+	selected := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		selected[i] = make(map[string]json.RawMessage, len(fields))
+		for key, value := range item {
+			if fields[key] {
+				selected[i][key] = value
+			}
+		}
+	}
+	return selected
+}
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type FileManagerController struct {
+	fileService *services.FileManagerService
+}
+
+func NewFileManagerController(fileService *services.FileManagerService) *FileManagerController {
+	return &FileManagerController{fileService: fileService}
+}
+
+// Connect 建立到实例的 SFTP 连接，返回后续文件操作使用的令牌
+func (fc *FileManagerController) Connect(c *gin.Context) {
+	var req InstanceSSHRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	session, err := fc.fileService.Connect(req.UserId, req.InstanceId, req.SSHConnectOptions())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(session, "已连接"))
+}
+
+type FileSessionRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Disconnect 断开 SFTP 连接
+func (fc *FileManagerController) Disconnect(c *gin.Context) {
+	var req FileSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := fc.fileService.Disconnect(req.Token); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "已断开"))
+}
+
+type FilePathRequest struct {
+	Token string `json:"token" binding:"required"`
+	Path  string `json:"path"`
+}
+
+// List 列出目录内容，path 为空时列出登录用户的主目录
+func (fc *FileManagerController) List(c *gin.Context) {
+	var req FilePathRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	entries, err := fc.fileService.List(req.Token, req.Path)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(entries, "success"))
+}
+
+// Read 读取文本文件内容用于在线编辑
+func (fc *FileManagerController) Read(c *gin.Context) {
+	var req FilePathRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Path == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "请指定文件路径"))
+		return
+	}
+
+	content, err := fc.fileService.ReadFile(req.Token, req.Path)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(content, "success"))
+}
+
+type FileWriteRequest struct {
+	Token   string `json:"token" binding:"required"`
+	Path    string `json:"path" binding:"required"`
+	Content string `json:"content"`
+}
+
+// Write 保存在线编辑的文件
+func (fc *FileManagerController) Write(c *gin.Context) {
+	var req FileWriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := fc.fileService.WriteFile(req.Token, req.Path, req.Content); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "文件已保存"))
+}
+
+// Upload 上传文件，使用 multipart 表单：token、path（目标目录）与 file
+func (fc *FileManagerController) Upload(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxUploadFileSize+1<<20)
+	token := c.PostForm("token")
+	dir := c.PostForm("path")
+	if token == "" || dir == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "token 与 path 不能为空"))
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "请选择文件"))
+		return
+	}
+	if header.Size > services.MaxUploadFileSize {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, fmt.Sprintf("文件超过 %s", services.FormatBytes(services.MaxUploadFileSize))))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	filePath, err := fc.fileService.Upload(token, dir, path.Base(header.Filename), file)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{"path": filePath}, "上传成功"))
+}
+
+// Download 下载文件
+func (fc *FileManagerController) Download(c *gin.Context) {
+	var req FilePathRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Path == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "请指定文件路径"))
+		return
+	}
+
+	file, info, err := fc.fileService.Open(req.Token, req.Path)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, info.Size(), "application/octet-stream", file, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", info.Name()),
+	})
+}
+
+// Mkdir 创建目录
+func (fc *FileManagerController) Mkdir(c *gin.Context) {
+	var req FilePathRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Path == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "请指定目录路径"))
+		return
+	}
+
+	if err := fc.fileService.Mkdir(req.Token, req.Path); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "目录已创建"))
+}
+
+type FileRenameRequest struct {
+	Token   string `json:"token" binding:"required"`
+	OldPath string `json:"oldPath" binding:"required"`
+	NewPath string `json:"newPath" binding:"required"`
+}
+
+// Rename 重命名或移动文件
+func (fc *FileManagerController) Rename(c *gin.Context) {
+	var req FileRenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := fc.fileService.Rename(req.Token, req.OldPath, req.NewPath); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "已重命名"))
+}
+
+// Delete 删除文件或目录
+func (fc *FileManagerController) Delete(c *gin.Context) {
+	var req FilePathRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Path == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "请指定文件路径"))
+		return
+	}
+
+	if err := fc.fileService.Delete(req.Token, req.Path); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "已删除"))
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type GatewayController struct {
+	gatewayService *services.GatewayService
+}
+
+func NewGatewayController(gatewayService *services.GatewayService) *GatewayController {
+	return &GatewayController{gatewayService: gatewayService}
+}
+
+type VcnGatewayRequest struct {
+	UserId string `json:"userId" binding:"required"`
+	VcnId  string `json:"vcnId" binding:"required"`
+	Region string `json:"region"`
+}
+
+// ListGateways 列出 VCN 的互联网网关、NAT 网关与服务网关
+func (gc *GatewayController) ListGateways(c *gin.Context) {
+	var req VcnGatewayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	gateways, err := gc.gatewayService.ListGateways(req.UserId, req.VcnId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(gateways, "获取成功"))
+}
+
+type CreateGatewayRequest struct {
+	UserId       string `json:"userId" binding:"required"`
+	VcnId        string `json:"vcnId" binding:"required"`
+	Type         string `json:"type" binding:"required,oneof=internet nat service"`
+	DisplayName  string `json:"displayName"`
+	BlockTraffic bool   `json:"blockTraffic"` // 仅 NAT 网关
+	Region       string `json:"region"`
+}
+
+// CreateGateway 创建网关
+func (gc *GatewayController) CreateGateway(c *gin.Context) {
+	var req CreateGatewayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	gatewayId, err := gc.gatewayService.CreateGateway(req.UserId, req.Region, services.GatewayParams{
+		VcnID:        req.VcnId,
+		Type:         req.Type,
+		DisplayName:  req.DisplayName,
+		BlockTraffic: req.BlockTraffic,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"gatewayId": gatewayId}, "网关创建成功"))
+}
+
+type GatewayRequest struct {
+	UserId    string `json:"userId" binding:"required"`
+	GatewayId string `json:"gatewayId" binding:"required"`
+	Region    string `json:"region"`
+}
+
+// DeleteGateway 删除网关及路由表中指向它的规则
+func (gc *GatewayController) DeleteGateway(c *gin.Context) {
+	var req GatewayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := gc.gatewayService.DeleteGateway(req.UserId, req.GatewayId, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "网关删除成功"))
+}
+
+// ListRouteTables 列出 VCN 的路由表
+func (gc *GatewayController) ListRouteTables(c *gin.Context) {
+	var req VcnGatewayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	tables, err := gc.gatewayService.ListRouteTables(req.UserId, req.VcnId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(tables, "获取成功"))
+}
+
+type GatewayRouteRequest struct {
+	UserId       string `json:"userId" binding:"required"`
+	RouteTableId string `json:"routeTableId" binding:"required"`
+	GatewayId    string `json:"gatewayId" binding:"required"`
+	Destination  string `json:"destination"` // 为空时使用默认路由或全部 Oracle 服务
+	Region       string `json:"region"`
+}
+
+// AttachGatewayRoute 在路由表中添加指向网关的规则
+func (gc *GatewayController) AttachGatewayRoute(c *gin.Context) {
+	var req GatewayRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := gc.gatewayService.AttachGatewayRoute(req.UserId, req.RouteTableId, req.GatewayId, req.Destination, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "路由规则已添加"))
+}
+
+// DetachGatewayRoute 移除路由表中指向网关的规则
+func (gc *GatewayController) DetachGatewayRoute(c *gin.Context) {
+	var req GatewayRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := gc.gatewayService.DetachGatewayRoute(req.UserId, req.RouteTableId, req.GatewayId, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "路由规则已移除"))
+}
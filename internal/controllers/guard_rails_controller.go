@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type GuardRailsController struct {
+	taskService *services.TaskService
+}
+
+func NewGuardRailsController(taskService *services.TaskService) *GuardRailsController {
+	return &GuardRailsController{taskService: taskService}
+}
+
+// APICallUsage 配置当天的 OCI API 调用次数
+type APICallUsage struct {
+	ConfigID string `json:"configId"`
+	Username string `json:"username"`
+	Calls    int64  `json:"calls"`
+}
+
+// GetGuardRails 获取部署级硬性限制与各配置当天的 API 调用次数
+func (gc *GuardRailsController) GetGuardRails(c *gin.Context) {
+	calls := services.GetDailyAPICalls()
+	usage := []APICallUsage{}
+	if len(calls) > 0 {
+		ids := make([]string, 0, len(calls))
+		for id := range calls {
+			ids = append(ids, id)
+		}
+		var users []models.OciUser
+		database.GetDB().Select("id", "username").Where("id IN ?", ids).Find(&users)
+		for _, user := range users {
+			usage = append(usage, APICallUsage{ConfigID: user.ID, Username: user.Username, Calls: calls[user.ID]})
+		}
+		sort.Slice(usage, func(i, j int) bool { return usage[i].Calls > usage[j].Calls })
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{
+		"rails":              services.GetGuardRails(),
+		"maxMinTaskInterval": services.MaxGuardMinTaskInterval,
+		"usage":              usage,
+	}, "success"))
+}
+
+// UpdateGuardRails 设置部署级硬性限制，立即对所有任务与 OCI 请求生效
+func (gc *GuardRailsController) UpdateGuardRails(c *gin.Context) {
+	var req services.GuardRails
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := gc.taskService.SetGuardRails(req); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "限制已保存"))
+}
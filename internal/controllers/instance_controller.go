@@ -1,11 +1,16 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
 
 	"github.com/adiecho/oci-panel/internal/models"
 	"github.com/adiecho/oci-panel/internal/services"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 )
 
 type InstanceController struct {
@@ -17,8 +22,28 @@ func NewInstanceController(instanceService *services.InstanceService) *InstanceC
 }
 
 type ListInstancesRequest struct {
-	UserId        string `json:"userId" binding:"required"`
-	CompartmentId string `json:"compartmentId" binding:"required"`
+	UserId        string   `json:"userId" binding:"required"`
+	CompartmentId string   `json:"compartmentId" binding:"required"`
+	Include       []string `json:"include"` // 附加数据：bootVolume / ipv6 / image / traffic，默认不获取以保证列表速度
+
+	// 分页、过滤与排序，page 为 0 时返回全部实例的数组，否则返回分页结果
+	Page      int    `json:"page"`
+	PageSize  int    `json:"pageSize"`
+	State     string `json:"state"`     // 生命周期状态，如 RUNNING
+	Shape     string `json:"shape"`     // 规格，如 VM.Standard.A1.Flex
+	Region    string `json:"region"`    // 为空时使用配置的主区域
+	Name      string `json:"name"`      // 名称包含的子串
+	Tag       string `json:"tag"`       // 标签，key 或 key=value
+	SortBy    string `json:"sortBy"`    // name / state / shape / timeCreated
+	SortOrder string `json:"sortOrder"` // asc / desc
+	Refresh   bool   `json:"refresh"`   // 跳过缓存直接从 OCI 获取
+}
+
+type InstancePageResponse struct {
+	List     interface{} `json:"list"` // []services.InstanceInfo，指定 fields 时仅包含所选字段
+	Total    int         `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"pageSize"`
 }
 
 func (ic *InstanceController) ListInstances(c *gin.Context) {
@@ -27,14 +52,101 @@ func (ic *InstanceController) ListInstances(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
+	for _, name := range req.Include {
+		if !services.IsInstanceEnrichment(name) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "不支持的附加数据: "+name))
+			return
+		}
+	}
+	query := services.InstanceListQuery{
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		State:     req.State,
+		Shape:     req.Shape,
+		Region:    req.Region,
+		Name:      req.Name,
+		Tag:       req.Tag,
+		SortBy:    req.SortBy,
+		SortOrder: req.SortOrder,
+		Username:  c.GetString("username"),
+		Refresh:   req.Refresh,
+	}
+	if err := query.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	result, err := ic.instanceService.ListInstances(req.UserId, req.CompartmentId, req.Include, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	var data interface{} = selectFields(c, result.List)
+	if req.Page > 0 {
+		data = InstancePageResponse{
+			List:     data,
+			Total:    result.Total,
+			Page:     req.Page,
+			PageSize: req.PageSize,
+		}
+	}
+	if !result.UpdateTime.IsZero() {
+		c.JSON(http.StatusOK, models.CachedResponse(data, "获取实例列表成功", result.UpdateTime))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(data, "获取实例列表成功"))
+}
+
+type RefreshInstanceListRequest struct {
+	UserId        string `json:"userId" binding:"required"`
+	CompartmentId string `json:"compartmentId" binding:"required"`
+	Region        string `json:"region"`
+}
+
+// RefreshInstanceList 立即从 OCI 刷新实例列表缓存
+func (ic *InstanceController) RefreshInstanceList(c *gin.Context) {
+	var req RefreshInstanceListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
 
-	instances, err := ic.instanceService.ListInstances(req.UserId, req.CompartmentId)
+	snapshot, err := ic.instanceService.RefreshInstanceList(req.UserId, req.CompartmentId, req.Region)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(instances, "获取实例列表成功"))
+	c.JSON(http.StatusOK, models.CachedResponse(snapshot, "实例列表已刷新", snapshot.UpdateTime))
+}
+
+// GetListCache 获取实例列表缓存的有效期（秒）
+func (ic *InstanceController) GetListCache(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{
+		"ttl":    services.GetInstanceListCacheTTL(),
+		"maxTtl": services.MaxInstanceListCacheTTL,
+	}, "success"))
+}
+
+type UpdateListCacheRequest struct {
+	TTL *int `json:"ttl" binding:"required"` // 为 0 时关闭缓存
+}
+
+// UpdateListCache 设置实例列表缓存的有效期（秒）
+func (ic *InstanceController) UpdateListCache(c *gin.Context) {
+	var req UpdateListCacheRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.SetInstanceListCacheTTL(*req.TTL); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "设置已保存"))
 }
 
 type InstanceActionRequest struct {
@@ -42,155 +154,708 @@ type InstanceActionRequest struct {
 	InstanceId string `json:"instanceId" binding:"required"`
 }
 
-func (ic *InstanceController) StartInstance(c *gin.Context) {
-	var req InstanceActionRequest
+type BatchInstanceActionRequest struct {
+	Action string                         `json:"action" binding:"required"` // start / stop / reboot / terminate
+	Items  []services.BatchInstanceTarget `json:"items" binding:"required,min=1,dive"`
+}
+
+// BatchInstanceAction 批量启动、停止、重启或终止实例，返回每个实例的结果
+func (ic *InstanceController) BatchInstanceAction(c *gin.Context) {
+	var req BatchInstanceActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if !services.IsBatchInstanceAction(req.Action) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "不支持的操作: "+req.Action))
+		return
+	}
+
+	report, err := ic.instanceService.BatchInstanceAction(req.Action, req.Items)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(report, fmt.Sprintf("成功 %d 个，失败 %d 个", report.Succeeded, report.Failed)))
+}
+
+func (ic *InstanceController) StartInstance(c *gin.Context) {
+	var req InstanceActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := ic.instanceService.StartInstance(req.UserId, req.InstanceId); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例启动成功"))
+}
+
+func (ic *InstanceController) StopInstance(c *gin.Context) {
+	var req InstanceActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := ic.instanceService.StopInstance(req.UserId, req.InstanceId); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例停止成功"))
+}
+
+func (ic *InstanceController) RebootInstance(c *gin.Context) {
+	var req InstanceActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := ic.instanceService.RebootInstance(req.UserId, req.InstanceId); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例重启成功"))
+}
+
+func (ic *InstanceController) TerminateInstance(c *gin.Context) {
+	var req InstanceActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := ic.instanceService.TerminateInstance(req.UserId, req.InstanceId); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例删除成功"))
+}
+
+type SetProtectionRequest struct {
+	UserId     string `json:"userId" binding:"required"`
+	InstanceId string `json:"instanceId" binding:"required"`
+	Protected  bool   `json:"protected"`
+}
+
+// SetProtection 手动开启或关闭实例的终止保护
+func (ic *InstanceController) SetProtection(c *gin.Context) {
+	var req SetProtectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.SetInstanceProtection(req.UserId, req.InstanceId, req.Protected); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	message := "已关闭终止保护（带保护标签的实例会在下次同步时重新开启）"
+	if req.Protected {
+		message = "已开启终止保护"
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, message))
+}
+
+type SetSSHPortRequest struct {
+	UserId     string `json:"userId" binding:"required"`
+	InstanceId string `json:"instanceId" binding:"required"`
+	Port       int    `json:"port" binding:"required"`
+}
+
+// SetSSHPort 记录实例实际使用的 SSH 端口，开启500Mbps时按此端口创建监听器
+func (ic *InstanceController) SetSSHPort(c *gin.Context) {
+	var req SetSSHPortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.SetInstanceSSHPort(req.UserId, req.InstanceId, req.Port); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "SSH端口已保存"))
+}
+
+// GetInstanceDetail 获取实例的网卡、存储卷、镜像、可用域与标签等完整信息
+func (ic *InstanceController) GetInstanceDetail(c *gin.Context) {
+	userId := c.Query("userId")
+	instanceId := c.Query("instanceId")
+	if userId == "" || instanceId == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "userId 和 instanceId 不能为空"))
+		return
+	}
+
+	detail, err := ic.instanceService.GetInstanceDetail(userId, instanceId, c.Query("region"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(detail, "获取成功"))
+}
+
+type InstanceMetricsRequest struct {
+	UserId     string `json:"userId" binding:"required"`
+	InstanceId string `json:"instanceId" binding:"required"`
+	Range      string `json:"range"` // 1h / 24h / 7d，默认 1h
+}
+
+// GetInstanceMetrics 获取实例的 CPU、内存、磁盘与网络监控指标，用于绘制图表
+func (ic *InstanceController) GetInstanceMetrics(c *gin.Context) {
+	var req InstanceMetricsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if !services.IsMetricRange(req.Range) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "时间范围仅支持 1h、24h、7d"))
+		return
+	}
+
+	metrics, err := ic.instanceService.GetInstanceMetrics(req.UserId, req.InstanceId, req.Range)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(metrics, "获取成功"))
+}
+
+type ResourceTagsRequest struct {
+	UserId       string `json:"userId" binding:"required"`
+	ResourceId   string `json:"resourceId" binding:"required"`
+	ResourceType string `json:"resourceType"` // instance / bootVolume / volume，默认 instance
+	Region       string `json:"region"`
+}
+
+type UpdateResourceTagsRequest struct {
+	ResourceTagsRequest
+	services.ResourceTags
+}
+
+// GetResourceTags 获取实例或卷的自由格式标签与定义标签
+func (ic *InstanceController) GetResourceTags(c *gin.Context) {
+	var req ResourceTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if req.ResourceType == "" {
+		req.ResourceType = services.TagResourceInstance
+	}
+	if !services.IsTagResource(req.ResourceType) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "不支持的资源类型: "+req.ResourceType))
+		return
+	}
+
+	tags, err := ic.instanceService.GetResourceTags(req.UserId, req.Region, req.ResourceType, req.ResourceId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(tags, "获取成功"))
+}
+
+// UpdateResourceTags 更新实例或卷的标签，未传入的标签类型保持不变
+func (ic *InstanceController) UpdateResourceTags(c *gin.Context) {
+	var req UpdateResourceTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if req.ResourceType == "" {
+		req.ResourceType = services.TagResourceInstance
+	}
+	if !services.IsTagResource(req.ResourceType) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "不支持的资源类型: "+req.ResourceType))
+		return
+	}
+	if req.FreeformTags == nil && req.DefinedTags == nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "freeformTags 和 definedTags 不能同时为空"))
+		return
+	}
+
+	tags, err := ic.instanceService.UpdateResourceTags(req.UserId, req.Region, req.ResourceType, req.ResourceId, req.ResourceTags)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(tags, "标签已更新"))
+}
+
+type ProtectTagRequest struct {
+	Tag string `json:"tag" binding:"required"`
+}
+
+// GetProtectTag 获取触发终止保护的实例标签
+func (ic *InstanceController) GetProtectTag(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{"tag": services.GetProtectTag()}, "success"))
+}
+
+// UpdateProtectTag 设置触发终止保护的实例标签，格式为 key=value
+func (ic *InstanceController) UpdateProtectTag(c *gin.Context) {
+	var req ProtectTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.SetProtectTag(req.Tag); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "保护标签已更新，将在下次同步时生效"))
+}
+
+type UpdateInstanceNameRequest struct {
+	UserId      string `json:"userId" binding:"required"`
+	InstanceId  string `json:"instanceId" binding:"required"`
+	DisplayName string `json:"displayName" binding:"required"`
+}
+
+func (ic *InstanceController) UpdateInstanceName(c *gin.Context) {
+	var req UpdateInstanceNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := ic.instanceService.UpdateInstanceName(req.UserId, req.InstanceId, req.DisplayName); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例名称更新成功"))
+}
+
+// ExportTerraform 导出实例的 Terraform/OpenTofu 配置，附带 import 块用于纳管现有资源
+func (ic *InstanceController) ExportTerraform(c *gin.Context) {
+	var req InstanceActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	export, err := ic.instanceService.ExportTerraform(req.UserId, req.InstanceId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(export, "success"))
+}
+
+type ChangeIPRequest struct {
+	UserId     string `json:"userId" binding:"required"`
+	InstanceId string `json:"instanceId" binding:"required"`
+}
+
+func (ic *InstanceController) ChangePublicIP(c *gin.Context) {
+	var req ChangeIPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	newIP, err := ic.instanceService.ChangePublicIP(req.UserId, req.InstanceId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"newIP": newIP}, "IP更改成功"))
+}
+
+type UpdateInstanceConfigRequest struct {
+	UserId      string  `json:"userId" binding:"required"`
+	InstanceId  string  `json:"instanceId" binding:"required"`
+	Shape       string  `json:"shape"` // 目标 Shape，为空表示不修改
+	Ocpus       float32 `json:"ocpus" binding:"required,gt=0"`
+	MemoryInGBs float32 `json:"memoryInGBs" binding:"required,gt=0"`
+	AutoRestart bool    `json:"autoRestart"` // 是否自动重启实例，默认false
+}
+
+func (ic *InstanceController) UpdateInstanceConfig(c *gin.Context) {
+	var req UpdateInstanceConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	// 先预检查，避免直接返回 OCI 原始错误
+	precheck, err := ic.instanceService.PrecheckInstanceConfig(req.UserId, req.InstanceId, req.Shape, req.Ocpus, req.MemoryInGBs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+	if precheck.Action == services.PrecheckActionRebuild {
+		respondRebuildSuggestion(c, precheck.CurrentShape, precheck.TargetShape)
+		return
+	}
+	if !precheck.Valid {
+		c.JSON(http.StatusBadRequest, models.ResponseData{Code: 400, Message: strings.Join(precheck.Errors, "；"), Data: precheck})
+		return
+	}
+	if precheck.Action == services.PrecheckActionNoChange {
+		c.JSON(http.StatusOK, models.SuccessResponse(nil, precheck.Message))
+		return
+	}
+
+	if err := ic.instanceService.UpdateInstanceConfig(req.UserId, req.InstanceId, req.Shape, req.Ocpus, req.MemoryInGBs, req.AutoRestart); err != nil {
+		var archErr *services.CrossArchitectureError
+		if errors.As(err, &archErr) {
+			respondRebuildSuggestion(c, archErr.CurrentShape, archErr.TargetShape)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	msg := "实例配置更新成功"
+	if req.AutoRestart {
+		msg = "实例配置更新成功，正在重启实例"
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, msg))
+}
+
+type PrecheckInstanceConfigRequest struct {
+	UserId      string  `json:"userId" binding:"required"`
+	InstanceId  string  `json:"instanceId" binding:"required"`
+	Shape       string  `json:"shape"`
+	Ocpus       float32 `json:"ocpus"`
+	MemoryInGBs float32 `json:"memoryInGBs"`
+}
+
+// PrecheckInstanceConfig 修改实例配置前的预检查，返回校验结果及修改方式
+func (ic *InstanceController) PrecheckInstanceConfig(c *gin.Context) {
+	var req PrecheckInstanceConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	precheck, err := ic.instanceService.PrecheckInstanceConfig(req.UserId, req.InstanceId, req.Shape, req.Ocpus, req.MemoryInGBs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(precheck, "预检查完成"))
+}
+
+// respondRebuildSuggestion 跨架构无法原地调整，返回重建建议供前端引导用户确认
+func respondRebuildSuggestion(c *gin.Context, currentShape, targetShape string) {
+	c.JSON(http.StatusConflict, models.ResponseData{
+		Code:    409,
+		Message: (&services.CrossArchitectureError{CurrentShape: currentShape, TargetShape: targetShape}).Error(),
+		Data: RebuildSuggestion{
+			RequiresRebuild:     true,
+			CurrentShape:        currentShape,
+			CurrentArchitecture: services.ShapeArchitecture(currentShape),
+			TargetShape:         targetShape,
+			TargetArchitecture:  services.ShapeArchitecture(targetShape),
+			RebuildAPI:          "/api/instance/rebuildShape",
+		},
+	})
+}
+
+// RebuildSuggestion 跨架构调整时返回的重建建议
+type RebuildSuggestion struct {
+	RequiresRebuild     bool   `json:"requiresRebuild"`
+	CurrentShape        string `json:"currentShape"`
+	CurrentArchitecture string `json:"currentArchitecture"`
+	TargetShape         string `json:"targetShape"`
+	TargetArchitecture  string `json:"targetArchitecture"`
+	RebuildAPI          string `json:"rebuildApi"`
+}
+
+type RebuildShapeRequest struct {
+	UserId            string  `json:"userId" binding:"required"`
+	InstanceId        string  `json:"instanceId" binding:"required"`
+	TargetShape       string  `json:"targetShape" binding:"required"`
+	Ocpus             float32 `json:"ocpus"`
+	MemoryInGBs       float32 `json:"memoryInGBs"`
+	ImageId           string  `json:"imageId"`
+	KeepOldBootVolume bool    `json:"keepOldBootVolume"`
+}
+
+// RebuildShape 跨架构重建实例（备份原引导卷后使用目标 Shape 重新创建）
+func (ic *InstanceController) RebuildShape(c *gin.Context) {
+	var req RebuildShapeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if services.IsFlexShape(req.TargetShape) && (req.Ocpus <= 0 || req.MemoryInGBs <= 0) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "Flex Shape 需要指定 OCPU 和内存"))
+		return
+	}
+
+	jobId, err := ic.instanceService.StartShapeRebuild(req.UserId, services.ShapeRebuildParams{
+		InstanceID:        req.InstanceId,
+		TargetShape:       req.TargetShape,
+		Ocpus:             req.Ocpus,
+		MemoryInGBs:       req.MemoryInGBs,
+		ImageID:           req.ImageId,
+		KeepOldBootVolume: req.KeepOldBootVolume,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"jobId": jobId}, "重建任务已启动，请等待完成"))
+}
+
+type RebuildShapeStatusRequest struct {
+	JobId string `json:"jobId" binding:"required"`
+}
+
+// RebuildShapeStatus 查询跨架构重建任务进度
+func (ic *InstanceController) RebuildShapeStatus(c *gin.Context) {
+	var req RebuildShapeStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, ok := ic.instanceService.GetShapeRebuildJob(req.JobId)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "重建任务不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "获取成功"))
+}
+
+type ChangeShapeRequest struct {
+	UserId      string  `json:"userId" binding:"required"`
+	InstanceId  string  `json:"instanceId" binding:"required"`
+	TargetShape string  `json:"targetShape"` // 为空表示只调整 OCPU/内存
+	Ocpus       float32 `json:"ocpus"`
+	MemoryInGBs float32 `json:"memoryInGBs"`
+	AutoRestart bool    `json:"autoRestart"`
+}
+
+// ChangeShape 后台原地调整实例 Shape，先预检查配额与兼容性，跨架构时返回重建建议
+func (ic *InstanceController) ChangeShape(c *gin.Context) {
+	var req ChangeShapeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
 
-	if err := ic.instanceService.StartInstance(req.UserId, req.InstanceId); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+	if services.IsFlexShape(req.TargetShape) && (req.Ocpus <= 0 || req.MemoryInGBs <= 0) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "Flex Shape 需要指定 OCPU 和内存"))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例启动成功"))
-}
-
-func (ic *InstanceController) StopInstance(c *gin.Context) {
-	var req InstanceActionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+	precheck, err := ic.instanceService.PrecheckInstanceConfig(req.UserId, req.InstanceId, req.TargetShape, req.Ocpus, req.MemoryInGBs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+	if precheck.Action == services.PrecheckActionRebuild {
+		respondRebuildSuggestion(c, precheck.CurrentShape, precheck.TargetShape)
+		return
+	}
+	if !precheck.Valid {
+		c.JSON(http.StatusBadRequest, models.ResponseData{Code: 400, Message: strings.Join(precheck.Errors, "；"), Data: precheck})
+		return
+	}
+	if precheck.Action == services.PrecheckActionNoChange {
+		c.JSON(http.StatusOK, models.SuccessResponse(nil, precheck.Message))
 		return
 	}
 
-	if err := ic.instanceService.StopInstance(req.UserId, req.InstanceId); err != nil {
+	jobId, err := ic.instanceService.StartShapeChange(req.UserId, services.ShapeChangeParams{
+		InstanceID:  req.InstanceId,
+		TargetShape: req.TargetShape,
+		Ocpus:       req.Ocpus,
+		MemoryInGBs: req.MemoryInGBs,
+		AutoRestart: req.AutoRestart,
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例停止成功"))
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"jobId": jobId}, "调整任务已启动，请等待完成"))
 }
 
-func (ic *InstanceController) RebootInstance(c *gin.Context) {
-	var req InstanceActionRequest
+type ChangeShapeStatusRequest struct {
+	JobId string `json:"jobId" binding:"required"`
+}
+
+// ChangeShapeStatus 查询调整 Shape 任务进度
+func (ic *InstanceController) ChangeShapeStatus(c *gin.Context) {
+	var req ChangeShapeStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
 
-	if err := ic.instanceService.RebootInstance(req.UserId, req.InstanceId); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+	job, ok := ic.instanceService.GetShapeChangeJob(req.JobId)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "调整任务不存在"))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例重启成功"))
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "获取成功"))
 }
 
-func (ic *InstanceController) TerminateInstance(c *gin.Context) {
-	var req InstanceActionRequest
+type CloneInstanceRequest struct {
+	UserId             string `json:"userId" binding:"required"`
+	InstanceId         string `json:"instanceId" binding:"required"`
+	Region             string `json:"region"`
+	DisplayName        string `json:"displayName"`
+	AvailabilityDomain string `json:"availabilityDomain"` // 为空时与原实例相同
+	KeepBackup         bool   `json:"keepBackup"`
+}
+
+// CloneInstance 克隆实例（备份引导卷后以相同 Shape 与网络创建新实例，可指定其它可用域）
+func (ic *InstanceController) CloneInstance(c *gin.Context) {
+	var req CloneInstanceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
 
-	if err := ic.instanceService.TerminateInstance(req.UserId, req.InstanceId); err != nil {
+	jobId, err := ic.instanceService.StartInstanceClone(req.UserId, req.Region, services.InstanceCloneParams{
+		InstanceID:         req.InstanceId,
+		DisplayName:        req.DisplayName,
+		AvailabilityDomain: req.AvailabilityDomain,
+		KeepBackup:         req.KeepBackup,
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例删除成功"))
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"jobId": jobId}, "克隆任务已启动，请等待完成"))
 }
 
-type UpdateInstanceNameRequest struct {
-	UserId      string `json:"userId" binding:"required"`
-	InstanceId  string `json:"instanceId" binding:"required"`
-	DisplayName string `json:"displayName" binding:"required"`
+type CloneInstanceStatusRequest struct {
+	JobId string `json:"jobId" binding:"required"`
 }
 
-func (ic *InstanceController) UpdateInstanceName(c *gin.Context) {
-	var req UpdateInstanceNameRequest
+// CloneInstanceStatus 查询克隆实例任务进度
+func (ic *InstanceController) CloneInstanceStatus(c *gin.Context) {
+	var req CloneInstanceStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
 
-	if err := ic.instanceService.UpdateInstanceName(req.UserId, req.InstanceId, req.DisplayName); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+	job, ok := ic.instanceService.GetInstanceCloneJob(req.JobId)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "克隆任务不存在"))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(nil, "实例名称更新成功"))
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "获取成功"))
 }
 
-type ChangeIPRequest struct {
-	UserId     string `json:"userId" binding:"required"`
-	InstanceId string `json:"instanceId" binding:"required"`
+type UpdateBootVolumeRequest struct {
+	UserId         string `json:"userId" binding:"required"`
+	InstanceId     string `json:"instanceId" binding:"required"`
+	SizeInGBs      int64  `json:"sizeInGBs" binding:"required,gt=0"`
+	VpusPerGB      int64  `json:"vpusPerGB" binding:"required,gt=0"`
+	GrowFilesystem bool   `json:"growFilesystem"`
 }
 
-func (ic *InstanceController) ChangePublicIP(c *gin.Context) {
-	var req ChangeIPRequest
+func (ic *InstanceController) UpdateBootVolume(c *gin.Context) {
+	var req UpdateBootVolumeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
 
-	newIP, err := ic.instanceService.ChangePublicIP(req.UserId, req.InstanceId)
+	growResult, err := ic.instanceService.UpdateBootVolumeConfig(req.UserId, req.InstanceId, req.SizeInGBs, req.VpusPerGB, req.GrowFilesystem)
 	if err != nil {
+		if growResult != nil {
+			// 引导卷已扩容，仅系统内扩展失败，返回命令输出便于排查
+			c.JSON(http.StatusInternalServerError, models.ResponseData{
+				Code:    500,
+				Message: "引导卷已扩容，但" + err.Error(),
+				Data:    growResult,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"newIP": newIP}, "IP更改成功"))
+	if growResult != nil {
+		c.JSON(http.StatusOK, models.SuccessResponse(growResult, "引导卷扩容并扩展文件系统成功"))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "引导卷配置更新成功"))
 }
 
-type UpdateInstanceConfigRequest struct {
-	UserId      string  `json:"userId" binding:"required"`
-	InstanceId  string  `json:"instanceId" binding:"required"`
-	Ocpus       float32 `json:"ocpus" binding:"required,gt=0"`
-	MemoryInGBs float32 `json:"memoryInGBs" binding:"required,gt=0"`
-	AutoRestart bool    `json:"autoRestart"` // 是否自动重启实例，默认false
+type EnablePasswordLoginRequest struct {
+	UserId     string `json:"userId" binding:"required"`
+	InstanceId string `json:"instanceId" binding:"required"`
+	Username   string `json:"username"` // 为空时使用 root
 }
 
-func (ic *InstanceController) UpdateInstanceConfig(c *gin.Context) {
-	var req UpdateInstanceConfigRequest
+// EnablePasswordLogin 通过运行命令为实例生成随机密码并开启 SSH 密码登录，密码仅返回一次
+func (ic *InstanceController) EnablePasswordLogin(c *gin.Context) {
+	var req EnablePasswordLoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
 
-	if err := ic.instanceService.UpdateInstanceConfig(req.UserId, req.InstanceId, req.Ocpus, req.MemoryInGBs, req.AutoRestart); err != nil {
+	login, err := ic.instanceService.EnablePasswordLogin(req.UserId, req.InstanceId, req.Username)
+	if err != nil {
+		if login != nil {
+			c.JSON(http.StatusInternalServerError, models.ResponseData{Code: 500, Message: err.Error(), Data: login.Command})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	msg := "实例配置更新成功"
-	if req.AutoRestart {
-		msg = "实例配置更新成功，正在重启实例"
-	}
-	c.JSON(http.StatusOK, models.SuccessResponse(nil, msg))
+	c.JSON(http.StatusOK, models.SuccessResponse(login, "密码登录已开启，请妥善保存密码"))
 }
 
-type UpdateBootVolumeRequest struct {
-	UserId     string `json:"userId" binding:"required"`
-	InstanceId string `json:"instanceId" binding:"required"`
-	SizeInGBs  int64  `json:"sizeInGBs" binding:"required,gt=0"`
-	VpusPerGB  int64  `json:"vpusPerGB" binding:"required,gt=0"`
+type PasswordUserDataRequest struct {
+	Username string `json:"username"` // 为空时使用 root
+	UserData string `json:"userData"` // 已有的 shell 脚本，密码设置追加在其后
 }
 
-func (ic *InstanceController) UpdateBootVolume(c *gin.Context) {
-	var req UpdateBootVolumeRequest
+// PasswordUserData 生成随机密码及开启密码登录的 cloud-init 脚本，用于创建实例或开机任务
+func (ic *InstanceController) PasswordUserData(c *gin.Context) {
+	var req PasswordUserDataRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
 
-	if err := ic.instanceService.UpdateBootVolumeConfig(req.UserId, req.InstanceId, req.SizeInGBs, req.VpusPerGB); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+	login, err := services.PasswordLoginUserData(req.UserData, req.Username)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(nil, "引导卷配置更新成功"))
+	c.JSON(http.StatusOK, models.SuccessResponse(login, "已生成密码，请妥善保存"))
 }
 
 type UpdateBootVolumeByIdRequest struct {
@@ -265,6 +930,7 @@ type AutoRescueRequest struct {
 	KeepBackup   bool   `json:"keepBackup"`
 }
 
+// AutoRescue 启动自动救援任务，进度可通过 autoRescueStatus 查询或 /ws/rescue 订阅
 func (ic *InstanceController) AutoRescue(c *gin.Context) {
 	var req AutoRescueRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -272,31 +938,117 @@ func (ic *InstanceController) AutoRescue(c *gin.Context) {
 		return
 	}
 
-	// 异步执行救援任务
+	jobId, err := ic.instanceService.StartAutoRescue(req.UserId, services.AutoRescueParams{
+		InstanceID:       req.InstanceId,
+		InstanceName:     req.InstanceName,
+		KeepBackupVolume: req.KeepBackup,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"jobId": jobId}, "自动救援任务已启动，请等待完成"))
+}
+
+type AutoRescueStatusRequest struct {
+	JobId string `json:"jobId" binding:"required"`
+}
+
+// AutoRescueStatus 查询自动救援任务进度
+func (ic *InstanceController) AutoRescueStatus(c *gin.Context) {
+	var req AutoRescueStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, ok := ic.instanceService.GetAutoRescueJob(req.JobId)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "救援任务不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "获取成功"))
+}
+
+type ListAutoRescueRequest struct {
+	UserId string `json:"userId" binding:"required"`
+}
+
+// ListAutoRescue 列出配置下的救援任务，包括已结束和被重启中断的任务
+func (ic *InstanceController) ListAutoRescue(c *gin.Context) {
+	var req ListAutoRescueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	jobs, err := ic.instanceService.ListAutoRescueJobs(req.UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(jobs, "获取成功"))
+}
+
+// StreamAutoRescue 通过 WebSocket 推送救援进度，先补发已有步骤，任务结束时推送最终状态后关闭
+// 任务ID只返回给发起者，作为订阅凭证放在 token 参数中，访问日志会对其脱敏
+func (ic *InstanceController) StreamAutoRescue(c *gin.Context) {
+	jobId := c.Query("token")
+	if _, ok := ic.instanceService.GetAutoRescueJob(jobId); !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "救援任务不存在"))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// 客户端只接收消息，读取失败即视为断开
+	disconnected := make(chan struct{})
 	go func() {
-		progressChan := make(chan services.AutoRescueProgress, 10)
-		go func() {
-			for progress := range progressChan {
-				// 进度可通过WebSocket推送，这里仅记录日志
-				_ = progress
+		defer close(disconnected)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
 			}
-		}()
-
-		err := ic.instanceService.AutoRescue(req.UserId, req.InstanceId, req.InstanceName, req.KeepBackup, progressChan)
-		close(progressChan)
-		if err != nil {
-			// 记录错误日志
-			_ = err
 		}
 	}()
 
-	c.JSON(http.StatusOK, models.SuccessResponse(nil, "自动救援任务已启动，请等待完成"))
+	sent := 0
+	for {
+		job, changed, ok := ic.instanceService.WatchAutoRescueJob(jobId)
+		if !ok {
+			return
+		}
+		for ; sent < len(job.Steps); sent++ {
+			if err := conn.WriteJSON(services.AutoRescueEvent{Type: "progress", Progress: &job.Steps[sent]}); err != nil {
+				return
+			}
+		}
+		if job.Status != services.AutoRescueRunning {
+			_ = conn.WriteJSON(services.AutoRescueEvent{Type: job.Status, Error: job.Error})
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		}
+		select {
+		case <-changed:
+		case <-disconnected:
+			return
+		}
+	}
 }
 
 // Enable500MbpsRequest 一键开启500Mbps请求（简化版，仅需要userId和instanceId）
 type Enable500MbpsRequest struct {
-	UserId     string `json:"userId" binding:"required"`
-	InstanceId string `json:"instanceId" binding:"required"`
+	UserId     string                    `json:"userId" binding:"required"`
+	InstanceId string                    `json:"instanceId" binding:"required"`
+	Ports      []services.NLBPortForward `json:"ports"` // 需要转发的 TCP/UDP 端口，为空时转发所有端口，SSH 端口始终转发
 }
 
 // Enable500Mbps 一键开启下行500Mbps
@@ -310,20 +1062,21 @@ func (ic *InstanceController) Enable500Mbps(c *gin.Context) {
 		return
 	}
 
-	// 使用默认SSH端口22
-	sshPort := 22
+	// 使用实例记录的SSH端口，未设置时为22
+	sshPort := services.GetInstanceSSHPort(req.InstanceId)
+	if err := services.ValidateNLBPortForwards(sshPort, req.Ports); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
 
-	// 异步执行
-	go func() {
-		publicIP, err := ic.instanceService.Enable500Mbps(req.UserId, req.InstanceId, sshPort)
-		if err != nil {
-			_ = err
-		} else {
-			_ = publicIP
-		}
-	}()
+	jobId, err := ic.instanceService.StartEnable500Mbps(req.UserId, req.InstanceId, sshPort, req.Ports)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse(map[string]interface{}{
+		"jobId":   jobId,
 		"warning": "开启后实例原公网IP将失效，请使用新分配的负载均衡器IP访问。此操作仅支持 VM.Standard.E2.1.Micro 实例。",
 	}, "500Mbps开启任务已启动，正在创建NAT网关和网络负载均衡器，请稍候..."))
 }
@@ -344,23 +1097,40 @@ func (ic *InstanceController) Disable500Mbps(c *gin.Context) {
 		return
 	}
 
-	// 默认清理所有资源（NAT网关和网络负载均衡器）
-	retainNatGw := false
-	retainNlb := false
-
-	// 异步执行
-	go func() {
-		err := ic.instanceService.Disable500Mbps(req.UserId, req.InstanceId, retainNatGw, retainNlb)
-		if err != nil {
-			_ = err
-		}
-	}()
+	// 清理所有资源（NAT网关和网络负载均衡器）
+	jobId, err := ic.instanceService.StartDisable500Mbps(req.UserId, req.InstanceId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse(map[string]interface{}{
+		"jobId":   jobId,
 		"warning": "关闭后NAT网关和网络负载均衡器将被删除，实例将失去公网访问能力，需要重新分配公网IP。",
 	}, "500Mbps关闭任务已启动，正在清理NAT网关和网络负载均衡器，请稍候..."))
 }
 
+type Bandwidth500MbpsStatusRequest struct {
+	JobId string `json:"jobId" binding:"required"`
+}
+
+// Bandwidth500MbpsStatus 查询开启或关闭500Mbps任务的结果，开启成功时返回新的公网IP
+func (ic *InstanceController) Bandwidth500MbpsStatus(c *gin.Context) {
+	var req Bandwidth500MbpsStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, ok := ic.instanceService.Get500MbpsJob(req.JobId)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "500Mbps任务不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "获取成功"))
+}
+
 // Check500MbpsSupport 检查实例是否支持500Mbps功能
 // 仅 VM.Standard.E2.1.Micro (AMD) 实例支持此功能
 type Check500MbpsSupportRequest struct {
@@ -394,3 +1164,158 @@ func getShapeSupportMessage(supported bool) string {
 	}
 	return "此实例不支持500Mbps功能，仅 VM.Standard.E2.1.Micro 实例支持此功能"
 }
+
+type InstanceTuningRequest struct {
+	UserId     string `json:"userId" binding:"required"`
+	InstanceId string `json:"instanceId" binding:"required"`
+	SwapSizeMB *int   `json:"swapSizeMb"` // 为空时使用默认大小，为 0 时不创建交换文件
+}
+
+// instanceTuningResponse 调优记录与运行命令的执行结果
+type instanceTuningResponse struct {
+	Tuning  *models.InstanceTuning       `json:"tuning"`
+	Command *services.AgentCommandResult `json:"command"`
+}
+
+// GetInstanceTuning 获取实例的交换文件与内核参数调优记录
+func (ic *InstanceController) GetInstanceTuning(c *gin.Context) {
+	var req InstanceTuningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	tuning, err := services.GetInstanceTuning(req.InstanceId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(tuning, "获取成功"))
+}
+
+// ApplyInstanceTuning 在实例内创建交换文件并应用 BBR 等内核参数
+func (ic *InstanceController) ApplyInstanceTuning(c *gin.Context) {
+	var req InstanceTuningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	swapSizeMB := services.DefaultTuningSwapSizeMB
+	if req.SwapSizeMB != nil {
+		swapSizeMB = *req.SwapSizeMB
+	}
+	tuning, result, err := ic.instanceService.ApplyInstanceTuning(req.UserId, req.InstanceId, swapSizeMB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ResponseData{
+			Code:    500,
+			Message: err.Error(),
+			Data:    instanceTuningResponse{Tuning: tuning, Command: result},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(instanceTuningResponse{Tuning: tuning, Command: result}, "调优已应用"))
+}
+
+// RollbackInstanceTuning 回滚实例的交换文件与内核参数调优
+func (ic *InstanceController) RollbackInstanceTuning(c *gin.Context) {
+	var req InstanceTuningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	tuning, result, err := ic.instanceService.RollbackInstanceTuning(req.UserId, req.InstanceId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ResponseData{
+			Code:    500,
+			Message: err.Error(),
+			Data:    instanceTuningResponse{Tuning: tuning, Command: result},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(instanceTuningResponse{Tuning: tuning, Command: result}, "调优已回滚"))
+}
+
+type ConvertOSRequest struct {
+	UserId            string `json:"userId" binding:"required"`
+	InstanceId        string `json:"instanceId" binding:"required"`
+	TargetVersion     string `json:"targetVersion"` // Ubuntu 版本，如 22.04，为空时使用最新版本
+	KeepOldBootVolume bool   `json:"keepOldBootVolume"`
+}
+
+// ConvertOS 替换引导卷将实例转换为 Ubuntu，保留实例与公网 IP
+func (ic *InstanceController) ConvertOS(c *gin.Context) {
+	var req ConvertOSRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, err := ic.instanceService.StartOSConversion(req.UserId, req.InstanceId, req.TargetVersion, req.KeepOldBootVolume)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"jobId": job.ID}, "系统转换任务已启动，请等待完成"))
+}
+
+type ConvertOSJobRequest struct {
+	JobId string `json:"jobId" binding:"required"`
+}
+
+// ConvertOSStatus 查询系统转换任务进度
+func (ic *InstanceController) ConvertOSStatus(c *gin.Context) {
+	var req ConvertOSJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, err := ic.instanceService.GetOSConversionJob(req.JobId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "转换任务不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "获取成功"))
+}
+
+// ResumeConvertOS 从上次完成的步骤继续执行失败或中断的系统转换任务
+func (ic *InstanceController) ResumeConvertOS(c *gin.Context) {
+	var req ConvertOSJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, err := ic.instanceService.ResumeOSConversion(req.JobId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "系统转换任务已继续执行"))
+}
+
+type ListConvertOSRequest struct {
+	UserId string `json:"userId" binding:"required"`
+}
+
+// ListConvertOS 列出配置下的系统转换任务
+func (ic *InstanceController) ListConvertOS(c *gin.Context) {
+	var req ListConvertOSRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	jobs, err := ic.instanceService.ListOSConversionJobs(req.UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(jobs, "获取成功"))
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryController struct {
+	discoveryService *services.DiscoveryService
+}
+
+func NewInventoryController(discoveryService *services.DiscoveryService) *InventoryController {
+	return &InventoryController{discoveryService: discoveryService}
+}
+
+type StartDiscoveryRequest struct {
+	ConfigID string `json:"configId" binding:"required"`
+}
+
+// StartDiscovery 启动资源发现任务，扫描配置下所有区间的现有资源
+func (ic *InventoryController) StartDiscovery(c *gin.Context) {
+	var req StartDiscoveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	jobId, err := ic.discoveryService.StartDiscovery(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"jobId": jobId}, "资源发现已启动"))
+}
+
+type DiscoveryStatusRequest struct {
+	JobId string `json:"jobId" binding:"required"`
+}
+
+// DiscoveryStatus 查询资源发现任务进度与结果
+func (ic *InventoryController) DiscoveryStatus(c *gin.Context) {
+	var req DiscoveryStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, ok := ic.discoveryService.GetDiscoveryJob(req.JobId)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "任务不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "success"))
+}
+
+type AdoptResourcesRequest struct {
+	ConfigID string                        `json:"configId" binding:"required"`
+	Items    []services.InventoryAdoptItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// AdoptResources 将选中的资源纳入本地清单
+func (ic *InventoryController) AdoptResources(c *gin.Context) {
+	var req AdoptResourcesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	count, err := ic.discoveryService.AdoptResources(req.ConfigID, req.Items)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]int{"count": count}, "已纳入清单"))
+}
+
+type ListInventoryRequest struct {
+	ConfigID     string `json:"configId"`
+	ResourceType string `json:"resourceType"`
+	Tag          string `json:"tag"`
+}
+
+// ListInventory 获取本地资源清单
+func (ic *InventoryController) ListInventory(c *gin.Context) {
+	var req ListInventoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	items, err := services.ListInventory(req.ConfigID, req.ResourceType, req.Tag)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取清单失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(items, "success"))
+}
+
+type UpdateInventoryItemRequest struct {
+	ID    string `json:"id" binding:"required"`
+	Notes string `json:"notes"`
+	Tags  string `json:"tags"`
+}
+
+// UpdateInventoryItem 更新清单项的备注与标签
+func (ic *InventoryController) UpdateInventoryItem(c *gin.Context) {
+	var req UpdateInventoryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.UpdateInventoryItem(req.ID, req.Notes, req.Tags); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "更新成功"))
+}
+
+type RemoveInventoryItemsRequest struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+// RemoveInventoryItems 从本地清单移除资源，不影响 OCI 上的资源
+func (ic *InventoryController) RemoveInventoryItems(c *gin.Context) {
+	var req RemoveInventoryItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := database.GetDB().Where("id IN ?", req.IDs).Delete(&models.InventoryItem{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "移除失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "移除成功"))
+}
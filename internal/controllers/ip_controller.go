@@ -43,7 +43,7 @@ func (ic *IpController) ChangePublicIp(c *gin.Context) {
 type AttachIpv6Request struct {
 	UserId         string `json:"userId" binding:"required"`
 	VnicId         string `json:"vnicId" binding:"required"`
-	Ipv6SubnetCidr string `json:"ipv6SubnetCidr" binding:"required"`
+	Ipv6SubnetCidr string `json:"ipv6SubnetCidr"` // 为空时从子网的 IPv6 前缀中分配
 }
 
 func (ic *IpController) AttachIpv6(c *gin.Context) {
@@ -60,3 +60,113 @@ func (ic *IpController) AttachIpv6(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.SuccessResponse(nil, "IPv6附加成功"))
 }
+
+type ListIpv6Request struct {
+	UserId   string `json:"userId" binding:"required"`
+	VnicId   string `json:"vnicId"`   // 与 subnetId 二选一
+	SubnetId string `json:"subnetId"` // 与 vnicId 二选一
+	Region   string `json:"region"`
+}
+
+// ListIpv6 列出 VNIC 或子网上的 IPv6 地址
+func (ic *IpController) ListIpv6(c *gin.Context) {
+	var req ListIpv6Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	addresses, err := ic.ipService.ListIpv6(req.UserId, req.VnicId, req.SubnetId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(addresses, "获取成功"))
+}
+
+type Ipv6Request struct {
+	UserId string `json:"userId" binding:"required"`
+	Ipv6Id string `json:"ipv6Id" binding:"required"`
+	Region string `json:"region"`
+}
+
+// DetachIpv6 删除 IPv6 地址
+func (ic *IpController) DetachIpv6(c *gin.Context) {
+	var req Ipv6Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := ic.ipService.DetachIpv6(req.UserId, req.Ipv6Id, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "IPv6删除成功"))
+}
+
+// RotateIpv6 更换 IPv6 地址
+func (ic *IpController) RotateIpv6(c *gin.Context) {
+	var req Ipv6Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	address, err := ic.ipService.RotateIpv6(req.UserId, req.Ipv6Id, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(address, "IPv6更换成功"))
+}
+
+type EnableVcnIpv6Request struct {
+	UserId string `json:"userId" binding:"required"`
+	VcnId  string `json:"vcnId" binding:"required"`
+	Region string `json:"region"`
+}
+
+// EnableVcnIpv6 为 VCN 启用 IPv6
+func (ic *IpController) EnableVcnIpv6(c *gin.Context) {
+	var req EnableVcnIpv6Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	blocks, err := ic.ipService.EnableVcnIpv6(req.UserId, req.VcnId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string][]string{"ipv6CidrBlocks": blocks}, "VCN已启用IPv6"))
+}
+
+type EnableSubnetIpv6Request struct {
+	UserId   string `json:"userId" binding:"required"`
+	SubnetId string `json:"subnetId" binding:"required"`
+	Prefix   string `json:"prefix"` // VCN 前缀中的 /64，为空时自动选择
+	Region   string `json:"region"`
+}
+
+// EnableSubnetIpv6 为子网分配 IPv6 /64 前缀
+func (ic *IpController) EnableSubnetIpv6(c *gin.Context) {
+	var req EnableSubnetIpv6Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	result, err := ic.ipService.EnableSubnetIpv6(req.UserId, req.SubnetId, req.Prefix, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, "子网已启用IPv6"))
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type IPRotationController struct {
+	ipRotationService *services.IPRotationService
+}
+
+func NewIPRotationController(ipRotationService *services.IPRotationService) *IPRotationController {
+	return &IPRotationController{ipRotationService: ipRotationService}
+}
+
+type CreateIPRotationRequest struct {
+	ConfigID   string `json:"configId" binding:"required"`
+	InstanceID string `json:"instanceId" binding:"required"`
+	Cron       string `json:"cron" binding:"required"` // 如 "@daily"、"@weekly" 或 "0 4 * * *"
+}
+
+// CreateIPRotation 创建实例公网 IP 定时更换计划
+func (ic *IPRotationController) CreateIPRotation(c *gin.Context) {
+	var req CreateIPRotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	schedule, err := ic.ipRotationService.CreateIPRotationSchedule(req.ConfigID, req.InstanceID, req.Cron)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(schedule, "计划已创建"))
+}
+
+type ListIPRotationsRequest struct {
+	ConfigID   string `json:"configId"`
+	InstanceID string `json:"instanceId"`
+}
+
+// ListIPRotations 获取公网 IP 定时更换计划及下次执行时间
+func (ic *IPRotationController) ListIPRotations(c *gin.Context) {
+	var req ListIPRotationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	schedules, err := services.ListIPRotationSchedules(req.ConfigID, req.InstanceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取计划失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(schedules, "success"))
+}
+
+type UpdateIPRotationRequest struct {
+	ID      string `json:"id" binding:"required"`
+	Cron    string `json:"cron" binding:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+// UpdateIPRotation 修改公网 IP 定时更换计划
+func (ic *IPRotationController) UpdateIPRotation(c *gin.Context) {
+	var req UpdateIPRotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.UpdateIPRotationSchedule(req.ID, req.Cron, req.Enabled); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "计划已更新"))
+}
+
+type DeleteIPRotationRequest struct {
+	ID string `json:"id" binding:"required"`
+}
+
+// DeleteIPRotation 删除公网 IP 定时更换计划
+func (ic *IPRotationController) DeleteIPRotation(c *gin.Context) {
+	var req DeleteIPRotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.DeleteIPRotationSchedule(req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "删除失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "计划已删除"))
+}
+
+type IPHistoryRequest struct {
+	ConfigID   string `json:"configId"`
+	InstanceID string `json:"instanceId"`
+	Page       int    `json:"page" binding:"required,min=1"`
+	PageSize   int    `json:"pageSize" binding:"required,min=1,max=100"`
+}
+
+// IPHistory 分页获取公网 IP 更换记录，包括手动更换与定时更换
+func (ic *IPRotationController) IPHistory(c *gin.Context) {
+	var req IPHistoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	records, total, err := services.GetPublicIPHistory(req.ConfigID, req.InstanceID, req.Page, req.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取记录失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{
+		"list":     records,
+		"total":    total,
+		"page":     req.Page,
+		"pageSize": req.PageSize,
+	}, "success"))
+}
+
+type BatchIPChangeRequest struct {
+	ConfigID    string   `json:"configId" binding:"required"`
+	InstanceIDs []string `json:"instanceIds" binding:"required,min=1"`
+	Delay       *int     `json:"delay"` // 相邻两次请求的间隔（秒），不传时使用默认值
+}
+
+// StartBatchIPChange 批量更换选中实例的公网 IP，按间隔逐个执行
+func (ic *IPRotationController) StartBatchIPChange(c *gin.Context) {
+	var req BatchIPChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	delay := services.DefaultBatchIPChangeDelay
+	if req.Delay != nil {
+		delay = *req.Delay
+	}
+	jobId, err := ic.ipRotationService.StartBatchIPChange(req.ConfigID, req.InstanceIDs, delay)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"jobId": jobId}, "批量更换已启动"))
+}
+
+type BatchIPChangeStatusRequest struct {
+	JobId string `json:"jobId" binding:"required"`
+}
+
+// BatchIPChangeStatus 查询批量更换进度及各实例的新旧 IP
+func (ic *IPRotationController) BatchIPChangeStatus(c *gin.Context) {
+	var req BatchIPChangeStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, ok := ic.ipRotationService.GetBatchIPChangeJob(req.JobId)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "任务不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "success"))
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type JobController struct {
+	jobService      *services.JobService
+	asyncJobService *services.AsyncJobService
+}
+
+func NewJobController(jobService *services.JobService, asyncJobService *services.AsyncJobService) *JobController {
+	return &JobController{jobService: jobService, asyncJobService: asyncJobService}
+}
+
+// ListJobs 获取已注册的后台作业及其最近一次运行状态
+func (jc *JobController) ListJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse(jc.jobService.ListJobs(), "success"))
+}
+
+type JobHistoryRequest struct {
+	Name     string `json:"name"` // 为空时返回所有作业
+	Page     int    `json:"page" binding:"required,min=1"`
+	PageSize int    `json:"pageSize" binding:"required,min=1,max=100"`
+}
+
+// JobHistory 分页获取作业运行记录
+func (jc *JobController) JobHistory(c *gin.Context) {
+	var req JobHistoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	runs, total, err := services.GetJobHistory(req.Name, req.Page, req.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取运行记录失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{
+		"list":     runs,
+		"total":    total,
+		"page":     req.Page,
+		"pageSize": req.PageSize,
+	}, "success"))
+}
+
+type RunJobRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// RunJob 立即在后台执行作业
+func (jc *JobController) RunJob(c *gin.Context) {
+	var req RunJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := jc.jobService.RunNow(req.Name); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "作业已开始执行"))
+}
+
+type ListAsyncJobsRequest struct {
+	UserId     string `json:"userId"`     // 为空时返回所有配置的任务
+	ResourceId string `json:"resourceId"` // 操作的实例或备份
+	Kind       string `json:"kind"`
+	Status     string `json:"status"`
+	Page       int    `json:"page" binding:"required,min=1"`
+	PageSize   int    `json:"pageSize" binding:"required,min=1,max=100"`
+}
+
+// ListAsyncJobs 分页获取克隆、重建、恢复备份等后台操作任务
+func (jc *JobController) ListAsyncJobs(c *gin.Context) {
+	var req ListAsyncJobsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	jobs, total, err := jc.asyncJobService.List(services.AsyncJobFilter{
+		ConfigID:   req.UserId,
+		ResourceID: req.ResourceId,
+		Kind:       req.Kind,
+		Status:     req.Status,
+	}, req.Page, req.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取任务失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{
+		"list":     jobs,
+		"total":    total,
+		"page":     req.Page,
+		"pageSize": req.PageSize,
+	}, "success"))
+}
+
+type AsyncJobRequest struct {
+	JobId string `json:"jobId" binding:"required"`
+}
+
+// GetAsyncJob 获取后台操作任务的参数、步骤与结果
+func (jc *JobController) GetAsyncJob(c *gin.Context) {
+	var req AsyncJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, err := jc.asyncJobService.Get(req.JobId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "任务不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(job, "success"))
+}
+
+// CancelAsyncJob 取消正在执行的后台操作，已创建的资源不会回滚
+func (jc *JobController) CancelAsyncJob(c *gin.Context) {
+	var req AsyncJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := jc.asyncJobService.Cancel(req.JobId); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "正在取消"))
+}
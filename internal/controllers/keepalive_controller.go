@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type KeepAliveController struct {
+	keepAliveService *services.IdleKeepAliveService
+}
+
+func NewKeepAliveController(keepAliveService *services.IdleKeepAliveService) *KeepAliveController {
+	return &KeepAliveController{keepAliveService: keepAliveService}
+}
+
+type KeepAliveConfigRequest struct {
+	ConfigID string `json:"configId" binding:"required"`
+}
+
+// GetKeepAlive 获取配置的闲置保活设置，未设置时返回空
+func (kc *KeepAliveController) GetKeepAlive(c *gin.Context) {
+	var req KeepAliveConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	setting, err := services.GetIdleKeepAlive(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取设置失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(setting, "success"))
+}
+
+type SaveKeepAliveRequest struct {
+	ConfigID string `json:"configId" binding:"required"`
+	Mode     string `json:"mode" binding:"required"` // notify / workload
+	Enabled  bool   `json:"enabled"`
+}
+
+// SaveKeepAlive 开启或修改配置的闲置保活，workload 模式需要实例启用运行命令插件
+func (kc *KeepAliveController) SaveKeepAlive(c *gin.Context) {
+	var req SaveKeepAliveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	setting, err := services.SaveIdleKeepAlive(req.ConfigID, req.Mode, req.Enabled)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(setting, "设置已保存"))
+}
+
+// DeleteKeepAlive 删除配置的闲置保活设置
+func (kc *KeepAliveController) DeleteKeepAlive(c *gin.Context) {
+	var req KeepAliveConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.DeleteIdleKeepAlive(req.ConfigID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "删除失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "设置已删除"))
+}
+
+// RunKeepAlive 立即执行一次闲置保活检查
+func (kc *KeepAliveController) RunKeepAlive(c *gin.Context) {
+	var req KeepAliveConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	result, err := kc.keepAliveService.RunIdleKeepAlive(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, "success"))
+}
+
+type IdleRiskRequest struct {
+	ConfigID string `json:"configId"` // 为空时返回所有配置
+}
+
+// IdleRisk 按最近 7 天的 CPU、网络与内存利用率评估 Always Free 实例的闲置回收风险
+func (kc *KeepAliveController) IdleRisk(c *gin.Context) {
+	var req IdleRiskRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	query := database.GetDB().Order("create_time DESC")
+	if req.ConfigID != "" {
+		query = query.Where("id = ?", req.ConfigID)
+	}
+	var users []models.OciUser
+	if err := query.Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取配置失败"))
+		return
+	}
+	if req.ConfigID != "" && len(users) == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "配置不存在"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), services.IdleRiskReportTimeout)
+	defer cancel()
+	c.JSON(http.StatusOK, models.SuccessResponse(kc.keepAliveService.IdleRiskReports(ctx, users), "success"))
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type LaunchCleanupController struct {
+	cleanupService *services.LaunchCleanupService
+}
+
+func NewLaunchCleanupController(cleanupService *services.LaunchCleanupService) *LaunchCleanupController {
+	return &LaunchCleanupController{cleanupService: cleanupService}
+}
+
+// GetSettings 获取是否自动清理开机失败的残留资源
+func (lc *LaunchCleanupController) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{"enabled": services.IsLaunchCleanupEnabled()}, "success"))
+}
+
+type LaunchCleanupSettingsRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// UpdateSettings 开启或关闭自动清理
+func (lc *LaunchCleanupController) UpdateSettings(c *gin.Context) {
+	var req LaunchCleanupSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.SetLaunchCleanupEnabled(req.Enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "设置已保存"))
+}
+
+// Preview 列出会被清理的残留资源，不执行删除
+func (lc *LaunchCleanupController) Preview(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	items, err := lc.cleanupService.Scan(ctx, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(items, "success"))
+}
+
+// Run 立即清理残留资源，返回每个资源的处理结果
+func (lc *LaunchCleanupController) Run(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	items, err := lc.cleanupService.Scan(ctx, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(items, "清理完成"))
+}
@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type NLBController struct {
+	nlbService *services.NLBService
+}
+
+func NewNLBController(nlbService *services.NLBService) *NLBController {
+	return &NLBController{nlbService: nlbService}
+}
+
+type ListNLBsRequest struct {
+	UserId        string `json:"userId" binding:"required"`
+	CompartmentId string `json:"compartmentId"` // 为空时使用租户根区间
+	Region        string `json:"region"`
+}
+
+// ListNLBs 列出网络负载均衡器
+func (nc *NLBController) ListNLBs(c *gin.Context) {
+	var req ListNLBsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if !services.IsValidCompartmentID(req.CompartmentId) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "区间 OCID 格式无效"))
+		return
+	}
+
+	nlbs, err := nc.nlbService.ListNLBs(req.UserId, req.CompartmentId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nlbs, "获取成功"))
+}
+
+type NLBRequest struct {
+	UserId string `json:"userId" binding:"required"`
+	NlbId  string `json:"nlbId" binding:"required"`
+	Region string `json:"region"`
+}
+
+// GetNLB 获取网络负载均衡器详情，包括监听器与后端集
+func (nc *NLBController) GetNLB(c *gin.Context) {
+	var req NLBRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	nlb, err := nc.nlbService.GetNLB(req.UserId, req.NlbId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nlb, "获取成功"))
+}
+
+// GetNLBHealth 获取健康检查状态
+func (nc *NLBController) GetNLBHealth(c *gin.Context) {
+	var req NLBRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	health, err := nc.nlbService.GetNLBHealth(req.UserId, req.NlbId, req.Region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(health, "获取成功"))
+}
+
+type CreateNLBRequest struct {
+	UserId      string `json:"userId" binding:"required"`
+	SubnetId    string `json:"subnetId" binding:"required"`
+	DisplayName string `json:"displayName"`
+	IsPrivate   bool   `json:"isPrivate"`
+	Region      string `json:"region"`
+}
+
+// CreateNLB 创建网络负载均衡器
+func (nc *NLBController) CreateNLB(c *gin.Context) {
+	var req CreateNLBRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	nlbId, err := nc.nlbService.CreateNLB(req.UserId, req.Region, services.NLBParams{
+		SubnetID:    req.SubnetId,
+		DisplayName: req.DisplayName,
+		IsPrivate:   req.IsPrivate,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]string{"nlbId": nlbId}, "网络负载均衡器创建中"))
+}
+
+// DeleteNLB 删除网络负载均衡器
+func (nc *NLBController) DeleteNLB(c *gin.Context) {
+	var req NLBRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := nc.nlbService.DeleteNLB(req.UserId, req.NlbId, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "删除中"))
+}
+
+type CreateNLBListenerRequest struct {
+	UserId         string `json:"userId" binding:"required"`
+	NlbId          string `json:"nlbId" binding:"required"`
+	Name           string `json:"name" binding:"required"`
+	Protocol       string `json:"protocol" binding:"omitempty,oneof=tcp udp tcp_and_udp"`
+	Port           int    `json:"port" binding:"gte=0,lte=65535"` // 0 表示所有端口
+	BackendSetName string `json:"backendSetName" binding:"required"`
+	Region         string `json:"region"`
+}
+
+// CreateListener 添加监听器
+func (nc *NLBController) CreateListener(c *gin.Context) {
+	var req CreateNLBListenerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := nc.nlbService.CreateListener(req.UserId, req.NlbId, req.Region, services.NLBListenerParams{
+		Name:           req.Name,
+		Protocol:       req.Protocol,
+		Port:           req.Port,
+		BackendSetName: req.BackendSetName,
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "监听器添加中"))
+}
+
+type NLBNamedRequest struct {
+	UserId string `json:"userId" binding:"required"`
+	NlbId  string `json:"nlbId" binding:"required"`
+	Name   string `json:"name" binding:"required"`
+	Region string `json:"region"`
+}
+
+// DeleteListener 删除监听器
+func (nc *NLBController) DeleteListener(c *gin.Context) {
+	var req NLBNamedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := nc.nlbService.DeleteListener(req.UserId, req.NlbId, req.Name, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "监听器删除中"))
+}
+
+type CreateNLBBackendSetRequest struct {
+	UserId           string `json:"userId" binding:"required"`
+	NlbId            string `json:"nlbId" binding:"required"`
+	Name             string `json:"name" binding:"required"`
+	Policy           string `json:"policy"` // FIVE_TUPLE, THREE_TUPLE, TWO_TUPLE
+	IsPreserveSource bool   `json:"isPreserveSource"`
+	HealthProtocol   string `json:"healthProtocol"` // TCP, UDP, HTTP, HTTPS
+	HealthPort       int    `json:"healthPort" binding:"required,gte=1,lte=65535"`
+	HealthUrlPath    string `json:"healthUrlPath"`
+	Region           string `json:"region"`
+}
+
+// CreateBackendSet 添加后端集
+func (nc *NLBController) CreateBackendSet(c *gin.Context) {
+	var req CreateNLBBackendSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := nc.nlbService.CreateBackendSet(req.UserId, req.NlbId, req.Region, services.NLBBackendSetParams{
+		Name:             req.Name,
+		Policy:           req.Policy,
+		IsPreserveSource: req.IsPreserveSource,
+		HealthProtocol:   req.HealthProtocol,
+		HealthPort:       req.HealthPort,
+		HealthURLPath:    req.HealthUrlPath,
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "后端集添加中"))
+}
+
+// DeleteBackendSet 删除后端集
+func (nc *NLBController) DeleteBackendSet(c *gin.Context) {
+	var req NLBNamedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := nc.nlbService.DeleteBackendSet(req.UserId, req.NlbId, req.Name, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "后端集删除中"))
+}
+
+type CreateNLBBackendRequest struct {
+	UserId         string `json:"userId" binding:"required"`
+	NlbId          string `json:"nlbId" binding:"required"`
+	BackendSetName string `json:"backendSetName" binding:"required"`
+	IpAddress      string `json:"ipAddress" binding:"omitempty,ip"` // 与 targetId 二选一
+	TargetId       string `json:"targetId"`                         // 实例 OCID，与 ipAddress 二选一
+	Port           int    `json:"port" binding:"gte=0,lte=65535"`   // 0 表示与监听器端口相同
+	Weight         int    `json:"weight" binding:"gte=0"`
+	Region         string `json:"region"`
+}
+
+// CreateBackend 向后端集添加后端
+func (nc *NLBController) CreateBackend(c *gin.Context) {
+	var req CreateNLBBackendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := nc.nlbService.CreateBackend(req.UserId, req.NlbId, req.BackendSetName, req.Region, services.NLBBackendParams{
+		IpAddress: req.IpAddress,
+		TargetID:  req.TargetId,
+		Port:      req.Port,
+		Weight:    req.Weight,
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "后端添加中"))
+}
+
+type DeleteNLBBackendRequest struct {
+	UserId         string `json:"userId" binding:"required"`
+	NlbId          string `json:"nlbId" binding:"required"`
+	BackendSetName string `json:"backendSetName" binding:"required"`
+	BackendName    string `json:"backendName" binding:"required"`
+	Region         string `json:"region"`
+}
+
+// DeleteBackend 从后端集删除后端
+func (nc *NLBController) DeleteBackend(c *gin.Context) {
+	var req DeleteNLBBackendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := nc.nlbService.DeleteBackend(req.UserId, req.NlbId, req.BackendSetName, req.BackendName, req.Region); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "后端删除中"))
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/adiecho/oci-panel/internal/database"
@@ -15,6 +17,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/oracle/oci-go-sdk/v65/core"
+	"gorm.io/gorm/clause"
 )
 
 type OciController struct {
@@ -60,6 +63,11 @@ func (oc *OciController) UserPage(c *gin.Context) {
 
 	query.Count(&total)
 	offset := (req.Page - 1) * req.PageSize
+	// 当前用户收藏的配置排在最前
+	favorites := services.FavoriteConfigIDs(c.GetString("username"))
+	if len(favorites) > 0 {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{SQL: "CASE WHEN id IN ? THEN 0 ELSE 1 END", Vars: []interface{}{favorites}}})
+	}
 	query.Order("create_time DESC").Limit(req.PageSize).Offset(offset).Find(&users)
 
 	responseList := make([]models.OciUserListResponse, len(users))
@@ -67,6 +75,7 @@ func (oc *OciController) UserPage(c *gin.Context) {
 
 	if cacheEnabled {
 		// 从数据库缓存读取
+		staleMinutes := oc.schedulerService.GetCacheStaleMinutes()
 		for i, user := range users {
 			tenantCreateTime := ""
 			if user.TenantCreateTime != nil {
@@ -88,7 +97,11 @@ func (oc *OciController) UserPage(c *gin.Context) {
 			if err == nil {
 				responseList[i].InstanceCount = cache.InstanceCount
 				responseList[i].RunningInstances = cache.RunningInstances
+				responseList[i].LastUpdated = cache.UpdateTime.Format("2006-01-02 15:04:05")
+			} else {
+				cache = nil
 			}
+			responseList[i].Stale = services.IsCacheStale(cache, staleMinutes)
 		}
 	} else {
 		// 实时获取（并发）
@@ -134,12 +147,17 @@ func (oc *OciController) UserPage(c *gin.Context) {
 			}
 		}
 
+		now := time.Now().Format("2006-01-02 15:04:05")
 		for i := 0; i < len(users); i++ {
 			result := <-resultChan
 			responseList[result.index].InstanceCount = result.instanceCount
 			responseList[result.index].RunningInstances = result.runningInstances
+			responseList[result.index].LastUpdated = now
 		}
 	}
+	for i := range responseList {
+		responseList[i].Favorite = slices.Contains(favorites, responseList[i].ID)
+	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse(UserPageResponse{
 		List:     responseList,
@@ -229,6 +247,27 @@ func (oc *OciController) UpdateCfgName(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Updated successfully"))
 }
 
+type UpdateCfgDefaultsRequest struct {
+	ID       string                   `json:"id" binding:"required"`
+	Defaults models.OciConfigDefaults `json:"defaults"`
+}
+
+// UpdateCfgDefaults 更新配置级默认开机参数
+func (oc *OciController) UpdateCfgDefaults(c *gin.Context) {
+	var req UpdateCfgDefaultsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.SaveConfigDefaults(req.ID, req.Defaults); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(req.Defaults, "默认参数已保存"))
+}
+
 type RemoveCfgRequest struct {
 	IDs []string `json:"ids" binding:"required"`
 }
@@ -257,6 +296,24 @@ func (oc *OciController) RemoveCfg(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "Failed to delete"))
 		return
 	}
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.InventoryItem{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.AccessLink{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.PowerSchedule{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.IPRotationSchedule{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.PublicIPHistory{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.BackupSchedule{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.BackupRetentionPolicy{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.OciEvent{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.InstanceSSHPort{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.InstanceTuning{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.OSConversionJob{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.AutoRescueJob{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.AsyncJob{})
+	services.DeleteTerminalRecordings(req.IDs)
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.TrafficQuota{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.IdleKeepAlive{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.Favorite{})
+	database.GetDB().Where("config_id IN ?", req.IDs).Delete(&models.InstanceListSnapshot{})
 
 	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Deleted successfully"))
 }
@@ -269,7 +326,10 @@ type CreateInstanceRequest struct {
 	Disk            int     `json:"disk"`
 	Architecture    string  `json:"architecture"`
 	OperationSystem string  `json:"operationSystem"`
-	SSHKeyID        string  `json:"sshKeyId" binding:"required"`
+	ImageID         string  `json:"imageId"`
+	CompartmentID   string  `json:"compartmentId"`
+	SSHKeyID        string  `json:"sshKeyId"` // 为空时使用配置的默认SSH密钥
+	UserData        string  `json:"userData"` // cloud-init 脚本，base64 或明文
 }
 
 func (oc *OciController) CreateInstance(c *gin.Context) {
@@ -279,13 +339,33 @@ func (oc *OciController) CreateInstance(c *gin.Context) {
 		return
 	}
 
+	var user models.OciUser
+	if err := database.GetDB().First(&user, "id = ?", req.UserID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "配置不存在"))
+		return
+	}
+
+	launch := services.LaunchDefaults{SSHKeyID: req.SSHKeyID, ImageID: req.ImageID, OperationSystem: req.OperationSystem}
+	services.ApplyConfigDefaults(&user, req.OciRegion, &launch)
+
 	// 验证SSH密钥是否存在
 	var sshKey models.SSHKey
-	if err := database.GetDB().First(&sshKey, "id = ?", req.SSHKeyID).Error; err != nil {
+	if err := database.GetDB().First(&sshKey, "id = ?", launch.SSHKeyID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "SSH密钥不存在"))
 		return
 	}
 
+	if !services.IsValidCompartmentID(req.CompartmentID) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "区间ID无效"))
+		return
+	}
+
+	userData, err := services.NormalizeUserData(req.UserData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
 	task := models.OciCreateTask{
 		ID:              uuid.New().String(),
 		UserID:          req.UserID,
@@ -293,9 +373,13 @@ func (oc *OciController) CreateInstance(c *gin.Context) {
 		Ocpus:           req.Ocpus,
 		Memory:          req.Memory,
 		Disk:            req.Disk,
+		Username:        user.Username,
 		Architecture:    req.Architecture,
-		OperationSystem: req.OperationSystem,
-		SSHKeyID:        req.SSHKeyID,
+		OperationSystem: launch.OperationSystem,
+		ImageId:         launch.ImageID,
+		CompartmentID:   req.CompartmentID,
+		SSHKeyID:        launch.SSHKeyID,
+		UserData:        userData,
 		CreateTime:      time.Now(),
 	}
 
@@ -407,6 +491,7 @@ func (oc *OciController) GetConfigDetails(c *gin.Context) {
 		KeyPath:     filepath.Base(user.OciKeyPath),
 		Region:      user.OciRegion,
 		CreateTime:  user.CreateTime.Format("2006-01-02 15:04:05"),
+		Defaults:    services.ConfigDefaults(&user),
 		Instances:   []models.InstanceInfo{},
 		Volumes:     []models.VolumeInfo{},
 		VCNs:        []models.VCNInfo{},
@@ -415,6 +500,84 @@ func (oc *OciController) GetConfigDetails(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(details, "Success"))
 }
 
+// GetConfigSummary 获取配置的资源概览（实例状态、算力/存储用量与 Always Free 额度、本月流量）
+func (oc *OciController) GetConfigSummary(c *gin.Context) {
+	var req GetConfigDetailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", req.ConfigID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "Configuration not found"))
+		return
+	}
+
+	summary, err := oc.ociService.GetConfigSummary(context.Background(), &user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取配置概览失败: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(summary, "Success"))
+}
+
+// GetCredentialsHealth 检查所有配置的 API 凭据健康状况
+func (oc *OciController) GetCredentialsHealth(c *gin.Context) {
+	report, err := oc.ociService.GetCredentialsHealthReport(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "检查凭据失败: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(report, "Success"))
+}
+
+type CheckConfigsAliveRequest struct {
+	ConfigIDs []string `json:"configIds"` // 为空时检查所有配置
+}
+
+// CheckConfigsAlive 批量检查配置的 API 凭据是否可用
+func (oc *OciController) CheckConfigsAlive(c *gin.Context) {
+	var req CheckConfigsAliveRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	results, err := oc.ociService.CheckConfigsAlive(context.Background(), req.ConfigIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "检查配置失败: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(results, "Success"))
+}
+
+// GetRegionLatency 测量面板主机到配置各已订阅区域的延迟
+func (oc *OciController) GetRegionLatency(c *gin.Context) {
+	var req GetConfigDetailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", req.ConfigID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "Configuration not found"))
+		return
+	}
+
+	results, err := oc.ociService.ProbeRegionLatency(context.Background(), &user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "测量区域延迟失败: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(results, "Success"))
+}
+
 type GetResourceRequest struct {
 	ConfigID   string `json:"configId" binding:"required"`
 	ClearCache bool   `json:"clearCache"`
@@ -437,9 +600,12 @@ func (oc *OciController) GetConfigInstances(c *gin.Context) {
 	if oc.schedulerService.IsCacheEnabled() {
 		cache, err := oc.schedulerService.GetConfigCache(req.ConfigID)
 		if err == nil && cache.InstancesData != "" {
+			if notModified(c, cacheETag(c, cache, "instances")) {
+				return
+			}
 			var instances []models.InstanceInfo
 			if json.Unmarshal([]byte(cache.InstancesData), &instances) == nil {
-				c.JSON(http.StatusOK, models.SuccessResponse(instances, "Success (cached)"))
+				c.JSON(http.StatusOK, models.CachedResponse(selectFields(c, instances), "Success (cached)", cache.UpdateTime))
 				return
 			}
 		}
@@ -469,7 +635,7 @@ func (oc *OciController) GetConfigInstances(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(instances, "Success"))
+	c.JSON(http.StatusOK, models.CachedResponse(selectFields(c, instances), "Success", time.Now()))
 }
 
 // GetConfigVolumes 获取配置的存储卷列表
@@ -488,9 +654,12 @@ func (oc *OciController) GetConfigVolumes(c *gin.Context) {
 	if oc.schedulerService.IsCacheEnabled() {
 		cache, err := oc.schedulerService.GetConfigCache(req.ConfigID)
 		if err == nil && cache.VolumesData != "" {
+			if notModified(c, cacheETag(c, cache, "volumes")) {
+				return
+			}
 			var volumes []models.VolumeInfo
 			if json.Unmarshal([]byte(cache.VolumesData), &volumes) == nil {
-				c.JSON(http.StatusOK, models.SuccessResponse(volumes, "Success (cached)"))
+				c.JSON(http.StatusOK, models.CachedResponse(selectFields(c, volumes), "Success (cached)", cache.UpdateTime))
 				return
 			}
 		}
@@ -513,7 +682,7 @@ func (oc *OciController) GetConfigVolumes(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(volumes, "Success"))
+	c.JSON(http.StatusOK, models.CachedResponse(selectFields(c, volumes), "Success", time.Now()))
 }
 
 // GetConfigVCNs 获取配置的VCN列表
@@ -532,9 +701,12 @@ func (oc *OciController) GetConfigVCNs(c *gin.Context) {
 	if oc.schedulerService.IsCacheEnabled() {
 		cache, err := oc.schedulerService.GetConfigCache(req.ConfigID)
 		if err == nil && cache.VcnsData != "" {
+			if notModified(c, cacheETag(c, cache, "vcns")) {
+				return
+			}
 			var vcns []models.VCNInfo
 			if json.Unmarshal([]byte(cache.VcnsData), &vcns) == nil {
-				c.JSON(http.StatusOK, models.SuccessResponse(vcns, "Success (cached)"))
+				c.JSON(http.StatusOK, models.CachedResponse(vcns, "Success (cached)", cache.UpdateTime))
 				return
 			}
 		}
@@ -557,7 +729,32 @@ func (oc *OciController) GetConfigVCNs(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(vcns, "Success"))
+	c.JSON(http.StatusOK, models.CachedResponse(vcns, "Success", time.Now()))
+}
+
+// ListCompartments 获取配置的区间树，用于创建实例时选择目标区间
+func (oc *OciController) ListCompartments(c *gin.Context) {
+	var req GetResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", req.ConfigID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "Configuration not found"))
+		return
+	}
+
+	ctx := context.Background()
+	tree, err := oc.ociService.ListCompartments(ctx, &user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(tree, "Success"))
 }
 
 // ClearConfigCache 刷新配置的缓存
@@ -592,7 +789,7 @@ func (oc *OciController) GetTenantInfo(c *gin.Context) {
 		if err == nil && cache.TenantData != "" {
 			var tenantInfo models.TenantInfo
 			if json.Unmarshal([]byte(cache.TenantData), &tenantInfo) == nil {
-				c.JSON(http.StatusOK, models.SuccessResponse(tenantInfo, "Success (cached)"))
+				c.JSON(http.StatusOK, models.CachedResponse(tenantInfo, "Success (cached)", cache.UpdateTime))
 				return
 			}
 		}
@@ -613,7 +810,7 @@ func (oc *OciController) GetTenantInfo(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(tenantInfo, "Success"))
+	c.JSON(http.StatusOK, models.CachedResponse(tenantInfo, "Success", time.Now()))
 }
 
 type GetTrafficDataRequest struct {
@@ -1114,3 +1311,71 @@ func (oc *OciController) ListImages(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.SuccessResponse(images, "获取镜像列表成功"))
 }
+
+// ListPlatformImagesRequest 获取平台镜像目录请求
+type ListPlatformImagesRequest struct {
+	ConfigID     string `json:"configId" binding:"required"`
+	Region       string `json:"region"`       // 为空时使用配置的区域
+	Shape        string `json:"shape"`        // 为空时按 architecture 选择开机任务使用的 Shape
+	Architecture string `json:"architecture"` // ARM 或 AMD
+	ClearCache   bool   `json:"clearCache"`
+}
+
+// ListPlatformImages 获取平台镜像目录，按系统与版本分组并附带镜像 OCID，供开机任务选择
+func (oc *OciController) ListPlatformImages(c *gin.Context) {
+	var req ListPlatformImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", req.ConfigID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "Configuration not found"))
+		return
+	}
+	region := req.Region
+	if region == "" {
+		region = user.OciRegion
+	}
+
+	groups, err := oc.ociService.ListPlatformImages(context.Background(), &user, region, req.Shape, req.Architecture, req.ClearCache)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(groups, "获取平台镜像成功"))
+}
+
+// CheckCapacityRequest 查询容量请求
+type CheckCapacityRequest struct {
+	ConfigID     string   `json:"configId" binding:"required"`
+	Regions      []string `json:"regions"`      // 为空时查询所有已订阅区域
+	Shape        string   `json:"shape"`        // 为空时按 architecture 选择开机任务使用的 Shape
+	Architecture string   `json:"architecture"` // ARM 或 AMD
+	Ocpus        float64  `json:"ocpus"`
+	Memory       float64  `json:"memory"`
+}
+
+// CheckCapacity 通过容量报告查询各区域、各可用域的剩余容量，不创建任何资源
+func (oc *OciController) CheckCapacity(c *gin.Context) {
+	var req CheckCapacityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", req.ConfigID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "Configuration not found"))
+		return
+	}
+	probes, err := oc.ociService.CheckCapacity(&user, req.Regions, req.Shape, req.Architecture, req.Ocpus, req.Memory)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(probes, "查询容量成功"))
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// 单次推送消息的大小上限
+const maxEventBodySize = 1 << 20
+
+type OCIEventController struct {
+	eventService *services.OCIEventService
+}
+
+func NewOCIEventController(eventService *services.OCIEventService) *OCIEventController {
+	return &OCIEventController{eventService: eventService}
+}
+
+// Receive 接收 OCI Notifications 推送，地址签名校验失败时返回 403
+func (ec *OCIEventController) Receive(c *gin.Context) {
+	configID := c.Param("configId")
+	if !services.VerifyEventWebhook(configID, c.Param("signature")) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse(403, "签名无效"))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxEventBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := ec.eventService.HandleDelivery(configID, c.Request.Header, body); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "success"))
+}
+
+type EventWebhookRequest struct {
+	ConfigID string `json:"configId" binding:"required"`
+}
+
+// GetWebhookURL 获取配置的事件推送地址，用于在 OCI 通知中创建 HTTPS 订阅
+func (ec *OCIEventController) GetWebhookURL(c *gin.Context) {
+	var req EventWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	path, err := services.EventWebhookPath(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+	// 未配置 public_url 时只返回路径，由用户拼接面板地址
+	url, _ := ec.eventService.EventWebhookURL(req.ConfigID)
+
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{"path": path, "url": url}, "success"))
+}
+
+// Bootstrap 在配置所在区域创建通知主题、事件规则与推送订阅
+func (ec *OCIEventController) Bootstrap(c *gin.Context) {
+	var req EventWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	result, err := ec.eventService.BootstrapEventDelivery(context.Background(), req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, "success"))
+}
+
+type ListEventsRequest struct {
+	ConfigID string `json:"configId"`
+	Limit    int    `json:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// ListEvents 获取最近收到的 OCI 事件
+func (ec *OCIEventController) ListEvents(c *gin.Context) {
+	var req ListEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 100
+	}
+
+	events, err := services.ListOCIEvents(req.ConfigID, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取事件失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(events, "success"))
+}
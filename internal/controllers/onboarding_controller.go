@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/middleware"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OnboardingController struct {
+	onboardingService *services.OnboardingService
+}
+
+func NewOnboardingController(onboardingService *services.OnboardingService) *OnboardingController {
+	return &OnboardingController{
+		onboardingService: onboardingService,
+	}
+}
+
+// GetStatus 获取首次使用引导进度
+func (oc *OnboardingController) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse(oc.onboardingService.GetStatus(), "success"))
+}
+
+type OnboardingAdminRequest struct {
+	Account  string `json:"account" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// SetupAdmin 设置管理员账号，成功后返回登录令牌供后续步骤使用
+func (oc *OnboardingController) SetupAdmin(c *gin.Context) {
+	var req OnboardingAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := oc.onboardingService.SetupAdmin(req.Account, req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	token, err := middleware.GenerateToken(req.Account)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "Failed to generate token"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(LoginResponse{
+		Token:    token,
+		Username: req.Account,
+	}, "管理员设置成功"))
+}
+
+type OnboardingOciConfigRequest struct {
+	Username       string `json:"username" binding:"required"`
+	OciTenantID    string `json:"ociTenantId" binding:"required"`
+	OciUserID      string `json:"ociUserId" binding:"required"`
+	OciFingerprint string `json:"ociFingerprint" binding:"required"`
+	OciRegion      string `json:"ociRegion" binding:"required"`
+	PrivateKey     string `json:"privateKey"`
+	OciKeyPath     string `json:"ociKeyPath"`
+}
+
+// ImportOciConfig 导入并验证第一个 OCI 配置
+func (oc *OnboardingController) ImportOciConfig(c *gin.Context) {
+	var req OnboardingOciConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	user, err := oc.onboardingService.ImportOciConfig(services.OnboardingOciConfig{
+		Username:       req.Username,
+		OciTenantID:    req.OciTenantID,
+		OciUserID:      req.OciUserID,
+		OciFingerprint: req.OciFingerprint,
+		OciRegion:      req.OciRegion,
+		PrivateKey:     req.PrivateKey,
+		OciKeyPath:     req.OciKeyPath,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(user, "配置导入成功"))
+}
+
+type OnboardingSSHKeyRequest struct {
+	Name string `json:"name"`
+}
+
+// GenerateSSHKey 生成 SSH 密钥，私钥仅在首次生成时返回
+func (oc *OnboardingController) GenerateSSHKey(c *gin.Context) {
+	var req OnboardingSSHKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if req.Name == "" {
+		req.Name = "oci-panel"
+	}
+
+	result, err := oc.onboardingService.GenerateSSHKey(req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, "密钥生成成功"))
+}
+
+type OnboardingTelegramRequest struct {
+	BotToken string `json:"botToken"`
+	ChatID   string `json:"chatId"`
+	Skip     bool   `json:"skip"`
+}
+
+// SetupTelegram 配置 Telegram 并发送测试消息，可跳过
+func (oc *OnboardingController) SetupTelegram(c *gin.Context) {
+	var req OnboardingTelegramRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if !req.Skip && (req.BotToken == "" || req.ChatID == "") {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "请填写 Bot Token 和 Chat ID"))
+		return
+	}
+
+	if err := oc.onboardingService.SetupTelegram(req.BotToken, req.ChatID, req.Skip); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Telegram 设置完成"))
+}
+
+// Complete 结束首次使用引导
+func (oc *OnboardingController) Complete(c *gin.Context) {
+	if err := oc.onboardingService.Complete(); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(oc.onboardingService.GetStatus(), "引导已完成"))
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PostActionController struct{}
+
+func NewPostActionController() *PostActionController {
+	return &PostActionController{}
+}
+
+type SavePostActionRequest struct {
+	ID          string `json:"id"` // 为空时创建
+	Name        string `json:"name" binding:"required"`
+	Type        string `json:"type" binding:"required"` // webhook / script
+	URL         string `json:"url"`
+	Headers     string `json:"headers"` // 每行一个 "Key: Value"
+	Script      string `json:"script"`
+	Timeout     int    `json:"timeout"` // 超时秒数，为 0 时使用默认值
+	Description string `json:"description"`
+}
+
+func (pc *PostActionController) CreatePostAction(c *gin.Context) {
+	var req SavePostActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	action := &models.PostProvisionAction{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Type:        req.Type,
+		URL:         strings.TrimSpace(req.URL),
+		Headers:     req.Headers,
+		Script:      req.Script,
+		Timeout:     req.Timeout,
+		Description: req.Description,
+		CreateTime:  time.Now(),
+	}
+	if err := services.ValidatePostAction(action); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := database.GetDB().Create(action).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "创建动作失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(action, "创建成功"))
+}
+
+func (pc *PostActionController) UpdatePostAction(c *gin.Context) {
+	var req SavePostActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	var action models.PostProvisionAction
+	if err := database.GetDB().First(&action, "id = ?", req.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "动作不存在"))
+		return
+	}
+
+	action.Name = req.Name
+	action.Type = req.Type
+	action.URL = strings.TrimSpace(req.URL)
+	action.Headers = req.Headers
+	action.Script = req.Script
+	action.Timeout = req.Timeout
+	action.Description = req.Description
+	if err := services.ValidatePostAction(&action); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := database.GetDB().Save(&action).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "更新失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "更新成功"))
+}
+
+type DeletePostActionRequest struct {
+	ID string `json:"id" binding:"required"`
+}
+
+// DeletePostAction 删除动作，并解除引用该动作的任务
+func (pc *PostActionController) DeletePostAction(c *gin.Context) {
+	var req DeletePostActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	db := database.GetDB()
+	if err := db.Delete(&models.PostProvisionAction{}, "id = ?", req.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "删除失败"))
+		return
+	}
+	db.Model(&models.OciCreateTask{}).Where("post_action_id = ?", req.ID).Update("post_action_id", "")
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "删除成功"))
+}
+
+func (pc *PostActionController) ListPostActions(c *gin.Context) {
+	var actions []models.PostProvisionAction
+	if err := database.GetDB().Order("create_time DESC").Find(&actions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取列表失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(actions, "success"))
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PowerScheduleController struct {
+	powerScheduleService *services.PowerScheduleService
+}
+
+func NewPowerScheduleController(powerScheduleService *services.PowerScheduleService) *PowerScheduleController {
+	return &PowerScheduleController{powerScheduleService: powerScheduleService}
+}
+
+type CreatePowerScheduleRequest struct {
+	ConfigID   string `json:"configId" binding:"required"`
+	InstanceID string `json:"instanceId" binding:"required"`
+	Action     string `json:"action" binding:"required"` // start / stop
+	Cron       string `json:"cron" binding:"required"`   // 如 "0 1 * * *" 表示每天 01:00
+}
+
+// CreatePowerSchedule 创建实例定时开关机计划
+func (pc *PowerScheduleController) CreatePowerSchedule(c *gin.Context) {
+	var req CreatePowerScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	schedule, err := pc.powerScheduleService.CreatePowerSchedule(req.ConfigID, req.InstanceID, req.Action, req.Cron)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(schedule, "计划已创建"))
+}
+
+type ListPowerSchedulesRequest struct {
+	ConfigID   string `json:"configId"`
+	InstanceID string `json:"instanceId"`
+}
+
+// ListPowerSchedules 获取定时开关机计划及下次执行时间
+func (pc *PowerScheduleController) ListPowerSchedules(c *gin.Context) {
+	var req ListPowerSchedulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	schedules, err := services.ListPowerSchedules(req.ConfigID, req.InstanceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取计划失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(schedules, "success"))
+}
+
+type UpdatePowerScheduleRequest struct {
+	ID      string `json:"id" binding:"required"`
+	Action  string `json:"action" binding:"required"`
+	Cron    string `json:"cron" binding:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+// UpdatePowerSchedule 修改定时开关机计划
+func (pc *PowerScheduleController) UpdatePowerSchedule(c *gin.Context) {
+	var req UpdatePowerScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.UpdatePowerSchedule(req.ID, req.Action, req.Cron, req.Enabled); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "计划已更新"))
+}
+
+type DeletePowerScheduleRequest struct {
+	ID string `json:"id" binding:"required"`
+}
+
+// DeletePowerSchedule 删除定时开关机计划
+func (pc *PowerScheduleController) DeletePowerSchedule(c *gin.Context) {
+	var req DeletePowerScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.DeletePowerSchedule(req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "删除失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "计划已删除"))
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportExportController struct {
+	reportExportService *services.ReportExportService
+}
+
+func NewReportExportController(reportExportService *services.ReportExportService) *ReportExportController {
+	return &ReportExportController{reportExportService: reportExportService}
+}
+
+// GetSettings 获取报表导出设置
+func (rc *ReportExportController) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse(services.GetReportExportSettings(), "success"))
+}
+
+// SaveSettings 保存报表导出设置
+func (rc *ReportExportController) SaveSettings(c *gin.Context) {
+	var req services.ReportExportSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.SaveReportExportSettings(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(req, "设置已保存"))
+}
+
+// Preview 生成报表但不上传，用于查看导出的内容
+func (rc *ReportExportController) Preview(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	report, err := rc.reportExportService.BuildReport(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(report, "success"))
+}
+
+// Run 立即导出报表到对象存储
+func (rc *ReportExportController) Run(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	objects, err := rc.reportExportService.Export(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ResponseData{Code: 500, Message: err.Error(), Data: objects})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(objects, "报表已导出"))
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SecurityAuditController struct {
+	securityAuditService *services.SecurityAuditService
+}
+
+func NewSecurityAuditController(securityAuditService *services.SecurityAuditService) *SecurityAuditController {
+	return &SecurityAuditController{securityAuditService: securityAuditService}
+}
+
+type SecurityFindingsRequest struct {
+	ConfigID string `json:"configId"` // 为空时返回所有配置
+}
+
+type SecurityReportResponse struct {
+	LastRun  string                   `json:"lastRun"` // 上次巡检时间，从未巡检时为空
+	Findings []models.SecurityFinding `json:"findings"`
+}
+
+// GetReport 获取最近一次巡检记录的风险规则
+func (sc *SecurityAuditController) GetReport(c *gin.Context) {
+	var req SecurityFindingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	findings, err := services.ListSecurityFindings(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取巡检报告失败"))
+		return
+	}
+
+	var setting models.SysSetting
+	database.GetDB().Where("key = ?", services.SettingSecurityAuditLastRun).First(&setting)
+
+	c.JSON(http.StatusOK, models.SuccessResponse(SecurityReportResponse{
+		LastRun:  setting.Value,
+		Findings: findings,
+	}, "success"))
+}
+
+// Scan 立即巡检所有配置的安全列表，不发送通知
+func (sc *SecurityAuditController) Scan(c *gin.Context) {
+	report, err := sc.securityAuditService.Run(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "安全巡检失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(report, "安全巡检完成"))
+}
+
+type RemediateFindingRequest struct {
+	ID     string `json:"id" binding:"required"`
+	Source string `json:"source"` // 为空时删除规则，否则将来源限制为该 CIDR
+}
+
+// Remediate 一键修复风险规则
+func (sc *SecurityAuditController) Remediate(c *gin.Context) {
+	var req RemediateFindingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := sc.securityAuditService.Remediate(req.ID, req.Source); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "修复成功"))
+}
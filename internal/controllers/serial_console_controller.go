@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+type SerialConsoleController struct {
+	consoleService *services.SerialConsoleService
+}
+
+func NewSerialConsoleController(consoleService *services.SerialConsoleService) *SerialConsoleController {
+	return &SerialConsoleController{consoleService: consoleService}
+}
+
+type CreateSerialConsoleRequest struct {
+	UserId     string `json:"userId" binding:"required"`
+	InstanceId string `json:"instanceId" binding:"required"`
+}
+
+// Create 创建实例串口控制台连接，返回连接 /ws/console 所需的一次性令牌
+func (sc *SerialConsoleController) Create(c *gin.Context) {
+	var req CreateSerialConsoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	session, err := sc.consoleService.CreateSession(req.UserId, req.InstanceId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(session, "控制台连接已创建"))
+}
+
+type CloseSerialConsoleRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Close 结束串口控制台会话并删除控制台连接
+func (sc *SerialConsoleController) Close(c *gin.Context) {
+	var req CloseSerialConsoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := sc.consoleService.CloseSession(req.Token); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "控制台连接已删除"))
+}
+
+// Attach 将 WebSocket 桥接到实例串口控制台
+// 浏览器无法在 WebSocket 握手中携带 Authorization 头，通过创建会话时返回的一次性令牌鉴权
+func (sc *SerialConsoleController) Attach(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse(401, "Unauthorized"))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	if err := sc.consoleService.Attach(token, conn); err != nil {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()))
+	}
+}
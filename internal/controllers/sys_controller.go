@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"image/png"
+	"io"
 	"net/http"
 
 	"github.com/adiecho/oci-panel/internal/config"
@@ -16,14 +17,18 @@ import (
 )
 
 type SysController struct {
-	cfg              *config.Config
-	schedulerService *services.SchedulerService
+	cfg                 *config.Config
+	schedulerService    *services.SchedulerService
+	diagnosticsService  *services.DiagnosticsService
+	housekeepingService *services.HousekeepingService
 }
 
-func NewSysController(cfg *config.Config, schedulerService *services.SchedulerService) *SysController {
+func NewSysController(cfg *config.Config, schedulerService *services.SchedulerService, diagnosticsService *services.DiagnosticsService, housekeepingService *services.HousekeepingService) *SysController {
 	return &SysController{
-		cfg:              cfg,
-		schedulerService: schedulerService,
+		cfg:                 cfg,
+		schedulerService:    schedulerService,
+		diagnosticsService:  diagnosticsService,
+		housekeepingService: housekeepingService,
 	}
 }
 
@@ -47,7 +52,7 @@ func (sc *SysController) Login(c *gin.Context) {
 		return
 	}
 
-	if req.Account != sc.cfg.Web.Account || req.Password != sc.cfg.Web.Password {
+	if !services.VerifyAdminCredentials(sc.cfg, req.Account, req.Password) {
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse(401, "Invalid credentials"))
 		return
 	}
@@ -91,8 +96,10 @@ func (sc *SysController) Login(c *gin.Context) {
 }
 
 type GlanceResponse struct {
-	TotalConfigs int64 `json:"totalConfigs"`
-	TotalTasks   int64 `json:"totalTasks"`
+	TotalConfigs      int64                  `json:"totalConfigs"`
+	TotalTasks        int64                  `json:"totalTasks"`
+	CacheStaleMinutes int                    `json:"cacheStaleMinutes"`
+	StaleConfigs      []services.StaleConfig `json:"staleConfigs"` // 缓存已过期的配置，可通过 refreshStaleCache 一键刷新；未启用缓存时为空
 }
 
 func (sc *SysController) GetGlance(c *gin.Context) {
@@ -104,29 +111,45 @@ func (sc *SysController) GetGlance(c *gin.Context) {
 	var totalTasks int64
 	db.Model(&models.OciCreateTask{}).Count(&totalTasks)
 
+	staleConfigs := []services.StaleConfig{}
+	if sc.schedulerService.IsCacheEnabled() {
+		if list, err := sc.schedulerService.GetStaleConfigs(); err == nil {
+			staleConfigs = list
+		}
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse(GlanceResponse{
-		TotalConfigs: totalConfigs,
-		TotalTasks:   totalTasks,
+		TotalConfigs:      totalConfigs,
+		TotalTasks:        totalTasks,
+		CacheStaleMinutes: sc.schedulerService.GetCacheStaleMinutes(),
+		StaleConfigs:      staleConfigs,
 	}, "success"))
 }
 
 type SysCfgResponse struct {
-	LogLevel      string `json:"logLevel"`
-	CacheEnabled  bool   `json:"cacheEnabled"`
-	CacheInterval int    `json:"cacheInterval"`
+	LogLevel             string `json:"logLevel"`
+	CacheEnabled         bool   `json:"cacheEnabled"`
+	CacheInterval        int    `json:"cacheInterval"`
+	CacheStaleMinutes    int    `json:"cacheStaleMinutes"`    // 缓存超过该时间未更新视为过期
+	TaskLogRetentionDays int    `json:"taskLogRetentionDays"` // 任务执行日志保留天数，0 表示永久保留
+	TaskLogMaxRows       int    `json:"taskLogMaxRows"`       // 每个任务保留的执行日志条数，0 表示不限制
 }
 
 func (sc *SysController) GetSysCfg(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(SysCfgResponse{
-		LogLevel:      sc.cfg.Logging.Level,
-		CacheEnabled:  sc.schedulerService.IsCacheEnabled(),
-		CacheInterval: sc.schedulerService.GetCacheInterval(),
+		LogLevel:             sc.cfg.Logging.Level,
+		CacheEnabled:         sc.schedulerService.IsCacheEnabled(),
+		CacheInterval:        sc.schedulerService.GetCacheInterval(),
+		CacheStaleMinutes:    sc.schedulerService.GetCacheStaleMinutes(),
+		TaskLogRetentionDays: services.GetTaskLogRetentionDays(),
+		TaskLogMaxRows:       services.GetTaskLogMaxRows(),
 	}, "success"))
 }
 
 type UpdateCacheCfgRequest struct {
-	CacheEnabled  bool `json:"cacheEnabled"`
-	CacheInterval int  `json:"cacheInterval"`
+	CacheEnabled      bool `json:"cacheEnabled"`
+	CacheInterval     int  `json:"cacheInterval"`
+	CacheStaleMinutes int  `json:"cacheStaleMinutes"` // 为 0 时不修改
 }
 
 func (sc *SysController) UpdateCacheCfg(c *gin.Context) {
@@ -148,6 +171,13 @@ func (sc *SysController) UpdateCacheCfg(c *gin.Context) {
 		}
 	}
 
+	if req.CacheStaleMinutes > 0 {
+		if err := sc.schedulerService.SetCacheStaleMinutes(req.CacheStaleMinutes); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "Failed to update cache stale threshold"))
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Cache configuration updated"))
 }
 
@@ -161,6 +191,21 @@ func (sc *SysController) RefreshCache(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(nil, "Cache refresh started"))
 }
 
+// RefreshStaleCache 刷新所有缓存已过期配置的缓存
+func (sc *SysController) RefreshStaleCache(c *gin.Context) {
+	if !sc.schedulerService.IsCacheEnabled() {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "Cache is not enabled"))
+		return
+	}
+
+	count, err := sc.schedulerService.RefreshStaleCaches()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{"count": count}, "Cache refresh started"))
+}
+
 const (
 	MfaEnabledKey = "mfa_enabled"
 	MfaSecretKey  = "mfa_secret"
@@ -315,3 +360,72 @@ func (sc *SysController) CheckMfaCode(c *gin.Context) {
 		NeedMFA:  false,
 	}, "MFA verification successful"))
 }
+
+// Diagnostics 获取系统自检结果，refresh=true 时重新执行检查
+func (sc *SysController) Diagnostics(c *gin.Context) {
+	var report *services.DiagnosticsReport
+	if c.Query("refresh") == "true" {
+		report = sc.diagnosticsService.Run()
+	} else {
+		report = sc.diagnosticsService.GetLastReport()
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(report, "获取成功"))
+}
+
+// RunHousekeeping 手动触发数据库维护
+func (sc *SysController) RunHousekeeping(c *gin.Context) {
+	report, err := sc.housekeepingService.Run()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "数据库维护失败: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(report, "数据库维护完成"))
+}
+
+type UpdateLogRetentionRequest struct {
+	TaskLogRetentionDays int  `json:"taskLogRetentionDays" binding:"min=0"`
+	TaskLogMaxRows       *int `json:"taskLogMaxRows" binding:"omitempty,min=0"` // 未提供时保持原设置
+}
+
+// UpdateLogRetention 设置任务执行日志的全局保留天数与每个任务的条数上限
+func (sc *SysController) UpdateLogRetention(c *gin.Context) {
+	var req UpdateLogRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.SetTaskLogRetentionDays(req.TaskLogRetentionDays); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "保存日志保留设置失败"))
+		return
+	}
+	if req.TaskLogMaxRows != nil {
+		if err := services.SetTaskLogMaxRows(*req.TaskLogMaxRows); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "保存日志保留设置失败"))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "日志保留设置已更新"))
+}
+
+type PurgeTaskLogsRequest struct {
+	OlderThanDays int `json:"olderThanDays" binding:"min=0"` // 删除该天数之前的日志，0 表示删除全部
+}
+
+// PurgeTaskLogs 清理所有任务的执行日志
+func (sc *SysController) PurgeTaskLogs(c *gin.Context) {
+	var req PurgeTaskLogsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	count, err := sc.housekeepingService.PurgeTaskLogs(req.OlderThanDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "清理日志失败: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(map[string]int64{"count": count}, "日志已清理"))
+}
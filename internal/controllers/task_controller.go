@@ -3,6 +3,7 @@ package controllers
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/adiecho/oci-panel/internal/database"
@@ -23,18 +24,36 @@ func NewTaskController(taskService *services.TaskService) *TaskController {
 }
 
 type CreateTaskRequest struct {
-	UserID          string  `json:"userId" binding:"required"`
-	OciRegion       string  `json:"ociRegion" binding:"required"`
-	Ocpus           float64 `json:"ocpus"`
-	Memory          float64 `json:"memory"`
-	Disk            int     `json:"disk"`
-	BootVolumeVpu   int64   `json:"bootVolumeVpu"`
-	Architecture    string  `json:"architecture"`
-	OperationSystem string  `json:"operationSystem"`
-	ImageId         string  `json:"imageId"`
-	SSHKeyID        string  `json:"sshKeyId" binding:"required"`
-	Interval        int     `json:"interval"`
-	ExecuteOnce     bool    `json:"executeOnce"`
+	UserID           string  `json:"userId" binding:"required"`
+	OciRegion        string  `json:"ociRegion" binding:"required"`
+	Ocpus            float64 `json:"ocpus"`
+	Memory           float64 `json:"memory"`
+	Disk             int     `json:"disk"`
+	BootVolumeVpu    int64   `json:"bootVolumeVpu"`
+	Architecture     string  `json:"architecture"`
+	OperationSystem  string  `json:"operationSystem"`
+	ImageId          string  `json:"imageId"`
+	CompartmentID    string  `json:"compartmentId"` // 目标区间，为空时使用租户根区间
+	SSHKeyID         string  `json:"sshKeyId"`      // 为空时使用配置的默认SSH密钥
+	Interval         int     `json:"interval"`
+	CreateNumbers    int     `json:"createNumbers"`    // 需要创建的实例数，成功达到该数量后任务完成
+	BackoffMin       int     `json:"backoffMin"`       // 容量不足退避起始秒数，为 0 时使用 interval
+	BackoffMax       int     `json:"backoffMax"`       // 容量不足退避上限秒数，为 0 时使用默认值
+	RotateAD         bool    `json:"rotateAd"`         // 每次执行轮换可用域
+	FallbackRegions  string  `json:"fallbackRegions"`  // 备用区域，逗号分隔
+	RegionFailover   int     `json:"regionFailover"`   // 连续容量不足多少次后切换区域
+	MaxExecuteCount  int     `json:"maxExecuteCount"`  // 最大执行次数，为 0 时不限制
+	ExpireAt         string  `json:"expireAt"`         // 截止时间，格式 2006-01-02 15:04:05，为空时不限制
+	LogRetentionDays int     `json:"logRetentionDays"` // 执行日志保留天数，为 0 时使用全局设置
+	GroupName        string  `json:"groupName"`        // 任务分组
+	Priority         int     `json:"priority"`         // 优先级，数值越大越优先
+	ExecuteWindows   string  `json:"executeWindows"`   // 每日允许执行的时间段，如 02:00-07:00,22:00-01:00，为空表示全天
+	WebhookURL       string  `json:"webhookUrl"`       // 开机成功后 POST 实例信息的地址
+	PostActionID     string  `json:"postActionId"`     // 开机成功后执行的动作
+	UserData         string  `json:"userData"`         // cloud-init 脚本，base64 或明文
+	ReservedPublicIP string  `json:"reservedPublicIp"` // 预留公网IP的 OCID 或地址，new 表示开机成功后新建，为空使用临时IP
+	ProbeOnly        bool    `json:"probeOnly"`        // 探测模式，仅记录各可用域容量，不创建实例
+	ExecuteOnce      bool    `json:"executeOnce"`
 }
 
 func (tc *TaskController) CreateTask(c *gin.Context) {
@@ -44,21 +63,94 @@ func (tc *TaskController) CreateTask(c *gin.Context) {
 		return
 	}
 
+	var user models.OciUser
+	if err := database.GetDB().First(&user, "id = ?", req.UserID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "配置不存在"))
+		return
+	}
+
+	launch := services.LaunchDefaults{SSHKeyID: req.SSHKeyID, ImageID: req.ImageId, OperationSystem: req.OperationSystem}
+	services.ApplyConfigDefaults(&user, req.OciRegion, &launch)
+	req.SSHKeyID, req.ImageId, req.OperationSystem = launch.SSHKeyID, launch.ImageID, launch.OperationSystem
+
 	var sshKey models.SSHKey
 	if err := database.GetDB().First(&sshKey, "id = ?", req.SSHKeyID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "SSH密钥不存在"))
 		return
 	}
 
-	var user models.OciUser
-	if err := database.GetDB().First(&user, "id = ?", req.UserID).Error; err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "配置不存在"))
+	if !services.IsValidCompartmentID(req.CompartmentID) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "区间ID无效"))
+		return
+	}
+
+	if req.RegionFailover < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "区域切换次数无效"))
+		return
+	}
+	fallbackRegions, err := tc.taskService.ValidateFallbackRegions(&user, req.OciRegion, req.FallbackRegions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
 		return
 	}
 
 	if req.Interval < 10 {
 		req.Interval = 60
 	}
+	if req.BackoffMin < 0 || req.BackoffMax < 0 || (req.BackoffMin > 0 && req.BackoffMax > 0 && req.BackoffMax < req.BackoffMin) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "退避时间设置无效"))
+		return
+	}
+	if req.CreateNumbers < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "创建数量无效"))
+		return
+	}
+	if req.CreateNumbers == 0 {
+		req.CreateNumbers = 1
+	}
+	if req.LogRetentionDays < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "日志保留天数无效"))
+		return
+	}
+	if req.MaxExecuteCount < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "最大执行次数无效"))
+		return
+	}
+	executeWindows, err := services.NormalizeTaskWindows(req.ExecuteWindows)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if err := services.ValidateWebhookURL(req.WebhookURL); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	userData, err := services.NormalizeUserData(req.UserData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	reservedPublicIP, err := tc.taskService.ResolveReservedPublicIP(&user, req.OciRegion, req.ReservedPublicIP)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if req.PostActionID != "" {
+		var action models.PostProvisionAction
+		if err := database.GetDB().First(&action, "id = ?", req.PostActionID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "开机后动作不存在"))
+			return
+		}
+	}
+	var expireAt *time.Time
+	if req.ExpireAt != "" {
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", req.ExpireAt, time.Local)
+		if err != nil || !t.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "截止时间无效"))
+			return
+		}
+		expireAt = &t
+	}
 	if req.Ocpus <= 0 {
 		req.Ocpus = 1
 	}
@@ -77,6 +169,10 @@ func (tc *TaskController) CreateTask(c *gin.Context) {
 	if req.OperationSystem == "" {
 		req.OperationSystem = "Ubuntu"
 	}
+	if err := tc.taskService.ValidateTaskImage(&user, req.OciRegion, req.ImageId, req.Architecture, req.Ocpus, req.Memory, fallbackRegions); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
 
 	// 如果是只执行一次，状态设置为 pending，执行后变为 completed 或 error
 	status := "running"
@@ -85,21 +181,39 @@ func (tc *TaskController) CreateTask(c *gin.Context) {
 	}
 
 	task := &models.OciCreateTask{
-		ID:              uuid.New().String(),
-		UserID:          req.UserID,
-		Username:        user.Username,
-		OciRegion:       req.OciRegion,
-		Ocpus:           req.Ocpus,
-		Memory:          req.Memory,
-		Disk:            req.Disk,
-		BootVolumeVpu:   req.BootVolumeVpu,
-		Architecture:    req.Architecture,
-		OperationSystem: req.OperationSystem,
-		ImageId:         req.ImageId,
-		SSHKeyID:        req.SSHKeyID,
-		Interval:        req.Interval,
-		Status:          status,
-		CreateTime:      time.Now(),
+		ID:               uuid.New().String(),
+		UserID:           req.UserID,
+		Username:         user.Username,
+		OciRegion:        req.OciRegion,
+		Ocpus:            req.Ocpus,
+		Memory:           req.Memory,
+		Disk:             req.Disk,
+		BootVolumeVpu:    req.BootVolumeVpu,
+		Architecture:     req.Architecture,
+		OperationSystem:  req.OperationSystem,
+		ImageId:          req.ImageId,
+		CompartmentID:    req.CompartmentID,
+		SSHKeyID:         req.SSHKeyID,
+		Interval:         req.Interval,
+		CreateNumbers:    req.CreateNumbers,
+		BackoffMin:       req.BackoffMin,
+		BackoffMax:       req.BackoffMax,
+		RotateAD:         req.RotateAD,
+		FallbackRegions:  fallbackRegions,
+		RegionFailover:   req.RegionFailover,
+		MaxExecuteCount:  req.MaxExecuteCount,
+		ExpireAt:         expireAt,
+		LogRetentionDays: req.LogRetentionDays,
+		GroupName:        strings.TrimSpace(req.GroupName),
+		Priority:         req.Priority,
+		ExecuteWindows:   executeWindows,
+		WebhookURL:       req.WebhookURL,
+		PostActionID:     req.PostActionID,
+		UserData:         userData,
+		ReservedPublicIP: reservedPublicIP,
+		ProbeOnly:        req.ProbeOnly,
+		Status:           status,
+		CreateTime:       time.Now(),
 	}
 
 	if req.ExecuteOnce {
@@ -126,17 +240,60 @@ func (tc *TaskController) CreateTask(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(task, "任务创建成功"))
 }
 
+type TaskTemplateRequest struct {
+	UserID     string `json:"userId" binding:"required"`
+	InstanceID string `json:"instanceId" binding:"required"`
+}
+
+// TaskTemplateFromInstance 以现有实例的规格生成创建任务的参数，返回结果可直接提交到 task/create
+func (tc *TaskController) TaskTemplateFromInstance(c *gin.Context) {
+	var req TaskTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	var user models.OciUser
+	if err := database.GetDB().First(&user, "id = ?", req.UserID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "配置不存在"))
+		return
+	}
+
+	task, err := tc.taskService.TaskTemplateFromInstance(&user, req.InstanceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(CreateTaskRequest{
+		UserID:          task.UserID,
+		OciRegion:       task.OciRegion,
+		Ocpus:           task.Ocpus,
+		Memory:          task.Memory,
+		Disk:            task.Disk,
+		BootVolumeVpu:   task.BootVolumeVpu,
+		Architecture:    task.Architecture,
+		OperationSystem: task.OperationSystem,
+		ImageId:         task.ImageId,
+		CompartmentID:   task.CompartmentID,
+		SSHKeyID:        task.SSHKeyID,
+		CreateNumbers:   1,
+		UserData:        task.UserData,
+	}, "success"))
+}
+
 type TaskPageRequest struct {
-	Page     int    `json:"page" binding:"required,min=1"`
-	PageSize int    `json:"pageSize" binding:"required,min=1,max=100"`
-	Status   string `json:"status"`
+	Page      int    `json:"page" binding:"required,min=1"`
+	PageSize  int    `json:"pageSize" binding:"required,min=1,max=100"`
+	Status    string `json:"status"`
+	GroupName string `json:"groupName"`
 }
 
 type TaskPageResponse struct {
-	List     []models.TaskListResponse `json:"list"`
-	Total    int64                     `json:"total"`
-	Page     int                       `json:"page"`
-	PageSize int                       `json:"pageSize"`
+	List     interface{} `json:"list"` // []models.TaskListResponse，指定 fields 时仅包含所选字段
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"pageSize"`
 }
 
 func (tc *TaskController) TaskList(c *gin.Context) {
@@ -154,6 +311,9 @@ func (tc *TaskController) TaskList(c *gin.Context) {
 	if req.Status != "" {
 		query = query.Where("status = ?", req.Status)
 	}
+	if req.GroupName != "" {
+		query = query.Where("group_name = ?", req.GroupName)
+	}
 
 	query.Count(&total)
 	offset := (req.Page - 1) * req.PageSize
@@ -165,28 +325,55 @@ func (tc *TaskController) TaskList(c *gin.Context) {
 		if t.LastExecuteTime != nil {
 			lastExecuteTime = t.LastExecuteTime.Format("2006-01-02 15:04:05")
 		}
+		expireAt := ""
+		if t.ExpireAt != nil {
+			expireAt = t.ExpireAt.Format("2006-01-02 15:04:05")
+		}
+		nextExecuteTime := ""
+		if t.NextExecuteTime != nil && t.Status == "running" {
+			nextExecuteTime = t.NextExecuteTime.Format("2006-01-02 15:04:05")
+		}
 		list[i] = models.TaskListResponse{
-			ID:              t.ID,
-			UserID:          t.UserID,
-			Username:        t.Username,
-			OciRegion:       t.OciRegion,
-			Ocpus:           t.Ocpus,
-			Memory:          t.Memory,
-			Disk:            t.Disk,
-			Architecture:    t.Architecture,
-			Interval:        t.Interval,
-			OperationSystem: t.OperationSystem,
-			Status:          t.Status,
-			ExecuteCount:    t.ExecuteCount,
-			SuccessCount:    t.SuccessCount,
-			LastExecuteTime: lastExecuteTime,
-			LastMessage:     t.LastMessage,
-			CreateTime:      t.CreateTime.Format("2006-01-02 15:04:05"),
+			ID:               t.ID,
+			UserID:           t.UserID,
+			Username:         t.Username,
+			OciRegion:        t.OciRegion,
+			Ocpus:            t.Ocpus,
+			Memory:           t.Memory,
+			Disk:             t.Disk,
+			Architecture:     t.Architecture,
+			Interval:         t.Interval,
+			BackoffMin:       t.BackoffMin,
+			BackoffMax:       t.BackoffMax,
+			CurrentBackoff:   t.CurrentBackoff,
+			RotateAD:         t.RotateAD,
+			FallbackRegions:  t.FallbackRegions,
+			CurrentRegion:    services.CurrentTaskRegion(&t),
+			OperationSystem:  t.OperationSystem,
+			Status:           t.Status,
+			ExecuteCount:     t.ExecuteCount,
+			SuccessCount:     t.SuccessCount,
+			CreateNumbers:    t.CreateNumbers,
+			LogRetentionDays: t.LogRetentionDays,
+			GroupName:        t.GroupName,
+			Priority:         t.Priority,
+			ExecuteWindows:   t.ExecuteWindows,
+			WebhookURL:       t.WebhookURL,
+			PostActionID:     t.PostActionID,
+			UserData:         t.UserData,
+			ReservedPublicIP: t.ReservedPublicIP,
+			ProbeOnly:        t.ProbeOnly,
+			MaxExecuteCount:  t.MaxExecuteCount,
+			ExpireAt:         expireAt,
+			LastExecuteTime:  lastExecuteTime,
+			NextExecuteTime:  nextExecuteTime,
+			LastMessage:      t.LastMessage,
+			CreateTime:       t.CreateTime.Format("2006-01-02 15:04:05"),
 		}
 	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse(TaskPageResponse{
-		List:     list,
+		List:     selectFields(c, list),
 		Total:    total,
 		Page:     req.Page,
 		PageSize: req.PageSize,
@@ -239,7 +426,49 @@ func (tc *TaskController) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SuccessResponse(nil, "任务已删除"))
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "任务已移入回收站"))
+}
+
+// RecycleBin 获取回收站中的任务，保留期满后自动彻底删除
+func (tc *TaskController) RecycleBin(c *gin.Context) {
+	tasks, err := tc.taskService.ListRecycledTasks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取回收站失败"))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(tasks, "success"))
+}
+
+// RestoreTask 从回收站恢复任务及其执行日志
+func (tc *TaskController) RestoreTask(c *gin.Context) {
+	var req TaskActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := tc.taskService.RestoreTask(req.TaskID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "任务已恢复"))
+}
+
+// PurgeTask 彻底删除回收站中的任务
+func (tc *TaskController) PurgeTask(c *gin.Context) {
+	var req TaskActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := tc.taskService.PurgeTask(req.TaskID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "任务已彻底删除"))
 }
 
 type BatchDeleteTaskRequest struct {
@@ -268,6 +497,39 @@ func (tc *TaskController) BatchDeleteTask(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(nil, "批量删除成功"))
 }
 
+// ExportTasks 导出所有开机任务定义（不含私钥等敏感信息）
+func (tc *TaskController) ExportTasks(c *gin.Context) {
+	export, err := tc.taskService.ExportTasks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(export, "success"))
+}
+
+type ImportTasksRequest struct {
+	Data  services.TaskExport `json:"data" binding:"required"`
+	Start bool                `json:"start"` // 导入后立即启动原本运行中的任务
+}
+
+// ImportTasks 从导出文件导入开机任务
+func (tc *TaskController) ImportTasks(c *gin.Context) {
+	var req ImportTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	result, err := tc.taskService.ImportTasks(&req.Data, req.Start)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(result, fmt.Sprintf("导入完成，成功%d个，跳过%d个", result.Imported, len(result.Skipped))))
+}
+
 type TaskLogsRequest struct {
 	TaskID   string `json:"taskId" binding:"required"`
 	Page     int    `json:"page" binding:"required,min=1"`
@@ -302,6 +564,108 @@ func (tc *TaskController) TaskLogs(c *gin.Context) {
 	}, "success"))
 }
 
+// TaskADStats 按可用域统计任务执行结果
+func (tc *TaskController) TaskADStats(c *gin.Context) {
+	var req TaskActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	stats, err := tc.taskService.GetTaskADStats(req.TaskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(stats, "success"))
+}
+
+type TaskAnalyticsRequest struct {
+	TaskID string `json:"taskId" binding:"required"`
+	Days   int    `json:"days"` // 统计最近天数，默认 7，最多 90
+}
+
+// TaskAnalytics 汇总任务的尝试次数走势、错误类型分布与首次成功耗时
+func (tc *TaskController) TaskAnalytics(c *gin.Context) {
+	var req TaskAnalyticsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	analytics, err := tc.taskService.GetTaskAnalytics(req.TaskID, req.Days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(analytics, "success"))
+}
+
+// ProbeTask 立即以任务的区域与规格探测一次各可用域容量
+func (tc *TaskController) ProbeTask(c *gin.Context) {
+	var req TaskActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	probes, err := tc.taskService.ProbeTaskOnce(req.TaskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(probes, "success"))
+}
+
+type CapacityProbeStatsRequest struct {
+	Region string `json:"region"`
+	Days   int    `json:"days"` // 统计最近天数，默认 7
+}
+
+// CapacityProbeStats 按区域与可用域汇总容量探测历史
+func (tc *TaskController) CapacityProbeStats(c *gin.Context) {
+	var req CapacityProbeStatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if req.Days <= 0 {
+		req.Days = 7
+	}
+
+	stats, err := services.GetCapacityProbeStats(req.Region, req.Days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(stats, "success"))
+}
+
+type UpdateTaskLogRetentionRequest struct {
+	TaskID           string `json:"taskId" binding:"required"`
+	LogRetentionDays int    `json:"logRetentionDays" binding:"min=0"`
+}
+
+// UpdateLogRetention 设置任务执行日志的保留天数，为 0 时使用全局设置
+func (tc *TaskController) UpdateLogRetention(c *gin.Context) {
+	var req UpdateTaskLogRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := tc.taskService.UpdateLogRetention(req.TaskID, req.LogRetentionDays); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "日志保留设置已更新"))
+}
+
 func (tc *TaskController) ClearTaskLogs(c *gin.Context) {
 	var req TaskActionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -316,3 +680,196 @@ func (tc *TaskController) ClearTaskLogs(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.SuccessResponse(nil, "日志已清空"))
 }
+
+type TaskConcurrencyRequest struct {
+	Concurrency     int  `json:"concurrency" binding:"required,min=1"`
+	RegionSpacingMs *int `json:"regionSpacingMs"` // 同一租户同一区域相邻开机请求的最小间隔（毫秒），不传时不修改
+}
+
+// GetTaskConcurrency 获取每个租户同时进行的开机请求上限与同区域请求间隔
+func (tc *TaskController) GetTaskConcurrency(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{
+		"concurrency":        tc.taskService.GetTaskConcurrency(),
+		"maxConcurrency":     services.MaxTaskConcurrency,
+		"regionSpacingMs":    tc.taskService.GetTaskRegionSpacing(),
+		"maxRegionSpacingMs": services.MaxTaskRegionSpacingMs,
+	}, "success"))
+}
+
+// UpdateTaskConcurrency 设置每个租户同时进行的开机请求上限
+func (tc *TaskController) UpdateTaskConcurrency(c *gin.Context) {
+	var req TaskConcurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+	if req.Concurrency > services.MaxTaskConcurrency {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, fmt.Sprintf("并发数不能超过 %d", services.MaxTaskConcurrency)))
+		return
+	}
+	if req.RegionSpacingMs != nil && (*req.RegionSpacingMs < 0 || *req.RegionSpacingMs > services.MaxTaskRegionSpacingMs) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, fmt.Sprintf("请求间隔需在 0-%d 毫秒之间", services.MaxTaskRegionSpacingMs)))
+		return
+	}
+
+	if err := tc.taskService.SetTaskConcurrency(req.Concurrency); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+	if req.RegionSpacingMs != nil {
+		if err := tc.taskService.SetTaskRegionSpacing(*req.RegionSpacingMs); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "并发设置已更新"))
+}
+
+type UpdateTaskPriorityRequest struct {
+	TaskID   string `json:"taskId" binding:"required"`
+	Priority int    `json:"priority"`
+}
+
+// UpdateTaskPriority 设置任务优先级，下次执行时生效
+func (tc *TaskController) UpdateTaskPriority(c *gin.Context) {
+	var req UpdateTaskPriorityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := tc.taskService.UpdateTaskPriority(req.TaskID, req.Priority); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "优先级已更新"))
+}
+
+type UpdateExecuteWindowsRequest struct {
+	TaskID         string `json:"taskId" binding:"required"`
+	ExecuteWindows string `json:"executeWindows"`
+}
+
+// UpdateExecuteWindows 设置任务每日允许执行的时间段
+func (tc *TaskController) UpdateExecuteWindows(c *gin.Context) {
+	var req UpdateExecuteWindowsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := tc.taskService.UpdateExecuteWindows(req.TaskID, req.ExecuteWindows); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "执行时间段已更新"))
+}
+
+type UpdateTaskHookRequest struct {
+	TaskID       string `json:"taskId" binding:"required"`
+	WebhookURL   string `json:"webhookUrl"`
+	PostActionID string `json:"postActionId"`
+}
+
+// UpdateTaskHook 设置任务开机成功后的 Webhook 与动作，均为空时不执行
+func (tc *TaskController) UpdateTaskHook(c *gin.Context) {
+	var req UpdateTaskHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := tc.taskService.UpdateTaskHook(req.TaskID, strings.TrimSpace(req.WebhookURL), req.PostActionID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "开机后动作已更新"))
+}
+
+type TaskGroupRequest struct {
+	GroupName string `json:"groupName" binding:"required"`
+}
+
+type SetTaskGroupRequest struct {
+	TaskIDs   []string `json:"taskIds" binding:"required"`
+	GroupName string   `json:"groupName"` // 为空时移出分组
+}
+
+// TaskGroups 获取任务分组及各分组的任务状态统计
+func (tc *TaskController) TaskGroups(c *gin.Context) {
+	stats, err := services.GetTaskGroupStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(stats, "success"))
+}
+
+// SetTaskGroup 批量设置任务所属分组
+func (tc *TaskController) SetTaskGroup(c *gin.Context) {
+	var req SetTaskGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := tc.taskService.SetTaskGroup(req.TaskIDs, strings.TrimSpace(req.GroupName)); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "分组已更新"))
+}
+
+// StartTaskGroup 启动分组内的所有任务
+func (tc *TaskController) StartTaskGroup(c *gin.Context) {
+	tc.handleTaskGroupAction(c, tc.taskService.StartTask, "启动")
+}
+
+// StopTaskGroup 停止分组内的所有任务
+func (tc *TaskController) StopTaskGroup(c *gin.Context) {
+	tc.handleTaskGroupAction(c, tc.taskService.StopTask, "停止")
+}
+
+// DeleteTaskGroup 删除分组内的所有任务
+func (tc *TaskController) DeleteTaskGroup(c *gin.Context) {
+	tc.handleTaskGroupAction(c, tc.taskService.DeleteTask, "删除")
+}
+
+// handleTaskGroupAction 对分组内的每个任务执行操作，并汇总失败数量
+func (tc *TaskController) handleTaskGroupAction(c *gin.Context, action func(taskID string) error, actionName string) {
+	var req TaskGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	taskIDs, err := tc.taskService.GroupTaskIDs(req.GroupName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+	if len(taskIDs) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "分组内没有任务"))
+		return
+	}
+
+	var failedCount int
+	for _, taskID := range taskIDs {
+		if err := action(taskID); err != nil {
+			failedCount++
+		}
+	}
+
+	if failedCount > 0 {
+		c.JSON(http.StatusOK, models.SuccessResponse(nil, fmt.Sprintf("%s完成，%d个失败", actionName, failedCount)))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, fmt.Sprintf("已%s%d个任务", actionName, len(taskIDs))))
+}
@@ -23,16 +23,23 @@ type TelegramConfigResponse struct {
 	ChatID   string `json:"chatId"`
 	Enabled  bool   `json:"enabled"`
 	Running  bool   `json:"running"`
+	ProxyURL string `json:"proxyUrl"`
+	APIBase  string `json:"apiBase"`
+	Language string `json:"language"`
 }
 
 func (tc *TelegramController) GetConfig(c *gin.Context) {
 	botToken, chatID, enabled := tc.telegramService.GetConfig()
+	proxyURL, apiBase := tc.telegramService.GetProxyConfig()
 
 	c.JSON(http.StatusOK, models.SuccessResponse(TelegramConfigResponse{
 		BotToken: botToken,
 		ChatID:   chatID,
 		Enabled:  enabled,
 		Running:  tc.telegramService.IsRunning(),
+		ProxyURL: proxyURL,
+		APIBase:  apiBase,
+		Language: tc.telegramService.GetLanguage(),
 	}, "success"))
 }
 
@@ -40,6 +47,9 @@ type UpdateTelegramConfigRequest struct {
 	BotToken string `json:"botToken"`
 	ChatID   string `json:"chatId"`
 	Enabled  bool   `json:"enabled"`
+	ProxyURL string `json:"proxyUrl"` // 支持 http://、https://、socks5://，为空表示直连
+	APIBase  string `json:"apiBase"`  // 自定义 API 地址，为空表示 https://api.telegram.org
+	Language string `json:"language"` // Bot 显示语言：zh / en，为空表示不修改
 }
 
 func (tc *TelegramController) UpdateConfig(c *gin.Context) {
@@ -55,6 +65,18 @@ func (tc *TelegramController) UpdateConfig(c *gin.Context) {
 		botToken = currentToken
 	}
 
+	if err := tc.telegramService.UpdateProxyConfig(req.ProxyURL, req.APIBase); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "代理配置无效: "+err.Error()))
+		return
+	}
+
+	if req.Language != "" {
+		if err := tc.telegramService.UpdateLanguage(req.Language); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(400, "语言设置无效: "+err.Error()))
+			return
+		}
+	}
+
 	if err := tc.telegramService.UpdateConfig(botToken, req.ChatID, req.Enabled); err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "更新配置失败: "+err.Error()))
 		return
@@ -113,11 +135,105 @@ func (tc *TelegramController) StopBot(c *gin.Context) {
 }
 
 type BotStatusResponse struct {
-	Running bool `json:"running"`
+	Running  bool                            `json:"running"`
+	Delivery services.TelegramDeliveryStatus `json:"delivery"` // 最近一次发送消息的结果
 }
 
 func (tc *TelegramController) GetBotStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse(BotStatusResponse{
-		Running: tc.telegramService.IsRunning(),
+		Running:  tc.telegramService.IsRunning(),
+		Delivery: tc.telegramService.GetDeliveryStatus(),
 	}, "success"))
 }
+
+type TelegramAuditLogsRequest struct {
+	Page     int    `json:"page" binding:"required,min=1"`
+	PageSize int    `json:"pageSize" binding:"required,min=1,max=100"`
+	TgUserID int64  `json:"tgUserId"`
+	Result   string `json:"result"`
+}
+
+type TelegramAuditLogsResponse struct {
+	List     []models.TelegramAuditLog `json:"list"`
+	Total    int64                     `json:"total"`
+	Page     int                       `json:"page"`
+	PageSize int                       `json:"pageSize"`
+}
+
+func (tc *TelegramController) AuditLogs(c *gin.Context) {
+	var req TelegramAuditLogsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	logs, total, err := tc.telegramService.GetAuditLogs(req.Page, req.PageSize, req.TgUserID, req.Result)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(TelegramAuditLogsResponse{
+		List:     logs,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, "success"))
+}
+
+type NotificationLogsRequest struct {
+	Page     int    `json:"page" binding:"required,min=1"`
+	PageSize int    `json:"pageSize" binding:"required,min=1,max=100"`
+	Result   string `json:"result"` // sent, silent, muted, failed
+}
+
+type NotificationLogsResponse struct {
+	List     []models.NotificationLog `json:"list"`
+	Total    int64                    `json:"total"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"pageSize"`
+}
+
+// NotificationLogs 分页查询通知发送记录
+func (tc *TelegramController) NotificationLogs(c *gin.Context) {
+	var req NotificationLogsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	logs, total, err := tc.telegramService.GetNotificationLogs(req.Page, req.PageSize, req.Result)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(NotificationLogsResponse{
+		List:     logs,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, "success"))
+}
+
+// GetQuietHours 获取通知免打扰设置
+func (tc *TelegramController) GetQuietHours(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse(services.GetNotifyQuietHours(), "success"))
+}
+
+// UpdateQuietHours 设置通知免打扰时间段、时区与免打扰方式，重要告警不受影响
+func (tc *TelegramController) UpdateQuietHours(c *gin.Context) {
+	var req services.NotifyQuietHours
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	quiet, err := services.SetNotifyQuietHours(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(quiet, "免打扰设置已保存"))
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type TrafficQuotaController struct {
+	trafficQuotaService *services.TrafficQuotaService
+}
+
+func NewTrafficQuotaController(trafficQuotaService *services.TrafficQuotaService) *TrafficQuotaController {
+	return &TrafficQuotaController{trafficQuotaService: trafficQuotaService}
+}
+
+type TrafficQuotaConfigRequest struct {
+	ConfigID string `json:"configId" binding:"required"`
+}
+
+// GetQuota 获取配置的流量配额，未设置时返回空
+func (tc *TrafficQuotaController) GetQuota(c *gin.Context) {
+	var req TrafficQuotaConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	quota, err := services.GetTrafficQuota(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取配额失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(quota, "success"))
+}
+
+type SaveTrafficQuotaRequest struct {
+	ConfigID    string   `json:"configId" binding:"required"`
+	LimitBytes  int64    `json:"limitBytes" binding:"required"` // 月度出站流量上限
+	WarnPercent int      `json:"warnPercent"`                   // 预警百分比，默认 80
+	Action      string   `json:"action" binding:"required"`     // notify / stop / release_ip
+	InstanceIDs []string `json:"instanceIds"`                   // 为空表示所有运行中的实例
+	Enabled     bool     `json:"enabled"`
+}
+
+// SaveQuota 创建或更新配置的流量配额
+func (tc *TrafficQuotaController) SaveQuota(c *gin.Context) {
+	var req SaveTrafficQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	quota, err := services.SaveTrafficQuota(req.ConfigID, req.LimitBytes, req.WarnPercent, req.Action, req.InstanceIDs, req.Enabled)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(quota, "配额已保存"))
+}
+
+// DeleteQuota 删除配置的流量配额
+func (tc *TrafficQuotaController) DeleteQuota(c *gin.Context) {
+	var req TrafficQuotaConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.DeleteTrafficQuota(req.ConfigID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "删除失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "配额已删除"))
+}
+
+// CheckQuota 立即检查配置的流量配额，达到阈值时会通知或执行动作
+func (tc *TrafficQuotaController) CheckQuota(c *gin.Context) {
+	var req TrafficQuotaConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	quota, err := tc.trafficQuotaService.CheckTrafficQuota(req.ConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(quota, "success"))
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type VpuAdvisorController struct {
+	advisorService *services.VpuAdvisorService
+	jobService     *services.JobService
+}
+
+func NewVpuAdvisorController(advisorService *services.VpuAdvisorService, jobService *services.JobService) *VpuAdvisorController {
+	return &VpuAdvisorController{advisorService: advisorService, jobService: jobService}
+}
+
+// Scan 列出所有配置中性能高于均衡档位的引导卷，不执行修改
+func (vc *VpuAdvisorController) Scan(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	report, err := vc.advisorService.Scan(ctx, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(report, "success"))
+}
+
+// Downgrade 通过作业框架在后台批量降级，结果在作业运行记录中查看
+func (vc *VpuAdvisorController) Downgrade(c *gin.Context) {
+	if err := vc.jobService.RunNow(services.VpuDowngradeJobName); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "已开始批量降级"))
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/adiecho/oci-panel/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+type WebTerminalController struct {
+	terminalService *services.WebTerminalService
+}
+
+func NewWebTerminalController(terminalService *services.WebTerminalService) *WebTerminalController {
+	return &WebTerminalController{terminalService: terminalService}
+}
+
+// InstanceSSHRequest 连接实例 SSH 的公共参数，sshKeyId、privateKey 与 password 至少填写一项
+type InstanceSSHRequest struct {
+	UserId     string `json:"userId" binding:"required"`
+	InstanceId string `json:"instanceId" binding:"required"`
+	Username   string `json:"username"`
+	SSHKeyID   string `json:"sshKeyId"`
+	PrivateKey string `json:"privateKey"`
+	Passphrase string `json:"passphrase"`
+	Password   string `json:"password"`
+	Host       string `json:"host"`
+	Port       int    `json:"port" binding:"omitempty,min=1,max=65535"`
+}
+
+func (r InstanceSSHRequest) SSHConnectOptions() services.SSHConnectOptions {
+	return services.SSHConnectOptions{
+		Username:   r.Username,
+		SSHKeyID:   r.SSHKeyID,
+		PrivateKey: r.PrivateKey,
+		Passphrase: r.Passphrase,
+		Password:   r.Password,
+		Host:       r.Host,
+		Port:       r.Port,
+	}
+}
+
+type CreateWebTerminalRequest struct {
+	InstanceSSHRequest
+	Record bool `json:"record"`
+}
+
+// Create 创建 Web SSH 终端会话，返回连接 /ws/terminal 所需的一次性令牌
+func (tc *WebTerminalController) Create(c *gin.Context) {
+	var req CreateWebTerminalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	session, err := tc.terminalService.CreateSession(req.UserId, req.InstanceId, services.WebTerminalOptions{
+		SSHConnectOptions: req.SSHConnectOptions(),
+		Record:            req.Record,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(session, "终端会话已创建"))
+}
+
+type CloseWebTerminalRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Close 结束 Web SSH 终端会话
+func (tc *WebTerminalController) Close(c *gin.Context) {
+	var req CloseWebTerminalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := tc.terminalService.CloseSession(req.Token); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "终端会话已结束"))
+}
+
+// Attach 将 WebSocket 桥接到实例 SSH
+// 浏览器无法在 WebSocket 握手中携带 Authorization 头，通过创建会话时返回的一次性令牌鉴权
+func (tc *WebTerminalController) Attach(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse(401, "Unauthorized"))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	if err := tc.terminalService.Attach(token, conn); err != nil {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()))
+	}
+}
+
+type TerminalRecordingsRequest struct {
+	ConfigID   string `json:"configId"`
+	InstanceID string `json:"instanceId"`
+	Page       int    `json:"page" binding:"required,min=1"`
+	PageSize   int    `json:"pageSize" binding:"required,min=1,max=100"`
+}
+
+// Recordings 分页获取终端会话录像
+func (tc *WebTerminalController) Recordings(c *gin.Context) {
+	var req TerminalRecordingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	records, total, err := services.GetTerminalRecordings(req.ConfigID, req.InstanceID, req.Page, req.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, "获取录像失败"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(gin.H{
+		"list":     records,
+		"total":    total,
+		"page":     req.Page,
+		"pageSize": req.PageSize,
+	}, "success"))
+}
+
+type TerminalRecordingRequest struct {
+	ID string `json:"id" binding:"required"`
+}
+
+// DownloadRecording 下载 asciicast 格式的录像文件，可用 asciinema-player 播放
+func (tc *WebTerminalController) DownloadRecording(c *gin.Context) {
+	var req TerminalRecordingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	recording, err := services.GetTerminalRecording(req.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, err.Error()))
+		return
+	}
+	if _, err := os.Stat(recording.FilePath); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(404, "录像文件不存在"))
+		return
+	}
+
+	c.FileAttachment(recording.FilePath, recording.ID+".cast")
+}
+
+// DeleteRecording 删除终端会话录像
+func (tc *WebTerminalController) DeleteRecording(c *gin.Context) {
+	var req TerminalRecordingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	if err := services.DeleteTerminalRecording(req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(nil, "录像已删除"))
+}
@@ -1,6 +1,11 @@
 package database
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/adiecho/oci-panel/internal/models"
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
@@ -11,17 +16,30 @@ var DB *gorm.DB
 
 func InitDB(dsn string) error {
 	var err error
-	DB, err = gorm.Open(sqlite.Open(dsn), &gorm.Config{
+	DB, err = gorm.Open(sqlite.Open(withBusyTimeout(dsn)), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
 		return err
 	}
 
+	// 数据库已被更新版本的面板迁移时拒绝启动，避免旧版本按旧结构写入并覆盖版本号
+	stored, err := storedSchemaVersion(DB)
+	if err != nil {
+		return err
+	}
+	if stored > models.SchemaVersion {
+		return fmt.Errorf("数据库结构版本 %d 高于当前程序支持的版本 %d，请升级面板", stored, models.SchemaVersion)
+	}
+
 	if err := models.AutoMigrate(DB); err != nil {
 		return err
 	}
 
+	if err := saveSchemaVersion(DB); err != nil {
+		return err
+	}
+
 	sqlDB, err := DB.DB()
 	if err != nil {
 		return err
@@ -32,6 +50,56 @@ func InitDB(dsn string) error {
 	return nil
 }
 
+// withBusyTimeout 多个面板进程共享同一个数据库文件时，写锁被占用的请求等待而不是立即失败
+func withBusyTimeout(dsn string) string {
+	if strings.Contains(dsn, "busy_timeout") {
+		return dsn
+	}
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + "_pragma=busy_timeout(5000)"
+}
+
+// storedSchemaVersion 读取数据库中记录的结构版本，新数据库返回 0
+func storedSchemaVersion(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable(&models.SysSetting{}) {
+		return 0, nil
+	}
+	var setting models.SysSetting
+	if err := db.Where("key = ?", models.SettingSchemaVersion).First(&setting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	version, err := strconv.Atoi(setting.Value)
+	if err != nil {
+		return 0, fmt.Errorf("数据库结构版本无效: %s", setting.Value)
+	}
+	return version, nil
+}
+
+// saveSchemaVersion 迁移完成后记录当前数据库结构版本，调用前已确认记录的版本不高于当前版本
+func saveSchemaVersion(db *gorm.DB) error {
+	value := strconv.Itoa(models.SchemaVersion)
+
+	var setting models.SysSetting
+	if err := db.Where("key = ?", models.SettingSchemaVersion).First(&setting).Error; err != nil {
+		setting = models.SysSetting{
+			ID:    models.SettingSchemaVersion,
+			Key:   models.SettingSchemaVersion,
+			Value: value,
+		}
+		return db.Create(&setting).Error
+	}
+	if setting.Value == value {
+		return nil
+	}
+	return db.Model(&setting).Update("value", value).Error
+}
+
 func GetDB() *gorm.DB {
 	return DB
 }
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+func TestInitDBRejectsNewerSchema(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "panel.db")
+	if err := InitDB(dsn); err != nil {
+		t.Fatal(err)
+	}
+	if version, err := storedSchemaVersion(DB); err != nil || version != models.SchemaVersion {
+		t.Fatalf("迁移后版本 = %d, %v, want %d", version, err, models.SchemaVersion)
+	}
+
+	newer := strconv.Itoa(models.SchemaVersion + 1)
+	if err := DB.Model(&models.SysSetting{}).Where("key = ?", models.SettingSchemaVersion).Update("value", newer).Error; err != nil {
+		t.Fatal(err)
+	}
+	closeDB(t)
+
+	if err := InitDB(dsn); err == nil {
+		t.Fatal("数据库版本高于程序版本时应拒绝启动")
+	}
+	defer closeDB(t)
+
+	var setting models.SysSetting
+	if err := DB.Where("key = ?", models.SettingSchemaVersion).First(&setting).Error; err != nil {
+		t.Fatal(err)
+	}
+	if setting.Value != newer {
+		t.Errorf("版本被改写为 %s, want %s", setting.Value, newer)
+	}
+}
+
+func closeDB(t *testing.T) {
+	t.Helper()
+	sqlDB, err := DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+}
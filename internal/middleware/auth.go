@@ -60,7 +60,15 @@ func AuthMiddleware() gin.HandlerFunc {
 		if path == "/api/sys/login" ||
 			path == "/api/sys/checkMfaCode" ||
 			path == "/api/passkey/beginLogin" ||
-			path == "/api/passkey/finishLogin" {
+			path == "/api/passkey/finishLogin" ||
+			path == "/api/onboarding/status" ||
+			path == "/api/onboarding/admin" {
+			c.Next()
+			return
+		}
+
+		// 访问链接接口通过请求中的令牌鉴权，OCI 事件推送通过地址中的签名鉴权
+		if strings.HasPrefix(path, "/api/operator/") || strings.HasPrefix(path, "/api/events/oci/") {
 			c.Next()
 			return
 		}
@@ -86,11 +94,39 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
-func CORS() gin.HandlerFunc {
+// CORS 跨域中间件，allowOrigins 为空或包含 "*" 时允许所有来源，否则仅回显列表中的来源
+func CORS(allowOrigins []string) gin.HandlerFunc {
+	allowAll := len(allowOrigins) == 0
+	allowed := make(map[string]bool, len(allowOrigins))
+	for _, origin := range allowOrigins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "*" {
+			allowAll = true
+		}
+		allowed[strings.ToLower(origin)] = true
+	}
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		origin := c.GetHeader("Origin")
+		if allowAll {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Writer.Header().Add("Vary", "Origin")
+			if origin != "" {
+				if !allowed[strings.ToLower(origin)] {
+					if c.Request.Method == "OPTIONS" {
+						c.AbortWithStatus(403)
+						return
+					}
+					c.Next()
+					return
+				}
+				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-None-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	AccessLogFormatCommon = "common"
+	AccessLogFormatJSON   = "json"
+)
+
+// sensitiveQueryKeys 访问日志中需要脱敏的查询参数（不区分大小写，包含即匹配）
+var sensitiveQueryKeys = []string{"token", "password", "secret", "key", "code", "auth"}
+
+// signedPathPrefixes 最后一段路径为签名的地址，OCI 事件推送无法携带自定义请求头，签名只能放在路径中
+var signedPathPrefixes = []string{"/api/events/oci/"}
+
+// AccessLogger 访问日志中间件，查询参数中的敏感信息会被替换为 ***
+func AccessLogger(format string) gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		path := redactPath(param.Path)
+		if format == AccessLogFormatJSON {
+			entry := map[string]interface{}{
+				"time":      param.TimeStamp.Format(time.RFC3339),
+				"status":    param.StatusCode,
+				"latencyMs": param.Latency.Milliseconds(),
+				"clientIp":  param.ClientIP,
+				"method":    param.Method,
+				"path":      path,
+				"size":      param.BodySize,
+			}
+			if param.ErrorMessage != "" {
+				entry["error"] = strings.TrimSpace(param.ErrorMessage)
+			}
+			data, _ := json.Marshal(entry)
+			return string(data) + "\n"
+		}
+
+		return fmt.Sprintf("[GIN] %s | %3d | %13v | %15s | %-7s %s\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			param.StatusCode,
+			param.Latency,
+			param.ClientIP,
+			param.Method,
+			path,
+			param.ErrorMessage,
+		)
+	})
+}
+
+// redactPath 对请求路径中的签名与敏感查询参数脱敏
+func redactPath(path string) string {
+	base, rawQuery, ok := strings.Cut(path, "?")
+	for _, prefix := range signedPathPrefixes {
+		if strings.HasPrefix(base, prefix) {
+			if i := strings.LastIndex(base, "/"); i >= len(prefix) {
+				base = base[:i+1] + "***"
+			}
+			break
+		}
+	}
+	if !ok || rawQuery == "" {
+		return base
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return base + "?***"
+	}
+	for key := range query {
+		lower := strings.ToLower(key)
+		for _, sensitive := range sensitiveQueryKeys {
+			if strings.Contains(lower, sensitive) {
+				query.Set(key, "***")
+				break
+			}
+		}
+	}
+	return base + "?" + query.Encode()
+}
@@ -7,16 +7,33 @@ import (
 )
 
 type OciUser struct {
-	ID               string     `gorm:"primaryKey;column:id" json:"id"`
-	Username         string     `gorm:"column:username" json:"username"`
-	TenantName       string     `gorm:"column:tenant_name" json:"tenantName"`
-	TenantCreateTime *time.Time `gorm:"column:tenant_create_time" json:"tenantCreateTime"`
-	OciTenantID      string     `gorm:"column:oci_tenant_id" json:"ociTenantId"`
-	OciUserID        string     `gorm:"column:oci_user_id" json:"ociUserId"`
-	OciFingerprint   string     `gorm:"column:oci_fingerprint" json:"ociFingerprint"`
-	OciRegion        string     `gorm:"column:oci_region" json:"ociRegion"`
-	OciKeyPath       string     `gorm:"column:oci_key_path" json:"ociKeyPath"`
-	CreateTime       time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	ID                     string     `gorm:"primaryKey;column:id" json:"id"`
+	Username               string     `gorm:"column:username" json:"username"`
+	TenantName             string     `gorm:"column:tenant_name" json:"tenantName"`
+	TenantCreateTime       *time.Time `gorm:"column:tenant_create_time" json:"tenantCreateTime"`
+	OciTenantID            string     `gorm:"column:oci_tenant_id" json:"ociTenantId"`
+	OciUserID              string     `gorm:"column:oci_user_id" json:"ociUserId"`
+	OciFingerprint         string     `gorm:"column:oci_fingerprint" json:"ociFingerprint"`
+	OciRegion              string     `gorm:"column:oci_region" json:"ociRegion"`
+	OciKeyPath             string     `gorm:"column:oci_key_path" json:"ociKeyPath"`
+	DefaultSSHKeyID        string     `gorm:"column:default_ssh_key_id" json:"defaultSshKeyId"` // Default* 为配置级默认值，开机时未填写的字段使用这些值
+	DefaultImageID         string     `gorm:"column:default_image_id" json:"defaultImageId"`
+	DefaultOperationSystem string     `gorm:"column:default_operation_system" json:"defaultOperationSystem"`
+	DefaultSubnetID        string     `gorm:"column:default_subnet_id" json:"defaultSubnetId"`
+	DefaultAD              string     `gorm:"column:default_ad" json:"defaultAd"`
+	LastSuccessTime        *time.Time `gorm:"column:last_success_time" json:"lastSuccessTime"` // 最近一次成功调用 OCI 的时间
+	LastErrorTime          *time.Time `gorm:"column:last_error_time" json:"lastErrorTime"`
+	LastError              string     `gorm:"column:last_error;type:text" json:"lastError"`
+	CreateTime             time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+// OciConfigDefaults 配置级默认开机参数
+type OciConfigDefaults struct {
+	SSHKeyID           string `json:"sshKeyId"`
+	ImageID            string `json:"imageId"`
+	OperationSystem    string `json:"operationSystem"`
+	SubnetID           string `json:"subnetId"`
+	AvailabilityDomain string `json:"availabilityDomain"`
 }
 
 // OciUserListResponse 配置列表响应
@@ -30,21 +47,25 @@ type OciUserListResponse struct {
 	CreateTime       string `json:"createTime"`
 	InstanceCount    int    `json:"instanceCount"`
 	RunningInstances int    `json:"runningInstances"`
+	LastUpdated      string `json:"lastUpdated"` // 实例数量的更新时间，从未同步时为空
+	Stale            bool   `json:"stale"`       // 缓存超过过期阈值未更新
+	Favorite         bool   `json:"favorite"`    // 当前用户已收藏
 }
 
 // OciConfigDetails 配置详情响应
 type OciConfigDetails struct {
-	UserID      string         `json:"userId"`
-	Username    string         `json:"username"`
-	TenantID    string         `json:"tenantId"`
-	TenantName  string         `json:"tenantName"`
-	Fingerprint string         `json:"fingerprint"`
-	KeyPath     string         `json:"keyPath"`
-	Region      string         `json:"region"`
-	CreateTime  string         `json:"createTime"`
-	Instances   []InstanceInfo `json:"instances"`
-	Volumes     []VolumeInfo   `json:"volumes"`
-	VCNs        []VCNInfo      `json:"vcns"`
+	UserID      string            `json:"userId"`
+	Username    string            `json:"username"`
+	TenantID    string            `json:"tenantId"`
+	TenantName  string            `json:"tenantName"`
+	Fingerprint string            `json:"fingerprint"`
+	KeyPath     string            `json:"keyPath"`
+	Region      string            `json:"region"`
+	CreateTime  string            `json:"createTime"`
+	Defaults    OciConfigDefaults `json:"defaults"`
+	Instances   []InstanceInfo    `json:"instances"`
+	Volumes     []VolumeInfo      `json:"volumes"`
+	VCNs        []VCNInfo         `json:"vcns"`
 }
 
 // InstanceInfo 实例信息
@@ -65,6 +86,8 @@ type InstanceInfo struct {
 	ImageName          string     `json:"imageName"`
 	CreateTime         string     `json:"createTime"`
 	VnicList           []VnicInfo `json:"vnicList"`
+	Protected          bool       `json:"protected"` // 是否开启终止保护
+	SSHPort            int        `json:"sshPort"`   // 实例实际使用的 SSH 端口，未设置时为 22
 }
 
 // VnicInfo VNIC信息
@@ -99,6 +122,16 @@ type VCNInfo struct {
 	Subnets     []SubnetInfo `json:"subnets"`
 }
 
+// CompartmentInfo 区间信息（树形结构）
+type CompartmentInfo struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	ParentID    string            `json:"parentId"`
+	IsRoot      bool              `json:"isRoot"`
+	Children    []CompartmentInfo `json:"children"`
+}
+
 // SubnetInfo 子网信息
 type SubnetInfo struct {
 	ID                 string `json:"id"`
@@ -180,63 +213,237 @@ func (OciUser) TableName() string {
 }
 
 type OciCreateTask struct {
-	ID              string     `gorm:"primaryKey;column:id" json:"id"`
-	UserID          string     `gorm:"column:user_id" json:"userId"`
-	Username        string     `gorm:"column:username" json:"username"`
-	OciRegion       string     `gorm:"column:oci_region" json:"ociRegion"`
-	Ocpus           float64    `gorm:"column:ocpus;default:1.0" json:"ocpus"`
-	Memory          float64    `gorm:"column:memory;default:6.0" json:"memory"`
-	Disk            int        `gorm:"column:disk;default:50" json:"disk"`
-	BootVolumeVpu   int64      `gorm:"column:boot_volume_vpu;default:10" json:"bootVolumeVpu"`
-	Architecture    string     `gorm:"column:architecture;default:ARM" json:"architecture"`
-	Interval        int        `gorm:"column:interval;default:60" json:"interval"`
-	CreateNumbers   int        `gorm:"column:create_numbers;default:1" json:"createNumbers"`
-	SSHKeyID        string     `gorm:"column:ssh_key_id" json:"sshKeyId"`
-	OperationSystem string     `gorm:"column:operation_system;default:Ubuntu" json:"operationSystem"`
-	ImageId         string     `gorm:"column:image_id" json:"imageId"`
-	Status          string     `gorm:"column:status;default:running" json:"status"`
-	ExecuteCount    int        `gorm:"column:execute_count;default:0" json:"executeCount"`
-	SuccessCount    int        `gorm:"column:success_count;default:0" json:"successCount"`
-	LastExecuteTime *time.Time `gorm:"column:last_execute_time" json:"lastExecuteTime"`
-	LastMessage     string     `gorm:"column:last_message;type:text" json:"lastMessage"`
-	CreateTime      time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	ID               string         `gorm:"primaryKey;column:id" json:"id"`
+	UserID           string         `gorm:"column:user_id" json:"userId"`
+	Username         string         `gorm:"column:username" json:"username"`
+	OciRegion        string         `gorm:"column:oci_region" json:"ociRegion"`
+	Ocpus            float64        `gorm:"column:ocpus;default:1.0" json:"ocpus"`
+	Memory           float64        `gorm:"column:memory;default:6.0" json:"memory"`
+	Disk             int            `gorm:"column:disk;default:50" json:"disk"`
+	BootVolumeVpu    int64          `gorm:"column:boot_volume_vpu;default:10" json:"bootVolumeVpu"`
+	Architecture     string         `gorm:"column:architecture;default:ARM" json:"architecture"`
+	Interval         int            `gorm:"column:interval;default:60" json:"interval"`
+	BackoffMin       int            `gorm:"column:backoff_min;default:0" json:"backoffMin"`         // 容量不足退避起始秒数，为 0 时使用 Interval
+	BackoffMax       int            `gorm:"column:backoff_max;default:0" json:"backoffMax"`         // 容量不足退避上限秒数，为 0 时使用默认值
+	CurrentBackoff   int            `gorm:"column:current_backoff;default:0" json:"currentBackoff"` // 当前退避秒数，为 0 表示按 Interval 执行
+	NextExecuteTime  *time.Time     `gorm:"column:next_execute_time" json:"nextExecuteTime"`        // 下次执行时间，重启后据此恢复调度
+	CreateNumbers    int            `gorm:"column:create_numbers;default:1" json:"createNumbers"`
+	SSHKeyID         string         `gorm:"column:ssh_key_id" json:"sshKeyId"`
+	OperationSystem  string         `gorm:"column:operation_system;default:Ubuntu" json:"operationSystem"`
+	ImageId          string         `gorm:"column:image_id" json:"imageId"`
+	CompartmentID    string         `gorm:"column:compartment_id" json:"compartmentId"`                  // 目标区间，为空时使用租户根区间
+	RotateAD         bool           `gorm:"column:rotate_ad" json:"rotateAd"`                            // 每次执行轮换可用域
+	ADIndex          int            `gorm:"column:ad_index;default:0" json:"adIndex"`                    // 下次尝试的可用域序号
+	FallbackRegions  string         `gorm:"column:fallback_regions" json:"fallbackRegions"`              // 备用区域，逗号分隔，按顺序轮换
+	RegionFailover   int            `gorm:"column:region_failover;default:0" json:"regionFailover"`      // 连续容量不足多少次后切换区域，为 0 时使用默认值
+	RegionIndex      int            `gorm:"column:region_index;default:0" json:"regionIndex"`            // 当前区域序号，0 为主区域
+	RegionFailures   int            `gorm:"column:region_failures;default:0" json:"regionFailures"`      // 当前区域连续容量不足次数
+	MaxExecuteCount  int            `gorm:"column:max_execute_count;default:0" json:"maxExecuteCount"`   // 最大执行次数，为 0 时不限制
+	ExpireAt         *time.Time     `gorm:"column:expire_at" json:"expireAt"`                            // 截止时间，到期后自动停止
+	LogRetentionDays int            `gorm:"column:log_retention_days;default:0" json:"logRetentionDays"` // 执行日志保留天数，为 0 时使用全局设置
+	GroupName        string         `gorm:"column:group_name;index" json:"groupName"`                    // 任务分组，为空表示未分组
+	Priority         int            `gorm:"column:priority;default:0" json:"priority"`                   // 优先级，数值越大越先获得租户并发名额
+	ExecuteWindows   string         `gorm:"column:execute_windows" json:"executeWindows"`                // 每日允许执行的时间段，如 02:00-07:00，为空表示全天
+	WebhookURL       string         `gorm:"column:webhook_url" json:"webhookUrl"`                        // 开机成功后 POST 实例信息的地址
+	PostActionID     string         `gorm:"column:post_action_id" json:"postActionId"`                   // 开机成功后执行的动作
+	UserData         string         `gorm:"column:user_data;type:text" json:"userData"`                  // base64 编码的 cloud-init 脚本
+	ReservedPublicIP string         `gorm:"column:reserved_public_ip" json:"reservedPublicIp"`           // 开机成功后绑定的预留公网IP OCID，new 表示新建，为空使用临时IP
+	ProbeOnly        bool           `gorm:"column:probe_only" json:"probeOnly"`                          // 探测模式，仅查询各可用域容量，不创建实例
+	Status           string         `gorm:"column:status;default:running" json:"status"`
+	ExecuteCount     int            `gorm:"column:execute_count;default:0" json:"executeCount"`
+	SuccessCount     int            `gorm:"column:success_count;default:0" json:"successCount"`
+	LastExecuteTime  *time.Time     `gorm:"column:last_execute_time" json:"lastExecuteTime"`
+	LastMessage      string         `gorm:"column:last_message;type:text" json:"lastMessage"`
+	CreateTime       time.Time      `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	DeletedAt        gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"` // 软删除时间，回收站保留期满后彻底删除
 }
 
 func (OciCreateTask) TableName() string {
 	return "oci_create_task"
 }
 
+// TaskGroupStat 任务分组统计
+type TaskGroupStat struct {
+	GroupName string `json:"groupName"`
+	Total     int    `json:"total"`
+	Running   int    `json:"running"`
+	Completed int    `json:"completed"`
+}
+
 // TaskLog 任务执行日志
 type TaskLog struct {
-	ID          string    `gorm:"primaryKey;column:id" json:"id"`
-	TaskID      string    `gorm:"column:task_id;index" json:"taskId"`
-	Status      string    `gorm:"column:status" json:"status"`
-	Message     string    `gorm:"column:message;type:text" json:"message"`
-	ExecuteTime time.Time `gorm:"column:execute_time;autoCreateTime" json:"executeTime"`
+	ID                 string    `gorm:"primaryKey;column:id" json:"id"`
+	TaskID             string    `gorm:"column:task_id;index" json:"taskId"`
+	Status             string    `gorm:"column:status" json:"status"`
+	Message            string    `gorm:"column:message;type:text" json:"message"`
+	AvailabilityDomain string    `gorm:"column:availability_domain" json:"availabilityDomain"`
+	ExecuteTime        time.Time `gorm:"column:execute_time;autoCreateTime" json:"executeTime"`
 }
 
 func (TaskLog) TableName() string {
 	return "task_log"
 }
 
+// TaskADStat 任务在单个可用域上的执行统计
+type TaskADStat struct {
+	AvailabilityDomain string `json:"availabilityDomain"`
+	Attempts           int64  `json:"attempts"`
+	Failures           int64  `json:"failures"`
+	Successes          int64  `json:"successes"`
+}
+
+// InventoryItem 纳入面板本地清单的 OCI 资源
+type InventoryItem struct {
+	ID              string    `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID        string    `gorm:"column:config_id;uniqueIndex:idx_inventory_resource" json:"configId"`
+	ResourceType    string    `gorm:"column:resource_type" json:"resourceType"` // instance / vcn / volume / nlb
+	ResourceID      string    `gorm:"column:resource_id;uniqueIndex:idx_inventory_resource" json:"resourceId"`
+	Name            string    `gorm:"column:name" json:"name"`
+	CompartmentID   string    `gorm:"column:compartment_id" json:"compartmentId"`
+	CompartmentName string    `gorm:"column:compartment_name" json:"compartmentName"`
+	Region          string    `gorm:"column:region" json:"region"`
+	Notes           string    `gorm:"column:notes;type:text" json:"notes"`
+	Tags            string    `gorm:"column:tags" json:"tags"` // 逗号分隔
+	AdoptTime       time.Time `gorm:"column:adopt_time" json:"adoptTime"`
+}
+
+func (InventoryItem) TableName() string {
+	return "inventory_item"
+}
+
+// TaskLease 任务执行权租约，多个面板进程共享同一个数据库文件时保证同一任务只由一个实例执行
+type TaskLease struct {
+	TaskID   string    `gorm:"primaryKey;column:task_id" json:"taskId"`
+	Owner    string    `gorm:"column:owner" json:"owner"` // 持有租约的实例标识
+	ExpireAt time.Time `gorm:"column:expire_at" json:"expireAt"`
+}
+
+func (TaskLease) TableName() string {
+	return "task_lease"
+}
+
+// CapacityProbe 一次容量探测在单个可用域上的结果
+type CapacityProbe struct {
+	ID                 string    `gorm:"primaryKey;column:id" json:"id"`
+	TaskID             string    `gorm:"column:task_id;index" json:"taskId"`
+	ConfigID           string    `gorm:"column:config_id" json:"configId"`
+	Region             string    `gorm:"column:region;index" json:"region"`
+	AvailabilityDomain string    `gorm:"column:availability_domain" json:"availabilityDomain"`
+	Shape              string    `gorm:"column:shape" json:"shape"`
+	Ocpus              float64   `gorm:"column:ocpus" json:"ocpus"`
+	Memory             float64   `gorm:"column:memory" json:"memory"`
+	Status             string    `gorm:"column:status" json:"status"` // AVAILABLE, OUT_OF_HOST_CAPACITY, HARDWARE_NOT_SUPPORTED 或 ERROR
+	AvailableCount     int64     `gorm:"column:available_count" json:"availableCount"`
+	Message            string    `gorm:"column:message" json:"message"`
+	CreateTime         time.Time `gorm:"column:create_time;index" json:"createTime"`
+}
+
+func (CapacityProbe) TableName() string {
+	return "capacity_probe"
+}
+
+// SecurityFinding 安全列表巡检发现的高风险入站规则
+type SecurityFinding struct {
+	ID               string    `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID         string    `gorm:"column:config_id;index" json:"configId"`
+	Username         string    `gorm:"column:username" json:"username"`
+	Region           string    `gorm:"column:region" json:"region"`
+	VcnID            string    `gorm:"column:vcn_id" json:"vcnId"`
+	SecurityListID   string    `gorm:"column:security_list_id" json:"securityListId"`
+	SecurityListName string    `gorm:"column:security_list_name" json:"securityListName"`
+	Fingerprint      string    `gorm:"column:fingerprint;uniqueIndex" json:"fingerprint"` // 配置、安全列表与规则内容的摘要，用于识别新发现
+	Severity         string    `gorm:"column:severity" json:"severity"`                   // high / medium / low
+	Category         string    `gorm:"column:category" json:"category"`                   // all_open / all_ports / sensitive_port / wide_range / ssh
+	Protocol         string    `gorm:"column:protocol" json:"protocol"`
+	Source           string    `gorm:"column:source" json:"source"`
+	PortRange        string    `gorm:"column:port_range" json:"portRange"`
+	Description      string    `gorm:"column:description" json:"description"`
+	Suggestion       string    `gorm:"column:suggestion" json:"suggestion"`
+	FirstSeen        time.Time `gorm:"column:first_seen" json:"firstSeen"`
+	LastSeen         time.Time `gorm:"column:last_seen" json:"lastSeen"`
+}
+
+func (SecurityFinding) TableName() string {
+	return "security_finding"
+}
+
+// CapacityProbeStat 按区域、可用域与规格汇总的容量探测统计
+type CapacityProbeStat struct {
+	Region             string `json:"region"`
+	AvailabilityDomain string `json:"availabilityDomain"`
+	Shape              string `json:"shape"`
+	Probes             int64  `json:"probes"`
+	Available          int64  `json:"available"`
+	LastAvailableTime  string `json:"lastAvailableTime"`
+	LastProbeTime      string `json:"lastProbeTime"`
+}
+
+// TaskAnalytics 任务执行情况分析，供前端绘制图表
+type TaskAnalytics struct {
+	TaskID               string              `json:"taskId"`
+	Days                 int                 `json:"days"`      // 统计最近天数
+	Attempts             int64               `json:"attempts"`  // 统计范围内的开机尝试次数
+	Successes            int64               `json:"successes"` // 统计范围内的成功次数
+	Failures             int64               `json:"failures"`
+	SuccessRate          float64             `json:"successRate"` // 成功率百分比
+	ErrorTypes           []TaskErrorTypeStat `json:"errorTypes"`
+	Timeline             []TaskAttemptBucket `json:"timeline"`             // 按小时汇总，仅包含有记录的时段
+	FirstSuccessTime     string              `json:"firstSuccessTime"`     // 首次成功时间，尚未成功时为空
+	TimeToSuccessSeconds *int64              `json:"timeToSuccessSeconds"` // 从创建任务到首次成功的秒数
+	AttemptsToSuccess    int64               `json:"attemptsToSuccess"`    // 首次成功前的尝试次数（含成功的一次）
+}
+
+// TaskErrorTypeStat 单类错误的出现次数
+type TaskErrorTypeStat struct {
+	Type  string `json:"type"` // capacity / rate_limit / limit / auth / other
+	Count int64  `json:"count"`
+}
+
+// TaskAttemptBucket 单个时段内的尝试与结果次数
+type TaskAttemptBucket struct {
+	Time      string `json:"time"` // 时段起点，格式 2006-01-02 15:00
+	Attempts  int64  `json:"attempts"`
+	Successes int64  `json:"successes"`
+	Failures  int64  `json:"failures"`
+}
+
 // TaskListResponse 任务列表响应
 type TaskListResponse struct {
-	ID              string  `json:"id"`
-	UserID          string  `json:"userId"`
-	Username        string  `json:"username"`
-	OciRegion       string  `json:"ociRegion"`
-	Ocpus           float64 `json:"ocpus"`
-	Memory          float64 `json:"memory"`
-	Disk            int     `json:"disk"`
-	Architecture    string  `json:"architecture"`
-	Interval        int     `json:"interval"`
-	OperationSystem string  `json:"operationSystem"`
-	Status          string  `json:"status"`
-	ExecuteCount    int     `json:"executeCount"`
-	SuccessCount    int     `json:"successCount"`
-	LastExecuteTime string  `json:"lastExecuteTime"`
-	LastMessage     string  `json:"lastMessage"`
-	CreateTime      string  `json:"createTime"`
+	ID               string  `json:"id"`
+	UserID           string  `json:"userId"`
+	Username         string  `json:"username"`
+	OciRegion        string  `json:"ociRegion"`
+	Ocpus            float64 `json:"ocpus"`
+	Memory           float64 `json:"memory"`
+	Disk             int     `json:"disk"`
+	Architecture     string  `json:"architecture"`
+	Interval         int     `json:"interval"`
+	BackoffMin       int     `json:"backoffMin"`
+	BackoffMax       int     `json:"backoffMax"`
+	CurrentBackoff   int     `json:"currentBackoff"`
+	RotateAD         bool    `json:"rotateAd"`
+	FallbackRegions  string  `json:"fallbackRegions"`
+	CurrentRegion    string  `json:"currentRegion"`
+	OperationSystem  string  `json:"operationSystem"`
+	Status           string  `json:"status"`
+	ExecuteCount     int     `json:"executeCount"`
+	SuccessCount     int     `json:"successCount"`
+	CreateNumbers    int     `json:"createNumbers"`
+	LogRetentionDays int     `json:"logRetentionDays"`
+	GroupName        string  `json:"groupName"`
+	Priority         int     `json:"priority"`
+	ExecuteWindows   string  `json:"executeWindows"`
+	WebhookURL       string  `json:"webhookUrl"`
+	PostActionID     string  `json:"postActionId"`
+	UserData         string  `json:"userData"`
+	ReservedPublicIP string  `json:"reservedPublicIp"`
+	ProbeOnly        bool    `json:"probeOnly"`
+	MaxExecuteCount  int     `json:"maxExecuteCount"`
+	ExpireAt         string  `json:"expireAt"`
+	LastExecuteTime  string  `json:"lastExecuteTime"`
+	NextExecuteTime  string  `json:"nextExecuteTime"`
+	LastMessage      string  `json:"lastMessage"`
+	CreateTime       string  `json:"createTime"`
 }
 
 type OciKv struct {
@@ -309,6 +516,22 @@ func (OciConfigCache) TableName() string {
 	return "oci_config_cache"
 }
 
+// InstanceListSnapshot 实例列表快照，按配置、区域与区间缓存 OCI 返回的实例
+type InstanceListSnapshot struct {
+	ID            string    `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID      string    `gorm:"column:config_id;uniqueIndex:idx_instance_snapshot_scope" json:"configId"`
+	Region        string    `gorm:"column:region;uniqueIndex:idx_instance_snapshot_scope" json:"region"`
+	CompartmentID string    `gorm:"column:compartment_id;uniqueIndex:idx_instance_snapshot_scope" json:"compartmentId"`
+	InstancesData string    `gorm:"column:instances_data;type:text" json:"-"` // OCI 返回的 []core.Instance
+	InstanceCount int       `gorm:"column:instance_count" json:"instanceCount"`
+	AccessTime    time.Time `gorm:"column:access_time;index" json:"accessTime"` // 最近一次被列表读取，长期未读取的快照会被删除
+	UpdateTime    time.Time `gorm:"column:update_time;index" json:"updateTime"` // 为零值时表示已失效，下次读取时重新获取
+}
+
+func (InstanceListSnapshot) TableName() string {
+	return "instance_list_snapshot"
+}
+
 // OciImageCache 镜像缓存表
 type OciImageCache struct {
 	ID           string    `gorm:"primaryKey;column:id" json:"id"`
@@ -385,10 +608,136 @@ type InstancePresetResponse struct {
 	CreateTime      string  `json:"createTime"`
 }
 
+// TelegramAuditLog Telegram Bot 操作审计日志
+type TelegramAuditLog struct {
+	ID         string    `gorm:"primaryKey;column:id" json:"id"`
+	TgUserID   int64     `gorm:"column:tg_user_id;index" json:"tgUserId"`
+	TgUsername string    `gorm:"column:tg_username" json:"tgUsername"`
+	ChatID     int64     `gorm:"column:chat_id" json:"chatId"`
+	Type       string    `gorm:"column:type" json:"type"` // command: 文本命令, callback: 按钮回调
+	Action     string    `gorm:"column:action" json:"action"`
+	Result     string    `gorm:"column:result" json:"result"`         // success, failed, ignored, denied, rate_limited
+	Error      string    `gorm:"column:error;type:text" json:"error"` // 处理失败的原因
+	CreateTime time.Time `gorm:"column:create_time;autoCreateTime;index" json:"createTime"`
+}
+
+func (TelegramAuditLog) TableName() string {
+	return "tg_audit_log"
+}
+
+// NotificationLog Telegram 通知发送记录
+type NotificationLog struct {
+	ID         string    `gorm:"primaryKey;column:id" json:"id"`
+	Title      string    `gorm:"column:title" json:"title"`
+	Message    string    `gorm:"column:message;type:text" json:"message"`
+	Result     string    `gorm:"column:result;index" json:"result"` // sent, silent, muted, failed
+	Error      string    `gorm:"column:error" json:"error"`
+	CreateTime time.Time `gorm:"column:create_time;autoCreateTime;index" json:"createTime"`
+}
+
+func (NotificationLog) TableName() string {
+	return "notification_log"
+}
+
+// TrafficAlertRule 租户月度流量告警规则
+type TrafficAlertRule struct {
+	ID             string    `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID       string    `gorm:"column:config_id;uniqueIndex" json:"configId"`
+	LimitBytes     int64     `gorm:"column:limit_bytes" json:"limitBytes"` // 月度出站流量上限
+	Thresholds     string    `gorm:"column:thresholds" json:"thresholds"`  // 告警百分比，逗号分隔，如 80,95
+	Enabled        bool      `gorm:"column:enabled" json:"enabled"`
+	LastAlertMonth string    `gorm:"column:last_alert_month" json:"lastAlertMonth"` // 最近告警月份，如 2006-01
+	LastAlertLevel int       `gorm:"column:last_alert_level" json:"lastAlertLevel"` // 本月已告警的最高百分比
+	CreateTime     time.Time `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime     time.Time `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (TrafficAlertRule) TableName() string {
+	return "traffic_alert_rule"
+}
+
+// TrafficQuota 租户月度出站流量配额，达到预警比例时通知，用尽后关机或释放公网 IP
+type TrafficQuota struct {
+	ID             string     `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID       string     `gorm:"column:config_id;uniqueIndex" json:"configId"`
+	LimitBytes     int64      `gorm:"column:limit_bytes" json:"limitBytes"`
+	WarnPercent    int        `gorm:"column:warn_percent;default:80" json:"warnPercent"`
+	Action         string     `gorm:"column:action" json:"action"`                      // notify 仅通知，stop 关机，release_ip 释放公网 IP
+	InstanceIDs    string     `gorm:"column:instance_ids;type:text" json:"instanceIds"` // 执行动作的实例，逗号分隔，为空表示所有运行中的实例
+	Enabled        bool       `gorm:"column:enabled" json:"enabled"`
+	LastCheckMonth string     `gorm:"column:last_check_month" json:"lastCheckMonth"` // 如 2006-01，跨月后重新计算
+	LastLevel      int        `gorm:"column:last_level" json:"lastLevel"`            // 本月已处理的最高百分比：预警比例或 100
+	LastCheckTime  *time.Time `gorm:"column:last_check_time" json:"lastCheckTime"`
+	LastPercent    float64    `gorm:"column:last_percent" json:"lastPercent"`
+	LastMessage    string     `gorm:"column:last_message;type:text" json:"lastMessage"`
+	CreateTime     time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (TrafficQuota) TableName() string {
+	return "traffic_quota"
+}
+
+// TrafficDailyStat 配置每日流量缓存，已结束的日期不再向 OCI 重复查询
+type TrafficDailyStat struct {
+	ID            string    `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID      string    `gorm:"column:config_id;uniqueIndex:idx_traffic_daily_config_day" json:"configId"`
+	Day           string    `gorm:"column:day;uniqueIndex:idx_traffic_daily_config_day" json:"day"` // 如 2006-01-02
+	InboundBytes  int64     `gorm:"column:inbound_bytes" json:"inboundBytes"`
+	OutboundBytes int64     `gorm:"column:outbound_bytes" json:"outboundBytes"`
+	InstanceCount int       `gorm:"column:instance_count" json:"instanceCount"`
+	UpdateTime    time.Time `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (TrafficDailyStat) TableName() string {
+	return "traffic_daily_stat"
+}
+
+// InstanceProtection 实例终止保护，开启后面板拒绝终止该实例
+type InstanceProtection struct {
+	InstanceID string    `gorm:"primaryKey;column:instance_id" json:"instanceId"`
+	ConfigID   string    `gorm:"column:config_id;index" json:"configId"`
+	Source     string    `gorm:"column:source" json:"source"` // manual 手动开启，tag 由实例标签同步
+	CreateTime time.Time `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (InstanceProtection) TableName() string {
+	return "instance_protection"
+}
+
+// InstanceSSHPort 实例实际使用的 SSH 端口，未记录的实例使用 22
+type InstanceSSHPort struct {
+	InstanceID string    `gorm:"primaryKey;column:instance_id" json:"instanceId"`
+	ConfigID   string    `gorm:"column:config_id;index" json:"configId"`
+	Port       int       `gorm:"column:port;not null" json:"port"`
+	UpdateTime time.Time `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (InstanceSSHPort) TableName() string {
+	return "instance_ssh_port"
+}
+
+// PostProvisionAction 开机成功后执行的动作，可被多个任务引用
+type PostProvisionAction struct {
+	ID          string    `gorm:"primaryKey;column:id" json:"id"`
+	Name        string    `gorm:"column:name;not null" json:"name"`
+	Type        string    `gorm:"column:type;not null" json:"type"` // webhook: 请求 URL, script: 在面板所在主机执行脚本
+	URL         string    `gorm:"column:url" json:"url"`
+	Headers     string    `gorm:"column:headers;type:text" json:"headers"` // 附加请求头，每行一个 "Key: Value"
+	Script      string    `gorm:"column:script;type:text" json:"script"`
+	Timeout     int       `gorm:"column:timeout;default:30" json:"timeout"` // 超时秒数
+	Description string    `gorm:"column:description;type:text" json:"description"`
+	CreateTime  time.Time `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (PostProvisionAction) TableName() string {
+	return "post_provision_action"
+}
+
 type ResponseData struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code        int         `json:"code"`
+	Message     string      `json:"message"`
+	Data        interface{} `json:"data,omitempty"`
+	LastUpdated string      `json:"lastUpdated,omitempty"` // 缓存数据的更新时间，实时获取时为响应时间
 }
 
 func SuccessResponse(data interface{}, message string) ResponseData {
@@ -402,6 +751,13 @@ func SuccessResponse(data interface{}, message string) ResponseData {
 	}
 }
 
+// CachedResponse 带数据更新时间的成功响应，用于缓存或同步得到的数据
+func CachedResponse(data interface{}, message string, lastUpdated time.Time) ResponseData {
+	response := SuccessResponse(data, message)
+	response.LastUpdated = lastUpdated.Format("2006-01-02 15:04:05")
+	return response
+}
+
 func ErrorResponse(code int, message string) ResponseData {
 	return ResponseData{
 		Code:    code,
@@ -409,8 +765,322 @@ func ErrorResponse(code int, message string) ResponseData {
 	}
 }
 
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+// AccessLink 限时、限定操作范围的实例访问链接，仅保存令牌的哈希
+type AccessLink struct {
+	ID           string     `gorm:"primaryKey;column:id" json:"id"`
+	TokenHash    string     `gorm:"column:token_hash;uniqueIndex" json:"-"`
+	Name         string     `gorm:"column:name" json:"name"` // 备注，如交给谁使用
+	ConfigID     string     `gorm:"column:config_id;index" json:"configId"`
+	InstanceID   string     `gorm:"column:instance_id" json:"instanceId"`
+	InstanceName string     `gorm:"column:instance_name" json:"instanceName"`
+	Actions      string     `gorm:"column:actions" json:"actions"` // 允许的操作，逗号分隔：start、stop、reboot
+	ExpireAt     time.Time  `gorm:"column:expire_at;index" json:"expireAt"`
+	UseCount     int        `gorm:"column:use_count;default:0" json:"useCount"`
+	LastUsedTime *time.Time `gorm:"column:last_used_time" json:"lastUsedTime"`
+	CreateTime   time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (AccessLink) TableName() string {
+	return "access_link"
+}
+
+// PowerSchedule 实例定时开关机计划
+type PowerSchedule struct {
+	ID           string     `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID     string     `gorm:"column:config_id;index" json:"configId"`
+	InstanceID   string     `gorm:"column:instance_id;index" json:"instanceId"`
+	InstanceName string     `gorm:"column:instance_name" json:"instanceName"`
+	Action       string     `gorm:"column:action" json:"action"` // start / stop
+	Cron         string     `gorm:"column:cron" json:"cron"`     // 五段式 cron 表达式，使用服务器本地时间
+	Enabled      bool       `gorm:"column:enabled;default:true" json:"enabled"`
+	LastRunTime  *time.Time `gorm:"column:last_run_time" json:"lastRunTime"`
+	LastStatus   string     `gorm:"column:last_status" json:"lastStatus"` // success / skipped / failed
+	LastMessage  string     `gorm:"column:last_message;type:text" json:"lastMessage"`
+	CreateTime   time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (PowerSchedule) TableName() string {
+	return "power_schedule"
+}
+
+// OciEvent 通过 OCI Notifications 推送收到的事件
+type OciEvent struct {
+	ID           string    `gorm:"primaryKey;column:id" json:"id"`
+	EventID      string    `gorm:"column:event_id;index" json:"eventId"` // OCI 事件 ID，用于忽略重复投递
+	ConfigID     string    `gorm:"column:config_id;index" json:"configId"`
+	EventType    string    `gorm:"column:event_type" json:"eventType"`
+	Source       string    `gorm:"column:source" json:"source"`
+	ResourceID   string    `gorm:"column:resource_id" json:"resourceId"`
+	ResourceName string    `gorm:"column:resource_name" json:"resourceName"`
+	EventTime    time.Time `gorm:"column:event_time" json:"eventTime"`
+	ReceiveTime  time.Time `gorm:"column:receive_time;index;autoCreateTime" json:"receiveTime"`
+}
+
+func (OciEvent) TableName() string {
+	return "oci_event"
+}
+
+// IPRotationSchedule 实例临时公网 IP 定时更换计划
+type IPRotationSchedule struct {
+	ID           string     `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID     string     `gorm:"column:config_id;index" json:"configId"`
+	InstanceID   string     `gorm:"column:instance_id;index" json:"instanceId"`
+	InstanceName string     `gorm:"column:instance_name" json:"instanceName"`
+	Cron         string     `gorm:"column:cron" json:"cron"` // 五段式 cron 表达式或 @daily、@weekly 等简写
+	Enabled      bool       `gorm:"column:enabled;default:true" json:"enabled"`
+	LastRunTime  *time.Time `gorm:"column:last_run_time" json:"lastRunTime"`
+	LastStatus   string     `gorm:"column:last_status" json:"lastStatus"` // success / failed
+	LastMessage  string     `gorm:"column:last_message;type:text" json:"lastMessage"`
+	CreateTime   time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (IPRotationSchedule) TableName() string {
+	return "ip_rotation_schedule"
+}
+
+// PublicIPHistory 实例公网 IP 更换记录
+type PublicIPHistory struct {
+	ID           string    `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID     string    `gorm:"column:config_id;index" json:"configId"`
+	InstanceID   string    `gorm:"column:instance_id;index" json:"instanceId"`
+	InstanceName string    `gorm:"column:instance_name" json:"instanceName"`
+	OldIP        string    `gorm:"column:old_ip" json:"oldIp"`
+	NewIP        string    `gorm:"column:new_ip" json:"newIp"`
+	Source       string    `gorm:"column:source" json:"source"` // manual / schedule / batch
+	CreateTime   time.Time `gorm:"column:create_time;index;autoCreateTime" json:"createTime"`
+}
+
+func (PublicIPHistory) TableName() string {
+	return "public_ip_history"
+}
+
+// BackupSchedule 实例引导卷定时备份计划
+type BackupSchedule struct {
+	ID             string     `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID       string     `gorm:"column:config_id;index" json:"configId"`
+	InstanceID     string     `gorm:"column:instance_id;index" json:"instanceId"`
+	InstanceName   string     `gorm:"column:instance_name" json:"instanceName"`
+	Cron           string     `gorm:"column:cron" json:"cron"`
+	RetentionCount int        `gorm:"column:retention_count;default:2" json:"retentionCount"` // 保留该计划创建的最近 N 份备份
+	Enabled        bool       `gorm:"column:enabled;default:true" json:"enabled"`
+	LastRunTime    *time.Time `gorm:"column:last_run_time" json:"lastRunTime"`
+	LastStatus     string     `gorm:"column:last_status" json:"lastStatus"` // success / failed
+	LastMessage    string     `gorm:"column:last_message;type:text" json:"lastMessage"`
+	CreateTime     time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (BackupSchedule) TableName() string {
+	return "backup_schedule"
+}
+
+// BackupRetentionPolicy 配置的卷备份保留策略，每个配置一条
+type BackupRetentionPolicy struct {
+	ID                  string     `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID            string     `gorm:"column:config_id;uniqueIndex" json:"configId"`
+	KeepCount           int        `gorm:"column:keep_count" json:"keepCount"`    // 每个卷保留的最近备份数，0 表示不限制
+	MaxAgeDays          int        `gorm:"column:max_age_days" json:"maxAgeDays"` // 超过该天数的备份被删除，0 表示不限制
+	IncludeBlockVolumes bool       `gorm:"column:include_block_volumes" json:"includeBlockVolumes"`
+	Enabled             bool       `gorm:"column:enabled" json:"enabled"`
+	LastRunTime         *time.Time `gorm:"column:last_run_time" json:"lastRunTime"`
+	LastStatus          string     `gorm:"column:last_status" json:"lastStatus"` // success / failed
+	LastMessage         string     `gorm:"column:last_message;type:text" json:"lastMessage"`
+	CreateTime          time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (BackupRetentionPolicy) TableName() string {
+	return "backup_retention_policy"
+}
+
+// IdleKeepAlive 配置的 Always Free 闲置回收保活设置，每天检查一次实例利用率
+type IdleKeepAlive struct {
+	ID          string     `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID    string     `gorm:"column:config_id;uniqueIndex" json:"configId"`
+	Mode        string     `gorm:"column:mode" json:"mode"` // notify 仅提醒，workload 通过运行命令启动低优先级负载
+	Enabled     bool       `gorm:"column:enabled" json:"enabled"`
+	LastRunTime *time.Time `gorm:"column:last_run_time" json:"lastRunTime"`
+	LastMessage string     `gorm:"column:last_message;type:text" json:"lastMessage"`
+	CreateTime  time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (IdleKeepAlive) TableName() string {
+	return "idle_keepalive"
+}
+
+// InstanceTuning 实例内已应用的交换文件与内核参数调优，记录原值用于回滚
+type InstanceTuning struct {
+	InstanceID     string    `gorm:"primaryKey;column:instance_id" json:"instanceId"`
+	ConfigID       string    `gorm:"column:config_id;index" json:"configId"`
+	Status         string    `gorm:"column:status" json:"status"` // applied / rolled_back
+	SwapSizeMB     int       `gorm:"column:swap_size_mb" json:"swapSizeMb"`
+	SwapCreated    bool      `gorm:"column:swap_created" json:"swapCreated"`                 // 交换文件由面板创建，回滚时删除
+	Sysctl         string    `gorm:"column:sysctl;type:text" json:"sysctl"`                  // 应用的内核参数，JSON 对象
+	PreviousSysctl string    `gorm:"column:previous_sysctl;type:text" json:"previousSysctl"` // 应用前的原值，JSON 对象
+	Output         string    `gorm:"column:output;type:text" json:"output"`
+	UpdateTime     time.Time `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (InstanceTuning) TableName() string {
+	return "instance_tuning"
+}
+
+// OSConversionJob 替换引导卷转换操作系统的任务，每步完成后保存进度以便中断后继续
+type OSConversionJob struct {
+	ID                string    `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID          string    `gorm:"column:config_id;index" json:"configId"`
+	InstanceID        string    `gorm:"column:instance_id;index" json:"instanceId"`
+	InstanceName      string    `gorm:"column:instance_name" json:"instanceName"`
+	TargetOS          string    `gorm:"column:target_os" json:"targetOs"`
+	TargetVersion     string    `gorm:"column:target_version" json:"targetVersion"` // 为空时使用最新的非 Minimal 版本
+	ImageID           string    `gorm:"column:image_id" json:"imageId"`
+	OldImageID        string    `gorm:"column:old_image_id" json:"oldImageId"`
+	OldBootVolumeID   string    `gorm:"column:old_boot_volume_id" json:"oldBootVolumeId"`
+	BootVolumeSizeGB  int64     `gorm:"column:boot_volume_size_gb" json:"bootVolumeSizeGb"`
+	BackupID          string    `gorm:"column:backup_id" json:"backupId"`
+	KeepOldBootVolume bool      `gorm:"column:keep_old_boot_volume" json:"keepOldBootVolume"`
+	OriginalPublicIP  string    `gorm:"column:original_public_ip" json:"originalPublicIp"`
+	PublicIP          string    `gorm:"column:public_ip" json:"publicIp"`
+	Step              int       `gorm:"column:step" json:"step"` // 已完成的步骤
+	TotalSteps        int       `gorm:"column:total_steps" json:"totalSteps"`
+	Status            string    `gorm:"column:status;index" json:"status"` // running / failed / completed
+	Message           string    `gorm:"column:message;type:text" json:"message"`
+	Error             string    `gorm:"column:error;type:text" json:"error"`
+	CreateTime        time.Time `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime        time.Time `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (OSConversionJob) TableName() string {
+	return "os_conversion_job"
+}
+
+// AutoRescueJob 自动救援任务，每步完成后保存，面板重启中断的任务可据此查看进度与已创建的资源
+type AutoRescueJob struct {
+	ID           string    `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID     string    `gorm:"column:config_id;index" json:"configId"`
+	InstanceID   string    `gorm:"column:instance_id;index" json:"instanceId"`
+	InstanceName string    `gorm:"column:instance_name" json:"instanceName"`
+	KeepBackup   bool      `gorm:"column:keep_backup" json:"keepBackup"`
+	StepLog      string    `gorm:"column:step_log;type:text" json:"-"`        // 步骤进度，JSON 数组
+	BackupID     string    `gorm:"column:backup_id" json:"backupId"`          // 原引导卷的备份
+	BootVolumeID string    `gorm:"column:boot_volume_id" json:"bootVolumeId"` // 从备份创建的新引导卷
+	PublicIP     string    `gorm:"column:public_ip" json:"publicIp"`
+	Status       string    `gorm:"column:status;index" json:"status"` // running / completed / error
+	Error        string    `gorm:"column:error;type:text" json:"error"`
+	CreateTime   time.Time `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime   time.Time `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (AutoRescueJob) TableName() string {
+	return "auto_rescue_job"
+}
+
+// AsyncJob 后台执行的长时间操作（克隆、重建、恢复备份、500Mbps 等），每次进度变化时保存
+type AsyncJob struct {
+	ID         string     `gorm:"primaryKey;column:id" json:"id"`
+	Kind       string     `gorm:"column:kind;index" json:"kind"`
+	ConfigID   string     `gorm:"column:config_id;index" json:"configId"`
+	ResourceID string     `gorm:"column:resource_id;index" json:"resourceId"` // 操作的实例或备份
+	Node       string     `gorm:"column:node" json:"node"`
+	Params     string     `gorm:"column:params;type:text" json:"-"`   // JSON
+	Status     string     `gorm:"column:status;index" json:"status"`  // running / cancelling / completed / error / cancelled / interrupted
+	StepLog    string     `gorm:"column:step_log;type:text" json:"-"` // 步骤进度，JSON 数组
+	Result     string     `gorm:"column:result;type:text" json:"-"`   // JSON，失败时可能为已完成部分的结果
+	Error      string     `gorm:"column:error;type:text" json:"error"`
+	CreateTime time.Time  `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime time.Time  `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+	FinishTime *time.Time `gorm:"column:finish_time" json:"finishTime"`
+}
+
+func (AsyncJob) TableName() string {
+	return "async_job"
+}
+
+// TerminalRecording Web SSH 终端会话录像，内容以 asciicast v2 格式保存在文件中
+type TerminalRecording struct {
+	ID         string     `gorm:"primaryKey;column:id" json:"id"`
+	ConfigID   string     `gorm:"column:config_id;index" json:"configId"`
+	InstanceID string     `gorm:"column:instance_id;index" json:"instanceId"`
+	Host       string     `gorm:"column:host" json:"host"`
+	Username   string     `gorm:"column:username" json:"username"`
+	FilePath   string     `gorm:"column:file_path" json:"-"`
+	Size       int64      `gorm:"column:size" json:"size"` // 录像文件字节数，会话结束时更新
+	StartTime  time.Time  `gorm:"column:start_time;index" json:"startTime"`
+	EndTime    *time.Time `gorm:"column:end_time" json:"endTime"`
+}
+
+func (TerminalRecording) TableName() string {
+	return "terminal_recording"
+}
+
+// Favorite 面板用户收藏的配置或实例，在列表中排在最前
+type Favorite struct {
+	ID         string    `gorm:"primaryKey;column:id" json:"id"`
+	Username   string    `gorm:"column:username;uniqueIndex:idx_favorite_target" json:"username"`
+	TargetType string    `gorm:"column:target_type;uniqueIndex:idx_favorite_target" json:"targetType"` // config / instance
+	TargetID   string    `gorm:"column:target_id;uniqueIndex:idx_favorite_target" json:"targetId"`
+	ConfigID   string    `gorm:"column:config_id;index" json:"configId"` // 收藏配置时与 TargetID 相同
+	CreateTime time.Time `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+func (Favorite) TableName() string {
+	return "favorite"
+}
+
+// LaunchAttempt 开机任务已提交的实例创建，用于发现创建失败后残留的实例与引导卷
+type LaunchAttempt struct {
+	InstanceID    string    `gorm:"primaryKey;column:instance_id" json:"instanceId"`
+	ConfigID      string    `gorm:"column:config_id;index" json:"configId"`
+	TaskID        string    `gorm:"column:task_id;index" json:"taskId"`
+	Region        string    `gorm:"column:region" json:"region"`
+	CompartmentID string    `gorm:"column:compartment_id" json:"compartmentId"`
+	DisplayName   string    `gorm:"column:display_name" json:"displayName"`
+	Status        string    `gorm:"column:status;index" json:"status"`       // pending / cleaned / failed
+	Message       string    `gorm:"column:message;type:text" json:"message"` // 清理结果
+	CreateTime    time.Time `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime    time.Time `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+func (LaunchAttempt) TableName() string {
+	return "launch_attempt"
+}
+
+// JobLease 后台作业的执行权租约，共享数据库的多个实例中只有持有者按计划执行作业
+type JobLease struct {
+	JobName  string    `gorm:"primaryKey;column:job_name" json:"jobName"`
+	Owner    string    `gorm:"column:owner" json:"owner"` // 持有租约的实例标识
+	ExpireAt time.Time `gorm:"column:expire_at" json:"expireAt"`
+}
+
+func (JobLease) TableName() string {
+	return "job_lease"
+}
+
+// JobRun 后台作业运行记录
+type JobRun struct {
+	ID         string     `gorm:"primaryKey;column:id" json:"id"`
+	JobName    string     `gorm:"column:job_name;index" json:"jobName"`
+	Node       string     `gorm:"column:node;index" json:"node"` // 执行作业的实例标识
+	Trigger    string     `gorm:"column:trigger" json:"trigger"` // schedule / manual
+	Status     string     `gorm:"column:status" json:"status"`   // running / success / failed
+	Attempts   int        `gorm:"column:attempts" json:"attempts"`
+	Message    string     `gorm:"column:message;type:text" json:"message"` // 成功时为结果摘要，失败时为最后一次的错误
+	StartTime  time.Time  `gorm:"column:start_time;index" json:"startTime"`
+	EndTime    *time.Time `gorm:"column:end_time" json:"endTime"`
+	DurationMs int64      `gorm:"column:duration_ms" json:"durationMs"`
+}
+
+func (JobRun) TableName() string {
+	return "job_run"
+}
+
+// SchemaVersion 当前数据库结构版本，表结构发生变化时递增
+const SchemaVersion = 44
+
+// SettingSchemaVersion 数据库结构版本在 sys_setting 中的键
+const SettingSchemaVersion = "schema_version"
+
+// AllModels 返回所有需要迁移的模型
+func AllModels() []interface{} {
+	return []interface{}{
 		&OciUser{},
 		&OciCreateTask{},
 		&TaskLog{},
@@ -422,5 +1092,39 @@ func AutoMigrate(db *gorm.DB) error {
 		&OciImageCache{},
 		&SSHKey{},
 		&InstancePreset{},
-	)
+		&TelegramAuditLog{},
+		&TrafficAlertRule{},
+		&TrafficDailyStat{},
+		&InstanceProtection{},
+		&PostProvisionAction{},
+		&CapacityProbe{},
+		&SecurityFinding{},
+		&TaskLease{},
+		&InventoryItem{},
+		&JobRun{},
+		&AccessLink{},
+		&PowerSchedule{},
+		&OciEvent{},
+		&IPRotationSchedule{},
+		&PublicIPHistory{},
+		&BackupSchedule{},
+		&BackupRetentionPolicy{},
+		&InstanceSSHPort{},
+		&TrafficQuota{},
+		&IdleKeepAlive{},
+		&InstanceTuning{},
+		&OSConversionJob{},
+		&TerminalRecording{},
+		&Favorite{},
+		&InstanceListSnapshot{},
+		&LaunchAttempt{},
+		&NotificationLog{},
+		&JobLease{},
+		&AutoRescueJob{},
+		&AsyncJob{},
+	}
+}
+
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(AllModels()...)
 }
@@ -1,6 +1,8 @@
 package router
 
 import (
+	"time"
+
 	"github.com/adiecho/oci-panel/internal/config"
 	"github.com/adiecho/oci-panel/internal/controllers"
 	"github.com/adiecho/oci-panel/internal/middleware"
@@ -9,13 +11,17 @@ import (
 )
 
 type Services struct {
-	Scheduler *services.SchedulerService
-	Task      *services.TaskService
-	Telegram  *services.TelegramService
+	Scheduler    *services.SchedulerService
+	Task         *services.TaskService
+	Telegram     *services.TelegramService
+	Diagnostics  *services.DiagnosticsService
+	TrafficAlert *services.TrafficAlertService
+	Jobs         *services.JobService
+	AsyncJobs    *services.AsyncJobService
 }
 
 func Setup(r *gin.Engine, cfg *config.Config) *Services {
-	r.Use(middleware.CORS())
+	r.Use(middleware.CORS(cfg.CORS.AllowOrigins))
 	r.Use(middleware.AuthMiddleware())
 
 	// 静态资源 - 前端构建文件
@@ -28,20 +34,69 @@ func Setup(r *gin.Engine, cfg *config.Config) *Services {
 	})
 
 	ociService := services.NewOCIService(cfg)
-	instanceService := services.NewInstanceService(ociService)
 	ipService := services.NewIpService(ociService)
 	_ = services.NewVolumeService(ociService)
 	wsService := services.NewWebSocketService()
 	schedulerService := services.NewSchedulerService(ociService)
-	taskService := services.NewTaskService(ociService)
 	telegramService := services.NewTelegramService(ociService)
+	asyncJobService := services.NewAsyncJobService()
+	asyncJobService.SetNodeID(cfg.Server.NodeID)
+	instanceService := services.NewInstanceService(ociService, telegramService, asyncJobService)
+	taskService := services.NewTaskService(ociService, telegramService)
+	taskService.SetNodeID(cfg.Server.NodeID)
+	diagnosticsService := services.NewDiagnosticsService(ociService, taskService, telegramService)
+	housekeepingService := services.NewHousekeepingService()
+	trafficAlertService := services.NewTrafficAlertService(ociService, telegramService)
+	securityAuditService := services.NewSecurityAuditService(ociService, telegramService)
+	discoveryService := services.NewDiscoveryService(ociService)
+	powerScheduleService := services.NewPowerScheduleService(ociService, telegramService)
+	ipRotationService := services.NewIPRotationService(ociService, telegramService, taskService)
+	backupScheduleService := services.NewBackupScheduleService(ociService, telegramService)
+	backupRetentionService := services.NewBackupRetentionService(ociService)
+	trafficQuotaService := services.NewTrafficQuotaService(ociService, telegramService)
+	keepAliveService := services.NewIdleKeepAliveService(ociService, telegramService)
+	reportExportService := services.NewReportExportService(ociService)
+	launchCleanupService := services.NewLaunchCleanupService(ociService)
+	customImageService := services.NewCustomImageService(ociService)
+	backupService := services.NewBackupService(ociService, asyncJobService)
+	gatewayService := services.NewGatewayService(ociService)
+	nlbService := services.NewNLBService(ociService)
+	vpuAdvisorService := services.NewVpuAdvisorService(ociService)
+	eventService := services.NewOCIEventService(ociService)
+	serialConsoleService := services.NewSerialConsoleService(ociService)
+	webTerminalService := services.NewWebTerminalService(ociService)
+	fileManagerService := services.NewFileManagerService(ociService)
+	jobService := services.NewJobService()
+	jobService.SetNodeID(cfg.Server.NodeID)
+	for _, job := range housekeepingService.Jobs() {
+		jobService.Register(job, services.JobOptions{MaxRetries: 1, RetryDelay: 5 * time.Minute})
+	}
+	jobService.Register(securityAuditService.Job(), services.JobOptions{MaxRetries: 2, RetryDelay: 10 * time.Minute})
+	// 定时开关机、IP 更换与备份由计划自身记录每次结果，失败时不重试，避免错过时间点后再执行
+	jobService.Register(powerScheduleService.Job(), services.JobOptions{})
+	jobService.Register(ipRotationService.Job(), services.JobOptions{})
+	jobService.Register(backupScheduleService.Job(), services.JobOptions{})
+	jobService.Register(backupRetentionService.Job(), services.JobOptions{})
+	jobService.Register(trafficQuotaService.Job(), services.JobOptions{MaxRetries: 1, RetryDelay: 10 * time.Minute})
+	jobService.Register(keepAliveService.Job(), services.JobOptions{})
+	jobService.Register(reportExportService.Job(), services.JobOptions{MaxRetries: 2, RetryDelay: 30 * time.Minute})
+	jobService.Register(instanceService.SnapshotJob(), services.JobOptions{})
+	jobService.Register(launchCleanupService.Job(), services.JobOptions{})
+	jobService.Register(vpuAdvisorService.Job(), services.JobOptions{Timeout: 30 * time.Minute})
+	onboardingService := services.NewOnboardingService(cfg, ociService, telegramService)
 
 	wsCtrl := controllers.NewWebSocketController(wsService)
 	r.GET("/ws/logs", wsCtrl.HandleWebSocket)
+	serialConsoleCtrl := controllers.NewSerialConsoleController(serialConsoleService)
+	r.GET("/ws/console", serialConsoleCtrl.Attach)
+	webTerminalCtrl := controllers.NewWebTerminalController(webTerminalService)
+	r.GET("/ws/terminal", webTerminalCtrl.Attach)
+	instanceCtrl := controllers.NewInstanceController(instanceService)
+	r.GET("/ws/rescue", instanceCtrl.StreamAutoRescue)
 
 	api := r.Group("/api")
 	{
-		sysCtrl := controllers.NewSysController(cfg, schedulerService)
+		sysCtrl := controllers.NewSysController(cfg, schedulerService, diagnosticsService, housekeepingService)
 		sys := api.Group("/sys")
 		{
 			sys.POST("/login", sysCtrl.Login)
@@ -50,10 +105,26 @@ func Setup(r *gin.Engine, cfg *config.Config) *Services {
 			sys.POST("/getSysCfg", sysCtrl.GetSysCfg)
 			sys.POST("/updateCacheCfg", sysCtrl.UpdateCacheCfg)
 			sys.POST("/refreshCache", sysCtrl.RefreshCache)
+			sys.POST("/refreshStaleCache", sysCtrl.RefreshStaleCache)
 			sys.POST("/getAuthStatus", sysCtrl.GetAuthStatus)
 			sys.POST("/generateMfaSecret", sysCtrl.GenerateMfaSecret)
 			sys.POST("/enableMfa", sysCtrl.EnableMfa)
 			sys.POST("/disableMfa", sysCtrl.DisableMfa)
+			sys.GET("/diagnostics", sysCtrl.Diagnostics)
+			sys.POST("/runHousekeeping", sysCtrl.RunHousekeeping)
+			sys.POST("/updateLogRetention", sysCtrl.UpdateLogRetention)
+			sys.POST("/purgeTaskLogs", sysCtrl.PurgeTaskLogs)
+		}
+
+		onboardingCtrl := controllers.NewOnboardingController(onboardingService)
+		onboarding := api.Group("/onboarding")
+		{
+			onboarding.GET("/status", onboardingCtrl.GetStatus)
+			onboarding.POST("/admin", onboardingCtrl.SetupAdmin)
+			onboarding.POST("/ociConfig", onboardingCtrl.ImportOciConfig)
+			onboarding.POST("/sshKey", onboardingCtrl.GenerateSSHKey)
+			onboarding.POST("/telegram", onboardingCtrl.SetupTelegram)
+			onboarding.POST("/complete", onboardingCtrl.Complete)
 		}
 
 		passkeyCtrl := controllers.NewPasskeyController(cfg)
@@ -73,16 +144,22 @@ func Setup(r *gin.Engine, cfg *config.Config) *Services {
 			oci.POST("/userPage", ociCtrl.UserPage)
 			oci.POST("/addCfg", ociCtrl.AddCfg)
 			oci.POST("/updateCfgName", ociCtrl.UpdateCfgName)
+			oci.POST("/updateCfgDefaults", ociCtrl.UpdateCfgDefaults)
 			oci.POST("/removeCfg", ociCtrl.RemoveCfg)
 			oci.POST("/createInstance", ociCtrl.CreateInstance)
 			oci.POST("/createTaskPage", ociCtrl.CreateTaskPage)
 			oci.POST("/uploadKey", ociCtrl.UploadKey)
 			oci.POST("/details", ociCtrl.GetConfigDetails)
+			oci.POST("/details/summary", ociCtrl.GetConfigSummary)
 			oci.POST("/details/instances", ociCtrl.GetConfigInstances)
 			oci.POST("/details/volumes", ociCtrl.GetConfigVolumes)
 			oci.POST("/details/vcns", ociCtrl.GetConfigVCNs)
+			oci.POST("/details/compartments", ociCtrl.ListCompartments)
 			oci.POST("/details/clearCache", ociCtrl.ClearConfigCache)
 			oci.POST("/tenant/info", ociCtrl.GetTenantInfo)
+			oci.POST("/credentialsHealth", ociCtrl.GetCredentialsHealth)
+			oci.POST("/checkAlive", ociCtrl.CheckConfigsAlive)
+			oci.POST("/regionLatency", ociCtrl.GetRegionLatency)
 			oci.POST("/tenant/updatePwdEx", ociCtrl.UpdatePasswordExpiry)
 			oci.POST("/tenant/updateUserInfo", ociCtrl.UpdateUserInfo)
 			oci.POST("/tenant/deleteUser", ociCtrl.DeleteUser)
@@ -97,26 +174,59 @@ func Setup(r *gin.Engine, cfg *config.Config) *Services {
 			oci.POST("/vcn/releaseSecurityRules", ociCtrl.ReleaseSecurityRules)
 			oci.POST("/vcn/delete", ociCtrl.DeleteVcn)
 			oci.POST("/images", ociCtrl.ListImages)
+			oci.POST("/platformImages", ociCtrl.ListPlatformImages)
+			oci.POST("/capacity", ociCtrl.CheckCapacity)
 		}
 
-		instanceCtrl := controllers.NewInstanceController(instanceService)
 		instance := api.Group("/instance")
 		{
 			instance.POST("/list", instanceCtrl.ListInstances)
+			instance.POST("/refreshList", instanceCtrl.RefreshInstanceList)
+			instance.POST("/getListCache", instanceCtrl.GetListCache)
+			instance.POST("/updateListCache", instanceCtrl.UpdateListCache)
 			instance.POST("/start", instanceCtrl.StartInstance)
 			instance.POST("/stop", instanceCtrl.StopInstance)
 			instance.POST("/reboot", instanceCtrl.RebootInstance)
 			instance.POST("/terminate", instanceCtrl.TerminateInstance)
+			instance.POST("/batch", instanceCtrl.BatchInstanceAction)
+			instance.POST("/setProtection", instanceCtrl.SetProtection)
+			instance.POST("/sshPort", instanceCtrl.SetSSHPort)
+			instance.GET("/detail", instanceCtrl.GetInstanceDetail)
+			instance.POST("/metrics", instanceCtrl.GetInstanceMetrics)
+			instance.POST("/tags", instanceCtrl.GetResourceTags)
+			instance.POST("/updateTags", instanceCtrl.UpdateResourceTags)
+			instance.POST("/getProtectTag", instanceCtrl.GetProtectTag)
+			instance.POST("/updateProtectTag", instanceCtrl.UpdateProtectTag)
 			instance.POST("/updateName", instanceCtrl.UpdateInstanceName)
 			instance.POST("/changeIP", instanceCtrl.ChangePublicIP)
+			instance.POST("/terraform", instanceCtrl.ExportTerraform)
 			instance.POST("/updateConfig", instanceCtrl.UpdateInstanceConfig)
+			instance.POST("/precheckConfig", instanceCtrl.PrecheckInstanceConfig)
+			instance.POST("/rebuildShape", instanceCtrl.RebuildShape)
+			instance.POST("/rebuildShapeStatus", instanceCtrl.RebuildShapeStatus)
+			instance.POST("/changeShape", instanceCtrl.ChangeShape)
+			instance.POST("/changeShapeStatus", instanceCtrl.ChangeShapeStatus)
+			instance.POST("/clone", instanceCtrl.CloneInstance)
+			instance.POST("/cloneStatus", instanceCtrl.CloneInstanceStatus)
+			instance.POST("/convertOS", instanceCtrl.ConvertOS)
+			instance.POST("/convertOSStatus", instanceCtrl.ConvertOSStatus)
+			instance.POST("/convertOSResume", instanceCtrl.ResumeConvertOS)
+			instance.POST("/convertOSList", instanceCtrl.ListConvertOS)
 			instance.POST("/updateBootVolume", instanceCtrl.UpdateBootVolume)
+			instance.POST("/enablePasswordLogin", instanceCtrl.EnablePasswordLogin)
+			instance.POST("/passwordUserData", instanceCtrl.PasswordUserData)
+			instance.POST("/tuning", instanceCtrl.GetInstanceTuning)
+			instance.POST("/applyTuning", instanceCtrl.ApplyInstanceTuning)
+			instance.POST("/rollbackTuning", instanceCtrl.RollbackInstanceTuning)
 			instance.POST("/createCloudShell", instanceCtrl.CreateCloudShell)
 			instance.POST("/attachIPv6", instanceCtrl.AttachIPv6)
 			instance.POST("/autoRescue", instanceCtrl.AutoRescue)
+			instance.POST("/autoRescueStatus", instanceCtrl.AutoRescueStatus)
+			instance.POST("/autoRescueList", instanceCtrl.ListAutoRescue)
 			instance.POST("/check500MbpsSupport", instanceCtrl.Check500MbpsSupport)
 			instance.POST("/enable500Mbps", instanceCtrl.Enable500Mbps)
 			instance.POST("/disable500Mbps", instanceCtrl.Disable500Mbps)
+			instance.POST("/500MbpsStatus", instanceCtrl.Bandwidth500MbpsStatus)
 		}
 
 		bootVolume := api.Group("/bootVolume")
@@ -129,6 +239,38 @@ func Setup(r *gin.Engine, cfg *config.Config) *Services {
 		{
 			ip.POST("/change", ipCtrl.ChangePublicIp)
 			ip.POST("/attachIpv6", ipCtrl.AttachIpv6)
+			ip.POST("/listIpv6", ipCtrl.ListIpv6)
+			ip.POST("/detachIpv6", ipCtrl.DetachIpv6)
+			ip.POST("/rotateIpv6", ipCtrl.RotateIpv6)
+			ip.POST("/enableVcnIpv6", ipCtrl.EnableVcnIpv6)
+			ip.POST("/enableSubnetIpv6", ipCtrl.EnableSubnetIpv6)
+		}
+
+		gatewayCtrl := controllers.NewGatewayController(gatewayService)
+		gateway := api.Group("/gateway")
+		{
+			gateway.POST("/list", gatewayCtrl.ListGateways)
+			gateway.POST("/create", gatewayCtrl.CreateGateway)
+			gateway.POST("/delete", gatewayCtrl.DeleteGateway)
+			gateway.POST("/routeTables", gatewayCtrl.ListRouteTables)
+			gateway.POST("/attachRoute", gatewayCtrl.AttachGatewayRoute)
+			gateway.POST("/detachRoute", gatewayCtrl.DetachGatewayRoute)
+		}
+
+		nlbCtrl := controllers.NewNLBController(nlbService)
+		nlb := api.Group("/nlb")
+		{
+			nlb.POST("/list", nlbCtrl.ListNLBs)
+			nlb.POST("/detail", nlbCtrl.GetNLB)
+			nlb.POST("/health", nlbCtrl.GetNLBHealth)
+			nlb.POST("/create", nlbCtrl.CreateNLB)
+			nlb.POST("/delete", nlbCtrl.DeleteNLB)
+			nlb.POST("/createListener", nlbCtrl.CreateListener)
+			nlb.POST("/deleteListener", nlbCtrl.DeleteListener)
+			nlb.POST("/createBackendSet", nlbCtrl.CreateBackendSet)
+			nlb.POST("/deleteBackendSet", nlbCtrl.DeleteBackendSet)
+			nlb.POST("/createBackend", nlbCtrl.CreateBackend)
+			nlb.POST("/deleteBackend", nlbCtrl.DeleteBackend)
 		}
 
 		keyCtrl := controllers.NewKeyController()
@@ -146,13 +288,41 @@ func Setup(r *gin.Engine, cfg *config.Config) *Services {
 		task := api.Group("/task")
 		{
 			task.POST("/create", taskCtrl.CreateTask)
+			task.POST("/templateFromInstance", taskCtrl.TaskTemplateFromInstance)
 			task.POST("/list", taskCtrl.TaskList)
 			task.POST("/start", taskCtrl.StartTask)
 			task.POST("/stop", taskCtrl.StopTask)
 			task.POST("/delete", taskCtrl.DeleteTask)
 			task.POST("/batchDelete", taskCtrl.BatchDeleteTask)
+			task.POST("/recycleBin", taskCtrl.RecycleBin)
+			task.POST("/restore", taskCtrl.RestoreTask)
+			task.POST("/purge", taskCtrl.PurgeTask)
+			task.POST("/export", taskCtrl.ExportTasks)
+			task.POST("/import", taskCtrl.ImportTasks)
+			task.POST("/groups", taskCtrl.TaskGroups)
+			task.POST("/setGroup", taskCtrl.SetTaskGroup)
+			task.POST("/group/start", taskCtrl.StartTaskGroup)
+			task.POST("/group/stop", taskCtrl.StopTaskGroup)
+			task.POST("/group/delete", taskCtrl.DeleteTaskGroup)
 			task.POST("/logs", taskCtrl.TaskLogs)
+			task.POST("/adStats", taskCtrl.TaskADStats)
+			task.POST("/analytics", taskCtrl.TaskAnalytics)
+			task.POST("/probe", taskCtrl.ProbeTask)
+			task.POST("/probeStats", taskCtrl.CapacityProbeStats)
 			task.POST("/clearLogs", taskCtrl.ClearTaskLogs)
+			task.POST("/updateLogRetention", taskCtrl.UpdateLogRetention)
+			task.POST("/updatePriority", taskCtrl.UpdateTaskPriority)
+			task.POST("/updateWindows", taskCtrl.UpdateExecuteWindows)
+			task.POST("/updateHook", taskCtrl.UpdateTaskHook)
+			task.POST("/getConcurrency", taskCtrl.GetTaskConcurrency)
+			task.POST("/updateConcurrency", taskCtrl.UpdateTaskConcurrency)
+		}
+
+		guardRailsCtrl := controllers.NewGuardRailsController(taskService)
+		guardRails := api.Group("/guardRails")
+		{
+			guardRails.POST("/get", guardRailsCtrl.GetGuardRails)
+			guardRails.POST("/update", guardRailsCtrl.UpdateGuardRails)
 		}
 
 		presetCtrl := controllers.NewPresetController()
@@ -165,6 +335,214 @@ func Setup(r *gin.Engine, cfg *config.Config) *Services {
 			preset.GET("/detail", presetCtrl.GetPreset)
 		}
 
+		postActionCtrl := controllers.NewPostActionController()
+		postAction := api.Group("/postAction")
+		{
+			postAction.POST("/create", postActionCtrl.CreatePostAction)
+			postAction.POST("/update", postActionCtrl.UpdatePostAction)
+			postAction.POST("/delete", postActionCtrl.DeletePostAction)
+			postAction.GET("/list", postActionCtrl.ListPostActions)
+		}
+
+		inventoryCtrl := controllers.NewInventoryController(discoveryService)
+		inventory := api.Group("/inventory")
+		{
+			inventory.POST("/discover", inventoryCtrl.StartDiscovery)
+			inventory.POST("/discoverStatus", inventoryCtrl.DiscoveryStatus)
+			inventory.POST("/adopt", inventoryCtrl.AdoptResources)
+			inventory.POST("/list", inventoryCtrl.ListInventory)
+			inventory.POST("/update", inventoryCtrl.UpdateInventoryItem)
+			inventory.POST("/remove", inventoryCtrl.RemoveInventoryItems)
+		}
+
+		securityAuditCtrl := controllers.NewSecurityAuditController(securityAuditService)
+		security := api.Group("/security")
+		{
+			security.POST("/report", securityAuditCtrl.GetReport)
+			security.POST("/scan", securityAuditCtrl.Scan)
+			security.POST("/remediate", securityAuditCtrl.Remediate)
+		}
+
+		accessLinkCtrl := controllers.NewAccessLinkController(instanceService)
+		accessLink := api.Group("/accessLink")
+		{
+			accessLink.POST("/create", accessLinkCtrl.CreateAccessLink)
+			accessLink.POST("/list", accessLinkCtrl.ListAccessLinks)
+			accessLink.POST("/revoke", accessLinkCtrl.RevokeAccessLink)
+		}
+		operator := api.Group("/operator")
+		{
+			operator.POST("/info", accessLinkCtrl.OperatorInfo)
+			operator.POST("/action", accessLinkCtrl.OperatorAction)
+		}
+
+		powerScheduleCtrl := controllers.NewPowerScheduleController(powerScheduleService)
+		powerSchedule := api.Group("/powerSchedule")
+		{
+			powerSchedule.POST("/create", powerScheduleCtrl.CreatePowerSchedule)
+			powerSchedule.POST("/list", powerScheduleCtrl.ListPowerSchedules)
+			powerSchedule.POST("/update", powerScheduleCtrl.UpdatePowerSchedule)
+			powerSchedule.POST("/delete", powerScheduleCtrl.DeletePowerSchedule)
+		}
+
+		ipRotationCtrl := controllers.NewIPRotationController(ipRotationService)
+		ipRotation := api.Group("/ipRotation")
+		{
+			ipRotation.POST("/create", ipRotationCtrl.CreateIPRotation)
+			ipRotation.POST("/list", ipRotationCtrl.ListIPRotations)
+			ipRotation.POST("/update", ipRotationCtrl.UpdateIPRotation)
+			ipRotation.POST("/delete", ipRotationCtrl.DeleteIPRotation)
+			ipRotation.POST("/history", ipRotationCtrl.IPHistory)
+			ipRotation.POST("/batch", ipRotationCtrl.StartBatchIPChange)
+			ipRotation.POST("/batchStatus", ipRotationCtrl.BatchIPChangeStatus)
+		}
+
+		backupScheduleCtrl := controllers.NewBackupScheduleController(backupScheduleService)
+		backupSchedule := api.Group("/backupSchedule")
+		{
+			backupSchedule.POST("/create", backupScheduleCtrl.CreateBackupSchedule)
+			backupSchedule.POST("/list", backupScheduleCtrl.ListBackupSchedules)
+			backupSchedule.POST("/update", backupScheduleCtrl.UpdateBackupSchedule)
+			backupSchedule.POST("/delete", backupScheduleCtrl.DeleteBackupSchedule)
+		}
+
+		backupRetentionCtrl := controllers.NewBackupRetentionController(backupRetentionService)
+		backupRetention := api.Group("/backupRetention")
+		{
+			backupRetention.POST("/get", backupRetentionCtrl.GetPolicy)
+			backupRetention.POST("/save", backupRetentionCtrl.SavePolicy)
+			backupRetention.POST("/delete", backupRetentionCtrl.DeletePolicy)
+			backupRetention.POST("/preview", backupRetentionCtrl.Preview)
+			backupRetention.POST("/run", backupRetentionCtrl.Run)
+		}
+
+		trafficQuotaCtrl := controllers.NewTrafficQuotaController(trafficQuotaService)
+		trafficQuota := api.Group("/trafficQuota")
+		{
+			trafficQuota.POST("/get", trafficQuotaCtrl.GetQuota)
+			trafficQuota.POST("/save", trafficQuotaCtrl.SaveQuota)
+			trafficQuota.POST("/delete", trafficQuotaCtrl.DeleteQuota)
+			trafficQuota.POST("/check", trafficQuotaCtrl.CheckQuota)
+		}
+
+		reportExportCtrl := controllers.NewReportExportController(reportExportService)
+		reportExport := api.Group("/reportExport")
+		{
+			reportExport.POST("/get", reportExportCtrl.GetSettings)
+			reportExport.POST("/save", reportExportCtrl.SaveSettings)
+			reportExport.POST("/preview", reportExportCtrl.Preview)
+			reportExport.POST("/run", reportExportCtrl.Run)
+		}
+
+		customImageCtrl := controllers.NewCustomImageController(customImageService)
+		customImage := api.Group("/customImage")
+		{
+			customImage.POST("/list", customImageCtrl.ListCustomImages)
+			customImage.POST("/create", customImageCtrl.CreateCustomImage)
+			customImage.POST("/delete", customImageCtrl.DeleteCustomImage)
+			customImage.POST("/export", customImageCtrl.ExportCustomImage)
+		}
+
+		backupCtrl := controllers.NewBackupController(backupService)
+		backup := api.Group("/backup")
+		{
+			backup.POST("/list", backupCtrl.ListBootVolumeBackups)
+			backup.POST("/create", backupCtrl.CreateBootVolumeBackup)
+			backup.POST("/delete", backupCtrl.DeleteBootVolumeBackup)
+			backup.POST("/restore", backupCtrl.RestoreBootVolumeBackup)
+			backup.POST("/restoreStatus", backupCtrl.RestoreStatus)
+			backup.POST("/policyList", backupCtrl.ListBackupPolicies)
+			backup.POST("/policyGet", backupCtrl.GetBackupPolicy)
+			backup.POST("/policyAssign", backupCtrl.AssignBackupPolicy)
+			backup.POST("/policyRemove", backupCtrl.RemoveBackupPolicy)
+		}
+
+		launchCleanupCtrl := controllers.NewLaunchCleanupController(launchCleanupService)
+		launchCleanup := api.Group("/launchCleanup")
+		{
+			launchCleanup.POST("/get", launchCleanupCtrl.GetSettings)
+			launchCleanup.POST("/update", launchCleanupCtrl.UpdateSettings)
+			launchCleanup.POST("/preview", launchCleanupCtrl.Preview)
+			launchCleanup.POST("/run", launchCleanupCtrl.Run)
+		}
+
+		vpuAdvisorCtrl := controllers.NewVpuAdvisorController(vpuAdvisorService, jobService)
+		vpuAdvisor := api.Group("/vpuAdvisor")
+		{
+			vpuAdvisor.POST("/scan", vpuAdvisorCtrl.Scan)
+			vpuAdvisor.POST("/downgrade", vpuAdvisorCtrl.Downgrade)
+		}
+
+		keepAliveCtrl := controllers.NewKeepAliveController(keepAliveService)
+		keepAlive := api.Group("/keepAlive")
+		{
+			keepAlive.POST("/get", keepAliveCtrl.GetKeepAlive)
+			keepAlive.POST("/save", keepAliveCtrl.SaveKeepAlive)
+			keepAlive.POST("/delete", keepAliveCtrl.DeleteKeepAlive)
+			keepAlive.POST("/run", keepAliveCtrl.RunKeepAlive)
+			keepAlive.POST("/risk", keepAliveCtrl.IdleRisk)
+		}
+
+		eventCtrl := controllers.NewOCIEventController(eventService)
+		events := api.Group("/events")
+		{
+			events.POST("/oci/:configId/:signature", eventCtrl.Receive)
+			events.POST("/webhookUrl", eventCtrl.GetWebhookURL)
+			events.POST("/bootstrap", eventCtrl.Bootstrap)
+			events.POST("/list", eventCtrl.ListEvents)
+		}
+
+		jobCtrl := controllers.NewJobController(jobService, asyncJobService)
+		jobs := api.Group("/jobs")
+		{
+			jobs.GET("", jobCtrl.ListJobs)
+			jobs.POST("/history", jobCtrl.JobHistory)
+			jobs.POST("/run", jobCtrl.RunJob)
+			jobs.POST("/asyncList", jobCtrl.ListAsyncJobs)
+			jobs.POST("/asyncDetail", jobCtrl.GetAsyncJob)
+			jobs.POST("/asyncCancel", jobCtrl.CancelAsyncJob)
+		}
+
+		console := api.Group("/console")
+		{
+			console.POST("/create", serialConsoleCtrl.Create)
+			console.POST("/close", serialConsoleCtrl.Close)
+		}
+
+		terminal := api.Group("/terminal")
+		{
+			terminal.POST("/create", webTerminalCtrl.Create)
+			terminal.POST("/close", webTerminalCtrl.Close)
+			terminal.POST("/recordings", webTerminalCtrl.Recordings)
+			terminal.POST("/recording/download", webTerminalCtrl.DownloadRecording)
+			terminal.POST("/recording/delete", webTerminalCtrl.DeleteRecording)
+		}
+
+		fileCtrl := controllers.NewFileManagerController(fileManagerService)
+		files := api.Group("/files")
+		{
+			files.POST("/connect", fileCtrl.Connect)
+			files.POST("/disconnect", fileCtrl.Disconnect)
+			files.POST("/list", fileCtrl.List)
+			files.POST("/read", fileCtrl.Read)
+			files.POST("/write", fileCtrl.Write)
+			files.POST("/upload", fileCtrl.Upload)
+			files.POST("/download", fileCtrl.Download)
+			files.POST("/mkdir", fileCtrl.Mkdir)
+			files.POST("/rename", fileCtrl.Rename)
+			files.POST("/delete", fileCtrl.Delete)
+		}
+
+		activityCtrl := controllers.NewActivityController()
+		api.POST("/activity", activityCtrl.List)
+
+		favoriteCtrl := controllers.NewFavoriteController()
+		favorite := api.Group("/favorite")
+		{
+			favorite.POST("/list", favoriteCtrl.List)
+			favorite.POST("/set", favoriteCtrl.Set)
+		}
+
 		telegramCtrl := controllers.NewTelegramController(telegramService)
 		telegram := api.Group("/telegram")
 		{
@@ -175,6 +553,10 @@ func Setup(r *gin.Engine, cfg *config.Config) *Services {
 			telegram.POST("/startBot", telegramCtrl.StartBot)
 			telegram.POST("/stopBot", telegramCtrl.StopBot)
 			telegram.GET("/status", telegramCtrl.GetBotStatus)
+			telegram.POST("/auditLogs", telegramCtrl.AuditLogs)
+			telegram.POST("/notificationLogs", telegramCtrl.NotificationLogs)
+			telegram.POST("/getQuietHours", telegramCtrl.GetQuietHours)
+			telegram.POST("/updateQuietHours", telegramCtrl.UpdateQuietHours)
 		}
 	}
 
@@ -184,8 +566,12 @@ func Setup(r *gin.Engine, cfg *config.Config) *Services {
 	})
 
 	return &Services{
-		Scheduler: schedulerService,
-		Task:      taskService,
-		Telegram:  telegramService,
+		Scheduler:    schedulerService,
+		Task:         taskService,
+		Telegram:     telegramService,
+		Diagnostics:  diagnosticsService,
+		TrafficAlert: trafficAlertService,
+		Jobs:         jobService,
+		AsyncJobs:    asyncJobService,
 	}
 }
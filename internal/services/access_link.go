@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	AccessLinkActionStart  = "start"
+	AccessLinkActionStop   = "stop"
+	AccessLinkActionReboot = "reboot"
+
+	// 访问链接最长有效期
+	MaxAccessLinkDuration = 7 * 24 * time.Hour
+)
+
+// accessLinkInstanceActions 访问链接操作对应的 OCI 实例操作
+var accessLinkInstanceActions = map[string]string{
+	AccessLinkActionStart:  "START",
+	AccessLinkActionStop:   "STOP",
+	AccessLinkActionReboot: "RESET",
+}
+
+// AccessLinkView 访问链接持有者可见的实例信息
+type AccessLinkView struct {
+	Name         string   `json:"name"`
+	InstanceName string   `json:"instanceName"`
+	State        string   `json:"state"`
+	Shape        string   `json:"shape"`
+	PublicIp     string   `json:"publicIp"`
+	PrivateIp    string   `json:"privateIp"`
+	Actions      []string `json:"actions"`
+	ExpireAt     string   `json:"expireAt"`
+}
+
+// hashAccessToken 计算令牌哈希，数据库中只保存哈希
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeAccessLinkActions 去重并校验操作
+func normalizeAccessLinkActions(actions []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, action := range actions {
+		action = strings.ToLower(strings.TrimSpace(action))
+		if _, ok := accessLinkInstanceActions[action]; !ok {
+			return nil, fmt.Errorf("不支持的操作: %s", action)
+		}
+		if !seen[action] {
+			seen[action] = true
+			result = append(result, action)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("至少选择一个允许的操作")
+	}
+	return result, nil
+}
+
+// CreateAccessLink 创建实例访问链接，返回链接记录和仅在创建时可见的令牌
+func (s *InstanceService) CreateAccessLink(userId, instanceId, name string, actions []string, duration time.Duration) (*models.AccessLink, string, error) {
+	if duration <= 0 || duration > MaxAccessLinkDuration {
+		return nil, "", fmt.Errorf("有效期需在 %d 小时以内", int(MaxAccessLinkDuration.Hours()))
+	}
+	actions, err := normalizeAccessLinkActions(actions)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, "", fmt.Errorf("user not found: %w", err)
+	}
+	instance, err := s.ociService.GetInstance(context.Background(), &user, instanceId)
+	if err != nil {
+		return nil, "", fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	link := models.AccessLink{
+		ID:           uuid.New().String(),
+		TokenHash:    hashAccessToken(token),
+		Name:         name,
+		ConfigID:     userId,
+		InstanceID:   instanceId,
+		InstanceName: stringValue(instance.DisplayName),
+		Actions:      strings.Join(actions, ","),
+		ExpireAt:     time.Now().Add(duration),
+	}
+	if err := database.GetDB().Create(&link).Error; err != nil {
+		return nil, "", err
+	}
+	return &link, token, nil
+}
+
+// ListAccessLinks 获取访问链接，configID 为空时返回所有配置
+func ListAccessLinks(configID string) ([]models.AccessLink, error) {
+	query := database.GetDB().Order("create_time DESC")
+	if configID != "" {
+		query = query.Where("config_id = ?", configID)
+	}
+	var links []models.AccessLink
+	err := query.Find(&links).Error
+	return links, err
+}
+
+// RevokeAccessLink 撤销访问链接，撤销后立即失效
+func RevokeAccessLink(id string) error {
+	result := database.GetDB().Where("id = ?", id).Delete(&models.AccessLink{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("访问链接不存在")
+	}
+	return nil
+}
+
+// resolveAccessLink 根据令牌查找未过期的访问链接
+func resolveAccessLink(token string) (*models.AccessLink, *models.OciUser, error) {
+	db := database.GetDB()
+	var link models.AccessLink
+	if token == "" || db.Where("token_hash = ?", hashAccessToken(token)).First(&link).Error != nil {
+		return nil, nil, fmt.Errorf("访问链接无效或已撤销")
+	}
+	if time.Now().After(link.ExpireAt) {
+		return nil, nil, fmt.Errorf("访问链接已过期")
+	}
+	var user models.OciUser
+	if err := db.Where("id = ?", link.ConfigID).First(&user).Error; err != nil {
+		return nil, nil, fmt.Errorf("访问链接对应的配置已删除")
+	}
+	return &link, &user, nil
+}
+
+// GetAccessLinkView 获取访问链接对应实例的当前状态
+func (s *InstanceService) GetAccessLinkView(token string) (*AccessLinkView, error) {
+	link, user, err := resolveAccessLink(token)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	instance, err := s.ociService.GetInstance(ctx, user, link.InstanceID)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+	view := &AccessLinkView{
+		Name:         link.Name,
+		InstanceName: stringValue(instance.DisplayName),
+		State:        string(instance.LifecycleState),
+		Shape:        stringValue(instance.Shape),
+		Actions:      strings.Split(link.Actions, ","),
+		ExpireAt:     link.ExpireAt.Format("2006-01-02 15:04:05"),
+	}
+	if vnic, err := s.ociService.getPrimaryVnic(ctx, user, link.InstanceID); err == nil {
+		view.PublicIp = stringValue(vnic.PublicIp)
+		view.PrivateIp = stringValue(vnic.PrivateIp)
+	}
+	return view, nil
+}
+
+// accessLinkAllows 判断访问链接是否授权了该操作
+func accessLinkAllows(link *models.AccessLink, action string) bool {
+	for _, a := range strings.Split(link.Actions, ",") {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecuteAccessLinkAction 通过访问链接执行实例操作，仅允许链接授权的操作
+func (s *InstanceService) ExecuteAccessLinkAction(token, action string) error {
+	link, user, err := resolveAccessLink(token)
+	if err != nil {
+		return err
+	}
+
+	action = strings.ToLower(strings.TrimSpace(action))
+	if !accessLinkAllows(link, action) {
+		return fmt.Errorf("访问链接未授权该操作")
+	}
+
+	if err := s.ociService.InstanceAction(context.Background(), user, link.InstanceID, accessLinkInstanceActions[action]); err != nil {
+		return fmt.Errorf("操作失败: %s", extractOCIErrorMessage(err))
+	}
+
+	database.GetDB().Model(link).Updates(map[string]interface{}{
+		"use_count":      gorm.Expr("use_count + 1"),
+		"last_used_time": time.Now(),
+	})
+	log.Printf("[AccessLink] %s (%s) executed %s on instance %s", link.ID, link.Name, action, link.InstanceName)
+	return nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+// setupTestDB 使用内存数据库，测试结束后恢复原数据库
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	previous := database.DB
+	if err := database.InitDB("file::memory:"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.DB = previous })
+}
+
+func TestNormalizeAccessLinkActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []string
+		want    []string
+		wantErr bool
+	}{
+		{"去重并统一大小写", []string{"Start", " stop ", "start"}, []string{"start", "stop"}, false},
+		{"不支持的操作", []string{"start", "terminate"}, nil, true},
+		{"至少一个操作", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeAccessLinkActions(tt.actions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessLinkAllows(t *testing.T) {
+	link := &models.AccessLink{Actions: "start,reboot"}
+	tests := []struct {
+		action string
+		want   bool
+	}{
+		{"start", true},
+		{"reboot", true},
+		{"stop", false},
+		{"", false},
+		{"start,reboot", false},
+	}
+	for _, tt := range tests {
+		if got := accessLinkAllows(link, tt.action); got != tt.want {
+			t.Errorf("accessLinkAllows(%q) = %v, want %v", tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestResolveAccessLink(t *testing.T) {
+	setupTestDB(t)
+	db := database.GetDB()
+	db.Create(&models.OciUser{ID: "config-1", Username: "config-1"})
+	links := []models.AccessLink{
+		{ID: "valid", TokenHash: hashAccessToken("valid-token"), ConfigID: "config-1", ExpireAt: time.Now().Add(time.Hour)},
+		{ID: "expired", TokenHash: hashAccessToken("expired-token"), ConfigID: "config-1", ExpireAt: time.Now().Add(-time.Minute)},
+		{ID: "orphan", TokenHash: hashAccessToken("orphan-token"), ConfigID: "config-deleted", ExpireAt: time.Now().Add(time.Hour)},
+	}
+	for i := range links {
+		if err := db.Create(&links[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantID  string
+		wantErr bool
+	}{
+		{"有效链接", "valid-token", "valid", false},
+		{"已过期", "expired-token", "", true},
+		{"配置已删除", "orphan-token", "", true},
+		{"未知令牌", "unknown-token", "", true},
+		{"空令牌", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, user, err := resolveAccessLink(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (link.ID != tt.wantID || user.ID != link.ConfigID) {
+				t.Errorf("link = %s, user = %s, want link %s", link.ID, user.ID, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	ActivityTypeAudit    = "audit"    // Telegram Bot 操作审计
+	ActivityTypeTask     = "task"     // 开机任务结果
+	ActivityTypeJob      = "job"      // 后台作业完成
+	ActivityTypeSecurity = "security" // 安全审计新发现
+	ActivityTypeEvent    = "event"    // OCI 事件推送
+
+	DefaultActivityPageSize = 20
+	MaxActivityPageSize     = 100
+	// 动态由多个表合并分页，每页都需要从各表读取前 page*pageSize 条，限制可翻阅的深度
+	MaxActivityDepth = 1000
+)
+
+// activityTaskStatuses 计入动态的任务日志状态，不包括每次容量不足的尝试
+var activityTaskStatuses = []string{"success", "expired", "hook", "hook_error"}
+
+// ActivityItem 动态中的一条记录
+type ActivityItem struct {
+	Type    string    `json:"type"`   // audit / task / job / security / event
+	Status  string    `json:"status"` // 审计结果、任务日志状态、作业状态、风险等级或事件类型
+	Subject string    `json:"subject"`
+	Message string    `json:"message"`
+	RefID   string    `json:"refId"` // 对应记录的 ID，如任务 ID、作业运行 ID
+	Time    time.Time `json:"time"`
+}
+
+// ActivityFeed 分页的动态列表
+type ActivityFeed struct {
+	Items    []ActivityItem `json:"items"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"pageSize"`
+}
+
+// activitySource 单个来源：统计总数并按时间倒序读取前 limit 条
+type activitySource struct {
+	Type  string
+	Count func(db *gorm.DB) (int64, error)
+	Fetch func(db *gorm.DB, limit int) ([]ActivityItem, error)
+}
+
+var activitySources = []activitySource{
+	{
+		Type: ActivityTypeAudit,
+		Count: func(db *gorm.DB) (int64, error) {
+			var count int64
+			err := db.Model(&models.TelegramAuditLog{}).Count(&count).Error
+			return count, err
+		},
+		Fetch: func(db *gorm.DB, limit int) ([]ActivityItem, error) {
+			var logs []models.TelegramAuditLog
+			if err := db.Order("create_time DESC").Limit(limit).Find(&logs).Error; err != nil {
+				return nil, err
+			}
+			items := make([]ActivityItem, len(logs))
+			for i, entry := range logs {
+				subject := entry.TgUsername
+				if subject == "" {
+					subject = fmt.Sprintf("%d", entry.TgUserID)
+				}
+				items[i] = ActivityItem{Type: ActivityTypeAudit, Status: entry.Result, Subject: subject, Message: entry.Action, RefID: entry.ID, Time: entry.CreateTime}
+			}
+			return items, nil
+		},
+	},
+	{
+		Type: ActivityTypeTask,
+		Count: func(db *gorm.DB) (int64, error) {
+			var count int64
+			err := db.Model(&models.TaskLog{}).Where("status IN ?", activityTaskStatuses).Count(&count).Error
+			return count, err
+		},
+		Fetch: func(db *gorm.DB, limit int) ([]ActivityItem, error) {
+			var rows []struct {
+				models.TaskLog
+				Username  string
+				OciRegion string
+			}
+			err := db.Table("task_log").
+				Select("task_log.*, oci_create_task.username, oci_create_task.oci_region").
+				Joins("LEFT JOIN oci_create_task ON oci_create_task.id = task_log.task_id").
+				Where("task_log.status IN ?", activityTaskStatuses).
+				Order("task_log.execute_time DESC").Limit(limit).Scan(&rows).Error
+			if err != nil {
+				return nil, err
+			}
+			items := make([]ActivityItem, len(rows))
+			for i, row := range rows {
+				subject := row.Username
+				if row.OciRegion != "" {
+					subject += " / " + row.OciRegion
+				}
+				items[i] = ActivityItem{Type: ActivityTypeTask, Status: row.Status, Subject: subject, Message: row.Message, RefID: row.TaskID, Time: row.ExecuteTime}
+			}
+			return items, nil
+		},
+	},
+	{
+		Type: ActivityTypeJob,
+		Count: func(db *gorm.DB) (int64, error) {
+			var count int64
+			err := db.Model(&models.JobRun{}).Where("end_time IS NOT NULL").Count(&count).Error
+			return count, err
+		},
+		Fetch: func(db *gorm.DB, limit int) ([]ActivityItem, error) {
+			var runs []models.JobRun
+			if err := db.Where("end_time IS NOT NULL").Order("end_time DESC").Limit(limit).Find(&runs).Error; err != nil {
+				return nil, err
+			}
+			items := make([]ActivityItem, len(runs))
+			for i, run := range runs {
+				items[i] = ActivityItem{Type: ActivityTypeJob, Status: run.Status, Subject: run.JobName, Message: run.Message, RefID: run.ID, Time: *run.EndTime}
+			}
+			return items, nil
+		},
+	},
+	{
+		Type: ActivityTypeSecurity,
+		Count: func(db *gorm.DB) (int64, error) {
+			var count int64
+			err := db.Model(&models.SecurityFinding{}).Count(&count).Error
+			return count, err
+		},
+		Fetch: func(db *gorm.DB, limit int) ([]ActivityItem, error) {
+			var findings []models.SecurityFinding
+			if err := db.Order("first_seen DESC").Limit(limit).Find(&findings).Error; err != nil {
+				return nil, err
+			}
+			items := make([]ActivityItem, len(findings))
+			for i, finding := range findings {
+				items[i] = ActivityItem{
+					Type:    ActivityTypeSecurity,
+					Status:  finding.Severity,
+					Subject: finding.Username + " / " + finding.SecurityListName,
+					Message: finding.Description,
+					RefID:   finding.ID,
+					Time:    finding.FirstSeen,
+				}
+			}
+			return items, nil
+		},
+	},
+	{
+		Type: ActivityTypeEvent,
+		Count: func(db *gorm.DB) (int64, error) {
+			var count int64
+			err := db.Model(&models.OciEvent{}).Count(&count).Error
+			return count, err
+		},
+		Fetch: func(db *gorm.DB, limit int) ([]ActivityItem, error) {
+			var events []models.OciEvent
+			if err := db.Order("receive_time DESC").Limit(limit).Find(&events).Error; err != nil {
+				return nil, err
+			}
+			items := make([]ActivityItem, len(events))
+			for i, event := range events {
+				subject := event.ResourceName
+				if subject == "" {
+					subject = event.ResourceID
+				}
+				items[i] = ActivityItem{Type: ActivityTypeEvent, Status: event.EventType, Subject: subject, Message: event.Source, RefID: event.ID, Time: event.ReceiveTime}
+			}
+			return items, nil
+		},
+	},
+}
+
+// IsActivityType 判断是否为支持的动态类型
+func IsActivityType(activityType string) bool {
+	for _, source := range activitySources {
+		if source.Type == activityType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetActivityFeed 合并审计日志、任务结果、作业运行、安全发现与 OCI 事件，按时间倒序分页
+// types 为空时包含所有类型
+func GetActivityFeed(page, pageSize int, types []string) (*ActivityFeed, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultActivityPageSize
+	}
+	if pageSize > MaxActivityPageSize {
+		pageSize = MaxActivityPageSize
+	}
+	if page*pageSize > MaxActivityDepth {
+		return nil, fmt.Errorf("最多查看最近 %d 条动态", MaxActivityDepth)
+	}
+
+	selected := make(map[string]bool, len(types))
+	for _, t := range types {
+		selected[t] = true
+	}
+
+	db := database.GetDB()
+	feed := &ActivityFeed{Items: []ActivityItem{}, Page: page, PageSize: pageSize}
+	var all []ActivityItem
+	for _, source := range activitySources {
+		if len(selected) > 0 && !selected[source.Type] {
+			continue
+		}
+		count, err := source.Count(db)
+		if err != nil {
+			return nil, err
+		}
+		feed.Total += count
+		items, err := source.Fetch(db, page*pageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Time.After(all[j].Time)
+	})
+	start := (page - 1) * pageSize
+	if start < len(all) {
+		feed.Items = all[start:min(start+pageSize, len(all))]
+	}
+	return feed, nil
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	AsyncJobRunning    = "running"
+	AsyncJobCancelling = "cancelling"
+	AsyncJobCompleted  = "completed"
+	AsyncJobError      = "error"
+	AsyncJobCancelled  = "cancelled"
+	// 服务重启时仍在执行的任务无法继续，标记为中断
+	AsyncJobInterrupted = "interrupted"
+
+	AsyncJobInstanceClone  = "instance_clone"
+	AsyncJobShapeRebuild   = "shape_rebuild"
+	AsyncJobShapeChange    = "shape_change"
+	AsyncJobBackupRestore  = "backup_restore"
+	AsyncJobEnable500Mbps  = "enable_500mbps"
+	AsyncJobDisable500Mbps = "disable_500mbps"
+)
+
+// AsyncJobFunc 任务的执行函数，取消任务时 ctx 结束，步骤进度写入 progress
+type AsyncJobFunc func(ctx context.Context, progress chan<- AutoRescueProgress) (interface{}, error)
+
+// AsyncJobInfo 后台操作任务，params 与 result 为原始 JSON
+type AsyncJobInfo struct {
+	models.AsyncJob
+	Params json.RawMessage      `json:"params,omitempty"`
+	Steps  []AutoRescueProgress `json:"steps"`
+	Result json.RawMessage      `json:"result,omitempty"`
+}
+
+// AsyncJobService 执行并记录克隆、重建等长时间操作，任务与进度保存在数据库中，可查询与取消
+type AsyncJobService struct {
+	nodeID  string
+	cancels map[string]context.CancelFunc // 当前实例正在执行的任务
+	mu      sync.Mutex
+}
+
+func NewAsyncJobService() *AsyncJobService {
+	return &AsyncJobService{
+		nodeID:  defaultTaskNodeID(),
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// SetNodeID 设置当前实例标识，与任务租约使用同一标识
+func (s *AsyncJobService) SetNodeID(nodeID string) {
+	if nodeID != "" {
+		s.nodeID = nodeID
+	}
+}
+
+// Start 将本实例上次退出时仍在执行的任务标记为中断，其它实例的任务由其自身处理
+func (s *AsyncJobService) Start() {
+	database.GetDB().Model(&models.AsyncJob{}).
+		Where("status IN ? AND (node = ? OR node = '' OR node IS NULL)", []string{AsyncJobRunning, AsyncJobCancelling}, s.nodeID).
+		Updates(map[string]interface{}{"status": AsyncJobInterrupted, "error": "服务重启，执行被中断", "finish_time": time.Now()})
+}
+
+// Run 创建任务记录并在后台执行，同一资源同时只能有一个未结束的任务
+func (s *AsyncJobService) Run(kind, configId, resourceId string, params interface{}, run AsyncJobFunc) (*models.AsyncJob, error) {
+	db := database.GetDB()
+	var count int64
+	db.Model(&models.AsyncJob{}).
+		Where("resource_id = ? AND status IN ?", resourceId, []string{AsyncJobRunning, AsyncJobCancelling}).Count(&count)
+	if count > 0 {
+		return nil, fmt.Errorf("该资源已有正在执行的任务")
+	}
+
+	data, _ := json.Marshal(params)
+	job := &models.AsyncJob{
+		ID:         uuid.New().String(),
+		Kind:       kind,
+		ConfigID:   configId,
+		ResourceID: resourceId,
+		Node:       s.nodeID,
+		Params:     string(data),
+		Status:     AsyncJobRunning,
+		StepLog:    "[]",
+	}
+	if err := db.Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.cancels[job.ID] = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer s.finish(job.ID)
+		progressChan := make(chan AutoRescueProgress, 10)
+		done := make(chan struct{})
+		go func() {
+			var steps []AutoRescueProgress
+			for progress := range progressChan {
+				progress.SSHPassword = ""
+				steps = append(steps, progress)
+				data, _ := json.Marshal(steps)
+				db.Model(&models.AsyncJob{}).Where("id = ?", job.ID).Update("step_log", string(data))
+			}
+			close(done)
+		}()
+
+		result, err := run(ctx, progressChan)
+		close(progressChan)
+		<-done
+
+		updates := map[string]interface{}{"status": AsyncJobCompleted, "finish_time": time.Now()}
+		if err != nil {
+			updates["status"] = AsyncJobError
+			updates["error"] = extractOCIErrorMessage(err)
+			if ctx.Err() != nil {
+				updates["status"] = AsyncJobCancelled
+			}
+			log.Printf("[AsyncJob] %s %s for %s ended with %s: %s", kind, job.ID, resourceId, updates["status"], updates["error"])
+		}
+		// 返回值为 nil 指针时编码为 null，不保存
+		if data, _ := json.Marshal(result); string(data) != "null" {
+			updates["result"] = string(data)
+		}
+		db.Model(&models.AsyncJob{}).Where("id = ?", job.ID).Updates(updates)
+	}()
+
+	return job, nil
+}
+
+func (s *AsyncJobService) finish(jobId string) {
+	s.mu.Lock()
+	if cancel, ok := s.cancels[jobId]; ok {
+		cancel()
+		delete(s.cancels, jobId)
+	}
+	s.mu.Unlock()
+}
+
+// Cancel 取消当前实例上正在执行的任务，操作在下一次调用 OCI 接口时中止，已创建的资源不会回滚
+func (s *AsyncJobService) Cancel(jobId string) error {
+	s.mu.Lock()
+	cancel, ok := s.cancels[jobId]
+	s.mu.Unlock()
+	if !ok {
+		var job models.AsyncJob
+		if err := database.GetDB().Where("id = ?", jobId).First(&job).Error; err != nil {
+			return fmt.Errorf("任务不存在")
+		}
+		if job.Status == AsyncJobRunning || job.Status == AsyncJobCancelling {
+			return fmt.Errorf("任务在节点 %s 上执行，无法在当前节点取消", job.Node)
+		}
+		return fmt.Errorf("任务已结束")
+	}
+
+	database.GetDB().Model(&models.AsyncJob{}).Where("id = ? AND status = ?", jobId, AsyncJobRunning).
+		Update("status", AsyncJobCancelling)
+	cancel()
+	return nil
+}
+
+func newAsyncJobInfo(job models.AsyncJob) *AsyncJobInfo {
+	info := &AsyncJobInfo{AsyncJob: job, Steps: []AutoRescueProgress{}}
+	if job.Params != "" {
+		info.Params = json.RawMessage(job.Params)
+	}
+	if job.Result != "" {
+		info.Result = json.RawMessage(job.Result)
+	}
+	if job.StepLog != "" {
+		_ = json.Unmarshal([]byte(job.StepLog), &info.Steps)
+	}
+	return info
+}
+
+// Get 获取任务详情
+func (s *AsyncJobService) Get(jobId string) (*AsyncJobInfo, error) {
+	var job models.AsyncJob
+	if err := database.GetDB().Where("id = ?", jobId).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return newAsyncJobInfo(job), nil
+}
+
+// getKind 获取指定类型的任务，类型不符时视为不存在
+func (s *AsyncJobService) getKind(jobId string, kinds ...string) (*AsyncJobInfo, bool) {
+	job, err := s.Get(jobId)
+	if err != nil {
+		return nil, false
+	}
+	for _, kind := range kinds {
+		if job.Kind == kind {
+			return job, true
+		}
+	}
+	return nil, false
+}
+
+// AsyncJobFilter 任务列表的筛选条件，为空的条件不参与筛选
+type AsyncJobFilter struct {
+	ConfigID   string
+	ResourceID string
+	Kind       string
+	Status     string
+}
+
+// List 分页列出任务，最新的在前
+func (s *AsyncJobService) List(filter AsyncJobFilter, page, pageSize int) ([]*AsyncJobInfo, int64, error) {
+	query := database.GetDB().Model(&models.AsyncJob{})
+	if filter.ConfigID != "" {
+		query = query.Where("config_id = ?", filter.ConfigID)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var records []models.AsyncJob
+	if err := query.Order("create_time DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	jobs := make([]*AsyncJobInfo, 0, len(records))
+	for _, record := range records {
+		jobs = append(jobs, newAsyncJobInfo(record))
+	}
+	return jobs, total, nil
+}
+
+// asyncJobValue 解码任务中的参数或结果，为空或格式不符时返回 nil
+func asyncJobValue[T any](data json.RawMessage) *T {
+	if len(data) == 0 {
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+func TestAsyncJobValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantNil bool
+		wantIP  string
+	}{
+		{"为空", "", true, ""},
+		{"格式错误", "{", true, ""},
+		{"类型不符", `["a"]`, true, ""},
+		{"正常", `{"publicIp":"203.0.113.1"}`, false, "203.0.113.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := asyncJobValue[bandwidth500MbpsResult](json.RawMessage(tt.data))
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("asyncJobValue(%q) = %v, wantNil %v", tt.data, got, tt.wantNil)
+			}
+			if got != nil && got.PublicIP != tt.wantIP {
+				t.Errorf("PublicIP = %q, want %q", got.PublicIP, tt.wantIP)
+			}
+		})
+	}
+}
+
+func TestNewAsyncJobInfo(t *testing.T) {
+	info := newAsyncJobInfo(models.AsyncJob{
+		ID:      "job",
+		Params:  `{"TargetShape":"VM.Standard.A1.Flex"}`,
+		StepLog: `[{"step":1,"totalSteps":7,"status":"completed","message":"关机成功"}]`,
+	})
+	if len(info.Steps) != 1 || info.Steps[0].Message != "关机成功" {
+		t.Errorf("Steps = %+v", info.Steps)
+	}
+	if info.Result != nil {
+		t.Errorf("没有结果时 Result 应为 nil: %s", info.Result)
+	}
+	params := asyncJobValue[ShapeRebuildParams](info.Params)
+	if params == nil || params.TargetShape != "VM.Standard.A1.Flex" {
+		t.Errorf("params = %+v", params)
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	_ = json.Unmarshal(data, &decoded)
+	if _, ok := decoded["params"].(map[string]interface{}); !ok {
+		t.Errorf("params 应编码为 JSON 对象: %s", data)
+	}
+	if _, ok := decoded["stepLog"]; ok {
+		t.Errorf("不应输出 stepLog: %s", data)
+	}
+}
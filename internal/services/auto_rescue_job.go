@@ -0,0 +1,194 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	AutoRescueRunning   = "running"
+	AutoRescueCompleted = "completed"
+	AutoRescueError     = "error"
+	// 数据库中为 running 但当前进程未在执行，说明面板重启导致任务中断
+	AutoRescueInterrupted = "interrupted"
+)
+
+// AutoRescueJob 自动救援任务及其步骤
+type AutoRescueJob struct {
+	models.AutoRescueJob
+	Steps []AutoRescueProgress `json:"steps"`
+
+	// 每次进度或状态变化时关闭并替换，用于唤醒订阅者
+	changed chan struct{}
+}
+
+// AutoRescueEvent 推送给订阅者的救援事件，type 为 progress、completed、error 或 interrupted
+type AutoRescueEvent struct {
+	Type     string              `json:"type"`
+	Progress *AutoRescueProgress `json:"progress,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// notify 唤醒等待中的订阅者，调用方需持有 rescueMu
+func (job *AutoRescueJob) notify() {
+	close(job.changed)
+	job.changed = make(chan struct{})
+}
+
+// record 将步骤写入 StepLog 并记录步骤中创建的资源，SSH 密码不落库
+func (job *AutoRescueJob) record(progress AutoRescueProgress) {
+	job.Steps = append(job.Steps, progress)
+	switch {
+	case progress.Step == 2 && progress.ResourceID != "":
+		job.BackupID = progress.ResourceID
+	case progress.Step == 5 && progress.ResourceID != "":
+		job.BootVolumeID = progress.ResourceID
+	}
+	if progress.PublicIP != "" {
+		job.PublicIP = progress.PublicIP
+	}
+
+	steps := make([]AutoRescueProgress, len(job.Steps))
+	for i, step := range job.Steps {
+		step.SSHPassword = ""
+		steps[i] = step
+	}
+	data, _ := json.Marshal(steps)
+	job.StepLog = string(data)
+}
+
+func newAutoRescueJob(record models.AutoRescueJob) *AutoRescueJob {
+	job := &AutoRescueJob{AutoRescueJob: record, Steps: []AutoRescueProgress{}}
+	if record.StepLog != "" {
+		_ = json.Unmarshal([]byte(record.StepLog), &job.Steps)
+	}
+	return job
+}
+
+// StartAutoRescue 启动自动救援任务，返回任务ID
+func (s *InstanceService) StartAutoRescue(userId string, params AutoRescueParams) (string, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return "", fmt.Errorf("user not found: %w", err)
+	}
+
+	job := &AutoRescueJob{
+		AutoRescueJob: models.AutoRescueJob{
+			ID:           uuid.New().String(),
+			ConfigID:     userId,
+			InstanceID:   params.InstanceID,
+			InstanceName: params.InstanceName,
+			KeepBackup:   params.KeepBackupVolume,
+			Status:       AutoRescueRunning,
+		},
+		Steps:   []AutoRescueProgress{},
+		changed: make(chan struct{}),
+	}
+
+	s.rescueMu.Lock()
+	for _, running := range s.rescueJobs {
+		if running.InstanceID == params.InstanceID {
+			s.rescueMu.Unlock()
+			return "", fmt.Errorf("实例已有正在执行的救援任务")
+		}
+	}
+	if err := database.GetDB().Create(&job.AutoRescueJob).Error; err != nil {
+		s.rescueMu.Unlock()
+		return "", err
+	}
+	s.rescueJobs[job.ID] = job
+	s.rescueMu.Unlock()
+
+	go func() {
+		db := database.GetDB()
+		progressChan := make(chan AutoRescueProgress, 10)
+		done := make(chan struct{})
+		go func() {
+			for progress := range progressChan {
+				s.rescueMu.Lock()
+				job.record(progress)
+				db.Save(&job.AutoRescueJob)
+				job.notify()
+				s.rescueMu.Unlock()
+			}
+			close(done)
+		}()
+
+		err := s.ociService.AutoRescue(&user, params, progressChan)
+		close(progressChan)
+		<-done
+
+		s.rescueMu.Lock()
+		if err != nil {
+			job.Status = AutoRescueError
+			job.Error = extractOCIErrorMessage(err)
+			log.Printf("[AutoRescue] Job %s for instance %s failed: %s", job.ID, job.InstanceID, job.Error)
+		} else {
+			job.Status = AutoRescueCompleted
+		}
+		db.Save(&job.AutoRescueJob)
+		job.notify()
+		delete(s.rescueJobs, job.ID)
+		s.rescueMu.Unlock()
+	}()
+
+	return job.ID, nil
+}
+
+// GetAutoRescueJob 获取自动救援任务状态
+func (s *InstanceService) GetAutoRescueJob(jobId string) (*AutoRescueJob, bool) {
+	job, _, ok := s.WatchAutoRescueJob(jobId)
+	return job, ok
+}
+
+// WatchAutoRescueJob 获取任务快照及下一次变化时关闭的通道，已结束的任务返回的通道为 nil
+func (s *InstanceService) WatchAutoRescueJob(jobId string) (*AutoRescueJob, <-chan struct{}, bool) {
+	s.rescueMu.RLock()
+	if job, ok := s.rescueJobs[jobId]; ok {
+		snapshot := *job
+		snapshot.Steps = append([]AutoRescueProgress(nil), job.Steps...)
+		s.rescueMu.RUnlock()
+		return &snapshot, job.changed, true
+	}
+	s.rescueMu.RUnlock()
+
+	var record models.AutoRescueJob
+	if err := database.GetDB().Where("id = ?", jobId).First(&record).Error; err != nil {
+		return nil, nil, false
+	}
+	job := newAutoRescueJob(record)
+	s.markRescueInterrupted(job)
+	return job, nil, true
+}
+
+// ListAutoRescueJobs 列出配置下的救援任务，最新的在前
+func (s *InstanceService) ListAutoRescueJobs(userId string) ([]*AutoRescueJob, error) {
+	var records []models.AutoRescueJob
+	if err := database.GetDB().Where("config_id = ?", userId).Order("create_time DESC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	jobs := make([]*AutoRescueJob, 0, len(records))
+	for _, record := range records {
+		job := newAutoRescueJob(record)
+		s.markRescueInterrupted(job)
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// markRescueInterrupted 将已不在执行的 running 任务标记为中断，仅影响返回结果
+func (s *InstanceService) markRescueInterrupted(job *AutoRescueJob) {
+	if job.Status != AutoRescueRunning {
+		return
+	}
+	s.rescueMu.RLock()
+	defer s.rescueMu.RUnlock()
+	if _, ok := s.rescueJobs[job.ID]; !ok {
+		job.Status = AutoRescueInterrupted
+	}
+}
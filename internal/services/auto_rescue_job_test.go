@@ -0,0 +1,44 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAutoRescueJobRecord(t *testing.T) {
+	job := &AutoRescueJob{}
+	steps := []AutoRescueProgress{
+		{Step: 1, Status: "completed", Message: "关机成功"},
+		{Step: 2, Status: "completed", Message: "备份原引导卷成功", ResourceID: "ocid1.bootvolumebackup.oc1..a"},
+		{Step: 5, Status: "running", Message: "正在创建47GB引导卷..."},
+		{Step: 5, Status: "completed", Message: "创建47GB引导卷成功", ResourceID: "ocid1.bootvolume.oc1..b"},
+		{Step: 9, Status: "completed", Message: "实例救援成功，已启动", PublicIP: "203.0.113.9", SSHPassword: "secret"},
+	}
+	for _, step := range steps {
+		job.record(step)
+	}
+
+	if job.BackupID != "ocid1.bootvolumebackup.oc1..a" {
+		t.Errorf("BackupID = %q", job.BackupID)
+	}
+	if job.BootVolumeID != "ocid1.bootvolume.oc1..b" {
+		t.Errorf("BootVolumeID = %q", job.BootVolumeID)
+	}
+	if job.PublicIP != "203.0.113.9" {
+		t.Errorf("PublicIP = %q", job.PublicIP)
+	}
+	if strings.Contains(job.StepLog, "secret") {
+		t.Errorf("StepLog 不应包含 SSH 密码: %s", job.StepLog)
+	}
+	if job.Steps[4].SSHPassword != "secret" {
+		t.Errorf("内存中的步骤应保留 SSH 密码")
+	}
+
+	restored := newAutoRescueJob(job.AutoRescueJob)
+	if len(restored.Steps) != len(steps) {
+		t.Fatalf("恢复的步骤数 = %d, want %d", len(restored.Steps), len(steps))
+	}
+	if restored.Steps[1].ResourceID != "ocid1.bootvolumebackup.oc1..a" {
+		t.Errorf("恢复的步骤资源 = %q", restored.Steps[1].ResourceID)
+	}
+}
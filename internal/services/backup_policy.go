@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// BackupPolicyInfo OCI 卷备份策略，Oracle 预定义的 bronze、silver、gold 策略不属于任何区间
+type BackupPolicyInfo struct {
+	ID                string                 `json:"id"`
+	DisplayName       string                 `json:"displayName"`
+	OracleDefined     bool                   `json:"oracleDefined"`
+	CompartmentID     string                 `json:"compartmentId,omitempty"`
+	DestinationRegion string                 `json:"destinationRegion,omitempty"` // 备份复制到的区域
+	Schedules         []BackupPolicySchedule `json:"schedules"`
+}
+
+// BackupPolicySchedule 备份策略中的一条计划
+type BackupPolicySchedule struct {
+	BackupType    string `json:"backupType"` // FULL, INCREMENTAL
+	Period        string `json:"period"`     // ONE_HOUR, ONE_DAY, ONE_WEEK, ONE_MONTH, ONE_YEAR
+	RetentionDays int    `json:"retentionDays"`
+	HourOfDay     *int   `json:"hourOfDay,omitempty"`
+	DayOfWeek     string `json:"dayOfWeek,omitempty"`
+	DayOfMonth    *int   `json:"dayOfMonth,omitempty"`
+}
+
+// BackupPolicyAssignmentInfo 卷当前分配的备份策略
+type BackupPolicyAssignmentInfo struct {
+	ID          string `json:"id"`
+	AssetID     string `json:"assetId"`
+	PolicyID    string `json:"policyId"`
+	PolicyName  string `json:"policyName"`
+	TimeCreated string `json:"timeCreated"`
+}
+
+func newBackupPolicyInfo(p core.VolumeBackupPolicy) BackupPolicyInfo {
+	info := BackupPolicyInfo{
+		ID:                stringValue(p.Id),
+		DisplayName:       stringValue(p.DisplayName),
+		OracleDefined:     p.CompartmentId == nil,
+		CompartmentID:     stringValue(p.CompartmentId),
+		DestinationRegion: stringValue(p.DestinationRegion),
+		Schedules:         make([]BackupPolicySchedule, 0, len(p.Schedules)),
+	}
+	for _, schedule := range p.Schedules {
+		item := BackupPolicySchedule{
+			BackupType: string(schedule.BackupType),
+			Period:     string(schedule.Period),
+			HourOfDay:  schedule.HourOfDay,
+			DayOfWeek:  string(schedule.DayOfWeek),
+			DayOfMonth: schedule.DayOfMonth,
+		}
+		if schedule.RetentionSeconds != nil {
+			item.RetentionDays = *schedule.RetentionSeconds / 86400
+		}
+		info.Schedules = append(info.Schedules, item)
+	}
+	return info
+}
+
+// IsBackupPolicyAsset 检查 OCID 是否为可分配备份策略的引导卷或块存储卷
+func IsBackupPolicyAsset(assetId string) bool {
+	return strings.HasPrefix(assetId, "ocid1.bootvolume.") || strings.HasPrefix(assetId, "ocid1.volume.")
+}
+
+// listBackupPolicies 列出 Oracle 预定义策略与区间内的自定义策略
+func listBackupPolicies(ctx context.Context, client core.BlockstorageClient, compartmentId string) ([]BackupPolicyInfo, error) {
+	policies := []BackupPolicyInfo{}
+	for _, compartment := range []*string{nil, &compartmentId} {
+		req := core.ListVolumeBackupPoliciesRequest{CompartmentId: compartment}
+		for {
+			resp, err := client.ListVolumeBackupPolicies(ctx, req)
+			if err != nil {
+				return nil, fmt.Errorf("获取备份策略失败: %s", extractOCIErrorMessage(err))
+			}
+			for _, p := range resp.Items {
+				policies = append(policies, newBackupPolicyInfo(p))
+			}
+			if resp.OpcNextPage == nil {
+				break
+			}
+			req.Page = resp.OpcNextPage
+		}
+	}
+	return policies, nil
+}
+
+// ListBackupPolicies 列出可分配的备份策略，compartmentId 为空时使用租户根区间
+func (s *BackupService) ListBackupPolicies(userId, compartmentId, region string) ([]BackupPolicyInfo, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	if compartmentId == "" {
+		compartmentId = user.OciTenantID
+	}
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	return listBackupPolicies(ctx, client, compartmentId)
+}
+
+// getBackupPolicyAssignment 获取卷当前的备份策略分配，未分配时返回 nil
+func getBackupPolicyAssignment(ctx context.Context, client core.BlockstorageClient, assetId string) (*core.VolumeBackupPolicyAssignment, error) {
+	resp, err := client.GetVolumeBackupPolicyAssetAssignment(ctx, core.GetVolumeBackupPolicyAssetAssignmentRequest{AssetId: &assetId})
+	if err != nil {
+		return nil, fmt.Errorf("获取备份策略分配失败: %s", extractOCIErrorMessage(err))
+	}
+	if len(resp.Items) == 0 {
+		return nil, nil
+	}
+	return &resp.Items[0], nil
+}
+
+func newBackupPolicyAssignmentInfo(ctx context.Context, client core.BlockstorageClient, assignment *core.VolumeBackupPolicyAssignment) *BackupPolicyAssignmentInfo {
+	info := &BackupPolicyAssignmentInfo{
+		ID:       stringValue(assignment.Id),
+		AssetID:  stringValue(assignment.AssetId),
+		PolicyID: stringValue(assignment.PolicyId),
+	}
+	if assignment.TimeCreated != nil {
+		info.TimeCreated = assignment.TimeCreated.Format("2006-01-02 15:04:05")
+	}
+	if resp, err := client.GetVolumeBackupPolicy(ctx, core.GetVolumeBackupPolicyRequest{PolicyId: assignment.PolicyId}); err == nil {
+		info.PolicyName = stringValue(resp.DisplayName)
+	}
+	return info
+}
+
+// GetBackupPolicyAssignment 获取卷当前分配的备份策略，未分配时返回 nil
+func (s *BackupService) GetBackupPolicyAssignment(userId, assetId, region string) (*BackupPolicyAssignmentInfo, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	assignment, err := getBackupPolicyAssignment(ctx, client, assetId)
+	if err != nil || assignment == nil {
+		return nil, err
+	}
+	return newBackupPolicyAssignmentInfo(ctx, client, assignment), nil
+}
+
+// AssignBackupPolicy 为卷分配备份策略，policy 可以是策略 OCID 或 bronze、silver、gold 等预定义策略名称
+// 每个卷只能分配一个策略，已分配其他策略时先取消原分配
+func (s *BackupService) AssignBackupPolicy(userId, assetId, policy, region string) (*BackupPolicyAssignmentInfo, error) {
+	if !IsBackupPolicyAsset(assetId) {
+		return nil, fmt.Errorf("只能为引导卷或块存储卷分配备份策略")
+	}
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	policyId := policy
+	if !strings.HasPrefix(policy, "ocid1.") {
+		policies, err := listBackupPolicies(ctx, client, user.OciTenantID)
+		if err != nil {
+			return nil, err
+		}
+		policyId = ""
+		for _, p := range policies {
+			if p.OracleDefined && strings.EqualFold(p.DisplayName, policy) {
+				policyId = p.ID
+				break
+			}
+		}
+		if policyId == "" {
+			return nil, fmt.Errorf("备份策略不存在: %s", policy)
+		}
+	}
+
+	existing, err := getBackupPolicyAssignment(ctx, client, assetId)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if stringValue(existing.PolicyId) == policyId {
+			return newBackupPolicyAssignmentInfo(ctx, client, existing), nil
+		}
+		if _, err := client.DeleteVolumeBackupPolicyAssignment(ctx, core.DeleteVolumeBackupPolicyAssignmentRequest{PolicyAssignmentId: existing.Id}); err != nil {
+			return nil, fmt.Errorf("取消原备份策略失败: %s", extractOCIErrorMessage(err))
+		}
+	}
+
+	resp, err := client.CreateVolumeBackupPolicyAssignment(ctx, core.CreateVolumeBackupPolicyAssignmentRequest{
+		CreateVolumeBackupPolicyAssignmentDetails: core.CreateVolumeBackupPolicyAssignmentDetails{
+			AssetId:  &assetId,
+			PolicyId: &policyId,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("分配备份策略失败: %s", extractOCIErrorMessage(err))
+	}
+	return newBackupPolicyAssignmentInfo(ctx, client, &resp.VolumeBackupPolicyAssignment), nil
+}
+
+// RemoveBackupPolicy 取消卷的备份策略，已由策略创建的备份不受影响
+func (s *BackupService) RemoveBackupPolicy(userId, assetId, region string) error {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return err
+	}
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	assignment, err := getBackupPolicyAssignment(ctx, client, assetId)
+	if err != nil {
+		return err
+	}
+	if assignment == nil {
+		return fmt.Errorf("该卷未分配备份策略")
+	}
+	if _, err := client.DeleteVolumeBackupPolicyAssignment(ctx, core.DeleteVolumeBackupPolicyAssignmentRequest{PolicyAssignmentId: assignment.Id}); err != nil {
+		return fmt.Errorf("取消备份策略失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
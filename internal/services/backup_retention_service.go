@@ -0,0 +1,441 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	BackupKindBoot  = "boot"
+	BackupKindBlock = "block"
+
+	// 带有该标签且值为 true 的备份不会被保留策略删除
+	BackupKeepTag = "oci-panel-keep"
+
+	// 保留策略的执行间隔
+	backupRetentionInterval = 24 * time.Hour
+	// 单个配置的执行超时
+	backupRetentionTimeout = 5 * time.Minute
+)
+
+// BackupCleanupItem 保留策略判定需要删除的备份
+type BackupCleanupItem struct {
+	BackupID     string `json:"backupId"`
+	DisplayName  string `json:"displayName"`
+	Kind         string `json:"kind"` // boot / block
+	VolumeID     string `json:"volumeId"`
+	VolumeName   string `json:"volumeName"`
+	InstanceName string `json:"instanceName"` // 引导卷所属实例，块存储卷或实例已删除时为空
+	SizeInGBs    int64  `json:"sizeInGBs"`
+	CreateTime   string `json:"createTime"`
+	Reason       string `json:"reason"`
+}
+
+// BackupCleanupResult 保留策略的预览或执行结果
+type BackupCleanupResult struct {
+	DryRun  bool                `json:"dryRun"`
+	Items   []BackupCleanupItem `json:"items"`
+	Deleted int                 `json:"deleted"`
+	Failed  []string            `json:"failed,omitempty"` // 删除失败的备份及原因
+}
+
+// volumeBackup 引导卷与块存储卷备份的统一表示
+type volumeBackup struct {
+	id          string
+	displayName string
+	kind        string
+	volumeID    string
+	sizeInGBs   int64
+	created     time.Time
+	keep        bool
+}
+
+type BackupRetentionService struct {
+	ociService *OCIService
+	mu         sync.Mutex
+	pending    []models.BackupRetentionPolicy
+}
+
+func NewBackupRetentionService(ociService *OCIService) *BackupRetentionService {
+	return &BackupRetentionService{ociService: ociService}
+}
+
+// Job 返回由作业框架调度的备份保留作业，每小时检查一次，每个策略每天执行一次
+func (s *BackupRetentionService) Job() Job {
+	return &FuncJob{
+		JobName:        "backup_retention",
+		JobDescription: "按保留策略删除超出数量或天数的卷备份",
+		CheckInterval:  time.Hour,
+		Due:            s.collectDue,
+		RunFunc:        s.runDue,
+	}
+}
+
+// collectDue 找出超过执行间隔未执行的策略，暂存后由 runDue 执行
+func (s *BackupRetentionService) collectDue() bool {
+	var policies []models.BackupRetentionPolicy
+	cutoff := time.Now().Add(-backupRetentionInterval)
+	if err := database.GetDB().Where("enabled = ? AND (last_run_time IS NULL OR last_run_time < ?)", true, cutoff).
+		Find(&policies).Error; err != nil {
+		log.Printf("[BackupRetention] Failed to load policies: %v", err)
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = policies
+	return len(policies) > 0
+}
+
+// runDue 依次执行到期的策略
+func (s *BackupRetentionService) runDue(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	due := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	var deleted, failed int
+	for i := range due {
+		result, err := s.apply(ctx, &due[i], false)
+		if err != nil {
+			failed++
+			log.Printf("[BackupRetention] Config %s failed: %v", due[i].ConfigID, err)
+			continue
+		}
+		deleted += result.Deleted
+		if len(result.Failed) > 0 {
+			failed++
+		}
+	}
+
+	summary := fmt.Sprintf("执行 %d 个策略，删除 %d 份备份，失败 %d 个策略", len(due), deleted, failed)
+	if failed > 0 {
+		return "", fmt.Errorf("%s", summary)
+	}
+	return summary, nil
+}
+
+// apply 按策略计算需要删除的备份，dryRun 为 false 时执行删除并记录结果
+func (s *BackupRetentionService) apply(ctx context.Context, policy *models.BackupRetentionPolicy, dryRun bool) (*BackupCleanupResult, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", policy.ConfigID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backupRetentionTimeout)
+	defer cancel()
+
+	client, err := s.ociService.GetBlockstorageClient(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BackupCleanupResult{DryRun: dryRun}
+	items, err := s.planCleanup(ctx, &user, client, policy)
+	if err == nil {
+		result.Items = items
+		if !dryRun {
+			for _, item := range items {
+				if err := deleteVolumeBackup(ctx, client, item); err != nil {
+					result.Failed = append(result.Failed, fmt.Sprintf("%s: %s", item.DisplayName, extractOCIErrorMessage(err)))
+					continue
+				}
+				result.Deleted++
+				log.Printf("[BackupRetention] Deleted %s backup %s (%s)", item.Kind, item.DisplayName, item.Reason)
+			}
+		}
+	}
+	if dryRun {
+		return result, err
+	}
+
+	updates := map[string]interface{}{"last_run_time": time.Now()}
+	switch {
+	case err != nil:
+		updates["last_status"] = BackupStatusFailed
+		updates["last_message"] = extractOCIErrorMessage(err)
+	case len(result.Failed) > 0:
+		updates["last_status"] = BackupStatusFailed
+		updates["last_message"] = fmt.Sprintf("删除 %d 份，失败 %d 份", result.Deleted, len(result.Failed))
+	default:
+		updates["last_status"] = BackupStatusSuccess
+		updates["last_message"] = fmt.Sprintf("删除 %d 份", result.Deleted)
+	}
+	database.GetDB().Model(&models.BackupRetentionPolicy{}).Where("id = ?", policy.ID).Updates(updates)
+	return result, err
+}
+
+// planCleanup 列出配置的卷备份并按策略选出需要删除的备份
+func (s *BackupRetentionService) planCleanup(ctx context.Context, user *models.OciUser, client core.BlockstorageClient, policy *models.BackupRetentionPolicy) ([]BackupCleanupItem, error) {
+	backups, err := listBootVolumeBackups(ctx, client, user.OciTenantID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.IncludeBlockVolumes {
+		blockBackups, err := listBlockVolumeBackups(ctx, client, user.OciTenantID)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, blockBackups...)
+	}
+
+	items := selectExpiredBackups(backups, policy.KeepCount, policy.MaxAgeDays, time.Now())
+	if len(items) == 0 {
+		return items, nil
+	}
+	volumeNames, instanceNames := s.volumeLabels(ctx, user, client, policy.IncludeBlockVolumes)
+	for i := range items {
+		items[i].VolumeName = volumeNames[items[i].VolumeID]
+		items[i].InstanceName = instanceNames[items[i].VolumeID]
+	}
+	return items, nil
+}
+
+// selectExpiredBackups 按卷分组，组内按创建时间从新到旧排列，超出保留数量或天数的备份需要删除
+// 每个卷始终保留最新的一份备份，带 oci-panel-keep 标签的备份不参与计数也不会被删除
+func selectExpiredBackups(backups []volumeBackup, keepCount, maxAgeDays int, now time.Time) []BackupCleanupItem {
+	groups := make(map[string][]volumeBackup)
+	var order []string
+	for _, backup := range backups {
+		if backup.keep || backup.volumeID == "" {
+			continue
+		}
+		if _, ok := groups[backup.volumeID]; !ok {
+			order = append(order, backup.volumeID)
+		}
+		groups[backup.volumeID] = append(groups[backup.volumeID], backup)
+	}
+
+	cutoff := now.AddDate(0, 0, -maxAgeDays)
+	items := []BackupCleanupItem{}
+	for _, volumeID := range order {
+		group := groups[volumeID]
+		sort.Slice(group, func(i, j int) bool { return group[i].created.After(group[j].created) })
+		for i, backup := range group {
+			if i == 0 {
+				continue
+			}
+			var reason string
+			switch {
+			case keepCount > 0 && i >= keepCount:
+				reason = fmt.Sprintf("超出保留数量 %d", keepCount)
+			case maxAgeDays > 0 && backup.created.Before(cutoff):
+				reason = fmt.Sprintf("超过 %d 天", maxAgeDays)
+			default:
+				continue
+			}
+			items = append(items, BackupCleanupItem{
+				BackupID:    backup.id,
+				DisplayName: backup.displayName,
+				Kind:        backup.kind,
+				VolumeID:    volumeID,
+				SizeInGBs:   backup.sizeInGBs,
+				CreateTime:  backup.created.Format("2006-01-02 15:04:05"),
+				Reason:      reason,
+			})
+		}
+	}
+	return items
+}
+
+// volumeLabels 获取卷名称与引导卷所属实例名称，仅用于展示，获取失败时返回空
+func (s *BackupRetentionService) volumeLabels(ctx context.Context, user *models.OciUser, client core.BlockstorageClient, includeBlock bool) (map[string]string, map[string]string) {
+	volumeNames := make(map[string]string)
+	instanceNames := make(map[string]string)
+	if bootVolumes, err := s.ociService.ListBootVolumes(ctx, user, user.OciTenantID); err == nil {
+		for _, bv := range bootVolumes {
+			volumeNames[bv.ID] = bv.DisplayName
+			instanceNames[bv.ID] = bv.InstanceName
+		}
+	}
+	if !includeBlock {
+		return volumeNames, instanceNames
+	}
+	req := core.ListVolumesRequest{CompartmentId: common.String(user.OciTenantID)}
+	for {
+		resp, err := client.ListVolumes(ctx, req)
+		if err != nil {
+			break
+		}
+		for _, v := range resp.Items {
+			volumeNames[stringValue(v.Id)] = stringValue(v.DisplayName)
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return volumeNames, instanceNames
+}
+
+func listBootVolumeBackups(ctx context.Context, client core.BlockstorageClient, compartmentID string) ([]volumeBackup, error) {
+	var backups []volumeBackup
+	req := core.ListBootVolumeBackupsRequest{
+		CompartmentId:  common.String(compartmentID),
+		LifecycleState: core.BootVolumeBackupLifecycleStateAvailable,
+	}
+	for {
+		resp, err := client.ListBootVolumeBackups(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range resp.Items {
+			backup := volumeBackup{
+				id:          stringValue(b.Id),
+				displayName: stringValue(b.DisplayName),
+				kind:        BackupKindBoot,
+				volumeID:    stringValue(b.BootVolumeId),
+				keep:        b.FreeformTags[BackupKeepTag] == "true",
+			}
+			if b.SizeInGBs != nil {
+				backup.sizeInGBs = *b.SizeInGBs
+			}
+			if b.TimeCreated != nil {
+				backup.created = b.TimeCreated.Time
+			}
+			backups = append(backups, backup)
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return backups, nil
+}
+
+func listBlockVolumeBackups(ctx context.Context, client core.BlockstorageClient, compartmentID string) ([]volumeBackup, error) {
+	var backups []volumeBackup
+	req := core.ListVolumeBackupsRequest{
+		CompartmentId:  common.String(compartmentID),
+		LifecycleState: core.VolumeBackupLifecycleStateAvailable,
+	}
+	for {
+		resp, err := client.ListVolumeBackups(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range resp.Items {
+			backup := volumeBackup{
+				id:          stringValue(b.Id),
+				displayName: stringValue(b.DisplayName),
+				kind:        BackupKindBlock,
+				volumeID:    stringValue(b.VolumeId),
+				keep:        b.FreeformTags[BackupKeepTag] == "true",
+			}
+			if b.SizeInGBs != nil {
+				backup.sizeInGBs = *b.SizeInGBs
+			}
+			if b.TimeCreated != nil {
+				backup.created = b.TimeCreated.Time
+			}
+			backups = append(backups, backup)
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return backups, nil
+}
+
+func deleteVolumeBackup(ctx context.Context, client core.BlockstorageClient, item BackupCleanupItem) error {
+	if item.Kind == BackupKindBlock {
+		_, err := client.DeleteVolumeBackup(ctx, core.DeleteVolumeBackupRequest{VolumeBackupId: common.String(item.BackupID)})
+		return err
+	}
+	_, err := client.DeleteBootVolumeBackup(ctx, core.DeleteBootVolumeBackupRequest{BootVolumeBackupId: common.String(item.BackupID)})
+	return err
+}
+
+// GetBackupRetentionPolicy 获取配置的保留策略，未设置时返回 nil
+func GetBackupRetentionPolicy(configID string) (*models.BackupRetentionPolicy, error) {
+	var policy models.BackupRetentionPolicy
+	result := database.GetDB().Where("config_id = ?", configID).Limit(1).Find(&policy)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &policy, nil
+}
+
+// validateBackupRetention 校验保留数量与天数，至少指定其中一项
+func validateBackupRetention(keepCount, maxAgeDays int) error {
+	if keepCount < 0 || maxAgeDays < 0 {
+		return fmt.Errorf("保留数量与天数不能为负数")
+	}
+	if keepCount == 0 && maxAgeDays == 0 {
+		return fmt.Errorf("保留数量与保留天数至少设置一项")
+	}
+	return nil
+}
+
+// SaveBackupRetentionPolicy 创建或更新配置的保留策略
+func SaveBackupRetentionPolicy(configID string, keepCount, maxAgeDays int, includeBlockVolumes, enabled bool) (*models.BackupRetentionPolicy, error) {
+	if err := validateBackupRetention(keepCount, maxAgeDays); err != nil {
+		return nil, err
+	}
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", configID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+
+	policy, err := GetBackupRetentionPolicy(configID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = &models.BackupRetentionPolicy{ID: uuid.New().String(), ConfigID: configID}
+	}
+	policy.KeepCount = keepCount
+	policy.MaxAgeDays = maxAgeDays
+	policy.IncludeBlockVolumes = includeBlockVolumes
+	policy.Enabled = enabled
+	if err := db.Save(policy).Error; err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// DeleteBackupRetentionPolicy 删除配置的保留策略
+func DeleteBackupRetentionPolicy(configID string) error {
+	return database.GetDB().Where("config_id = ?", configID).Delete(&models.BackupRetentionPolicy{}).Error
+}
+
+// PreviewCleanup 按传入的规则预览需要删除的备份，不修改已保存的策略
+func (s *BackupRetentionService) PreviewCleanup(configID string, keepCount, maxAgeDays int, includeBlockVolumes bool) (*BackupCleanupResult, error) {
+	if err := validateBackupRetention(keepCount, maxAgeDays); err != nil {
+		return nil, err
+	}
+	policy := &models.BackupRetentionPolicy{
+		ConfigID:            configID,
+		KeepCount:           keepCount,
+		MaxAgeDays:          maxAgeDays,
+		IncludeBlockVolumes: includeBlockVolumes,
+	}
+	return s.apply(context.Background(), policy, true)
+}
+
+// RunPolicy 立即执行配置已保存的保留策略
+func (s *BackupRetentionService) RunPolicy(configID string) (*BackupCleanupResult, error) {
+	policy, err := GetBackupRetentionPolicy(configID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("配置未设置保留策略")
+	}
+	return s.apply(context.Background(), policy, false)
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	BackupStatusSuccess = "success"
+	BackupStatusFailed  = "failed"
+
+	// 定时备份创建的备份带有该标签，值为计划 ID，清理时只处理带标签的备份
+	BackupScheduleTag = "oci-panel-backup-schedule"
+
+	DefaultBackupRetentionCount = 2
+	MaxBackupRetentionCount     = 10
+
+	// 服务暂停后补执行错过计划的最长时间
+	backupScheduleCatchUp = 10 * time.Minute
+	// 单个计划的执行超时，备份在 OCI 中异步完成，不等待备份可用
+	backupScheduleTimeout = 2 * time.Minute
+)
+
+// BackupScheduleView 带下次执行时间的定时备份计划
+type BackupScheduleView struct {
+	models.BackupSchedule
+	NextRunTime string `json:"nextRunTime"` // 已停用或表达式无效时为空
+}
+
+type BackupScheduleService struct {
+	ociService      *OCIService
+	telegramService *TelegramService
+	runner          *cronRunner[models.BackupSchedule]
+}
+
+func NewBackupScheduleService(ociService *OCIService, telegramService *TelegramService) *BackupScheduleService {
+	s := &BackupScheduleService{
+		ociService:      ociService,
+		telegramService: telegramService,
+	}
+	s.runner = newCronRunner("BackupSchedule", backupScheduleCatchUp,
+		func(schedule *models.BackupSchedule) (string, string) { return schedule.ID, schedule.Cron },
+		s.runDue)
+	return s
+}
+
+// Job 返回由作业框架调度的定时备份作业，每分钟检查一次，只有存在到期计划时才会执行并记录
+func (s *BackupScheduleService) Job() Job {
+	return s.runner.job("boot_volume_backup", "按计划备份实例引导卷并清理旧备份")
+}
+
+// runDue 执行到期的计划，失败时通过 Telegram 通知
+func (s *BackupScheduleService) runDue(ctx context.Context, due []models.BackupSchedule) (string, error) {
+	var succeeded, failed int
+	for i := range due {
+		schedule := &due[i]
+		updates := map[string]interface{}{"last_run_time": time.Now()}
+
+		message, err := s.execute(ctx, schedule)
+		if err != nil {
+			failed++
+			updates["last_status"] = BackupStatusFailed
+			updates["last_message"] = err.Error()
+			s.notifyFailure(schedule, err.Error())
+			log.Printf("[BackupSchedule] %s failed: %v", schedule.InstanceName, err)
+		} else {
+			succeeded++
+			updates["last_status"] = BackupStatusSuccess
+			updates["last_message"] = message
+			log.Printf("[BackupSchedule] %s: %s", schedule.InstanceName, message)
+		}
+		database.GetDB().Model(&models.BackupSchedule{}).Where("id = ?", schedule.ID).Updates(updates)
+	}
+
+	summary := fmt.Sprintf("执行 %d 个计划：成功 %d，失败 %d", len(due), succeeded, failed)
+	if failed > 0 {
+		return "", fmt.Errorf("%s", summary)
+	}
+	return summary, nil
+}
+
+// execute 为计划对应实例的引导卷创建完整备份，并删除超出保留数量的旧备份
+// 实例已终止或不存在时停用计划
+func (s *BackupScheduleService) execute(ctx context.Context, schedule *models.BackupSchedule) (string, error) {
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", schedule.ConfigID).First(&user).Error; err != nil {
+		return "", fmt.Errorf("配置不存在")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backupScheduleTimeout)
+	defer cancel()
+
+	instance, err := s.ociService.GetInstance(ctx, &user, schedule.InstanceID)
+	if err != nil {
+		if serviceErr, ok := common.IsServiceError(err); ok && serviceErr.GetHTTPStatusCode() == 404 {
+			db.Model(schedule).Update("enabled", false)
+			return "", fmt.Errorf("实例不存在，计划已停用")
+		}
+		return "", fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+	if instance.LifecycleState == core.InstanceLifecycleStateTerminated || instance.LifecycleState == core.InstanceLifecycleStateTerminating {
+		db.Model(schedule).Update("enabled", false)
+		return "", fmt.Errorf("实例已终止，计划已停用")
+	}
+
+	bootVolume, err := s.ociService.GetBootVolumeByInstanceId(&user, schedule.InstanceID)
+	if err != nil {
+		return "", fmt.Errorf("获取引导卷失败: %s", extractOCIErrorMessage(err))
+	}
+
+	client, err := s.ociService.GetBlockstorageClient(&user)
+	if err != nil {
+		return "", err
+	}
+	displayName := fmt.Sprintf("%s-auto-%s", stringValue(instance.DisplayName), time.Now().Format("20060102-1504"))
+	backupResp, err := client.CreateBootVolumeBackup(ctx, core.CreateBootVolumeBackupRequest{
+		CreateBootVolumeBackupDetails: core.CreateBootVolumeBackupDetails{
+			BootVolumeId: bootVolume.Id,
+			DisplayName:  common.String(displayName),
+			Type:         core.CreateBootVolumeBackupDetailsTypeFull,
+			FreeformTags: map[string]string{BackupScheduleTag: schedule.ID},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("创建备份失败: %s", extractOCIErrorMessage(err))
+	}
+
+	deleted, err := pruneScheduledBackups(ctx, client, stringValue(bootVolume.CompartmentId), stringValue(bootVolume.Id), schedule.ID, schedule.RetentionCount)
+	if err != nil {
+		return "", fmt.Errorf("备份 %s 已创建，清理旧备份失败: %s", stringValue(backupResp.Id), extractOCIErrorMessage(err))
+	}
+	return fmt.Sprintf("已创建备份 %s，清理旧备份 %d 份", displayName, deleted), nil
+}
+
+// pruneScheduledBackups 删除计划创建的超出保留数量的旧备份，正在创建的备份计入数量但不会被删除
+func pruneScheduledBackups(ctx context.Context, client core.BlockstorageClient, compartmentID, bootVolumeID, scheduleID string, keep int) (int, error) {
+	var backups []core.BootVolumeBackup
+	req := core.ListBootVolumeBackupsRequest{
+		CompartmentId: common.String(compartmentID),
+		BootVolumeId:  common.String(bootVolumeID),
+		SortBy:        core.ListBootVolumeBackupsSortByTimecreated,
+		SortOrder:     core.ListBootVolumeBackupsSortOrderDesc,
+	}
+	for {
+		resp, err := client.ListBootVolumeBackups(ctx, req)
+		if err != nil {
+			return 0, err
+		}
+		for _, backup := range resp.Items {
+			if backup.FreeformTags[BackupScheduleTag] != scheduleID {
+				continue
+			}
+			if backup.LifecycleState == core.BootVolumeBackupLifecycleStateTerminating || backup.LifecycleState == core.BootVolumeBackupLifecycleStateTerminated {
+				continue
+			}
+			backups = append(backups, backup)
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+
+	deleted := 0
+	for i := keep; i < len(backups); i++ {
+		if backups[i].LifecycleState != core.BootVolumeBackupLifecycleStateAvailable {
+			continue
+		}
+		if _, err := client.DeleteBootVolumeBackup(ctx, core.DeleteBootVolumeBackupRequest{BootVolumeBackupId: backups[i].Id}); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (s *BackupScheduleService) notifyFailure(schedule *models.BackupSchedule, message string) {
+	if s.telegramService == nil {
+		return
+	}
+	tg := s.telegramService
+	var user models.OciUser
+	database.GetDB().Where("id = ?", schedule.ConfigID).First(&user)
+	text := tg.t("backup_schedule_failed", user.Username, schedule.InstanceName, message)
+	if err := tg.SendCriticalNotification(tg.t("backup_schedule_failed_title"), text); err != nil {
+		log.Printf("[BackupSchedule] Failed to send notification: %v", err)
+	}
+}
+
+// validateBackupSchedule 校验 cron 表达式与保留数量，返回规范化后的表达式
+func validateBackupSchedule(cron string, retentionCount int) (string, error) {
+	if retentionCount < 1 || retentionCount > MaxBackupRetentionCount {
+		return "", fmt.Errorf("保留数量需在 1-%d 之间", MaxBackupRetentionCount)
+	}
+	return normalizeCron(cron)
+}
+
+// CreateBackupSchedule 为实例创建引导卷定时备份计划，retentionCount 为 0 时使用默认值
+func (s *BackupScheduleService) CreateBackupSchedule(configID, instanceID, cron string, retentionCount int) (*models.BackupSchedule, error) {
+	if retentionCount == 0 {
+		retentionCount = DefaultBackupRetentionCount
+	}
+	cron, err := validateBackupSchedule(cron, retentionCount)
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", configID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+	instance, err := s.ociService.GetInstance(context.Background(), &user, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+
+	schedule := models.BackupSchedule{
+		ID:             uuid.New().String(),
+		ConfigID:       configID,
+		InstanceID:     instanceID,
+		InstanceName:   stringValue(instance.DisplayName),
+		Cron:           cron,
+		RetentionCount: retentionCount,
+		Enabled:        true,
+	}
+	if err := db.Create(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ListBackupSchedules 获取定时备份计划，参数为空时不按该条件过滤
+func ListBackupSchedules(configID, instanceID string) ([]BackupScheduleView, error) {
+	query := database.GetDB().Order("create_time DESC")
+	if configID != "" {
+		query = query.Where("config_id = ?", configID)
+	}
+	if instanceID != "" {
+		query = query.Where("instance_id = ?", instanceID)
+	}
+	var schedules []models.BackupSchedule
+	if err := query.Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := make([]BackupScheduleView, len(schedules))
+	for i, schedule := range schedules {
+		list[i].BackupSchedule = schedule
+		if schedule.Enabled {
+			list[i].NextRunTime = cronNextRunText(schedule.Cron, now)
+		}
+	}
+	return list, nil
+}
+
+// UpdateBackupSchedule 修改计划的表达式、保留数量与启用状态
+func UpdateBackupSchedule(id, cron string, retentionCount int, enabled bool) error {
+	cron, err := validateBackupSchedule(cron, retentionCount)
+	if err != nil {
+		return err
+	}
+	result := database.GetDB().Model(&models.BackupSchedule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"cron":            cron,
+		"retention_count": retentionCount,
+		"enabled":         enabled,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("计划不存在")
+	}
+	return nil
+}
+
+// DeleteBackupSchedule 删除定时备份计划，已创建的备份保留在 OCI 中
+func DeleteBackupSchedule(id string) error {
+	return database.GetDB().Where("id = ?", id).Delete(&models.BackupSchedule{}).Error
+}
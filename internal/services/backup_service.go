@@ -0,0 +1,638 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// 恢复方式
+const (
+	BackupRestoreModeVolume = "volume" // 只恢复出引导卷
+	BackupRestoreModeLaunch = "launch" // 恢复引导卷并创建新实例
+	BackupRestoreModeAttach = "attach" // 恢复引导卷并替换已有实例的引导卷，原引导卷保留
+)
+
+const (
+	bootVolumeBackupWaitTimeout     = 2 * time.Hour
+	bootVolumeAttachmentWaitTimeout = 10 * time.Minute
+)
+
+// BackupService 管理引导卷备份，以及从备份恢复引导卷、创建或替换实例
+type BackupService struct {
+	ociService *OCIService
+	asyncJobs  *AsyncJobService
+}
+
+func NewBackupService(ociService *OCIService, asyncJobs *AsyncJobService) *BackupService {
+	return &BackupService{ociService: ociService, asyncJobs: asyncJobs}
+}
+
+// BootVolumeBackupInfo 引导卷备份信息
+type BootVolumeBackupInfo struct {
+	ID              string `json:"id"`
+	DisplayName     string `json:"displayName"`
+	BootVolumeID    string `json:"bootVolumeId"`
+	State           string `json:"state"`
+	Type            string `json:"type"`       // FULL, INCREMENTAL
+	SourceType      string `json:"sourceType"` // MANUAL, SCHEDULED
+	SizeInGBs       int64  `json:"sizeInGBs"`
+	UniqueSizeInGBs int64  `json:"uniqueSizeInGBs"`
+	TimeCreated     string `json:"timeCreated"`
+	ExpirationTime  string `json:"expirationTime,omitempty"`
+	Keep            bool   `json:"keep"` // 带保留标签，保留策略不会删除
+}
+
+func newBootVolumeBackupInfo(b core.BootVolumeBackup) BootVolumeBackupInfo {
+	info := BootVolumeBackupInfo{
+		ID:              stringValue(b.Id),
+		DisplayName:     stringValue(b.DisplayName),
+		BootVolumeID:    stringValue(b.BootVolumeId),
+		State:           string(b.LifecycleState),
+		Type:            string(b.Type),
+		SourceType:      string(b.SourceType),
+		SizeInGBs:       int64Value(b.SizeInGBs),
+		UniqueSizeInGBs: int64Value(b.UniqueSizeInGBs),
+		Keep:            b.FreeformTags[BackupKeepTag] == "true",
+	}
+	if b.TimeCreated != nil {
+		info.TimeCreated = b.TimeCreated.Format("2006-01-02 15:04:05")
+	}
+	if b.ExpirationTime != nil {
+		info.ExpirationTime = b.ExpirationTime.Format("2006-01-02 15:04:05")
+	}
+	return info
+}
+
+// ListBootVolumeBackups 列出区间内的引导卷备份，bootVolumeId 不为空时只列出该引导卷的备份
+func (s *BackupService) ListBootVolumeBackups(userId, compartmentId, bootVolumeId, region string) ([]BootVolumeBackupInfo, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	if compartmentId == "" {
+		compartmentId = user.OciTenantID
+	}
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	backups := []BootVolumeBackupInfo{}
+	req := core.ListBootVolumeBackupsRequest{
+		CompartmentId: &compartmentId,
+		SortBy:        core.ListBootVolumeBackupsSortByTimecreated,
+		SortOrder:     core.ListBootVolumeBackupsSortOrderDesc,
+	}
+	if bootVolumeId != "" {
+		req.BootVolumeId = &bootVolumeId
+	}
+	for {
+		resp, err := client.ListBootVolumeBackups(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("获取引导卷备份失败: %s", extractOCIErrorMessage(err))
+		}
+		for _, b := range resp.Items {
+			if b.LifecycleState == core.BootVolumeBackupLifecycleStateTerminated {
+				continue
+			}
+			backups = append(backups, newBootVolumeBackupInfo(b))
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return backups, nil
+}
+
+// BootVolumeBackupParams 创建引导卷备份参数，实例与引导卷二选一
+type BootVolumeBackupParams struct {
+	InstanceID   string
+	BootVolumeID string
+	DisplayName  string // 为空时使用引导卷名加时间
+	Incremental  bool
+	Keep         bool // 打上保留标签，避免被备份保留策略删除
+}
+
+// CreateBootVolumeBackup 创建引导卷备份，备份在后台完成，返回时状态为 CREATING
+func (s *BackupService) CreateBootVolumeBackup(userId, region string, params BootVolumeBackupParams) (*BootVolumeBackupInfo, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	if params.InstanceID == "" && params.BootVolumeID == "" {
+		return nil, fmt.Errorf("请指定实例或引导卷")
+	}
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	var bootVolume *core.BootVolume
+	if params.BootVolumeID != "" {
+		resp, err := client.GetBootVolume(ctx, core.GetBootVolumeRequest{BootVolumeId: &params.BootVolumeID})
+		if err != nil {
+			return nil, fmt.Errorf("获取引导卷失败: %s", extractOCIErrorMessage(err))
+		}
+		bootVolume = &resp.BootVolume
+	} else {
+		bootVolume, err = s.ociService.GetBootVolumeByInstanceId(user, params.InstanceID)
+		if err != nil {
+			return nil, fmt.Errorf("获取引导卷失败: %s", extractOCIErrorMessage(err))
+		}
+	}
+
+	displayName := params.DisplayName
+	if displayName == "" {
+		displayName = fmt.Sprintf("%s-%s", stringValue(bootVolume.DisplayName), time.Now().Format("20060102-150405"))
+	}
+	details := core.CreateBootVolumeBackupDetails{
+		BootVolumeId: bootVolume.Id,
+		DisplayName:  &displayName,
+		Type:         core.CreateBootVolumeBackupDetailsTypeFull,
+	}
+	if params.Incremental {
+		details.Type = core.CreateBootVolumeBackupDetailsTypeIncremental
+	}
+	if params.Keep {
+		details.FreeformTags = map[string]string{BackupKeepTag: "true"}
+	}
+	resp, err := client.CreateBootVolumeBackup(ctx, core.CreateBootVolumeBackupRequest{CreateBootVolumeBackupDetails: details})
+	if err != nil {
+		return nil, fmt.Errorf("创建引导卷备份失败: %s", extractOCIErrorMessage(err))
+	}
+	info := newBootVolumeBackupInfo(resp.BootVolumeBackup)
+	return &info, nil
+}
+
+// DeleteBootVolumeBackup 删除引导卷备份
+func (s *BackupService) DeleteBootVolumeBackup(userId, backupId, region string) error {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return err
+	}
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.DeleteBootVolumeBackup(ctx, core.DeleteBootVolumeBackupRequest{BootVolumeBackupId: &backupId}); err != nil {
+		return fmt.Errorf("删除引导卷备份失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// BackupRestoreParams 从备份恢复的参数
+type BackupRestoreParams struct {
+	BackupID           string
+	Mode               string // volume, launch, attach
+	AvailabilityDomain string // 恢复到的可用域，可写 AD-1 等后缀；attach 时使用实例所在可用域
+	DisplayName        string // 恢复出的引导卷名称，launch 时同时作为实例名称
+	SizeInGBs          int64  // 为空时与备份相同，不能小于备份大小
+	VpusPerGB          int64
+	InstanceID         string // attach 时要替换引导卷的实例
+	Shape              string // launch 时的实例规格
+	Ocpus              float32
+	MemoryInGBs        float32
+	SubnetID           string // launch 时的子网
+	AssignPublicIP     bool
+}
+
+// BackupRestoreResult 恢复结果
+type BackupRestoreResult struct {
+	BootVolumeID    string `json:"bootVolumeId"`
+	InstanceID      string `json:"instanceId,omitempty"`
+	OldBootVolumeID string `json:"oldBootVolumeId,omitempty"` // attach 时被替换下来的引导卷，需要时手动删除
+	PublicIP        string `json:"publicIp,omitempty"`
+}
+
+// BackupRestoreJob 恢复任务
+type BackupRestoreJob struct {
+	ID         string               `json:"id"`
+	BackupID   string               `json:"backupId"`
+	Mode       string               `json:"mode"`
+	Status     string               `json:"status"` // 见 AsyncJob 的状态
+	Steps      []AutoRescueProgress `json:"steps"`
+	Result     *BackupRestoreResult `json:"result,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	CreateTime string               `json:"createTime"`
+}
+
+// validateRestoreParams 检查恢复方式所需的参数
+func validateRestoreParams(params BackupRestoreParams) error {
+	switch params.Mode {
+	case BackupRestoreModeVolume:
+		if params.AvailabilityDomain == "" {
+			return fmt.Errorf("请指定可用域")
+		}
+	case BackupRestoreModeLaunch:
+		if params.AvailabilityDomain == "" || params.Shape == "" || params.SubnetID == "" {
+			return fmt.Errorf("创建实例需要指定可用域、Shape 与子网")
+		}
+		if IsFlexShape(params.Shape) && (params.Ocpus <= 0 || params.MemoryInGBs <= 0) {
+			return fmt.Errorf("Flex Shape 需要指定 OCPU 与内存")
+		}
+	case BackupRestoreModeAttach:
+		if params.InstanceID == "" {
+			return fmt.Errorf("请指定要替换引导卷的实例")
+		}
+	default:
+		return fmt.Errorf("不支持的恢复方式: %s", params.Mode)
+	}
+	return nil
+}
+
+// StartRestore 校验参数与备份后启动恢复任务，返回任务ID
+func (s *BackupService) StartRestore(userId, region string, params BackupRestoreParams) (string, error) {
+	if err := validateRestoreParams(params); err != nil {
+		return "", err
+	}
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return "", err
+	}
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	backup, err := client.GetBootVolumeBackup(ctx, core.GetBootVolumeBackupRequest{BootVolumeBackupId: &params.BackupID})
+	if err != nil {
+		return "", fmt.Errorf("获取引导卷备份失败: %s", extractOCIErrorMessage(err))
+	}
+	switch backup.LifecycleState {
+	case core.BootVolumeBackupLifecycleStateAvailable, core.BootVolumeBackupLifecycleStateCreating:
+	default:
+		return "", fmt.Errorf("备份状态为 %s，无法恢复", backup.LifecycleState)
+	}
+	if params.SizeInGBs > 0 && params.SizeInGBs < int64Value(backup.SizeInGBs) {
+		return "", fmt.Errorf("引导卷大小不能小于备份大小 %dGB", int64Value(backup.SizeInGBs))
+	}
+
+	job, err := s.asyncJobs.Run(AsyncJobBackupRestore, userId, params.BackupID, params,
+		func(ctx context.Context, progress chan<- AutoRescueProgress) (interface{}, error) {
+			result, err := s.restore(ctx, user, &backup.BootVolumeBackup, params, progress)
+			if result != nil && result.InstanceID != "" {
+				InvalidateInstanceSnapshots(user.ID)
+			}
+			return result, err
+		})
+	if err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// GetRestoreJob 获取恢复任务状态
+func (s *BackupService) GetRestoreJob(jobId string) (*BackupRestoreJob, bool) {
+	job, ok := s.asyncJobs.getKind(jobId, AsyncJobBackupRestore)
+	if !ok {
+		return nil, false
+	}
+	restore := &BackupRestoreJob{
+		ID:         job.ID,
+		BackupID:   job.ResourceID,
+		Status:     job.Status,
+		Steps:      job.Steps,
+		Result:     asyncJobValue[BackupRestoreResult](job.Result),
+		Error:      job.Error,
+		CreateTime: job.CreateTime.Format("2006-01-02 15:04:05"),
+	}
+	if params := asyncJobValue[BackupRestoreParams](job.Params); params != nil {
+		restore.Mode = params.Mode
+	}
+	return restore, true
+}
+
+// restore 从备份恢复引导卷，再按恢复方式创建实例或替换实例的引导卷
+// 出错时返回已完成部分的结果，便于找到恢复出的引导卷
+func (s *BackupService) restore(ctx context.Context, user *models.OciUser, backup *core.BootVolumeBackup, params BackupRestoreParams, progressChan chan<- AutoRescueProgress) (*BackupRestoreResult, error) {
+	totalSteps := map[string]int{
+		BackupRestoreModeVolume: 2,
+		BackupRestoreModeLaunch: 4,
+		BackupRestoreModeAttach: 6,
+	}[params.Mode]
+
+	sendProgress := func(step int, status, message string) {
+		if progressChan != nil {
+			progressChan <- AutoRescueProgress{
+				Step:       step,
+				TotalSteps: totalSteps,
+				Status:     status,
+				Message:    message,
+			}
+		}
+	}
+
+	blockClient, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blockstorage client: %w", err)
+	}
+	computeClient, err := s.ociService.GetComputeClient(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute client: %w", err)
+	}
+
+	// Step 1: 确定目标可用域与区间，等待备份完成
+	sendProgress(1, "running", "正在检查备份...")
+	compartmentID := stringValue(backup.CompartmentId)
+	var instance *core.Instance
+	targetAD := params.AvailabilityDomain
+	if params.Mode == BackupRestoreModeAttach {
+		instance, err = s.ociService.GetInstanceById(user, params.InstanceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get instance: %w", err)
+		}
+		targetAD = stringValue(instance.AvailabilityDomain)
+		compartmentID = stringValue(instance.CompartmentId)
+	} else {
+		targetAD, err = s.ociService.resolveAvailabilityDomain(ctx, user, targetAD)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := waitBootVolumeBackupAvailable(ctx, blockClient, stringValue(backup.Id)); err != nil {
+		return nil, err
+	}
+	sendProgress(1, "completed", "备份可用")
+
+	// Step 2: 从备份恢复引导卷
+	sendProgress(2, "running", fmt.Sprintf("正在 %s 恢复引导卷...", targetAD))
+	volumeName := params.DisplayName
+	if volumeName == "" {
+		volumeName = stringValue(backup.DisplayName) + "-restored"
+	}
+	details := core.CreateBootVolumeDetails{
+		AvailabilityDomain: &targetAD,
+		CompartmentId:      &compartmentID,
+		DisplayName:        &volumeName,
+		SourceDetails:      core.BootVolumeSourceFromBootVolumeBackupDetails{Id: backup.Id},
+	}
+	if params.SizeInGBs > 0 {
+		details.SizeInGBs = &params.SizeInGBs
+	}
+	if params.VpusPerGB > 0 {
+		details.VpusPerGB = &params.VpusPerGB
+	}
+	volumeID, err := s.ociService.restoreBootVolume(ctx, user, details)
+	if volumeID == "" {
+		return nil, err
+	}
+	result := &BackupRestoreResult{BootVolumeID: volumeID}
+	if err != nil {
+		return result, err
+	}
+	sendProgress(2, "completed", "恢复引导卷成功")
+
+	switch params.Mode {
+	case BackupRestoreModeLaunch:
+		err = s.restoreLaunch(ctx, user, computeClient, params, targetAD, compartmentID, result, sendProgress)
+	case BackupRestoreModeAttach:
+		err = s.restoreAttach(ctx, user, computeClient, instance, result, sendProgress)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if progressChan != nil {
+		progressChan <- AutoRescueProgress{
+			Step:       totalSteps,
+			TotalSteps: totalSteps,
+			Status:     "completed",
+			Message:    "从备份恢复成功",
+			PublicIP:   result.PublicIP,
+		}
+	}
+	return result, nil
+}
+
+// restoreLaunch 使用恢复的引导卷创建新实例
+func (s *BackupService) restoreLaunch(ctx context.Context, user *models.OciUser, computeClient core.ComputeClient, params BackupRestoreParams, targetAD, compartmentID string, result *BackupRestoreResult, sendProgress func(int, string, string)) error {
+	// Step 3: 创建实例
+	sendProgress(3, "running", "正在创建新实例...")
+	displayName := params.DisplayName
+	if displayName == "" {
+		displayName = "restored-" + time.Now().Format("20060102-150405")
+	}
+	launchDetails := core.LaunchInstanceDetails{
+		CompartmentId:      &compartmentID,
+		AvailabilityDomain: &targetAD,
+		DisplayName:        &displayName,
+		Shape:              &params.Shape,
+		SourceDetails:      core.InstanceSourceViaBootVolumeDetails{BootVolumeId: &result.BootVolumeID},
+		CreateVnicDetails: &core.CreateVnicDetails{
+			SubnetId:       &params.SubnetID,
+			AssignPublicIp: common.Bool(params.AssignPublicIP),
+		},
+	}
+	if IsFlexShape(params.Shape) {
+		launchDetails.ShapeConfig = &core.LaunchInstanceShapeConfigDetails{
+			Ocpus:       &params.Ocpus,
+			MemoryInGBs: &params.MemoryInGBs,
+		}
+	}
+	launchResp, err := computeClient.LaunchInstance(ctx, core.LaunchInstanceRequest{LaunchInstanceDetails: launchDetails})
+	if err != nil {
+		return fmt.Errorf("failed to launch instance: %w", err)
+	}
+	result.InstanceID = stringValue(launchResp.Id)
+	sendProgress(3, "completed", "新实例已提交创建")
+
+	// Step 4: 等待实例运行并获取公网IP
+	sendProgress(4, "running", "正在等待新实例启动...")
+	for i := 0; i < 60; i++ {
+		instResp, err := computeClient.GetInstance(ctx, core.GetInstanceRequest{InstanceId: launchResp.Id})
+		if err == nil && instResp.LifecycleState == core.InstanceLifecycleStateRunning {
+			break
+		}
+		time.Sleep(5 * time.Second)
+	}
+	result.PublicIP = s.ociService.instancePublicIP(ctx, user, computeClient, compartmentID, result.InstanceID)
+	sendProgress(4, "completed", "新实例已启动")
+	return nil
+}
+
+// restoreAttach 停止实例，将原引导卷替换为恢复的引导卷后重新启动，原引导卷保留
+func (s *BackupService) restoreAttach(ctx context.Context, user *models.OciUser, computeClient core.ComputeClient, instance *core.Instance, result *BackupRestoreResult, sendProgress func(int, string, string)) error {
+	result.InstanceID = stringValue(instance.Id)
+
+	// Step 3: 停止实例
+	sendProgress(3, "running", "正在关机...")
+	if instance.LifecycleState != core.InstanceLifecycleStateStopped {
+		if _, err := computeClient.InstanceAction(ctx, core.InstanceActionRequest{
+			InstanceId: instance.Id,
+			Action:     core.InstanceActionActionStop,
+		}); err != nil {
+			return fmt.Errorf("failed to stop instance: %w", err)
+		}
+		if err := s.ociService.waitInstanceState(ctx, computeClient, *instance.Id, core.InstanceLifecycleStateStopped); err != nil {
+			return err
+		}
+	}
+	sendProgress(3, "completed", "关机成功")
+
+	// Step 4: 分离原引导卷
+	sendProgress(4, "running", "正在分离原引导卷...")
+	attachments, err := computeClient.ListBootVolumeAttachments(ctx, core.ListBootVolumeAttachmentsRequest{
+		CompartmentId:      instance.CompartmentId,
+		AvailabilityDomain: instance.AvailabilityDomain,
+		InstanceId:         instance.Id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list boot volume attachments: %w", err)
+	}
+	for _, attachment := range attachments.Items {
+		if attachment.LifecycleState != core.BootVolumeAttachmentLifecycleStateAttached {
+			continue
+		}
+		result.OldBootVolumeID = stringValue(attachment.BootVolumeId)
+		if _, err := computeClient.DetachBootVolume(ctx, core.DetachBootVolumeRequest{BootVolumeAttachmentId: attachment.Id}); err != nil {
+			return fmt.Errorf("failed to detach boot volume: %w", err)
+		}
+		if err := waitBootVolumeAttachmentState(ctx, computeClient, *attachment.Id, core.BootVolumeAttachmentLifecycleStateDetached); err != nil {
+			return err
+		}
+	}
+	sendProgress(4, "completed", "分离原引导卷成功")
+
+	// Step 5: 附加恢复的引导卷
+	sendProgress(5, "running", "正在附加恢复的引导卷...")
+	attachResp, err := computeClient.AttachBootVolume(ctx, core.AttachBootVolumeRequest{
+		AttachBootVolumeDetails: core.AttachBootVolumeDetails{
+			BootVolumeId: &result.BootVolumeID,
+			InstanceId:   instance.Id,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to attach boot volume: %w", err)
+	}
+	if err := waitBootVolumeAttachmentState(ctx, computeClient, *attachResp.Id, core.BootVolumeAttachmentLifecycleStateAttached); err != nil {
+		return err
+	}
+	sendProgress(5, "completed", "附加引导卷成功")
+
+	// Step 6: 启动实例并获取公网IP
+	sendProgress(6, "running", "正在启动实例...")
+	if _, err := computeClient.InstanceAction(ctx, core.InstanceActionRequest{
+		InstanceId: instance.Id,
+		Action:     core.InstanceActionActionStart,
+	}); err != nil {
+		return fmt.Errorf("failed to start instance: %w", err)
+	}
+	if err := s.ociService.waitInstanceState(ctx, computeClient, *instance.Id, core.InstanceLifecycleStateRunning); err != nil {
+		return err
+	}
+	result.PublicIP = s.ociService.instancePublicIP(ctx, user, computeClient, stringValue(instance.CompartmentId), result.InstanceID)
+	sendProgress(6, "completed", "实例已启动")
+	return nil
+}
+
+// waitBootVolumeBackupAvailable 等待引导卷备份完成，备份失败或被删除时返回错误
+func waitBootVolumeBackupAvailable(ctx context.Context, client core.BlockstorageClient, backupID string) error {
+	deadline := time.Now().Add(bootVolumeBackupWaitTimeout)
+	for time.Now().Before(deadline) {
+		resp, err := client.GetBootVolumeBackup(ctx, core.GetBootVolumeBackupRequest{BootVolumeBackupId: &backupID})
+		if err != nil {
+			return fmt.Errorf("failed to get backup status: %w", err)
+		}
+		switch resp.LifecycleState {
+		case core.BootVolumeBackupLifecycleStateAvailable:
+			return nil
+		case core.BootVolumeBackupLifecycleStateFaulty, core.BootVolumeBackupLifecycleStateTerminating, core.BootVolumeBackupLifecycleStateTerminated:
+			return fmt.Errorf("引导卷备份失败 (%s)", resp.LifecycleState)
+		}
+		time.Sleep(3 * time.Second)
+	}
+	return fmt.Errorf("等待引导卷备份完成超时")
+}
+
+// restoreBootVolume 从备份创建引导卷并等待可用，创建成功但等待失败时同时返回引导卷 ID
+func (s *OCIService) restoreBootVolume(ctx context.Context, user *models.OciUser, details core.CreateBootVolumeDetails) (string, error) {
+	client, err := s.GetBlockstorageClient(user)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.CreateBootVolume(ctx, core.CreateBootVolumeRequest{CreateBootVolumeDetails: details})
+	if err != nil {
+		return "", fmt.Errorf("failed to create boot volume: %w", err)
+	}
+	volumeID := stringValue(resp.Id)
+	if err := s.WaitBootVolumeAvailable(ctx, user, volumeID); err != nil {
+		return volumeID, err
+	}
+	return volumeID, nil
+}
+
+// waitBootVolumeAttachmentState 等待引导卷附件进入指定状态
+func waitBootVolumeAttachmentState(ctx context.Context, client core.ComputeClient, attachmentID string, state core.BootVolumeAttachmentLifecycleStateEnum) error {
+	deadline := time.Now().Add(bootVolumeAttachmentWaitTimeout)
+	for time.Now().Before(deadline) {
+		resp, err := client.GetBootVolumeAttachment(ctx, core.GetBootVolumeAttachmentRequest{BootVolumeAttachmentId: &attachmentID})
+		if err != nil {
+			return fmt.Errorf("failed to get boot volume attachment: %w", err)
+		}
+		if resp.LifecycleState == state {
+			return nil
+		}
+		time.Sleep(3 * time.Second)
+	}
+	return fmt.Errorf("等待引导卷附件变为 %s 超时", state)
+}
+
+// waitInstanceState 等待实例进入指定状态
+func (s *OCIService) waitInstanceState(ctx context.Context, client core.ComputeClient, instanceID string, state core.InstanceLifecycleStateEnum) error {
+	for i := 0; i < 100; i++ {
+		resp, err := client.GetInstance(ctx, core.GetInstanceRequest{InstanceId: &instanceID})
+		if err != nil {
+			return fmt.Errorf("failed to get instance status: %w", err)
+		}
+		if resp.LifecycleState == state {
+			return nil
+		}
+		time.Sleep(3 * time.Second)
+	}
+	return fmt.Errorf("等待实例变为 %s 超时", state)
+}
+
+// instancePublicIP 获取实例主网卡的公网IP，获取失败时返回空
+func (s *OCIService) instancePublicIP(ctx context.Context, user *models.OciUser, computeClient core.ComputeClient, compartmentID, instanceID string) string {
+	vnClient, err := s.GetVirtualNetworkClient(user)
+	if err != nil {
+		return ""
+	}
+	attachments, err := computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+		CompartmentId: &compartmentID,
+		InstanceId:    &instanceID,
+	})
+	if err != nil {
+		return ""
+	}
+	for _, attachment := range attachments.Items {
+		if attachment.VnicId == nil {
+			continue
+		}
+		vnicResp, err := vnClient.GetVnic(ctx, core.GetVnicRequest{VnicId: attachment.VnicId})
+		if err != nil {
+			log.Printf("[Backup] Failed to get vnic %s: %v", *attachment.VnicId, err)
+			continue
+		}
+		if vnicResp.IsPrimary != nil && *vnicResp.IsPrimary {
+			return stringValue(vnicResp.PublicIp)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package services
+
+import "testing"
+
+func TestValidateRestoreParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  BackupRestoreParams
+		wantErr bool
+	}{
+		{"只恢复引导卷", BackupRestoreParams{Mode: BackupRestoreModeVolume, AvailabilityDomain: "AD-1"}, false},
+		{"只恢复引导卷缺少可用域", BackupRestoreParams{Mode: BackupRestoreModeVolume}, true},
+		{"创建实例", BackupRestoreParams{Mode: BackupRestoreModeLaunch, AvailabilityDomain: "AD-1", Shape: "VM.Standard.E2.1.Micro", SubnetID: "ocid1.subnet.oc1..a"}, false},
+		{"创建实例缺少子网", BackupRestoreParams{Mode: BackupRestoreModeLaunch, AvailabilityDomain: "AD-1", Shape: "VM.Standard.E2.1.Micro"}, true},
+		{"Flex 缺少 OCPU", BackupRestoreParams{Mode: BackupRestoreModeLaunch, AvailabilityDomain: "AD-1", Shape: "VM.Standard.A1.Flex", SubnetID: "ocid1.subnet.oc1..a", MemoryInGBs: 6}, true},
+		{"Flex 完整配置", BackupRestoreParams{Mode: BackupRestoreModeLaunch, AvailabilityDomain: "AD-1", Shape: "VM.Standard.A1.Flex", SubnetID: "ocid1.subnet.oc1..a", Ocpus: 1, MemoryInGBs: 6}, false},
+		{"替换引导卷", BackupRestoreParams{Mode: BackupRestoreModeAttach, InstanceID: "ocid1.instance.oc1..a"}, false},
+		{"替换引导卷缺少实例", BackupRestoreParams{Mode: BackupRestoreModeAttach}, true},
+		{"未知方式", BackupRestoreParams{Mode: "clone"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRestoreParams(tt.params); (err != nil) != tt.wantErr {
+				t.Errorf("validateRestoreParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsBackupPolicyAsset(t *testing.T) {
+	tests := []struct {
+		name    string
+		assetId string
+		want    bool
+	}{
+		{"引导卷", "ocid1.bootvolume.oc1.ap-tokyo-1.abc", true},
+		{"块存储卷", "ocid1.volume.oc1.ap-tokyo-1.abc", true},
+		{"卷组", "ocid1.volumegroup.oc1.ap-tokyo-1.abc", false},
+		{"实例", "ocid1.instance.oc1.ap-tokyo-1.abc", false},
+		{"空值", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBackupPolicyAsset(tt.assetId); got != tt.want {
+				t.Errorf("IsBackupPolicyAsset(%q) = %v, want %v", tt.assetId, got, tt.want)
+			}
+		})
+	}
+}
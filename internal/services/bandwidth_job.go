@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	Bandwidth500MbpsEnable  = "enable"
+	Bandwidth500MbpsDisable = "disable"
+)
+
+// Bandwidth500MbpsJob 开启或关闭500Mbps的任务，开启成功时 publicIp 为负载均衡器的公网IP
+type Bandwidth500MbpsJob struct {
+	ID         string `json:"id"`
+	InstanceID string `json:"instanceId"`
+	Action     string `json:"action"` // enable, disable
+	Status     string `json:"status"` // 见 AsyncJob 的状态
+	PublicIP   string `json:"publicIp,omitempty"`
+	Error      string `json:"error,omitempty"`
+	CreateTime string `json:"createTime"`
+	FinishTime string `json:"finishTime,omitempty"`
+}
+
+type bandwidth500MbpsResult struct {
+	PublicIP string `json:"publicIp"`
+}
+
+type enable500MbpsParams struct {
+	SSHPort int              `json:"sshPort"`
+	Ports   []NLBPortForward `json:"ports"`
+}
+
+// StartEnable500Mbps 启动开启500Mbps任务，返回任务ID
+func (s *InstanceService) StartEnable500Mbps(userId, instanceId string, sshPort int, ports []NLBPortForward) (string, error) {
+	params := enable500MbpsParams{SSHPort: sshPort, Ports: ports}
+	return s.start500MbpsJob(userId, instanceId, Bandwidth500MbpsEnable, params, func(ctx context.Context, user *models.OciUser) (string, error) {
+		return s.ociService.Enable500Mbps(ctx, user, instanceId, sshPort, ports)
+	})
+}
+
+// StartDisable500Mbps 启动关闭500Mbps任务，NAT网关与网络负载均衡器一并删除
+func (s *InstanceService) StartDisable500Mbps(userId, instanceId string) (string, error) {
+	return s.start500MbpsJob(userId, instanceId, Bandwidth500MbpsDisable, nil, func(ctx context.Context, user *models.OciUser) (string, error) {
+		return "", s.ociService.Disable500Mbps(ctx, user, instanceId, false, false)
+	})
+}
+
+func (s *InstanceService) start500MbpsJob(userId, instanceId, action string, params interface{}, run func(ctx context.Context, user *models.OciUser) (string, error)) (string, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return "", fmt.Errorf("user not found: %w", err)
+	}
+
+	kind := AsyncJobEnable500Mbps
+	if action == Bandwidth500MbpsDisable {
+		kind = AsyncJobDisable500Mbps
+	}
+	job, err := s.asyncJobs.Run(kind, userId, instanceId, params,
+		func(ctx context.Context, _ chan<- AutoRescueProgress) (interface{}, error) {
+			publicIP, err := run(ctx, &user)
+			s.notify500Mbps(&user, instanceId, action, publicIP, err)
+			if err != nil || publicIP == "" {
+				return nil, err
+			}
+			return bandwidth500MbpsResult{PublicIP: publicIP}, nil
+		})
+	if err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// Get500MbpsJob 获取500Mbps任务状态
+func (s *InstanceService) Get500MbpsJob(jobId string) (*Bandwidth500MbpsJob, bool) {
+	job, ok := s.asyncJobs.getKind(jobId, AsyncJobEnable500Mbps, AsyncJobDisable500Mbps)
+	if !ok {
+		return nil, false
+	}
+	bandwidth := &Bandwidth500MbpsJob{
+		ID:         job.ID,
+		InstanceID: job.ResourceID,
+		Action:     Bandwidth500MbpsEnable,
+		Status:     job.Status,
+		Error:      job.Error,
+		CreateTime: job.CreateTime.Format("2006-01-02 15:04:05"),
+	}
+	if job.Kind == AsyncJobDisable500Mbps {
+		bandwidth.Action = Bandwidth500MbpsDisable
+	}
+	if result := asyncJobValue[bandwidth500MbpsResult](job.Result); result != nil {
+		bandwidth.PublicIP = result.PublicIP
+	}
+	if job.FinishTime != nil {
+		bandwidth.FinishTime = job.FinishTime.Format("2006-01-02 15:04:05")
+	}
+	return bandwidth, true
+}
+
+func (s *InstanceService) notify500Mbps(user *models.OciUser, instanceId, action, publicIP string, err error) {
+	if s.telegramService == nil {
+		return
+	}
+	tg := s.telegramService
+	var text string
+	switch {
+	case err != nil:
+		text = tg.t("bandwidth_500mbps_failed", user.Username, instanceId, tg.t("bandwidth_500mbps_"+action), extractOCIErrorMessage(err))
+	case action == Bandwidth500MbpsEnable:
+		text = tg.t("bandwidth_500mbps_enabled", user.Username, instanceId, publicIP)
+	default:
+		text = tg.t("bandwidth_500mbps_disabled", user.Username, instanceId)
+	}
+	if err := tg.SendNotification(tg.t("bandwidth_500mbps_title"), text); err != nil {
+		log.Printf("[500Mbps] Failed to send notification: %v", err)
+	}
+}
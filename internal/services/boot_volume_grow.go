@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/computeinstanceagent"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	// 运行命令在实例内的最长执行时间
+	growFilesystemTimeoutSeconds = 300
+	// 等待引导卷扩容完成的最长时间
+	bootVolumeResizeWaitTimeout = 5 * time.Minute
+)
+
+// growFilesystemScript 重新扫描引导卷并扩展根分区与文件系统
+// 优先使用 Oracle Linux 自带的 oci-growfs，其它系统回退到 growpart + resize2fs/xfs_growfs
+const growFilesystemScript = `#!/bin/bash
+set -e
+SUDO=""
+if [ "$(id -u)" != "0" ]; then SUDO="sudo -n"; fi
+
+if [ -e /dev/oracleoci/oraclevda ]; then
+  $SUDO dd iflag=direct if=/dev/oracleoci/oraclevda of=/dev/null count=1 2>/dev/null
+  DEV=$(basename "$(readlink -f /dev/oracleoci/oraclevda)")
+else
+  ROOT_SRC=$(findmnt -no SOURCE /)
+  DEV=$(lsblk -no PKNAME "$ROOT_SRC" | head -n1)
+fi
+if [ -e "/sys/class/block/$DEV/device/rescan" ]; then
+  echo 1 | $SUDO tee "/sys/class/block/$DEV/device/rescan" >/dev/null
+fi
+echo "rescanned /dev/$DEV"
+
+if [ -x /usr/libexec/oci-growfs ]; then
+  $SUDO /usr/libexec/oci-growfs -y
+else
+  ROOT_SRC=$(findmnt -no SOURCE /)
+  PART_NUM=$(cat "/sys/class/block/$(basename "$ROOT_SRC")/partition")
+  $SUDO growpart "/dev/$DEV" "$PART_NUM" || true
+  case "$(findmnt -no FSTYPE /)" in
+    xfs) $SUDO xfs_growfs / ;;
+    ext*) $SUDO resize2fs "$ROOT_SRC" ;;
+    *) echo "unsupported filesystem"; exit 1 ;;
+  esac
+fi
+df -h /
+`
+
+// AgentCommandResult 通过 Cloud Agent 运行命令的执行结果
+type AgentCommandResult struct {
+	CommandID string `json:"commandId"`
+	State     string `json:"state"`
+	ExitCode  int    `json:"exitCode"`
+	Output    string `json:"output"`
+}
+
+// WaitBootVolumeAvailable 等待引导卷扩容或创建完成并回到可用状态
+func (s *OCIService) WaitBootVolumeAvailable(ctx context.Context, user *models.OciUser, bootVolumeId string) error {
+	client, err := s.GetBlockstorageClient(user)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(bootVolumeResizeWaitTimeout)
+	for time.Now().Before(deadline) {
+		resp, err := client.GetBootVolume(ctx, core.GetBootVolumeRequest{BootVolumeId: &bootVolumeId})
+		if err != nil {
+			return fmt.Errorf("failed to get boot volume status: %w", err)
+		}
+		if resp.LifecycleState == core.BootVolumeLifecycleStateAvailable {
+			return nil
+		}
+		time.Sleep(3 * time.Second)
+	}
+	return fmt.Errorf("等待引导卷可用超时")
+}
+
+// GrowBootVolumeFilesystem 通过 Cloud Agent 的运行命令插件在实例内扩展根文件系统
+// 需要实例启用 Compute Instance Run Command 插件，并允许 ocarun 用户免密 sudo
+func (s *OCIService) GrowBootVolumeFilesystem(ctx context.Context, user *models.OciUser, instance *core.Instance) (*AgentCommandResult, error) {
+	if instance.LifecycleState != core.InstanceLifecycleStateRunning {
+		return nil, fmt.Errorf("实例未运行，无法在系统内扩展文件系统")
+	}
+
+	result, err := s.RunAgentCommand(ctx, user, instance, "oci-panel-growfs", growFilesystemScript, growFilesystemTimeoutSeconds)
+	if err != nil {
+		return result, err
+	}
+	if !result.Succeeded() {
+		return result, fmt.Errorf("扩展文件系统失败 (%s, exit %d)", result.State, result.ExitCode)
+	}
+	return result, nil
+}
+
+// Succeeded 判断命令是否执行成功且退出码为 0
+func (r *AgentCommandResult) Succeeded() bool {
+	return r.State == string(computeinstanceagent.InstanceAgentCommandExecutionLifecycleStateSucceeded) && r.ExitCode == 0
+}
+
+// RunAgentCommand 通过 Cloud Agent 的运行命令插件在实例内执行脚本并等待结果
+// 命令执行结束即返回结果，是否成功由调用方通过 Succeeded 判断；仅在创建命令失败或等待超时时返回错误
+func (s *OCIService) RunAgentCommand(ctx context.Context, user *models.OciUser, instance *core.Instance, displayName, script string, timeoutSeconds int) (*AgentCommandResult, error) {
+	client, err := s.GetComputeInstanceAgentClient(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance agent client: %w", err)
+	}
+
+	timeout := timeoutSeconds
+	createResp, err := client.CreateInstanceAgentCommand(ctx, computeinstanceagent.CreateInstanceAgentCommandRequest{
+		CreateInstanceAgentCommandDetails: computeinstanceagent.CreateInstanceAgentCommandDetails{
+			CompartmentId:             instance.CompartmentId,
+			DisplayName:               &displayName,
+			ExecutionTimeOutInSeconds: &timeout,
+			Target: &computeinstanceagent.InstanceAgentCommandTarget{
+				InstanceId: instance.Id,
+			},
+			Content: &computeinstanceagent.InstanceAgentCommandContent{
+				Source: computeinstanceagent.InstanceAgentCommandSourceViaTextDetails{
+					Text: stringPtr(script),
+				},
+				Output: computeinstanceagent.InstanceAgentCommandOutputViaTextDetails{},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create instance agent command: %w", err)
+	}
+
+	result := &AgentCommandResult{CommandID: *createResp.Id}
+	deadline := time.Now().Add(time.Duration(timeoutSeconds+60) * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(5 * time.Second)
+
+		execResp, err := client.GetInstanceAgentCommandExecution(ctx, computeinstanceagent.GetInstanceAgentCommandExecutionRequest{
+			InstanceAgentCommandId: createResp.Id,
+			InstanceId:             instance.Id,
+		})
+		if err != nil {
+			// 实例尚未领取命令时执行记录可能还不存在
+			continue
+		}
+
+		result.State = string(execResp.LifecycleState)
+		switch execResp.LifecycleState {
+		case computeinstanceagent.InstanceAgentCommandExecutionLifecycleStateAccepted,
+			computeinstanceagent.InstanceAgentCommandExecutionLifecycleStateInProgress:
+			continue
+		}
+
+		if output, ok := execResp.Content.(computeinstanceagent.InstanceAgentCommandExecutionOutputViaTextDetails); ok {
+			if output.ExitCode != nil {
+				result.ExitCode = *output.ExitCode
+			}
+			if output.Text != nil {
+				result.Output = *output.Text
+			}
+		}
+		return result, nil
+	}
+
+	result.State = "TIMED_OUT"
+	return result, fmt.Errorf("等待运行命令结果超时，请确认实例已启用运行命令插件")
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	// 均衡性能的 VPU，Always Free 额度只包含该档位，更高档位按量计费
+	balancedVpusPerGB = 10
+
+	VpuDowngradeJobName = "boot_volume_vpu_downgrade"
+)
+
+// BootVolumeVpuAdvice 性能档位高于均衡的引导卷
+type BootVolumeVpuAdvice struct {
+	ConfigID     string `json:"configId"`
+	Username     string `json:"username"`
+	Region       string `json:"region"`
+	BootVolumeID string `json:"bootVolumeId"`
+	Name         string `json:"name"`
+	State        string `json:"state"`
+	SizeInGBs    int64  `json:"sizeInGBs"`
+	VpusPerGB    int64  `json:"vpusPerGB"`
+	AutoTune     bool   `json:"autoTune"` // 开启自动调优时 OCI 可能再次调高性能
+	Downgraded   bool   `json:"downgraded"`
+	Error        string `json:"error,omitempty"`
+}
+
+// VpuAdvisorReport 引导卷性能扫描结果
+type VpuAdvisorReport struct {
+	Volumes  []BootVolumeVpuAdvice `json:"volumes"`
+	Warnings []string              `json:"warnings"` // 扫描失败的配置
+}
+
+// VpuAdvisorService 检查所有配置的引导卷性能档位，超过均衡档位时提示并可批量降级
+// 面板不记录账户类型，所有配置都按免费账户检查，降级前请确认扫描结果
+type VpuAdvisorService struct {
+	ociService *OCIService
+}
+
+func NewVpuAdvisorService(ociService *OCIService) *VpuAdvisorService {
+	return &VpuAdvisorService{ociService: ociService}
+}
+
+// Job 批量降级作业只在手动触发时执行
+func (s *VpuAdvisorService) Job() Job {
+	return &FuncJob{
+		JobName:        VpuDowngradeJobName,
+		JobDescription: "将性能高于均衡档位的引导卷批量降级为均衡（10 VPU/GB），仅手动触发",
+		CheckInterval:  24 * time.Hour,
+		Due:            func() bool { return false },
+		RunFunc: func(ctx context.Context) (string, error) {
+			report, err := s.Scan(ctx, true)
+			if err != nil {
+				return "", err
+			}
+			return summarizeVpuDowngrade(report)
+		},
+	}
+}
+
+// summarizeVpuDowngrade 汇总降级结果，全部降级失败时返回错误
+func summarizeVpuDowngrade(report *VpuAdvisorReport) (string, error) {
+	var downgraded, failed []string
+	for _, v := range report.Volumes {
+		if v.Downgraded {
+			downgraded = append(downgraded, fmt.Sprintf("%s/%s %s", v.Username, v.Region, v.Name))
+		} else {
+			failed = append(failed, fmt.Sprintf("%s/%s %s: %s", v.Username, v.Region, v.Name, v.Error))
+		}
+	}
+	if len(downgraded) == 0 && len(failed) == 0 && len(report.Warnings) == 0 {
+		return "未发现高于均衡性能的引导卷", nil
+	}
+	result := fmt.Sprintf("已降级 %d 个", len(downgraded))
+	if len(downgraded) > 0 {
+		result += "：" + strings.Join(downgraded, ", ")
+	}
+	if len(failed) > 0 {
+		result += fmt.Sprintf("；失败 %d 个：%s", len(failed), strings.Join(failed, ", "))
+	}
+	if len(report.Warnings) > 0 {
+		result += "；扫描失败：" + strings.Join(report.Warnings, ", ")
+	}
+	if len(downgraded) == 0 && (len(failed) > 0 || len(report.Warnings) > 0) {
+		return result, fmt.Errorf("%s", result)
+	}
+	return result, nil
+}
+
+// Scan 检查所有配置主区域内的引导卷，downgrade 为 true 时将超过均衡档位的引导卷降级
+func (s *VpuAdvisorService) Scan(ctx context.Context, downgrade bool) (*VpuAdvisorReport, error) {
+	var users []models.OciUser
+	if err := database.GetDB().Order("create_time ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	report := &VpuAdvisorReport{Volumes: []BootVolumeVpuAdvice{}, Warnings: []string{}}
+	for i := range users {
+		if ctx.Err() != nil {
+			break
+		}
+		user := &users[i]
+		volumes, err := s.listHighVpuBootVolumes(ctx, user)
+		if err != nil {
+			log.Printf("[VpuAdvisor] Failed to list boot volumes for %s: %v", user.Username, err)
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %s", user.Username, extractOCIErrorMessage(err)))
+			continue
+		}
+		for j := range volumes {
+			if downgrade {
+				s.downgrade(ctx, user, &volumes[j])
+			}
+			report.Volumes = append(report.Volumes, volumes[j])
+		}
+	}
+	return report, nil
+}
+
+// listHighVpuBootVolumes 列出租户根区间内性能高于均衡档位的引导卷
+func (s *VpuAdvisorService) listHighVpuBootVolumes(ctx context.Context, user *models.OciUser) ([]BootVolumeVpuAdvice, error) {
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []BootVolumeVpuAdvice
+	req := core.ListBootVolumesRequest{CompartmentId: &user.OciTenantID}
+	for {
+		resp, err := client.ListBootVolumes(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, bv := range resp.Items {
+			if bv.LifecycleState != core.BootVolumeLifecycleStateAvailable || int64Value(bv.VpusPerGB) <= balancedVpusPerGB {
+				continue
+			}
+			volumes = append(volumes, BootVolumeVpuAdvice{
+				ConfigID:     user.ID,
+				Username:     user.Username,
+				Region:       user.OciRegion,
+				BootVolumeID: stringValue(bv.Id),
+				Name:         stringValue(bv.DisplayName),
+				State:        string(bv.LifecycleState),
+				SizeInGBs:    int64Value(bv.SizeInGBs),
+				VpusPerGB:    int64Value(bv.VpusPerGB),
+				AutoTune:     (bv.IsAutoTuneEnabled != nil && *bv.IsAutoTuneEnabled) || len(bv.AutotunePolicies) > 0,
+			})
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return volumes, nil
+}
+
+// downgrade 将引导卷降级为均衡性能并关闭自动调优，挂载中的引导卷可在线修改
+func (s *VpuAdvisorService) downgrade(ctx context.Context, user *models.OciUser, volume *BootVolumeVpuAdvice) {
+	client, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		volume.Error = err.Error()
+		return
+	}
+	details := core.UpdateBootVolumeDetails{VpusPerGB: common.Int64(balancedVpusPerGB)}
+	if volume.AutoTune {
+		details.IsAutoTuneEnabled = common.Bool(false)
+		details.AutotunePolicies = []core.AutotunePolicy{}
+	}
+	_, err = client.UpdateBootVolume(ctx, core.UpdateBootVolumeRequest{
+		BootVolumeId:            &volume.BootVolumeID,
+		UpdateBootVolumeDetails: details,
+	})
+	if err != nil {
+		volume.Error = extractOCIErrorMessage(err)
+		return
+	}
+	log.Printf("[VpuAdvisor] Downgraded boot volume %s (%d -> %d VPU/GB) for %s", volume.Name, volume.VpusPerGB, balancedVpusPerGB, user.Username)
+	volume.Downgraded = true
+	volume.VpusPerGB = balancedVpusPerGB
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+const (
+	CapacityStatusAvailable = string(core.CapacityReportShapeAvailabilityAvailabilityStatusAvailable)
+	CapacityStatusError     = "ERROR"
+
+	capacityProbeTimeout = 60 * time.Second
+)
+
+// taskShape 按架构返回开机使用的 Shape
+func taskShape(architecture string) string {
+	if architecture == "AMD" {
+		return "VM.Standard.E2.1.Micro"
+	}
+	return "VM.Standard.A1.Flex"
+}
+
+// ProbeCapacity 通过容量报告查询区域内各可用域的剩余容量，不创建任何资源
+func (s *OCIService) ProbeCapacity(ctx context.Context, user *models.OciUser, region, architecture string, ocpus, memory float64) ([]models.CapacityProbe, error) {
+	return s.probeShapeCapacity(ctx, user, region, taskShape(architecture), ocpus, memory)
+}
+
+// probeShapeCapacity 查询区域内各可用域对指定 Shape 的剩余容量
+func (s *OCIService) probeShapeCapacity(ctx context.Context, user *models.OciUser, region, shape string, ocpus, memory float64) ([]models.CapacityProbe, error) {
+	regionUser := *user
+	regionUser.OciRegion = region
+
+	identityClient, err := s.GetIdentityClient(&regionUser)
+	if err != nil {
+		return nil, fmt.Errorf("获取身份客户端失败: %w", err)
+	}
+	adResp, err := identityClient.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{
+		CompartmentId: &user.OciTenantID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取可用域失败: %w", err)
+	}
+	computeClient, err := s.GetComputeClient(&regionUser)
+	if err != nil {
+		return nil, fmt.Errorf("获取计算客户端失败: %w", err)
+	}
+
+	details := core.CreateCapacityReportShapeAvailabilityDetails{InstanceShape: &shape}
+	if IsFlexShape(shape) {
+		ocpus32, memory32 := float32(ocpus), float32(memory)
+		details.InstanceShapeConfig = &core.CapacityReportInstanceShapeConfig{Ocpus: &ocpus32, MemoryInGBs: &memory32}
+	}
+
+	now := time.Now()
+	probes := make([]models.CapacityProbe, 0, len(adResp.Items))
+	for _, ad := range adResp.Items {
+		probe := models.CapacityProbe{
+			ID:                 uuid.New().String(),
+			ConfigID:           user.ID,
+			Region:             region,
+			AvailabilityDomain: *ad.Name,
+			Shape:              shape,
+			Ocpus:              ocpus,
+			Memory:             memory,
+			CreateTime:         now,
+		}
+
+		resp, err := computeClient.CreateComputeCapacityReport(ctx, core.CreateComputeCapacityReportRequest{
+			CreateComputeCapacityReportDetails: core.CreateComputeCapacityReportDetails{
+				CompartmentId:       &user.OciTenantID,
+				AvailabilityDomain:  ad.Name,
+				ShapeAvailabilities: []core.CreateCapacityReportShapeAvailabilityDetails{details},
+			},
+		})
+		if err != nil {
+			probe.Status = CapacityStatusError
+			probe.Message = extractOCIErrorMessage(err)
+		} else {
+			// 未指定容错域时结果按容错域拆分，任一容错域可用即视为可用域可用
+			for _, item := range resp.ShapeAvailabilities {
+				if item.AvailableCount != nil {
+					probe.AvailableCount += *item.AvailableCount
+				}
+				if probe.Status != CapacityStatusAvailable {
+					probe.Status = string(item.AvailabilityStatus)
+				}
+			}
+		}
+		probes = append(probes, probe)
+	}
+	return probes, nil
+}
+
+// capacityShapeConfig Flex Shape 未指定规格时按 1 OCPU、每 OCPU 6GB 内存查询，固定规格的 Shape 不需要
+func capacityShapeConfig(shape string, ocpus, memory float64) (float64, float64) {
+	if !IsFlexShape(shape) {
+		return 0, 0
+	}
+	if ocpus <= 0 {
+		ocpus = 1
+	}
+	if memory <= 0 {
+		memory = ocpus * 6
+	}
+	return ocpus, memory
+}
+
+// CheckCapacity 查询租户在多个区域各可用域的容量并写入探测历史，regions 为空时查询所有已订阅区域
+// shape 为空时使用开机任务对应架构的 Shape，区域查询失败时记录一条可用域为空的 ERROR 结果，不影响其它区域
+func (s *OCIService) CheckCapacity(user *models.OciUser, regions []string, shape, architecture string, ocpus, memory float64) ([]models.CapacityProbe, error) {
+	if shape == "" {
+		shape = taskShape(architecture)
+	}
+	if len(regions) == 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), capacityProbeTimeout)
+		subscribed, err := s.ListSubscribedRegions(ctx, user)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("获取已订阅区域失败: %s", extractOCIErrorMessage(err))
+		}
+		regions = subscribed
+	}
+	ocpus, memory = capacityShapeConfig(shape, ocpus, memory)
+
+	results := make([][]models.CapacityProbe, len(regions))
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		wg.Add(1)
+		go func(i int, region string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), capacityProbeTimeout)
+			defer cancel()
+			probes, err := s.probeShapeCapacity(ctx, user, region, shape, ocpus, memory)
+			if err != nil {
+				probes = []models.CapacityProbe{{
+					ID:         uuid.New().String(),
+					ConfigID:   user.ID,
+					Region:     region,
+					Shape:      shape,
+					Ocpus:      ocpus,
+					Memory:     memory,
+					Status:     CapacityStatusError,
+					Message:    extractOCIErrorMessage(err),
+					CreateTime: time.Now(),
+				}}
+			}
+			results[i] = probes
+		}(i, region)
+	}
+	wg.Wait()
+
+	var probes []models.CapacityProbe
+	for _, items := range results {
+		probes = append(probes, items...)
+	}
+	if len(probes) > 0 {
+		if err := database.GetDB().Create(&probes).Error; err != nil {
+			return nil, err
+		}
+	}
+	return probes, nil
+}
+
+// probeTask 以任务的区域与规格执行一次容量探测并记录结果
+func (s *TaskService) probeTask(task *models.OciCreateTask, user *models.OciUser) ([]models.CapacityProbe, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), capacityProbeTimeout)
+	defer cancel()
+
+	probes, err := s.ociService.ProbeCapacity(ctx, user, CurrentTaskRegion(task), task.Architecture, task.Ocpus, task.Memory)
+	if err != nil {
+		return nil, err
+	}
+	for i := range probes {
+		probes[i].TaskID = task.ID
+	}
+	if len(probes) > 0 {
+		if err := database.GetDB().Create(&probes).Error; err != nil {
+			return nil, err
+		}
+	}
+	return probes, nil
+}
+
+// summarizeProbes 生成探测结果摘要，如 "AD-1: 可用(3) | AD-2: 容量不足"
+func summarizeProbes(probes []models.CapacityProbe) string {
+	items := make([]string, 0, len(probes))
+	for _, probe := range probes {
+		name := probe.AvailabilityDomain
+		if idx := strings.LastIndex(name, ":"); idx >= 0 {
+			name = name[idx+1:]
+		}
+		var status string
+		switch probe.Status {
+		case CapacityStatusAvailable:
+			status = fmt.Sprintf("可用(%d)", probe.AvailableCount)
+		case string(core.CapacityReportShapeAvailabilityAvailabilityStatusOutOfHostCapacity):
+			status = "容量不足"
+		case string(core.CapacityReportShapeAvailabilityAvailabilityStatusHardwareNotSupported):
+			status = "不支持"
+		default:
+			status = "查询失败"
+		}
+		items = append(items, fmt.Sprintf("%s: %s", name, status))
+	}
+	return strings.Join(items, " | ")
+}
+
+// executeProbe 探测模式下的一次执行，只记录容量情况，任务不会因此完成
+func (s *TaskService) executeProbe(task *models.OciCreateTask, user *models.OciUser) {
+	probes, err := s.probeTask(task, user)
+
+	now := time.Now()
+	task.ExecuteCount++
+	task.LastExecuteTime = &now
+	if err != nil {
+		task.LastMessage = fmt.Sprintf("容量探测失败: %s", extractOCIErrorMessage(err))
+		s.logTaskExecution(task.ID, "error", task.LastMessage)
+	} else {
+		task.LastMessage = fmt.Sprintf("[%s] %s", CurrentTaskRegion(task), summarizeProbes(probes))
+		s.logTaskExecution(task.ID, "probe", task.LastMessage)
+	}
+	database.GetDB().Save(task)
+
+	if condition := taskStopCondition(task, time.Now()); condition != "" {
+		s.expireTask(task, condition)
+		return
+	}
+	s.scheduleTask(*task)
+}
+
+// ProbeTaskOnce 立即以任务的规格执行一次容量探测，不影响任务状态
+func (s *TaskService) ProbeTaskOnce(taskID string) ([]models.CapacityProbe, error) {
+	db := database.GetDB()
+	var task models.OciCreateTask
+	if err := db.Where("id = ?", taskID).First(&task).Error; err != nil {
+		return nil, fmt.Errorf("任务不存在")
+	}
+	var user models.OciUser
+	if err := db.Where("id = ?", task.UserID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+	return s.probeTask(&task, &user)
+}
+
+// GetCapacityProbeStats 汇总最近 days 天的容量探测结果，region 为空时包含所有区域
+func GetCapacityProbeStats(region string, days int) ([]models.CapacityProbeStat, error) {
+	var rows []struct {
+		Region             string
+		AvailabilityDomain string
+		Shape              string
+		Probes             int64
+		Available          int64
+		LastAvailableTime  string
+		LastProbeTime      string
+	}
+	query := database.GetDB().Model(&models.CapacityProbe{}).
+		Select("region, availability_domain, shape, COUNT(*) AS probes, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS available, "+
+			"COALESCE(MAX(CASE WHEN status = ? THEN create_time END), '') AS last_available_time, "+
+			"MAX(create_time) AS last_probe_time", CapacityStatusAvailable, CapacityStatusAvailable).
+		Where("create_time >= ?", time.Now().AddDate(0, 0, -days))
+	if region != "" {
+		query = query.Where("region = ?", region)
+	}
+	if err := query.Group("region, availability_domain, shape").
+		Order("region, availability_domain, shape").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make([]models.CapacityProbeStat, len(rows))
+	for i, row := range rows {
+		stats[i] = models.CapacityProbeStat{
+			Region:             row.Region,
+			AvailabilityDomain: row.AvailabilityDomain,
+			Shape:              row.Shape,
+			Probes:             row.Probes,
+			Available:          row.Available,
+		}
+		stats[i].LastAvailableTime = formatSQLiteTime(row.LastAvailableTime)
+		stats[i].LastProbeTime = formatSQLiteTime(row.LastProbeTime)
+	}
+	return stats, nil
+}
+
+// formatSQLiteTime 将聚合查询返回的时间文本格式化为 2006-01-02 15:04:05，无法解析时原样返回
+func formatSQLiteTime(value string) string {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.In(time.Local).Format("2006-01-02 15:04:05")
+		}
+	}
+	return value
+}
@@ -0,0 +1,27 @@
+package services
+
+import "testing"
+
+func TestCapacityShapeConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		shape      string
+		ocpus      float64
+		memory     float64
+		wantOcpus  float64
+		wantMemory float64
+	}{
+		{"Flex 未指定规格", "VM.Standard.A1.Flex", 0, 0, 1, 6},
+		{"Flex 只指定 OCPU", "VM.Standard.A1.Flex", 4, 0, 4, 24},
+		{"Flex 指定规格", "VM.Standard.E4.Flex", 2, 8, 2, 8},
+		{"固定规格", "VM.Standard.E2.1.Micro", 1, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ocpus, memory := capacityShapeConfig(tt.shape, tt.ocpus, tt.memory)
+			if ocpus != tt.wantOcpus || memory != tt.wantMemory {
+				t.Errorf("capacityShapeConfig() = %v, %v, want %v, %v", ocpus, memory, tt.wantOcpus, tt.wantMemory)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// OCI 实例元数据 user_data 解码后的大小上限
+const maxUserDataBytes = 32000
+
+// NormalizeUserData 规范化 cloud-init 脚本：已是 base64 时原样保留，否则按明文编码为 base64
+func NormalizeUserData(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	compact := strings.Join(strings.Fields(value), "")
+	decoded, err := base64.StdEncoding.DecodeString(compact)
+	if err != nil || !utf8.Valid(decoded) {
+		decoded = []byte(value)
+		compact = base64.StdEncoding.EncodeToString(decoded)
+	}
+	if len(decoded) > maxUserDataBytes {
+		return "", fmt.Errorf("cloud-init 脚本不能超过 %d 字节", maxUserDataBytes)
+	}
+	return compact, nil
+}
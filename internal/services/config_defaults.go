@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// ConfigDefaults 返回配置的默认开机参数
+func ConfigDefaults(user *models.OciUser) models.OciConfigDefaults {
+	return models.OciConfigDefaults{
+		SSHKeyID:           user.DefaultSSHKeyID,
+		ImageID:            user.DefaultImageID,
+		OperationSystem:    user.DefaultOperationSystem,
+		SubnetID:           user.DefaultSubnetID,
+		AvailabilityDomain: user.DefaultAD,
+	}
+}
+
+// SaveConfigDefaults 校验并保存配置的默认开机参数
+func SaveConfigDefaults(userId string, defaults models.OciConfigDefaults) error {
+	db := database.GetDB()
+
+	var user models.OciUser
+	if err := db.Where("id = ?", userId).First(&user).Error; err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if defaults.SSHKeyID != "" {
+		var sshKey models.SSHKey
+		if err := db.Where("id = ?", defaults.SSHKeyID).First(&sshKey).Error; err != nil {
+			return fmt.Errorf("SSH密钥不存在")
+		}
+	}
+	if defaults.ImageID != "" && !strings.HasPrefix(defaults.ImageID, "ocid1.image.") {
+		return fmt.Errorf("镜像ID无效")
+	}
+	if defaults.SubnetID != "" && !strings.HasPrefix(defaults.SubnetID, "ocid1.subnet.") {
+		return fmt.Errorf("子网ID无效")
+	}
+
+	return db.Model(&user).Updates(map[string]interface{}{
+		"default_ssh_key_id":       defaults.SSHKeyID,
+		"default_image_id":         defaults.ImageID,
+		"default_operation_system": defaults.OperationSystem,
+		"default_subnet_id":        defaults.SubnetID,
+		"default_ad":               defaults.AvailabilityDomain,
+	}).Error
+}
+
+// LaunchDefaults 开机请求中可由配置默认值补全的字段
+type LaunchDefaults struct {
+	SSHKeyID        string
+	ImageID         string
+	OperationSystem string
+}
+
+// ApplyConfigDefaults 使用配置默认值补全未填写的开机参数
+// 镜像 OCID 与区域相关，仅在目标区域为配置主区域时使用默认镜像
+func ApplyConfigDefaults(user *models.OciUser, region string, params *LaunchDefaults) {
+	if params.SSHKeyID == "" {
+		params.SSHKeyID = user.DefaultSSHKeyID
+	}
+	if params.ImageID == "" && params.OperationSystem == "" {
+		if user.DefaultImageID != "" && region == user.OciRegion {
+			params.ImageID = user.DefaultImageID
+		}
+		params.OperationSystem = user.DefaultOperationSystem
+	}
+}
+
+// selectAvailabilityDomain 按序号选择可用域，配置了默认可用域时从默认可用域开始轮换
+func selectAvailabilityDomain(ads []identity.AvailabilityDomain, defaultAD string, adIndex int) string {
+	if adIndex < 0 {
+		adIndex = 0
+	}
+	start := 0
+	for i, ad := range ads {
+		if ad.Name != nil && defaultAD != "" && (*ad.Name == defaultAD || strings.HasSuffix(*ad.Name, ":"+defaultAD)) {
+			start = i
+			break
+		}
+	}
+	return *ads[(start+adIndex)%len(ads)].Name
+}
+
+// resolveDefaultSubnet 校验配置的默认子网，可用时返回子网ID及其所属可用域（区域级子网为空）
+func resolveDefaultSubnet(ctx context.Context, client core.VirtualNetworkClient, subnetId string) (string, string, error) {
+	resp, err := client.GetSubnet(ctx, core.GetSubnetRequest{SubnetId: &subnetId})
+	if err != nil {
+		return "", "", fmt.Errorf("获取默认子网失败: %w", err)
+	}
+	if resp.LifecycleState != core.SubnetLifecycleStateAvailable {
+		return "", "", fmt.Errorf("默认子网不可用: %s", resp.LifecycleState)
+	}
+	ad := ""
+	if resp.AvailabilityDomain != nil {
+		ad = *resp.AvailabilityDomain
+	}
+	return *resp.Id, ad, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// Always Free 块存储额度（引导卷与块存储卷合计）
+const alwaysFreeStorageGBs = 200
+
+// ConfigResourceUsage 资源用量与 Always Free 额度
+type ConfigResourceUsage struct {
+	Used  float32 `json:"used"`
+	Limit float32 `json:"limit"`
+}
+
+// ConfigSummary 单个配置的资源概览
+type ConfigSummary struct {
+	ConfigID         string              `json:"configId"`
+	Username         string              `json:"username"`
+	Region           string              `json:"region"`
+	InstanceCount    int                 `json:"instanceCount"`
+	States           map[string]int      `json:"states"`
+	TotalOcpus       float32             `json:"totalOcpus"`
+	TotalMemoryGBs   float32             `json:"totalMemoryGBs"`
+	A1Ocpus          ConfigResourceUsage `json:"a1Ocpus"`
+	A1MemoryGBs      ConfigResourceUsage `json:"a1MemoryGBs"`
+	MicroInstances   ConfigResourceUsage `json:"microInstances"`
+	StorageGBs       ConfigResourceUsage `json:"storageGBs"`
+	BootVolumeCount  int                 `json:"bootVolumeCount"`
+	BlockVolumeCount int                 `json:"blockVolumeCount"`
+	InboundTraffic   int64               `json:"inboundTraffic"`
+	OutboundTraffic  int64               `json:"outboundTraffic"`
+	Warnings         []string            `json:"warnings"`
+}
+
+// GetConfigSummary 汇总配置主区域的实例状态、算力/存储用量（对比 Always Free 额度）及本月流量
+// 单项查询失败时记录到 Warnings，不影响其它数据
+func (s *OCIService) GetConfigSummary(ctx context.Context, user *models.OciUser) (*ConfigSummary, error) {
+	instances, err := s.ListInstances(ctx, user, user.OciTenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ConfigSummary{
+		ConfigID:       user.ID,
+		Username:       user.Username,
+		Region:         user.OciRegion,
+		States:         map[string]int{},
+		A1Ocpus:        ConfigResourceUsage{Limit: alwaysFreeA1Ocpus},
+		A1MemoryGBs:    ConfigResourceUsage{Limit: alwaysFreeA1MemoryGBs},
+		MicroInstances: ConfigResourceUsage{Limit: alwaysFreeMicroCount},
+		StorageGBs:     ConfigResourceUsage{Limit: alwaysFreeStorageGBs},
+		Warnings:       []string{},
+	}
+
+	for _, inst := range instances {
+		if inst.LifecycleState == core.InstanceLifecycleStateTerminated {
+			continue
+		}
+		summary.InstanceCount++
+		summary.States[string(inst.LifecycleState)]++
+
+		var ocpus, memory float32
+		if inst.ShapeConfig != nil {
+			ocpus = derefFloat32(inst.ShapeConfig.Ocpus)
+			memory = derefFloat32(inst.ShapeConfig.MemoryInGBs)
+		}
+		summary.TotalOcpus += ocpus
+		summary.TotalMemoryGBs += memory
+
+		if inst.Shape == nil || inst.LifecycleState == core.InstanceLifecycleStateTerminating {
+			continue
+		}
+		switch *inst.Shape {
+		case alwaysFreeA1FlexShape:
+			summary.A1Ocpus.Used += ocpus
+			summary.A1MemoryGBs.Used += memory
+		case alwaysFreeMicroShape:
+			summary.MicroInstances.Used++
+		}
+	}
+
+	if err := s.sumConfigStorage(ctx, user, summary); err != nil {
+		summary.Warnings = append(summary.Warnings, "无法统计存储用量: "+extractOCIErrorMessage(err))
+	}
+
+	traffic, err := s.GetMonthlyTrafficStats(ctx, user)
+	if err != nil {
+		summary.Warnings = append(summary.Warnings, "无法获取流量统计: "+extractOCIErrorMessage(err))
+	} else {
+		summary.InboundTraffic = traffic.InboundTraffic
+		summary.OutboundTraffic = traffic.OutboundTraffic
+	}
+
+	return summary, nil
+}
+
+// sumConfigStorage 统计租户根区间内引导卷与块存储卷的总容量
+func (s *OCIService) sumConfigStorage(ctx context.Context, user *models.OciUser, summary *ConfigSummary) error {
+	client, err := s.GetBlockstorageClient(user)
+	if err != nil {
+		return err
+	}
+
+	bootReq := core.ListBootVolumesRequest{CompartmentId: &user.OciTenantID}
+	for {
+		resp, err := client.ListBootVolumes(ctx, bootReq)
+		if err != nil {
+			return err
+		}
+		for _, bv := range resp.Items {
+			if bv.LifecycleState == core.BootVolumeLifecycleStateTerminated || bv.SizeInGBs == nil {
+				continue
+			}
+			summary.BootVolumeCount++
+			summary.StorageGBs.Used += float32(*bv.SizeInGBs)
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		bootReq.Page = resp.OpcNextPage
+	}
+
+	volumeReq := core.ListVolumesRequest{CompartmentId: &user.OciTenantID}
+	for {
+		resp, err := client.ListVolumes(ctx, volumeReq)
+		if err != nil {
+			return err
+		}
+		for _, v := range resp.Items {
+			if v.LifecycleState == core.VolumeLifecycleStateTerminated || v.SizeInGBs == nil {
+				continue
+			}
+			summary.BlockVolumeCount++
+			summary.StorageGBs.Used += float32(*v.SizeInGBs)
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		volumeReq.Page = resp.OpcNextPage
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+const (
+	// 免费试用期天数，从租户创建时间起算
+	freeTrialDays = 30
+
+	credentialsHealthTimeout     = 60 * time.Second
+	credentialsHealthConcurrency = 5
+
+	configAliveTimeout = 10 * time.Second
+)
+
+// ConfigAliveResult 单个配置的存活检查结果
+type ConfigAliveResult struct {
+	ConfigID  string `json:"configId"`
+	Username  string `json:"username"`
+	Region    string `json:"region"`
+	Alive     bool   `json:"alive"`
+	Error     string `json:"error"`
+	LatencyMs int64  `json:"latencyMs"`
+	CheckTime string `json:"checkTime"`
+}
+
+// CheckConfigsAlive 并发检查配置的 API 凭据是否可用，configIDs 为空时检查所有配置
+func (s *OCIService) CheckConfigsAlive(ctx context.Context, configIDs []string) ([]*ConfigAliveResult, error) {
+	query := database.GetDB().Order("create_time ASC")
+	if len(configIDs) > 0 {
+		query = query.Where("id IN ?", configIDs)
+	}
+	var users []models.OciUser
+	if err := query.Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]*ConfigAliveResult, len(users))
+	semaphore := make(chan struct{}, credentialsHealthConcurrency)
+	var wg sync.WaitGroup
+	for i := range users {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i] = s.checkConfigAlive(ctx, &users[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// checkConfigAlive 通过列出实例验证配置可用，并记录检查结果
+func (s *OCIService) checkConfigAlive(ctx context.Context, user *models.OciUser) *ConfigAliveResult {
+	ctx, cancel := context.WithTimeout(ctx, configAliveTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := s.ListInstances(ctx, user, user.OciTenantID)
+	RecordConfigHealth(user.ID, err)
+
+	result := &ConfigAliveResult{
+		ConfigID:  user.ID,
+		Username:  user.Username,
+		Region:    user.OciRegion,
+		Alive:     err == nil,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckTime: start.Format("2006-01-02 15:04:05"),
+	}
+	if err != nil {
+		result.Error = extractOCIErrorMessage(err)
+	}
+	return result
+}
+
+// CredentialsHealth 单个配置的 API 凭据健康状况
+type CredentialsHealth struct {
+	ConfigID           string   `json:"configId"`
+	Username           string   `json:"username"`
+	Region             string   `json:"region"`
+	Alive              bool     `json:"alive"`
+	Fingerprint        string   `json:"fingerprint"`
+	KeyState           string   `json:"keyState"`
+	KeyCreateTime      string   `json:"keyCreateTime"`
+	KeyAgeDays         int      `json:"keyAgeDays"`
+	LastSuccessTime    string   `json:"lastSuccessTime"`
+	LastErrorTime      string   `json:"lastErrorTime"`
+	LastError          string   `json:"lastError"`
+	SubscribedRegions  []string `json:"subscribedRegions"`
+	ReachableRegions   []string `json:"reachableRegions"`
+	UnreachableRegions []string `json:"unreachableRegions"`
+	TrialExpireTime    string   `json:"trialExpireTime"` // 按租户创建时间推算，租户创建时间未知时为空
+	TrialDaysLeft      *int     `json:"trialDaysLeft"`   // 负数表示试用期已结束
+}
+
+// RecordConfigHealth 记录配置最近一次 OCI 调用的结果
+func RecordConfigHealth(configID string, err error) {
+	now := time.Now()
+	updates := map[string]interface{}{"last_success_time": now}
+	if err != nil {
+		updates = map[string]interface{}{
+			"last_error_time": now,
+			"last_error":      extractOCIErrorMessage(err),
+		}
+	}
+	database.GetDB().Model(&models.OciUser{}).Where("id = ?", configID).Updates(updates)
+}
+
+// GetCredentialsHealthReport 检查所有配置的凭据健康状况
+func (s *OCIService) GetCredentialsHealthReport(ctx context.Context) ([]*CredentialsHealth, error) {
+	var users []models.OciUser
+	if err := database.GetDB().Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	report := make([]*CredentialsHealth, len(users))
+	semaphore := make(chan struct{}, credentialsHealthConcurrency)
+	var wg sync.WaitGroup
+	for i := range users {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			report[i] = s.CheckCredentialsHealth(ctx, &users[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return report, nil
+}
+
+// CheckCredentialsHealth 检查单个配置的 API 密钥、可访问区域及试用期，并记录检查结果
+func (s *OCIService) CheckCredentialsHealth(ctx context.Context, user *models.OciUser) *CredentialsHealth {
+	ctx, cancel := context.WithTimeout(ctx, credentialsHealthTimeout)
+	defer cancel()
+
+	health := &CredentialsHealth{
+		ConfigID:           user.ID,
+		Username:           user.Username,
+		Region:             user.OciRegion,
+		Fingerprint:        user.OciFingerprint,
+		SubscribedRegions:  []string{},
+		ReachableRegions:   []string{},
+		UnreachableRegions: []string{},
+	}
+	fillTrialExpiry(health, user.TenantCreateTime)
+
+	err := s.checkAPIKey(ctx, user, health)
+	if err == nil {
+		health.Alive = true
+		s.checkRegionsReachable(ctx, user, health)
+	}
+	RecordConfigHealth(user.ID, err)
+
+	var latest models.OciUser
+	if database.GetDB().Where("id = ?", user.ID).First(&latest).Error == nil {
+		if latest.LastSuccessTime != nil {
+			health.LastSuccessTime = latest.LastSuccessTime.Format("2006-01-02 15:04:05")
+		}
+		if latest.LastErrorTime != nil {
+			health.LastErrorTime = latest.LastErrorTime.Format("2006-01-02 15:04:05")
+		}
+		health.LastError = latest.LastError
+	}
+	return health
+}
+
+// checkAPIKey 查询配置所用 API 密钥的状态与创建时间
+func (s *OCIService) checkAPIKey(ctx context.Context, user *models.OciUser, health *CredentialsHealth) error {
+	client, err := s.GetIdentityClient(user)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.ListApiKeys(ctx, identity.ListApiKeysRequest{UserId: &user.OciUserID})
+	if err != nil {
+		return err
+	}
+	for _, key := range resp.Items {
+		if key.Fingerprint == nil || *key.Fingerprint != user.OciFingerprint {
+			continue
+		}
+		health.KeyState = string(key.LifecycleState)
+		if key.TimeCreated != nil {
+			health.KeyCreateTime = key.TimeCreated.Format("2006-01-02 15:04:05")
+			health.KeyAgeDays = int(time.Since(key.TimeCreated.Time).Hours() / 24)
+		}
+		break
+	}
+	return nil
+}
+
+// checkRegionsReachable 逐个探测已订阅区域是否可以正常调用
+func (s *OCIService) checkRegionsReachable(ctx context.Context, user *models.OciUser, health *CredentialsHealth) {
+	regions, err := s.ListSubscribedRegions(ctx, user)
+	if err != nil {
+		log.Printf("Failed to list subscribed regions for %s: %v", user.Username, err)
+		return
+	}
+	health.SubscribedRegions = regions
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, region := range regions {
+		wg.Add(1)
+		go func(region string) {
+			defer wg.Done()
+			client, err := s.GetIdentityClient(user)
+			if err == nil {
+				client.SetRegion(region)
+				_, err = client.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{CompartmentId: &user.OciTenantID})
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				health.UnreachableRegions = append(health.UnreachableRegions, region)
+			} else {
+				health.ReachableRegions = append(health.ReachableRegions, region)
+			}
+		}(region)
+	}
+	wg.Wait()
+
+	sort.Strings(health.ReachableRegions)
+	sort.Strings(health.UnreachableRegions)
+}
+
+// fillTrialExpiry 按租户创建时间推算免费试用期的结束时间
+func fillTrialExpiry(health *CredentialsHealth, tenantCreateTime *time.Time) {
+	if tenantCreateTime == nil {
+		return
+	}
+	expireTime := tenantCreateTime.AddDate(0, 0, freeTrialDays)
+	daysLeft := int(time.Until(expireTime).Hours() / 24)
+	health.TrialExpireTime = expireTime.Format("2006-01-02 15:04:05")
+	health.TrialDaysLeft = &daysLeft
+}
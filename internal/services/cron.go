@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule 五段式 cron 表达式（分 时 日 月 周），使用服务器本地时间
+type cronSchedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	domAny bool
+	dowAny bool
+}
+
+// cronFieldBounds 各段的取值范围，周日可写作 0 或 7
+var cronFieldBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// cronDescriptors 常用周期的简写
+var cronDescriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// parseCron 解析 cron 表达式，支持 *、列表、范围与步长，如 "0 1 * * 1-5"、"*/30 8-20 * * *"，
+// 也支持 @hourly、@daily、@weekly、@monthly 简写
+func parseCron(expr string) (*cronSchedule, error) {
+	if spec, ok := cronDescriptors[strings.TrimSpace(expr)]; ok {
+		expr = spec
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron 表达式需包含 5 段：分 时 日 月 周")
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		value, err := parseCronField(field, cronFieldBounds[i][0], cronFieldBounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron 表达式第 %d 段无效: %w", i+1, err)
+		}
+		bits[i] = value
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &cronSchedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("步长无效: %s", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangeText != "*" {
+			startText, endText, isRange := strings.Cut(rangeText, "-")
+			start, err := strconv.Atoi(startText)
+			if err != nil {
+				return 0, fmt.Errorf("取值无效: %s", part)
+			}
+			lo, hi = start, start
+			if isRange {
+				if hi, err = strconv.Atoi(endText); err != nil {
+					return 0, fmt.Errorf("取值无效: %s", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("取值超出范围 %d-%d: %s", min, max, part)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// dayMatches 日与周同时指定时满足其一即可，与标准 cron 一致
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// matches 判断时间所在的分钟是否匹配
+func (c *cronSchedule) matches(t time.Time) bool {
+	return c.minute&(1<<uint(t.Minute())) != 0 &&
+		c.hour&(1<<uint(t.Hour())) != 0 &&
+		c.month&(1<<uint(t.Month())) != 0 &&
+		c.dayMatches(t)
+}
+
+// next 返回 after 之后第一个匹配的分钟，五年内没有匹配时返回零值
+func (c *cronSchedule) next(after time.Time) time.Time {
+	loc := after.Location()
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute()+1, 0, 0, loc)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// truncateMinute 截断到所在分钟的开始
+func truncateMinute(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+}
+
+// cronWindow 记录上次检查时间，计算每次检查需要匹配的分钟范围
+type cronWindow struct {
+	lastCheck time.Time
+	catchUp   time.Duration // 暂停超过该时长后不再补上错过的分钟
+}
+
+// advance 返回自上次检查以来需要匹配的分钟范围 [from, to]
+// 检查间隔偶尔超过一分钟时补上中间错过的分钟，首次检查或暂停过久时只检查当前分钟
+func (w *cronWindow) advance(now time.Time) (time.Time, time.Time) {
+	to := truncateMinute(now)
+	from := to
+	if !w.lastCheck.IsZero() && now.Sub(w.lastCheck) <= w.catchUp {
+		from = truncateMinute(w.lastCheck).Add(time.Minute)
+	}
+	w.lastCheck = now
+	return from, to
+}
+
+// dueBetween 判断 [from, to] 内是否有匹配的分钟
+func (c *cronSchedule) dueBetween(from, to time.Time) bool {
+	for t := from; !t.After(to); t = t.Add(time.Minute) {
+		if c.matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeCron 校验 cron 表达式并合并多余空白
+func normalizeCron(expr string) (string, error) {
+	expr = strings.Join(strings.Fields(expr), " ")
+	if _, err := parseCron(expr); err != nil {
+		return "", err
+	}
+	return expr, nil
+}
+
+// cronNextRunText 格式化表达式在 after 之后的下次执行时间，表达式无效或没有匹配时返回空
+func cronNextRunText(expr string, after time.Time) string {
+	cron, err := parseCron(expr)
+	if err != nil {
+		return ""
+	}
+	if next := cron.next(after); !next.IsZero() {
+		return next.Format("2006-01-02 15:04:05")
+	}
+	return ""
+}
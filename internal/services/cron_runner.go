@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+)
+
+// cronRunner 按计划自身的 cron 表达式调度的通用作业，定时开关机、IP 定时更换与定时备份共用
+// 到期的计划先加入待执行队列，作业正在执行导致本次未能开始时保留到下次执行
+type cronRunner[T any] struct {
+	logPrefix string
+	window    cronWindow
+	load      func() ([]T, error)       // 加载已启用的计划
+	key       func(*T) (string, string) // 返回计划 ID 与 cron 表达式
+	run       func(context.Context, []T) (string, error)
+	mu        sync.Mutex
+	pending   []T
+}
+
+// newCronRunner 创建从数据库加载已启用计划的调度器
+func newCronRunner[T any](logPrefix string, catchUp time.Duration, key func(*T) (string, string), run func(context.Context, []T) (string, error)) *cronRunner[T] {
+	return &cronRunner[T]{
+		logPrefix: logPrefix,
+		window:    cronWindow{catchUp: catchUp},
+		load: func() ([]T, error) {
+			var schedules []T
+			err := database.GetDB().Where("enabled = ?", true).Find(&schedules).Error
+			return schedules, err
+		},
+		key: key,
+		run: run,
+	}
+}
+
+// job 返回每分钟检查一次的作业，只有存在到期计划时才会执行并记录
+func (r *cronRunner[T]) job(name, description string) Job {
+	return &FuncJob{
+		JobName:        name,
+		JobDescription: description,
+		CheckInterval:  time.Minute,
+		Due:            r.collectDue,
+		RunFunc:        r.runDue,
+	}
+}
+
+// collectDue 找出自上次检查以来到期的计划加入待执行队列，已在队列中的计划不重复加入
+func (r *cronRunner[T]) collectDue() bool {
+	schedules, err := r.load()
+	if err != nil {
+		log.Printf("[%s] Failed to load schedules: %v", r.logPrefix, err)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(r.pending) > 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queued := make(map[string]bool, len(r.pending))
+	for i := range r.pending {
+		id, _ := r.key(&r.pending[i])
+		queued[id] = true
+	}
+	from, to := r.window.advance(time.Now())
+	for i := range schedules {
+		id, expr := r.key(&schedules[i])
+		if queued[id] {
+			continue
+		}
+		if cron, err := parseCron(expr); err == nil && cron.dueBetween(from, to) {
+			r.pending = append(r.pending, schedules[i])
+			queued[id] = true
+		}
+	}
+	return len(r.pending) > 0
+}
+
+// runDue 取出待执行队列并执行；手动触发时队列通常为空，先检查一次到期计划
+func (r *cronRunner[T]) runDue(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	empty := len(r.pending) == 0
+	r.mu.Unlock()
+	if empty {
+		r.collectDue()
+	}
+
+	r.mu.Lock()
+	due := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+
+	if len(due) == 0 {
+		return "没有到期的计划", nil
+	}
+	return r.run(ctx, due)
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCronWindowAdvance(t *testing.T) {
+	base := time.Date(2026, 10, 15, 8, 0, 30, 0, time.Local)
+	tests := []struct {
+		name      string
+		lastCheck time.Time
+		now       time.Time
+		wantFrom  time.Time
+		wantTo    time.Time
+	}{
+		{"首次检查只匹配当前分钟", time.Time{}, base, base.Truncate(time.Minute), base.Truncate(time.Minute)},
+		{"正常间隔", base, base.Add(time.Minute), base.Truncate(time.Minute).Add(time.Minute), base.Truncate(time.Minute).Add(time.Minute)},
+		{"补上错过的分钟", base, base.Add(3 * time.Minute), base.Truncate(time.Minute).Add(time.Minute), base.Truncate(time.Minute).Add(3 * time.Minute)},
+		{"暂停过久不补", base, base.Add(time.Hour), base.Truncate(time.Minute).Add(time.Hour), base.Truncate(time.Minute).Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := cronWindow{lastCheck: tt.lastCheck, catchUp: 10 * time.Minute}
+			from, to := w.advance(tt.now)
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("advance = [%v, %v], want [%v, %v]", from, to, tt.wantFrom, tt.wantTo)
+			}
+			if !w.lastCheck.Equal(tt.now) {
+				t.Errorf("lastCheck = %v, want %v", w.lastCheck, tt.now)
+			}
+		})
+	}
+}
+
+type testSchedule struct {
+	ID   string
+	Cron string
+}
+
+// newTestCronRunner 创建从固定列表加载计划的调度器，返回每次执行收到的计划 ID
+func newTestCronRunner(schedules []testSchedule) (*cronRunner[testSchedule], *[][]string) {
+	var runs [][]string
+	r := newCronRunner("Test", 10*time.Minute,
+		func(s *testSchedule) (string, string) { return s.ID, s.Cron },
+		func(ctx context.Context, due []testSchedule) (string, error) {
+			var ids []string
+			for _, s := range due {
+				ids = append(ids, s.ID)
+			}
+			runs = append(runs, ids)
+			return "", nil
+		})
+	r.load = func() ([]testSchedule, error) { return schedules, nil }
+	return r, &runs
+}
+
+func TestCronRunnerCollectDue(t *testing.T) {
+	// 2 月 31 日不存在，该表达式永远不会到期
+	schedules := []testSchedule{{"every", "* * * * *"}, {"never", "0 0 31 2 *"}, {"invalid", "bad"}}
+	tests := []struct {
+		name    string
+		pending []testSchedule
+		want    []string
+	}{
+		{"只收集到期的计划", nil, []string{"every"}},
+		{"保留上次未执行的计划且不重复加入", []testSchedule{{"every", "* * * * *"}, {"never", "0 0 31 2 *"}}, []string{"every", "never"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestCronRunner(schedules)
+			r.pending = tt.pending
+			if !r.collectDue() {
+				t.Fatal("collectDue = false, want true")
+			}
+			var got []string
+			for _, s := range r.pending {
+				got = append(got, s.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("pending = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("pending = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCronRunnerRunDue(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedules []testSchedule
+		collect   bool // 执行前是否已有一次到期检查
+		wantRuns  int
+		wantMsg   string
+	}{
+		{"按计划执行已收集的计划", []testSchedule{{"every", "* * * * *"}}, true, 1, ""},
+		{"手动执行时先检查到期计划", []testSchedule{{"every", "* * * * *"}}, false, 1, ""},
+		{"没有到期计划时不执行", []testSchedule{{"never", "0 0 31 2 *"}}, false, 0, "没有到期的计划"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, runs := newTestCronRunner(tt.schedules)
+			if tt.collect {
+				r.collectDue()
+			}
+			msg, err := r.runDue(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(*runs) != tt.wantRuns || msg != tt.wantMsg {
+				t.Errorf("runs = %v, message = %q, want %d runs, message %q", *runs, msg, tt.wantRuns, tt.wantMsg)
+			}
+			if len(r.pending) != 0 {
+				t.Errorf("pending = %v, want empty", r.pending)
+			}
+		})
+	}
+}
+
+func TestCronDueBetween(t *testing.T) {
+	from := time.Date(2026, 10, 15, 8, 1, 0, 0, time.Local)
+	tests := []struct {
+		expr string
+		to   time.Time
+		want bool
+	}{
+		{"5 8 * * *", from.Add(9 * time.Minute), true},
+		{"5 8 * * *", from.Add(3 * time.Minute), false},
+		{"*/15 * * * *", from.Add(13 * time.Minute), false},
+		{"*/15 * * * *", from.Add(14 * time.Minute), true},
+		{"0 9 * * *", from.Add(time.Hour), true},
+	}
+	for _, tt := range tests {
+		cron, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := cron.dueBetween(from, tt.to); got != tt.want {
+			t.Errorf("%q dueBetween(%s, %s) = %v, want %v", tt.expr, from.Format("15:04"), tt.to.Format("15:04"), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	cryptSHA512Prefix        = "$6$"
+	cryptSHA512DefaultRounds = 5000
+	cryptSHA512MaxSaltLength = 16
+	cryptAlphabet            = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// cryptSHA512Order 摘要字节在输出中的排列顺序，每组三个字节编码为四个字符
+var cryptSHA512Order = [21][3]int{
+	{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4}, {47, 5, 26}, {6, 27, 48},
+	{28, 49, 7}, {50, 8, 29}, {9, 30, 51}, {31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13},
+	{56, 14, 35}, {15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19}, {62, 20, 41},
+}
+
+// HashPasswordSHA512 生成 crypt(3) SHA-512 格式（$6$）的密码哈希，可用于 chpasswd -e 与 /etc/shadow
+func HashPasswordSHA512(password string) (string, error) {
+	salt := make([]byte, cryptSHA512MaxSaltLength)
+	for i := range salt {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(cryptAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		salt[i] = cryptAlphabet[n.Int64()]
+	}
+	return cryptSHA512(password, cryptSHA512Prefix+string(salt))
+}
+
+// cryptSHA512 按 Ulrich Drepper 的 SHA-crypt 规范计算哈希，setting 为 $6$[rounds=N$]salt
+func cryptSHA512(password, setting string) (string, error) {
+	if !strings.HasPrefix(setting, cryptSHA512Prefix) {
+		return "", fmt.Errorf("不支持的哈希格式")
+	}
+	rest := strings.TrimPrefix(setting, cryptSHA512Prefix)
+
+	rounds := cryptSHA512DefaultRounds
+	customRounds := false
+	if strings.HasPrefix(rest, "rounds=") {
+		end := strings.IndexByte(rest, '$')
+		if end < 0 {
+			return "", fmt.Errorf("哈希格式不正确")
+		}
+		n, err := strconv.Atoi(rest[len("rounds="):end])
+		if err != nil {
+			return "", fmt.Errorf("哈希格式不正确")
+		}
+		rounds = min(max(n, 1000), 999999999)
+		customRounds = true
+		rest = rest[end+1:]
+	}
+	salt := rest
+	if i := strings.IndexByte(salt, '$'); i >= 0 {
+		salt = salt[:i]
+	}
+	if len(salt) > cryptSHA512MaxSaltLength {
+		salt = salt[:cryptSHA512MaxSaltLength]
+	}
+
+	p, s := []byte(password), []byte(salt)
+
+	alt := sha512.New()
+	alt.Write(p)
+	alt.Write(s)
+	alt.Write(p)
+	altSum := alt.Sum(nil)
+
+	a := sha512.New()
+	a.Write(p)
+	a.Write(s)
+	for i := len(p); i > 0; i -= sha512.Size {
+		a.Write(altSum[:min(i, sha512.Size)])
+	}
+	for i := len(p); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			a.Write(altSum)
+		} else {
+			a.Write(p)
+		}
+	}
+	sum := a.Sum(nil)
+
+	dp := sha512.New()
+	for range p {
+		dp.Write(p)
+	}
+	pSeq := repeatBytes(dp.Sum(nil), len(p))
+
+	ds := sha512.New()
+	for i := 0; i < 16+int(sum[0]); i++ {
+		ds.Write(s)
+	}
+	sSeq := repeatBytes(ds.Sum(nil), len(s))
+
+	for i := 0; i < rounds; i++ {
+		c := sha512.New()
+		if i&1 != 0 {
+			c.Write(pSeq)
+		} else {
+			c.Write(sum)
+		}
+		if i%3 != 0 {
+			c.Write(sSeq)
+		}
+		if i%7 != 0 {
+			c.Write(pSeq)
+		}
+		if i&1 != 0 {
+			c.Write(sum)
+		} else {
+			c.Write(pSeq)
+		}
+		sum = c.Sum(nil)
+	}
+
+	var out strings.Builder
+	out.WriteString(cryptSHA512Prefix)
+	if customRounds {
+		fmt.Fprintf(&out, "rounds=%d$", rounds)
+	}
+	out.WriteString(salt)
+	out.WriteByte('$')
+	for _, group := range cryptSHA512Order {
+		writeCryptBase64(&out, uint(sum[group[0]])<<16|uint(sum[group[1]])<<8|uint(sum[group[2]]), 4)
+	}
+	writeCryptBase64(&out, uint(sum[63]), 2)
+	return out.String(), nil
+}
+
+// repeatBytes 重复 digest 直到长度为 n
+func repeatBytes(digest []byte, n int) []byte {
+	seq := make([]byte, 0, n)
+	for len(seq) < n {
+		seq = append(seq, digest[:min(n-len(seq), len(digest))]...)
+	}
+	return seq
+}
+
+// writeCryptBase64 以 crypt 字母表从低位开始输出 n 个字符
+func writeCryptBase64(out *strings.Builder, value uint, n int) {
+	for i := 0; i < n; i++ {
+		out.WriteByte(cryptAlphabet[value&0x3f])
+		value >>= 6
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCryptSHA512(t *testing.T) {
+	// 规范中的测试向量
+	tests := []struct {
+		name     string
+		setting  string
+		password string
+		want     string
+	}{
+		{"默认轮数", "$6$saltstring", "Hello world!",
+			"$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"指定轮数且盐超长", "$6$rounds=10000$saltstringsaltstring", "Hello world!",
+			"$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+		{"轮数低于下限", "$6$rounds=10$roundstoolow", "the minimum number is still observed",
+			"$6$rounds=1000$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cryptSHA512(tt.password, tt.setting)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("cryptSHA512() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashPasswordSHA512(t *testing.T) {
+	hash, err := HashPasswordSHA512("Secret-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[1] != "6" || len(parts[2]) != cryptSHA512MaxSaltLength || len(parts[3]) != 86 {
+		t.Fatalf("哈希格式不正确: %s", hash)
+	}
+	again, _ := cryptSHA512("Secret-123", hash)
+	if again != hash {
+		t.Errorf("使用相同盐重新计算 = %s, want %s", again, hash)
+	}
+}
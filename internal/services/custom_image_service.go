@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+)
+
+// CustomImageService 管理租户的自定义镜像，用于维护黄金镜像
+type CustomImageService struct {
+	ociService *OCIService
+}
+
+func NewCustomImageService(ociService *OCIService) *CustomImageService {
+	return &CustomImageService{ociService: ociService}
+}
+
+// CustomImageInfo 自定义镜像信息
+type CustomImageInfo struct {
+	ID                     string `json:"id"`
+	DisplayName            string `json:"displayName"`
+	CompartmentID          string `json:"compartmentId"`
+	OperatingSystem        string `json:"operatingSystem"`
+	OperatingSystemVersion string `json:"operatingSystemVersion"`
+	State                  string `json:"state"`
+	SizeInMBs              int64  `json:"sizeInMBs"`
+	BillableSizeInGBs      int64  `json:"billableSizeInGBs"`
+	BaseImageID            string `json:"baseImageId"`
+	LaunchMode             string `json:"launchMode"`
+	TimeCreated            string `json:"timeCreated"`
+}
+
+// IsImageExportFormat 检查导出格式是否受支持，空值表示使用默认的 OCI 格式
+func IsImageExportFormat(format string) bool {
+	if format == "" {
+		return true
+	}
+	_, ok := core.GetMappingExportImageDetailsExportFormatEnum(format)
+	return ok
+}
+
+func newCustomImageInfo(img core.Image) CustomImageInfo {
+	info := CustomImageInfo{
+		ID:                     stringValue(img.Id),
+		DisplayName:            stringValue(img.DisplayName),
+		CompartmentID:          stringValue(img.CompartmentId),
+		OperatingSystem:        stringValue(img.OperatingSystem),
+		OperatingSystemVersion: stringValue(img.OperatingSystemVersion),
+		State:                  string(img.LifecycleState),
+		SizeInMBs:              int64Value(img.SizeInMBs),
+		BillableSizeInGBs:      int64Value(img.BillableSizeInGBs),
+		BaseImageID:            stringValue(img.BaseImageId),
+		LaunchMode:             string(img.LaunchMode),
+	}
+	if img.TimeCreated != nil {
+		info.TimeCreated = img.TimeCreated.Format("2006-01-02 15:04:05")
+	}
+	return info
+}
+
+// customImageUser 读取配置，region 不为空时切换到该区域
+func customImageUser(userId, region string) (*models.OciUser, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if region != "" {
+		user.OciRegion = region
+	}
+	return &user, nil
+}
+
+// ListCustomImages 列出区间内的自定义镜像，compartmentId 为空时使用租户根区间
+func (s *CustomImageService) ListCustomImages(userId, compartmentId, region string) ([]CustomImageInfo, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	if compartmentId == "" {
+		compartmentId = user.OciTenantID
+	}
+	client, err := s.ociService.GetComputeClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	images := []CustomImageInfo{}
+	req := core.ListImagesRequest{
+		CompartmentId: &compartmentId,
+		SortBy:        core.ListImagesSortByTimecreated,
+		SortOrder:     core.ListImagesSortOrderDesc,
+	}
+	for {
+		resp, err := client.ListImages(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		// 平台镜像不属于任何区间，只保留区间内的自定义镜像
+		for _, img := range resp.Items {
+			if img.CompartmentId != nil && *img.CompartmentId == compartmentId {
+				images = append(images, newCustomImageInfo(img))
+			}
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return images, nil
+}
+
+// CreateFromInstance 从实例创建自定义镜像，镜像创建在实例所在区间，创建期间实例会短暂停止响应
+func (s *CustomImageService) CreateFromInstance(userId, instanceId, displayName, region string) (*CustomImageInfo, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	instance, err := s.ociService.GetInstance(ctx, user, instanceId)
+	if err != nil {
+		return nil, err
+	}
+	if displayName == "" {
+		displayName = fmt.Sprintf("%s-%s", stringValue(instance.DisplayName), time.Now().Format("20060102-150405"))
+	}
+
+	client, err := s.ociService.GetComputeClient(user)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.CreateImage(ctx, core.CreateImageRequest{
+		CreateImageDetails: core.CreateImageDetails{
+			CompartmentId: instance.CompartmentId,
+			InstanceId:    instance.Id,
+			DisplayName:   &displayName,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建镜像失败: %s", extractOCIErrorMessage(err))
+	}
+
+	log.Printf("[CustomImage] Creating image %s from instance %s for %s", displayName, instanceId, user.Username)
+	info := newCustomImageInfo(resp.Image)
+	return &info, nil
+}
+
+// getCustomImage 获取镜像并确认是自定义镜像，避免误操作平台镜像
+func (s *CustomImageService) getCustomImage(ctx context.Context, client core.ComputeClient, imageId string) (*core.Image, error) {
+	resp, err := client.GetImage(ctx, core.GetImageRequest{ImageId: &imageId})
+	if err != nil {
+		return nil, err
+	}
+	if resp.CompartmentId == nil {
+		return nil, fmt.Errorf("平台镜像不支持该操作")
+	}
+	return &resp.Image, nil
+}
+
+// DeleteCustomImage 删除自定义镜像
+func (s *CustomImageService) DeleteCustomImage(userId, imageId, region string) error {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return err
+	}
+	client, err := s.ociService.GetComputeClient(user)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	image, err := s.getCustomImage(ctx, client, imageId)
+	if err != nil {
+		return err
+	}
+	if _, err := client.DeleteImage(ctx, core.DeleteImageRequest{ImageId: &imageId}); err != nil {
+		return fmt.Errorf("删除镜像失败: %s", extractOCIErrorMessage(err))
+	}
+
+	log.Printf("[CustomImage] Deleted image %s (%s) for %s", stringValue(image.DisplayName), imageId, user.Username)
+	return nil
+}
+
+// ExportCustomImage 将自定义镜像导出到对象存储桶，返回对象名，导出在 OCI 后台进行
+// objectName 为空时使用镜像名称，format 为空时导出为 OCI 格式
+func (s *CustomImageService) ExportCustomImage(userId, imageId, bucketName, objectName, format, region string) (string, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return "", err
+	}
+	client, err := s.ociService.GetComputeClient(user)
+	if err != nil {
+		return "", err
+	}
+	storageClient, err := s.ociService.GetObjectStorageClient(user)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	image, err := s.getCustomImage(ctx, client, imageId)
+	if err != nil {
+		return "", err
+	}
+	nsResp, err := storageClient.GetNamespace(ctx, objectstorage.GetNamespaceRequest{})
+	if err != nil {
+		return "", fmt.Errorf("获取对象存储命名空间失败: %s", extractOCIErrorMessage(err))
+	}
+
+	exportFormat := core.ExportImageDetailsExportFormatOci
+	if format != "" {
+		exportFormat, _ = core.GetMappingExportImageDetailsExportFormatEnum(format)
+	}
+	if objectName == "" {
+		objectName = stringValue(image.DisplayName) + "." + strings.ToLower(string(exportFormat))
+	}
+
+	_, err = client.ExportImage(ctx, core.ExportImageRequest{
+		ImageId: &imageId,
+		ExportImageDetails: core.ExportImageViaObjectStorageTupleDetails{
+			BucketName:    common.String(bucketName),
+			NamespaceName: nsResp.Value,
+			ObjectName:    common.String(objectName),
+			ExportFormat:  exportFormat,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("导出镜像失败: %s", extractOCIErrorMessage(err))
+	}
+
+	log.Printf("[CustomImage] Exporting image %s to %s/%s for %s", imageId, bucketName, objectName, user.Username)
+	return objectName, nil
+}
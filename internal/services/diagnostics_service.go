@@ -0,0 +1,266 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	DiagnosticStatusOK      = "ok"
+	DiagnosticStatusWarning = "warning"
+	DiagnosticStatusError   = "error"
+
+	// 启动后等待任务服务加载完定时器再检查，避免误报
+	diagnosticsStartupDelay = 10 * time.Second
+)
+
+// DiagnosticCheck 单项检查结果
+type DiagnosticCheck struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+}
+
+// DiagnosticsReport 自检报告
+type DiagnosticsReport struct {
+	Healthy   bool              `json:"healthy"`
+	CheckTime string            `json:"checkTime"`
+	Checks    []DiagnosticCheck `json:"checks"`
+}
+
+type DiagnosticsService struct {
+	ociService      *OCIService
+	taskService     *TaskService
+	telegramService *TelegramService
+	lastReport      *DiagnosticsReport
+	mu              sync.RWMutex
+}
+
+func NewDiagnosticsService(ociService *OCIService, taskService *TaskService, telegramService *TelegramService) *DiagnosticsService {
+	return &DiagnosticsService{
+		ociService:      ociService,
+		taskService:     taskService,
+		telegramService: telegramService,
+	}
+}
+
+// RunStartupChecks 启动时执行自检，并将发现的问题写入日志
+func (s *DiagnosticsService) RunStartupChecks() {
+	time.Sleep(diagnosticsStartupDelay)
+
+	report := s.Run()
+	for _, check := range report.Checks {
+		if check.Status == DiagnosticStatusOK {
+			continue
+		}
+		log.Printf("[Diagnostics] %s: %s", check.Name, check.Message)
+		for _, detail := range check.Details {
+			log.Printf("[Diagnostics]   - %s", detail)
+		}
+	}
+	if report.Healthy {
+		log.Println("[Diagnostics] All startup checks passed")
+	}
+}
+
+// Run 执行全部检查并缓存结果
+func (s *DiagnosticsService) Run() *DiagnosticsReport {
+	checks := []DiagnosticCheck{
+		s.checkSchema(),
+		s.checkOciKeys(),
+		s.checkTelegram(),
+		s.checkTaskScheduler(),
+	}
+
+	healthy := true
+	for _, check := range checks {
+		if check.Status == DiagnosticStatusError {
+			healthy = false
+			break
+		}
+	}
+
+	report := &DiagnosticsReport{
+		Healthy:   healthy,
+		CheckTime: time.Now().Format("2006-01-02 15:04:05"),
+		Checks:    checks,
+	}
+
+	s.mu.Lock()
+	s.lastReport = report
+	s.mu.Unlock()
+
+	return report
+}
+
+// GetLastReport 获取最近一次自检结果，尚未执行过时立即执行
+func (s *DiagnosticsService) GetLastReport() *DiagnosticsReport {
+	s.mu.RLock()
+	report := s.lastReport
+	s.mu.RUnlock()
+
+	if report == nil {
+		return s.Run()
+	}
+	return report
+}
+
+// checkSchema 检查数据库结构版本及表是否完整
+func (s *DiagnosticsService) checkSchema() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "database_schema"}
+	db := database.GetDB()
+
+	var missing []string
+	for _, model := range models.AllModels() {
+		if !db.Migrator().HasTable(model) {
+			missing = append(missing, fmt.Sprintf("缺少数据表: %T", model))
+		}
+	}
+
+	var setting models.SysSetting
+	if err := db.Where("key = ?", models.SettingSchemaVersion).First(&setting).Error; err != nil {
+		check.Status = DiagnosticStatusError
+		check.Message = "未找到数据库结构版本记录"
+		check.Details = missing
+		return check
+	}
+
+	version, err := strconv.Atoi(setting.Value)
+	if err != nil {
+		check.Status = DiagnosticStatusError
+		check.Message = fmt.Sprintf("数据库结构版本无效: %s", setting.Value)
+		check.Details = missing
+		return check
+	}
+
+	if len(missing) > 0 {
+		check.Status = DiagnosticStatusError
+		check.Message = "数据表不完整"
+		check.Details = missing
+		return check
+	}
+
+	if version > models.SchemaVersion {
+		check.Status = DiagnosticStatusError
+		check.Message = fmt.Sprintf("数据库已被更新版本的面板迁移: 当前 %d, 程序支持 %d，请升级面板", version, models.SchemaVersion)
+		return check
+	}
+	if version != models.SchemaVersion {
+		check.Status = DiagnosticStatusError
+		check.Message = fmt.Sprintf("数据库结构版本不匹配: 当前 %d, 期望 %d", version, models.SchemaVersion)
+		return check
+	}
+
+	check.Status = DiagnosticStatusOK
+	check.Message = fmt.Sprintf("数据库结构版本 %d", version)
+	return check
+}
+
+// checkOciKeys 检查所有配置的私钥文件是否可读取并解析
+func (s *DiagnosticsService) checkOciKeys() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "oci_keys"}
+
+	var users []models.OciUser
+	if err := database.GetDB().Find(&users).Error; err != nil {
+		check.Status = DiagnosticStatusError
+		check.Message = fmt.Sprintf("读取配置失败: %v", err)
+		return check
+	}
+
+	for i := range users {
+		user := &users[i]
+		provider, err := s.ociService.GetConfigProvider(user)
+		if err == nil {
+			_, err = provider.PrivateRSAKey()
+		}
+		if err != nil {
+			check.Details = append(check.Details, fmt.Sprintf("%s: %v", user.Username, err))
+		}
+	}
+
+	if len(check.Details) > 0 {
+		check.Status = DiagnosticStatusError
+		check.Message = fmt.Sprintf("%d/%d 个配置的私钥无法解析", len(check.Details), len(users))
+		return check
+	}
+
+	check.Status = DiagnosticStatusOK
+	check.Message = fmt.Sprintf("%d 个配置的私钥均可解析", len(users))
+	return check
+}
+
+// checkTelegram 已启用 Telegram 时校验 Bot Token 是否有效
+func (s *DiagnosticsService) checkTelegram() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "telegram"}
+
+	_, _, enabled := s.telegramService.GetConfig()
+	if !enabled {
+		check.Status = DiagnosticStatusOK
+		check.Message = "Telegram 未启用"
+		return check
+	}
+
+	if err := s.telegramService.TestConnection(); err != nil {
+		check.Status = DiagnosticStatusError
+		check.Message = fmt.Sprintf("Bot Token 校验失败: %v", err)
+		return check
+	}
+
+	if !s.telegramService.IsRunning() {
+		check.Status = DiagnosticStatusWarning
+		check.Message = "Telegram 已启用但 Bot 未运行"
+		return check
+	}
+
+	check.Status = DiagnosticStatusOK
+	check.Message = "Bot Token 有效"
+	return check
+}
+
+// checkTaskScheduler 检查数据库中的任务状态与内存中的定时器是否一致
+func (s *DiagnosticsService) checkTaskScheduler() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "task_scheduler"}
+
+	var tasks []models.OciCreateTask
+	if err := database.GetDB().Find(&tasks).Error; err != nil {
+		check.Status = DiagnosticStatusError
+		check.Message = fmt.Sprintf("读取任务失败: %v", err)
+		return check
+	}
+
+	scheduled := s.taskService.scheduledTaskIDs()
+	running := 0
+	for _, task := range tasks {
+		isRunning := task.Status == "running"
+		if isRunning {
+			running++
+		}
+		switch {
+		case isRunning && !scheduled[task.ID]:
+			check.Details = append(check.Details, fmt.Sprintf("任务 %s 状态为运行中但未被调度", task.ID))
+		case !isRunning && scheduled[task.ID]:
+			check.Details = append(check.Details, fmt.Sprintf("任务 %s 状态为 %s 但仍有定时器", task.ID, task.Status))
+		}
+		delete(scheduled, task.ID)
+	}
+	for id := range scheduled {
+		check.Details = append(check.Details, fmt.Sprintf("定时器 %s 对应的任务已不存在", id))
+	}
+
+	if len(check.Details) > 0 {
+		check.Status = DiagnosticStatusWarning
+		check.Message = "任务状态与调度器不一致"
+		return check
+	}
+
+	check.Status = DiagnosticStatusOK
+	check.Message = fmt.Sprintf("%d 个运行中任务均已调度", running)
+	return check
+}
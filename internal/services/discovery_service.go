@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/networkloadbalancer"
+)
+
+const (
+	InventoryTypeInstance = "instance"
+	InventoryTypeVCN      = "vcn"
+	InventoryTypeVolume   = "volume"
+	InventoryTypeNLB      = "nlb"
+
+	// 单个区间的扫描超时
+	discoveryCompartmentTimeout = 60 * time.Second
+	// 发现任务结果保留时间
+	discoveryJobRetention = 24 * time.Hour
+)
+
+// DiscoveredResource 扫描到的资源
+type DiscoveredResource struct {
+	Type            string `json:"type"` // instance / vcn / volume / nlb
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	CompartmentID   string `json:"compartmentId"`
+	CompartmentName string `json:"compartmentName"`
+	State           string `json:"state"`
+	Detail          string `json:"detail"`  // 规格、网段、容量或 IP 等摘要
+	Adopted         bool   `json:"adopted"` // 是否已纳入本地清单
+}
+
+// DiscoveryJob 资源发现任务
+type DiscoveryJob struct {
+	ID           string               `json:"id"`
+	ConfigID     string               `json:"configId"`
+	Status       string               `json:"status"` // running, completed, error
+	Compartments int                  `json:"compartments"`
+	Scanned      int                  `json:"scanned"`
+	Resources    []DiscoveredResource `json:"resources"`
+	Errors       []string             `json:"errors"` // 部分区间或资源类型扫描失败的原因
+	Error        string               `json:"error,omitempty"`
+	CreateTime   string               `json:"createTime"`
+	finishTime   time.Time
+}
+
+// InventoryAdoptItem 选择纳入清单的资源
+type InventoryAdoptItem struct {
+	ResourceType    string `json:"resourceType" binding:"required"`
+	ResourceID      string `json:"resourceId" binding:"required"`
+	Name            string `json:"name"`
+	CompartmentID   string `json:"compartmentId"`
+	CompartmentName string `json:"compartmentName"`
+	Notes           string `json:"notes"`
+	Tags            string `json:"tags"`
+}
+
+type DiscoveryService struct {
+	ociService *OCIService
+	mu         sync.RWMutex
+	jobs       map[string]*DiscoveryJob
+}
+
+func NewDiscoveryService(ociService *OCIService) *DiscoveryService {
+	return &DiscoveryService{
+		ociService: ociService,
+		jobs:       make(map[string]*DiscoveryJob),
+	}
+}
+
+// StartDiscovery 启动资源发现任务，扫描配置主区域下所有可访问区间的实例、VCN、块存储卷与网络负载均衡器
+func (s *DiscoveryService) StartDiscovery(configID string) (string, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", configID).First(&user).Error; err != nil {
+		return "", fmt.Errorf("配置不存在")
+	}
+
+	job := &DiscoveryJob{
+		ID:         uuid.New().String(),
+		ConfigID:   configID,
+		Status:     "running",
+		Resources:  []DiscoveredResource{},
+		Errors:     []string{},
+		CreateTime: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	s.mu.Lock()
+	s.cleanupJobs()
+	s.jobs[job.ID] = job
+	s.mu.Unlock()
+
+	go s.runDiscovery(job, &user)
+	return job.ID, nil
+}
+
+// cleanupJobs 删除已结束且超过保留时间的任务，调用方需持有写锁
+func (s *DiscoveryService) cleanupJobs() {
+	for id, job := range s.jobs {
+		if job.Status != "running" && time.Since(job.finishTime) > discoveryJobRetention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func (s *DiscoveryService) runDiscovery(job *DiscoveryJob, user *models.OciUser) {
+	ctx, cancel := context.WithTimeout(context.Background(), discoveryCompartmentTimeout)
+	tree, err := s.ociService.ListCompartments(ctx, user)
+	cancel()
+	if err != nil {
+		s.mu.Lock()
+		job.Status = "error"
+		job.Error = "获取区间失败: " + extractOCIErrorMessage(err)
+		job.finishTime = time.Now()
+		s.mu.Unlock()
+		return
+	}
+
+	var compartments []models.CompartmentInfo
+	var flatten func(node models.CompartmentInfo)
+	flatten = func(node models.CompartmentInfo) {
+		compartments = append(compartments, node)
+		for _, child := range node.Children {
+			flatten(child)
+		}
+	}
+	flatten(*tree)
+
+	s.mu.Lock()
+	job.Compartments = len(compartments)
+	s.mu.Unlock()
+
+	adopted := make(map[string]bool)
+	var items []models.InventoryItem
+	database.GetDB().Where("config_id = ?", user.ID).Find(&items)
+	for _, item := range items {
+		adopted[item.ResourceID] = true
+	}
+
+	for _, compartment := range compartments {
+		ctx, cancel := context.WithTimeout(context.Background(), discoveryCompartmentTimeout)
+		resources, errs := s.scanCompartment(ctx, user, compartment)
+		cancel()
+		for i := range resources {
+			resources[i].Adopted = adopted[resources[i].ID]
+		}
+
+		s.mu.Lock()
+		job.Scanned++
+		job.Resources = append(job.Resources, resources...)
+		job.Errors = append(job.Errors, errs...)
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	job.Status = "completed"
+	job.finishTime = time.Now()
+	s.mu.Unlock()
+}
+
+// scanCompartment 扫描单个区间，某类资源失败时记录错误并继续扫描其它类型
+func (s *DiscoveryService) scanCompartment(ctx context.Context, user *models.OciUser, compartment models.CompartmentInfo) ([]DiscoveredResource, []string) {
+	var resources []DiscoveredResource
+	var errs []string
+	add := func(resourceType, id, name, state, detail string) {
+		resources = append(resources, DiscoveredResource{
+			Type:            resourceType,
+			ID:              id,
+			Name:            name,
+			CompartmentID:   compartment.ID,
+			CompartmentName: compartment.Name,
+			State:           state,
+			Detail:          detail,
+		})
+	}
+	fail := func(resourceType string, err error) {
+		errs = append(errs, fmt.Sprintf("%s/%s: %s", compartment.Name, resourceType, extractOCIErrorMessage(err)))
+	}
+
+	if computeClient, err := s.ociService.GetComputeClient(user); err != nil {
+		fail(InventoryTypeInstance, err)
+	} else {
+		req := core.ListInstancesRequest{CompartmentId: &compartment.ID}
+		for {
+			resp, err := computeClient.ListInstances(ctx, req)
+			if err != nil {
+				fail(InventoryTypeInstance, err)
+				break
+			}
+			for _, instance := range resp.Items {
+				if instance.LifecycleState == core.InstanceLifecycleStateTerminated {
+					continue
+				}
+				detail := stringValue(instance.Shape)
+				if instance.ShapeConfig != nil && instance.ShapeConfig.Ocpus != nil && instance.ShapeConfig.MemoryInGBs != nil {
+					detail = fmt.Sprintf("%s %g OCPU / %gGB", detail, *instance.ShapeConfig.Ocpus, *instance.ShapeConfig.MemoryInGBs)
+				}
+				add(InventoryTypeInstance, stringValue(instance.Id), stringValue(instance.DisplayName), string(instance.LifecycleState), detail)
+			}
+			if resp.OpcNextPage == nil {
+				break
+			}
+			req.Page = resp.OpcNextPage
+		}
+	}
+
+	if vnClient, err := s.ociService.GetVirtualNetworkClient(user); err != nil {
+		fail(InventoryTypeVCN, err)
+	} else if resp, err := vnClient.ListVcns(ctx, core.ListVcnsRequest{CompartmentId: &compartment.ID}); err != nil {
+		fail(InventoryTypeVCN, err)
+	} else {
+		for _, vcn := range resp.Items {
+			if vcn.LifecycleState == core.VcnLifecycleStateTerminated {
+				continue
+			}
+			add(InventoryTypeVCN, stringValue(vcn.Id), stringValue(vcn.DisplayName), string(vcn.LifecycleState), strings.Join(vcn.CidrBlocks, ", "))
+		}
+	}
+
+	if blockClient, err := s.ociService.GetBlockstorageClient(user); err != nil {
+		fail(InventoryTypeVolume, err)
+	} else if resp, err := blockClient.ListVolumes(ctx, core.ListVolumesRequest{CompartmentId: &compartment.ID}); err != nil {
+		fail(InventoryTypeVolume, err)
+	} else {
+		for _, volume := range resp.Items {
+			if volume.LifecycleState == core.VolumeLifecycleStateTerminated {
+				continue
+			}
+			detail := ""
+			if volume.SizeInGBs != nil {
+				detail = fmt.Sprintf("%dGB", *volume.SizeInGBs)
+			}
+			add(InventoryTypeVolume, stringValue(volume.Id), stringValue(volume.DisplayName), string(volume.LifecycleState), detail)
+		}
+	}
+
+	if nlbClient, err := s.ociService.GetNetworkLoadBalancerClient(user); err != nil {
+		fail(InventoryTypeNLB, err)
+	} else if resp, err := nlbClient.ListNetworkLoadBalancers(ctx, networkloadbalancer.ListNetworkLoadBalancersRequest{CompartmentId: &compartment.ID}); err != nil {
+		fail(InventoryTypeNLB, err)
+	} else {
+		for _, nlb := range resp.Items {
+			if nlb.LifecycleState == networkloadbalancer.LifecycleStateDeleted {
+				continue
+			}
+			var ips []string
+			for _, ip := range nlb.IpAddresses {
+				ips = append(ips, stringValue(ip.IpAddress))
+			}
+			add(InventoryTypeNLB, stringValue(nlb.Id), stringValue(nlb.DisplayName), string(nlb.LifecycleState), strings.Join(ips, ", "))
+		}
+	}
+
+	return resources, errs
+}
+
+// GetDiscoveryJob 获取资源发现任务状态
+func (s *DiscoveryService) GetDiscoveryJob(jobID string) (*DiscoveryJob, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *job
+	snapshot.Resources = append([]DiscoveredResource(nil), job.Resources...)
+	snapshot.Errors = append([]string(nil), job.Errors...)
+	return &snapshot, true
+}
+
+// normalizeInventoryTags 规范化逗号分隔的标签，去除空白与重复项
+func normalizeInventoryTags(value string) string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		tag := strings.TrimSpace(part)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return strings.Join(tags, ",")
+}
+
+// AdoptResources 将选中的资源纳入本地清单，已存在的资源更新名称、备注与标签，返回处理的数量
+func (s *DiscoveryService) AdoptResources(configID string, adoptItems []InventoryAdoptItem) (int, error) {
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", configID).First(&user).Error; err != nil {
+		return 0, fmt.Errorf("配置不存在")
+	}
+
+	for _, adoptItem := range adoptItems {
+		switch adoptItem.ResourceType {
+		case InventoryTypeInstance, InventoryTypeVCN, InventoryTypeVolume, InventoryTypeNLB:
+		default:
+			return 0, fmt.Errorf("不支持的资源类型: %s", adoptItem.ResourceType)
+		}
+	}
+
+	now := time.Now()
+	for _, adoptItem := range adoptItems {
+		var item models.InventoryItem
+		if err := db.Where("config_id = ? AND resource_id = ?", configID, adoptItem.ResourceID).First(&item).Error; err != nil {
+			item = models.InventoryItem{
+				ID:           uuid.New().String(),
+				ConfigID:     configID,
+				ResourceID:   adoptItem.ResourceID,
+				Region:       user.OciRegion,
+				AdoptTime:    now,
+				ResourceType: adoptItem.ResourceType,
+			}
+		}
+		item.Name = adoptItem.Name
+		item.CompartmentID = adoptItem.CompartmentID
+		item.CompartmentName = adoptItem.CompartmentName
+		item.Notes = adoptItem.Notes
+		item.Tags = normalizeInventoryTags(adoptItem.Tags)
+		if err := db.Save(&item).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(adoptItems), nil
+}
+
+// ListInventory 获取本地清单，参数为空时不按该条件过滤
+func ListInventory(configID, resourceType, tag string) ([]models.InventoryItem, error) {
+	query := database.GetDB().Order("adopt_time DESC")
+	if configID != "" {
+		query = query.Where("config_id = ?", configID)
+	}
+	if resourceType != "" {
+		query = query.Where("resource_type = ?", resourceType)
+	}
+	if tag = strings.TrimSpace(tag); tag != "" {
+		query = query.Where("(',' || tags || ',') LIKE ?", "%,"+tag+",%")
+	}
+	var items []models.InventoryItem
+	err := query.Find(&items).Error
+	return items, err
+}
+
+// UpdateInventoryItem 更新清单项的备注与标签
+func UpdateInventoryItem(id, notes, tags string) error {
+	result := database.GetDB().Model(&models.InventoryItem{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"notes": notes,
+		"tags":  normalizeInventoryTags(tags),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("清单项不存在")
+	}
+	return nil
+}
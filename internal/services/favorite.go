@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	FavoriteTypeConfig   = "config"
+	FavoriteTypeInstance = "instance"
+)
+
+// ListFavorites 获取用户的收藏，按收藏时间倒序
+func ListFavorites(username string) ([]models.Favorite, error) {
+	favorites := []models.Favorite{}
+	err := database.GetDB().Where("username = ?", username).Order("create_time DESC").Find(&favorites).Error
+	return favorites, err
+}
+
+// SetFavorite 收藏或取消收藏配置或实例，收藏实例时 configID 为实例所属配置
+func SetFavorite(username, targetType, targetID, configID string, favorite bool) error {
+	if targetType != FavoriteTypeConfig && targetType != FavoriteTypeInstance {
+		return fmt.Errorf("不支持的收藏类型: %s", targetType)
+	}
+	if targetType == FavoriteTypeConfig {
+		configID = targetID
+	}
+
+	db := database.GetDB()
+	if !favorite {
+		return db.Where("username = ? AND target_type = ? AND target_id = ?", username, targetType, targetID).
+			Delete(&models.Favorite{}).Error
+	}
+
+	var count int64
+	if err := db.Model(&models.OciUser{}).Where("id = ?", configID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("配置不存在")
+	}
+	item := models.Favorite{
+		ID:         uuid.New().String(),
+		Username:   username,
+		TargetType: targetType,
+		TargetID:   targetID,
+		ConfigID:   configID,
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error
+}
+
+// favoriteIDs 用户收藏的配置或实例 ID，username 为空时返回任意用户的收藏（Telegram 机器人不区分面板用户）
+func favoriteIDs(username, targetType string) map[string]bool {
+	query := database.GetDB().Model(&models.Favorite{}).Where("target_type = ?", targetType)
+	if username != "" {
+		query = query.Where("username = ?", username)
+	}
+	var ids []string
+	query.Distinct().Pluck("target_id", &ids)
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// FavoriteConfigIDs 用户收藏的配置 ID
+func FavoriteConfigIDs(username string) []string {
+	set := favoriteIDs(username, FavoriteTypeConfig)
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// sortFavoritesFirst 将收藏的项稳定地移到列表最前，其余顺序不变
+func sortFavoritesFirst[T any](items []T, isFavorite func(T) bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return isFavorite(items[i]) && !isFavorite(items[j])
+	})
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// 会话空闲超过该时间后断开 SFTP 连接
+	fileManagerIdleTimeout = 10 * time.Minute
+	fileManagerDialTimeout = 20 * time.Second
+
+	// MaxEditableFileSize 在线编辑的文件大小上限，更大的文件需下载后编辑
+	MaxEditableFileSize = 1 << 20
+	// MaxUploadFileSize 单个上传文件的大小上限
+	MaxUploadFileSize = 100 << 20
+)
+
+// FileManagerSession 实例文件管理会话，令牌在空闲超时前可重复使用
+type FileManagerSession struct {
+	Token       string `json:"token"`
+	InstanceID  string `json:"instanceId"`
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Username    string `json:"username"`
+	HomeDir     string `json:"homeDir"`
+	Fingerprint string `json:"fingerprint"` // 主机密钥指纹
+
+	sshClient  *ssh.Client
+	sftpClient *sftp.Client
+	idleTimer  *time.Timer
+}
+
+// FileEntry 目录中的文件
+type FileEntry struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode"` // 如 -rw-r--r--
+	IsDir   bool      `json:"isDir"`
+	IsLink  bool      `json:"isLink"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// FileContent 在线编辑的文本文件内容
+type FileContent struct {
+	Path    string    `json:"path"`
+	Content string    `json:"content"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+type FileManagerService struct {
+	ociService *OCIService
+	mu         sync.Mutex
+	sessions   map[string]*FileManagerSession
+}
+
+func NewFileManagerService(ociService *OCIService) *FileManagerService {
+	return &FileManagerService{
+		ociService: ociService,
+		sessions:   make(map[string]*FileManagerSession),
+	}
+}
+
+// Connect 使用面板保存或用户提供的 SSH 凭据建立 SFTP 连接，返回后续操作使用的令牌
+func (s *FileManagerService) Connect(userId, instanceId string, opts SSHConnectOptions) (*FileManagerSession, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	target, err := s.ociService.resolveSSHTarget(context.Background(), &user, instanceId, opts)
+	if err != nil {
+		return nil, err
+	}
+	sshClient, fingerprint, err := target.dial(fileManagerDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	sftpClient, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, fmt.Errorf("打开 SFTP 失败: %w", err)
+	}
+	homeDir, err := sftpClient.Getwd()
+	if err != nil {
+		homeDir = "/"
+	}
+
+	session := &FileManagerSession{
+		Token:       uuid.New().String(),
+		InstanceID:  instanceId,
+		Host:        target.Host,
+		Port:        target.Port,
+		Username:    target.Username,
+		HomeDir:     homeDir,
+		Fingerprint: fingerprint,
+		sshClient:   sshClient,
+		sftpClient:  sftpClient,
+	}
+	token := session.Token
+	session.idleTimer = time.AfterFunc(fileManagerIdleTimeout, func() {
+		_ = s.Disconnect(token)
+	})
+	s.mu.Lock()
+	s.sessions[token] = session
+	s.mu.Unlock()
+	return session, nil
+}
+
+// Disconnect 断开会话的 SFTP 连接
+func (s *FileManagerService) Disconnect(token string) error {
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	delete(s.sessions, token)
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("会话不存在或已断开")
+	}
+	session.idleTimer.Stop()
+	_ = session.sftpClient.Close()
+	_ = session.sshClient.Close()
+	return nil
+}
+
+// client 获取会话的 SFTP 客户端并重置空闲计时
+func (s *FileManagerService) client(token string) (*sftp.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[token]
+	if !ok {
+		return nil, fmt.Errorf("会话不存在或已断开，请重新连接")
+	}
+	session.idleTimer.Reset(fileManagerIdleTimeout)
+	return session.sftpClient, nil
+}
+
+// List 列出目录内容，目录在前并按名称排序
+func (s *FileManagerService) List(token, dir string) ([]FileEntry, error) {
+	client, err := s.client(token)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		if dir, err = client.Getwd(); err != nil {
+			dir = "/"
+		}
+	}
+	infos, err := client.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取目录失败: %w", err)
+	}
+
+	entries := make([]FileEntry, 0, len(infos))
+	for _, info := range infos {
+		entry := FileEntry{
+			Name:    info.Name(),
+			Path:    path.Join(dir, info.Name()),
+			Size:    info.Size(),
+			Mode:    info.Mode().String(),
+			IsDir:   info.IsDir(),
+			IsLink:  info.Mode()&os.ModeSymlink != 0,
+			ModTime: info.ModTime(),
+		}
+		// 指向目录的符号链接按目录展示，便于直接进入
+		if entry.IsLink {
+			if target, err := client.Stat(entry.Path); err == nil {
+				entry.IsDir = target.IsDir()
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// ReadFile 读取文本文件用于在线编辑，超过大小上限或非 UTF-8 内容时拒绝
+func (s *FileManagerService) ReadFile(token, filePath string) (*FileContent, error) {
+	client, err := s.client(token)
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("文件不存在: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("不能编辑目录")
+	}
+	if info.Size() > MaxEditableFileSize {
+		return nil, fmt.Errorf("文件超过 %s，请下载后编辑", FormatBytes(MaxEditableFileSize))
+	}
+
+	file, err := client.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, MaxEditableFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("文件不是文本文件，请下载后查看")
+	}
+	return &FileContent{Path: filePath, Content: string(data), Size: int64(len(data)), ModTime: info.ModTime()}, nil
+}
+
+// WriteFile 保存在线编辑的文件内容，已存在的文件保留原有权限
+func (s *FileManagerService) WriteFile(token, filePath, content string) error {
+	if len(content) > MaxEditableFileSize {
+		return fmt.Errorf("内容超过 %s", FormatBytes(MaxEditableFileSize))
+	}
+	client, err := s.client(token)
+	if err != nil {
+		return err
+	}
+	file, err := client.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("打开文件失败: %w", err)
+	}
+	defer file.Close()
+	if _, err := io.WriteString(file, content); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return nil
+}
+
+// Upload 将文件上传到指定目录，同名文件会被覆盖
+func (s *FileManagerService) Upload(token, dir, name string, reader io.Reader) (string, error) {
+	if name == "" || name == "." || name == ".." || path.Base(name) != name {
+		return "", fmt.Errorf("文件名无效")
+	}
+	client, err := s.client(token)
+	if err != nil {
+		return "", err
+	}
+	filePath := path.Join(dir, name)
+	file, err := client.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return "", fmt.Errorf("创建文件失败: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.ReadFrom(reader); err != nil {
+		return "", fmt.Errorf("上传失败: %w", err)
+	}
+	return filePath, nil
+}
+
+// Open 打开文件用于下载，调用方负责关闭
+func (s *FileManagerService) Open(token, filePath string) (*sftp.File, os.FileInfo, error) {
+	client, err := s.client(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := client.Stat(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("文件不存在: %w", err)
+	}
+	if info.IsDir() {
+		return nil, nil, fmt.Errorf("不能下载目录")
+	}
+	file, err := client.Open(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+	return file, info, nil
+}
+
+// Mkdir 创建目录，父目录不存在时一并创建
+func (s *FileManagerService) Mkdir(token, dir string) error {
+	client, err := s.client(token)
+	if err != nil {
+		return err
+	}
+	if err := client.MkdirAll(dir); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	return nil
+}
+
+// Rename 重命名或移动文件
+func (s *FileManagerService) Rename(token, oldPath, newPath string) error {
+	client, err := s.client(token)
+	if err != nil {
+		return err
+	}
+	if err := client.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("重命名失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除文件或目录，目录会递归删除
+func (s *FileManagerService) Delete(token, filePath string) error {
+	if path.Clean(filePath) == "/" {
+		return fmt.Errorf("不能删除根目录")
+	}
+	client, err := s.client(token)
+	if err != nil {
+		return err
+	}
+	info, err := client.Lstat(filePath)
+	if err != nil {
+		return fmt.Errorf("文件不存在: %w", err)
+	}
+	if info.IsDir() {
+		err = client.RemoveAll(filePath)
+	} else {
+		err = client.Remove(filePath)
+	}
+	if err != nil {
+		return fmt.Errorf("删除失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// 网关类型
+const (
+	GatewayTypeInternet = "internet"
+	GatewayTypeNat      = "nat"
+	GatewayTypeService  = "service"
+)
+
+const gatewayWaitTimeout = 2 * time.Minute
+
+// GatewayService 管理 VCN 的互联网网关、NAT 网关与服务网关，以及路由表中指向网关的规则
+type GatewayService struct {
+	ociService *OCIService
+}
+
+func NewGatewayService(ociService *OCIService) *GatewayService {
+	return &GatewayService{ociService: ociService}
+}
+
+// GatewayInfo 网关信息
+type GatewayInfo struct {
+	ID           string   `json:"id"`
+	Type         string   `json:"type"` // internet, nat, service
+	DisplayName  string   `json:"displayName"`
+	VcnID        string   `json:"vcnId"`
+	State        string   `json:"state"`
+	Enabled      bool     `json:"enabled"`            // 互联网网关是否启用，NAT 与服务网关为未阻断流量
+	NatIP        string   `json:"natIp,omitempty"`    // NAT 网关的出口公网IP
+	Services     []string `json:"services,omitempty"` // 服务网关可访问的 Oracle 服务
+	RouteTableID string   `json:"routeTableId,omitempty"`
+	TimeCreated  string   `json:"timeCreated"`
+}
+
+// RouteTableInfo 路由表信息
+type RouteTableInfo struct {
+	ID          string          `json:"id"`
+	DisplayName string          `json:"displayName"`
+	IsDefault   bool            `json:"isDefault"`
+	Rules       []RouteRuleInfo `json:"rules"`
+}
+
+// RouteRuleInfo 路由规则
+type RouteRuleInfo struct {
+	Destination     string `json:"destination"`
+	DestinationType string `json:"destinationType"` // CIDR_BLOCK, SERVICE_CIDR_BLOCK
+	NetworkEntityID string `json:"networkEntityId"`
+	GatewayType     string `json:"gatewayType,omitempty"`
+	Description     string `json:"description,omitempty"`
+}
+
+// GatewayParams 创建网关参数
+type GatewayParams struct {
+	VcnID        string
+	Type         string
+	DisplayName  string // 为空时使用 oci-panel-<类型>-gateway
+	BlockTraffic bool   // NAT 网关创建后先阻断流量
+}
+
+// GatewayTypeOf 根据 OCID 判断网关类型，不是网关时返回空
+func GatewayTypeOf(id string) string {
+	switch {
+	case strings.HasPrefix(id, "ocid1.internetgateway."):
+		return GatewayTypeInternet
+	case strings.HasPrefix(id, "ocid1.natgateway."):
+		return GatewayTypeNat
+	case strings.HasPrefix(id, "ocid1.servicegateway."):
+		return GatewayTypeService
+	}
+	return ""
+}
+
+func formatSDKTime(t *common.SDKTime) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// ListGateways 列出 VCN 所在区间内属于该 VCN 的全部网关
+func (s *GatewayService) ListGateways(userId, vcnId, region string) ([]GatewayInfo, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	vcn, err := client.GetVcn(ctx, core.GetVcnRequest{VcnId: &vcnId})
+	if err != nil {
+		return nil, fmt.Errorf("获取 VCN 失败: %s", extractOCIErrorMessage(err))
+	}
+
+	gateways := []GatewayInfo{}
+	igwResp, err := client.ListInternetGateways(ctx, core.ListInternetGatewaysRequest{CompartmentId: vcn.CompartmentId, VcnId: &vcnId})
+	if err != nil {
+		return nil, fmt.Errorf("获取互联网网关失败: %s", extractOCIErrorMessage(err))
+	}
+	for _, g := range igwResp.Items {
+		gateways = append(gateways, GatewayInfo{
+			ID:           stringValue(g.Id),
+			Type:         GatewayTypeInternet,
+			DisplayName:  stringValue(g.DisplayName),
+			VcnID:        stringValue(g.VcnId),
+			State:        string(g.LifecycleState),
+			Enabled:      g.IsEnabled != nil && *g.IsEnabled,
+			RouteTableID: stringValue(g.RouteTableId),
+			TimeCreated:  formatSDKTime(g.TimeCreated),
+		})
+	}
+
+	natResp, err := client.ListNatGateways(ctx, core.ListNatGatewaysRequest{CompartmentId: vcn.CompartmentId, VcnId: &vcnId})
+	if err != nil {
+		return nil, fmt.Errorf("获取 NAT 网关失败: %s", extractOCIErrorMessage(err))
+	}
+	for _, g := range natResp.Items {
+		gateways = append(gateways, GatewayInfo{
+			ID:           stringValue(g.Id),
+			Type:         GatewayTypeNat,
+			DisplayName:  stringValue(g.DisplayName),
+			VcnID:        stringValue(g.VcnId),
+			State:        string(g.LifecycleState),
+			Enabled:      g.BlockTraffic == nil || !*g.BlockTraffic,
+			NatIP:        stringValue(g.NatIp),
+			RouteTableID: stringValue(g.RouteTableId),
+			TimeCreated:  formatSDKTime(g.TimeCreated),
+		})
+	}
+
+	sgwResp, err := client.ListServiceGateways(ctx, core.ListServiceGatewaysRequest{CompartmentId: vcn.CompartmentId, VcnId: &vcnId})
+	if err != nil {
+		return nil, fmt.Errorf("获取服务网关失败: %s", extractOCIErrorMessage(err))
+	}
+	for _, g := range sgwResp.Items {
+		info := GatewayInfo{
+			ID:           stringValue(g.Id),
+			Type:         GatewayTypeService,
+			DisplayName:  stringValue(g.DisplayName),
+			VcnID:        stringValue(g.VcnId),
+			State:        string(g.LifecycleState),
+			Enabled:      g.BlockTraffic == nil || !*g.BlockTraffic,
+			RouteTableID: stringValue(g.RouteTableId),
+			TimeCreated:  formatSDKTime(g.TimeCreated),
+		}
+		for _, svc := range g.Services {
+			info.Services = append(info.Services, stringValue(svc.ServiceName))
+		}
+		gateways = append(gateways, info)
+	}
+	return gateways, nil
+}
+
+// CreateGateway 在 VCN 中创建网关并等待可用，服务网关默认开放区域内的全部 Oracle 服务
+func (s *GatewayService) CreateGateway(userId, region string, params GatewayParams) (string, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return "", err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	vcn, err := client.GetVcn(ctx, core.GetVcnRequest{VcnId: &params.VcnID})
+	if err != nil {
+		return "", fmt.Errorf("获取 VCN 失败: %s", extractOCIErrorMessage(err))
+	}
+	name := params.DisplayName
+	if name == "" {
+		name = fmt.Sprintf("oci-panel-%s-gateway", params.Type)
+	}
+
+	switch params.Type {
+	case GatewayTypeInternet:
+		return createInternetGateway(ctx, client, *vcn.CompartmentId, params.VcnID, name)
+	case GatewayTypeNat:
+		return createNatGateway(ctx, client, *vcn.CompartmentId, params.VcnID, name, params.BlockTraffic)
+	case GatewayTypeService:
+		service, err := allOracleServices(ctx, client)
+		if err != nil {
+			return "", err
+		}
+		resp, err := client.CreateServiceGateway(ctx, core.CreateServiceGatewayRequest{
+			CreateServiceGatewayDetails: core.CreateServiceGatewayDetails{
+				CompartmentId: vcn.CompartmentId,
+				VcnId:         &params.VcnID,
+				DisplayName:   &name,
+				Services:      []core.ServiceIdRequestDetails{{ServiceId: service.Id}},
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("创建服务网关失败: %s", extractOCIErrorMessage(err))
+		}
+		err = waitGatewayAvailable(func() (string, error) {
+			resp, err := client.GetServiceGateway(ctx, core.GetServiceGatewayRequest{ServiceGatewayId: resp.Id})
+			return string(resp.LifecycleState), err
+		})
+		return stringValue(resp.Id), err
+	}
+	return "", fmt.Errorf("不支持的网关类型: %s", params.Type)
+}
+
+// DeleteGateway 删除网关，先移除 VCN 路由表中指向该网关的规则，否则 OCI 会拒绝删除
+func (s *GatewayService) DeleteGateway(userId, gatewayId, region string) error {
+	gatewayType := GatewayTypeOf(gatewayId)
+	if gatewayType == "" {
+		return fmt.Errorf("不是网关 OCID: %s", gatewayId)
+	}
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	var compartmentId, vcnId *string
+	switch gatewayType {
+	case GatewayTypeInternet:
+		resp, err := client.GetInternetGateway(ctx, core.GetInternetGatewayRequest{IgId: &gatewayId})
+		if err != nil {
+			return fmt.Errorf("获取网关失败: %s", extractOCIErrorMessage(err))
+		}
+		compartmentId, vcnId = resp.CompartmentId, resp.VcnId
+	case GatewayTypeNat:
+		resp, err := client.GetNatGateway(ctx, core.GetNatGatewayRequest{NatGatewayId: &gatewayId})
+		if err != nil {
+			return fmt.Errorf("获取网关失败: %s", extractOCIErrorMessage(err))
+		}
+		compartmentId, vcnId = resp.CompartmentId, resp.VcnId
+	case GatewayTypeService:
+		resp, err := client.GetServiceGateway(ctx, core.GetServiceGatewayRequest{ServiceGatewayId: &gatewayId})
+		if err != nil {
+			return fmt.Errorf("获取网关失败: %s", extractOCIErrorMessage(err))
+		}
+		compartmentId, vcnId = resp.CompartmentId, resp.VcnId
+	}
+
+	tables, err := client.ListRouteTables(ctx, core.ListRouteTablesRequest{CompartmentId: compartmentId, VcnId: vcnId})
+	if err != nil {
+		return fmt.Errorf("获取路由表失败: %s", extractOCIErrorMessage(err))
+	}
+	for _, table := range tables.Items {
+		rules, removed := removeGatewayRoutes(table.RouteRules, gatewayId)
+		if !removed {
+			continue
+		}
+		if _, err := client.UpdateRouteTable(ctx, core.UpdateRouteTableRequest{
+			RtId:                    table.Id,
+			UpdateRouteTableDetails: core.UpdateRouteTableDetails{RouteRules: rules},
+		}); err != nil {
+			return fmt.Errorf("移除路由表 %s 中的规则失败: %s", stringValue(table.DisplayName), extractOCIErrorMessage(err))
+		}
+	}
+
+	switch gatewayType {
+	case GatewayTypeInternet:
+		_, err = client.DeleteInternetGateway(ctx, core.DeleteInternetGatewayRequest{IgId: &gatewayId})
+	case GatewayTypeNat:
+		_, err = client.DeleteNatGateway(ctx, core.DeleteNatGatewayRequest{NatGatewayId: &gatewayId})
+	case GatewayTypeService:
+		_, err = client.DeleteServiceGateway(ctx, core.DeleteServiceGatewayRequest{ServiceGatewayId: &gatewayId})
+	}
+	if err != nil {
+		return fmt.Errorf("删除网关失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// ListRouteTables 列出 VCN 的路由表
+func (s *GatewayService) ListRouteTables(userId, vcnId, region string) ([]RouteTableInfo, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	vcn, err := client.GetVcn(ctx, core.GetVcnRequest{VcnId: &vcnId})
+	if err != nil {
+		return nil, fmt.Errorf("获取 VCN 失败: %s", extractOCIErrorMessage(err))
+	}
+	resp, err := client.ListRouteTables(ctx, core.ListRouteTablesRequest{CompartmentId: vcn.CompartmentId, VcnId: &vcnId})
+	if err != nil {
+		return nil, fmt.Errorf("获取路由表失败: %s", extractOCIErrorMessage(err))
+	}
+
+	tables := make([]RouteTableInfo, 0, len(resp.Items))
+	for _, table := range resp.Items {
+		info := RouteTableInfo{
+			ID:          stringValue(table.Id),
+			DisplayName: stringValue(table.DisplayName),
+			IsDefault:   stringValue(table.Id) == stringValue(vcn.DefaultRouteTableId),
+			Rules:       make([]RouteRuleInfo, 0, len(table.RouteRules)),
+		}
+		for _, rule := range table.RouteRules {
+			info.Rules = append(info.Rules, RouteRuleInfo{
+				Destination:     stringValue(rule.Destination),
+				DestinationType: string(rule.DestinationType),
+				NetworkEntityID: stringValue(rule.NetworkEntityId),
+				GatewayType:     GatewayTypeOf(stringValue(rule.NetworkEntityId)),
+				Description:     stringValue(rule.Description),
+			})
+		}
+		tables = append(tables, info)
+	}
+	return tables, nil
+}
+
+// AttachGatewayRoute 在路由表中添加指向网关的规则，同一目标已有规则时替换为该网关
+// destination 为空时互联网与 NAT 网关使用 0.0.0.0/0，服务网关使用区域内全部 Oracle 服务
+func (s *GatewayService) AttachGatewayRoute(userId, routeTableId, gatewayId, destination, region string) error {
+	gatewayType := GatewayTypeOf(gatewayId)
+	if gatewayType == "" {
+		return fmt.Errorf("不是网关 OCID: %s", gatewayId)
+	}
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	destinationType := core.RouteRuleDestinationTypeCidrBlock
+	if gatewayType == GatewayTypeService {
+		destinationType = core.RouteRuleDestinationTypeServiceCidrBlock
+		if destination == "" {
+			service, err := allOracleServices(ctx, client)
+			if err != nil {
+				return err
+			}
+			destination = stringValue(service.CidrBlock)
+		}
+	} else if destination == "" {
+		destination = "0.0.0.0/0"
+	}
+
+	table, err := client.GetRouteTable(ctx, core.GetRouteTableRequest{RtId: &routeTableId})
+	if err != nil {
+		return fmt.Errorf("获取路由表失败: %s", extractOCIErrorMessage(err))
+	}
+	rules := replaceGatewayRoute(table.RouteRules, destination, destinationType, gatewayId)
+	if _, err := client.UpdateRouteTable(ctx, core.UpdateRouteTableRequest{
+		RtId:                    &routeTableId,
+		UpdateRouteTableDetails: core.UpdateRouteTableDetails{RouteRules: rules},
+	}); err != nil {
+		return fmt.Errorf("更新路由表失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// DetachGatewayRoute 移除路由表中指向网关的全部规则
+func (s *GatewayService) DetachGatewayRoute(userId, routeTableId, gatewayId, region string) error {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	table, err := client.GetRouteTable(ctx, core.GetRouteTableRequest{RtId: &routeTableId})
+	if err != nil {
+		return fmt.Errorf("获取路由表失败: %s", extractOCIErrorMessage(err))
+	}
+	rules, removed := removeGatewayRoutes(table.RouteRules, gatewayId)
+	if !removed {
+		return fmt.Errorf("路由表中没有指向该网关的规则")
+	}
+	if _, err := client.UpdateRouteTable(ctx, core.UpdateRouteTableRequest{
+		RtId:                    &routeTableId,
+		UpdateRouteTableDetails: core.UpdateRouteTableDetails{RouteRules: rules},
+	}); err != nil {
+		return fmt.Errorf("更新路由表失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// replaceGatewayRoute 返回添加了指向网关规则的新规则列表，替换目标相同的原规则
+func replaceGatewayRoute(rules []core.RouteRule, destination string, destinationType core.RouteRuleDestinationTypeEnum, gatewayId string) []core.RouteRule {
+	result := make([]core.RouteRule, 0, len(rules)+1)
+	for _, rule := range rules {
+		if stringValue(rule.Destination) != destination {
+			result = append(result, rule)
+		}
+	}
+	return append(result, core.RouteRule{
+		Destination:     &destination,
+		DestinationType: destinationType,
+		NetworkEntityId: &gatewayId,
+	})
+}
+
+// removeGatewayRoutes 返回去掉指向网关规则后的规则列表，以及是否有规则被移除
+func removeGatewayRoutes(rules []core.RouteRule, gatewayId string) ([]core.RouteRule, bool) {
+	result := make([]core.RouteRule, 0, len(rules))
+	for _, rule := range rules {
+		if stringValue(rule.NetworkEntityId) != gatewayId {
+			result = append(result, rule)
+		}
+	}
+	return result, len(result) != len(rules)
+}
+
+// allOracleServices 返回服务网关可用的 All <region> Services in Oracle Services Network
+func allOracleServices(ctx context.Context, client core.VirtualNetworkClient) (*core.Service, error) {
+	resp, err := client.ListServices(ctx, core.ListServicesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("获取 Oracle 服务列表失败: %s", extractOCIErrorMessage(err))
+	}
+	for i, svc := range resp.Items {
+		if strings.HasPrefix(stringValue(svc.CidrBlock), "all-") {
+			return &resp.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("区域内没有可用的 Oracle 服务")
+}
+
+// createInternetGateway 创建启用的互联网网关并等待可用
+func createInternetGateway(ctx context.Context, client core.VirtualNetworkClient, compartmentId, vcnId, name string) (string, error) {
+	resp, err := client.CreateInternetGateway(ctx, core.CreateInternetGatewayRequest{
+		CreateInternetGatewayDetails: core.CreateInternetGatewayDetails{
+			CompartmentId: &compartmentId,
+			VcnId:         &vcnId,
+			DisplayName:   &name,
+			IsEnabled:     common.Bool(true),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("创建Internet网关失败: %w", err)
+	}
+	err = waitGatewayAvailable(func() (string, error) {
+		resp, err := client.GetInternetGateway(ctx, core.GetInternetGatewayRequest{IgId: resp.Id})
+		return string(resp.LifecycleState), err
+	})
+	return stringValue(resp.Id), err
+}
+
+// createNatGateway 创建 NAT 网关并等待可用
+func createNatGateway(ctx context.Context, client core.VirtualNetworkClient, compartmentId, vcnId, name string, blockTraffic bool) (string, error) {
+	resp, err := client.CreateNatGateway(ctx, core.CreateNatGatewayRequest{
+		CreateNatGatewayDetails: core.CreateNatGatewayDetails{
+			CompartmentId: &compartmentId,
+			VcnId:         &vcnId,
+			DisplayName:   &name,
+			BlockTraffic:  &blockTraffic,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create NAT gateway: %w", err)
+	}
+	err = waitGatewayAvailable(func() (string, error) {
+		resp, err := client.GetNatGateway(ctx, core.GetNatGatewayRequest{NatGatewayId: resp.Id})
+		return string(resp.LifecycleState), err
+	})
+	return stringValue(resp.Id), err
+}
+
+// waitGatewayAvailable 等待网关进入 AVAILABLE 状态
+func waitGatewayAvailable(state func() (string, error)) error {
+	deadline := time.Now().Add(gatewayWaitTimeout)
+	for time.Now().Before(deadline) {
+		current, err := state()
+		if err != nil {
+			return fmt.Errorf("获取网关状态失败: %s", extractOCIErrorMessage(err))
+		}
+		if current == "AVAILABLE" {
+			return nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return fmt.Errorf("等待网关可用超时")
+}
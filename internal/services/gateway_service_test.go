@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func TestGatewayTypeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{"互联网网关", "ocid1.internetgateway.oc1.ap-tokyo-1.a", GatewayTypeInternet},
+		{"NAT 网关", "ocid1.natgateway.oc1.ap-tokyo-1.a", GatewayTypeNat},
+		{"服务网关", "ocid1.servicegateway.oc1.ap-tokyo-1.a", GatewayTypeService},
+		{"动态路由网关", "ocid1.drg.oc1.ap-tokyo-1.a", ""},
+		{"私有IP", "ocid1.privateip.oc1.ap-tokyo-1.a", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GatewayTypeOf(tt.id); got != tt.want {
+				t.Errorf("GatewayTypeOf(%q) = %q, want %q", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func routeRule(destination, entity string) core.RouteRule {
+	return core.RouteRule{
+		Destination:     common.String(destination),
+		DestinationType: core.RouteRuleDestinationTypeCidrBlock,
+		NetworkEntityId: common.String(entity),
+	}
+}
+
+func TestReplaceGatewayRoute(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       []core.RouteRule
+		destination string
+		want        map[string]string
+	}{
+		{"空路由表", nil, "0.0.0.0/0", map[string]string{"0.0.0.0/0": "igw"}},
+		{"替换目标相同的规则", []core.RouteRule{routeRule("0.0.0.0/0", "nat")}, "0.0.0.0/0", map[string]string{"0.0.0.0/0": "igw"}},
+		{"保留其他目标", []core.RouteRule{routeRule("::/0", "igw"), routeRule("10.1.0.0/16", "drg")}, "0.0.0.0/0", map[string]string{"::/0": "igw", "10.1.0.0/16": "drg", "0.0.0.0/0": "igw"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := replaceGatewayRoute(tt.rules, tt.destination, core.RouteRuleDestinationTypeCidrBlock, "igw")
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d rules, want %d", len(got), len(tt.want))
+			}
+			for _, rule := range got {
+				if tt.want[*rule.Destination] != *rule.NetworkEntityId {
+					t.Errorf("rule %s -> %s, want %s", *rule.Destination, *rule.NetworkEntityId, tt.want[*rule.Destination])
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveGatewayRoutes(t *testing.T) {
+	rules := []core.RouteRule{routeRule("0.0.0.0/0", "igw"), routeRule("::/0", "igw"), routeRule("10.1.0.0/16", "drg")}
+	tests := []struct {
+		name        string
+		gatewayId   string
+		wantLen     int
+		wantRemoved bool
+	}{
+		{"移除全部指向网关的规则", "igw", 1, true},
+		{"移除单条规则", "drg", 2, true},
+		{"没有匹配的规则", "nat", 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed := removeGatewayRoutes(rules, tt.gatewayId)
+			if len(got) != tt.wantLen || removed != tt.wantRemoved {
+				t.Errorf("removeGatewayRoutes() = %d rules, %v; want %d, %v", len(got), removed, tt.wantLen, tt.wantRemoved)
+			}
+		})
+	}
+}
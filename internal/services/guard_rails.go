@@ -0,0 +1,158 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+const (
+	SettingGuardMinTaskInterval    = "guard_min_task_interval"
+	SettingGuardMaxPolledTenancies = "guard_max_polled_tenancies"
+	SettingGuardMaxDailyAPICalls   = "guard_max_daily_api_calls"
+
+	MaxGuardMinTaskInterval = 86400
+)
+
+// GuardRails 部署级的硬性限制，无论任务如何设置都会生效，为 0 时不限制
+type GuardRails struct {
+	MinTaskInterval    int   `json:"minTaskInterval"`    // 开机任务的最短执行间隔（秒），低于 10 秒时按 10 秒
+	MaxPolledTenancies int   `json:"maxPolledTenancies"` // 同时进行开机请求的租户数上限
+	MaxDailyAPICalls   int64 `json:"maxDailyApiCalls"`   // 每个配置每天的 OCI API 调用次数上限（按服务器时区计算）
+}
+
+// Validate 校验限制的取值范围
+func (g GuardRails) Validate() error {
+	if g.MinTaskInterval < 0 || g.MinTaskInterval > MaxGuardMinTaskInterval {
+		return fmt.Errorf("最短执行间隔需在 0-%d 秒之间", MaxGuardMinTaskInterval)
+	}
+	if g.MaxPolledTenancies < 0 {
+		return fmt.Errorf("租户数上限不能小于 0")
+	}
+	if g.MaxDailyAPICalls < 0 {
+		return fmt.Errorf("API 调用次数上限不能小于 0")
+	}
+	return nil
+}
+
+// guardRailsValue 当前生效的硬性限制，任务服务启动时从系统设置加载
+var guardRailsValue atomic.Pointer[GuardRails]
+
+// GetGuardRails 获取当前生效的硬性限制
+func GetGuardRails() GuardRails {
+	if rails := guardRailsValue.Load(); rails != nil {
+		return *rails
+	}
+	return GuardRails{}
+}
+
+// loadGuardRails 从系统设置加载硬性限制，无效的值按不限制处理
+func (s *TaskService) loadGuardRails() {
+	var settings []models.SysSetting
+	database.GetDB().Where("key IN ?", []string{SettingGuardMinTaskInterval, SettingGuardMaxPolledTenancies, SettingGuardMaxDailyAPICalls}).
+		Find(&settings)
+	var rails GuardRails
+	for _, setting := range settings {
+		value, err := strconv.ParseInt(setting.Value, 10, 64)
+		if err != nil || value < 0 {
+			log.Printf("Invalid guard rail setting %s=%q, ignored", setting.Key, setting.Value)
+			continue
+		}
+		switch setting.Key {
+		case SettingGuardMinTaskInterval:
+			rails.MinTaskInterval = int(min(value, MaxGuardMinTaskInterval))
+		case SettingGuardMaxPolledTenancies:
+			rails.MaxPolledTenancies = int(value)
+		case SettingGuardMaxDailyAPICalls:
+			rails.MaxDailyAPICalls = value
+		}
+	}
+	guardRailsValue.Store(&rails)
+	s.limiter.setMaxTenants(rails.MaxPolledTenancies)
+}
+
+// SetGuardRails 保存硬性限制并立即生效
+func (s *TaskService) SetGuardRails(rails GuardRails) error {
+	if err := rails.Validate(); err != nil {
+		return err
+	}
+	if err := saveSetting(SettingGuardMinTaskInterval, strconv.Itoa(rails.MinTaskInterval)); err != nil {
+		return err
+	}
+	if err := saveSetting(SettingGuardMaxPolledTenancies, strconv.Itoa(rails.MaxPolledTenancies)); err != nil {
+		return err
+	}
+	if err := saveSetting(SettingGuardMaxDailyAPICalls, strconv.FormatInt(rails.MaxDailyAPICalls, 10)); err != nil {
+		return err
+	}
+	guardRailsValue.Store(&rails)
+	s.limiter.setMaxTenants(rails.MaxPolledTenancies)
+	return nil
+}
+
+// effectiveMinTaskInterval 开机任务实际使用的最短执行间隔（秒）
+func effectiveMinTaskInterval() int {
+	return max(minTaskInterval, GetGuardRails().MinTaskInterval)
+}
+
+// apiCallCounter 按配置统计当天的 OCI API 调用次数，服务重启后重新计数
+type apiCallCounter struct {
+	mu     sync.Mutex
+	day    string
+	counts map[string]int64
+}
+
+var apiCalls = &apiCallCounter{counts: make(map[string]int64)}
+
+// allow 记录一次调用，超过当天上限时返回错误
+func (c *apiCallCounter) allow(configID string, limit int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if today := time.Now().Format("2006-01-02"); today != c.day {
+		c.day = today
+		c.counts = make(map[string]int64)
+	}
+	if limit > 0 && c.counts[configID] >= limit {
+		return fmt.Errorf("该配置今日 OCI API 调用次数已达上限 %d，次日自动恢复", limit)
+	}
+	c.counts[configID]++
+	return nil
+}
+
+// snapshot 返回当天各配置的调用次数
+func (c *apiCallCounter) snapshot() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make(map[string]int64, len(c.counts))
+	if c.day != time.Now().Format("2006-01-02") {
+		return result
+	}
+	for id, count := range c.counts {
+		result[id] = count
+	}
+	return result
+}
+
+// GetDailyAPICalls 获取当天各配置的 OCI API 调用次数
+func GetDailyAPICalls() map[string]int64 {
+	return apiCalls.snapshot()
+}
+
+// guardClient 为 OCI 客户端加上每日调用次数限制，SDK 的每次请求（包括重试）都计入
+func guardClient(user *models.OciUser, client *common.BaseClient) {
+	if user.ID == "" {
+		return
+	}
+	configID := user.ID
+	client.Interceptor = func(*http.Request) error {
+		return apiCalls.allow(configID, GetGuardRails().MaxDailyAPICalls)
+	}
+}
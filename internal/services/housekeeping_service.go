@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	SettingHousekeepingLastRun  = "housekeeping_last_run"
+	SettingTaskLogRetentionDays = "task_log_retention_days"
+	SettingTaskLogMaxRows       = "task_log_max_rows"
+
+	// 自动维护间隔
+	HousekeepingInterval = 24 * time.Hour
+	// 回收站中的任务保留天数，超过后连同日志彻底删除
+	TaskRecycleRetentionDays = 7
+	// Bot 审计日志保留天数
+	HousekeepingAuditRetentionDays = 30
+	// 通知发送记录保留天数与条数上限
+	HousekeepingNotificationRetentionDays = 30
+	HousekeepingNotificationMaxRows       = 2000
+	// 每日流量缓存保留天数
+	HousekeepingTrafficStatRetentionDays = 400
+	// 任务执行日志默认永久保留，需在设置中开启按天数清理，任务可单独设置
+	DefaultTaskLogRetentionDays = 0
+	// 每个任务的执行日志条数默认不限制，需在设置中开启
+	DefaultTaskLogMaxRows = 0
+	// 容量探测记录保留天数
+	HousekeepingCapacityProbeRetentionDays = 90
+	// 访问链接过期后保留天数
+	HousekeepingAccessLinkRetentionDays = 7
+	// OCI 推送事件保留天数
+	HousekeepingOciEventRetentionDays = 30
+	// 已结束的后台操作任务保留天数
+	HousekeepingAsyncJobRetentionDays = 90
+)
+
+// HousekeepingReport 数据库维护结果
+type HousekeepingReport struct {
+	OrphanTaskLogs   int64  `json:"orphanTaskLogs"`
+	RecycledTasks    int64  `json:"recycledTasks"`
+	ExpiredTaskLogs  int64  `json:"expiredTaskLogs"`
+	ExcessTaskLogs   int64  `json:"excessTaskLogs"`
+	AuditLogs        int64  `json:"auditLogs"`
+	NotificationLogs int64  `json:"notificationLogs"`
+	TrafficStats     int64  `json:"trafficStats"`
+	CapacityProbes   int64  `json:"capacityProbes"`
+	TaskLeases       int64  `json:"taskLeases"`
+	JobRuns          int64  `json:"jobRuns"`
+	AccessLinks      int64  `json:"accessLinks"`
+	OciEvents        int64  `json:"ociEvents"`
+	AsyncJobs        int64  `json:"asyncJobs"`
+	SizeBefore       int64  `json:"sizeBefore"`
+	SizeAfter        int64  `json:"sizeAfter"`
+	ReclaimedBytes   int64  `json:"reclaimedBytes"`
+	ReclaimedSpace   string `json:"reclaimedSpace"`
+	DurationMs       int64  `json:"durationMs"`
+	ExecuteTime      string `json:"executeTime"`
+	VacuumSuccessful bool   `json:"vacuumSuccessful"`
+}
+
+type HousekeepingService struct {
+	runMutex sync.Mutex
+}
+
+func NewHousekeepingService() *HousekeepingService {
+	return &HousekeepingService{}
+}
+
+// Jobs 返回由作业框架调度的数据库维护作业，日志保留策略每小时执行，避免高频重试的任务在两次维护之间积累过多日志
+func (s *HousekeepingService) Jobs() []Job {
+	return []Job{
+		&FuncJob{
+			JobName:        "housekeeping",
+			JobDescription: "数据库维护：清理孤立与过期数据后执行 VACUUM",
+			CheckInterval:  time.Hour,
+			Due:            s.isDue,
+			RunFunc: func(ctx context.Context) (string, error) {
+				report, err := s.Run()
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("清理 %d 个任务、%d 条日志，回收 %s", report.RecycledTasks,
+					report.OrphanTaskLogs+report.ExpiredTaskLogs+report.ExcessTaskLogs, report.ReclaimedSpace), nil
+			},
+		},
+		&FuncJob{
+			JobName:        "task_log_retention",
+			JobDescription: "按保留天数与条数上限清理任务执行日志",
+			CheckInterval:  time.Hour,
+			RunFunc: func(ctx context.Context) (string, error) {
+				expired, excess, err := s.enforceTaskLogRetention()
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("删除 %d 条过期日志、%d 条超出上限的日志", expired, excess), nil
+			},
+		},
+	}
+}
+
+// isDue 距上次维护是否已超过维护间隔
+func (s *HousekeepingService) isDue() bool {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingHousekeepingLastRun).First(&setting).Error; err != nil {
+		return true
+	}
+	lastRun, err := time.ParseInLocation("2006-01-02 15:04:05", setting.Value, time.Local)
+	if err != nil {
+		return true
+	}
+	return time.Since(lastRun) >= HousekeepingInterval
+}
+
+// Run 执行一次数据库维护：清理孤立/过期数据后执行 VACUUM 与 ANALYZE
+func (s *HousekeepingService) Run() (*HousekeepingReport, error) {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	start := time.Now()
+	db := database.GetDB()
+	report := &HousekeepingReport{
+		SizeBefore: databaseSize(db),
+	}
+
+	// 回收站中的任务仍保留日志，孤立判断需包含软删除的任务
+	result := db.Where("task_id NOT IN (?)", db.Unscoped().Model(&models.OciCreateTask{}).Select("id")).Delete(&models.TaskLog{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	report.OrphanTaskLogs = result.RowsAffected
+
+	recycled, err := purgeRecycledTasks(db, start.AddDate(0, 0, -TaskRecycleRetentionDays))
+	if err != nil {
+		return nil, err
+	}
+	report.RecycledTasks = recycled
+
+	expiredLogs, err := deleteExpiredTaskLogs(db, start)
+	if err != nil {
+		return nil, err
+	}
+	report.ExpiredTaskLogs = expiredLogs
+
+	excessLogs, err := deleteExcessTaskLogs(db)
+	if err != nil {
+		return nil, err
+	}
+	report.ExcessTaskLogs = excessLogs
+
+	auditCutoff := start.AddDate(0, 0, -HousekeepingAuditRetentionDays)
+	result = db.Where("create_time < ?", auditCutoff).Delete(&models.TelegramAuditLog{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	report.AuditLogs = result.RowsAffected
+
+	notificationLogs, err := trimNotificationLogs(db, start.AddDate(0, 0, -HousekeepingNotificationRetentionDays), HousekeepingNotificationMaxRows)
+	if err != nil {
+		return nil, err
+	}
+	report.NotificationLogs = notificationLogs
+
+	trafficCutoff := start.AddDate(0, 0, -HousekeepingTrafficStatRetentionDays).Format(trafficDayLayout)
+	result = db.Where("day < ? OR config_id NOT IN (?)", trafficCutoff, db.Model(&models.OciUser{}).Select("id")).
+		Delete(&models.TrafficDailyStat{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	report.TrafficStats = result.RowsAffected
+
+	probeCutoff := start.AddDate(0, 0, -HousekeepingCapacityProbeRetentionDays)
+	result = db.Where("create_time < ?", probeCutoff).Delete(&models.CapacityProbe{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	report.CapacityProbes = result.RowsAffected
+
+	result = db.Where("task_id NOT IN (?)", db.Unscoped().Model(&models.OciCreateTask{}).Select("id")).Delete(&models.TaskLease{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	report.TaskLeases = result.RowsAffected
+
+	jobRunCutoff := start.AddDate(0, 0, -HousekeepingJobRunRetentionDays)
+	result = db.Where("start_time < ? AND status <> ?", jobRunCutoff, JobStatusRunning).Delete(&models.JobRun{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	report.JobRuns = result.RowsAffected
+
+	result = db.Where("expire_at < ?", start.AddDate(0, 0, -HousekeepingAccessLinkRetentionDays)).Delete(&models.AccessLink{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	report.AccessLinks = result.RowsAffected
+
+	result = db.Where("receive_time < ?", start.AddDate(0, 0, -HousekeepingOciEventRetentionDays)).Delete(&models.OciEvent{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	report.OciEvents = result.RowsAffected
+
+	asyncJobCutoff := start.AddDate(0, 0, -HousekeepingAsyncJobRetentionDays)
+	result = db.Where("create_time < ? AND status NOT IN ?", asyncJobCutoff, []string{AsyncJobRunning, AsyncJobCancelling}).Delete(&models.AsyncJob{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	report.AsyncJobs = result.RowsAffected
+
+	if err := db.Exec("VACUUM").Error; err != nil {
+		log.Printf("[Housekeeping] VACUUM failed: %v", err)
+	} else {
+		report.VacuumSuccessful = true
+	}
+	if err := db.Exec("ANALYZE").Error; err != nil {
+		log.Printf("[Housekeeping] ANALYZE failed: %v", err)
+	}
+
+	report.SizeAfter = databaseSize(db)
+	if report.SizeBefore > report.SizeAfter {
+		report.ReclaimedBytes = report.SizeBefore - report.SizeAfter
+	}
+	report.ReclaimedSpace = FormatBytes(report.ReclaimedBytes)
+	report.DurationMs = time.Since(start).Milliseconds()
+	report.ExecuteTime = start.Format("2006-01-02 15:04:05")
+
+	s.saveLastRun(report.ExecuteTime)
+
+	log.Printf("[Housekeeping] Removed %d orphan logs, %d recycled tasks, %d expired logs, %d excess logs, %d audit logs, %d notification logs, %d traffic stats, %d capacity probes, reclaimed %s",
+		report.OrphanTaskLogs, report.RecycledTasks, report.ExpiredTaskLogs, report.ExcessTaskLogs, report.AuditLogs, report.NotificationLogs, report.TrafficStats, report.CapacityProbes, report.ReclaimedSpace)
+
+	return report, nil
+}
+
+// enforceTaskLogRetention 按保留策略清理执行日志，不执行 VACUUM
+func (s *HousekeepingService) enforceTaskLogRetention() (int64, int64, error) {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	db := database.GetDB()
+	expired, err := deleteExpiredTaskLogs(db, time.Now())
+	if err != nil {
+		return 0, 0, err
+	}
+	excess, err := deleteExcessTaskLogs(db)
+	if err != nil {
+		return expired, 0, err
+	}
+	return expired, excess, nil
+}
+
+// PurgeTaskLogs 删除所有任务在 olderThanDays 天前的执行日志，为 0 时删除全部日志，随后执行 VACUUM 回收空间
+func (s *HousekeepingService) PurgeTaskLogs(olderThanDays int) (int64, error) {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	db := database.GetDB()
+	// GORM 拒绝无条件的批量删除，删除全部日志时使用恒真条件
+	query := db.Where("1 = 1")
+	if olderThanDays > 0 {
+		query = query.Where("execute_time < ?", time.Now().AddDate(0, 0, -olderThanDays))
+	}
+	result := query.Delete(&models.TaskLog{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if err := db.Exec("VACUUM").Error; err != nil {
+		log.Printf("[Housekeeping] VACUUM after purge failed: %v", err)
+	}
+	log.Printf("[Housekeeping] Purged %d task logs", result.RowsAffected)
+	return result.RowsAffected, nil
+}
+
+func (s *HousekeepingService) saveLastRun(value string) {
+	db := database.GetDB()
+	var setting models.SysSetting
+	if err := db.Where("key = ?", SettingHousekeepingLastRun).First(&setting).Error; err != nil {
+		setting = models.SysSetting{
+			ID:    uuid.New().String(),
+			Key:   SettingHousekeepingLastRun,
+			Value: value,
+		}
+		db.Create(&setting)
+		return
+	}
+	db.Model(&setting).Update("value", value)
+}
+
+// GetTaskLogRetentionDays 获取任务执行日志的全局保留天数，0 表示永久保留
+func GetTaskLogRetentionDays() int {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingTaskLogRetentionDays).First(&setting).Error; err != nil {
+		return DefaultTaskLogRetentionDays
+	}
+	days, err := strconv.Atoi(setting.Value)
+	if err != nil || days < 0 {
+		return DefaultTaskLogRetentionDays
+	}
+	return days
+}
+
+// SetTaskLogRetentionDays 设置任务执行日志的全局保留天数，0 表示永久保留
+func SetTaskLogRetentionDays(days int) error {
+	return saveSetting(SettingTaskLogRetentionDays, strconv.Itoa(days))
+}
+
+// GetTaskLogMaxRows 获取每个任务保留的执行日志条数上限，0 表示不限制
+func GetTaskLogMaxRows() int {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingTaskLogMaxRows).First(&setting).Error; err != nil {
+		return DefaultTaskLogMaxRows
+	}
+	rows, err := strconv.Atoi(setting.Value)
+	if err != nil || rows < 0 {
+		return DefaultTaskLogMaxRows
+	}
+	return rows
+}
+
+// SetTaskLogMaxRows 设置每个任务保留的执行日志条数上限，0 表示不限制
+func SetTaskLogMaxRows(rows int) error {
+	return saveSetting(SettingTaskLogMaxRows, strconv.Itoa(rows))
+}
+
+// deleteExcessTaskLogs 日志条数超过上限的任务只保留最新的记录
+func deleteExcessTaskLogs(db *gorm.DB) (int64, error) {
+	maxRows := GetTaskLogMaxRows()
+	if maxRows <= 0 {
+		return 0, nil
+	}
+
+	var taskIDs []string
+	if err := db.Model(&models.TaskLog{}).Group("task_id").Having("COUNT(*) > ?", maxRows).
+		Pluck("task_id", &taskIDs).Error; err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, taskID := range taskIDs {
+		keep := db.Model(&models.TaskLog{}).Where("task_id = ?", taskID).
+			Order("execute_time DESC").Limit(maxRows).Select("id")
+		result := db.Where("task_id = ? AND id NOT IN (?)", taskID, keep).Delete(&models.TaskLog{})
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+	}
+	return total, nil
+}
+
+// trimNotificationLogs 删除早于 cutoff 的通知记录，剩余记录超过 maxRows 时只保留最新的部分
+func trimNotificationLogs(db *gorm.DB, cutoff time.Time, maxRows int) (int64, error) {
+	result := db.Where("create_time < ?", cutoff).Delete(&models.NotificationLog{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	total := result.RowsAffected
+
+	keep := db.Model(&models.NotificationLog{}).Order("create_time DESC").Limit(maxRows).Select("id")
+	result = db.Where("id NOT IN (?)", keep).Delete(&models.NotificationLog{})
+	if result.Error != nil {
+		return total, result.Error
+	}
+	return total + result.RowsAffected, nil
+}
+
+// deleteExpiredTaskLogs 按任务单独设置或全局设置的保留天数删除过期的执行日志
+func deleteExpiredTaskLogs(db *gorm.DB, now time.Time) (int64, error) {
+	var total int64
+
+	var overrides []int
+	if err := db.Model(&models.OciCreateTask{}).Where("log_retention_days > 0").
+		Distinct().Pluck("log_retention_days", &overrides).Error; err != nil {
+		return 0, err
+	}
+	for _, days := range overrides {
+		result := db.Where("task_id IN (?) AND execute_time < ?",
+			db.Model(&models.OciCreateTask{}).Where("log_retention_days = ?", days).Select("id"),
+			now.AddDate(0, 0, -days)).Delete(&models.TaskLog{})
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+	}
+
+	if days := GetTaskLogRetentionDays(); days > 0 {
+		result := db.Where("task_id IN (?) AND execute_time < ?",
+			db.Model(&models.OciCreateTask{}).Where("log_retention_days = 0").Select("id"),
+			now.AddDate(0, 0, -days)).Delete(&models.TaskLog{})
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+	}
+	return total, nil
+}
+
+// databaseSize 通过 PRAGMA 计算 SQLite 数据库文件大小
+func databaseSize(db *gorm.DB) int64 {
+	var pageCount, pageSize int64
+	db.Raw("PRAGMA page_count").Scan(&pageCount)
+	db.Raw("PRAGMA page_size").Scan(&pageSize)
+	return pageCount * pageSize
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestTrimNotificationLogs(t *testing.T) {
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -HousekeepingNotificationRetentionDays)
+	tests := []struct {
+		name        string
+		ages        []time.Duration // 各条记录距今的时间
+		maxRows     int
+		wantDeleted int64
+		wantKept    []string
+	}{
+		{"删除早于保留天数的记录", []time.Duration{time.Hour, 40 * 24 * time.Hour}, 10, 1, []string{"n0"}},
+		{"超过条数上限时只保留最新的", []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour}, 2, 1, []string{"n0", "n1"}},
+		{"同时按天数与条数清理", []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 31 * 24 * time.Hour}, 2, 2, []string{"n0", "n1"}},
+		{"未超过限制时不删除", []time.Duration{time.Hour}, 10, 0, []string{"n0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			db := database.GetDB()
+			for i, age := range tt.ages {
+				db.Create(&models.NotificationLog{ID: fmt.Sprintf("n%d", i), CreateTime: now.Add(-age)})
+			}
+
+			deleted, err := trimNotificationLogs(db, cutoff, tt.maxRows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var kept []string
+			db.Model(&models.NotificationLog{}).Order("id").Pluck("id", &kept)
+			if deleted != tt.wantDeleted || fmt.Sprint(kept) != fmt.Sprint(tt.wantKept) {
+				t.Errorf("deleted %d, kept %v; want deleted %d, kept %v", deleted, kept, tt.wantDeleted, tt.wantKept)
+			}
+		})
+	}
+}
+
+func TestPurgeRecycledTasks(t *testing.T) {
+	setupTestDB(t)
+	db := database.GetDB()
+	now := time.Now()
+	tasks := []struct {
+		id        string
+		deletedAt time.Time // 为零时未删除
+	}{
+		{"live", time.Time{}},
+		{"recent", now.Add(-24 * time.Hour)},
+		{"expired", now.Add(-10 * 24 * time.Hour)},
+	}
+	for _, task := range tasks {
+		record := models.OciCreateTask{ID: task.id, Status: "stopped"}
+		if !task.deletedAt.IsZero() {
+			record.DeletedAt = gorm.DeletedAt{Time: task.deletedAt, Valid: true}
+		}
+		if err := db.Create(&record).Error; err != nil {
+			t.Fatal(err)
+		}
+		db.Create(&models.TaskLog{ID: "log-" + task.id, TaskID: task.id, ExecuteTime: now})
+	}
+
+	purged, err := purgeRecycledTasks(db, now.Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Errorf("purged = %d, want 1", purged)
+	}
+
+	var remaining []string
+	db.Unscoped().Model(&models.OciCreateTask{}).Order("id").Pluck("id", &remaining)
+	if fmt.Sprint(remaining) != "[live recent]" {
+		t.Errorf("remaining tasks = %v, want [live recent]", remaining)
+	}
+	var logs []string
+	db.Model(&models.TaskLog{}).Order("id").Pluck("id", &logs)
+	if fmt.Sprint(logs) != "[log-live log-recent]" {
+		t.Errorf("remaining logs = %v, want [log-live log-recent]", logs)
+	}
+}
+
+func TestDeleteExpiredTaskLogs(t *testing.T) {
+	setupTestDB(t)
+	db := database.GetDB()
+	now := time.Now()
+	if err := SetTaskLogRetentionDays(30); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&models.OciCreateTask{ID: "global", Status: "running"})
+	db.Create(&models.OciCreateTask{ID: "override", Status: "running", LogRetentionDays: 3})
+
+	logs := []struct {
+		id     string
+		taskID string
+		age    time.Duration
+	}{
+		{"global-new", "global", 10 * 24 * time.Hour},
+		{"global-old", "global", 40 * 24 * time.Hour},
+		{"override-new", "override", 24 * time.Hour},
+		{"override-old", "override", 5 * 24 * time.Hour},
+	}
+	for _, l := range logs {
+		db.Create(&models.TaskLog{ID: l.id, TaskID: l.taskID, ExecuteTime: now.Add(-l.age)})
+	}
+
+	deleted, err := deleteExpiredTaskLogs(db, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	db.Model(&models.TaskLog{}).Order("id").Pluck("id", &kept)
+	if deleted != 2 || fmt.Sprint(kept) != "[global-new override-new]" {
+		t.Errorf("deleted %d, kept %v; want deleted 2, kept [global-new override-new]", deleted, kept)
+	}
+}
+
+func TestDeleteExcessTaskLogs(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxRows     int
+		wantDeleted int64
+		wantKept    string
+	}{
+		{"只保留最新的记录", 2, 2, "[log0 log1]"},
+		{"为 0 时不限制", 0, 0, "[log0 log1 log2 log3]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			db := database.GetDB()
+			if err := SetTaskLogMaxRows(tt.maxRows); err != nil {
+				t.Fatal(err)
+			}
+			now := time.Now()
+			for i := 0; i < 4; i++ {
+				db.Create(&models.TaskLog{ID: fmt.Sprintf("log%d", i), TaskID: "task", ExecuteTime: now.Add(-time.Duration(i) * time.Hour)})
+			}
+
+			deleted, err := deleteExcessTaskLogs(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var kept []string
+			db.Model(&models.TaskLog{}).Order("id").Pluck("id", &kept)
+			if deleted != tt.wantDeleted || fmt.Sprint(kept) != tt.wantKept {
+				t.Errorf("deleted %d, kept %v; want deleted %d, kept %s", deleted, kept, tt.wantDeleted, tt.wantKept)
+			}
+		})
+	}
+}
+
+func TestTaskLogRetentionOptIn(t *testing.T) {
+	setupTestDB(t)
+	db := database.GetDB()
+	if days, rows := GetTaskLogRetentionDays(), GetTaskLogMaxRows(); days != 0 || rows != 0 {
+		t.Fatalf("未设置时 retention = %d, maxRows = %d, want 0, 0", days, rows)
+	}
+
+	now := time.Now()
+	db.Create(&models.OciCreateTask{ID: "global", Status: "running"})
+	db.Create(&models.OciCreateTask{ID: "override", Status: "running", LogRetentionDays: 3})
+	for i := 0; i < 3; i++ {
+		db.Create(&models.TaskLog{ID: fmt.Sprintf("global-%d", i), TaskID: "global", ExecuteTime: now.AddDate(-1, 0, -i)})
+	}
+	db.Create(&models.TaskLog{ID: "override-old", TaskID: "override", ExecuteTime: now.AddDate(0, 0, -5)})
+
+	expired, err := deleteExpiredTaskLogs(db, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	excess, err := deleteExcessTaskLogs(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	db.Model(&models.TaskLog{}).Order("id").Pluck("id", &kept)
+	// 未开启全局清理时只有单独设置了保留天数的任务会被清理
+	if expired != 1 || excess != 0 || fmt.Sprint(kept) != "[global-0 global-1 global-2]" {
+		t.Errorf("expired %d, excess %d, kept %v; want 1, 0, [global-0 global-1 global-2]", expired, excess, kept)
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/computeinstanceagent"
+)
+
+const (
+	KeepAliveModeNotify   = "notify"
+	KeepAliveModeWorkload = "workload"
+
+	// 保活检查的执行间隔
+	keepAliveInterval = 24 * time.Hour
+	// 单个配置的检查超时
+	keepAliveTimeout = 10 * time.Minute
+	// 保活负载在实例内的运行时长（秒），每天运行一次即可使 CPU 95 百分位超过回收阈值
+	keepAliveWorkloadSeconds = 5400
+)
+
+// keepAliveWorkloadScript 以最低优先级占满一个 CPU 核心，不影响实例上的其它服务
+const keepAliveWorkloadScript = `#!/bin/bash
+timeout %d nice -n 19 sh -c 'while :; do :; done' >/dev/null 2>&1 || true
+`
+
+// KeepAliveResult 一次保活检查的结果
+type KeepAliveResult struct {
+	Instances []InstanceUtilization `json:"instances"`
+	Workloads []string              `json:"workloads"` // 已启动保活负载的实例
+	Failed    []string              `json:"failed,omitempty"`
+}
+
+type IdleKeepAliveService struct {
+	ociService      *OCIService
+	telegramService *TelegramService
+	mu              sync.Mutex
+	pending         []models.IdleKeepAlive
+}
+
+func NewIdleKeepAliveService(ociService *OCIService, telegramService *TelegramService) *IdleKeepAliveService {
+	return &IdleKeepAliveService{
+		ociService:      ociService,
+		telegramService: telegramService,
+	}
+}
+
+// Job 返回由作业框架调度的闲置回收保活作业，每小时检查一次，每个配置每天执行一次
+func (s *IdleKeepAliveService) Job() Job {
+	return &FuncJob{
+		JobName:        "idle_keepalive",
+		JobDescription: "检查 Always Free 实例利用率，低于回收阈值时提醒或启动保活负载",
+		CheckInterval:  time.Hour,
+		Due:            s.collectDue,
+		RunFunc:        s.runDue,
+	}
+}
+
+// collectDue 找出超过执行间隔未检查的配置，暂存后由 runDue 执行
+func (s *IdleKeepAliveService) collectDue() bool {
+	var settings []models.IdleKeepAlive
+	cutoff := time.Now().Add(-keepAliveInterval)
+	if err := database.GetDB().Where("enabled = ? AND (last_run_time IS NULL OR last_run_time < ?)", true, cutoff).
+		Find(&settings).Error; err != nil {
+		log.Printf("[KeepAlive] Failed to load settings: %v", err)
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = settings
+	return len(settings) > 0
+}
+
+// runDue 依次检查到期的配置
+func (s *IdleKeepAliveService) runDue(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	due := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	var atRisk, workloads, failed int
+	for i := range due {
+		result, err := s.run(ctx, &due[i])
+		if err != nil {
+			failed++
+			log.Printf("[KeepAlive] Config %s failed: %v", due[i].ConfigID, err)
+			continue
+		}
+		for _, util := range result.Instances {
+			if len(util.LowMetrics) > 0 {
+				atRisk++
+			}
+		}
+		workloads += len(result.Workloads)
+		if len(result.Failed) > 0 {
+			failed++
+		}
+	}
+
+	summary := fmt.Sprintf("检查 %d 个配置：低利用率实例 %d，启动负载 %d，失败 %d", len(due), atRisk, workloads, failed)
+	if failed > 0 {
+		return "", fmt.Errorf("%s", summary)
+	}
+	return summary, nil
+}
+
+// run 检查配置下 Always Free 实例的利用率，有指标低于阈值时推送提醒，workload 模式下为 CPU 过低的实例启动保活负载
+func (s *IdleKeepAliveService) run(ctx context.Context, setting *models.IdleKeepAlive) (*KeepAliveResult, error) {
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", setting.ConfigID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, keepAliveTimeout)
+	defer cancel()
+
+	now := time.Now()
+	list, err := s.ociService.ListAlwaysFreeUtilization(ctx, &user)
+	if err != nil {
+		message := "获取实例失败: " + extractOCIErrorMessage(err)
+		db.Model(&models.IdleKeepAlive{}).Where("id = ?", setting.ID).Updates(map[string]interface{}{
+			"last_run_time": now,
+			"last_message":  message,
+		})
+		return nil, fmt.Errorf("%s", message)
+	}
+
+	tg := s.telegramService
+	result := &KeepAliveResult{Instances: list, Workloads: []string{}}
+	var lines []string
+	for _, util := range list {
+		if util.Error != "" {
+			result.Failed = append(result.Failed, util.InstanceName+": "+util.Error)
+			continue
+		}
+		if len(util.LowMetrics) == 0 {
+			continue
+		}
+		memory := ""
+		if util.HasMemory {
+			memory = tg.t("idle_keepalive_memory", util.MemoryP95)
+		}
+		line := tg.t("idle_keepalive_item", util.InstanceName, util.CPUP95, util.NetworkP95, memory)
+		if setting.Mode == KeepAliveModeWorkload && slices.Contains(util.LowMetrics, IdleMetricCPU) {
+			if err := s.startWorkload(ctx, &user, util.InstanceID); err != nil {
+				result.Failed = append(result.Failed, util.InstanceName+": "+extractOCIErrorMessage(err))
+				line += tg.t("idle_keepalive_start_failed", extractOCIErrorMessage(err))
+			} else {
+				result.Workloads = append(result.Workloads, util.InstanceName)
+				line += tg.t("idle_keepalive_started")
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) > 0 {
+		if err := tg.SendNotification(tg.t("idle_keepalive_title"), tg.t("idle_keepalive_notify", user.Username, strings.Join(lines, "\n"))); err != nil {
+			log.Printf("[KeepAlive] Failed to send notification: %v", err)
+		}
+	}
+
+	message := fmt.Sprintf("检查 %d 个实例，低利用率 %d 个，启动负载 %d 个", len(list), len(lines), len(result.Workloads))
+	if len(result.Failed) > 0 {
+		message += "\n" + strings.Join(result.Failed, "\n")
+	}
+	db.Model(&models.IdleKeepAlive{}).Where("id = ?", setting.ID).Updates(map[string]interface{}{
+		"last_run_time": now,
+		"last_message":  message,
+	})
+	return result, nil
+}
+
+// startWorkload 通过 Cloud Agent 的运行命令插件在实例内启动保活负载，不等待执行结束
+func (s *IdleKeepAliveService) startWorkload(ctx context.Context, user *models.OciUser, instanceID string) error {
+	instance, err := s.ociService.GetInstance(ctx, user, instanceID)
+	if err != nil {
+		return err
+	}
+	client, err := s.ociService.GetComputeInstanceAgentClient(user)
+	if err != nil {
+		return err
+	}
+
+	timeout := keepAliveWorkloadSeconds + 60
+	_, err = client.CreateInstanceAgentCommand(ctx, computeinstanceagent.CreateInstanceAgentCommandRequest{
+		CreateInstanceAgentCommandDetails: computeinstanceagent.CreateInstanceAgentCommandDetails{
+			CompartmentId:             instance.CompartmentId,
+			DisplayName:               common.String("oci-panel-keepalive"),
+			ExecutionTimeOutInSeconds: &timeout,
+			Target: &computeinstanceagent.InstanceAgentCommandTarget{
+				InstanceId: instance.Id,
+			},
+			Content: &computeinstanceagent.InstanceAgentCommandContent{
+				Source: computeinstanceagent.InstanceAgentCommandSourceViaTextDetails{
+					Text: common.String(fmt.Sprintf(keepAliveWorkloadScript, keepAliveWorkloadSeconds)),
+				},
+				Output: computeinstanceagent.InstanceAgentCommandOutputViaTextDetails{},
+			},
+		},
+	})
+	return err
+}
+
+// GetIdleKeepAlive 获取配置的保活设置，未设置时返回 nil
+func GetIdleKeepAlive(configID string) (*models.IdleKeepAlive, error) {
+	var setting models.IdleKeepAlive
+	result := database.GetDB().Where("config_id = ?", configID).Limit(1).Find(&setting)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &setting, nil
+}
+
+// SaveIdleKeepAlive 创建或更新配置的保活设置
+func SaveIdleKeepAlive(configID, mode string, enabled bool) (*models.IdleKeepAlive, error) {
+	if mode != KeepAliveModeNotify && mode != KeepAliveModeWorkload {
+		return nil, fmt.Errorf("不支持的模式: %s", mode)
+	}
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", configID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+
+	setting, err := GetIdleKeepAlive(configID)
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil {
+		setting = &models.IdleKeepAlive{ID: uuid.New().String(), ConfigID: configID}
+	}
+	setting.Mode = mode
+	setting.Enabled = enabled
+	if err := db.Save(setting).Error; err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// DeleteIdleKeepAlive 删除配置的保活设置
+func DeleteIdleKeepAlive(configID string) error {
+	return database.GetDB().Where("config_id = ?", configID).Delete(&models.IdleKeepAlive{}).Error
+}
+
+// RunIdleKeepAlive 立即按配置已保存的设置执行一次保活检查
+func (s *IdleKeepAliveService) RunIdleKeepAlive(configID string) (*KeepAliveResult, error) {
+	setting, err := GetIdleKeepAlive(configID)
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil {
+		return nil, fmt.Errorf("配置未设置闲置保活")
+	}
+	return s.run(context.Background(), setting)
+}
+
+// IdleRiskReports 生成配置的闲置回收风险报告
+func (s *IdleKeepAliveService) IdleRiskReports(ctx context.Context, users []models.OciUser) []*IdleRiskReport {
+	return s.ociService.GetIdleRiskReports(ctx, users)
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	// Oracle 按最近 7 天的利用率判断 Always Free 实例是否闲置
+	idleReclaimWindow = 7 * 24 * time.Hour
+	// CPU 95 百分位、网络与内存（仅 A1）利用率低于该百分比时可能被回收
+	idleReclaimThreshold = 20.0
+	// 监控数据的采样粒度，idleMetricSeconds 为对应的秒数
+	idleMetricInterval = "5m"
+	idleMetricSeconds  = 300
+
+	IdleMetricCPU     = "cpu"
+	IdleMetricNetwork = "network"
+	IdleMetricMemory  = "memory"
+)
+
+// InstanceUtilization 实例最近 7 天的利用率，均为 95 百分位
+type InstanceUtilization struct {
+	InstanceID   string   `json:"instanceId"`
+	InstanceName string   `json:"instanceName"`
+	Shape        string   `json:"shape"`
+	CPUP95       float64  `json:"cpuP95"`
+	NetworkP95   float64  `json:"networkP95"` // 占实例网络带宽的百分比
+	MemoryP95    float64  `json:"memoryP95"`  // 仅 A1 实例统计
+	HasMemory    bool     `json:"hasMemory"`
+	Samples      int      `json:"samples"`    // CPU 数据点数，为 0 表示实例未上报监控数据
+	LowMetrics   []string `json:"lowMetrics"` // 低于回收阈值的指标：cpu / network / memory
+	Error        string   `json:"error,omitempty"`
+}
+
+// isAlwaysFreeShape 判断规格是否属于 Always Free
+func isAlwaysFreeShape(shape string) bool {
+	return shape == alwaysFreeMicroShape || shape == alwaysFreeA1FlexShape
+}
+
+// percentile95 计算数据点的 95 百分位
+func percentile95(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	index := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	return sorted[max(index, 0)]
+}
+
+// instanceBandwidthBps 实例的网络带宽（bit/s），规格未返回带宽时按 Always Free 的默认值估算
+func instanceBandwidthBps(instance *core.Instance) float64 {
+	if instance.ShapeConfig != nil && instance.ShapeConfig.NetworkingBandwidthInGbps != nil && *instance.ShapeConfig.NetworkingBandwidthInGbps > 0 {
+		return float64(*instance.ShapeConfig.NetworkingBandwidthInGbps) * 1e9
+	}
+	if stringValue(instance.Shape) == alwaysFreeMicroShape {
+		return 0.48e9
+	}
+	if instance.ShapeConfig != nil && instance.ShapeConfig.Ocpus != nil {
+		return float64(*instance.ShapeConfig.Ocpus) * 1e9
+	}
+	return 1e9
+}
+
+// GetInstanceUtilization 通过监控服务统计实例最近 7 天的 CPU、网络与内存利用率
+func (s *OCIService) GetInstanceUtilization(ctx context.Context, user *models.OciUser, instance *core.Instance) (*InstanceUtilization, error) {
+	client, err := s.newMonitoringClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceID := stringValue(instance.Id)
+	compartmentID := stringValue(instance.CompartmentId)
+	end := time.Now()
+	start := end.Add(-idleReclaimWindow)
+	util := &InstanceUtilization{
+		InstanceID:   instanceID,
+		InstanceName: stringValue(instance.DisplayName),
+		Shape:        stringValue(instance.Shape),
+		LowMetrics:   []string{},
+	}
+
+	cpu, err := queryInstanceMetric(ctx, client, compartmentID,
+		fmt.Sprintf("CpuUtilization[%s]{resourceId = \"%s\"}.mean()", idleMetricInterval, instanceID), start, end)
+	if err != nil {
+		return nil, err
+	}
+	util.Samples = len(cpu)
+	if util.Samples == 0 {
+		util.Error = "实例未上报监控数据，请确认 Cloud Agent 的监控插件已启用"
+		return util, nil
+	}
+	util.CPUP95 = percentile95(cpu)
+
+	bytesOut, err := queryInstanceMetric(ctx, client, compartmentID,
+		fmt.Sprintf("NetworksBytesOut[%s]{resourceId = \"%s\"}.sum()", idleMetricInterval, instanceID), start, end)
+	if err != nil {
+		return nil, err
+	}
+	bandwidth := instanceBandwidthBps(instance)
+	network := make([]float64, len(bytesOut))
+	for i, bytes := range bytesOut {
+		network[i] = bytes * 8 / idleMetricSeconds / bandwidth * 100
+	}
+	util.NetworkP95 = percentile95(network)
+
+	if util.Shape == alwaysFreeA1FlexShape {
+		memory, err := queryInstanceMetric(ctx, client, compartmentID,
+			fmt.Sprintf("MemoryUtilization[%s]{resourceId = \"%s\"}.mean()", idleMetricInterval, instanceID), start, end)
+		if err != nil {
+			return nil, err
+		}
+		util.HasMemory = len(memory) > 0
+		util.MemoryP95 = percentile95(memory)
+	}
+
+	if util.CPUP95 < idleReclaimThreshold {
+		util.LowMetrics = append(util.LowMetrics, IdleMetricCPU)
+	}
+	if util.NetworkP95 < idleReclaimThreshold {
+		util.LowMetrics = append(util.LowMetrics, IdleMetricNetwork)
+	}
+	if util.HasMemory && util.MemoryP95 < idleReclaimThreshold {
+		util.LowMetrics = append(util.LowMetrics, IdleMetricMemory)
+	}
+	return util, nil
+}
+
+// ListAlwaysFreeUtilization 统计配置下所有运行中的 Always Free 实例的利用率
+func (s *OCIService) ListAlwaysFreeUtilization(ctx context.Context, user *models.OciUser) ([]InstanceUtilization, error) {
+	instances, err := s.ListInstances(ctx, user, user.OciTenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []InstanceUtilization{}
+	for i := range instances {
+		instance := &instances[i]
+		if instance.LifecycleState != core.InstanceLifecycleStateRunning || !isAlwaysFreeShape(stringValue(instance.Shape)) {
+			continue
+		}
+		util, err := s.GetInstanceUtilization(ctx, user, instance)
+		if err != nil {
+			util = &InstanceUtilization{
+				InstanceID:   stringValue(instance.Id),
+				InstanceName: stringValue(instance.DisplayName),
+				Shape:        stringValue(instance.Shape),
+				LowMetrics:   []string{},
+				Error:        "获取监控数据失败: " + extractOCIErrorMessage(err),
+			}
+		}
+		list = append(list, *util)
+	}
+	return list, nil
+}
+
+const (
+	// 生成风险报告的超时，每个实例需要查询三项指标
+	IdleRiskReportTimeout = 2 * time.Minute
+
+	IdleRiskHigh    = "high"
+	IdleRiskMedium  = "medium"
+	IdleRiskLow     = "low"
+	IdleRiskUnknown = "unknown"
+)
+
+// IdleRiskItem 实例的闲置回收风险
+type IdleRiskItem struct {
+	InstanceUtilization
+	RiskScore int    `json:"riskScore"` // 0-100，越高越可能被回收
+	RiskLevel string `json:"riskLevel"` // high / medium / low / unknown
+}
+
+// IdleRiskReport 配置下 Always Free 实例的闲置回收风险报告
+type IdleRiskReport struct {
+	ConfigID  string         `json:"configId"`
+	Username  string         `json:"username"`
+	Region    string         `json:"region"`
+	Instances []IdleRiskItem `json:"instances"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// idleMetricRisk 单个指标的风险：低于阈值为 1，达到阈值两倍为 0，之间线性递减
+func idleMetricRisk(value float64) float64 {
+	return math.Max(0, math.Min(1, (2*idleReclaimThreshold-value)/idleReclaimThreshold))
+}
+
+// idleRiskScore 计算实例的回收风险，Oracle 仅在所有适用指标都偏低时回收，因此取各指标风险的最小值
+func idleRiskScore(util *InstanceUtilization) (int, string) {
+	if util.Error != "" || util.Samples == 0 {
+		return 0, IdleRiskUnknown
+	}
+	risk := math.Min(idleMetricRisk(util.CPUP95), idleMetricRisk(util.NetworkP95))
+	if util.HasMemory {
+		risk = math.Min(risk, idleMetricRisk(util.MemoryP95))
+	}
+	score := int(math.Round(risk * 100))
+	switch {
+	case score >= 80:
+		return score, IdleRiskHigh
+	case score >= 40:
+		return score, IdleRiskMedium
+	default:
+		return score, IdleRiskLow
+	}
+}
+
+// GetIdleRiskReport 按最近 7 天的利用率评估配置下 Always Free 实例的回收风险，风险高的排在前面
+func (s *OCIService) GetIdleRiskReport(ctx context.Context, user *models.OciUser) *IdleRiskReport {
+	report := &IdleRiskReport{
+		ConfigID:  user.ID,
+		Username:  user.Username,
+		Region:    user.OciRegion,
+		Instances: []IdleRiskItem{},
+	}
+	list, err := s.ListAlwaysFreeUtilization(ctx, user)
+	if err != nil {
+		report.Error = "获取实例失败: " + extractOCIErrorMessage(err)
+		return report
+	}
+	for i := range list {
+		item := IdleRiskItem{InstanceUtilization: list[i]}
+		item.RiskScore, item.RiskLevel = idleRiskScore(&list[i])
+		report.Instances = append(report.Instances, item)
+	}
+	sort.SliceStable(report.Instances, func(i, j int) bool {
+		return report.Instances[i].RiskScore > report.Instances[j].RiskScore
+	})
+	return report
+}
+
+// GetIdleRiskReports 并发生成多个配置的回收风险报告，顺序与传入的配置一致
+func (s *OCIService) GetIdleRiskReports(ctx context.Context, users []models.OciUser) []*IdleRiskReport {
+	reports := make([]*IdleRiskReport, len(users))
+	var wg sync.WaitGroup
+	for i := range users {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reports[i] = s.GetIdleRiskReport(ctx, &users[i])
+		}(i)
+	}
+	wg.Wait()
+	return reports
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// compatibleImages 已确认与 Shape 兼容的镜像，避免开机任务每次执行都重复检查
+var compatibleImages sync.Map
+
+// CheckImageCompatibility 校验镜像可用且支持目标 Shape，Flex Shape 同时校验镜像对 OCPU 与内存的限制
+// 平台镜像与自定义镜像都适用，自定义镜像需在创建时或之后添加了该 Shape 的兼容性
+func (s *OCIService) CheckImageCompatibility(ctx context.Context, user *models.OciUser, imageId, shape string, ocpus, memory float64) (*core.Image, error) {
+	client, err := s.GetComputeClient(user)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.GetImage(ctx, core.GetImageRequest{ImageId: &imageId})
+	if err != nil {
+		return nil, fmt.Errorf("获取镜像失败: %s", extractOCIErrorMessage(err))
+	}
+	if resp.LifecycleState != core.ImageLifecycleStateAvailable {
+		return nil, fmt.Errorf("镜像 %s 当前状态为 %s，不可用于开机", stringValue(resp.DisplayName), resp.LifecycleState)
+	}
+
+	req := core.ListImageShapeCompatibilityEntriesRequest{ImageId: &imageId}
+	for {
+		entries, err := client.ListImageShapeCompatibilityEntries(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("获取镜像兼容的 Shape 失败: %s", extractOCIErrorMessage(err))
+		}
+		for _, entry := range entries.Items {
+			if stringValue(entry.Shape) != shape {
+				continue
+			}
+			if IsFlexShape(shape) {
+				if err := checkImageShapeConstraints(entry, ocpus, memory); err != nil {
+					return nil, err
+				}
+			}
+			return &resp.Image, nil
+		}
+		if entries.OpcNextPage == nil {
+			break
+		}
+		req.Page = entries.OpcNextPage
+	}
+	return nil, fmt.Errorf("镜像 %s 不支持 Shape %s", stringValue(resp.DisplayName), shape)
+}
+
+// checkImageShapeConstraints 校验 OCPU 与内存是否在镜像允许的范围内
+func checkImageShapeConstraints(entry core.ImageShapeCompatibilitySummary, ocpus, memory float64) error {
+	if c := entry.OcpuConstraints; c != nil {
+		if (c.Min != nil && ocpus < float64(*c.Min)) || (c.Max != nil && ocpus > float64(*c.Max)) {
+			return fmt.Errorf("镜像要求 OCPU 在 %s 范围内", constraintRange(c.Min, c.Max))
+		}
+	}
+	if c := entry.MemoryConstraints; c != nil {
+		if (c.MinInGBs != nil && memory < float64(*c.MinInGBs)) || (c.MaxInGBs != nil && memory > float64(*c.MaxInGBs)) {
+			return fmt.Errorf("镜像要求内存在 %sGB 范围内", constraintRange(c.MinInGBs, c.MaxInGBs))
+		}
+	}
+	return nil
+}
+
+func constraintRange(minValue, maxValue *int) string {
+	low, high := "-", "-"
+	if minValue != nil {
+		low = fmt.Sprint(*minValue)
+	}
+	if maxValue != nil {
+		high = fmt.Sprint(*maxValue)
+	}
+	return low + "~" + high
+}
+
+// checkImageCompatibilityCached 开机前校验指定的镜像，同一镜像与规格只检查一次
+func (s *OCIService) checkImageCompatibilityCached(ctx context.Context, user *models.OciUser, imageId, shape string, ocpus, memory float64) error {
+	key := fmt.Sprintf("%s|%s|%g|%g", imageId, shape, ocpus, memory)
+	if _, ok := compatibleImages.Load(key); ok {
+		return nil
+	}
+	if _, err := s.CheckImageCompatibility(ctx, user, imageId, shape, ocpus, memory); err != nil {
+		return err
+	}
+	compatibleImages.Store(key, struct{}{})
+	return nil
+}
+
+// ValidateTaskImage 校验开机任务指定的镜像（平台镜像或自定义镜像）与任务的 Shape 兼容
+// 自定义镜像只存在于所在区域，切换到备用区域后无法使用，因此不能与备用区域同时设置
+func (s *TaskService) ValidateTaskImage(user *models.OciUser, region, imageId, architecture string, ocpus, memory float64, fallbackRegions string) error {
+	if imageId == "" {
+		return nil
+	}
+	regionUser := *user
+	regionUser.OciRegion = region
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	image, err := s.ociService.CheckImageCompatibility(ctx, &regionUser, imageId, taskShape(architecture), ocpus, memory)
+	if err != nil {
+		return err
+	}
+	if image.CompartmentId != nil && fallbackRegions != "" {
+		return fmt.Errorf("自定义镜像不能与备用区域同时使用")
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	BatchActionStart     = "start"
+	BatchActionStop      = "stop"
+	BatchActionReboot    = "reboot"
+	BatchActionTerminate = "terminate"
+
+	// 单次批量操作的实例数上限
+	MaxBatchInstances = 100
+	// 同时执行操作的实例数，避免触发 OCI 接口限流
+	batchInstanceWorkers = 5
+)
+
+// batchInstanceActions 批量操作对应的实例操作
+var batchInstanceActions = map[string]string{
+	BatchActionStart:  "START",
+	BatchActionStop:   "STOP",
+	BatchActionReboot: "RESET",
+}
+
+// IsBatchInstanceAction 判断是否为支持的批量操作
+func IsBatchInstanceAction(action string) bool {
+	_, ok := batchInstanceActions[action]
+	return ok || action == BatchActionTerminate
+}
+
+// BatchInstanceTarget 批量操作中的一个实例
+type BatchInstanceTarget struct {
+	UserId     string `json:"userId" binding:"required"`
+	InstanceId string `json:"instanceId" binding:"required"`
+}
+
+// BatchInstanceResult 单个实例的操作结果
+type BatchInstanceResult struct {
+	UserId     string `json:"userId"`
+	InstanceId string `json:"instanceId"`
+	Status     string `json:"status"` // success, failed
+	Error      string `json:"error,omitempty"`
+}
+
+// BatchInstanceReport 批量操作的结果，Results 与请求中实例的顺序一致
+type BatchInstanceReport struct {
+	Action    string                `json:"action"`
+	Total     int                   `json:"total"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []BatchInstanceResult `json:"results"`
+}
+
+// BatchInstanceAction 对多个配置下的实例并发执行启动、停止、重启或终止，返回每个实例的结果
+// 终止操作会跳过开启了终止保护的实例
+func (s *OCIService) BatchInstanceAction(ctx context.Context, action string, targets []BatchInstanceTarget) (*BatchInstanceReport, error) {
+	if !IsBatchInstanceAction(action) {
+		return nil, fmt.Errorf("不支持的操作: %s", action)
+	}
+	seen := make(map[BatchInstanceTarget]bool)
+	var items []BatchInstanceTarget
+	for _, target := range targets {
+		if target.UserId != "" && target.InstanceId != "" && !seen[target] {
+			seen[target] = true
+			items = append(items, target)
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("请选择实例")
+	}
+	if len(items) > MaxBatchInstances {
+		return nil, fmt.Errorf("单次最多操作 %d 个实例", MaxBatchInstances)
+	}
+
+	// 每个配置只查询一次
+	users := make(map[string]*models.OciUser)
+	for _, item := range items {
+		if _, ok := users[item.UserId]; ok {
+			continue
+		}
+		var user models.OciUser
+		if err := database.GetDB().Where("id = ?", item.UserId).First(&user).Error; err != nil {
+			users[item.UserId] = nil
+			continue
+		}
+		users[item.UserId] = &user
+	}
+
+	report := &BatchInstanceReport{Action: action, Total: len(items), Results: make([]BatchInstanceResult, len(items))}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(batchInstanceWorkers, len(items)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				item := items[i]
+				result := BatchInstanceResult{UserId: item.UserId, InstanceId: item.InstanceId, Status: "success"}
+				if err := s.runBatchInstanceAction(ctx, users[item.UserId], action, item.InstanceId); err != nil {
+					result.Status = "failed"
+					result.Error = extractOCIErrorMessage(err)
+				}
+				report.Results[i] = result
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	invalidated := make(map[string]bool)
+	for _, result := range report.Results {
+		if result.Status == "success" {
+			report.Succeeded++
+			if !invalidated[result.UserId] {
+				invalidated[result.UserId] = true
+				InvalidateInstanceSnapshots(result.UserId)
+			}
+		} else {
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+func (s *OCIService) runBatchInstanceAction(ctx context.Context, user *models.OciUser, action, instanceId string) error {
+	if user == nil {
+		return fmt.Errorf("配置不存在")
+	}
+	if action == BatchActionTerminate {
+		if IsInstanceProtected(instanceId) {
+			return fmt.Errorf("实例已开启终止保护，请先关闭保护")
+		}
+		return s.TerminateInstance(ctx, user, instanceId)
+	}
+	return s.InstanceAction(ctx, user, instanceId, batchInstanceActions[action])
+}
+
+// ListRunningInstanceTargets 列出所有配置主区域中运行中的实例，返回实例与无法获取实例的配置名
+func (s *OCIService) ListRunningInstanceTargets(ctx context.Context) ([]BatchInstanceTarget, []string, error) {
+	var users []models.OciUser
+	if err := database.GetDB().Find(&users).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var targets []BatchInstanceTarget
+	var failed []string
+	for i := range users {
+		instances, err := s.ListInstances(ctx, &users[i], users[i].OciTenantID)
+		if err != nil {
+			failed = append(failed, users[i].Username)
+			continue
+		}
+		for _, inst := range instances {
+			if inst.Id != nil && inst.LifecycleState == core.InstanceLifecycleStateRunning {
+				targets = append(targets, BatchInstanceTarget{UserId: users[i].ID, InstanceId: *inst.Id})
+			}
+		}
+	}
+	return targets, failed, nil
+}
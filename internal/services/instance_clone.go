@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// InstanceCloneParams 克隆实例参数
+type InstanceCloneParams struct {
+	InstanceID         string
+	DisplayName        string // 为空时使用 原名称-clone
+	AvailabilityDomain string // 目标可用域，可写完整名称或 AD-1 等后缀，为空时与原实例相同
+	KeepBackup         bool   // 保留克隆过程中创建的引导卷备份
+}
+
+// InstanceCloneResult 克隆结果
+type InstanceCloneResult struct {
+	NewInstanceID      string `json:"newInstanceId"`
+	AvailabilityDomain string `json:"availabilityDomain"`
+	PublicIP           string `json:"publicIp"`
+	BackupID           string `json:"backupId,omitempty"`
+}
+
+// InstanceCloneJob 克隆实例任务
+type InstanceCloneJob struct {
+	ID         string               `json:"id"`
+	InstanceID string               `json:"instanceId"`
+	Status     string               `json:"status"` // 见 AsyncJob 的状态
+	Steps      []AutoRescueProgress `json:"steps"`
+	Result     *InstanceCloneResult `json:"result,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	CreateTime string               `json:"createTime"`
+}
+
+// CloneInstance 备份原实例的引导卷，在目标可用域用备份恢复出新引导卷，
+// 再以相同 Shape、子网与网络安全组创建新实例，原实例保持运行
+func (s *OCIService) CloneInstance(ctx context.Context, user *models.OciUser, params InstanceCloneParams, progressChan chan<- AutoRescueProgress) (*InstanceCloneResult, error) {
+	const totalSteps = 6
+
+	sendProgress := func(step int, status, message string) {
+		if progressChan != nil {
+			progressChan <- AutoRescueProgress{
+				Step:       step,
+				TotalSteps: totalSteps,
+				Status:     status,
+				Message:    message,
+			}
+		}
+	}
+
+	computeClient, err := s.GetComputeClient(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute client: %w", err)
+	}
+	blockClient, err := s.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blockstorage client: %w", err)
+	}
+	vnClient, err := s.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual network client: %w", err)
+	}
+
+	// Step 1: 读取原实例的规格、引导卷与主网卡配置
+	sendProgress(1, "running", "正在读取原实例配置...")
+	instance, err := s.GetInstanceById(user, params.InstanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+	bootVolume, err := s.GetBootVolumeByInstanceId(user, params.InstanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boot volume: %w", err)
+	}
+	primaryVnic, err := s.primaryVnic(ctx, computeClient, vnClient, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	targetAD := stringValue(instance.AvailabilityDomain)
+	if params.AvailabilityDomain != "" {
+		targetAD, err = s.resolveAvailabilityDomain(ctx, user, params.AvailabilityDomain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	subnet, err := vnClient.GetSubnet(ctx, core.GetSubnetRequest{SubnetId: primaryVnic.SubnetId})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subnet: %w", err)
+	}
+	if subnet.AvailabilityDomain != nil && *subnet.AvailabilityDomain != targetAD {
+		return nil, fmt.Errorf("原实例的子网只属于 %s，无法在 %s 克隆", *subnet.AvailabilityDomain, targetAD)
+	}
+
+	displayName := params.DisplayName
+	if displayName == "" {
+		displayName = stringValue(instance.DisplayName) + "-clone"
+	}
+	sendProgress(1, "completed", "读取原实例配置成功")
+
+	// Step 2: 备份引导卷，运行中的实例可直接备份（崩溃一致性）
+	sendProgress(2, "running", "正在备份引导卷...")
+	backupName := fmt.Sprintf("%s-clone-%s", stringValue(instance.DisplayName), time.Now().Format("20060102-150405"))
+	backupResp, err := blockClient.CreateBootVolumeBackup(ctx, core.CreateBootVolumeBackupRequest{
+		CreateBootVolumeBackupDetails: core.CreateBootVolumeBackupDetails{
+			BootVolumeId: bootVolume.Id,
+			DisplayName:  &backupName,
+			Type:         core.CreateBootVolumeBackupDetailsTypeFull,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create boot volume backup: %w", err)
+	}
+	backupID := *backupResp.Id
+	fail := func(err error) (*InstanceCloneResult, error) {
+		if !params.KeepBackup {
+			s.deleteCloneBackup(blockClient, backupID)
+		}
+		return nil, err
+	}
+	if err := waitBootVolumeBackupAvailable(ctx, blockClient, backupID); err != nil {
+		return fail(err)
+	}
+	sendProgress(2, "completed", "备份引导卷成功")
+
+	// Step 3: 在目标可用域从备份恢复引导卷，保持原引导卷的容量与性能
+	sendProgress(3, "running", fmt.Sprintf("正在 %s 恢复引导卷...", targetAD))
+	volumeName := displayName + " (Boot Volume)"
+	volumeID, err := s.restoreBootVolume(ctx, user, core.CreateBootVolumeDetails{
+		AvailabilityDomain: &targetAD,
+		CompartmentId:      instance.CompartmentId,
+		DisplayName:        &volumeName,
+		SizeInGBs:          bootVolume.SizeInGBs,
+		VpusPerGB:          bootVolume.VpusPerGB,
+		SourceDetails:      core.BootVolumeSourceFromBootVolumeBackupDetails{Id: &backupID},
+	})
+	if err != nil {
+		return fail(err)
+	}
+	sendProgress(3, "completed", "恢复引导卷成功")
+
+	// Step 4: 使用恢复的引导卷创建新实例，不带原实例的 cloud-init 脚本以免重复执行
+	sendProgress(4, "running", "正在创建新实例...")
+	metadata := make(map[string]string, len(instance.Metadata))
+	for key, value := range instance.Metadata {
+		if key != "user_data" {
+			metadata[key] = value
+		}
+	}
+	launchDetails := core.LaunchInstanceDetails{
+		CompartmentId:      instance.CompartmentId,
+		AvailabilityDomain: &targetAD,
+		DisplayName:        &displayName,
+		Shape:              instance.Shape,
+		SourceDetails:      core.InstanceSourceViaBootVolumeDetails{BootVolumeId: &volumeID},
+		CreateVnicDetails: &core.CreateVnicDetails{
+			SubnetId:       primaryVnic.SubnetId,
+			AssignPublicIp: common.Bool(primaryVnic.PublicIp != nil),
+			NsgIds:         primaryVnic.NsgIds,
+		},
+		Metadata:     metadata,
+		FreeformTags: instance.FreeformTags,
+		DefinedTags:  instance.DefinedTags,
+	}
+	if instance.ShapeConfig != nil && IsFlexShape(stringValue(instance.Shape)) {
+		launchDetails.ShapeConfig = &core.LaunchInstanceShapeConfigDetails{
+			Ocpus:       instance.ShapeConfig.Ocpus,
+			MemoryInGBs: instance.ShapeConfig.MemoryInGBs,
+		}
+	}
+	launchResp, err := computeClient.LaunchInstance(ctx, core.LaunchInstanceRequest{LaunchInstanceDetails: launchDetails})
+	if err != nil {
+		// 引导卷未被实例使用，一并删除
+		if _, delErr := blockClient.DeleteBootVolume(ctx, core.DeleteBootVolumeRequest{BootVolumeId: &volumeID}); delErr != nil {
+			log.Printf("[Clone] Failed to delete boot volume %s: %v", volumeID, delErr)
+		}
+		return fail(fmt.Errorf("failed to launch new instance: %w", err))
+	}
+	sendProgress(4, "completed", "新实例已提交创建")
+
+	// Step 5: 等待新实例运行
+	sendProgress(5, "running", "正在等待新实例启动...")
+	for i := 0; i < 60; i++ {
+		instResp, err := computeClient.GetInstance(ctx, core.GetInstanceRequest{InstanceId: launchResp.Id})
+		if err == nil && instResp.LifecycleState == core.InstanceLifecycleStateRunning {
+			break
+		}
+		time.Sleep(5 * time.Second)
+	}
+	sendProgress(5, "completed", "新实例已启动")
+
+	// Step 6: 获取公网IP并清理备份
+	sendProgress(6, "running", "正在获取新实例网络信息...")
+	result := &InstanceCloneResult{
+		NewInstanceID:      *launchResp.Id,
+		AvailabilityDomain: targetAD,
+	}
+	newVnics, err := computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+		CompartmentId: instance.CompartmentId,
+		InstanceId:    launchResp.Id,
+	})
+	if err == nil && len(newVnics.Items) > 0 && newVnics.Items[0].VnicId != nil {
+		vnicResp, err := vnClient.GetVnic(ctx, core.GetVnicRequest{VnicId: newVnics.Items[0].VnicId})
+		if err == nil && vnicResp.PublicIp != nil {
+			result.PublicIP = *vnicResp.PublicIp
+		}
+	}
+	if params.KeepBackup {
+		result.BackupID = backupID
+	} else {
+		s.deleteCloneBackup(blockClient, backupID)
+	}
+
+	if progressChan != nil {
+		progressChan <- AutoRescueProgress{
+			Step:       totalSteps,
+			TotalSteps: totalSteps,
+			Status:     "completed",
+			Message:    "实例克隆成功",
+			PublicIP:   result.PublicIP,
+		}
+	}
+
+	return result, nil
+}
+
+// primaryVnic 获取实例的主网卡
+func (s *OCIService) primaryVnic(ctx context.Context, computeClient core.ComputeClient, vnClient core.VirtualNetworkClient, instance *core.Instance) (*core.Vnic, error) {
+	attachments, err := computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+		CompartmentId: instance.CompartmentId,
+		InstanceId:    instance.Id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vnic attachments: %w", err)
+	}
+	for _, attachment := range attachments.Items {
+		if attachment.VnicId == nil || attachment.LifecycleState != core.VnicAttachmentLifecycleStateAttached {
+			continue
+		}
+		vnicResp, err := vnClient.GetVnic(ctx, core.GetVnicRequest{VnicId: attachment.VnicId})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get vnic: %w", err)
+		}
+		if vnicResp.IsPrimary != nil && *vnicResp.IsPrimary {
+			return &vnicResp.Vnic, nil
+		}
+	}
+	return nil, fmt.Errorf("未找到实例的主网卡")
+}
+
+// resolveAvailabilityDomain 将 AD-1 等后缀解析为区域内可用域的完整名称
+func (s *OCIService) resolveAvailabilityDomain(ctx context.Context, user *models.OciUser, name string) (string, error) {
+	identityClient, err := s.GetIdentityClient(user)
+	if err != nil {
+		return "", fmt.Errorf("获取身份客户端失败: %w", err)
+	}
+	resp, err := identityClient.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{
+		CompartmentId: &user.OciTenantID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("获取可用域失败: %w", err)
+	}
+	for _, ad := range resp.Items {
+		if ad.Name != nil && (*ad.Name == name || strings.HasSuffix(*ad.Name, ":"+name)) {
+			return *ad.Name, nil
+		}
+	}
+	return "", fmt.Errorf("可用域不存在: %s", name)
+}
+
+// deleteCloneBackup 删除克隆过程中创建的引导卷备份
+func (s *OCIService) deleteCloneBackup(client core.BlockstorageClient, backupID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.DeleteBootVolumeBackup(ctx, core.DeleteBootVolumeBackupRequest{BootVolumeBackupId: &backupID}); err != nil {
+		log.Printf("[Clone] Failed to delete boot volume backup %s: %v", backupID, err)
+	}
+}
+
+// StartInstanceClone 启动克隆实例任务，返回任务ID，region 为空时使用配置的主区域
+func (s *InstanceService) StartInstanceClone(userId, region string, params InstanceCloneParams) (string, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return "", fmt.Errorf("user not found: %w", err)
+	}
+	if region != "" {
+		user.OciRegion = region
+	}
+
+	job, err := s.asyncJobs.Run(AsyncJobInstanceClone, userId, params.InstanceID, params,
+		func(ctx context.Context, progress chan<- AutoRescueProgress) (interface{}, error) {
+			result, err := s.ociService.CloneInstance(ctx, &user, params, progress)
+			if err == nil {
+				InvalidateInstanceSnapshots(user.ID)
+			}
+			return result, err
+		})
+	if err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// GetInstanceCloneJob 获取克隆实例任务状态
+func (s *InstanceService) GetInstanceCloneJob(jobId string) (*InstanceCloneJob, bool) {
+	job, ok := s.asyncJobs.getKind(jobId, AsyncJobInstanceClone)
+	if !ok {
+		return nil, false
+	}
+	return &InstanceCloneJob{
+		ID:         job.ID,
+		InstanceID: job.ResourceID,
+		Status:     job.Status,
+		Steps:      job.Steps,
+		Result:     asyncJobValue[InstanceCloneResult](job.Result),
+		Error:      job.Error,
+		CreateTime: job.CreateTime.Format("2006-01-02 15:04:05"),
+	}, true
+}
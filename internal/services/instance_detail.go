@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// InstanceDetail 实例的完整信息，一次返回网卡、卷、镜像与标签
+type InstanceDetail struct {
+	ID                 string                            `json:"id"`
+	DisplayName        string                            `json:"displayName"`
+	State              string                            `json:"state"`
+	Shape              string                            `json:"shape"`
+	Ocpus              float32                           `json:"ocpus"`
+	Memory             float32                           `json:"memory"`
+	Region             string                            `json:"region"`
+	CompartmentID      string                            `json:"compartmentId"`
+	AvailabilityDomain string                            `json:"availabilityDomain"`
+	FaultDomain        string                            `json:"faultDomain"`
+	TimeCreated        string                            `json:"timeCreated"`
+	ImageID            string                            `json:"imageId"`
+	ImageName          string                            `json:"imageName"`
+	OperatingSystem    string                            `json:"operatingSystem"` // 镜像的系统与版本，如 Canonical Ubuntu 22.04
+	Protected          bool                              `json:"protected"`
+	SSHPort            int                               `json:"sshPort"`
+	Vnics              []InstanceDetailVnic              `json:"vnics"`
+	BootVolume         *InstanceDetailVolume             `json:"bootVolume"`
+	BlockVolumes       []InstanceDetailVolume            `json:"blockVolumes"`
+	FreeformTags       map[string]string                 `json:"freeformTags"`
+	DefinedTags        map[string]map[string]interface{} `json:"definedTags"`
+	Warnings           []string                          `json:"warnings,omitempty"` // 获取失败的部分，其余信息照常返回
+}
+
+// InstanceDetailVnic 实例网卡及其全部 IP
+type InstanceDetailVnic struct {
+	VnicID        string             `json:"vnicId"`
+	Name          string             `json:"name"`
+	IsPrimary     bool               `json:"isPrimary"`
+	SubnetID      string             `json:"subnetId"`
+	MacAddress    string             `json:"macAddress"`
+	HostnameLabel string             `json:"hostnameLabel"`
+	PrivateIPs    []InstanceDetailIP `json:"privateIps"`
+	IPv6s         []string           `json:"ipv6s"`
+	NsgIDs        []string           `json:"nsgIds"`
+}
+
+// InstanceDetailIP 私有 IP 及其绑定的公网 IP
+type InstanceDetailIP struct {
+	PrivateIP      string `json:"privateIp"`
+	IsPrimary      bool   `json:"isPrimary"`
+	PublicIP       string `json:"publicIp"`
+	PublicLifetime string `json:"publicLifetime"` // EPHEMERAL / RESERVED，未绑定公网 IP 时为空
+}
+
+// InstanceDetailVolume 引导卷或块存储卷
+type InstanceDetailVolume struct {
+	ID             string `json:"id"`
+	DisplayName    string `json:"displayName"`
+	SizeInGBs      int64  `json:"sizeInGBs"`
+	VpusPerGB      int64  `json:"vpusPerGB"`
+	State          string `json:"state"`
+	AttachmentID   string `json:"attachmentId"`
+	AttachmentType string `json:"attachmentType"` // iscsi / paravirtualized 等，引导卷为空
+	Device         string `json:"device"`
+	ReadOnly       bool   `json:"readOnly"`
+}
+
+// GetInstanceDetail 汇总实例的网卡、卷、镜像与标签，region 为空时使用配置的主区域
+func (s *InstanceService) GetInstanceDetail(userId, instanceId, region string) (*InstanceDetail, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if region != "" {
+		user.OciRegion = region
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	return s.ociService.GetInstanceDetail(ctx, &user, instanceId)
+}
+
+// GetInstanceDetail 获取实例的完整信息，网卡、卷与镜像并发获取，部分失败时记录在 Warnings 中
+func (s *OCIService) GetInstanceDetail(ctx context.Context, user *models.OciUser, instanceId string) (*InstanceDetail, error) {
+	instance, err := s.GetInstance(ctx, user, instanceId)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &InstanceDetail{
+		ID:                 stringValue(instance.Id),
+		DisplayName:        stringValue(instance.DisplayName),
+		State:              string(instance.LifecycleState),
+		Shape:              stringValue(instance.Shape),
+		Region:             user.OciRegion,
+		CompartmentID:      stringValue(instance.CompartmentId),
+		AvailabilityDomain: stringValue(instance.AvailabilityDomain),
+		FaultDomain:        stringValue(instance.FaultDomain),
+		Protected:          IsInstanceProtected(instanceId),
+		SSHPort:            GetInstanceSSHPort(instanceId),
+		Vnics:              []InstanceDetailVnic{},
+		BlockVolumes:       []InstanceDetailVolume{},
+		FreeformTags:       instance.FreeformTags,
+		DefinedTags:        instance.DefinedTags,
+	}
+	if instance.TimeCreated != nil {
+		detail.TimeCreated = instance.TimeCreated.Format("2006-01-02 15:04:05")
+	}
+	if instance.ShapeConfig != nil {
+		if instance.ShapeConfig.Ocpus != nil {
+			detail.Ocpus = *instance.ShapeConfig.Ocpus
+		}
+		if instance.ShapeConfig.MemoryInGBs != nil {
+			detail.Memory = *instance.ShapeConfig.MemoryInGBs
+		}
+	}
+	if detail.FreeformTags == nil {
+		detail.FreeformTags = map[string]string{}
+	}
+	if detail.DefinedTags == nil {
+		detail.DefinedTags = map[string]map[string]interface{}{}
+	}
+
+	var mu sync.Mutex
+	warn := func(part string, err error) {
+		mu.Lock()
+		detail.Warnings = append(detail.Warnings, fmt.Sprintf("%s: %s", part, extractOCIErrorMessage(err)))
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		vnics, err := s.instanceDetailVnics(ctx, user, instance)
+		if err != nil {
+			warn("网卡", err)
+			return
+		}
+		detail.Vnics = vnics
+	}()
+	go func() {
+		defer wg.Done()
+		bootVolume, blockVolumes, err := s.instanceDetailVolumes(ctx, user, instance)
+		if err != nil {
+			warn("存储卷", err)
+			return
+		}
+		detail.BootVolume = bootVolume
+		detail.BlockVolumes = blockVolumes
+	}()
+	go func() {
+		defer wg.Done()
+		source, ok := instance.SourceDetails.(core.InstanceSourceViaImageDetails)
+		if !ok || source.ImageId == nil {
+			return
+		}
+		detail.ImageID = *source.ImageId
+		client, err := s.GetComputeClient(user)
+		if err != nil {
+			warn("镜像", err)
+			return
+		}
+		resp, err := client.GetImage(ctx, core.GetImageRequest{ImageId: source.ImageId})
+		if err != nil {
+			warn("镜像", err)
+			return
+		}
+		detail.ImageName = stringValue(resp.DisplayName)
+		detail.OperatingSystem = stringValue(resp.OperatingSystem)
+		if resp.OperatingSystemVersion != nil {
+			detail.OperatingSystem += " " + *resp.OperatingSystemVersion
+		}
+	}()
+	wg.Wait()
+
+	return detail, nil
+}
+
+// instanceDetailVnics 获取实例的全部网卡，包括每张网卡的全部私有 IP、公网 IP 与 IPv6
+func (s *OCIService) instanceDetailVnics(ctx context.Context, user *models.OciUser, instance *core.Instance) ([]InstanceDetailVnic, error) {
+	computeClient, err := s.GetComputeClient(user)
+	if err != nil {
+		return nil, err
+	}
+	vnClient, err := s.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	attachResp, err := computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+		CompartmentId: instance.CompartmentId,
+		InstanceId:    instance.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vnics := []InstanceDetailVnic{}
+	for _, attachment := range attachResp.Items {
+		if attachment.VnicId == nil || attachment.LifecycleState != core.VnicAttachmentLifecycleStateAttached {
+			continue
+		}
+		vnicResp, err := vnClient.GetVnic(ctx, core.GetVnicRequest{VnicId: attachment.VnicId})
+		if err != nil {
+			return nil, err
+		}
+		vnic := InstanceDetailVnic{
+			VnicID:        *attachment.VnicId,
+			Name:          stringValue(vnicResp.DisplayName),
+			IsPrimary:     vnicResp.IsPrimary != nil && *vnicResp.IsPrimary,
+			SubnetID:      stringValue(vnicResp.SubnetId),
+			MacAddress:    stringValue(vnicResp.MacAddress),
+			HostnameLabel: stringValue(vnicResp.HostnameLabel),
+			PrivateIPs:    []InstanceDetailIP{},
+			IPv6s:         []string{},
+			NsgIDs:        vnicResp.NsgIds,
+		}
+		if vnic.NsgIDs == nil {
+			vnic.NsgIDs = []string{}
+		}
+
+		privateResp, err := vnClient.ListPrivateIps(ctx, core.ListPrivateIpsRequest{VnicId: attachment.VnicId})
+		if err != nil {
+			return nil, err
+		}
+		for _, privateIP := range privateResp.Items {
+			ip := InstanceDetailIP{
+				PrivateIP: stringValue(privateIP.IpAddress),
+				IsPrimary: privateIP.IsPrimary != nil && *privateIP.IsPrimary,
+			}
+			publicResp, err := vnClient.GetPublicIpByPrivateIpId(ctx, core.GetPublicIpByPrivateIpIdRequest{
+				GetPublicIpByPrivateIpIdDetails: core.GetPublicIpByPrivateIpIdDetails{PrivateIpId: privateIP.Id},
+			})
+			if err == nil {
+				ip.PublicIP = stringValue(publicResp.IpAddress)
+				ip.PublicLifetime = string(publicResp.Lifetime)
+			} else if serviceErr, ok := common.IsServiceError(err); !ok || serviceErr.GetHTTPStatusCode() != 404 {
+				return nil, err
+			}
+			vnic.PrivateIPs = append(vnic.PrivateIPs, ip)
+		}
+
+		ipv6Resp, err := vnClient.ListIpv6s(ctx, core.ListIpv6sRequest{VnicId: attachment.VnicId})
+		if err != nil {
+			return nil, err
+		}
+		for _, ipv6 := range ipv6Resp.Items {
+			if ipv6.IpAddress != nil {
+				vnic.IPv6s = append(vnic.IPv6s, *ipv6.IpAddress)
+			}
+		}
+		vnics = append(vnics, vnic)
+	}
+	return vnics, nil
+}
+
+// instanceDetailVolumes 获取实例的引导卷与已挂载的块存储卷
+func (s *OCIService) instanceDetailVolumes(ctx context.Context, user *models.OciUser, instance *core.Instance) (*InstanceDetailVolume, []InstanceDetailVolume, error) {
+	computeClient, err := s.GetComputeClient(user)
+	if err != nil {
+		return nil, nil, err
+	}
+	storageClient, err := s.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var bootVolume *InstanceDetailVolume
+	bootResp, err := computeClient.ListBootVolumeAttachments(ctx, core.ListBootVolumeAttachmentsRequest{
+		CompartmentId:      instance.CompartmentId,
+		InstanceId:         instance.Id,
+		AvailabilityDomain: instance.AvailabilityDomain,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, attachment := range bootResp.Items {
+		if attachment.BootVolumeId == nil || attachment.LifecycleState != core.BootVolumeAttachmentLifecycleStateAttached {
+			continue
+		}
+		bvResp, err := storageClient.GetBootVolume(ctx, core.GetBootVolumeRequest{BootVolumeId: attachment.BootVolumeId})
+		if err != nil {
+			return nil, nil, err
+		}
+		bootVolume = &InstanceDetailVolume{
+			ID:           *attachment.BootVolumeId,
+			DisplayName:  stringValue(bvResp.DisplayName),
+			SizeInGBs:    int64Value(bvResp.SizeInGBs),
+			VpusPerGB:    int64Value(bvResp.VpusPerGB),
+			State:        string(bvResp.LifecycleState),
+			AttachmentID: stringValue(attachment.Id),
+		}
+		break
+	}
+
+	blockVolumes := []InstanceDetailVolume{}
+	req := core.ListVolumeAttachmentsRequest{CompartmentId: instance.CompartmentId, InstanceId: instance.Id}
+	for {
+		resp, err := computeClient.ListVolumeAttachments(ctx, req)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, attachment := range resp.Items {
+			if attachment.GetVolumeId() == nil || attachment.GetLifecycleState() != core.VolumeAttachmentLifecycleStateAttached {
+				continue
+			}
+			volResp, err := storageClient.GetVolume(ctx, core.GetVolumeRequest{VolumeId: attachment.GetVolumeId()})
+			if err != nil {
+				return nil, nil, err
+			}
+			blockVolumes = append(blockVolumes, InstanceDetailVolume{
+				ID:             *attachment.GetVolumeId(),
+				DisplayName:    stringValue(volResp.DisplayName),
+				SizeInGBs:      int64Value(volResp.SizeInGBs),
+				VpusPerGB:      int64Value(volResp.VpusPerGB),
+				State:          string(volResp.LifecycleState),
+				AttachmentID:   stringValue(attachment.GetId()),
+				AttachmentType: volumeAttachmentType(attachment),
+				Device:         stringValue(attachment.GetDevice()),
+				ReadOnly:       attachment.GetIsReadOnly() != nil && *attachment.GetIsReadOnly(),
+			})
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return bootVolume, blockVolumes, nil
+}
+
+// volumeAttachmentType 卷挂载方式
+func volumeAttachmentType(attachment core.VolumeAttachment) string {
+	switch attachment.(type) {
+	case core.IScsiVolumeAttachment:
+		return "iscsi"
+	case core.ParavirtualizedVolumeAttachment:
+		return "paravirtualized"
+	case core.EmulatedVolumeAttachment:
+		return "emulated"
+	}
+	return ""
+}
+
+// int64Value 返回整数指针的值，nil 时返回 0
+func int64Value(value *int64) int64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+)
+
+// 实例列表可选的附加数据，每项都需要对每个实例额外调用 OCI 接口，默认不获取
+const (
+	InstanceEnrichBootVolume = "bootVolume" // 引导卷大小
+	InstanceEnrichIPv6       = "ipv6"       // 附加的 IPv6 地址
+	InstanceEnrichImage      = "image"      // 镜像名称
+	InstanceEnrichTraffic    = "traffic"    // 本月流量
+
+	// 同时补充数据的实例数，避免触发 OCI 接口限流
+	instanceEnrichConcurrency = 5
+)
+
+var instanceEnrichments = map[string]bool{
+	InstanceEnrichBootVolume: true,
+	InstanceEnrichIPv6:       true,
+	InstanceEnrichImage:      true,
+	InstanceEnrichTraffic:    true,
+}
+
+// IsInstanceEnrichment 判断是否为支持的实例列表附加数据
+func IsInstanceEnrichment(name string) bool {
+	return instanceEnrichments[name]
+}
+
+// InstanceTraffic 实例本月的 VNIC 流量
+type InstanceTraffic struct {
+	InboundBytes  int64 `json:"inboundBytes"`
+	OutboundBytes int64 `json:"outboundBytes"`
+}
+
+// enrichInstances 按 include 为实例列表补充附加数据，单项获取失败记录在 EnrichErrors 中，不影响其它数据
+func (s *OCIService) enrichInstances(ctx context.Context, user *models.OciUser, instances []core.Instance, result []InstanceInfo, include []string) error {
+	selected := make(map[string]bool, len(include))
+	for _, name := range include {
+		selected[name] = true
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	computeClient, err := s.GetComputeClient(user)
+	if err != nil {
+		return err
+	}
+	var blockClient core.BlockstorageClient
+	if selected[InstanceEnrichBootVolume] {
+		if blockClient, err = s.GetBlockstorageClient(user); err != nil {
+			return err
+		}
+	}
+	var vnClient core.VirtualNetworkClient
+	if selected[InstanceEnrichIPv6] || selected[InstanceEnrichTraffic] {
+		if vnClient, err = s.GetVirtualNetworkClient(user); err != nil {
+			return err
+		}
+	}
+	var monitoringClient monitoring.MonitoringClient
+	if selected[InstanceEnrichTraffic] {
+		if monitoringClient, err = s.newMonitoringClient(user); err != nil {
+			return err
+		}
+	}
+
+	// 多个实例通常使用同一镜像，镜像名称只查询一次
+	var imageMu sync.Mutex
+	imageNames := make(map[string]string)
+	imageName := func(imageId string) (string, error) {
+		imageMu.Lock()
+		defer imageMu.Unlock()
+		if name, ok := imageNames[imageId]; ok {
+			return name, nil
+		}
+		resp, err := computeClient.GetImage(ctx, core.GetImageRequest{ImageId: &imageId})
+		if err != nil {
+			return "", err
+		}
+		imageNames[imageId] = stringValue(resp.DisplayName)
+		return imageNames[imageId], nil
+	}
+
+	now := time.Now()
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	sem := make(chan struct{}, instanceEnrichConcurrency)
+	var wg sync.WaitGroup
+	for i := range instances {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(inst core.Instance, info *InstanceInfo) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fail := func(name string, err error) {
+				if info.EnrichErrors == nil {
+					info.EnrichErrors = make(map[string]string)
+				}
+				info.EnrichErrors[name] = extractOCIErrorMessage(err)
+			}
+
+			if selected[InstanceEnrichBootVolume] {
+				if size, err := instanceBootVolumeSize(ctx, computeClient, blockClient, inst); err != nil {
+					fail(InstanceEnrichBootVolume, err)
+				} else {
+					info.BootVolumeSizeGB = &size
+				}
+			}
+			if selected[InstanceEnrichImage] && inst.ImageId != nil {
+				if name, err := imageName(*inst.ImageId); err != nil {
+					fail(InstanceEnrichImage, err)
+				} else {
+					info.ImageName = name
+				}
+			}
+			if !selected[InstanceEnrichIPv6] && !selected[InstanceEnrichTraffic] {
+				return
+			}
+
+			vnicIds, err := instanceVnicIds(ctx, computeClient, inst)
+			if err != nil {
+				if selected[InstanceEnrichIPv6] {
+					fail(InstanceEnrichIPv6, err)
+				}
+				if selected[InstanceEnrichTraffic] {
+					fail(InstanceEnrichTraffic, err)
+				}
+				return
+			}
+			if selected[InstanceEnrichIPv6] {
+				info.IPv6Addresses = []string{}
+				for _, vnicId := range vnicIds {
+					resp, err := vnClient.ListIpv6s(ctx, core.ListIpv6sRequest{VnicId: common.String(vnicId)})
+					if err != nil {
+						fail(InstanceEnrichIPv6, err)
+						break
+					}
+					for _, ipv6 := range resp.Items {
+						if ipv6.IpAddress != nil {
+							info.IPv6Addresses = append(info.IPv6Addresses, *ipv6.IpAddress)
+						}
+					}
+				}
+			}
+			if selected[InstanceEnrichTraffic] {
+				traffic := &InstanceTraffic{}
+				for _, vnicId := range vnicIds {
+					in, err := sumVnicTraffic(ctx, monitoringClient, stringValue(inst.CompartmentId), "VnicToNetworkBytes", vnicId, startOfMonth, now)
+					if err != nil {
+						fail(InstanceEnrichTraffic, err)
+						traffic = nil
+						break
+					}
+					out, err := sumVnicTraffic(ctx, monitoringClient, stringValue(inst.CompartmentId), "VnicFromNetworkBytes", vnicId, startOfMonth, now)
+					if err != nil {
+						fail(InstanceEnrichTraffic, err)
+						traffic = nil
+						break
+					}
+					traffic.InboundBytes += in
+					traffic.OutboundBytes += out
+				}
+				info.Traffic = traffic
+			}
+		}(instances[i], &result[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// instanceBootVolumeSize 获取实例引导卷大小（GB）
+func instanceBootVolumeSize(ctx context.Context, computeClient core.ComputeClient, blockClient core.BlockstorageClient, inst core.Instance) (int64, error) {
+	resp, err := computeClient.ListBootVolumeAttachments(ctx, core.ListBootVolumeAttachmentsRequest{
+		CompartmentId:      inst.CompartmentId,
+		AvailabilityDomain: inst.AvailabilityDomain,
+		InstanceId:         inst.Id,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, attachment := range resp.Items {
+		if attachment.BootVolumeId == nil || attachment.LifecycleState != core.BootVolumeAttachmentLifecycleStateAttached {
+			continue
+		}
+		volume, err := blockClient.GetBootVolume(ctx, core.GetBootVolumeRequest{BootVolumeId: attachment.BootVolumeId})
+		if err != nil {
+			return 0, err
+		}
+		if volume.SizeInGBs != nil {
+			return *volume.SizeInGBs, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("未找到引导卷")
+}
+
+// instanceVnicIds 获取实例已附加的 VNIC ID
+func instanceVnicIds(ctx context.Context, computeClient core.ComputeClient, inst core.Instance) ([]string, error) {
+	resp, err := computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+		CompartmentId: inst.CompartmentId,
+		InstanceId:    inst.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, attachment := range resp.Items {
+		if attachment.VnicId != nil && attachment.LifecycleState == core.VnicAttachmentLifecycleStateAttached {
+			ids = append(ids, *attachment.VnicId)
+		}
+	}
+	return ids, nil
+}
+
+// sumVnicTraffic 汇总 VNIC 在时间范围内的流量字节数
+func sumVnicTraffic(ctx context.Context, client monitoring.MonitoringClient, compartmentId, metric, vnicId string, start, end time.Time) (int64, error) {
+	resp, err := client.SummarizeMetricsData(ctx, monitoring.SummarizeMetricsDataRequest{
+		CompartmentId: common.String(compartmentId),
+		SummarizeMetricsDataDetails: monitoring.SummarizeMetricsDataDetails{
+			Namespace: common.String("oci_vcn"),
+			Query:     common.String(fmt.Sprintf("%s[1d]{resourceId = \"%s\"}.sum()", metric, vnicId)),
+			StartTime: &common.SDKTime{Time: start},
+			EndTime:   &common.SDKTime{Time: end},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, item := range resp.Items {
+		for _, dp := range item.AggregatedDatapoints {
+			if dp.Value != nil {
+				total += int64(*dp.Value)
+			}
+		}
+	}
+	return total, nil
+}
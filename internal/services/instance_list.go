@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// 实例列表的排序字段
+const (
+	InstanceSortName        = "name"
+	InstanceSortState       = "state"
+	InstanceSortShape       = "shape"
+	InstanceSortTimeCreated = "timeCreated"
+
+	MaxInstancePageSize = 100
+	// 从 OCI 分页获取实例时每页的数量
+	ociInstancePageLimit = 100
+)
+
+// InstanceListQuery 实例列表的分页、过滤与排序参数，Page 为 0 时返回全部实例
+type InstanceListQuery struct {
+	Page      int
+	PageSize  int
+	State     string // 生命周期状态，如 RUNNING，交给 OCI 过滤
+	Shape     string
+	Region    string // 为空时使用配置的主区域
+	Name      string // 名称包含的子串，不区分大小写
+	Tag       string // 实例标签，格式为 key 或 key=value，定义标签可写为 namespace.key
+	SortBy    string // name / state / shape / timeCreated，默认按创建时间
+	SortOrder string // asc / desc，默认 desc
+	Username  string // 该面板用户收藏的实例排在最前
+	Refresh   bool   // 跳过实例列表缓存，直接从 OCI 获取
+}
+
+// Validate 校验参数并补全默认值
+func (q *InstanceListQuery) Validate() error {
+	if q.Page < 0 {
+		return fmt.Errorf("page 不能小于 0")
+	}
+	if q.Page > 0 && (q.PageSize < 1 || q.PageSize > MaxInstancePageSize) {
+		return fmt.Errorf("pageSize 需在 1-%d 之间", MaxInstancePageSize)
+	}
+	if tag := strings.TrimSpace(q.Tag); tag != "" {
+		key, value, hasValue := strings.Cut(tag, "=")
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("标签格式应为 key 或 key=value")
+		}
+		q.Tag = strings.TrimSpace(key)
+		if hasValue {
+			q.Tag += "=" + strings.TrimSpace(value)
+		}
+	}
+	if q.State != "" {
+		state, ok := core.GetMappingInstanceLifecycleStateEnum(q.State)
+		if !ok {
+			return fmt.Errorf("不支持的实例状态: %s", q.State)
+		}
+		q.State = string(state)
+	}
+	switch q.SortBy {
+	case "":
+		q.SortBy = InstanceSortTimeCreated
+	case InstanceSortName, InstanceSortState, InstanceSortShape, InstanceSortTimeCreated:
+	default:
+		return fmt.Errorf("不支持的排序字段: %s", q.SortBy)
+	}
+	switch strings.ToLower(q.SortOrder) {
+	case "":
+		q.SortOrder = "desc"
+	case "asc", "desc":
+		q.SortOrder = strings.ToLower(q.SortOrder)
+	default:
+		return fmt.Errorf("排序方向只能为 asc 或 desc")
+	}
+	return nil
+}
+
+// listAllInstances 从 OCI 分页获取区间内的全部实例，state 不为空时交给 OCI 按状态过滤
+func (s *OCIService) listAllInstances(ctx context.Context, user *models.OciUser, compartmentId, state string) ([]core.Instance, error) {
+	client, err := s.GetComputeClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	req := core.ListInstancesRequest{
+		CompartmentId: &compartmentId,
+		Limit:         common.Int(ociInstancePageLimit),
+	}
+	if state != "" {
+		req.LifecycleState = core.InstanceLifecycleStateEnum(state)
+	}
+
+	var instances []core.Instance
+	for {
+		resp, err := client.ListInstances(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, resp.Items...)
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return instances, nil
+}
+
+// filterInstances 按状态、规格、名称与标签过滤并排序，返回新的切片
+func filterInstances(instances []core.Instance, query InstanceListQuery) []core.Instance {
+	name := strings.ToLower(query.Name)
+	filtered := []core.Instance{}
+	for _, inst := range instances {
+		if query.State != "" && string(inst.LifecycleState) != query.State {
+			continue
+		}
+		if query.Shape != "" && (inst.Shape == nil || *inst.Shape != query.Shape) {
+			continue
+		}
+		if name != "" && (inst.DisplayName == nil || !strings.Contains(strings.ToLower(*inst.DisplayName), name)) {
+			continue
+		}
+		if query.Tag != "" && !instanceMatchesTag(inst, query.Tag) {
+			continue
+		}
+		filtered = append(filtered, inst)
+	}
+
+	sortInstances(filtered, query.SortBy, query.SortOrder == "desc")
+	return filtered
+}
+
+// sortInstances 按字段排序，字段相同时按创建时间排序，保证分页结果稳定
+func sortInstances(instances []core.Instance, sortBy string, desc bool) {
+	key := func(inst core.Instance) string {
+		switch sortBy {
+		case InstanceSortName:
+			return strings.ToLower(common.PointerString(inst.DisplayName))
+		case InstanceSortState:
+			return string(inst.LifecycleState)
+		case InstanceSortShape:
+			return common.PointerString(inst.Shape)
+		}
+		return ""
+	}
+	sort.SliceStable(instances, func(i, j int) bool {
+		a, b := instances[i], instances[j]
+		if ka, kb := key(a), key(b); ka != kb {
+			return (ka < kb) != desc
+		}
+		var ta, tb int64
+		if a.TimeCreated != nil {
+			ta = a.TimeCreated.UnixNano()
+		}
+		if b.TimeCreated != nil {
+			tb = b.TimeCreated.UnixNano()
+		}
+		if ta == tb {
+			return false
+		}
+		return (ta < tb) != desc
+	})
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SettingInstanceListCacheTTL = "instance_list_cache_ttl"
+
+	// 实例列表缓存的默认有效期（秒），为 0 时不缓存
+	DefaultInstanceListCacheTTL = 300
+	MaxInstanceListCacheTTL     = 86400
+
+	// 超过该时间未被读取的快照不再后台刷新并被删除
+	instanceSnapshotIdle = 24 * time.Hour
+)
+
+// GetInstanceListCacheTTL 获取实例列表缓存的有效期（秒）
+func GetInstanceListCacheTTL() int {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingInstanceListCacheTTL).First(&setting).Error; err != nil {
+		return DefaultInstanceListCacheTTL
+	}
+	ttl, err := strconv.Atoi(setting.Value)
+	if err != nil || ttl < 0 || ttl > MaxInstanceListCacheTTL {
+		return DefaultInstanceListCacheTTL
+	}
+	return ttl
+}
+
+// SetInstanceListCacheTTL 设置实例列表缓存的有效期（秒），为 0 时关闭缓存并删除已有快照
+func SetInstanceListCacheTTL(ttl int) error {
+	if ttl < 0 || ttl > MaxInstanceListCacheTTL {
+		return fmt.Errorf("缓存有效期需在 0-%d 秒之间", MaxInstanceListCacheTTL)
+	}
+	if err := saveSetting(SettingInstanceListCacheTTL, strconv.Itoa(ttl)); err != nil {
+		return err
+	}
+	if ttl == 0 {
+		return database.GetDB().Where("1 = 1").Delete(&models.InstanceListSnapshot{}).Error
+	}
+	return nil
+}
+
+// InvalidateInstanceSnapshots 使配置的实例列表快照失效，实例状态变化后调用，下次读取时重新获取
+func InvalidateInstanceSnapshots(configID string) {
+	database.GetDB().Model(&models.InstanceListSnapshot{}).Where("config_id = ?", configID).
+		Update("update_time", time.Time{})
+}
+
+// cachedInstances 读取实例列表快照，快照过期、失效或 refresh 时从 OCI 获取并保存
+// OCI 请求失败时若有旧快照则返回旧快照，避免限流时列表无法打开
+func (s *InstanceService) cachedInstances(ctx context.Context, user *models.OciUser, compartmentId string, ttl int, refresh bool) ([]core.Instance, time.Time, error) {
+	db := database.GetDB()
+	var snapshot models.InstanceListSnapshot
+	found := db.Where("config_id = ? AND region = ? AND compartment_id = ?", user.ID, user.OciRegion, compartmentId).
+		First(&snapshot).Error == nil
+
+	if found && !refresh && time.Since(snapshot.UpdateTime) < time.Duration(ttl)*time.Second {
+		var instances []core.Instance
+		if err := json.Unmarshal([]byte(snapshot.InstancesData), &instances); err == nil {
+			db.Model(&snapshot).Update("access_time", time.Now())
+			return instances, snapshot.UpdateTime, nil
+		}
+	}
+
+	updated, instances, err := s.refreshInstanceSnapshot(ctx, user, compartmentId)
+	if err != nil {
+		if found && !refresh && !snapshot.UpdateTime.IsZero() {
+			var instances []core.Instance
+			if json.Unmarshal([]byte(snapshot.InstancesData), &instances) == nil {
+				log.Printf("List instances for %s failed, using snapshot from %s: %v", user.Username, snapshot.UpdateTime.Format("2006-01-02 15:04:05"), err)
+				return instances, snapshot.UpdateTime, nil
+			}
+		}
+		return nil, time.Time{}, err
+	}
+	return instances, updated.UpdateTime, nil
+}
+
+// refreshInstanceSnapshot 从 OCI 获取实例并保存为快照
+func (s *InstanceService) refreshInstanceSnapshot(ctx context.Context, user *models.OciUser, compartmentId string) (*models.InstanceListSnapshot, []core.Instance, error) {
+	instances, err := s.ociService.listAllInstances(ctx, user, compartmentId, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := json.Marshal(instances)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	snapshot := models.InstanceListSnapshot{
+		ID:            uuid.New().String(),
+		ConfigID:      user.ID,
+		Region:        user.OciRegion,
+		CompartmentID: compartmentId,
+		InstancesData: string(data),
+		InstanceCount: len(instances),
+		AccessTime:    now,
+		UpdateTime:    now,
+	}
+	err = database.GetDB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "config_id"}, {Name: "region"}, {Name: "compartment_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"instances_data", "instance_count", "access_time", "update_time"}),
+	}).Create(&snapshot).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return &snapshot, instances, nil
+}
+
+// RefreshInstanceList 立即从 OCI 刷新实例列表快照，region 为空时使用配置的主区域
+func (s *InstanceService) RefreshInstanceList(userId, compartmentId, region string) (*models.InstanceListSnapshot, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if region != "" {
+		user.OciRegion = region
+	}
+
+	snapshot, _, err := s.refreshInstanceSnapshot(context.Background(), &user, compartmentId)
+	return snapshot, err
+}
+
+// SnapshotJob 后台刷新过期的实例列表快照，使列表页面无需等待 OCI
+func (s *InstanceService) SnapshotJob() Job {
+	return &FuncJob{
+		JobName:        "instance_list_sync",
+		JobDescription: "后台刷新过期的实例列表缓存，删除长期未读取的缓存",
+		CheckInterval:  time.Minute,
+		Due:            s.snapshotsDue,
+		RunFunc:        s.syncSnapshots,
+	}
+}
+
+// snapshotsDue 缓存开启且存在过期或长期未读取的快照时执行
+func (s *InstanceService) snapshotsDue() bool {
+	ttl := GetInstanceListCacheTTL()
+	if ttl == 0 {
+		return false
+	}
+	var count int64
+	database.GetDB().Model(&models.InstanceListSnapshot{}).
+		Where("update_time < ? OR access_time < ?", time.Now().Add(-time.Duration(ttl)*time.Second), time.Now().Add(-instanceSnapshotIdle)).
+		Count(&count)
+	return count > 0
+}
+
+// syncSnapshots 删除长期未读取或配置已删除的快照，并刷新其余过期的快照
+func (s *InstanceService) syncSnapshots(ctx context.Context) (string, error) {
+	db := database.GetDB()
+	removed := db.Where("access_time < ? OR config_id NOT IN (?)", time.Now().Add(-instanceSnapshotIdle), db.Model(&models.OciUser{}).Select("id")).
+		Delete(&models.InstanceListSnapshot{}).RowsAffected
+
+	var snapshots []models.InstanceListSnapshot
+	cutoff := time.Now().Add(-time.Duration(GetInstanceListCacheTTL()) * time.Second)
+	if err := db.Select("id", "config_id", "region", "compartment_id").Where("update_time < ?", cutoff).Find(&snapshots).Error; err != nil {
+		return "", err
+	}
+
+	users := make(map[string]*models.OciUser)
+	refreshed, failed := 0, 0
+	for _, snapshot := range snapshots {
+		if ctx.Err() != nil {
+			break
+		}
+		user, ok := users[snapshot.ConfigID]
+		if !ok {
+			var u models.OciUser
+			if err := db.Where("id = ?", snapshot.ConfigID).First(&u).Error; err == nil {
+				user = &u
+			}
+			users[snapshot.ConfigID] = user
+		}
+		if user == nil {
+			continue
+		}
+		regionUser := *user
+		regionUser.OciRegion = snapshot.Region
+		if _, _, err := s.refreshInstanceSnapshot(ctx, &regionUser, snapshot.CompartmentID); err != nil {
+			log.Printf("Failed to refresh instance snapshot for %s [%s]: %v", user.Username, snapshot.Region, err)
+			failed++
+			continue
+		}
+		refreshed++
+	}
+
+	result := fmt.Sprintf("刷新 %d 个，失败 %d 个，删除 %d 个", refreshed, failed, removed)
+	if failed > 0 && refreshed == 0 {
+		return result, fmt.Errorf("%s", result)
+	}
+	return result, nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+)
+
+const (
+	MetricRange1h  = "1h"
+	MetricRange24h = "24h"
+	MetricRange7d  = "7d"
+)
+
+// metricRange 查询范围与对应的采样粒度，粒度越大返回的数据点越少
+type metricRange struct {
+	Duration time.Duration
+	Interval string
+	Seconds  float64
+}
+
+var metricRanges = map[string]metricRange{
+	MetricRange1h:  {Duration: time.Hour, Interval: "1m", Seconds: 60},
+	MetricRange24h: {Duration: 24 * time.Hour, Interval: "5m", Seconds: 300},
+	MetricRange7d:  {Duration: 7 * 24 * time.Hour, Interval: "1h", Seconds: 3600},
+}
+
+// IsMetricRange 判断是否为支持的指标查询范围，空值使用默认的 1h
+func IsMetricRange(rangeName string) bool {
+	_, ok := metricRanges[rangeName]
+	return ok || rangeName == ""
+}
+
+// instanceMetricDefs 图表展示的实例指标，Rate 为 true 时将每个采样周期的累计字节数换算为每秒速率
+var instanceMetricDefs = []struct {
+	Name   string
+	Metric string
+	Stat   string
+	Unit   string
+	Rate   bool
+}{
+	{"cpu", "CpuUtilization", "mean", "%", false},
+	{"memory", "MemoryUtilization", "mean", "%", false},
+	{"diskRead", "DiskBytesRead", "sum", "B/s", true},
+	{"diskWrite", "DiskBytesWritten", "sum", "B/s", true},
+	{"networkIn", "NetworksBytesIn", "sum", "B/s", true},
+	{"networkOut", "NetworksBytesOut", "sum", "B/s", true},
+}
+
+// MetricPoint 指标数据点
+type MetricPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// MetricSeries 单个指标的时间序列
+type MetricSeries struct {
+	Name   string        `json:"name"` // cpu / memory / diskRead / diskWrite / networkIn / networkOut
+	Unit   string        `json:"unit"`
+	Points []MetricPoint `json:"points"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// InstanceMetrics 实例在查询范围内的监控指标
+type InstanceMetrics struct {
+	InstanceID string         `json:"instanceId"`
+	Range      string         `json:"range"`
+	Interval   string         `json:"interval"`
+	StartTime  time.Time      `json:"startTime"`
+	EndTime    time.Time      `json:"endTime"`
+	Series     []MetricSeries `json:"series"`
+}
+
+// newMonitoringClient 创建配置对应的监控服务客户端
+func (s *OCIService) newMonitoringClient(user *models.OciUser) (monitoring.MonitoringClient, error) {
+	configProvider, err := s.GetConfigProvider(user)
+	if err != nil {
+		return monitoring.MonitoringClient{}, err
+	}
+	client, err := monitoring.NewMonitoringClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return monitoring.MonitoringClient{}, err
+	}
+	guardClient(user, &client.BaseClient)
+	return client, nil
+}
+
+// queryInstanceMetricPoints 查询实例在时间范围内按采样粒度汇总的指标数据点
+func queryInstanceMetricPoints(ctx context.Context, client monitoring.MonitoringClient, compartmentID, query string, start, end time.Time) ([]MetricPoint, error) {
+	resp, err := client.SummarizeMetricsData(ctx, monitoring.SummarizeMetricsDataRequest{
+		CompartmentId: common.String(compartmentID),
+		SummarizeMetricsDataDetails: monitoring.SummarizeMetricsDataDetails{
+			Namespace: common.String("oci_computeagent"),
+			Query:     common.String(query),
+			StartTime: &common.SDKTime{Time: start},
+			EndTime:   &common.SDKTime{Time: end},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	points := []MetricPoint{}
+	for _, item := range resp.Items {
+		for _, dp := range item.AggregatedDatapoints {
+			if dp.Timestamp != nil && dp.Value != nil {
+				points = append(points, MetricPoint{Time: dp.Timestamp.Time, Value: *dp.Value})
+			}
+		}
+	}
+	return points, nil
+}
+
+// queryInstanceMetric 查询实例在时间范围内按采样粒度汇总的指标值
+func queryInstanceMetric(ctx context.Context, client monitoring.MonitoringClient, compartmentID, query string, start, end time.Time) ([]float64, error) {
+	points, err := queryInstanceMetricPoints(ctx, client, compartmentID, query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, len(points))
+	for i, point := range points {
+		values[i] = point.Value
+	}
+	return values, nil
+}
+
+// GetInstanceMetrics 查询实例最近 1h / 24h / 7d 的 CPU、内存、磁盘与网络指标，各指标并发查询
+// 单个指标查询失败时记录在对应序列的 Error 中，不影响其它指标
+func (s *OCIService) GetInstanceMetrics(ctx context.Context, user *models.OciUser, instanceID, rangeName string) (*InstanceMetrics, error) {
+	if rangeName == "" {
+		rangeName = MetricRange1h
+	}
+	r, ok := metricRanges[rangeName]
+	if !ok {
+		return nil, fmt.Errorf("不支持的时间范围: %s", rangeName)
+	}
+
+	instance, err := s.GetInstance(ctx, user, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.newMonitoringClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now()
+	start := end.Add(-r.Duration)
+	result := &InstanceMetrics{
+		InstanceID: instanceID,
+		Range:      rangeName,
+		Interval:   r.Interval,
+		StartTime:  start,
+		EndTime:    end,
+		Series:     make([]MetricSeries, len(instanceMetricDefs)),
+	}
+
+	var wg sync.WaitGroup
+	for i, def := range instanceMetricDefs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			series := MetricSeries{Name: def.Name, Unit: def.Unit, Points: []MetricPoint{}}
+			query := fmt.Sprintf("%s[%s]{resourceId = \"%s\"}.%s()", def.Metric, r.Interval, instanceID, def.Stat)
+			points, err := queryInstanceMetricPoints(ctx, client, stringValue(instance.CompartmentId), query, start, end)
+			if err != nil {
+				series.Error = extractOCIErrorMessage(err)
+			} else {
+				if def.Rate {
+					for j := range points {
+						points[j].Value /= r.Seconds
+					}
+				}
+				series.Points = points
+			}
+			result.Series[i] = series
+		}(i)
+	}
+	wg.Wait()
+	return result, nil
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/limits"
+)
+
+const (
+	PrecheckActionNoChange       = "no_change"
+	PrecheckActionApplyStopped   = "apply_stopped"   // 实例已停止，直接修改，下次启动生效
+	PrecheckActionRebootRequired = "reboot_required" // 实例运行中，需要先停止再修改
+	PrecheckActionRebuild        = "rebuild"         // 跨架构，需要重建实例
+
+	// Always Free 额度
+	alwaysFreeA1Ocpus     = 4
+	alwaysFreeA1MemoryGBs = 24
+	alwaysFreeMicroCount  = 2
+	alwaysFreeMicroShape  = "VM.Standard.E2.1.Micro"
+	alwaysFreeA1FlexShape = "VM.Standard.A1.Flex"
+
+	limitsServiceCompute  = "compute"
+	precheckMemoryEpsilon = 0.001
+)
+
+// shapeLimitNames Flex Shape 对应的租户服务限额名称（核数 / 内存）
+var shapeLimitNames = map[string][2]string{
+	"VM.Standard.A1.Flex": {"standard-a1-core-count", "standard-a1-memory-count"},
+	"VM.Standard.E3.Flex": {"standard-e3-core-ad-count", "standard-e3-memory-count"},
+	"VM.Standard.E4.Flex": {"standard-e4-core-count", "standard-e4-memory-count"},
+	"VM.Standard.E5.Flex": {"standard-e5-core-count", "standard-e5-memory-count"},
+}
+
+// ShapeLimits Shape 的 OCPU / 内存取值范围
+type ShapeLimits struct {
+	MinOcpus         float32 `json:"minOcpus"`
+	MaxOcpus         float32 `json:"maxOcpus"`
+	MinMemoryInGBs   float32 `json:"minMemoryInGBs"`
+	MaxMemoryInGBs   float32 `json:"maxMemoryInGBs"`
+	MinMemoryPerOcpu float32 `json:"minMemoryPerOcpu"`
+	MaxMemoryPerOcpu float32 `json:"maxMemoryPerOcpu"`
+}
+
+// InstanceConfigPrecheck 修改实例配置前的预检查结果
+type InstanceConfigPrecheck struct {
+	Valid         bool         `json:"valid"`
+	Action        string       `json:"action"`
+	Message       string       `json:"message"`
+	CurrentShape  string       `json:"currentShape"`
+	TargetShape   string       `json:"targetShape"`
+	CurrentOcpus  float32      `json:"currentOcpus"`
+	CurrentMemory float32      `json:"currentMemory"`
+	TargetOcpus   float32      `json:"targetOcpus"`
+	TargetMemory  float32      `json:"targetMemory"`
+	ShapeLimits   *ShapeLimits `json:"shapeLimits,omitempty"`
+	Errors        []string     `json:"errors"`
+	Warnings      []string     `json:"warnings"`
+}
+
+// PrecheckInstanceShape 校验 OCPU/内存修改是否满足 Shape 范围、租户限额和 Always Free 额度，
+// 并说明修改方式（无需变更 / 直接生效 / 需要重启 / 需要重建）
+func (s *OCIService) PrecheckInstanceShape(ctx context.Context, user *models.OciUser, instanceId string, shape string, ocpus float32, memoryInGBs float32) (*InstanceConfigPrecheck, error) {
+	instance, err := s.GetInstance(ctx, user, instanceId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	result := &InstanceConfigPrecheck{
+		CurrentShape: *instance.Shape,
+		TargetShape:  *instance.Shape,
+		TargetOcpus:  ocpus,
+		TargetMemory: memoryInGBs,
+		Errors:       []string{},
+		Warnings:     []string{},
+	}
+	if shape != "" {
+		result.TargetShape = shape
+	}
+	if instance.ShapeConfig != nil {
+		if instance.ShapeConfig.Ocpus != nil {
+			result.CurrentOcpus = *instance.ShapeConfig.Ocpus
+		}
+		if instance.ShapeConfig.MemoryInGBs != nil {
+			result.CurrentMemory = *instance.ShapeConfig.MemoryInGBs
+		}
+	}
+
+	if ShapeArchitecture(result.TargetShape) != ShapeArchitecture(result.CurrentShape) {
+		result.Action = PrecheckActionRebuild
+		result.Message = (&CrossArchitectureError{CurrentShape: result.CurrentShape, TargetShape: result.TargetShape}).Error()
+		return result, nil
+	}
+
+	sameShape := result.TargetShape == result.CurrentShape
+	if !IsFlexShape(result.TargetShape) {
+		// 固定规格的 Shape 无法自定义 OCPU/内存
+		result.TargetOcpus = 0
+		result.TargetMemory = 0
+		if sameShape {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s 为固定规格，不支持调整 OCPU 和内存", result.TargetShape))
+		}
+	} else {
+		limitsInfo, err := s.getShapeLimits(ctx, user, instance, result.TargetShape)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("无法获取 Shape 取值范围: %s", extractOCIErrorMessage(err)))
+		} else {
+			result.ShapeLimits = limitsInfo
+			result.Errors = append(result.Errors, validateShapeLimits(limitsInfo, ocpus, memoryInGBs)...)
+		}
+	}
+
+	if sameShape && ocpus == result.CurrentOcpus && abs32(memoryInGBs-result.CurrentMemory) < precheckMemoryEpsilon {
+		result.Valid = len(result.Errors) == 0
+		result.Action = PrecheckActionNoChange
+		result.Message = "配置未变化，无需修改"
+		return result, nil
+	}
+
+	s.checkServiceLimits(ctx, user, instance, result)
+	s.checkAlwaysFree(ctx, user, instance, result)
+
+	result.Valid = len(result.Errors) == 0
+	if instance.LifecycleState == core.InstanceLifecycleStateStopped {
+		result.Action = PrecheckActionApplyStopped
+		result.Message = "实例已停止，修改后在下次启动时生效"
+	} else if instance.LifecycleState == core.InstanceLifecycleStateRunning {
+		result.Action = PrecheckActionRebootRequired
+		result.Message = "实例运行中，修改需先停止实例，可选择修改后自动启动"
+	} else {
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("实例处于 %s 状态，无法修改配置", instance.LifecycleState))
+	}
+
+	return result, nil
+}
+
+// getShapeLimits 获取 Shape 在实例所在可用域的 OCPU/内存取值范围
+func (s *OCIService) getShapeLimits(ctx context.Context, user *models.OciUser, instance *core.Instance, shapeName string) (*ShapeLimits, error) {
+	client, err := s.GetComputeClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	req := core.ListShapesRequest{
+		CompartmentId:      instance.CompartmentId,
+		AvailabilityDomain: instance.AvailabilityDomain,
+	}
+	for {
+		resp, err := client.ListShapes(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range resp.Items {
+			if item.Shape == nil || *item.Shape != shapeName {
+				continue
+			}
+			info := &ShapeLimits{}
+			if item.OcpuOptions != nil {
+				info.MinOcpus = derefFloat32(item.OcpuOptions.Min)
+				info.MaxOcpus = derefFloat32(item.OcpuOptions.Max)
+			}
+			if item.MemoryOptions != nil {
+				info.MinMemoryInGBs = derefFloat32(item.MemoryOptions.MinInGBs)
+				info.MaxMemoryInGBs = derefFloat32(item.MemoryOptions.MaxInGBs)
+				info.MinMemoryPerOcpu = derefFloat32(item.MemoryOptions.MinPerOcpuInGBs)
+				info.MaxMemoryPerOcpu = derefFloat32(item.MemoryOptions.MaxPerOcpuInGBs)
+			}
+			return info, nil
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+
+	return nil, fmt.Errorf("shape %s is not available in %s", shapeName, *instance.AvailabilityDomain)
+}
+
+func validateShapeLimits(info *ShapeLimits, ocpus, memoryInGBs float32) []string {
+	var errs []string
+	if info.MaxOcpus > 0 && (ocpus < info.MinOcpus || ocpus > info.MaxOcpus) {
+		errs = append(errs, fmt.Sprintf("OCPU 需在 %.0f - %.0f 之间", info.MinOcpus, info.MaxOcpus))
+	}
+	if info.MaxMemoryInGBs > 0 && (memoryInGBs < info.MinMemoryInGBs || memoryInGBs > info.MaxMemoryInGBs) {
+		errs = append(errs, fmt.Sprintf("内存需在 %.0fGB - %.0fGB 之间", info.MinMemoryInGBs, info.MaxMemoryInGBs))
+	}
+	if ocpus > 0 && info.MaxMemoryPerOcpu > 0 {
+		perOcpu := memoryInGBs / ocpus
+		if perOcpu < info.MinMemoryPerOcpu-precheckMemoryEpsilon || perOcpu > info.MaxMemoryPerOcpu+precheckMemoryEpsilon {
+			errs = append(errs, fmt.Sprintf("每 OCPU 内存需在 %.0fGB - %.0fGB 之间，当前为 %.1fGB",
+				info.MinMemoryPerOcpu, info.MaxMemoryPerOcpu, perOcpu))
+		}
+	}
+	return errs
+}
+
+// checkServiceLimits 检查租户在该可用域的剩余限额是否足够
+func (s *OCIService) checkServiceLimits(ctx context.Context, user *models.OciUser, instance *core.Instance, result *InstanceConfigPrecheck) {
+	names, ok := shapeLimitNames[result.TargetShape]
+	if !ok {
+		return
+	}
+
+	client, err := s.GetLimitsClient(user)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("无法获取租户限额: %v", err))
+		return
+	}
+
+	// 同 Shape 修改时原实例已占用的额度会被释放
+	extraOcpus := result.TargetOcpus
+	extraMemory := result.TargetMemory
+	if result.TargetShape == result.CurrentShape {
+		extraOcpus -= result.CurrentOcpus
+		extraMemory -= result.CurrentMemory
+	}
+
+	checks := []struct {
+		limitName string
+		required  float32
+		label     string
+	}{
+		{names[0], extraOcpus, "OCPU"},
+		{names[1], extraMemory, "内存(GB)"},
+	}
+	for _, check := range checks {
+		if check.required <= 0 {
+			continue
+		}
+		limitName := check.limitName
+		resp, err := client.GetResourceAvailability(ctx, limits.GetResourceAvailabilityRequest{
+			ServiceName:        stringPtr(limitsServiceCompute),
+			LimitName:          &limitName,
+			CompartmentId:      &user.OciTenantID,
+			AvailabilityDomain: instance.AvailabilityDomain,
+		})
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("无法获取 %s 限额: %s", check.label, extractOCIErrorMessage(err)))
+			continue
+		}
+		var available float32
+		if resp.FractionalAvailability != nil {
+			available = *resp.FractionalAvailability
+		} else if resp.Available != nil {
+			available = float32(*resp.Available)
+		} else {
+			continue
+		}
+		if check.required > available {
+			result.Errors = append(result.Errors, fmt.Sprintf("租户 %s 限额不足：需要增加 %.1f，剩余 %.1f", check.label, check.required, available))
+		}
+	}
+}
+
+// checkAlwaysFree 检查修改后是否超出 Always Free 额度（超出会产生费用，仅提示）
+func (s *OCIService) checkAlwaysFree(ctx context.Context, user *models.OciUser, instance *core.Instance, result *InstanceConfigPrecheck) {
+	if result.TargetShape != alwaysFreeA1FlexShape && result.TargetShape != alwaysFreeMicroShape {
+		if result.CurrentShape == alwaysFreeA1FlexShape || result.CurrentShape == alwaysFreeMicroShape {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s 不属于 Always Free 资源，将按量计费", result.TargetShape))
+		}
+		return
+	}
+
+	instances, err := s.ListInstances(ctx, user, user.OciTenantID)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("无法统计 Always Free 用量: %s", extractOCIErrorMessage(err)))
+		return
+	}
+
+	var a1Ocpus, a1Memory float32
+	microCount := 0
+	for _, inst := range instances {
+		if inst.Id == nil || *inst.Id == *instance.Id || inst.Shape == nil ||
+			inst.LifecycleState == core.InstanceLifecycleStateTerminated ||
+			inst.LifecycleState == core.InstanceLifecycleStateTerminating {
+			continue
+		}
+		switch *inst.Shape {
+		case alwaysFreeA1FlexShape:
+			if inst.ShapeConfig != nil {
+				a1Ocpus += derefFloat32(inst.ShapeConfig.Ocpus)
+				a1Memory += derefFloat32(inst.ShapeConfig.MemoryInGBs)
+			}
+		case alwaysFreeMicroShape:
+			microCount++
+		}
+	}
+
+	if result.TargetShape == alwaysFreeA1FlexShape {
+		totalOcpus := a1Ocpus + result.TargetOcpus
+		totalMemory := a1Memory + result.TargetMemory
+		if totalOcpus > alwaysFreeA1Ocpus || totalMemory > alwaysFreeA1MemoryGBs {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("修改后 A1 总用量为 %.0f OCPU / %.0fGB，超出 Always Free 额度 (%d OCPU / %dGB)",
+				totalOcpus, totalMemory, alwaysFreeA1Ocpus, alwaysFreeA1MemoryGBs))
+		}
+	} else if microCount+1 > alwaysFreeMicroCount {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("E2.1.Micro 实例将达到 %d 台，超出 Always Free 额度 (%d 台)",
+			microCount+1, alwaysFreeMicroCount))
+	}
+}
+
+func derefFloat32(v *float32) float32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
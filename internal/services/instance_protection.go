@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SettingProtectTag = "protect_tag"
+
+	// 默认的终止保护标签，格式为 key=value
+	DefaultProtectTag = "protect=true"
+
+	ProtectionSourceManual = "manual"
+	ProtectionSourceTag    = "tag"
+)
+
+// GetProtectTag 获取触发终止保护的实例标签
+func GetProtectTag() string {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingProtectTag).First(&setting).Error; err != nil || setting.Value == "" {
+		return DefaultProtectTag
+	}
+	return setting.Value
+}
+
+// SetProtectTag 设置触发终止保护的实例标签，格式为 key=value
+func SetProtectTag(tag string) error {
+	tag = strings.TrimSpace(tag)
+	key, value, ok := strings.Cut(tag, "=")
+	if !ok || strings.TrimSpace(key) == "" || strings.TrimSpace(value) == "" {
+		return fmt.Errorf("标签格式应为 key=value")
+	}
+	return saveSetting(SettingProtectTag, strings.TrimSpace(key)+"="+strings.TrimSpace(value))
+}
+
+// instanceHasTag 检查实例的自由格式标签或定义标签中是否包含指定标签（值不区分大小写）
+func instanceHasTag(inst core.Instance, tag string) bool {
+	key, value, ok := strings.Cut(tag, "=")
+	if !ok {
+		return false
+	}
+	if v, exists := inst.FreeformTags[key]; exists && strings.EqualFold(v, value) {
+		return true
+	}
+	for _, tags := range inst.DefinedTags {
+		if v, exists := tags[key]; exists && strings.EqualFold(fmt.Sprint(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// SyncInstanceProtection 根据实例标签同步终止保护，手动开启的保护不受影响
+func SyncInstanceProtection(configID string, instances []core.Instance) error {
+	db := database.GetDB()
+	tag := GetProtectTag()
+
+	var tagged, untagged []string
+	for _, inst := range instances {
+		if inst.Id == nil || inst.LifecycleState == core.InstanceLifecycleStateTerminated {
+			continue
+		}
+		if instanceHasTag(inst, tag) {
+			tagged = append(tagged, *inst.Id)
+		} else {
+			untagged = append(untagged, *inst.Id)
+		}
+	}
+
+	for _, instanceID := range tagged {
+		protection := models.InstanceProtection{InstanceID: instanceID, ConfigID: configID, Source: ProtectionSourceTag}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&protection).Error; err != nil {
+			return err
+		}
+	}
+	if len(untagged) > 0 {
+		return db.Where("instance_id IN ? AND source = ?", untagged, ProtectionSourceTag).
+			Delete(&models.InstanceProtection{}).Error
+	}
+	return nil
+}
+
+// IsInstanceProtected 检查实例是否开启了终止保护
+func IsInstanceProtected(instanceID string) bool {
+	var count int64
+	database.GetDB().Model(&models.InstanceProtection{}).Where("instance_id = ?", instanceID).Count(&count)
+	return count > 0
+}
+
+// SetInstanceProtection 手动开启或关闭实例的终止保护
+func SetInstanceProtection(configID, instanceID string, protected bool) error {
+	db := database.GetDB()
+	if !protected {
+		return db.Where("instance_id = ?", instanceID).Delete(&models.InstanceProtection{}).Error
+	}
+	protection := models.InstanceProtection{InstanceID: instanceID, ConfigID: configID, Source: ProtectionSourceManual}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "instance_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source"}),
+	}).Create(&protection).Error
+}
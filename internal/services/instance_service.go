@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/adiecho/oci-panel/internal/database"
 	"github.com/adiecho/oci-panel/internal/models"
@@ -10,11 +12,36 @@ import (
 )
 
 type InstanceService struct {
-	ociService *OCIService
+	ociService      *OCIService
+	telegramService *TelegramService
+	asyncJobs       *AsyncJobService
+	rescueJobs      map[string]*AutoRescueJob
+	rescueMu        sync.RWMutex
+	// 当前进程中正在执行的系统转换任务，数据库中为 running 但不在此处的任务已被重启中断
+	activeConversions map[string]bool
+	conversionMu      sync.Mutex
 }
 
-func NewInstanceService(ociService *OCIService) *InstanceService {
-	return &InstanceService{ociService: ociService}
+func NewInstanceService(ociService *OCIService, telegramService *TelegramService, asyncJobs *AsyncJobService) *InstanceService {
+	return &InstanceService{
+		ociService:        ociService,
+		telegramService:   telegramService,
+		asyncJobs:         asyncJobs,
+		rescueJobs:        make(map[string]*AutoRescueJob),
+		activeConversions: make(map[string]bool),
+	}
+}
+
+// ShapeRebuildJob 跨架构重建任务
+type ShapeRebuildJob struct {
+	ID          string               `json:"id"`
+	InstanceID  string               `json:"instanceId"`
+	TargetShape string               `json:"targetShape"`
+	Status      string               `json:"status"` // 见 AsyncJob 的状态
+	Steps       []AutoRescueProgress `json:"steps"`
+	Result      *ShapeRebuildResult  `json:"result,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	CreateTime  string               `json:"createTime"`
 }
 
 type InstanceInfo struct {
@@ -26,20 +53,61 @@ type InstanceInfo struct {
 	TimeCreated        string `json:"timeCreated"`
 	PublicIp           string `json:"publicIp"`
 	PrivateIp          string `json:"privateIp"`
+	Favorite           bool   `json:"favorite"` // 当前用户已收藏
+
+	FreeformTags map[string]string                 `json:"freeformTags,omitempty"`
+	DefinedTags  map[string]map[string]interface{} `json:"definedTags,omitempty"`
+
+	// 以下为按需获取的附加数据，仅在请求 include 对应项时返回
+	BootVolumeSizeGB *int64           `json:"bootVolumeSizeGb,omitempty"`
+	IPv6Addresses    []string         `json:"ipv6Addresses,omitempty"`
+	ImageName        string           `json:"imageName,omitempty"`
+	Traffic          *InstanceTraffic `json:"traffic,omitempty"`
+	// 附加数据获取失败的原因，键为 include 中的项
+	EnrichErrors map[string]string `json:"enrichErrors,omitempty"`
 }
 
-func (s *InstanceService) ListInstances(userId string, compartmentId string) ([]InstanceInfo, error) {
+// InstanceListResult 实例列表结果，Total 为过滤后的实例总数
+type InstanceListResult struct {
+	List       []InstanceInfo
+	Total      int
+	UpdateTime time.Time // 来自缓存时为快照的更新时间，实时获取时为零值
+}
+
+// ListInstances 获取实例列表，include 指定需要额外获取的附加数据（bootVolume / ipv6 / image / traffic）
+// 开启缓存时优先读取实例列表快照，收藏的实例排在最前，query.Page 大于 0 时只返回该页实例，附加数据也只为该页获取
+func (s *InstanceService) ListInstances(userId string, compartmentId string, include []string, query InstanceListQuery) (*InstanceListResult, error) {
 	var user models.OciUser
 	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
+	if query.Region != "" {
+		user.OciRegion = query.Region
+	}
 
-	instances, err := s.ociService.ListInstances(context.Background(), &user, compartmentId)
+	ctx := context.Background()
+	var instances []core.Instance
+	var updateTime time.Time
+	var err error
+	if ttl := GetInstanceListCacheTTL(); ttl > 0 {
+		instances, updateTime, err = s.cachedInstances(ctx, &user, compartmentId, ttl, query.Refresh)
+	} else {
+		instances, err = s.ociService.listAllInstances(ctx, &user, compartmentId, query.State)
+	}
 	if err != nil {
 		return nil, err
 	}
+	instances = filterInstances(instances, query)
+
+	favorites := favoriteIDs(query.Username, FavoriteTypeInstance)
+	sortFavoritesFirst(instances, func(inst core.Instance) bool { return favorites[*inst.Id] })
+	total := len(instances)
+	if query.Page > 0 {
+		start := min((query.Page-1)*query.PageSize, total)
+		instances = instances[start:min(start+query.PageSize, total)]
+	}
 
-	var result []InstanceInfo
+	result := []InstanceInfo{}
 	for _, inst := range instances {
 		info := InstanceInfo{
 			ID:                 *inst.Id,
@@ -48,11 +116,17 @@ func (s *InstanceService) ListInstances(userId string, compartmentId string) ([]
 			AvailabilityDomain: *inst.AvailabilityDomain,
 			Shape:              *inst.Shape,
 			TimeCreated:        inst.TimeCreated.String(),
+			Favorite:           favorites[*inst.Id],
+			FreeformTags:       inst.FreeformTags,
+			DefinedTags:        inst.DefinedTags,
 		}
 		result = append(result, info)
 	}
 
-	return result, nil
+	if err := s.ociService.enrichInstances(ctx, &user, instances, result, include); err != nil {
+		return nil, err
+	}
+	return &InstanceListResult{List: result, Total: total, UpdateTime: updateTime}, nil
 }
 
 func (s *InstanceService) StartInstance(userId string, instanceId string) error {
@@ -61,7 +135,11 @@ func (s *InstanceService) StartInstance(userId string, instanceId string) error
 		return fmt.Errorf("user not found: %w", err)
 	}
 
-	return s.ociService.InstanceAction(context.Background(), &user, instanceId, "START")
+	if err := s.ociService.InstanceAction(context.Background(), &user, instanceId, "START"); err != nil {
+		return err
+	}
+	InvalidateInstanceSnapshots(userId)
+	return nil
 }
 
 func (s *InstanceService) StopInstance(userId string, instanceId string) error {
@@ -70,7 +148,11 @@ func (s *InstanceService) StopInstance(userId string, instanceId string) error {
 		return fmt.Errorf("user not found: %w", err)
 	}
 
-	return s.ociService.InstanceAction(context.Background(), &user, instanceId, "STOP")
+	if err := s.ociService.InstanceAction(context.Background(), &user, instanceId, "STOP"); err != nil {
+		return err
+	}
+	InvalidateInstanceSnapshots(userId)
+	return nil
 }
 
 func (s *InstanceService) RebootInstance(userId string, instanceId string) error {
@@ -79,7 +161,11 @@ func (s *InstanceService) RebootInstance(userId string, instanceId string) error
 		return fmt.Errorf("user not found: %w", err)
 	}
 
-	return s.ociService.InstanceAction(context.Background(), &user, instanceId, "RESET")
+	if err := s.ociService.InstanceAction(context.Background(), &user, instanceId, "RESET"); err != nil {
+		return err
+	}
+	InvalidateInstanceSnapshots(userId)
+	return nil
 }
 
 func (s *InstanceService) TerminateInstance(userId string, instanceId string) error {
@@ -88,7 +174,22 @@ func (s *InstanceService) TerminateInstance(userId string, instanceId string) er
 		return fmt.Errorf("user not found: %w", err)
 	}
 
-	return s.ociService.TerminateInstance(context.Background(), &user, instanceId)
+	if IsInstanceProtected(instanceId) {
+		return fmt.Errorf("实例已开启终止保护，请先关闭保护")
+	}
+
+	if err := s.ociService.TerminateInstance(context.Background(), &user, instanceId); err != nil {
+		return err
+	}
+	InvalidateInstanceSnapshots(userId)
+	return nil
+}
+
+// BatchInstanceAction 批量启动、停止、重启或终止多个配置下的实例
+func (s *InstanceService) BatchInstanceAction(action string, targets []BatchInstanceTarget) (*BatchInstanceReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	return s.ociService.BatchInstanceAction(ctx, action, targets)
 }
 
 func (s *InstanceService) UpdateInstanceName(userId string, instanceId string, displayName string) error {
@@ -100,6 +201,30 @@ func (s *InstanceService) UpdateInstanceName(userId string, instanceId string, d
 	return s.ociService.UpdateInstance(context.Background(), &user, instanceId, displayName)
 }
 
+// ExportTerraform 导出实例的 Terraform 配置
+func (s *InstanceService) ExportTerraform(userId string, instanceId string) (*TerraformExport, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	return s.ociService.ExportInstanceTerraform(ctx, &user, instanceId)
+}
+
+// GetInstanceMetrics 获取实例的 CPU、内存、磁盘与网络监控指标
+func (s *InstanceService) GetInstanceMetrics(userId string, instanceId string, rangeName string) (*InstanceMetrics, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	return s.ociService.GetInstanceMetrics(ctx, &user, instanceId, rangeName)
+}
+
 // ChangePublicIP 更改实例公网IP
 func (s *InstanceService) ChangePublicIP(userId string, instanceId string) (string, error) {
 	var user models.OciUser
@@ -120,31 +245,83 @@ func (s *InstanceService) ChangePublicIP(userId string, instanceId string) (stri
 	}
 
 	// 使用第一个VNIC更改IP
-	vnicId := details.VnicList[0].VnicID
-	newIP, err := s.ociService.ChangePublicIP(ctx, &user, vnicId)
+	vnic := details.VnicList[0]
+	newIP, err := s.ociService.ChangePublicIP(ctx, &user, vnic.VnicID)
 	if err != nil {
 		return "", fmt.Errorf("failed to change public IP: %w", err)
 	}
+	recordPublicIPChange(user.ID, instanceId, details.DisplayName, vnic.PublicIP, newIP, IPChangeSourceManual)
 
 	return newIP, nil
 }
 
 // UpdateInstanceConfig 更新实例配置（CPU和内存）
 // autoRestart: 是否在更新后自动重启实例（如果实例原来是运行状态）
-func (s *InstanceService) UpdateInstanceConfig(userId string, instanceId string, ocpus float32, memoryInGBs float32, autoRestart bool) error {
+// shape: 目标 Shape，为空表示不修改；跨架构时返回 *CrossArchitectureError
+func (s *InstanceService) UpdateInstanceConfig(userId string, instanceId string, shape string, ocpus float32, memoryInGBs float32, autoRestart bool) error {
 	var user models.OciUser
 	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
 		return fmt.Errorf("user not found: %w", err)
 	}
 
-	return s.ociService.UpdateInstanceShape(context.Background(), &user, instanceId, ocpus, memoryInGBs, autoRestart)
+	_, err := s.ociService.UpdateInstanceShape(context.Background(), &user, instanceId, shape, ocpus, memoryInGBs, autoRestart, nil)
+	return err
+}
+
+// PrecheckInstanceConfig 修改实例配置前的预检查
+func (s *InstanceService) PrecheckInstanceConfig(userId string, instanceId string, shape string, ocpus float32, memoryInGBs float32) (*InstanceConfigPrecheck, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	return s.ociService.PrecheckInstanceShape(context.Background(), &user, instanceId, shape, ocpus, memoryInGBs)
+}
+
+// StartShapeRebuild 启动跨架构重建任务，返回任务ID
+func (s *InstanceService) StartShapeRebuild(userId string, params ShapeRebuildParams) (string, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return "", fmt.Errorf("user not found: %w", err)
+	}
+
+	job, err := s.asyncJobs.Run(AsyncJobShapeRebuild, userId, params.InstanceID, params,
+		func(ctx context.Context, progress chan<- AutoRescueProgress) (interface{}, error) {
+			return s.ociService.RebuildInstanceWithShape(ctx, &user, params, progress)
+		})
+	if err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// GetShapeRebuildJob 获取跨架构重建任务状态
+func (s *InstanceService) GetShapeRebuildJob(jobId string) (*ShapeRebuildJob, bool) {
+	job, ok := s.asyncJobs.getKind(jobId, AsyncJobShapeRebuild)
+	if !ok {
+		return nil, false
+	}
+	rebuild := &ShapeRebuildJob{
+		ID:         job.ID,
+		InstanceID: job.ResourceID,
+		Status:     job.Status,
+		Steps:      job.Steps,
+		Result:     asyncJobValue[ShapeRebuildResult](job.Result),
+		Error:      job.Error,
+		CreateTime: job.CreateTime.Format("2006-01-02 15:04:05"),
+	}
+	if params := asyncJobValue[ShapeRebuildParams](job.Params); params != nil {
+		rebuild.TargetShape = params.TargetShape
+	}
+	return rebuild, true
 }
 
 // UpdateBootVolumeConfig 更新引导卷配置（通过实例ID）
-func (s *InstanceService) UpdateBootVolumeConfig(userId string, instanceId string, sizeInGBs int64, vpusPerGB int64) error {
+// growFilesystem 为 true 时在扩容完成后通过 Cloud Agent 扩展系统内的根文件系统
+func (s *InstanceService) UpdateBootVolumeConfig(userId string, instanceId string, sizeInGBs int64, vpusPerGB int64, growFilesystem bool) (*AgentCommandResult, error) {
 	var user models.OciUser
 	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
-		return fmt.Errorf("user not found: %w", err)
+		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
 	ctx := context.Background()
@@ -152,13 +329,13 @@ func (s *InstanceService) UpdateBootVolumeConfig(userId string, instanceId strin
 	// 获取实例信息
 	instance, err := s.ociService.GetInstance(ctx, &user, instanceId)
 	if err != nil {
-		return fmt.Errorf("failed to get instance: %w", err)
+		return nil, fmt.Errorf("failed to get instance: %w", err)
 	}
 
 	// 获取引导卷ID
 	computeClient, err := s.ociService.GetComputeClient(&user)
 	if err != nil {
-		return fmt.Errorf("failed to get compute client: %w", err)
+		return nil, fmt.Errorf("failed to get compute client: %w", err)
 	}
 
 	listAttachReq := core.ListBootVolumeAttachmentsRequest{
@@ -168,15 +345,25 @@ func (s *InstanceService) UpdateBootVolumeConfig(userId string, instanceId strin
 	}
 	attachResp, err := computeClient.ListBootVolumeAttachments(ctx, listAttachReq)
 	if err != nil {
-		return fmt.Errorf("failed to list boot volume attachments: %w", err)
+		return nil, fmt.Errorf("failed to list boot volume attachments: %w", err)
 	}
 
 	if len(attachResp.Items) == 0 {
-		return fmt.Errorf("no boot volume found for instance")
+		return nil, fmt.Errorf("no boot volume found for instance")
 	}
 
 	bootVolumeId := *attachResp.Items[0].BootVolumeId
-	return s.ociService.UpdateBootVolume(ctx, &user, bootVolumeId, sizeInGBs, vpusPerGB)
+	if err := s.ociService.UpdateBootVolume(ctx, &user, bootVolumeId, sizeInGBs, vpusPerGB); err != nil {
+		return nil, err
+	}
+	if !growFilesystem {
+		return nil, nil
+	}
+
+	if err := s.ociService.WaitBootVolumeAvailable(ctx, &user, bootVolumeId); err != nil {
+		return nil, err
+	}
+	return s.ociService.GrowBootVolumeFilesystem(ctx, &user, instance)
 }
 
 // UpdateBootVolumeById 直接通过引导卷ID更新配置
@@ -235,42 +422,6 @@ func (s *InstanceService) AttachIPv6(userId string, instanceId string) (string,
 	return ipv6Address, nil
 }
 
-// AutoRescue 自动救援/缩小硬盘
-func (s *InstanceService) AutoRescue(userId string, instanceId string, instanceName string, keepBackup bool, progressChan chan<- AutoRescueProgress) error {
-	var user models.OciUser
-	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
-		return fmt.Errorf("user not found: %w", err)
-	}
-
-	params := AutoRescueParams{
-		InstanceID:       instanceId,
-		InstanceName:     instanceName,
-		KeepBackupVolume: keepBackup,
-	}
-
-	return s.ociService.AutoRescue(&user, params, progressChan)
-}
-
-// Enable500Mbps 一键开启下行500Mbps
-func (s *InstanceService) Enable500Mbps(userId string, instanceId string, sshPort int) (string, error) {
-	var user models.OciUser
-	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
-		return "", fmt.Errorf("user not found: %w", err)
-	}
-
-	return s.ociService.Enable500Mbps(&user, instanceId, sshPort)
-}
-
-// Disable500Mbps 关闭下行500Mbps
-func (s *InstanceService) Disable500Mbps(userId string, instanceId string, retainNatGw, retainNlb bool) error {
-	var user models.OciUser
-	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
-		return fmt.Errorf("user not found: %w", err)
-	}
-
-	return s.ociService.Disable500Mbps(&user, instanceId, retainNatGw, retainNlb)
-}
-
 // Check500MbpsSupport 检查实例是否支持500Mbps功能
 // 仅 VM.Standard.E2.1.Micro (AMD) 实例支持此功能
 func (s *InstanceService) Check500MbpsSupport(userId string, instanceId string) (bool, string, error) {
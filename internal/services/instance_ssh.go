@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHConnectOptions 面板连接实例 SSH 的参数，SSHKeyID、PrivateKey 与 Password 至少填写一项
+type SSHConnectOptions struct {
+	Username   string // 为空时按实例镜像选择 ubuntu 或 opc
+	SSHKeyID   string // 使用已保存的 SSH 密钥
+	PrivateKey string
+	Passphrase string
+	Password   string
+	Host       string // 为空时使用实例的公网 IP
+	Port       int    // 为 0 时使用实例记录的 SSH 端口
+}
+
+// sshTarget 已确定地址与认证方式的 SSH 连接目标
+type sshTarget struct {
+	Host     string
+	Port     int
+	Username string
+	auth     []ssh.AuthMethod
+}
+
+// resolveSSHTarget 校验凭据并确定实例的 SSH 地址、端口与登录用户
+func (s *OCIService) resolveSSHTarget(ctx context.Context, user *models.OciUser, instanceId string, opts SSHConnectOptions) (*sshTarget, error) {
+	auth, err := buildSSHAuth(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	host := strings.TrimSpace(opts.Host)
+	if host == "" {
+		vnic, err := s.getPrimaryVnic(ctx, user, instanceId)
+		if err != nil {
+			return nil, fmt.Errorf("获取实例网卡失败: %w", err)
+		}
+		host = stringValue(vnic.PublicIp)
+		if host == "" {
+			return nil, fmt.Errorf("实例没有公网 IP，请填写连接地址")
+		}
+	}
+	port := opts.Port
+	if port == 0 {
+		port = GetInstanceSSHPort(instanceId)
+	}
+	if port < 1 || port > 65535 {
+		return nil, fmt.Errorf("端口需在 1-65535 之间")
+	}
+	username := strings.TrimSpace(opts.Username)
+	if username == "" {
+		username = s.defaultSSHUsername(ctx, user, instanceId)
+	}
+	return &sshTarget{Host: host, Port: port, Username: username, auth: auth}, nil
+}
+
+// dial 连接 SSH，返回客户端与主机密钥指纹
+// 实例的主机密钥事先无法获知，接受任意密钥并将指纹返回给调用方供用户核对
+func (t *sshTarget) dial(timeout time.Duration) (*ssh.Client, string, error) {
+	var fingerprint string
+	config := &ssh.ClientConfig{
+		User: t.Username,
+		Auth: t.auth,
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			fingerprint = ssh.FingerprintSHA256(key)
+			return nil
+		},
+		Timeout: timeout,
+	}
+	client, err := ssh.Dial("tcp", net.JoinHostPort(t.Host, strconv.Itoa(t.Port)), config)
+	if err != nil {
+		return nil, "", fmt.Errorf("SSH 连接失败: %w", err)
+	}
+	return client, fingerprint, nil
+}
+
+// buildSSHAuth 按已保存密钥、私钥、密码的顺序组合认证方式
+func buildSSHAuth(opts SSHConnectOptions) ([]ssh.AuthMethod, error) {
+	var auth []ssh.AuthMethod
+	if opts.SSHKeyID != "" {
+		var key models.SSHKey
+		if err := database.GetDB().Where("id = ?", opts.SSHKeyID).First(&key).Error; err != nil {
+			return nil, fmt.Errorf("SSH 密钥不存在")
+		}
+		if key.PrivateKey == "" {
+			return nil, fmt.Errorf("该 SSH 密钥未保存私钥")
+		}
+		signer, err := parseSSHPrivateKey(key.PrivateKey, opts.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if opts.PrivateKey != "" {
+		signer, err := parseSSHPrivateKey(opts.PrivateKey, opts.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if opts.Password != "" {
+		password := opts.Password
+		auth = append(auth, ssh.Password(password), ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			for i := range answers {
+				answers[i] = password
+			}
+			return answers, nil
+		}))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("请选择 SSH 密钥或填写私钥、密码")
+	}
+	return auth, nil
+}
+
+func parseSSHPrivateKey(privateKey, passphrase string) (ssh.Signer, error) {
+	var signer ssh.Signer
+	var err error
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(privateKey))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("私钥解析失败: %w", err)
+	}
+	return signer, nil
+}
+
+// defaultSSHUsername 按实例镜像的操作系统返回默认登录用户，获取失败时使用 opc
+func (s *OCIService) defaultSSHUsername(ctx context.Context, user *models.OciUser, instanceId string) string {
+	instance, err := s.GetInstance(ctx, user, instanceId)
+	if err != nil || instance.ImageId == nil {
+		return "opc"
+	}
+	client, err := s.GetComputeClient(user)
+	if err != nil {
+		return "opc"
+	}
+	resp, err := client.GetImage(ctx, core.GetImageRequest{ImageId: instance.ImageId})
+	if err == nil && strings.Contains(strings.ToLower(stringValue(resp.OperatingSystem)), "ubuntu") {
+		return "ubuntu"
+	}
+	return "opc"
+}
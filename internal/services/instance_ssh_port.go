@@ -0,0 +1,37 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultSSHPort 未记录端口的实例使用的 SSH 端口
+const DefaultSSHPort = 22
+
+// GetInstanceSSHPort 获取实例实际使用的 SSH 端口
+func GetInstanceSSHPort(instanceID string) int {
+	var record models.InstanceSSHPort
+	if err := database.GetDB().Where("instance_id = ?", instanceID).First(&record).Error; err != nil || record.Port <= 0 {
+		return DefaultSSHPort
+	}
+	return record.Port
+}
+
+// SetInstanceSSHPort 记录实例实际使用的 SSH 端口，设为 22 时删除记录
+func SetInstanceSSHPort(configID, instanceID string, port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("端口需在 1-65535 之间")
+	}
+	db := database.GetDB()
+	if port == DefaultSSHPort {
+		return db.Where("instance_id = ?", instanceID).Delete(&models.InstanceSSHPort{}).Error
+	}
+	record := models.InstanceSSHPort{InstanceID: instanceID, ConfigID: configID, Port: port}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "instance_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"port", "update_time"}),
+	}).Create(&record).Error
+}
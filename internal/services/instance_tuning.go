@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	InstanceTuningApplied    = "applied"
+	InstanceTuningRolledBack = "rolled_back"
+
+	// 默认交换文件大小，1GB 内存的 Micro 实例建议 1-2GB
+	DefaultTuningSwapSizeMB = 1024
+	MaxTuningSwapSizeMB     = 8192
+	// 运行命令在实例内的最长执行时间，不支持 fallocate 的文件系统需用 dd 写满交换文件
+	tuningTimeoutSeconds = 600
+)
+
+// tuningSysctlParam 调优时写入的内核参数
+type tuningSysctlParam struct {
+	Key   string
+	Value string
+}
+
+// tuningSysctlParams 启用 BBR 拥塞控制并降低小内存实例的换页倾向
+var tuningSysctlParams = []tuningSysctlParam{
+	{"net.core.default_qdisc", "fq"},
+	{"net.ipv4.tcp_congestion_control", "bbr"},
+	{"net.ipv4.tcp_fastopen", "3"},
+	{"vm.swappiness", "10"},
+	{"vm.vfs_cache_pressure", "50"},
+}
+
+// tuningScriptHeader 调优与回滚脚本的公共开头
+const tuningScriptHeader = `#!/bin/bash
+set -e
+SUDO=""
+if [ "$(id -u)" != "0" ]; then SUDO="sudo -n"; fi
+SWAPFILE=/oci-panel.swap
+SYSCTL_CONF=/etc/sysctl.d/99-oci-panel.conf
+`
+
+// tuningApplyScript 创建交换文件并定义逐项应用内核参数的函数
+// 输出 SWAP_CREATED、PREV 与 APPLIED 行，供面板记录实际应用的内容与原值
+const tuningApplyScript = `if [ "$SWAP_MB" -gt 0 ]; then
+  if swapon --show=NAME --noheadings 2>/dev/null | grep -qx "$SWAPFILE"; then
+    echo "swap $SWAPFILE already active"
+  else
+    $SUDO fallocate -l "${SWAP_MB}M" "$SWAPFILE" 2>/dev/null || $SUDO dd if=/dev/zero of="$SWAPFILE" bs=1M count="$SWAP_MB" status=none
+    $SUDO chmod 600 "$SWAPFILE"
+    $SUDO mkswap "$SWAPFILE" >/dev/null
+    $SUDO swapon "$SWAPFILE"
+    grep -q "^$SWAPFILE " /etc/fstab || echo "$SWAPFILE none swap sw 0 0" | $SUDO tee -a /etc/fstab >/dev/null
+    echo "SWAP_CREATED"
+  fi
+fi
+
+$SUDO modprobe tcp_bbr 2>/dev/null || true
+CONF_TMP=$(mktemp)
+tune() {
+  echo "PREV $1=$(sysctl -n "$1" 2>/dev/null)"
+  if $SUDO sysctl -w "$1=$2" >/dev/null 2>&1; then
+    echo "$1 = $2" >> "$CONF_TMP"
+    echo "APPLIED $1=$2"
+  else
+    echo "SKIPPED $1"
+  fi
+}
+`
+
+// tuningApplyFooter 持久化已应用的内核参数并输出当前状态
+const tuningApplyFooter = `if [ -s "$CONF_TMP" ]; then $SUDO install -m 644 "$CONF_TMP" "$SYSCTL_CONF"; fi
+rm -f "$CONF_TMP"
+free -m
+`
+
+// shellQuote 将字符串转为单引号包围的 shell 参数
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// buildTuningScript 生成创建交换文件与应用内核参数的脚本，swapSizeMB 为 0 时不创建交换文件
+func buildTuningScript(swapSizeMB int) string {
+	var b strings.Builder
+	b.WriteString(tuningScriptHeader)
+	fmt.Fprintf(&b, "SWAP_MB=%d\n", swapSizeMB)
+	b.WriteString(tuningApplyScript)
+	for _, param := range tuningSysctlParams {
+		fmt.Fprintf(&b, "tune %s %s\n", param.Key, shellQuote(param.Value))
+	}
+	b.WriteString(tuningApplyFooter)
+	return b.String()
+}
+
+// buildTuningRollbackScript 生成回滚脚本：删除面板创建的交换文件与参数文件，并恢复内核参数原值
+func buildTuningRollbackScript(tuning *models.InstanceTuning, previous map[string]string) string {
+	var b strings.Builder
+	b.WriteString(tuningScriptHeader)
+	if tuning.SwapCreated {
+		b.WriteString(`if swapon --show=NAME --noheadings 2>/dev/null | grep -qx "$SWAPFILE"; then $SUDO swapoff "$SWAPFILE"; fi
+$SUDO sed -i "\#^$SWAPFILE #d" /etc/fstab
+$SUDO rm -f "$SWAPFILE"
+`)
+	}
+	b.WriteString("$SUDO rm -f \"$SYSCTL_CONF\"\n")
+	for _, param := range tuningSysctlParams {
+		value, ok := previous[param.Key]
+		if !ok || value == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "$SUDO sysctl -w %s >/dev/null || true\n", shellQuote(param.Key+"="+value))
+	}
+	b.WriteString("free -m\n")
+	return b.String()
+}
+
+// parseTuningOutput 从脚本输出中解析是否创建了交换文件、实际应用的参数及其原值
+func parseTuningOutput(output string) (bool, map[string]string, map[string]string) {
+	swapCreated := false
+	applied := map[string]string{}
+	previous := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "SWAP_CREATED":
+			swapCreated = true
+		case strings.HasPrefix(line, "PREV "):
+			if key, value, ok := strings.Cut(strings.TrimPrefix(line, "PREV "), "="); ok {
+				previous[key] = strings.TrimSpace(value)
+			}
+		case strings.HasPrefix(line, "APPLIED "):
+			if key, value, ok := strings.Cut(strings.TrimPrefix(line, "APPLIED "), "="); ok {
+				applied[key] = value
+			}
+		}
+	}
+	// 只保留实际应用的参数的原值，回滚时仅恢复这些参数
+	for key := range previous {
+		if _, ok := applied[key]; !ok {
+			delete(previous, key)
+		}
+	}
+	return swapCreated, applied, previous
+}
+
+// GetInstanceTuning 获取实例的调优记录，未调优过时返回 nil
+func GetInstanceTuning(instanceID string) (*models.InstanceTuning, error) {
+	var tuning models.InstanceTuning
+	result := database.GetDB().Where("instance_id = ?", instanceID).Limit(1).Find(&tuning)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &tuning, nil
+}
+
+// loadTuningInstance 获取需要执行运行命令的实例，要求实例运行中
+func (s *InstanceService) loadTuningInstance(ctx context.Context, userId, instanceId string) (*models.OciUser, *core.Instance, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, nil, fmt.Errorf("user not found: %w", err)
+	}
+	instance, err := s.ociService.GetInstance(ctx, &user, instanceId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+	if instance.LifecycleState != core.InstanceLifecycleStateRunning {
+		return nil, nil, fmt.Errorf("实例未运行，无法执行调优")
+	}
+	return &user, instance, nil
+}
+
+// ApplyInstanceTuning 通过 Cloud Agent 在实例内创建交换文件并应用 BBR 等内核参数，记录应用的内容与原值用于回滚
+// 需要实例启用 Compute Instance Run Command 插件，并允许 ocarun 用户免密 sudo
+func (s *InstanceService) ApplyInstanceTuning(userId, instanceId string, swapSizeMB int) (*models.InstanceTuning, *AgentCommandResult, error) {
+	if swapSizeMB < 0 || swapSizeMB > MaxTuningSwapSizeMB {
+		return nil, nil, fmt.Errorf("交换文件大小需在 0-%d MB 之间", MaxTuningSwapSizeMB)
+	}
+	existing, err := GetInstanceTuning(instanceId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing != nil && existing.Status == InstanceTuningApplied {
+		return nil, nil, fmt.Errorf("实例已应用调优，请先回滚")
+	}
+
+	ctx := context.Background()
+	user, instance, err := s.loadTuningInstance(ctx, userId, instanceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.ociService.RunAgentCommand(ctx, user, instance, "oci-panel-tuning", buildTuningScript(swapSizeMB), tuningTimeoutSeconds)
+	if err != nil {
+		return nil, result, err
+	}
+
+	// 脚本中途失败时也记录已生效的部分，确保可以回滚
+	swapCreated, applied, previous := parseTuningOutput(result.Output)
+	var tuning *models.InstanceTuning
+	if swapCreated || len(applied) > 0 {
+		appliedJSON, _ := json.Marshal(applied)
+		previousJSON, _ := json.Marshal(previous)
+		tuning = &models.InstanceTuning{
+			InstanceID:     instanceId,
+			ConfigID:       userId,
+			Status:         InstanceTuningApplied,
+			SwapCreated:    swapCreated,
+			Sysctl:         string(appliedJSON),
+			PreviousSysctl: string(previousJSON),
+			Output:         result.Output,
+		}
+		if swapCreated {
+			tuning.SwapSizeMB = swapSizeMB
+		}
+		if err := database.GetDB().Save(tuning).Error; err != nil {
+			return nil, result, err
+		}
+	}
+
+	if !result.Succeeded() {
+		return tuning, result, fmt.Errorf("调优失败 (%s, exit %d)", result.State, result.ExitCode)
+	}
+	return tuning, result, nil
+}
+
+// RollbackInstanceTuning 按调优记录删除面板创建的交换文件并恢复内核参数原值
+func (s *InstanceService) RollbackInstanceTuning(userId, instanceId string) (*models.InstanceTuning, *AgentCommandResult, error) {
+	tuning, err := GetInstanceTuning(instanceId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tuning == nil || tuning.Status != InstanceTuningApplied {
+		return nil, nil, fmt.Errorf("实例没有可回滚的调优")
+	}
+	previous := map[string]string{}
+	if tuning.PreviousSysctl != "" {
+		if err := json.Unmarshal([]byte(tuning.PreviousSysctl), &previous); err != nil {
+			return nil, nil, fmt.Errorf("调优记录已损坏: %w", err)
+		}
+	}
+
+	ctx := context.Background()
+	user, instance, err := s.loadTuningInstance(ctx, userId, instanceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.ociService.RunAgentCommand(ctx, user, instance, "oci-panel-tuning-rollback", buildTuningRollbackScript(tuning, previous), tuningTimeoutSeconds)
+	if err != nil {
+		return tuning, result, err
+	}
+	if !result.Succeeded() {
+		return tuning, result, fmt.Errorf("回滚失败 (%s, exit %d)", result.State, result.ExitCode)
+	}
+
+	tuning.Status = InstanceTuningRolledBack
+	tuning.Output = result.Output
+	if err := database.GetDB().Save(tuning).Error; err != nil {
+		return nil, result, err
+	}
+	return tuning, result, nil
+}
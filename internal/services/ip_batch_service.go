@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+const (
+	IPChangeSourceBatch = "batch"
+
+	// 批量更换时相邻两次请求的默认间隔与上限（秒）
+	DefaultBatchIPChangeDelay = 10
+	MaxBatchIPChangeDelay     = 600
+	// 单次批量更换的实例数上限
+	MaxBatchIPChangeInstances = 50
+	// 遇到 OCI 限流时的重试次数与首次等待时间，之后每次翻倍
+	batchIPChangeThrottleRetries = 2
+	batchIPChangeThrottleBackoff = 10 * time.Second
+	// 批量任务结果保留时间
+	batchIPChangeRetention = 24 * time.Hour
+)
+
+// BatchIPChangeResult 单个实例的更换结果
+type BatchIPChangeResult struct {
+	InstanceID   string `json:"instanceId"`
+	InstanceName string `json:"instanceName"`
+	Status       string `json:"status"` // pending, success, failed
+	OldIP        string `json:"oldIp"`
+	NewIP        string `json:"newIp"`
+	Error        string `json:"error,omitempty"`
+}
+
+// BatchIPChangeJob 批量更换公网 IP 任务
+type BatchIPChangeJob struct {
+	ID         string                `json:"id"`
+	ConfigID   string                `json:"configId"`
+	Status     string                `json:"status"` // running, completed
+	Delay      int                   `json:"delay"`  // 相邻两次请求的间隔（秒）
+	Finished   int                   `json:"finished"`
+	Succeeded  int                   `json:"succeeded"`
+	Failed     int                   `json:"failed"`
+	Results    []BatchIPChangeResult `json:"results"`
+	CreateTime string                `json:"createTime"`
+	finishTime time.Time
+}
+
+// StartBatchIPChange 启动批量更换公网 IP 任务，按顺序逐个更换并在相邻请求间等待 delay 秒
+// 每次请求占用租户的并发名额，与开机任务共享限流
+func (s *IPRotationService) StartBatchIPChange(configID string, instanceIDs []string, delay int) (string, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", configID).First(&user).Error; err != nil {
+		return "", fmt.Errorf("配置不存在")
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, id := range instanceIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("请选择实例")
+	}
+	if len(ids) > MaxBatchIPChangeInstances {
+		return "", fmt.Errorf("单次最多更换 %d 个实例", MaxBatchIPChangeInstances)
+	}
+	if delay < 0 || delay > MaxBatchIPChangeDelay {
+		return "", fmt.Errorf("间隔需在 0-%d 秒之间", MaxBatchIPChangeDelay)
+	}
+
+	job := &BatchIPChangeJob{
+		ID:         uuid.New().String(),
+		ConfigID:   configID,
+		Status:     "running",
+		Delay:      delay,
+		Results:    make([]BatchIPChangeResult, len(ids)),
+		CreateTime: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	for i, id := range ids {
+		job.Results[i] = BatchIPChangeResult{InstanceID: id, Status: "pending"}
+	}
+
+	s.batchMu.Lock()
+	s.cleanupBatchJobs()
+	s.batchJobs[job.ID] = job
+	s.batchMu.Unlock()
+
+	go s.runBatchIPChange(job, &user)
+	return job.ID, nil
+}
+
+// cleanupBatchJobs 删除已结束且超过保留时间的批量任务，调用方需持有写锁
+func (s *IPRotationService) cleanupBatchJobs() {
+	for id, job := range s.batchJobs {
+		if job.Status != "running" && time.Since(job.finishTime) > batchIPChangeRetention {
+			delete(s.batchJobs, id)
+		}
+	}
+}
+
+func (s *IPRotationService) runBatchIPChange(job *BatchIPChangeJob, user *models.OciUser) {
+	for i := range job.Results {
+		if i > 0 && job.Delay > 0 {
+			time.Sleep(time.Duration(job.Delay) * time.Second)
+		}
+
+		s.batchMu.RLock()
+		instanceID := job.Results[i].InstanceID
+		s.batchMu.RUnlock()
+
+		name, oldIP, newIP, err := s.changeBatchInstanceIP(user, instanceID)
+
+		s.batchMu.Lock()
+		result := &job.Results[i]
+		result.InstanceName = name
+		result.OldIP = oldIP
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			job.Failed++
+		} else {
+			result.Status = "success"
+			result.NewIP = newIP
+			job.Succeeded++
+		}
+		job.Finished++
+		s.batchMu.Unlock()
+	}
+
+	s.batchMu.Lock()
+	job.Status = "completed"
+	job.finishTime = time.Now()
+	s.batchMu.Unlock()
+	log.Printf("[IPRotation] Batch %s finished for config %s: %d succeeded, %d failed", job.ID, user.Username, job.Succeeded, job.Failed)
+}
+
+// changeBatchInstanceIP 更换单个实例主 VNIC 的临时公网 IP，遇到限流时等待后重试
+func (s *IPRotationService) changeBatchInstanceIP(user *models.OciUser, instanceID string) (string, string, string, error) {
+	if s.limiter != nil {
+		s.limiter.acquire(user.ID, 0)
+		defer s.limiter.release(user.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ipRotationTimeout)
+	defer cancel()
+
+	details, err := s.ociService.GetInstanceDetails(ctx, user, instanceID)
+	if err != nil {
+		return "", "", "", fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+	if details.State == "TERMINATED" || details.State == "TERMINATING" {
+		return details.DisplayName, "", "", fmt.Errorf("实例已终止")
+	}
+	if len(details.VnicList) == 0 {
+		return details.DisplayName, "", "", fmt.Errorf("实例没有 VNIC")
+	}
+
+	vnic := details.VnicList[0]
+	backoff := batchIPChangeThrottleBackoff
+	for attempt := 0; ; attempt++ {
+		newIP, err := s.ociService.ChangePublicIP(ctx, user, vnic.VnicID)
+		if err == nil {
+			recordPublicIPChange(user.ID, instanceID, details.DisplayName, vnic.PublicIP, newIP, IPChangeSourceBatch)
+			return details.DisplayName, vnic.PublicIP, newIP, nil
+		}
+		serviceErr, ok := common.IsServiceError(err)
+		if !ok || serviceErr.GetHTTPStatusCode() != 429 || attempt >= batchIPChangeThrottleRetries {
+			return details.DisplayName, vnic.PublicIP, "", fmt.Errorf("更换公网 IP 失败: %s", extractOCIErrorMessage(err))
+		}
+		log.Printf("[IPRotation] Throttled while changing IP of %s, retrying in %s", details.DisplayName, backoff)
+		select {
+		case <-ctx.Done():
+			return details.DisplayName, vnic.PublicIP, "", fmt.Errorf("更换公网 IP 超时")
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// GetBatchIPChangeJob 获取批量更换任务的进度与结果副本
+func (s *IPRotationService) GetBatchIPChangeJob(id string) (*BatchIPChangeJob, bool) {
+	s.batchMu.RLock()
+	defer s.batchMu.RUnlock()
+
+	job, ok := s.batchJobs[id]
+	if !ok {
+		return nil, false
+	}
+	copied := *job
+	copied.Results = append([]BatchIPChangeResult(nil), job.Results...)
+	return &copied, true
+}
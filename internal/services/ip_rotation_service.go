@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+const (
+	IPChangeSourceManual   = "manual"
+	IPChangeSourceSchedule = "schedule"
+
+	IPRotationStatusSuccess = "success"
+	IPRotationStatusFailed  = "failed"
+
+	// 服务暂停后补执行错过计划的最长时间
+	ipRotationCatchUp = 10 * time.Minute
+	// 单次更换的超时
+	ipRotationTimeout = 90 * time.Second
+)
+
+// IPRotationScheduleView 带下次执行时间的 IP 更换计划
+type IPRotationScheduleView struct {
+	models.IPRotationSchedule
+	NextRunTime string `json:"nextRunTime"` // 已停用或表达式无效时为空
+}
+
+// recordPublicIPChange 记录一次公网 IP 更换
+func recordPublicIPChange(configID, instanceID, instanceName, oldIP, newIP, source string) {
+	record := models.PublicIPHistory{
+		ID:           uuid.New().String(),
+		ConfigID:     configID,
+		InstanceID:   instanceID,
+		InstanceName: instanceName,
+		OldIP:        oldIP,
+		NewIP:        newIP,
+		Source:       source,
+	}
+	if err := database.GetDB().Create(&record).Error; err != nil {
+		log.Printf("[IPRotation] Failed to record IP change for %s: %v", instanceID, err)
+	}
+}
+
+type IPRotationService struct {
+	ociService      *OCIService
+	telegramService *TelegramService
+	runner          *cronRunner[models.IPRotationSchedule]
+	limiter         *tenantLimiter // 与开机任务共享的租户并发限制，用于批量更换
+	batchMu         sync.RWMutex
+	batchJobs       map[string]*BatchIPChangeJob
+}
+
+func NewIPRotationService(ociService *OCIService, telegramService *TelegramService, taskService *TaskService) *IPRotationService {
+	s := &IPRotationService{
+		ociService:      ociService,
+		telegramService: telegramService,
+		batchJobs:       make(map[string]*BatchIPChangeJob),
+	}
+	s.runner = newCronRunner("IPRotation", ipRotationCatchUp,
+		func(schedule *models.IPRotationSchedule) (string, string) { return schedule.ID, schedule.Cron },
+		s.runDue)
+	if taskService != nil {
+		s.limiter = taskService.limiter
+	}
+	return s
+}
+
+// Job 返回由作业框架调度的 IP 定时更换作业，每分钟检查一次，只有存在到期计划时才会执行并记录
+func (s *IPRotationService) Job() Job {
+	return s.runner.job("ip_rotation", "按计划更换实例临时公网 IP")
+}
+
+// runDue 依次更换到期计划的公网 IP，结果通过 Telegram 推送
+func (s *IPRotationService) runDue(ctx context.Context, due []models.IPRotationSchedule) (string, error) {
+	var succeeded, failed int
+	for i := range due {
+		schedule := &due[i]
+		updates := map[string]interface{}{"last_run_time": time.Now()}
+
+		oldIP, newIP, err := s.rotate(ctx, schedule)
+		if err != nil {
+			failed++
+			updates["last_status"] = IPRotationStatusFailed
+			updates["last_message"] = err.Error()
+			s.notify(schedule, "ip_rotation_failed", err.Error())
+			log.Printf("[IPRotation] %s failed: %v", schedule.InstanceName, err)
+		} else {
+			succeeded++
+			updates["last_status"] = IPRotationStatusSuccess
+			updates["last_message"] = fmt.Sprintf("%s -> %s", oldIP, newIP)
+			s.notify(schedule, "ip_rotation_success", oldIP, newIP)
+			log.Printf("[IPRotation] %s: %s -> %s", schedule.InstanceName, oldIP, newIP)
+		}
+		database.GetDB().Model(&models.IPRotationSchedule{}).Where("id = ?", schedule.ID).Updates(updates)
+	}
+
+	summary := fmt.Sprintf("执行 %d 个计划：成功 %d，失败 %d", len(due), succeeded, failed)
+	if failed > 0 {
+		return "", fmt.Errorf("%s", summary)
+	}
+	return summary, nil
+}
+
+// rotate 更换计划对应实例主 VNIC 的临时公网 IP，实例已终止或不存在时停用计划
+func (s *IPRotationService) rotate(ctx context.Context, schedule *models.IPRotationSchedule) (string, string, error) {
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", schedule.ConfigID).First(&user).Error; err != nil {
+		return "", "", fmt.Errorf("配置不存在")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ipRotationTimeout)
+	defer cancel()
+
+	details, err := s.ociService.GetInstanceDetails(ctx, &user, schedule.InstanceID)
+	if err != nil {
+		if serviceErr, ok := common.IsServiceError(err); ok && serviceErr.GetHTTPStatusCode() == 404 {
+			db.Model(schedule).Update("enabled", false)
+			return "", "", fmt.Errorf("实例不存在，计划已停用")
+		}
+		return "", "", fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+	if details.State == "TERMINATED" || details.State == "TERMINATING" {
+		db.Model(schedule).Update("enabled", false)
+		return "", "", fmt.Errorf("实例已终止，计划已停用")
+	}
+	if len(details.VnicList) == 0 {
+		return "", "", fmt.Errorf("实例没有 VNIC")
+	}
+
+	vnic := details.VnicList[0]
+	newIP, err := s.ociService.ChangePublicIP(ctx, &user, vnic.VnicID)
+	if err != nil {
+		return "", "", fmt.Errorf("更换公网 IP 失败: %s", extractOCIErrorMessage(err))
+	}
+	recordPublicIPChange(user.ID, schedule.InstanceID, details.DisplayName, vnic.PublicIP, newIP, IPChangeSourceSchedule)
+	return vnic.PublicIP, newIP, nil
+}
+
+// notify 推送更换结果，key 对应的标题为 key + "_title"
+func (s *IPRotationService) notify(schedule *models.IPRotationSchedule, key string, args ...interface{}) {
+	if s.telegramService == nil {
+		return
+	}
+	tg := s.telegramService
+	var user models.OciUser
+	database.GetDB().Where("id = ?", schedule.ConfigID).First(&user)
+	text := tg.t(key, append([]interface{}{user.Username, schedule.InstanceName}, args...)...)
+	if err := tg.SendNotification(tg.t(key+"_title"), text); err != nil {
+		log.Printf("[IPRotation] Failed to send notification: %v", err)
+	}
+}
+
+// CreateIPRotationSchedule 为实例创建 IP 定时更换计划
+func (s *IPRotationService) CreateIPRotationSchedule(configID, instanceID, cron string) (*models.IPRotationSchedule, error) {
+	cron, err := normalizeCron(cron)
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", configID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+	instance, err := s.ociService.GetInstance(context.Background(), &user, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+
+	schedule := models.IPRotationSchedule{
+		ID:           uuid.New().String(),
+		ConfigID:     configID,
+		InstanceID:   instanceID,
+		InstanceName: stringValue(instance.DisplayName),
+		Cron:         cron,
+		Enabled:      true,
+	}
+	if err := db.Create(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ListIPRotationSchedules 获取 IP 定时更换计划，参数为空时不按该条件过滤
+func ListIPRotationSchedules(configID, instanceID string) ([]IPRotationScheduleView, error) {
+	query := database.GetDB().Order("create_time DESC")
+	if configID != "" {
+		query = query.Where("config_id = ?", configID)
+	}
+	if instanceID != "" {
+		query = query.Where("instance_id = ?", instanceID)
+	}
+	var schedules []models.IPRotationSchedule
+	if err := query.Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := make([]IPRotationScheduleView, len(schedules))
+	for i, schedule := range schedules {
+		list[i].IPRotationSchedule = schedule
+		if schedule.Enabled {
+			list[i].NextRunTime = cronNextRunText(schedule.Cron, now)
+		}
+	}
+	return list, nil
+}
+
+// UpdateIPRotationSchedule 修改计划的表达式与启用状态
+func UpdateIPRotationSchedule(id, cron string, enabled bool) error {
+	cron, err := normalizeCron(cron)
+	if err != nil {
+		return err
+	}
+	result := database.GetDB().Model(&models.IPRotationSchedule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"cron":    cron,
+		"enabled": enabled,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("计划不存在")
+	}
+	return nil
+}
+
+// DeleteIPRotationSchedule 删除 IP 定时更换计划
+func DeleteIPRotationSchedule(id string) error {
+	return database.GetDB().Where("id = ?", id).Delete(&models.IPRotationSchedule{}).Error
+}
+
+// GetPublicIPHistory 分页获取公网 IP 更换记录，参数为空时不按该条件过滤
+func GetPublicIPHistory(configID, instanceID string, page, pageSize int) ([]models.PublicIPHistory, int64, error) {
+	query := database.GetDB().Model(&models.PublicIPHistory{})
+	if configID != "" {
+		query = query.Where("config_id = ?", configID)
+	}
+	if instanceID != "" {
+		query = query.Where("instance_id = ?", instanceID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var records []models.PublicIPHistory
+	err := query.Order("create_time DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&records).Error
+	return records, total, err
+}
@@ -86,11 +86,18 @@ func (s *IpService) ChangePublicIp(userId string, instanceId string, compartment
 
 	ctx := context.Background()
 
+	// 记录历史用的原 IP，获取失败不影响更换
+	var oldIp string
+	if vnic, err := s.GetVnic(userId, *vnicId); err == nil && vnic.PublicIp != nil {
+		oldIp = *vnic.PublicIp
+	}
+
 	// 使用OCIService的ChangePublicIP方法（已修复使用正确的PrivateIpId）
 	newIp, err := s.ociService.ChangePublicIP(ctx, &user, *vnicId)
 	if err != nil {
 		return "", fmt.Errorf("failed to change public ip: %w", err)
 	}
+	recordPublicIPChange(user.ID, instanceId, "", oldIp, newIp, IPChangeSourceManual)
 
 	return newIp, nil
 }
@@ -108,10 +115,13 @@ func (s *IpService) AttachIpv6(userId string, vnicId string, ipv6SubnetCidr stri
 
 	req := core.CreateIpv6Request{
 		CreateIpv6Details: core.CreateIpv6Details{
-			VnicId:         &vnicId,
-			Ipv6SubnetCidr: &ipv6SubnetCidr,
+			VnicId: &vnicId,
 		},
 	}
+	// 为空时由 OCI 从子网的 IPv6 前缀中分配
+	if ipv6SubnetCidr != "" {
+		req.Ipv6SubnetCidr = &ipv6SubnetCidr
+	}
 
 	_, err = networkClient.CreateIpv6(context.Background(), req)
 	if err != nil {
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// Ipv6Info VNIC 上的 IPv6 地址
+type Ipv6Info struct {
+	ID          string `json:"id"`
+	IpAddress   string `json:"ipAddress"`
+	VnicID      string `json:"vnicId"`
+	SubnetID    string `json:"subnetId"`
+	State       string `json:"state"`
+	Lifetime    string `json:"lifetime"` // EPHEMERAL, RESERVED
+	TimeCreated string `json:"timeCreated"`
+}
+
+// SubnetIpv6Result 子网启用 IPv6 的结果
+type SubnetIpv6Result struct {
+	SubnetID       string   `json:"subnetId"`
+	Ipv6CidrBlocks []string `json:"ipv6CidrBlocks"`
+	RouteAdded     bool     `json:"routeAdded"` // 是否为路由表补充了 ::/0 到互联网网关的规则
+}
+
+func newIpv6Info(ip core.Ipv6) Ipv6Info {
+	info := Ipv6Info{
+		ID:        stringValue(ip.Id),
+		IpAddress: stringValue(ip.IpAddress),
+		VnicID:    stringValue(ip.VnicId),
+		SubnetID:  stringValue(ip.SubnetId),
+		State:     string(ip.LifecycleState),
+		Lifetime:  string(ip.Lifetime),
+	}
+	if ip.TimeCreated != nil {
+		info.TimeCreated = ip.TimeCreated.Format("2006-01-02 15:04:05")
+	}
+	return info
+}
+
+// ListIpv6 列出 VNIC 或子网上的 IPv6 地址，vnicId 与 subnetId 二选一
+func (s *IpService) ListIpv6(userId, vnicId, subnetId, region string) ([]Ipv6Info, error) {
+	if vnicId == "" && subnetId == "" {
+		return nil, fmt.Errorf("请指定 VNIC 或子网")
+	}
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	req := core.ListIpv6sRequest{}
+	if vnicId != "" {
+		req.VnicId = &vnicId
+	} else {
+		req.SubnetId = &subnetId
+	}
+	addresses := []Ipv6Info{}
+	for {
+		resp, err := client.ListIpv6s(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("获取 IPv6 地址失败: %s", extractOCIErrorMessage(err))
+		}
+		for _, ip := range resp.Items {
+			addresses = append(addresses, newIpv6Info(ip))
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return addresses, nil
+}
+
+// DetachIpv6 删除 VNIC 上的 IPv6 地址
+func (s *IpService) DetachIpv6(userId, ipv6Id, region string) error {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.DeleteIpv6(ctx, core.DeleteIpv6Request{Ipv6Id: &ipv6Id}); err != nil {
+		return fmt.Errorf("删除 IPv6 地址失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// RotateIpv6 在同一 VNIC 上分配新的 IPv6 地址后删除原地址，返回新地址
+// 先分配后删除，更换过程中 VNIC 始终保留一个可用的 IPv6 地址
+func (s *IpService) RotateIpv6(userId, ipv6Id, region string) (*Ipv6Info, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	old, err := client.GetIpv6(ctx, core.GetIpv6Request{Ipv6Id: &ipv6Id})
+	if err != nil {
+		return nil, fmt.Errorf("获取 IPv6 地址失败: %s", extractOCIErrorMessage(err))
+	}
+	if old.VnicId == nil {
+		return nil, fmt.Errorf("IPv6 地址未分配给 VNIC")
+	}
+	if old.Lifetime == core.Ipv6LifetimeReserved {
+		return nil, fmt.Errorf("预留 IPv6 地址不支持更换")
+	}
+
+	details := core.CreateIpv6Details{VnicId: old.VnicId}
+	// 子网有多个 /64 前缀时，新地址与原地址保持在同一前缀
+	if subnet, err := client.GetSubnet(ctx, core.GetSubnetRequest{SubnetId: old.SubnetId}); err == nil {
+		if prefix := ipv6PrefixContaining(subnet.Ipv6CidrBlocks, stringValue(old.IpAddress)); prefix != "" {
+			details.Ipv6SubnetCidr = &prefix
+		}
+	}
+	created, err := client.CreateIpv6(ctx, core.CreateIpv6Request{CreateIpv6Details: details})
+	if err != nil {
+		return nil, fmt.Errorf("分配新 IPv6 地址失败: %s", extractOCIErrorMessage(err))
+	}
+	if _, err := client.DeleteIpv6(ctx, core.DeleteIpv6Request{Ipv6Id: &ipv6Id}); err != nil {
+		return nil, fmt.Errorf("已分配新地址 %s，但删除原地址失败: %s", stringValue(created.IpAddress), extractOCIErrorMessage(err))
+	}
+	info := newIpv6Info(created.Ipv6)
+	return &info, nil
+}
+
+// EnableVcnIpv6 为 VCN 分配 Oracle 提供的 /56 IPv6 前缀，已启用时直接返回现有前缀
+func (s *IpService) EnableVcnIpv6(userId, vcnId, region string) ([]string, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	vcn, err := client.GetVcn(ctx, core.GetVcnRequest{VcnId: &vcnId})
+	if err != nil {
+		return nil, fmt.Errorf("获取 VCN 失败: %s", extractOCIErrorMessage(err))
+	}
+	if len(vcn.Ipv6CidrBlocks) > 0 {
+		return vcn.Ipv6CidrBlocks, nil
+	}
+
+	if _, err := client.AddIpv6VcnCidr(ctx, core.AddIpv6VcnCidrRequest{
+		VcnId:                 &vcnId,
+		AddVcnIpv6CidrDetails: core.AddVcnIpv6CidrDetails{IsOracleGuaAllocationEnabled: common.Bool(true)},
+	}); err != nil {
+		return nil, fmt.Errorf("VCN 启用 IPv6 失败: %s", extractOCIErrorMessage(err))
+	}
+	for i := 0; i < 60; i++ {
+		time.Sleep(3 * time.Second)
+		vcn, err := client.GetVcn(ctx, core.GetVcnRequest{VcnId: &vcnId})
+		if err != nil {
+			return nil, fmt.Errorf("获取 VCN 失败: %s", extractOCIErrorMessage(err))
+		}
+		if len(vcn.Ipv6CidrBlocks) > 0 {
+			return vcn.Ipv6CidrBlocks, nil
+		}
+	}
+	return nil, fmt.Errorf("等待 VCN 分配 IPv6 前缀超时")
+}
+
+// EnableSubnetIpv6 为子网分配 VCN 前缀中的 /64，prefix 为空时自动选择未被其他子网使用的 /64
+// 子网路由表只有 IPv4 默认路由指向互联网网关时，同时补充 ::/0 路由；安全列表规则需另行配置
+func (s *IpService) EnableSubnetIpv6(userId, subnetId, prefix, region string) (*SubnetIpv6Result, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	subnet, err := client.GetSubnet(ctx, core.GetSubnetRequest{SubnetId: &subnetId})
+	if err != nil {
+		return nil, fmt.Errorf("获取子网失败: %s", extractOCIErrorMessage(err))
+	}
+	blocks := subnet.Ipv6CidrBlocks
+
+	if len(blocks) == 0 || (prefix != "" && !ipv6PrefixListed(blocks, prefix)) {
+		vcn, err := client.GetVcn(ctx, core.GetVcnRequest{VcnId: subnet.VcnId})
+		if err != nil {
+			return nil, fmt.Errorf("获取 VCN 失败: %s", extractOCIErrorMessage(err))
+		}
+		if len(vcn.Ipv6CidrBlocks) == 0 {
+			return nil, fmt.Errorf("VCN 未启用 IPv6，请先为 VCN 启用 IPv6")
+		}
+		if prefix == "" {
+			subnets, err := client.ListSubnets(ctx, core.ListSubnetsRequest{
+				CompartmentId: subnet.CompartmentId,
+				VcnId:         subnet.VcnId,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("获取子网列表失败: %s", extractOCIErrorMessage(err))
+			}
+			var used []string
+			for _, item := range subnets.Items {
+				used = append(used, item.Ipv6CidrBlocks...)
+			}
+			prefix, err = nextFreeIpv6Prefix(vcn.Ipv6CidrBlocks[0], used)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := client.AddIpv6SubnetCidr(ctx, core.AddIpv6SubnetCidrRequest{
+			SubnetId:                 &subnetId,
+			AddSubnetIpv6CidrDetails: core.AddSubnetIpv6CidrDetails{Ipv6CidrBlock: &prefix},
+		}); err != nil {
+			return nil, fmt.Errorf("子网启用 IPv6 失败: %s", extractOCIErrorMessage(err))
+		}
+		blocks = nil
+		for i := 0; i < 60 && len(blocks) == 0; i++ {
+			time.Sleep(3 * time.Second)
+			resp, err := client.GetSubnet(ctx, core.GetSubnetRequest{SubnetId: &subnetId})
+			if err != nil {
+				return nil, fmt.Errorf("获取子网失败: %s", extractOCIErrorMessage(err))
+			}
+			if ipv6PrefixListed(resp.Ipv6CidrBlocks, prefix) {
+				blocks = resp.Ipv6CidrBlocks
+			}
+		}
+		if len(blocks) == 0 {
+			return nil, fmt.Errorf("等待子网分配 IPv6 前缀超时")
+		}
+	}
+
+	result := &SubnetIpv6Result{SubnetID: subnetId, Ipv6CidrBlocks: blocks}
+	if subnet.RouteTableId != nil {
+		result.RouteAdded, err = addIpv6DefaultRoute(ctx, client, *subnet.RouteTableId)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// addIpv6DefaultRoute 路由表的 IPv4 默认路由指向互联网网关且没有 ::/0 路由时，补充指向同一网关的 ::/0 路由
+func addIpv6DefaultRoute(ctx context.Context, client core.VirtualNetworkClient, routeTableId string) (bool, error) {
+	table, err := client.GetRouteTable(ctx, core.GetRouteTableRequest{RtId: &routeTableId})
+	if err != nil {
+		return false, fmt.Errorf("获取路由表失败: %s", extractOCIErrorMessage(err))
+	}
+	var gateway *string
+	for _, rule := range table.RouteRules {
+		destination := stringValue(rule.Destination)
+		if destination == "::/0" {
+			return false, nil
+		}
+		if destination == "0.0.0.0/0" && strings.HasPrefix(stringValue(rule.NetworkEntityId), "ocid1.internetgateway.") {
+			gateway = rule.NetworkEntityId
+		}
+	}
+	if gateway == nil {
+		return false, nil
+	}
+
+	rules := append(table.RouteRules, core.RouteRule{
+		Destination:     common.String("::/0"),
+		DestinationType: core.RouteRuleDestinationTypeCidrBlock,
+		NetworkEntityId: gateway,
+	})
+	if _, err := client.UpdateRouteTable(ctx, core.UpdateRouteTableRequest{
+		RtId:                    &routeTableId,
+		UpdateRouteTableDetails: core.UpdateRouteTableDetails{RouteRules: rules},
+	}); err != nil {
+		return false, fmt.Errorf("添加 IPv6 默认路由失败: %s", extractOCIErrorMessage(err))
+	}
+	return true, nil
+}
+
+// nextFreeIpv6Prefix 返回 VCN 前缀中第一个与已用前缀不重叠的 /64
+func nextFreeIpv6Prefix(vcnCidr string, used []string) (string, error) {
+	vcnPrefix, err := netip.ParsePrefix(vcnCidr)
+	if err != nil || !vcnPrefix.Addr().Is6() {
+		return "", fmt.Errorf("VCN IPv6 前缀无效: %s", vcnCidr)
+	}
+	if vcnPrefix.Bits() > 64 {
+		return "", fmt.Errorf("VCN IPv6 前缀 %s 小于 /64", vcnCidr)
+	}
+	var usedPrefixes []netip.Prefix
+	for _, cidr := range used {
+		if p, err := netip.ParsePrefix(cidr); err == nil {
+			usedPrefixes = append(usedPrefixes, p)
+		}
+	}
+
+	base := vcnPrefix.Masked().Addr().As16()
+	high := binary.BigEndian.Uint64(base[:8])
+	count := uint64(1) << (64 - vcnPrefix.Bits())
+	for i := uint64(0); i < count; i++ {
+		var addr [16]byte
+		binary.BigEndian.PutUint64(addr[:8], high+i)
+		candidate := netip.PrefixFrom(netip.AddrFrom16(addr), 64)
+		free := true
+		for _, p := range usedPrefixes {
+			if candidate.Overlaps(p) {
+				free = false
+				break
+			}
+		}
+		if free {
+			return candidate.String(), nil
+		}
+	}
+	return "", fmt.Errorf("VCN IPv6 前缀 %s 中没有可用的 /64", vcnCidr)
+}
+
+// ipv6PrefixContaining 返回包含该地址的前缀，没有时返回空
+func ipv6PrefixContaining(prefixes []string, address string) string {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return ""
+	}
+	for _, cidr := range prefixes {
+		if p, err := netip.ParsePrefix(cidr); err == nil && p.Contains(addr) {
+			return cidr
+		}
+	}
+	return ""
+}
+
+// ipv6PrefixListed 检查前缀是否已在列表中，忽略书写格式的差异
+func ipv6PrefixListed(prefixes []string, prefix string) bool {
+	want, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return false
+	}
+	for _, cidr := range prefixes {
+		if p, err := netip.ParsePrefix(cidr); err == nil && p.Masked() == want.Masked() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package services
+
+import "testing"
+
+func TestNextFreeIpv6Prefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		vcn     string
+		used    []string
+		want    string
+		wantErr bool
+	}{
+		{"没有已用前缀", "2603:c020:4000:1a00::/56", nil, "2603:c020:4000:1a00::/64", false},
+		{"跳过已用前缀", "2603:c020:4000:1a00::/56", []string{"2603:c020:4000:1a00::/64", "2603:c020:4000:1a01::/64"}, "2603:c020:4000:1a02::/64", false},
+		{"已用前缀不连续", "2603:c020:4000:1a00::/56", []string{"2603:c020:4000:1a01::/64"}, "2603:c020:4000:1a00::/64", false},
+		{"忽略其他 VCN 的前缀", "2603:c020:4000:1a00::/56", []string{"2603:c020:4000:1b00::/64"}, "2603:c020:4000:1a00::/64", false},
+		{"/64 的 VCN 已用完", "2603:c020:4000:1a00::/64", []string{"2603:c020:4000:1a00::/64"}, "", true},
+		{"IPv4 前缀", "10.0.0.0/16", nil, "", true},
+		{"格式无效", "abc", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextFreeIpv6Prefix(tt.vcn, tt.used)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nextFreeIpv6Prefix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("nextFreeIpv6Prefix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIpv6PrefixContaining(t *testing.T) {
+	prefixes := []string{"2603:c020:4000:1a00::/64", "2603:c020:4000:1a01::/64"}
+	tests := []struct {
+		name    string
+		address string
+		want    string
+	}{
+		{"第一个前缀", "2603:c020:4000:1a00::1234", "2603:c020:4000:1a00::/64"},
+		{"第二个前缀", "2603:c020:4000:1a01:abcd::1", "2603:c020:4000:1a01::/64"},
+		{"不在任何前缀", "2603:c020:4000:1a02::1", ""},
+		{"地址无效", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ipv6PrefixContaining(prefixes, tt.address); got != tt.want {
+				t.Errorf("ipv6PrefixContaining(%q) = %q, want %q", tt.address, got, tt.want)
+			}
+		})
+	}
+}
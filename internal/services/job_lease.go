@@ -0,0 +1,49 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// jobLeaseTTL 作业租约有效期，持有者每次检查时续约，宕机后其它实例在两个检查间隔后接管
+func jobLeaseTTL(interval time.Duration) time.Duration {
+	return 2*interval + time.Minute
+}
+
+// SetNodeID 设置当前实例标识，与任务租约使用同一标识
+func (s *JobService) SetNodeID(nodeID string) {
+	if nodeID != "" {
+		s.nodeID = nodeID
+	}
+}
+
+// acquireJobLease 尝试取得或续约作业的执行权，其它实例持有未过期的租约时返回 false
+func (s *JobService) acquireJobLease(name string, interval time.Duration) bool {
+	db := database.GetDB()
+	now := time.Now()
+	expireAt := now.Add(jobLeaseTTL(interval))
+
+	result := db.Model(&models.JobLease{}).
+		Where("job_name = ? AND (owner = ? OR expire_at < ?)", name, s.nodeID, now).
+		Updates(map[string]interface{}{"owner": s.nodeID, "expire_at": expireAt})
+	if result.Error == nil && result.RowsAffected > 0 {
+		return true
+	}
+
+	result = db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.JobLease{JobName: name, Owner: s.nodeID, ExpireAt: expireAt})
+	if result.Error != nil {
+		log.Printf("[Jobs] Failed to acquire lease for %s: %v", name, result.Error)
+		return false
+	}
+	return result.RowsAffected > 0
+}
+
+// releaseJobLeases 释放当前实例持有的所有作业租约，使其它实例可以立即接管
+func (s *JobService) releaseJobLeases() {
+	database.GetDB().Where("owner = ?", s.nodeID).Delete(&models.JobLease{})
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	JobStatusRunning = "running"
+	JobStatusSuccess = "success"
+	JobStatusFailed  = "failed"
+
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+
+	// 作业运行记录保留天数
+	HousekeepingJobRunRetentionDays = 30
+)
+
+// Job 由作业框架统一调度的后台作业
+type Job interface {
+	// Name 作业唯一标识，用于运行记录与接口
+	Name() string
+	Description() string
+	// Interval 检查间隔，实现 JobDueChecker 时每次检查由 IsDue 决定是否执行
+	Interval() time.Duration
+	// Run 执行一次作业，返回结果摘要
+	Run(ctx context.Context) (string, error)
+}
+
+// JobDueChecker 按自身记录的上次执行时间决定是否到期的作业
+type JobDueChecker interface {
+	IsDue() bool
+}
+
+// JobOptions 作业的重试与超时设置
+type JobOptions struct {
+	MaxRetries int           // 失败后的重试次数
+	RetryDelay time.Duration // 首次重试前的等待时间，之后每次翻倍
+	Timeout    time.Duration // 单次执行超时，为 0 时不限制
+}
+
+// FuncJob 使用函数定义的作业
+type FuncJob struct {
+	JobName        string
+	JobDescription string
+	CheckInterval  time.Duration
+	Due            func() bool // 为空时每次检查都执行
+	RunFunc        func(ctx context.Context) (string, error)
+}
+
+func (j *FuncJob) Name() string            { return j.JobName }
+func (j *FuncJob) Description() string     { return j.JobDescription }
+func (j *FuncJob) Interval() time.Duration { return j.CheckInterval }
+
+func (j *FuncJob) IsDue() bool {
+	return j.Due == nil || j.Due()
+}
+
+func (j *FuncJob) Run(ctx context.Context) (string, error) {
+	return j.RunFunc(ctx)
+}
+
+// JobStatus 作业当前状态
+type JobStatus struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Interval    string         `json:"interval"`
+	MaxRetries  int            `json:"maxRetries"`
+	Running     bool           `json:"running"`
+	NextCheck   string         `json:"nextCheck"` // 下次检查时间，到期的作业在该时间执行
+	LastRun     *models.JobRun `json:"lastRun"`
+}
+
+type registeredJob struct {
+	job       Job
+	options   JobOptions
+	running   bool
+	nextCheck time.Time
+}
+
+type JobService struct {
+	jobs     map[string]*registeredJob
+	order    []string
+	stopChan chan struct{}
+	running  bool
+	mutex    sync.Mutex
+	nodeID   string // 当前实例标识，用于作业租约与运行记录
+}
+
+func NewJobService() *JobService {
+	return &JobService{
+		jobs:     make(map[string]*registeredJob),
+		stopChan: make(chan struct{}),
+		nodeID:   defaultTaskNodeID(),
+	}
+}
+
+// Register 注册作业，需在 Start 之前调用
+func (s *JobService) Register(job Job, options JobOptions) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.jobs[job.Name()]; ok {
+		log.Printf("[Jobs] Job %s already registered", job.Name())
+		return
+	}
+	s.jobs[job.Name()] = &registeredJob{job: job, options: options}
+	s.order = append(s.order, job.Name())
+}
+
+func (s *JobService) Start() {
+	s.mutex.Lock()
+	if s.running {
+		s.mutex.Unlock()
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	entries := make([]*registeredJob, 0, len(s.order))
+	for _, name := range s.order {
+		entries = append(entries, s.jobs[name])
+	}
+	s.mutex.Unlock()
+
+	// 本实例上次退出时仍在执行的记录不会再结束，其它实例的记录由其自身处理
+	database.GetDB().Model(&models.JobRun{}).Where("status = ? AND (node = ? OR node = '' OR node IS NULL)", JobStatusRunning, s.nodeID).
+		Updates(map[string]interface{}{"status": JobStatusFailed, "message": "服务重启，执行被中断"})
+
+	for _, entry := range entries {
+		go s.loop(entry)
+	}
+	log.Printf("Job service started with %d jobs", len(entries))
+}
+
+func (s *JobService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+	s.releaseJobLeases()
+	log.Println("Job service stopped")
+}
+
+func (s *JobService) loop(entry *registeredJob) {
+	interval := entry.job.Interval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.mutex.Lock()
+	entry.nextCheck = time.Now().Add(interval)
+	stopChan := s.stopChan
+	s.mutex.Unlock()
+
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+			s.mutex.Lock()
+			entry.nextCheck = time.Now().Add(interval)
+			s.mutex.Unlock()
+
+			// 多个实例共享数据库时，只有持有租约的实例按计划执行作业
+			if !s.acquireJobLease(entry.job.Name(), interval) {
+				continue
+			}
+			if checker, ok := entry.job.(JobDueChecker); ok && !checker.IsDue() {
+				continue
+			}
+			if s.begin(entry) {
+				s.execute(entry, JobTriggerSchedule, stopChan)
+			}
+		}
+	}
+}
+
+// begin 标记作业开始执行，作业已在执行时返回 false
+func (s *JobService) begin(entry *registeredJob) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry.running {
+		return false
+	}
+	entry.running = true
+	return true
+}
+
+// execute 执行作业并按设置重试，运行结果写入运行记录
+func (s *JobService) execute(entry *registeredJob, trigger string, stopChan chan struct{}) {
+	defer func() {
+		s.mutex.Lock()
+		entry.running = false
+		s.mutex.Unlock()
+	}()
+
+	db := database.GetDB()
+	name := entry.job.Name()
+	run := models.JobRun{
+		ID:        uuid.New().String(),
+		JobName:   name,
+		Trigger:   trigger,
+		Node:      s.nodeID,
+		Status:    JobStatusRunning,
+		StartTime: time.Now(),
+	}
+	db.Create(&run)
+
+	delay := entry.options.RetryDelay
+	var message string
+	var err error
+retry:
+	for attempt := 1; ; attempt++ {
+		run.Attempts = attempt
+		message, err = s.runOnce(entry)
+		if err == nil {
+			break
+		}
+		log.Printf("[Jobs] %s attempt %d failed: %v", name, attempt, err)
+		if attempt > entry.options.MaxRetries {
+			break
+		}
+		select {
+		case <-stopChan:
+			err = fmt.Errorf("服务停止，放弃重试: %w", err)
+			break retry
+		case <-time.After(delay):
+			delay *= 2
+		}
+	}
+
+	endTime := time.Now()
+	run.EndTime = &endTime
+	run.DurationMs = endTime.Sub(run.StartTime).Milliseconds()
+	run.Status = JobStatusSuccess
+	run.Message = message
+	if err != nil {
+		run.Status = JobStatusFailed
+		run.Message = err.Error()
+	}
+	db.Save(&run)
+}
+
+// runOnce 执行一次作业，作业 panic 时转换为错误
+func (s *JobService) runOnce(entry *registeredJob) (message string, err error) {
+	ctx := context.Background()
+	if entry.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, entry.options.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("作业异常: %v", r)
+		}
+	}()
+	return entry.job.Run(ctx)
+}
+
+// RunNow 立即在后台执行作业，不检查是否到期
+func (s *JobService) RunNow(name string) error {
+	s.mutex.Lock()
+	entry, ok := s.jobs[name]
+	stopChan := s.stopChan
+	s.mutex.Unlock()
+	if !ok {
+		return fmt.Errorf("作业不存在: %s", name)
+	}
+	if !s.begin(entry) {
+		return fmt.Errorf("作业正在执行")
+	}
+	go s.execute(entry, JobTriggerManual, stopChan)
+	return nil
+}
+
+// ListJobs 获取已注册作业的状态与最近一次运行记录
+func (s *JobService) ListJobs() []JobStatus {
+	s.mutex.Lock()
+	list := make([]JobStatus, 0, len(s.order))
+	for _, name := range s.order {
+		entry := s.jobs[name]
+		status := JobStatus{
+			Name:        name,
+			Description: entry.job.Description(),
+			Interval:    entry.job.Interval().String(),
+			MaxRetries:  entry.options.MaxRetries,
+			Running:     entry.running,
+		}
+		if !entry.nextCheck.IsZero() {
+			status.NextCheck = entry.nextCheck.Format("2006-01-02 15:04:05")
+		}
+		list = append(list, status)
+	}
+	s.mutex.Unlock()
+
+	db := database.GetDB()
+	for i := range list {
+		var run models.JobRun
+		if err := db.Where("job_name = ?", list[i].Name).Order("start_time DESC").First(&run).Error; err == nil {
+			list[i].LastRun = &run
+		}
+	}
+	return list
+}
+
+// GetJobHistory 分页获取作业运行记录，name 为空时返回所有作业
+func GetJobHistory(name string, page, pageSize int) ([]models.JobRun, int64, error) {
+	query := database.GetDB().Model(&models.JobRun{})
+	if name != "" {
+		query = query.Where("job_name = ?", name)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var runs []models.JobRun
+	err := query.Order("start_time DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&runs).Error
+	return runs, total, err
+}
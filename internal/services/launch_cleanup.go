@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SettingLaunchCleanupEnabled = "launch_cleanup_enabled"
+
+	LaunchDebrisInstance   = "instance"
+	LaunchDebrisBootVolume = "bootVolume"
+
+	LaunchAttemptPending = "pending"
+	LaunchAttemptCleaned = "cleaned"
+	LaunchAttemptFailed  = "failed"
+
+	// 实例提交后超过该时间仍在创建中视为创建失败
+	launchDebrisMinAge = time.Hour
+	// 提交后检查实例是否进入运行状态的间隔
+	launchAttemptWatchPoll = 30 * time.Second
+	// 超过该时间仍无法确定结果的记录不再跟踪
+	launchAttemptTrackDuration = 7 * 24 * time.Hour
+	// 清理记录的保留时间
+	launchAttemptRetention = 30 * 24 * time.Hour
+)
+
+// LaunchDebris 开机失败后残留的资源
+type LaunchDebris struct {
+	ConfigID    string `json:"configId"`
+	Username    string `json:"username"`
+	TaskID      string `json:"taskId"`
+	Region      string `json:"region"`
+	Type        string `json:"type"` // instance / bootVolume
+	ResourceID  string `json:"resourceId"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	TimeCreated string `json:"timeCreated"`
+	Reason      string `json:"reason"`
+	Removed     bool   `json:"removed"`
+	Error       string `json:"error,omitempty"`
+}
+
+// LaunchCleanupService 跟踪开机任务提交的实例，清理创建失败后残留的实例与引导卷
+type LaunchCleanupService struct {
+	ociService *OCIService
+}
+
+func NewLaunchCleanupService(ociService *OCIService) *LaunchCleanupService {
+	return &LaunchCleanupService{ociService: ociService}
+}
+
+// IsLaunchCleanupEnabled 是否自动清理残留资源，清理会删除资源，默认关闭
+func IsLaunchCleanupEnabled() bool {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingLaunchCleanupEnabled).First(&setting).Error; err != nil {
+		return false
+	}
+	return setting.Value == "true"
+}
+
+// SetLaunchCleanupEnabled 开启或关闭自动清理
+func SetLaunchCleanupEnabled(enabled bool) error {
+	return saveSetting(SettingLaunchCleanupEnabled, fmt.Sprintf("%t", enabled))
+}
+
+// trackLaunchAttempt 开启自动清理时记录开机任务提交成功的实例，并在后台等待其进入运行状态
+// 实例一旦运行过就不再跟踪，之后终止时保留的引导卷不会被当作残留资源
+func (s *TaskService) trackLaunchAttempt(taskID string, user models.OciUser, region string, instance *core.Instance) {
+	if instance == nil || instance.Id == nil || !IsLaunchCleanupEnabled() {
+		return
+	}
+	recordLaunchAttempt(taskID, user.ID, region, instance)
+
+	go func() {
+		regionUser := user
+		regionUser.OciRegion = region
+		instanceID := *instance.Id
+		ctx, cancel := context.WithTimeout(context.Background(), launchDebrisMinAge)
+		defer cancel()
+		for {
+			current, err := s.ociService.GetInstance(ctx, &regionUser, instanceID)
+			if err == nil {
+				switch current.LifecycleState {
+				case core.InstanceLifecycleStateProvisioning:
+				case core.InstanceLifecycleStateTerminating, core.InstanceLifecycleStateTerminated:
+					return
+				default:
+					database.GetDB().Where("instance_id = ?", instanceID).Delete(&models.LaunchAttempt{})
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				// 超时仍未运行，由清理作业继续跟踪
+				return
+			case <-time.After(launchAttemptWatchPoll):
+			}
+		}
+	}()
+}
+
+// recordLaunchAttempt 记录开机任务提交成功的实例，实例进入运行状态前由清理作业跟踪
+func recordLaunchAttempt(taskID, configID, region string, instance *core.Instance) {
+	if instance == nil || instance.Id == nil {
+		return
+	}
+	attempt := models.LaunchAttempt{
+		InstanceID:    *instance.Id,
+		ConfigID:      configID,
+		TaskID:        taskID,
+		Region:        region,
+		CompartmentID: common.PointerString(instance.CompartmentId),
+		DisplayName:   common.PointerString(instance.DisplayName),
+		Status:        LaunchAttemptPending,
+	}
+	if err := database.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&attempt).Error; err != nil {
+		log.Printf("[LaunchCleanup] Failed to record launch attempt %s: %v", *instance.Id, err)
+	}
+}
+
+// Job 每小时检查一次跟踪中的开机记录并清理残留资源
+func (s *LaunchCleanupService) Job() Job {
+	return &FuncJob{
+		JobName:        "launch_cleanup",
+		JobDescription: "清理开机失败后卡在创建中的实例与遗留的引导卷",
+		CheckInterval:  time.Hour,
+		Due:            IsLaunchCleanupEnabled,
+		RunFunc: func(ctx context.Context) (string, error) {
+			items, err := s.Scan(ctx, true)
+			if err != nil {
+				return "", err
+			}
+			return summarizeLaunchDebris(items)
+		},
+	}
+}
+
+// summarizeLaunchDebris 汇总清理结果，全部删除失败时返回错误
+func summarizeLaunchDebris(items []LaunchDebris) (string, error) {
+	if len(items) == 0 {
+		return "未发现残留资源", nil
+	}
+	var removed, failed []string
+	for _, item := range items {
+		if item.Removed {
+			removed = append(removed, fmt.Sprintf("%s/%s %s", item.Username, item.Region, item.Name))
+		} else {
+			failed = append(failed, fmt.Sprintf("%s/%s %s: %s", item.Username, item.Region, item.Name, item.Error))
+		}
+	}
+	result := fmt.Sprintf("已清理 %d 个", len(removed))
+	if len(removed) > 0 {
+		result += "：" + strings.Join(removed, ", ")
+	}
+	if len(failed) > 0 {
+		result += fmt.Sprintf("；失败 %d 个：%s", len(failed), strings.Join(failed, ", "))
+		if len(removed) == 0 {
+			return result, fmt.Errorf("%s", result)
+		}
+	}
+	return result, nil
+}
+
+// Scan 检查跟踪中的开机记录，找出创建失败后残留的资源，remove 为 true 时删除并更新记录
+// 实例进入运行状态后不再跟踪，其引导卷即使之后被保留也不会被清理
+func (s *LaunchCleanupService) Scan(ctx context.Context, remove bool) ([]LaunchDebris, error) {
+	db := database.GetDB()
+	if remove {
+		db.Where("status <> ? AND update_time < ?", LaunchAttemptPending, time.Now().Add(-launchAttemptRetention)).
+			Delete(&models.LaunchAttempt{})
+	}
+
+	var attempts []models.LaunchAttempt
+	if err := db.Where("status = ?", LaunchAttemptPending).Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]*models.OciUser)
+	items := []LaunchDebris{}
+	for _, attempt := range attempts {
+		if ctx.Err() != nil {
+			break
+		}
+		user, ok := users[attempt.ConfigID]
+		if !ok {
+			var u models.OciUser
+			if err := db.Where("id = ?", attempt.ConfigID).First(&u).Error; err == nil {
+				user = &u
+			}
+			users[attempt.ConfigID] = user
+		}
+		if user == nil {
+			if remove {
+				db.Delete(&attempt)
+			}
+			continue
+		}
+
+		regionUser := *user
+		regionUser.OciRegion = attempt.Region
+		found, done, err := s.checkAttempt(ctx, &regionUser, attempt)
+		if err != nil {
+			log.Printf("[LaunchCleanup] Failed to check instance %s for %s [%s]: %v", attempt.InstanceID, user.Username, attempt.Region, err)
+			continue
+		}
+		if !remove {
+			items = append(items, found...)
+			continue
+		}
+
+		if len(found) == 0 {
+			// 实例已正常运行，或超过跟踪时间仍无法确定结果
+			if done || time.Since(attempt.CreateTime) > launchAttemptTrackDuration {
+				db.Delete(&attempt)
+			}
+			continue
+		}
+		status := LaunchAttemptCleaned
+		var messages []string
+		for i := range found {
+			s.removeDebris(ctx, &regionUser, &found[i])
+			if found[i].Removed {
+				messages = append(messages, fmt.Sprintf("已删除%s %s：%s", launchDebrisTypeName(found[i].Type), found[i].Name, found[i].Reason))
+			} else {
+				status = LaunchAttemptFailed
+				messages = append(messages, fmt.Sprintf("删除%s %s 失败：%s", launchDebrisTypeName(found[i].Type), found[i].Name, found[i].Error))
+			}
+		}
+		message := strings.Join(messages, "；")
+		if status == LaunchAttemptCleaned || time.Since(attempt.CreateTime) > launchAttemptTrackDuration {
+			db.Model(&attempt).Updates(map[string]interface{}{"status": status, "message": message})
+		}
+		if attempt.TaskID != "" {
+			db.Create(&models.TaskLog{ID: uuid.New().String(), TaskID: attempt.TaskID, Status: "cleanup", Message: message, ExecuteTime: time.Now()})
+		}
+		items = append(items, found...)
+	}
+	return items, nil
+}
+
+func launchDebrisTypeName(debrisType string) string {
+	if debrisType == LaunchDebrisBootVolume {
+		return "引导卷"
+	}
+	return "实例"
+}
+
+// checkAttempt 检查一次开机记录，返回残留资源；done 表示实例已正常创建，无需继续跟踪
+func (s *LaunchCleanupService) checkAttempt(ctx context.Context, user *models.OciUser, attempt models.LaunchAttempt) ([]LaunchDebris, bool, error) {
+	debris := func(item LaunchDebris) LaunchDebris {
+		item.ConfigID = attempt.ConfigID
+		item.Username = user.Username
+		item.TaskID = attempt.TaskID
+		item.Region = attempt.Region
+		return item
+	}
+
+	instance, err := s.ociService.GetInstance(ctx, user, attempt.InstanceID)
+	gone := false
+	if err != nil {
+		serviceErr, ok := common.IsServiceError(err)
+		if !ok || serviceErr.GetHTTPStatusCode() != 404 {
+			return nil, false, err
+		}
+		gone = true
+	}
+
+	if !gone {
+		switch instance.LifecycleState {
+		case core.InstanceLifecycleStateProvisioning:
+			if instance.TimeCreated == nil || time.Since(instance.TimeCreated.Time) < launchDebrisMinAge {
+				return nil, false, nil
+			}
+			return []LaunchDebris{debris(LaunchDebris{
+				Type:        LaunchDebrisInstance,
+				ResourceID:  attempt.InstanceID,
+				Name:        common.PointerString(instance.DisplayName),
+				State:       string(instance.LifecycleState),
+				TimeCreated: instance.TimeCreated.Format("2006-01-02 15:04:05"),
+				Reason:      fmt.Sprintf("提交后超过 %d 分钟仍在创建中", int(launchDebrisMinAge.Minutes())),
+			})}, false, nil
+		case core.InstanceLifecycleStateTerminating, core.InstanceLifecycleStateTerminated:
+		default:
+			return nil, true, nil
+		}
+	}
+
+	// 实例未能运行就已终止，检查 OCI 是否遗留了引导卷
+	volumes, err := s.unattachedBootVolumes(ctx, user, attempt.CompartmentID, attempt.DisplayName+" (Boot Volume)")
+	if err != nil {
+		return nil, false, err
+	}
+	var items []LaunchDebris
+	for _, bv := range volumes {
+		// 只处理本次提交前后创建的同名引导卷
+		if bv.TimeCreated == nil || bv.TimeCreated.Before(attempt.CreateTime.Add(-10*time.Minute)) {
+			continue
+		}
+		items = append(items, debris(LaunchDebris{
+			Type:        LaunchDebrisBootVolume,
+			ResourceID:  *bv.Id,
+			Name:        common.PointerString(bv.DisplayName),
+			State:       string(bv.LifecycleState),
+			TimeCreated: bv.TimeCreated.Format("2006-01-02 15:04:05"),
+			Reason:      "实例创建失败后遗留，未挂载到任何实例",
+		}))
+	}
+	return items, len(items) == 0, nil
+}
+
+// unattachedBootVolumes 列出区间内指定名称、可用且未挂载的引导卷
+func (s *LaunchCleanupService) unattachedBootVolumes(ctx context.Context, user *models.OciUser, compartmentID, name string) ([]core.BootVolume, error) {
+	storageClient, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, err
+	}
+	computeClient, err := s.ociService.GetComputeClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []core.BootVolume
+	req := core.ListBootVolumesRequest{CompartmentId: &compartmentID}
+	for {
+		resp, err := storageClient.ListBootVolumes(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, bv := range resp.Items {
+			if bv.Id != nil && bv.AvailabilityDomain != nil && common.PointerString(bv.DisplayName) == name &&
+				bv.LifecycleState == core.BootVolumeLifecycleStateAvailable {
+				volumes = append(volumes, bv)
+			}
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+
+	// 挂载记录需要按可用域查询
+	attached := make(map[string]bool)
+	queried := make(map[string]bool)
+	for _, bv := range volumes {
+		ad := *bv.AvailabilityDomain
+		if queried[ad] {
+			continue
+		}
+		queried[ad] = true
+		attachReq := core.ListBootVolumeAttachmentsRequest{AvailabilityDomain: &ad, CompartmentId: &compartmentID}
+		for {
+			resp, err := computeClient.ListBootVolumeAttachments(ctx, attachReq)
+			if err != nil {
+				return nil, err
+			}
+			for _, attachment := range resp.Items {
+				if attachment.BootVolumeId != nil && attachment.LifecycleState != core.BootVolumeAttachmentLifecycleStateDetached {
+					attached[*attachment.BootVolumeId] = true
+				}
+			}
+			if resp.OpcNextPage == nil {
+				break
+			}
+			attachReq.Page = resp.OpcNextPage
+		}
+	}
+
+	var result []core.BootVolume
+	for _, bv := range volumes {
+		if !attached[*bv.Id] {
+			result = append(result, bv)
+		}
+	}
+	return result, nil
+}
+
+// removeDebris 删除残留资源，卡在创建中的实例连同引导卷一起终止
+func (s *LaunchCleanupService) removeDebris(ctx context.Context, user *models.OciUser, item *LaunchDebris) {
+	var err error
+	switch item.Type {
+	case LaunchDebrisInstance:
+		var client core.ComputeClient
+		if client, err = s.ociService.GetComputeClient(user); err == nil {
+			_, err = client.TerminateInstance(ctx, core.TerminateInstanceRequest{
+				InstanceId:         &item.ResourceID,
+				PreserveBootVolume: common.Bool(false),
+			})
+		}
+	case LaunchDebrisBootVolume:
+		var client core.BlockstorageClient
+		if client, err = s.ociService.GetBlockstorageClient(user); err == nil {
+			_, err = client.DeleteBootVolume(ctx, core.DeleteBootVolumeRequest{BootVolumeId: &item.ResourceID})
+		}
+	}
+
+	if err != nil {
+		item.Error = extractOCIErrorMessage(err)
+		log.Printf("[LaunchCleanup] Failed to remove %s %s (%s) for %s [%s]: %v", item.Type, item.Name, item.ResourceID, item.Username, item.Region, err)
+		return
+	}
+	item.Removed = true
+	InvalidateInstanceSnapshots(item.ConfigID)
+	log.Printf("[LaunchCleanup] Removed %s %s (%s) for %s [%s]: %s", item.Type, item.Name, item.ResourceID, item.Username, item.Region, item.Reason)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/v65/networkloadbalancer"
+)
+
+const (
+	NLBProtocolTCP       = "tcp"
+	NLBProtocolUDP       = "udp"
+	NLBProtocolTCPAndUDP = "tcp_and_udp"
+
+	// 开启500Mbps时可指定的转发端口数上限，受网络负载均衡器监听器数量限制
+	MaxNLBPortForwards = 20
+)
+
+// NLBPortForward 开启500Mbps时通过网络负载均衡器转发的端口
+type NLBPortForward struct {
+	Protocol string `json:"protocol"` // tcp / udp / tcp_and_udp
+	Port     int    `json:"port"`
+}
+
+// nlbForwarding 网络负载均衡器的监听器与后端集
+type nlbForwarding struct {
+	Listeners   map[string]networkloadbalancer.ListenerDetails
+	BackendSets map[string]networkloadbalancer.BackendSetDetails
+}
+
+// parseNLBProtocol 将 tcp、udp、tcp_and_udp 转换为监听器协议，为空时使用 TCP
+func parseNLBProtocol(protocol string) (networkloadbalancer.ListenerProtocolsEnum, error) {
+	switch strings.ToLower(protocol) {
+	case NLBProtocolTCP, "":
+		return networkloadbalancer.ListenerProtocolsTcp, nil
+	case NLBProtocolUDP:
+		return networkloadbalancer.ListenerProtocolsUdp, nil
+	case NLBProtocolTCPAndUDP:
+		return networkloadbalancer.ListenerProtocolsTcpAndUdp, nil
+	}
+	return "", fmt.Errorf("不支持的协议: %s", protocol)
+}
+
+// mergeNLBPortForwards 校验并合并转发端口，同一端口同时指定 TCP 与 UDP 时合并为 TCP_AND_UDP
+// SSH 端口始终以 TCP 转发，避免开启后无法登录实例
+func mergeNLBPortForwards(sshPort int, ports []NLBPortForward) (map[int]networkloadbalancer.ListenerProtocolsEnum, error) {
+	merged := map[int]networkloadbalancer.ListenerProtocolsEnum{sshPort: networkloadbalancer.ListenerProtocolsTcp}
+	for _, forward := range ports {
+		if forward.Port < 1 || forward.Port > 65535 {
+			return nil, fmt.Errorf("端口需在 1-65535 之间: %d", forward.Port)
+		}
+		protocol, err := parseNLBProtocol(forward.Protocol)
+		if err != nil {
+			return nil, err
+		}
+		if existing, ok := merged[forward.Port]; ok && existing != protocol {
+			protocol = networkloadbalancer.ListenerProtocolsTcpAndUdp
+		}
+		merged[forward.Port] = protocol
+	}
+	if len(merged) > MaxNLBPortForwards {
+		return nil, fmt.Errorf("最多转发 %d 个端口", MaxNLBPortForwards)
+	}
+	return merged, nil
+}
+
+// ValidateNLBPortForwards 校验开启500Mbps时指定的转发端口
+func ValidateNLBPortForwards(sshPort int, ports []NLBPortForward) error {
+	_, err := mergeNLBPortForwards(sshPort, ports)
+	return err
+}
+
+// buildNLBForwarding 生成转发到实例的监听器与后端集
+// 未指定端口时保持原有行为，使用一个转发所有 TCP/UDP 端口的监听器；指定后每个端口一个监听器
+// 健康检查均使用实例的 SSH 端口
+func buildNLBForwarding(instanceID, privateIP string, sshPort int, ports []NLBPortForward) (*nlbForwarding, error) {
+	backendSet := func(port int) networkloadbalancer.BackendSetDetails {
+		weight := 1
+		isPreserveSource := true
+		isFailOpen := true
+		healthPort := sshPort
+		return networkloadbalancer.BackendSetDetails{
+			Policy:           networkloadbalancer.NetworkLoadBalancingPolicyTwoTuple,
+			IsPreserveSource: &isPreserveSource,
+			IsFailOpen:       &isFailOpen,
+			HealthChecker: &networkloadbalancer.HealthChecker{
+				Protocol: networkloadbalancer.HealthCheckProtocolsTcp,
+				Port:     &healthPort,
+			},
+			Backends: []networkloadbalancer.Backend{
+				{
+					IpAddress: &privateIP,
+					TargetId:  &instanceID,
+					Port:      &port,
+					Weight:    &weight,
+				},
+			},
+		}
+	}
+
+	forwarding := &nlbForwarding{
+		Listeners:   map[string]networkloadbalancer.ListenerDetails{},
+		BackendSets: map[string]networkloadbalancer.BackendSetDetails{},
+	}
+	if len(ports) == 0 {
+		anyPort := 0
+		forwarding.Listeners["listener1"] = networkloadbalancer.ListenerDetails{
+			Name:                  stringPtr("listener1"),
+			DefaultBackendSetName: stringPtr("backend1"),
+			Protocol:              networkloadbalancer.ListenerProtocolsTcpAndUdp,
+			Port:                  &anyPort,
+		}
+		forwarding.BackendSets["backend1"] = backendSet(anyPort)
+		return forwarding, nil
+	}
+
+	merged, err := mergeNLBPortForwards(sshPort, ports)
+	if err != nil {
+		return nil, err
+	}
+	numbers := make([]int, 0, len(merged))
+	for port := range merged {
+		numbers = append(numbers, port)
+	}
+	sort.Ints(numbers)
+	for _, port := range numbers {
+		listenerPort := port
+		protocol := merged[port]
+		name := fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), port)
+		forwarding.Listeners["listener-"+name] = networkloadbalancer.ListenerDetails{
+			Name:                  stringPtr("listener-" + name),
+			DefaultBackendSetName: stringPtr("backend-" + name),
+			Protocol:              protocol,
+			Port:                  &listenerPort,
+		}
+		forwarding.BackendSets["backend-"+name] = backendSet(port)
+	}
+	return forwarding, nil
+}
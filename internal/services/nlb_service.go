@@ -0,0 +1,513 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/networkloadbalancer"
+)
+
+// NLBService 管理网络负载均衡器及其监听器、后端集与后端
+// 网络负载均衡器同一时间只能执行一个修改，修改接口提交后立即返回，状态通过详情查询
+type NLBService struct {
+	ociService *OCIService
+}
+
+func NewNLBService(ociService *OCIService) *NLBService {
+	return &NLBService{ociService: ociService}
+}
+
+// NLBInfo 网络负载均衡器信息
+type NLBInfo struct {
+	ID          string              `json:"id"`
+	DisplayName string              `json:"displayName"`
+	State       string              `json:"state"`
+	IsPrivate   bool                `json:"isPrivate"`
+	SubnetID    string              `json:"subnetId"`
+	PublicIP    string              `json:"publicIp"`
+	PrivateIP   string              `json:"privateIp"`
+	Listeners   []NLBListenerInfo   `json:"listeners"`
+	BackendSets []NLBBackendSetInfo `json:"backendSets"`
+	TimeCreated string              `json:"timeCreated"`
+}
+
+// NLBListenerInfo 监听器
+type NLBListenerInfo struct {
+	Name           string `json:"name"`
+	Protocol       string `json:"protocol"`
+	Port           int    `json:"port"` // 0 表示所有端口
+	BackendSetName string `json:"backendSetName"`
+}
+
+// NLBBackendSetInfo 后端集
+type NLBBackendSetInfo struct {
+	Name             string           `json:"name"`
+	Policy           string           `json:"policy"`
+	IsPreserveSource bool             `json:"isPreserveSource"`
+	HealthProtocol   string           `json:"healthProtocol"`
+	HealthPort       int              `json:"healthPort"`
+	Backends         []NLBBackendInfo `json:"backends"`
+}
+
+// NLBBackendInfo 后端
+type NLBBackendInfo struct {
+	Name      string `json:"name"`
+	IpAddress string `json:"ipAddress,omitempty"`
+	TargetID  string `json:"targetId,omitempty"`
+	Port      int    `json:"port"`
+	Weight    int    `json:"weight"`
+	IsDrain   bool   `json:"isDrain"`
+	IsBackup  bool   `json:"isBackup"`
+	IsOffline bool   `json:"isOffline"`
+}
+
+// NLBHealthInfo 健康检查状态，status 为 OK、WARNING、CRITICAL 或 UNKNOWN
+type NLBHealthInfo struct {
+	Status      string                      `json:"status"`
+	BackendSets map[string]NLBBackendHealth `json:"backendSets"`
+}
+
+// NLBBackendHealth 后端集的健康状态与异常的后端
+type NLBBackendHealth struct {
+	Status           string   `json:"status"`
+	TotalBackends    int      `json:"totalBackends"`
+	CriticalBackends []string `json:"criticalBackends"`
+	WarningBackends  []string `json:"warningBackends"`
+	UnknownBackends  []string `json:"unknownBackends"`
+}
+
+// NLBParams 创建网络负载均衡器参数，监听器与后端集创建后再添加
+type NLBParams struct {
+	SubnetID    string
+	DisplayName string // 为空时使用 nlb-时间
+	IsPrivate   bool
+}
+
+// NLBListenerParams 监听器参数
+type NLBListenerParams struct {
+	Name           string
+	Protocol       string // tcp, udp, tcp_and_udp
+	Port           int    // 0 表示所有端口
+	BackendSetName string
+}
+
+// NLBBackendSetParams 后端集参数
+type NLBBackendSetParams struct {
+	Name             string
+	Policy           string // FIVE_TUPLE, THREE_TUPLE, TWO_TUPLE，为空时使用 FIVE_TUPLE
+	IsPreserveSource bool
+	HealthProtocol   string // TCP, UDP, HTTP, HTTPS，为空时使用 TCP
+	HealthPort       int
+	HealthURLPath    string // HTTP、HTTPS 健康检查的路径
+}
+
+// NLBBackendParams 后端参数，IP 与实例二选一
+type NLBBackendParams struct {
+	IpAddress string
+	TargetID  string
+	Port      int // 0 表示与监听器端口相同
+	Weight    int
+}
+
+func newNLBInfo(nlb networkloadbalancer.NetworkLoadBalancer) NLBInfo {
+	info := NLBInfo{
+		ID:          stringValue(nlb.Id),
+		DisplayName: stringValue(nlb.DisplayName),
+		State:       string(nlb.LifecycleState),
+		IsPrivate:   nlb.IsPrivate != nil && *nlb.IsPrivate,
+		SubnetID:    stringValue(nlb.SubnetId),
+		Listeners:   []NLBListenerInfo{},
+		BackendSets: []NLBBackendSetInfo{},
+		TimeCreated: formatSDKTime(nlb.TimeCreated),
+	}
+	for _, ip := range nlb.IpAddresses {
+		address := stringValue(ip.IpAddress)
+		if ip.IsPublic != nil && *ip.IsPublic {
+			info.PublicIP = address
+		} else if info.PrivateIP == "" {
+			info.PrivateIP = address
+		}
+	}
+	for _, listener := range nlb.Listeners {
+		info.Listeners = append(info.Listeners, NLBListenerInfo{
+			Name:           stringValue(listener.Name),
+			Protocol:       string(listener.Protocol),
+			Port:           intValue(listener.Port),
+			BackendSetName: stringValue(listener.DefaultBackendSetName),
+		})
+	}
+	sort.Slice(info.Listeners, func(i, j int) bool { return info.Listeners[i].Name < info.Listeners[j].Name })
+	for _, set := range nlb.BackendSets {
+		setInfo := NLBBackendSetInfo{
+			Name:             stringValue(set.Name),
+			Policy:           string(set.Policy),
+			IsPreserveSource: set.IsPreserveSource != nil && *set.IsPreserveSource,
+			Backends:         []NLBBackendInfo{},
+		}
+		if set.HealthChecker != nil {
+			setInfo.HealthProtocol = string(set.HealthChecker.Protocol)
+			setInfo.HealthPort = intValue(set.HealthChecker.Port)
+		}
+		for _, backend := range set.Backends {
+			setInfo.Backends = append(setInfo.Backends, NLBBackendInfo{
+				Name:      stringValue(backend.Name),
+				IpAddress: stringValue(backend.IpAddress),
+				TargetID:  stringValue(backend.TargetId),
+				Port:      intValue(backend.Port),
+				Weight:    intValue(backend.Weight),
+				IsDrain:   backend.IsDrain != nil && *backend.IsDrain,
+				IsBackup:  backend.IsBackup != nil && *backend.IsBackup,
+				IsOffline: backend.IsOffline != nil && *backend.IsOffline,
+			})
+		}
+		info.BackendSets = append(info.BackendSets, setInfo)
+	}
+	sort.Slice(info.BackendSets, func(i, j int) bool { return info.BackendSets[i].Name < info.BackendSets[j].Name })
+	return info
+}
+
+func intValue(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func (s *NLBService) client(userId, region string) (*models.OciUser, networkloadbalancer.NetworkLoadBalancerClient, error) {
+	user, err := customImageUser(userId, region)
+	if err != nil {
+		return nil, networkloadbalancer.NetworkLoadBalancerClient{}, err
+	}
+	client, err := s.ociService.GetNetworkLoadBalancerClient(user)
+	return user, client, err
+}
+
+// ListNLBs 列出区间内的网络负载均衡器，compartmentId 为空时使用租户根区间
+func (s *NLBService) ListNLBs(userId, compartmentId, region string) ([]NLBInfo, error) {
+	user, client, err := s.client(userId, region)
+	if err != nil {
+		return nil, err
+	}
+	if compartmentId == "" {
+		compartmentId = user.OciTenantID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	nlbs := []NLBInfo{}
+	req := networkloadbalancer.ListNetworkLoadBalancersRequest{CompartmentId: &compartmentId}
+	for {
+		resp, err := client.ListNetworkLoadBalancers(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("获取网络负载均衡器失败: %s", extractOCIErrorMessage(err))
+		}
+		for _, item := range resp.Items {
+			if item.LifecycleState == networkloadbalancer.LifecycleStateDeleted {
+				continue
+			}
+			nlbs = append(nlbs, newNLBInfo(networkloadbalancer.NetworkLoadBalancer(item)))
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+	return nlbs, nil
+}
+
+// GetNLB 获取网络负载均衡器详情
+func (s *NLBService) GetNLB(userId, nlbId, region string) (*NLBInfo, error) {
+	_, client, err := s.client(userId, region)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	resp, err := client.GetNetworkLoadBalancer(ctx, networkloadbalancer.GetNetworkLoadBalancerRequest{NetworkLoadBalancerId: &nlbId})
+	if err != nil {
+		return nil, fmt.Errorf("获取网络负载均衡器失败: %s", extractOCIErrorMessage(err))
+	}
+	info := newNLBInfo(resp.NetworkLoadBalancer)
+	return &info, nil
+}
+
+// GetNLBHealth 获取网络负载均衡器与各后端集的健康检查状态
+func (s *NLBService) GetNLBHealth(userId, nlbId, region string) (*NLBHealthInfo, error) {
+	_, client, err := s.client(userId, region)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	nlb, err := client.GetNetworkLoadBalancer(ctx, networkloadbalancer.GetNetworkLoadBalancerRequest{NetworkLoadBalancerId: &nlbId})
+	if err != nil {
+		return nil, fmt.Errorf("获取网络负载均衡器失败: %s", extractOCIErrorMessage(err))
+	}
+	health, err := client.GetNetworkLoadBalancerHealth(ctx, networkloadbalancer.GetNetworkLoadBalancerHealthRequest{NetworkLoadBalancerId: &nlbId})
+	if err != nil {
+		return nil, fmt.Errorf("获取健康状态失败: %s", extractOCIErrorMessage(err))
+	}
+
+	info := &NLBHealthInfo{
+		Status:      string(health.Status),
+		BackendSets: make(map[string]NLBBackendHealth, len(nlb.BackendSets)),
+	}
+	for name := range nlb.BackendSets {
+		setName := name
+		resp, err := client.GetBackendSetHealth(ctx, networkloadbalancer.GetBackendSetHealthRequest{
+			NetworkLoadBalancerId: &nlbId,
+			BackendSetName:        &setName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("获取后端集 %s 健康状态失败: %s", name, extractOCIErrorMessage(err))
+		}
+		info.BackendSets[name] = NLBBackendHealth{
+			Status:           string(resp.Status),
+			TotalBackends:    intValue(resp.TotalBackendCount),
+			CriticalBackends: resp.CriticalStateBackendNames,
+			WarningBackends:  resp.WarningStateBackendNames,
+			UnknownBackends:  resp.UnknownStateBackendNames,
+		}
+	}
+	return info, nil
+}
+
+// CreateNLB 在子网所在区间创建网络负载均衡器，返回 ID，创建在后台完成
+func (s *NLBService) CreateNLB(userId, region string, params NLBParams) (string, error) {
+	user, client, err := s.client(userId, region)
+	if err != nil {
+		return "", err
+	}
+	vnClient, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	subnet, err := vnClient.GetSubnet(ctx, core.GetSubnetRequest{SubnetId: &params.SubnetID})
+	if err != nil {
+		return "", fmt.Errorf("获取子网失败: %s", extractOCIErrorMessage(err))
+	}
+	name := params.DisplayName
+	if name == "" {
+		name = fmt.Sprintf("nlb-%s", time.Now().Format("20060102150405"))
+	}
+	resp, err := client.CreateNetworkLoadBalancer(ctx, networkloadbalancer.CreateNetworkLoadBalancerRequest{
+		CreateNetworkLoadBalancerDetails: networkloadbalancer.CreateNetworkLoadBalancerDetails{
+			CompartmentId: subnet.CompartmentId,
+			DisplayName:   &name,
+			SubnetId:      &params.SubnetID,
+			IsPrivate:     &params.IsPrivate,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("创建网络负载均衡器失败: %s", extractOCIErrorMessage(err))
+	}
+	return stringValue(resp.Id), nil
+}
+
+// DeleteNLB 删除网络负载均衡器
+func (s *NLBService) DeleteNLB(userId, nlbId, region string) error {
+	_, client, err := s.client(userId, region)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.DeleteNetworkLoadBalancer(ctx, networkloadbalancer.DeleteNetworkLoadBalancerRequest{NetworkLoadBalancerId: &nlbId}); err != nil {
+		return fmt.Errorf("删除网络负载均衡器失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// CreateListener 添加监听器，后端集需已存在
+func (s *NLBService) CreateListener(userId, nlbId, region string, params NLBListenerParams) error {
+	protocol, err := parseNLBProtocol(params.Protocol)
+	if err != nil {
+		return err
+	}
+	if params.Port < 0 || params.Port > 65535 {
+		return fmt.Errorf("端口需在 0-65535 之间: %d", params.Port)
+	}
+	_, client, err := s.client(userId, region)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.CreateListener(ctx, networkloadbalancer.CreateListenerRequest{
+		NetworkLoadBalancerId: &nlbId,
+		CreateListenerDetails: networkloadbalancer.CreateListenerDetails{
+			Name:                  &params.Name,
+			DefaultBackendSetName: &params.BackendSetName,
+			Port:                  &params.Port,
+			Protocol:              protocol,
+		},
+	}); err != nil {
+		return fmt.Errorf("添加监听器失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// DeleteListener 删除监听器
+func (s *NLBService) DeleteListener(userId, nlbId, name, region string) error {
+	_, client, err := s.client(userId, region)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.DeleteListener(ctx, networkloadbalancer.DeleteListenerRequest{
+		NetworkLoadBalancerId: &nlbId,
+		ListenerName:          &name,
+	}); err != nil {
+		return fmt.Errorf("删除监听器失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// nlbBackendSetDetails 校验后端集参数并生成创建请求
+func nlbBackendSetDetails(params NLBBackendSetParams) (*networkloadbalancer.CreateBackendSetDetails, error) {
+	policy := networkloadbalancer.NetworkLoadBalancingPolicyFiveTuple
+	if params.Policy != "" {
+		var ok bool
+		if policy, ok = networkloadbalancer.GetMappingNetworkLoadBalancingPolicyEnum(params.Policy); !ok {
+			return nil, fmt.Errorf("不支持的负载均衡策略: %s", params.Policy)
+		}
+	}
+	healthProtocol := networkloadbalancer.HealthCheckProtocolsTcp
+	if params.HealthProtocol != "" {
+		var ok bool
+		if healthProtocol, ok = networkloadbalancer.GetMappingHealthCheckProtocolsEnum(params.HealthProtocol); !ok {
+			return nil, fmt.Errorf("不支持的健康检查协议: %s", params.HealthProtocol)
+		}
+	}
+	if params.HealthPort < 1 || params.HealthPort > 65535 {
+		return nil, fmt.Errorf("健康检查端口需在 1-65535 之间: %d", params.HealthPort)
+	}
+
+	health := &networkloadbalancer.HealthCheckerDetails{
+		Protocol: healthProtocol,
+		Port:     common.Int(params.HealthPort),
+	}
+	switch healthProtocol {
+	case networkloadbalancer.HealthCheckProtocolsHttp, networkloadbalancer.HealthCheckProtocolsHttps:
+		path := params.HealthURLPath
+		if path == "" {
+			path = "/"
+		}
+		health.UrlPath = &path
+		health.ReturnCode = common.Int(200)
+	}
+	return &networkloadbalancer.CreateBackendSetDetails{
+		Name:             &params.Name,
+		Policy:           policy,
+		HealthChecker:    health,
+		IsPreserveSource: &params.IsPreserveSource,
+	}, nil
+}
+
+// CreateBackendSet 添加后端集
+func (s *NLBService) CreateBackendSet(userId, nlbId, region string, params NLBBackendSetParams) error {
+	details, err := nlbBackendSetDetails(params)
+	if err != nil {
+		return err
+	}
+	_, client, err := s.client(userId, region)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.CreateBackendSet(ctx, networkloadbalancer.CreateBackendSetRequest{
+		NetworkLoadBalancerId:   &nlbId,
+		CreateBackendSetDetails: *details,
+	}); err != nil {
+		return fmt.Errorf("添加后端集失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// DeleteBackendSet 删除后端集，需先删除使用它的监听器
+func (s *NLBService) DeleteBackendSet(userId, nlbId, name, region string) error {
+	_, client, err := s.client(userId, region)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.DeleteBackendSet(ctx, networkloadbalancer.DeleteBackendSetRequest{
+		NetworkLoadBalancerId: &nlbId,
+		BackendSetName:        &name,
+	}); err != nil {
+		return fmt.Errorf("删除后端集失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// CreateBackend 向后端集添加后端
+func (s *NLBService) CreateBackend(userId, nlbId, backendSetName, region string, params NLBBackendParams) error {
+	if (params.IpAddress == "") == (params.TargetID == "") {
+		return fmt.Errorf("后端需指定 IP 或实例之一")
+	}
+	if params.Port < 0 || params.Port > 65535 {
+		return fmt.Errorf("端口需在 0-65535 之间: %d", params.Port)
+	}
+	_, client, err := s.client(userId, region)
+	if err != nil {
+		return err
+	}
+
+	details := networkloadbalancer.CreateBackendDetails{Port: &params.Port}
+	if params.IpAddress != "" {
+		details.IpAddress = &params.IpAddress
+	} else {
+		details.TargetId = &params.TargetID
+	}
+	if params.Weight > 0 {
+		details.Weight = &params.Weight
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.CreateBackend(ctx, networkloadbalancer.CreateBackendRequest{
+		NetworkLoadBalancerId: &nlbId,
+		BackendSetName:        &backendSetName,
+		CreateBackendDetails:  details,
+	}); err != nil {
+		return fmt.Errorf("添加后端失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
+
+// DeleteBackend 从后端集删除后端，名称为详情中返回的后端名称
+func (s *NLBService) DeleteBackend(userId, nlbId, backendSetName, backendName, region string) error {
+	_, client, err := s.client(userId, region)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := client.DeleteBackend(ctx, networkloadbalancer.DeleteBackendRequest{
+		NetworkLoadBalancerId: &nlbId,
+		BackendSetName:        &backendSetName,
+		BackendName:           &backendName,
+	}); err != nil {
+		return fmt.Errorf("删除后端失败: %s", extractOCIErrorMessage(err))
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/oracle/oci-go-sdk/v65/networkloadbalancer"
+)
+
+func TestParseNLBProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		want     networkloadbalancer.ListenerProtocolsEnum
+		wantErr  bool
+	}{
+		{"为空默认TCP", "", networkloadbalancer.ListenerProtocolsTcp, false},
+		{"大小写不敏感", "UDP", networkloadbalancer.ListenerProtocolsUdp, false},
+		{"TCP与UDP", "tcp_and_udp", networkloadbalancer.ListenerProtocolsTcpAndUdp, false},
+		{"不支持的协议", "http", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNLBProtocol(tt.protocol)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNLBProtocol(%q) error = %v, wantErr %v", tt.protocol, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseNLBProtocol(%q) = %q, want %q", tt.protocol, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNLBBackendSetDetails(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     NLBBackendSetParams
+		wantErr    bool
+		wantPolicy networkloadbalancer.NetworkLoadBalancingPolicyEnum
+		wantPath   string
+	}{
+		{"默认策略与TCP健康检查", NLBBackendSetParams{Name: "bs", HealthPort: 22}, false, networkloadbalancer.NetworkLoadBalancingPolicyFiveTuple, ""},
+		{"HTTP健康检查默认路径", NLBBackendSetParams{Name: "bs", Policy: "TWO_TUPLE", HealthProtocol: "HTTP", HealthPort: 80}, false, networkloadbalancer.NetworkLoadBalancingPolicyTwoTuple, "/"},
+		{"HTTPS健康检查指定路径", NLBBackendSetParams{Name: "bs", HealthProtocol: "https", HealthPort: 443, HealthURLPath: "/health"}, false, networkloadbalancer.NetworkLoadBalancingPolicyFiveTuple, "/health"},
+		{"不支持的策略", NLBBackendSetParams{Name: "bs", Policy: "ROUND_ROBIN", HealthPort: 22}, true, "", ""},
+		{"不支持的健康检查协议", NLBBackendSetParams{Name: "bs", HealthProtocol: "ICMP", HealthPort: 22}, true, "", ""},
+		{"健康检查端口为0", NLBBackendSetParams{Name: "bs"}, true, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details, err := nlbBackendSetDetails(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nlbBackendSetDetails() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if details.Policy != tt.wantPolicy {
+				t.Errorf("Policy = %q, want %q", details.Policy, tt.wantPolicy)
+			}
+			if got := stringValue(details.HealthChecker.UrlPath); got != tt.wantPath {
+				t.Errorf("UrlPath = %q, want %q", got, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/events"
+	"github.com/oracle/oci-go-sdk/v65/ons"
+)
+
+const (
+	// 面板创建的通知主题名称，重复执行时按名称复用
+	eventTopicName = "oci-panel-events"
+	// 等待新建主题变为可用的最长时间
+	eventTopicActiveTimeout = 60 * time.Second
+)
+
+// eventRuleTemplate 面板创建的事件规则
+type eventRuleTemplate struct {
+	DisplayName string
+	Description string
+	EventTypes  []string
+}
+
+// eventRuleTemplates 实例生命周期与预算告警规则，规则建在根区间，覆盖所有子区间
+var eventRuleTemplates = []eventRuleTemplate{
+	{
+		DisplayName: "oci-panel-instance-lifecycle",
+		Description: "OCI Panel: 实例状态变化",
+		EventTypes: []string{
+			"com.oraclecloud.computeapi.launchinstance.end",
+			"com.oraclecloud.computeapi.instanceaction.end",
+			"com.oraclecloud.computeapi.updateinstance.end",
+			"com.oraclecloud.computeapi.terminateinstance.end",
+		},
+	},
+	{
+		DisplayName: "oci-panel-budget-alerts",
+		Description: "OCI Panel: 预算告警",
+		EventTypes:  []string{"com.oraclecloud.budgets.triggeralert"},
+	},
+}
+
+// EventBootstrapResult 事件推送初始化结果
+type EventBootstrapResult struct {
+	TopicID           string   `json:"topicId"`
+	SubscriptionID    string   `json:"subscriptionId"`
+	SubscriptionState string   `json:"subscriptionState"` // PENDING 表示等待推送地址确认
+	RuleIDs           []string `json:"ruleIds"`
+	Created           []string `json:"created"` // 本次新建的资源，已存在的资源直接复用
+}
+
+// BootstrapEventDelivery 在配置所在区域创建通知主题、事件规则和指向面板推送地址的订阅
+// 已存在的同名主题、规则和相同地址的订阅会被复用，可重复执行
+func (s *OCIEventService) BootstrapEventDelivery(ctx context.Context, configID string) (*EventBootstrapResult, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", configID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+	webhookURL, err := s.EventWebhookURL(configID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EventBootstrapResult{}
+	topicID, created, err := s.ensureEventTopic(ctx, &user)
+	if err != nil {
+		return nil, fmt.Errorf("创建通知主题失败: %s", extractOCIErrorMessage(err))
+	}
+	result.TopicID = topicID
+	if created {
+		result.Created = append(result.Created, "topic")
+	}
+
+	subscription, created, err := s.ensureEventSubscription(ctx, &user, topicID, webhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("创建订阅失败: %s", extractOCIErrorMessage(err))
+	}
+	result.SubscriptionID = subscription.id
+	result.SubscriptionState = subscription.state
+	if created {
+		result.Created = append(result.Created, "subscription")
+	}
+
+	for _, template := range eventRuleTemplates {
+		ruleID, created, err := s.ensureEventRule(ctx, &user, topicID, template)
+		if err != nil {
+			return nil, fmt.Errorf("创建事件规则 %s 失败: %s", template.DisplayName, extractOCIErrorMessage(err))
+		}
+		result.RuleIDs = append(result.RuleIDs, ruleID)
+		if created {
+			result.Created = append(result.Created, template.DisplayName)
+		}
+	}
+
+	log.Printf("[OCIEvents] Bootstrapped event delivery for config %s, created: %v", user.Username, result.Created)
+	return result, nil
+}
+
+// ensureEventTopic 查找或创建通知主题，新建时等待主题可用
+func (s *OCIEventService) ensureEventTopic(ctx context.Context, user *models.OciUser) (string, bool, error) {
+	client, err := s.ociService.GetNotificationControlPlaneClient(user)
+	if err != nil {
+		return "", false, err
+	}
+
+	listResp, err := client.ListTopics(ctx, ons.ListTopicsRequest{
+		CompartmentId: common.String(user.OciTenantID),
+		Name:          common.String(eventTopicName),
+	})
+	if err != nil {
+		return "", false, err
+	}
+	for _, topic := range listResp.Items {
+		if topic.LifecycleState != ons.NotificationTopicSummaryLifecycleStateDeleting {
+			return stringValue(topic.TopicId), false, nil
+		}
+	}
+
+	createResp, err := client.CreateTopic(ctx, ons.CreateTopicRequest{
+		CreateTopicDetails: ons.CreateTopicDetails{
+			Name:          common.String(eventTopicName),
+			CompartmentId: common.String(user.OciTenantID),
+			Description:   common.String("OCI Panel 事件推送"),
+		},
+	})
+	if err != nil {
+		return "", false, err
+	}
+	topicID := stringValue(createResp.TopicId)
+
+	deadline := time.Now().Add(eventTopicActiveTimeout)
+	for createResp.LifecycleState != ons.NotificationTopicLifecycleStateActive && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return "", true, ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+		getResp, err := client.GetTopic(ctx, ons.GetTopicRequest{TopicId: common.String(topicID)})
+		if err != nil {
+			return "", true, err
+		}
+		createResp.NotificationTopic = getResp.NotificationTopic
+	}
+	return topicID, true, nil
+}
+
+type eventSubscriptionState struct {
+	id    string
+	state string
+}
+
+// ensureEventSubscription 查找或创建指向推送地址的订阅，仍未确认的订阅会重新发送确认
+func (s *OCIEventService) ensureEventSubscription(ctx context.Context, user *models.OciUser, topicID, endpoint string) (*eventSubscriptionState, bool, error) {
+	client, err := s.ociService.GetNotificationDataPlaneClient(user)
+	if err != nil {
+		return nil, false, err
+	}
+
+	listResp, err := client.ListSubscriptions(ctx, ons.ListSubscriptionsRequest{
+		CompartmentId: common.String(user.OciTenantID),
+		TopicId:       common.String(topicID),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	for _, subscription := range listResp.Items {
+		if stringValue(subscription.Endpoint) != endpoint || subscription.LifecycleState == ons.SubscriptionSummaryLifecycleStateDeleted {
+			continue
+		}
+		if subscription.LifecycleState == ons.SubscriptionSummaryLifecycleStatePending {
+			if _, err := client.ResendSubscriptionConfirmation(ctx, ons.ResendSubscriptionConfirmationRequest{Id: subscription.Id}); err != nil {
+				log.Printf("[OCIEvents] Failed to resend confirmation for %s: %v", stringValue(subscription.Id), err)
+			}
+		}
+		return &eventSubscriptionState{id: stringValue(subscription.Id), state: string(subscription.LifecycleState)}, false, nil
+	}
+
+	createResp, err := client.CreateSubscription(ctx, ons.CreateSubscriptionRequest{
+		CreateSubscriptionDetails: ons.CreateSubscriptionDetails{
+			TopicId:       common.String(topicID),
+			CompartmentId: common.String(user.OciTenantID),
+			Protocol:      common.String("CUSTOM_HTTPS"),
+			Endpoint:      common.String(endpoint),
+		},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &eventSubscriptionState{id: stringValue(createResp.Id), state: string(createResp.LifecycleState)}, true, nil
+}
+
+// ensureEventRule 查找或创建投递到通知主题的事件规则
+func (s *OCIEventService) ensureEventRule(ctx context.Context, user *models.OciUser, topicID string, template eventRuleTemplate) (string, bool, error) {
+	client, err := s.ociService.GetEventsClient(user)
+	if err != nil {
+		return "", false, err
+	}
+
+	listResp, err := client.ListRules(ctx, events.ListRulesRequest{
+		CompartmentId: common.String(user.OciTenantID),
+		DisplayName:   common.String(template.DisplayName),
+	})
+	if err != nil {
+		return "", false, err
+	}
+	for _, rule := range listResp.Items {
+		if rule.LifecycleState != events.RuleLifecycleStateDeleting && rule.LifecycleState != events.RuleLifecycleStateDeleted {
+			return stringValue(rule.Id), false, nil
+		}
+	}
+
+	condition, err := json.Marshal(map[string][]string{"eventType": template.EventTypes})
+	if err != nil {
+		return "", false, err
+	}
+	createResp, err := client.CreateRule(ctx, events.CreateRuleRequest{
+		CreateRuleDetails: events.CreateRuleDetails{
+			DisplayName:   common.String(template.DisplayName),
+			Description:   common.String(template.Description),
+			IsEnabled:     common.Bool(true),
+			Condition:     common.String(string(condition)),
+			CompartmentId: common.String(user.OciTenantID),
+			Actions: &events.ActionDetailsList{
+				Actions: []events.ActionDetails{
+					events.CreateNotificationServiceActionDetails{
+						IsEnabled: common.Bool(true),
+						TopicId:   common.String(topicID),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return stringValue(createResp.Id), true, nil
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+const (
+	SettingOCIEventsSecret = "oci_events_secret"
+
+	// ONS 确认订阅时请求头中携带的确认地址
+	onsConfirmationHeader = "X-OCI-NS-ConfirmationURL"
+	// 确认订阅请求超时
+	onsConfirmationTimeout = 10 * time.Second
+	// 刷新单个实例缓存的超时
+	eventInstanceRefreshTimeout = 30 * time.Second
+)
+
+// ociCloudEvent OCI Events 推送的 CloudEvents 消息
+type ociCloudEvent struct {
+	EventType string `json:"eventType"`
+	Source    string `json:"source"`
+	EventID   string `json:"eventID"`
+	EventTime string `json:"eventTime"`
+	Data      struct {
+		ResourceID   string `json:"resourceId"`
+		ResourceName string `json:"resourceName"`
+	} `json:"data"`
+}
+
+type OCIEventService struct {
+	ociService *OCIService
+	cacheMu    sync.Mutex
+}
+
+func NewOCIEventService(ociService *OCIService) *OCIEventService {
+	return &OCIEventService{ociService: ociService}
+}
+
+// eventsSecretMu 保证并发请求首次生成密钥时只有一个密钥被保存
+var eventsSecretMu sync.Mutex
+
+// eventsSecret 获取事件推送地址的签名密钥，首次使用时生成
+func eventsSecret() ([]byte, error) {
+	eventsSecretMu.Lock()
+	defer eventsSecretMu.Unlock()
+
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingOCIEventsSecret).First(&setting).Error; err == nil && setting.Value != "" {
+		return hex.DecodeString(setting.Value)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if err := saveSetting(SettingOCIEventsSecret, hex.EncodeToString(secret)); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// eventWebhookSignature 计算配置对应推送地址中的签名
+func eventWebhookSignature(configID string) (string, error) {
+	secret, err := eventsSecret()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(configID))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// EventWebhookPath 生成配置的事件推送路径，签名绑定配置 ID，泄露一个地址不影响其它配置
+func EventWebhookPath(configID string) (string, error) {
+	signature, err := eventWebhookSignature(configID)
+	if err != nil {
+		return "", err
+	}
+	return "/api/events/oci/" + configID + "/" + signature, nil
+}
+
+// EventWebhookURL 生成完整的事件推送地址，未配置 public_url 时返回错误
+func (s *OCIEventService) EventWebhookURL(configID string) (string, error) {
+	path, err := EventWebhookPath(configID)
+	if err != nil {
+		return "", err
+	}
+	base := strings.TrimRight(s.ociService.cfg.Server.PublicURL, "/")
+	if base == "" {
+		return "", fmt.Errorf("未配置 server.public_url，无法生成推送地址")
+	}
+	if !strings.HasPrefix(base, "https://") {
+		return "", fmt.Errorf("OCI 通知仅支持 https 推送地址")
+	}
+	return base + path, nil
+}
+
+// VerifyEventWebhook 校验推送地址中的签名
+func VerifyEventWebhook(configID, signature string) bool {
+	expected, err := eventWebhookSignature(configID)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// confirmSubscription 访问 ONS 提供的确认地址完成订阅确认，仅允许 oraclecloud.com 域名
+func confirmSubscription(confirmationURL string) error {
+	parsed, err := url.Parse(confirmationURL)
+	if err != nil || parsed.Scheme != "https" || !strings.HasSuffix(parsed.Hostname(), ".oraclecloud.com") {
+		return fmt.Errorf("确认地址无效: %s", confirmationURL)
+	}
+
+	client := &http.Client{Timeout: onsConfirmationTimeout}
+	resp, err := client.Get(confirmationURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("确认订阅失败: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HandleDelivery 处理一次 ONS 推送：订阅确认消息自动确认，事件消息记录后刷新对应实例的缓存
+func (s *OCIEventService) HandleDelivery(configID string, header http.Header, body []byte) error {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", configID).First(&user).Error; err != nil {
+		return fmt.Errorf("配置不存在")
+	}
+
+	confirmationURL := header.Get(onsConfirmationHeader)
+	if confirmationURL == "" {
+		var confirmation struct {
+			ConfirmationURL string `json:"ConfirmationURL"`
+		}
+		if json.Unmarshal(body, &confirmation) == nil {
+			confirmationURL = confirmation.ConfirmationURL
+		}
+	}
+	if confirmationURL != "" {
+		if err := confirmSubscription(confirmationURL); err != nil {
+			return err
+		}
+		log.Printf("[OCIEvents] Confirmed subscription for config %s", user.Username)
+		return nil
+	}
+
+	var event ociCloudEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.EventType == "" {
+		return fmt.Errorf("无法解析事件消息")
+	}
+
+	db := database.GetDB()
+	if event.EventID != "" {
+		var count int64
+		db.Model(&models.OciEvent{}).Where("config_id = ? AND event_id = ?", configID, event.EventID).Count(&count)
+		if count > 0 {
+			return nil
+		}
+	}
+
+	record := models.OciEvent{
+		ID:           uuid.New().String(),
+		EventID:      event.EventID,
+		ConfigID:     configID,
+		EventType:    event.EventType,
+		Source:       event.Source,
+		ResourceID:   event.Data.ResourceID,
+		ResourceName: event.Data.ResourceName,
+		EventTime:    time.Now(),
+	}
+	if t, err := time.Parse(time.RFC3339, event.EventTime); err == nil {
+		record.EventTime = t
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(event.Data.ResourceID, "ocid1.instance.") {
+		go s.refreshCachedInstance(&user, event.Data.ResourceID)
+	}
+	return nil
+}
+
+// refreshCachedInstance 重新获取单个实例并更新配置缓存，实例已终止或不存在时从缓存中移除
+func (s *OCIEventService) refreshCachedInstance(user *models.OciUser, instanceID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), eventInstanceRefreshTimeout)
+	defer cancel()
+
+	detail, err := s.ociService.GetInstanceDetails(ctx, user, instanceID)
+	if err != nil {
+		if serviceErr, ok := common.IsServiceError(err); !ok || serviceErr.GetHTTPStatusCode() != 404 {
+			log.Printf("[OCIEvents] Failed to refresh instance %s: %v", instanceID, err)
+			return
+		}
+		detail = nil
+	} else if detail.State == "TERMINATED" {
+		detail = nil
+	}
+
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	db := database.GetDB()
+	var cache models.OciConfigCache
+	if err := db.Where("config_id = ?", user.ID).First(&cache).Error; err != nil {
+		return
+	}
+	var instances []models.InstanceInfo
+	if cache.InstancesData != "" {
+		if err := json.Unmarshal([]byte(cache.InstancesData), &instances); err != nil {
+			return
+		}
+	}
+
+	updated := instances[:0]
+	found := false
+	for _, instance := range instances {
+		if instance.ID != instanceID {
+			updated = append(updated, instance)
+			continue
+		}
+		found = true
+		if detail != nil {
+			updated = append(updated, *detail)
+		}
+	}
+	if !found && detail != nil {
+		updated = append(updated, *detail)
+	}
+
+	running := 0
+	for _, instance := range updated {
+		if instance.State == "RUNNING" {
+			running++
+		}
+	}
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return
+	}
+	db.Model(&cache).Updates(map[string]interface{}{
+		"instances_data":    string(data),
+		"instance_count":    len(updated),
+		"running_instances": running,
+	})
+}
+
+// ListOCIEvents 获取最近收到的事件，configID 为空时返回所有配置
+func ListOCIEvents(configID string, limit int) ([]models.OciEvent, error) {
+	query := database.GetDB().Order("receive_time DESC").Limit(limit)
+	if configID != "" {
+		query = query.Where("config_id = ?", configID)
+	}
+	var events []models.OciEvent
+	err := query.Find(&events).Error
+	return events, err
+}
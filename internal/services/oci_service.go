@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"strings"
 	"time"
@@ -10,11 +11,16 @@ import (
 	"github.com/adiecho/oci-panel/internal/config"
 	"github.com/adiecho/oci-panel/internal/models"
 	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/computeinstanceagent"
 	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/events"
 	"github.com/oracle/oci-go-sdk/v65/identity"
 	"github.com/oracle/oci-go-sdk/v65/identitydomains"
+	"github.com/oracle/oci-go-sdk/v65/limits"
 	"github.com/oracle/oci-go-sdk/v65/monitoring"
 	"github.com/oracle/oci-go-sdk/v65/networkloadbalancer"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+	"github.com/oracle/oci-go-sdk/v65/ons"
 )
 
 type OCIService struct {
@@ -30,6 +36,13 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+// IsValidCompartmentID 校验区间 OCID 格式，空值表示使用租户根区间
+func IsValidCompartmentID(compartmentID string) bool {
+	return compartmentID == "" ||
+		strings.HasPrefix(compartmentID, "ocid1.compartment.") ||
+		strings.HasPrefix(compartmentID, "ocid1.tenancy.")
+}
+
 // 辅助函数：创建布尔指针
 func boolPtr(b bool) *bool {
 	return &b
@@ -62,6 +75,7 @@ func (s *OCIService) GetComputeClient(user *models.OciUser) (core.ComputeClient,
 	if err != nil {
 		return core.ComputeClient{}, err
 	}
+	guardClient(user, &client.BaseClient)
 
 	return client, nil
 }
@@ -76,6 +90,7 @@ func (s *OCIService) GetVirtualNetworkClient(user *models.OciUser) (core.Virtual
 	if err != nil {
 		return core.VirtualNetworkClient{}, err
 	}
+	guardClient(user, &client.BaseClient)
 
 	return client, nil
 }
@@ -90,6 +105,7 @@ func (s *OCIService) GetBlockstorageClient(user *models.OciUser) (core.Blockstor
 	if err != nil {
 		return core.BlockstorageClient{}, err
 	}
+	guardClient(user, &client.BaseClient)
 
 	return client, nil
 }
@@ -104,6 +120,37 @@ func (s *OCIService) GetIdentityClient(user *models.OciUser) (identity.IdentityC
 	if err != nil {
 		return identity.IdentityClient{}, err
 	}
+	guardClient(user, &client.BaseClient)
+
+	return client, nil
+}
+
+func (s *OCIService) GetLimitsClient(user *models.OciUser) (limits.LimitsClient, error) {
+	configProvider, err := s.GetConfigProvider(user)
+	if err != nil {
+		return limits.LimitsClient{}, err
+	}
+
+	client, err := limits.NewLimitsClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return limits.LimitsClient{}, err
+	}
+	guardClient(user, &client.BaseClient)
+
+	return client, nil
+}
+
+func (s *OCIService) GetComputeInstanceAgentClient(user *models.OciUser) (computeinstanceagent.ComputeInstanceAgentClient, error) {
+	configProvider, err := s.GetConfigProvider(user)
+	if err != nil {
+		return computeinstanceagent.ComputeInstanceAgentClient{}, err
+	}
+
+	client, err := computeinstanceagent.NewComputeInstanceAgentClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return computeinstanceagent.ComputeInstanceAgentClient{}, err
+	}
+	guardClient(user, &client.BaseClient)
 
 	return client, nil
 }
@@ -118,6 +165,7 @@ func (s *OCIService) GetIdentityDomainsClient(user *models.OciUser, endpoint str
 	if err != nil {
 		return identitydomains.IdentityDomainsClient{}, err
 	}
+	guardClient(user, &client.BaseClient)
 
 	return client, nil
 }
@@ -216,6 +264,7 @@ type LaunchInstanceParams struct {
 	SshPublicKey       string
 	BootVolumeSizeGBs  int64
 	BootVolumeVpuPerGB int64
+	UserData           string // base64 编码的 cloud-init 脚本，为空时不设置
 }
 
 func (s *OCIService) LaunchInstance(ctx context.Context, user *models.OciUser, params LaunchInstanceParams) (*core.Instance, error) {
@@ -254,6 +303,9 @@ func (s *OCIService) LaunchInstance(ctx context.Context, user *models.OciUser, p
 			},
 		},
 	}
+	if params.UserData != "" {
+		req.Metadata["user_data"] = params.UserData
+	}
 
 	resp, err := client.LaunchInstance(ctx, req)
 	if err != nil {
@@ -264,74 +316,102 @@ func (s *OCIService) LaunchInstance(ctx context.Context, user *models.OciUser, p
 }
 
 // CreateInstance 自动创建实例（自动获取AD、VCN、子网，可指定镜像ID）
-func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, region, architecture, operationSystem string, ocpus, memory float64, disk int, vpusPerGB int64, sshPublicKey string, imageIdParam string) error {
+// adIndex 为可用域序号（按序号对可用域数量取模），返回本次使用的可用域及创建的实例
+func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, region, architecture, operationSystem string, ocpus, memory float64, disk int, vpusPerGB int64, sshPublicKey string, userData string, imageIdParam string, compartmentIdParam string, adIndex int) (string, *core.Instance, error) {
 	// 临时切换用户区域
 	originalRegion := user.OciRegion
 	user.OciRegion = region
 	defer func() { user.OciRegion = originalRegion }()
 
+	// 未指定区间时使用租户根区间
 	compartmentId := user.OciTenantID
+	if compartmentIdParam != "" {
+		compartmentId = compartmentIdParam
+	}
 
 	// 1. 获取身份客户端
 	identityClient, err := s.GetIdentityClient(user)
 	if err != nil {
-		return fmt.Errorf("获取身份客户端失败: %w", err)
+		return "", nil, fmt.Errorf("获取身份客户端失败: %w", err)
 	}
 
 	// 2. 获取可用域列表
 	adResp, err := identityClient.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{
-		CompartmentId: &compartmentId,
+		CompartmentId: &user.OciTenantID,
 	})
 	if err != nil {
-		return fmt.Errorf("获取可用域失败: %w", err)
+		return "", nil, fmt.Errorf("获取可用域失败: %w", err)
 	}
 	if len(adResp.Items) == 0 {
-		return fmt.Errorf("没有可用的可用域")
+		return "", nil, fmt.Errorf("没有可用的可用域")
 	}
-	availabilityDomain := *adResp.Items[0].Name
+	// 默认可用域名称与区域相关，仅在配置主区域生效
+	defaultAD := ""
+	if region == originalRegion {
+		defaultAD = user.DefaultAD
+	}
+	availabilityDomain := selectAvailabilityDomain(adResp.Items, defaultAD, adIndex)
 
 	// 3. 获取或创建VCN和子网
 	vnClient, err := s.GetVirtualNetworkClient(user)
 	if err != nil {
-		return fmt.Errorf("获取网络客户端失败: %w", err)
-	}
-
-	// 列出现有VCN（只获取Available状态的VCN）
-	vcnLifecycleState := core.VcnLifecycleStateAvailable
-	vcnResp, err := vnClient.ListVcns(ctx, core.ListVcnsRequest{
-		CompartmentId:  &compartmentId,
-		LifecycleState: vcnLifecycleState,
-	})
-	if err != nil {
-		return fmt.Errorf("获取VCN列表失败: %w", err)
+		return availabilityDomain, nil, fmt.Errorf("获取网络客户端失败: %w", err)
 	}
 
 	var subnetId string
 	var targetVcn *core.Vcn
-	// 遍历所有VCN查找可用的公有子网
-	for i := range vcnResp.Items {
-		vcn := &vcnResp.Items[i]
-		subnetResp, err := vnClient.ListSubnets(ctx, core.ListSubnetsRequest{
+	// 在配置主区域开机且设置了默认子网时直接使用默认子网
+	if user.DefaultSubnetID != "" && region == originalRegion {
+		defaultSubnetId, subnetAD, err := resolveDefaultSubnet(ctx, vnClient, user.DefaultSubnetID)
+		if err != nil {
+			return availabilityDomain, nil, err
+		}
+		subnetId = defaultSubnetId
+		if subnetAD != "" {
+			// AD级子网只能在其所属可用域开机
+			availabilityDomain = subnetAD
+		}
+	}
+
+	if subnetId == "" {
+		// 列出现有VCN（只获取Available状态的VCN）
+		vcnLifecycleState := core.VcnLifecycleStateAvailable
+		vcnResp, err := vnClient.ListVcns(ctx, core.ListVcnsRequest{
 			CompartmentId:  &compartmentId,
-			VcnId:          vcn.Id,
-			LifecycleState: core.SubnetLifecycleStateAvailable,
+			LifecycleState: vcnLifecycleState,
 		})
 		if err != nil {
-			continue
+			return availabilityDomain, nil, fmt.Errorf("获取VCN列表失败: %w", err)
 		}
-		// 查找公有子网（ProhibitPublicIpOnVnic为false的子网）
-		for _, subnet := range subnetResp.Items {
-			if subnet.ProhibitPublicIpOnVnic != nil && !*subnet.ProhibitPublicIpOnVnic {
-				subnetId = *subnet.Id
+
+		// 遍历所有VCN查找可用的公有子网
+		for i := range vcnResp.Items {
+			vcn := &vcnResp.Items[i]
+			subnetResp, err := vnClient.ListSubnets(ctx, core.ListSubnetsRequest{
+				CompartmentId:  &compartmentId,
+				VcnId:          vcn.Id,
+				LifecycleState: core.SubnetLifecycleStateAvailable,
+			})
+			if err != nil {
+				continue
+			}
+			// 查找公有子网（ProhibitPublicIpOnVnic为false的子网），跳过属于其它可用域的AD级子网
+			for _, subnet := range subnetResp.Items {
+				if subnet.AvailabilityDomain != nil && *subnet.AvailabilityDomain != availabilityDomain {
+					continue
+				}
+				if subnet.ProhibitPublicIpOnVnic != nil && !*subnet.ProhibitPublicIpOnVnic {
+					subnetId = *subnet.Id
+					break
+				}
+			}
+			if subnetId != "" {
 				break
 			}
-		}
-		if subnetId != "" {
-			break
-		}
-		// 保存第一个VCN用于后续创建子网
-		if targetVcn == nil {
-			targetVcn = vcn
+			// 保存第一个VCN用于后续创建子网
+			if targetVcn == nil {
+				targetVcn = vcn
+			}
 		}
 	}
 
@@ -352,7 +432,7 @@ func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, r
 				},
 			})
 			if err != nil {
-				return fmt.Errorf("创建VCN失败: %w", err)
+				return availabilityDomain, nil, fmt.Errorf("创建VCN失败: %w", err)
 			}
 			// 等待VCN创建完成
 			for i := 0; i < 30; i++ {
@@ -364,7 +444,7 @@ func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, r
 				time.Sleep(time.Second)
 			}
 			if targetVcn == nil {
-				return fmt.Errorf("等待VCN创建超时")
+				return availabilityDomain, nil, fmt.Errorf("等待VCN创建超时")
 			}
 		} else {
 			// 使用现有VCN的CIDR（使用CidrBlocks替代已弃用的CidrBlock）
@@ -379,37 +459,17 @@ func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, r
 			VcnId:         targetVcn.Id,
 		})
 		if err != nil {
-			return fmt.Errorf("获取Internet网关列表失败: %w", err)
+			return availabilityDomain, nil, fmt.Errorf("获取Internet网关列表失败: %w", err)
 		}
 
 		var internetGatewayId *string
 		if len(igwResp.Items) == 0 {
 			// 创建Internet网关
-			igwName := "oci-panel-gateway"
-			isEnabled := true
-			createIgwResp, err := vnClient.CreateInternetGateway(ctx, core.CreateInternetGatewayRequest{
-				CreateInternetGatewayDetails: core.CreateInternetGatewayDetails{
-					CompartmentId: &compartmentId,
-					VcnId:         targetVcn.Id,
-					DisplayName:   &igwName,
-					IsEnabled:     &isEnabled,
-				},
-			})
+			igwId, err := createInternetGateway(ctx, vnClient, compartmentId, *targetVcn.Id, "oci-panel-gateway")
 			if err != nil {
-				return fmt.Errorf("创建Internet网关失败: %w", err)
-			}
-			// 等待Internet网关创建完成
-			for i := 0; i < 30; i++ {
-				getIgwResp, err := vnClient.GetInternetGateway(ctx, core.GetInternetGatewayRequest{IgId: createIgwResp.Id})
-				if err == nil && getIgwResp.LifecycleState == core.InternetGatewayLifecycleStateAvailable {
-					internetGatewayId = createIgwResp.Id
-					break
-				}
-				time.Sleep(time.Second)
-			}
-			if internetGatewayId == nil {
-				return fmt.Errorf("等待Internet网关创建超时")
+				return availabilityDomain, nil, err
 			}
+			internetGatewayId = &igwId
 		} else {
 			internetGatewayId = igwResp.Items[0].Id
 		}
@@ -442,7 +502,7 @@ func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, r
 						},
 					})
 					if err != nil {
-						return fmt.Errorf("更新路由表失败: %w", err)
+						return availabilityDomain, nil, fmt.Errorf("更新路由表失败: %w", err)
 					}
 				}
 			}
@@ -462,7 +522,7 @@ func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, r
 			},
 		})
 		if err != nil {
-			return fmt.Errorf("创建子网失败: %w", err)
+			return availabilityDomain, nil, fmt.Errorf("创建子网失败: %w", err)
 		}
 		// 等待子网创建完成
 		for i := 0; i < 30; i++ {
@@ -474,26 +534,26 @@ func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, r
 			time.Sleep(time.Second)
 		}
 		if subnetId == "" {
-			return fmt.Errorf("等待子网创建超时")
+			return availabilityDomain, nil, fmt.Errorf("等待子网创建超时")
 		}
 	}
 
 	// 4. 确定Shape
-	shape := "VM.Standard.A1.Flex"
-	if architecture == "AMD" {
-		shape = "VM.Standard.E2.1.Micro"
-	}
+	shape := taskShape(architecture)
 
 	// 5. 获取镜像
 	var imageId string
 	if imageIdParam != "" {
-		// 使用指定的镜像ID
+		// 使用指定的镜像ID（平台镜像或自定义镜像），开机前确认与 Shape 兼容
+		if err := s.checkImageCompatibilityCached(ctx, user, imageIdParam, shape, ocpus, memory); err != nil {
+			return availabilityDomain, nil, err
+		}
 		imageId = imageIdParam
 	} else {
 		// 自动获取最新镜像
 		computeClient, err := s.GetComputeClient(user)
 		if err != nil {
-			return fmt.Errorf("获取计算客户端失败: %w", err)
+			return availabilityDomain, nil, fmt.Errorf("获取计算客户端失败: %w", err)
 		}
 
 		osName := "Canonical Ubuntu"
@@ -511,10 +571,10 @@ func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, r
 			SortOrder:       core.ListImagesSortOrderDesc,
 		})
 		if err != nil {
-			return fmt.Errorf("获取镜像列表失败: %w", err)
+			return availabilityDomain, nil, fmt.Errorf("获取镜像列表失败: %w", err)
 		}
 		if len(imageResp.Items) == 0 {
-			return fmt.Errorf("没有找到合适的镜像")
+			return availabilityDomain, nil, fmt.Errorf("没有找到合适的镜像")
 		}
 		imageId = *imageResp.Items[0].Id
 	}
@@ -535,14 +595,15 @@ func (s *OCIService) CreateInstance(ctx context.Context, user *models.OciUser, r
 		SshPublicKey:       sshPublicKey,
 		BootVolumeSizeGBs:  int64(disk),
 		BootVolumeVpuPerGB: vpusPerGB,
+		UserData:           userData,
 	}
 
-	_, err = s.LaunchInstance(ctx, user, params)
+	instance, err := s.LaunchInstance(ctx, user, params)
 	if err != nil {
-		return fmt.Errorf("创建实例失败: %w", err)
+		return availabilityDomain, nil, fmt.Errorf("创建实例失败: %w", err)
 	}
 
-	return nil
+	return availabilityDomain, instance, nil
 }
 
 // GetInstanceDetails 获取实例详细信息包括VNICs
@@ -569,6 +630,8 @@ func (s *OCIService) GetInstanceDetails(ctx context.Context, user *models.OciUse
 		PublicIPs:          []string{},
 		PrivateIPs:         []string{},
 		VnicList:           []models.VnicInfo{},
+		Protected:          IsInstanceProtected(*instance.Id),
+		SSHPort:            GetInstanceSSHPort(*instance.Id),
 	}
 
 	// 获取实例规格配置
@@ -799,6 +862,75 @@ func (s *OCIService) ListBootVolumes(ctx context.Context, user *models.OciUser,
 	return volumes, nil
 }
 
+// ListCompartments 获取租户下的区间树（根节点为租户本身）
+func (s *OCIService) ListCompartments(ctx context.Context, user *models.OciUser) (*models.CompartmentInfo, error) {
+	identityClient, err := s.GetIdentityClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &models.CompartmentInfo{
+		ID:       user.OciTenantID,
+		Name:     user.TenantName,
+		IsRoot:   true,
+		Children: []models.CompartmentInfo{},
+	}
+	if root.Name == "" {
+		root.Name = "root"
+	}
+
+	var items []identity.Compartment
+	subtree := true
+	req := identity.ListCompartmentsRequest{
+		CompartmentId:          &user.OciTenantID,
+		CompartmentIdInSubtree: &subtree,
+		AccessLevel:            identity.ListCompartmentsAccessLevelAccessible,
+		LifecycleState:         identity.CompartmentLifecycleStateActive,
+	}
+	for {
+		resp, err := identityClient.ListCompartments(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, resp.Items...)
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+
+	// 按父区间分组后递归构建树
+	childrenOf := make(map[string][]identity.Compartment)
+	for _, item := range items {
+		if item.CompartmentId != nil {
+			childrenOf[*item.CompartmentId] = append(childrenOf[*item.CompartmentId], item)
+		}
+	}
+
+	var build func(parentID string) []models.CompartmentInfo
+	build = func(parentID string) []models.CompartmentInfo {
+		nodes := []models.CompartmentInfo{}
+		for _, item := range childrenOf[parentID] {
+			node := models.CompartmentInfo{
+				ID:       *item.Id,
+				ParentID: parentID,
+			}
+			if item.Name != nil {
+				node.Name = *item.Name
+			}
+			if item.Description != nil {
+				node.Description = *item.Description
+			}
+			node.Children = build(node.ID)
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	root.Children = build(user.OciTenantID)
+
+	return root, nil
+}
+
 // ListVCNs 列出虚拟云网络
 func (s *OCIService) ListVCNs(ctx context.Context, user *models.OciUser, compartmentId string) ([]models.VCNInfo, error) {
 	client, err := s.GetVirtualNetworkClient(user)
@@ -904,24 +1036,8 @@ func (s *OCIService) ChangePublicIP(ctx context.Context, user *models.OciUser, v
 	}
 
 	// Step 1: 如果有现有公网IP，先删除
-	if vnicResp.PublicIp != nil && *vnicResp.PublicIp != "" {
-		// 通过IP地址获取Public IP的OCID
-		getPublicIpReq := core.GetPublicIpByIpAddressRequest{
-			GetPublicIpByIpAddressDetails: core.GetPublicIpByIpAddressDetails{
-				IpAddress: vnicResp.PublicIp,
-			},
-		}
-		getPublicIpResp, err := vnClient.GetPublicIpByIpAddress(ctx, getPublicIpReq)
-		if err != nil {
-			return "", fmt.Errorf("failed to get public IP by address: %w", err)
-		}
-
-		// 删除现有公网IP
-		deleteReq := core.DeletePublicIpRequest{PublicIpId: getPublicIpResp.Id}
-		_, err = vnClient.DeletePublicIp(ctx, deleteReq)
-		if err != nil {
-			return "", fmt.Errorf("failed to delete public IP: %w", err)
-		}
+	if err := deleteVnicPublicIP(ctx, vnClient, &vnicResp.Vnic); err != nil {
+		return "", err
 	}
 
 	// Step 2: 获取VNIC的私有IP的OCID（这是关键，不能使用IP地址字符串）
@@ -952,81 +1068,125 @@ func (s *OCIService) ChangePublicIP(ctx context.Context, user *models.OciUser, v
 	return *createResp.IpAddress, nil
 }
 
-// UpdateInstanceShape 更新实例配置（CPU和内存）
-// autoRestart: 是否在更新后自动重启实例
-func (s *OCIService) UpdateInstanceShape(ctx context.Context, user *models.OciUser, instanceId string, ocpus float32, memoryInGBs float32, autoRestart bool) error {
+// UpdateInstanceShape 更新实例 Shape 及 CPU/内存（4步骤）：校验镜像兼容性、关机、更新、按需开机
+// autoRestart: 是否在更新后自动重启实例（如果实例原来是运行状态）
+// progressChan 为 nil 时不报告进度；更新失败时原本运行中的实例会重新启动，跨架构时返回 *CrossArchitectureError
+func (s *OCIService) UpdateInstanceShape(ctx context.Context, user *models.OciUser, instanceId string, shape string, ocpus float32, memoryInGBs float32, autoRestart bool, progressChan chan<- AutoRescueProgress) (*ShapeChangeResult, error) {
+	const totalSteps = 4
+
+	sendProgress := func(step int, status, message string) {
+		if progressChan != nil {
+			progressChan <- AutoRescueProgress{
+				Step:       step,
+				TotalSteps: totalSteps,
+				Status:     status,
+				Message:    message,
+			}
+		}
+	}
+
 	client, err := s.GetComputeClient(user)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 获取当前实例信息
 	instance, err := s.GetInstance(ctx, user, instanceId)
 	if err != nil {
-		return fmt.Errorf("failed to get instance: %w", err)
+		return nil, fmt.Errorf("failed to get instance: %w", err)
 	}
 
-	// 记录原始状态，以便决定是否需要重启
-	wasRunning := instance.LifecycleState == core.InstanceLifecycleStateRunning
+	// 跨架构无法原地调整，需要走重建流程
+	currentShape := *instance.Shape
+	targetShape := currentShape
+	if shape != "" && shape != targetShape {
+		if ShapeArchitecture(shape) != ShapeArchitecture(targetShape) {
+			return nil, &CrossArchitectureError{CurrentShape: targetShape, TargetShape: shape}
+		}
+		targetShape = shape
+	}
+	// Flex Shape 必须指定 OCPU 和内存，在关机前拒绝，避免实例停止后更新失败
+	if IsFlexShape(targetShape) && (ocpus <= 0 || memoryInGBs <= 0) {
+		return nil, fmt.Errorf("Flex Shape 需要指定 OCPU 和内存")
+	}
+	if instance.LifecycleState != core.InstanceLifecycleStateRunning && instance.LifecycleState != core.InstanceLifecycleStateStopped {
+		return nil, fmt.Errorf("instance is in %s state, cannot update config", instance.LifecycleState)
+	}
+
+	// Step 1: 原镜像需支持目标 Shape，镜像已被删除时无法校验，交由 OCI 判断
+	sendProgress(1, "running", "正在校验镜像兼容性...")
+	if source, ok := instance.SourceDetails.(core.InstanceSourceViaImageDetails); ok && source.ImageId != nil {
+		if _, err := client.GetImage(ctx, core.GetImageRequest{ImageId: source.ImageId}); err == nil {
+			if _, err := s.CheckImageCompatibility(ctx, user, *source.ImageId, targetShape, float64(ocpus), float64(memoryInGBs)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sendProgress(1, "completed", "镜像兼容性校验通过")
 
-	// 如果实例正在运行，需要先停止
-	if instance.LifecycleState == core.InstanceLifecycleStateRunning {
-		// 停止实例
+	// Step 2: 如果实例正在运行，需要先停止
+	wasRunning := instance.LifecycleState == core.InstanceLifecycleStateRunning
+	sendProgress(2, "running", "正在关机...")
+	if wasRunning {
 		_, err = client.InstanceAction(ctx, core.InstanceActionRequest{
 			InstanceId: instance.Id,
 			Action:     core.InstanceActionActionStop,
 		})
 		if err != nil {
-			return fmt.Errorf("failed to stop instance: %w", err)
+			return nil, fmt.Errorf("failed to stop instance: %w", err)
+		}
+		if err := s.waitInstanceState(ctx, client, instanceId, core.InstanceLifecycleStateStopped); err != nil {
+			return nil, err
 		}
+	}
+	sendProgress(2, "completed", "关机成功")
 
-		// 等待实例停止
-		for {
-			instResp, err := client.GetInstance(ctx, core.GetInstanceRequest{InstanceId: instance.Id})
-			if err != nil {
-				return fmt.Errorf("failed to get instance status: %w", err)
-			}
-			if instResp.LifecycleState == core.InstanceLifecycleStateStopped {
-				break
-			}
-			if instResp.LifecycleState == core.InstanceLifecycleStateTerminated {
-				return fmt.Errorf("instance was terminated unexpectedly")
+	// Step 3: 更新实例配置
+	sendProgress(3, "running", fmt.Sprintf("正在调整为 %s...", targetShape))
+	_, err = client.UpdateInstance(ctx, core.UpdateInstanceRequest{
+		InstanceId:            &instanceId,
+		UpdateInstanceDetails: shapeUpdateDetails(currentShape, targetShape, ocpus, memoryInGBs),
+	})
+	if err == nil {
+		err = s.waitInstanceState(ctx, client, instanceId, core.InstanceLifecycleStateStopped)
+	}
+	if err != nil {
+		// 调整失败时恢复原实例运行，任务可能已被取消，使用新的 context
+		if wasRunning {
+			if _, startErr := client.InstanceAction(context.Background(), core.InstanceActionRequest{
+				InstanceId: instance.Id,
+				Action:     core.InstanceActionActionStart,
+			}); startErr != nil {
+				log.Printf("[ShapeChange] Failed to restart instance %s: %v", instanceId, startErr)
 			}
-			time.Sleep(3 * time.Second)
 		}
-	} else if instance.LifecycleState != core.InstanceLifecycleStateStopped {
-		return fmt.Errorf("instance is in %s state, cannot update config", instance.LifecycleState)
+		return nil, fmt.Errorf("failed to update instance config: %s", extractOCIErrorMessage(err))
 	}
+	sendProgress(3, "completed", fmt.Sprintf("已调整为 %s", targetShape))
 
-	// 更新实例配置
-	req := core.UpdateInstanceRequest{
-		InstanceId: &instanceId,
-		UpdateInstanceDetails: core.UpdateInstanceDetails{
-			ShapeConfig: &core.UpdateInstanceShapeConfigDetails{
-				Ocpus:       &ocpus,
-				MemoryInGBs: &memoryInGBs,
-			},
-		},
+	result := &ShapeChangeResult{
+		PreviousShape: currentShape,
+		Shape:         targetShape,
+		State:         string(core.InstanceLifecycleStateStopped),
 	}
 
-	_, err = client.UpdateInstance(ctx, req)
-	if err != nil {
-		return fmt.Errorf("failed to update instance config: %w", err)
+	// Step 4: 如果需要自动重启，且实例之前是运行状态
+	if !autoRestart || !wasRunning {
+		sendProgress(4, "completed", "实例保持关机状态")
+		return result, nil
 	}
-
-	// 如果需要自动重启，且实例之前是运行状态
-	if autoRestart && wasRunning {
-		// 启动实例
-		_, err = client.InstanceAction(ctx, core.InstanceActionRequest{
-			InstanceId: instance.Id,
-			Action:     core.InstanceActionActionStart,
-		})
-		if err != nil {
-			return fmt.Errorf("config updated but failed to restart instance: %w", err)
-		}
+	sendProgress(4, "running", "正在开机...")
+	_, err = client.InstanceAction(ctx, core.InstanceActionRequest{
+		InstanceId: instance.Id,
+		Action:     core.InstanceActionActionStart,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config updated but failed to restart instance: %w", err)
 	}
+	result.State = string(core.InstanceLifecycleStateStarting)
+	sendProgress(4, "completed", "已发送开机请求，实例正在启动")
 
-	return nil
+	return result, nil
 }
 
 // UpdateBootVolume 更新引导卷配置
@@ -1110,6 +1270,19 @@ func (s *OCIService) GetConsoleConnectionString(ctx context.Context, user *model
 	return connectionString, nil
 }
 
+// DeleteConsoleConnection 删除控制台连接
+func (s *OCIService) DeleteConsoleConnection(ctx context.Context, user *models.OciUser, connectionId string) error {
+	client, err := s.GetComputeClient(user)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.DeleteInstanceConsoleConnection(ctx, core.DeleteInstanceConsoleConnectionRequest{
+		InstanceConsoleConnectionId: &connectionId,
+	})
+	return err
+}
+
 // GetTenantInfo 获取租户详情
 func (s *OCIService) GetTenantInfo(ctx context.Context, user *models.OciUser) (*models.TenantInfo, error) {
 	identityClient, err := s.GetIdentityClient(user)
@@ -1358,6 +1531,7 @@ func (s *OCIService) GetTrafficData(ctx context.Context, user *models.OciUser, v
 	if err != nil {
 		return nil, err
 	}
+	guardClient(user, &monitoringClient.BaseClient)
 
 	trafficData := &models.TrafficData{
 		Time:     []string{},
@@ -1646,25 +1820,15 @@ func (s *OCIService) CreateIpv6ByInstanceId(ctx context.Context, user *models.Oc
 		return "", fmt.Errorf("failed to get subnet: %w", err)
 	}
 
-	// 获取VCN信息
-	vcnReq := core.GetVcnRequest{VcnId: subnetResp.VcnId}
-	vcnResp, err := vnClient.GetVcn(ctx, vcnReq)
-	if err != nil {
-		return "", fmt.Errorf("failed to get VCN: %w", err)
-	}
-
-	// 检查VCN是否启用了IPv6
-	if len(vcnResp.Ipv6CidrBlocks) == 0 {
-		return "", fmt.Errorf("VCN does not have IPv6 enabled. Please enable IPv6 on VCN first via Oracle Cloud Console")
+	// 检查子网是否分配了 IPv6 前缀，地址从子网的 /64 中分配
+	if len(subnetResp.Ipv6CidrBlocks) == 0 && subnetResp.Ipv6CidrBlock == nil {
+		return "", fmt.Errorf("subnet does not have IPv6 enabled, enable IPv6 on the VCN and subnet first")
 	}
 
-	ipv6SubnetCidr := vcnResp.Ipv6CidrBlocks[0]
-
 	// 创建IPv6
 	createIpv6Req := core.CreateIpv6Request{
 		CreateIpv6Details: core.CreateIpv6Details{
-			VnicId:         vnicId,
-			Ipv6SubnetCidr: &ipv6SubnetCidr,
+			VnicId: vnicId,
 		},
 	}
 	createIpv6Resp, err := vnClient.CreateIpv6(ctx, createIpv6Req)
@@ -1751,6 +1915,71 @@ func (s *OCIService) GetNetworkLoadBalancerClient(user *models.OciUser) (network
 	if err != nil {
 		return networkloadbalancer.NetworkLoadBalancerClient{}, err
 	}
+	guardClient(user, &client.BaseClient)
+
+	return client, nil
+}
+
+// GetNotificationControlPlaneClient 获取通知主题管理客户端
+func (s *OCIService) GetNotificationControlPlaneClient(user *models.OciUser) (ons.NotificationControlPlaneClient, error) {
+	configProvider, err := s.GetConfigProvider(user)
+	if err != nil {
+		return ons.NotificationControlPlaneClient{}, err
+	}
+
+	client, err := ons.NewNotificationControlPlaneClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return ons.NotificationControlPlaneClient{}, err
+	}
+	guardClient(user, &client.BaseClient)
+
+	return client, nil
+}
+
+// GetNotificationDataPlaneClient 获取通知订阅客户端
+func (s *OCIService) GetNotificationDataPlaneClient(user *models.OciUser) (ons.NotificationDataPlaneClient, error) {
+	configProvider, err := s.GetConfigProvider(user)
+	if err != nil {
+		return ons.NotificationDataPlaneClient{}, err
+	}
+
+	client, err := ons.NewNotificationDataPlaneClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return ons.NotificationDataPlaneClient{}, err
+	}
+	guardClient(user, &client.BaseClient)
+
+	return client, nil
+}
+
+// GetEventsClient 获取事件规则客户端
+func (s *OCIService) GetEventsClient(user *models.OciUser) (events.EventsClient, error) {
+	configProvider, err := s.GetConfigProvider(user)
+	if err != nil {
+		return events.EventsClient{}, err
+	}
+
+	client, err := events.NewEventsClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return events.EventsClient{}, err
+	}
+	guardClient(user, &client.BaseClient)
+
+	return client, nil
+}
+
+// GetObjectStorageClient 获取对象存储客户端
+func (s *OCIService) GetObjectStorageClient(user *models.OciUser) (objectstorage.ObjectStorageClient, error) {
+	configProvider, err := s.GetConfigProvider(user)
+	if err != nil {
+		return objectstorage.ObjectStorageClient{}, err
+	}
+
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		return objectstorage.ObjectStorageClient{}, err
+	}
+	guardClient(user, &client.BaseClient)
 
 	return client, nil
 }
@@ -1770,6 +1999,7 @@ type AutoRescueProgress struct {
 	Message     string `json:"message"`
 	PublicIP    string `json:"publicIp,omitempty"`
 	SSHPassword string `json:"sshPassword,omitempty"`
+	ResourceID  string `json:"resourceId,omitempty"` // 本步骤创建的资源
 }
 
 // AutoRescue 自动救援/缩小硬盘 (9步骤)
@@ -1801,6 +2031,17 @@ func (s *OCIService) AutoRescue(user *models.OciUser, params AutoRescueParams, p
 			}
 		}
 	}
+	sendCreated := func(step int, message, resourceID string) {
+		if progressChan != nil {
+			progressChan <- AutoRescueProgress{
+				Step:       step,
+				TotalSteps: 9,
+				Status:     "completed",
+				Message:    message,
+				ResourceID: resourceID,
+			}
+		}
+	}
 
 	// 获取实例信息
 	instance, err := s.GetInstanceById(user, params.InstanceID)
@@ -1863,7 +2104,7 @@ func (s *OCIService) AutoRescue(user *models.OciUser, params AutoRescueParams, p
 		return fmt.Errorf("failed to create boot volume backup: %w", err)
 	}
 	backupId := backupResp.Id
-	sendProgress(2, "completed", "备份原引导卷成功")
+	sendCreated(2, "备份原引导卷成功", *backupId)
 
 	time.Sleep(3 * time.Second)
 
@@ -1892,15 +2133,8 @@ func (s *OCIService) AutoRescue(user *models.OciUser, params AutoRescueParams, p
 	sendProgress(3, "completed", "分离原引导卷成功")
 
 	// 等待备份完成
-	for {
-		backupStatusResp, err := blockClient.GetBootVolumeBackup(ctx, core.GetBootVolumeBackupRequest{BootVolumeBackupId: backupId})
-		if err != nil {
-			return fmt.Errorf("failed to get backup status: %w", err)
-		}
-		if backupStatusResp.LifecycleState == core.BootVolumeBackupLifecycleStateAvailable {
-			break
-		}
-		time.Sleep(2 * time.Second)
+	if err := waitBootVolumeBackupAvailable(ctx, blockClient, *backupId); err != nil {
+		return err
 	}
 
 	// Step 4: 删除原引导卷
@@ -1929,41 +2163,26 @@ func (s *OCIService) AutoRescue(user *models.OciUser, params AutoRescueParams, p
 	sendProgress(5, "running", "正在创建47GB引导卷...")
 	newBvName := "Restored-Boot-Volume-47GB"
 	sizeInGBs := int64(47)
-	newBvResp, err := blockClient.CreateBootVolume(ctx, core.CreateBootVolumeRequest{
-		CreateBootVolumeDetails: core.CreateBootVolumeDetails{
-			CompartmentId:      instance.CompartmentId,
-			AvailabilityDomain: instance.AvailabilityDomain,
-			DisplayName:        &newBvName,
-			SizeInGBs:          &sizeInGBs,
-			SourceDetails: core.BootVolumeSourceFromBootVolumeBackupDetails{
-				Id: backupId,
-			},
+	newBvId, err := s.restoreBootVolume(ctx, user, core.CreateBootVolumeDetails{
+		CompartmentId:      instance.CompartmentId,
+		AvailabilityDomain: instance.AvailabilityDomain,
+		DisplayName:        &newBvName,
+		SizeInGBs:          &sizeInGBs,
+		SourceDetails: core.BootVolumeSourceFromBootVolumeBackupDetails{
+			Id: backupId,
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create new boot volume: %w", err)
-	}
-	newBvId := newBvResp.Id
-
-	// 等待新引导卷可用
-	for {
-		bvResp, err := blockClient.GetBootVolume(ctx, core.GetBootVolumeRequest{BootVolumeId: newBvId})
-		if err != nil {
-			return fmt.Errorf("failed to get new boot volume status: %w", err)
-		}
-		if bvResp.LifecycleState == core.BootVolumeLifecycleStateAvailable {
-			break
-		}
-		time.Sleep(2 * time.Second)
+		return err
 	}
-	sendProgress(5, "completed", "创建47GB引导卷成功")
+	sendCreated(5, "创建47GB引导卷成功", newBvId)
 
 	// Step 6: 附加新引导卷到实例
 	sendProgress(6, "running", "正在附加新引导卷到实例...")
 	attachName := "New-Boot-Volume"
-	_, err = computeClient.AttachBootVolume(ctx, core.AttachBootVolumeRequest{
+	attachResp, err := computeClient.AttachBootVolume(ctx, core.AttachBootVolumeRequest{
 		AttachBootVolumeDetails: core.AttachBootVolumeDetails{
-			BootVolumeId: newBvId,
+			BootVolumeId: &newBvId,
 			InstanceId:   instance.Id,
 			DisplayName:  &attachName,
 		},
@@ -1991,7 +2210,9 @@ func (s *OCIService) AutoRescue(user *models.OciUser, params AutoRescueParams, p
 
 	// Step 8: 等待引导卷附件完成
 	sendProgress(8, "running", "正在等待引导卷附件完成...")
-	time.Sleep(5 * time.Second)
+	if err := waitBootVolumeAttachmentState(ctx, computeClient, *attachResp.Id, core.BootVolumeAttachmentLifecycleStateAttached); err != nil {
+		return err
+	}
 	sendProgress(8, "completed", "引导卷附件完成")
 
 	// Step 9: 启动实例
@@ -2053,9 +2274,8 @@ func (s *OCIService) Check500MbpsSupport(user *models.OciUser, instanceID string
 	return supported, shape, nil
 }
 
-// Enable500Mbps 一键开启下行500Mbps
-func (s *OCIService) Enable500Mbps(user *models.OciUser, instanceID string, sshPort int) (string, error) {
-	ctx := context.Background()
+// Enable500Mbps 一键开启下行500Mbps，ports 为空时转发所有端口
+func (s *OCIService) Enable500Mbps(ctx context.Context, user *models.OciUser, instanceID string, sshPort int, ports []NLBPortForward) (string, error) {
 
 	vnClient, err := s.GetVirtualNetworkClient(user)
 	if err != nil {
@@ -2097,6 +2317,11 @@ func (s *OCIService) Enable500Mbps(user *models.OciUser, instanceID string, sshP
 	}
 	privateIP := *privateIpResp.Items[0].IpAddress
 
+	forwarding, err := buildNLBForwarding(*instance.Id, privateIP, sshPort, ports)
+	if err != nil {
+		return "", err
+	}
+
 	compartmentID := *instance.CompartmentId
 
 	// 创建或获取NAT网关
@@ -2114,30 +2339,11 @@ func (s *OCIService) Enable500Mbps(user *models.OciUser, instanceID string, sshP
 		natGatewayId = natGatewayResp.Items[0].Id
 	} else {
 		// 创建NAT网关
-		natName := "nat-gateway"
-		createNatResp, err := vnClient.CreateNatGateway(ctx, core.CreateNatGatewayRequest{
-			CreateNatGatewayDetails: core.CreateNatGatewayDetails{
-				CompartmentId: &compartmentID,
-				VcnId:         vcn.Id,
-				DisplayName:   &natName,
-			},
-		})
+		natId, err := createNatGateway(ctx, vnClient, compartmentID, *vcn.Id, "nat-gateway", false)
 		if err != nil {
-			return "", fmt.Errorf("failed to create NAT gateway: %w", err)
-		}
-		natGatewayId = createNatResp.Id
-
-		// 等待NAT网关可用
-		for {
-			natResp, err := vnClient.GetNatGateway(ctx, core.GetNatGatewayRequest{NatGatewayId: natGatewayId})
-			if err != nil {
-				return "", fmt.Errorf("failed to get NAT gateway status: %w", err)
-			}
-			if natResp.LifecycleState == core.NatGatewayLifecycleStateAvailable {
-				break
-			}
-			time.Sleep(2 * time.Second)
+			return "", err
 		}
+		natGatewayId = &natId
 	}
 
 	// 获取子网
@@ -2167,10 +2373,6 @@ func (s *OCIService) Enable500Mbps(user *models.OciUser, instanceID string, sshP
 	// 创建网络负载均衡器
 	nlbName := fmt.Sprintf("nlb-%s", time.Now().Format("20060102150405"))
 	isPrivate := false
-	port := 0
-	weight := 1
-	isPreserveSource := true
-	isFailOpen := true
 
 	createNlbResp, err := nlbClient.CreateNetworkLoadBalancer(ctx, networkloadbalancer.CreateNetworkLoadBalancerRequest{
 		CreateNetworkLoadBalancerDetails: networkloadbalancer.CreateNetworkLoadBalancerDetails{
@@ -2178,33 +2380,8 @@ func (s *OCIService) Enable500Mbps(user *models.OciUser, instanceID string, sshP
 			DisplayName:   &nlbName,
 			SubnetId:      subnetId,
 			IsPrivate:     &isPrivate,
-			Listeners: map[string]networkloadbalancer.ListenerDetails{
-				"listener1": {
-					Name:                  stringPtr("listener1"),
-					DefaultBackendSetName: stringPtr("backend1"),
-					Protocol:              networkloadbalancer.ListenerProtocolsTcpAndUdp,
-					Port:                  &port,
-				},
-			},
-			BackendSets: map[string]networkloadbalancer.BackendSetDetails{
-				"backend1": {
-					Policy:           networkloadbalancer.NetworkLoadBalancingPolicyTwoTuple,
-					IsPreserveSource: &isPreserveSource,
-					IsFailOpen:       &isFailOpen,
-					HealthChecker: &networkloadbalancer.HealthChecker{
-						Protocol: networkloadbalancer.HealthCheckProtocolsTcp,
-						Port:     &sshPort,
-					},
-					Backends: []networkloadbalancer.Backend{
-						{
-							IpAddress: &privateIP,
-							TargetId:  instance.Id,
-							Port:      &port,
-							Weight:    &weight,
-						},
-					},
-				},
-			},
+			Listeners:     forwarding.Listeners,
+			BackendSets:   forwarding.BackendSets,
 		},
 	})
 	if err != nil {
@@ -2323,8 +2500,7 @@ func (s *OCIService) Enable500Mbps(user *models.OciUser, instanceID string, sshP
 }
 
 // Disable500Mbps 关闭下行500Mbps
-func (s *OCIService) Disable500Mbps(user *models.OciUser, instanceID string, retainNatGw, retainNlb bool) error {
-	ctx := context.Background()
+func (s *OCIService) Disable500Mbps(ctx context.Context, user *models.OciUser, instanceID string, retainNatGw, retainNlb bool) error {
 
 	vnClient, err := s.GetVirtualNetworkClient(user)
 	if err != nil {
@@ -2991,42 +3167,63 @@ type MonthlyTrafficStats struct {
 	OutboundTraffic int64
 }
 
-// GetMonthlyTrafficStats 获取指定配置的月度流量统计
-func (s *OCIService) GetMonthlyTrafficStats(ctx context.Context, user *models.OciUser) (*MonthlyTrafficStats, error) {
+// dailyTraffic 单日流量
+type dailyTraffic struct {
+	Inbound  int64
+	Outbound int64
+}
+
+// queryDailyTraffic 按天查询指定时间范围内所有实例VNIC的流量，返回按日期（2006-01-02）汇总的结果与实例数
+func (s *OCIService) queryDailyTraffic(ctx context.Context, user *models.OciUser, startTime, endTime time.Time) (map[string]*dailyTraffic, int, error) {
 	configProvider, err := s.GetConfigProvider(user)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	computeClient, err := core.NewComputeClientWithConfigurationProvider(configProvider)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	guardClient(user, &computeClient.BaseClient)
 
 	vnClient, err := core.NewVirtualNetworkClientWithConfigurationProvider(configProvider)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	guardClient(user, &vnClient.BaseClient)
 
 	monitoringClient, err := monitoring.NewMonitoringClientWithConfigurationProvider(configProvider)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	guardClient(user, &monitoringClient.BaseClient)
 
 	compartmentId := user.OciTenantID
-	stats := &MonthlyTrafficStats{}
+	days := make(map[string]*dailyTraffic)
+	addDatapoints := func(items []monitoring.MetricData, inbound bool) {
+		for _, item := range items {
+			for _, dp := range item.AggregatedDatapoints {
+				if dp.Value == nil || dp.Timestamp == nil {
+					continue
+				}
+				day := dp.Timestamp.Time.In(startTime.Location()).Format(trafficDayLayout)
+				if days[day] == nil {
+					days[day] = &dailyTraffic{}
+				}
+				if inbound {
+					days[day].Inbound += int64(*dp.Value)
+				} else {
+					days[day].Outbound += int64(*dp.Value)
+				}
+			}
+		}
+	}
 
 	// 获取实例列表
 	instances, err := s.ListInstances(ctx, user, compartmentId)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	stats.InstanceCount = len(instances)
-
-	// 获取本月时间范围
-	now := time.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Second)
 
 	// 遍历每个实例获取VNIC流量
 	for _, instance := range instances {
@@ -3069,19 +3266,13 @@ func (s *OCIService) GetMonthlyTrafficStats(ctx context.Context, user *models.Oc
 				SummarizeMetricsDataDetails: monitoring.SummarizeMetricsDataDetails{
 					Namespace: stringPtr("oci_vcn"),
 					Query:     &inQuery,
-					StartTime: &common.SDKTime{Time: startOfMonth},
-					EndTime:   &common.SDKTime{Time: endOfMonth},
+					StartTime: &common.SDKTime{Time: startTime},
+					EndTime:   &common.SDKTime{Time: endTime},
 				},
 			}
 			inResp, err := monitoringClient.SummarizeMetricsData(ctx, inReq)
 			if err == nil {
-				for _, item := range inResp.Items {
-					for _, dp := range item.AggregatedDatapoints {
-						if dp.Value != nil {
-							stats.InboundTraffic += int64(*dp.Value)
-						}
-					}
-				}
+				addDatapoints(inResp.Items, true)
 			}
 
 			// 查询出站流量 (VnicFromNetworkBytes)
@@ -3091,24 +3282,18 @@ func (s *OCIService) GetMonthlyTrafficStats(ctx context.Context, user *models.Oc
 				SummarizeMetricsDataDetails: monitoring.SummarizeMetricsDataDetails{
 					Namespace: stringPtr("oci_vcn"),
 					Query:     &outQuery,
-					StartTime: &common.SDKTime{Time: startOfMonth},
-					EndTime:   &common.SDKTime{Time: endOfMonth},
+					StartTime: &common.SDKTime{Time: startTime},
+					EndTime:   &common.SDKTime{Time: endTime},
 				},
 			}
 			outResp, err := monitoringClient.SummarizeMetricsData(ctx, outReq)
 			if err == nil {
-				for _, item := range outResp.Items {
-					for _, dp := range item.AggregatedDatapoints {
-						if dp.Value != nil {
-							stats.OutboundTraffic += int64(*dp.Value)
-						}
-					}
-				}
+				addDatapoints(outResp.Items, false)
 			}
 		}
 	}
 
-	return stats, nil
+	return days, len(instances), nil
 }
 
 // FormatBytes 格式化字节数为人类可读格式
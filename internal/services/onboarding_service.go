@@ -0,0 +1,424 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/config"
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	SettingAdminAccount      = "admin_account"
+	SettingAdminPasswordHash = "admin_password_hash"
+	SettingOnboardingState   = "onboarding_state"
+
+	OnboardingStepAdmin     = "admin"
+	OnboardingStepOciConfig = "oci_config"
+	OnboardingStepSSHKey    = "ssh_key"
+	OnboardingStepTelegram  = "telegram"
+
+	OnboardingStatusPending = "pending"
+	OnboardingStatusDone    = "done"
+	OnboardingStatusSkipped = "skipped"
+
+	// 示例配置中的默认账号密码，视为尚未设置管理员
+	defaultAdminAccount  = "admin"
+	defaultAdminPassword = "admin"
+)
+
+// onboardingSteps 引导步骤顺序
+var onboardingSteps = []string{
+	OnboardingStepAdmin,
+	OnboardingStepOciConfig,
+	OnboardingStepSSHKey,
+	OnboardingStepTelegram,
+}
+
+// OnboardingStep 单个引导步骤状态
+type OnboardingStep struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	UpdateTime string `json:"updateTime,omitempty"`
+}
+
+// OnboardingState 首次使用引导进度，保存在 sys_setting 中以便中断后继续
+type OnboardingState struct {
+	Completed bool                       `json:"completed"`
+	Steps     map[string]*OnboardingStep `json:"steps"`
+	ConfigID  string                     `json:"configId,omitempty"`
+	SSHKeyID  string                     `json:"sshKeyId,omitempty"`
+}
+
+// OnboardingStatus 引导进度响应
+type OnboardingStatus struct {
+	Required  bool              `json:"required"`
+	Completed bool              `json:"completed"`
+	NextStep  string            `json:"nextStep"`
+	Steps     []*OnboardingStep `json:"steps"`
+	ConfigID  string            `json:"configId,omitempty"`
+	SSHKeyID  string            `json:"sshKeyId,omitempty"`
+}
+
+// OnboardingOciConfig 引导中导入的 OCI 配置
+type OnboardingOciConfig struct {
+	Username       string
+	OciTenantID    string
+	OciUserID      string
+	OciFingerprint string
+	OciRegion      string
+	PrivateKey     string // PEM 内容，与 OciKeyPath 二选一
+	OciKeyPath     string // 已通过 /oci/uploadKey 上传的文件名
+}
+
+// OnboardingSSHKey 引导中生成的 SSH 密钥
+type OnboardingSSHKey struct {
+	Key        *models.SSHKey `json:"key"`
+	PrivateKey string         `json:"privateKey,omitempty"` // 仅首次生成时返回
+}
+
+type OnboardingService struct {
+	cfg             *config.Config
+	ociService      *OCIService
+	telegramService *TelegramService
+	mutex           sync.Mutex
+}
+
+func NewOnboardingService(cfg *config.Config, ociService *OCIService, telegramService *TelegramService) *OnboardingService {
+	s := &OnboardingService{
+		cfg:             cfg,
+		ociService:      ociService,
+		telegramService: telegramService,
+	}
+	s.loadAdminAccount()
+	return s
+}
+
+// loadAdminAccount 使用引导中设置的管理员账号覆盖配置文件中的账号
+func (s *OnboardingService) loadAdminAccount() {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingAdminAccount).First(&setting).Error; err == nil && setting.Value != "" {
+		s.cfg.Web.Account = setting.Value
+	}
+}
+
+// VerifyAdminCredentials 校验管理员账号密码，引导中设置过密码时优先使用数据库中的密码哈希
+func VerifyAdminCredentials(cfg *config.Config, account, password string) bool {
+	if account != cfg.Web.Account {
+		return false
+	}
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingAdminPasswordHash).First(&setting).Error; err == nil && setting.Value != "" {
+		return bcrypt.CompareHashAndPassword([]byte(setting.Value), []byte(password)) == nil
+	}
+	return password == cfg.Web.Password
+}
+
+// AdminSetupPending 是否仍可在未登录状态下设置管理员：未设置过管理员、仍为默认账号密码且尚无任何配置
+func (s *OnboardingService) AdminSetupPending() bool {
+	db := database.GetDB()
+	var count int64
+	db.Model(&models.SysSetting{}).Where("key = ?", SettingAdminPasswordHash).Count(&count)
+	if count > 0 {
+		return false
+	}
+	if s.cfg.Web.Password != "" &&
+		(s.cfg.Web.Account != defaultAdminAccount || s.cfg.Web.Password != defaultAdminPassword) {
+		return false
+	}
+	db.Model(&models.OciUser{}).Count(&count)
+	return count == 0
+}
+
+// GetStatus 返回引导进度
+func (s *OnboardingService) GetStatus() *OnboardingStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := s.loadState()
+	status := &OnboardingStatus{
+		Completed: state.Completed,
+		ConfigID:  state.ConfigID,
+		SSHKeyID:  state.SSHKeyID,
+	}
+	for _, name := range onboardingSteps {
+		step := state.Steps[name]
+		status.Steps = append(status.Steps, step)
+		if status.NextStep == "" && step.Status == OnboardingStatusPending {
+			status.NextStep = name
+		}
+	}
+
+	var configCount int64
+	database.GetDB().Model(&models.OciUser{}).Count(&configCount)
+	status.Required = !state.Completed && configCount == 0
+	return status
+}
+
+// SetupAdmin 设置管理员账号密码；已设置过时仅校验账号密码，便于重复提交
+func (s *OnboardingService) SetupAdmin(account, password string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := s.loadState()
+	if state.Steps[OnboardingStepAdmin].Status == OnboardingStatusDone || !s.AdminSetupPending() {
+		if !VerifyAdminCredentials(s.cfg, account, password) {
+			return fmt.Errorf("管理员已设置，请使用已设置的账号密码")
+		}
+		s.markStep(state, OnboardingStepAdmin, OnboardingStatusDone, "")
+		return s.saveState(state)
+	}
+
+	if len(password) < 8 || password == defaultAdminPassword {
+		return fmt.Errorf("密码至少 8 位且不能使用默认密码")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := saveSetting(SettingAdminAccount, account); err != nil {
+		return err
+	}
+	if err := saveSetting(SettingAdminPasswordHash, string(hash)); err != nil {
+		return err
+	}
+	s.cfg.Web.Account = account
+
+	s.markStep(state, OnboardingStepAdmin, OnboardingStatusDone, "")
+	return s.saveState(state)
+}
+
+// ImportOciConfig 校验并导入第一个 OCI 配置；相同租户与 API 密钥的配置已存在时直接复用
+func (s *OnboardingService) ImportOciConfig(params OnboardingOciConfig) (*models.OciUser, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	db := database.GetDB()
+	state := s.loadState()
+
+	var existing models.OciUser
+	if err := db.Where("oci_tenant_id = ? AND oci_user_id = ? AND oci_fingerprint = ?",
+		params.OciTenantID, params.OciUserID, params.OciFingerprint).First(&existing).Error; err == nil {
+		state.ConfigID = existing.ID
+		s.markStep(state, OnboardingStepOciConfig, OnboardingStatusDone, "")
+		return &existing, s.saveState(state)
+	}
+
+	keyPath := params.OciKeyPath
+	writtenKey := ""
+	if params.PrivateKey != "" {
+		if block, _ := pem.Decode([]byte(params.PrivateKey)); block == nil {
+			return nil, fmt.Errorf("私钥格式无效")
+		}
+		if err := os.MkdirAll("./keys", 0755); err != nil {
+			return nil, err
+		}
+		keyPath = uuid.New().String() + ".pem"
+		writtenKey = filepath.Join("./keys", keyPath)
+		if err := os.WriteFile(writtenKey, []byte(params.PrivateKey), 0600); err != nil {
+			return nil, err
+		}
+	}
+	if keyPath == "" {
+		return nil, fmt.Errorf("请上传或填写 API 私钥")
+	}
+
+	user := models.OciUser{
+		ID:             uuid.New().String(),
+		Username:       params.Username,
+		OciTenantID:    params.OciTenantID,
+		OciUserID:      params.OciUserID,
+		OciFingerprint: params.OciFingerprint,
+		OciRegion:      params.OciRegion,
+		OciKeyPath:     keyPath,
+		CreateTime:     time.Now(),
+	}
+
+	// 通过查询租户信息验证配置是否可用
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	tenantInfo, err := s.ociService.GetTenantInfo(ctx, &user)
+	if err != nil {
+		if writtenKey != "" {
+			os.Remove(writtenKey)
+		}
+		return nil, fmt.Errorf("配置验证失败: %s", extractOCIErrorMessage(err))
+	}
+	user.TenantName = tenantInfo.Name
+	if parsedTime, err := time.Parse("2006-01-02 15:04:05", tenantInfo.CreateTime); err == nil {
+		user.TenantCreateTime = &parsedTime
+	}
+
+	if err := db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+
+	state.ConfigID = user.ID
+	s.markStep(state, OnboardingStepOciConfig, OnboardingStatusDone, "")
+	return &user, s.saveState(state)
+}
+
+// GenerateSSHKey 生成 SSH 密钥并设为引导配置的默认密钥；已生成过时返回原密钥（不再返回私钥）
+func (s *OnboardingService) GenerateSSHKey(name string) (*OnboardingSSHKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	db := database.GetDB()
+	state := s.loadState()
+
+	if state.SSHKeyID != "" {
+		var key models.SSHKey
+		if err := db.Where("id = ?", state.SSHKeyID).First(&key).Error; err == nil {
+			key.PrivateKey = ""
+			return &OnboardingSSHKey{Key: &key}, nil
+		}
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	privateBlock, err := ssh.MarshalPrivateKey(privateKey, name)
+	if err != nil {
+		return nil, err
+	}
+	privatePEM := string(pem.EncodeToMemory(privateBlock))
+
+	key := models.SSHKey{
+		ID:         uuid.New().String(),
+		Name:       name,
+		PublicKey:  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey))) + " " + name,
+		PrivateKey: privatePEM,
+		KeyType:    "standalone",
+		CreateTime: time.Now(),
+	}
+	if err := db.Create(&key).Error; err != nil {
+		return nil, err
+	}
+
+	if state.ConfigID != "" {
+		db.Model(&models.OciUser{}).Where("id = ?", state.ConfigID).Update("default_ssh_key_id", key.ID)
+	}
+
+	state.SSHKeyID = key.ID
+	s.markStep(state, OnboardingStepSSHKey, OnboardingStatusDone, "")
+	if err := s.saveState(state); err != nil {
+		return nil, err
+	}
+
+	key.PrivateKey = ""
+	return &OnboardingSSHKey{Key: &key, PrivateKey: privatePEM}, nil
+}
+
+// SetupTelegram 保存 Telegram 配置并发送测试消息，skip 为 true 时跳过此步骤
+func (s *OnboardingService) SetupTelegram(botToken, chatID string, skip bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := s.loadState()
+	if skip {
+		s.markStep(state, OnboardingStepTelegram, OnboardingStatusSkipped, "")
+		return s.saveState(state)
+	}
+
+	if err := s.telegramService.UpdateConfig(botToken, chatID, true); err != nil {
+		return err
+	}
+	if err := s.telegramService.SendCriticalNotification("OCI Panel", "✅ Telegram 通知配置成功"); err != nil {
+		s.markStep(state, OnboardingStepTelegram, OnboardingStatusPending, err.Error())
+		s.saveState(state)
+		return fmt.Errorf("测试消息发送失败: %w", err)
+	}
+
+	s.markStep(state, OnboardingStepTelegram, OnboardingStatusDone, "")
+	return s.saveState(state)
+}
+
+// Complete 结束引导，未完成的可选步骤标记为跳过
+func (s *OnboardingService) Complete() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := s.loadState()
+	for _, name := range []string{OnboardingStepAdmin, OnboardingStepOciConfig} {
+		if state.Steps[name].Status != OnboardingStatusDone {
+			return fmt.Errorf("请先完成步骤: %s", name)
+		}
+	}
+	for _, name := range onboardingSteps {
+		if state.Steps[name].Status == OnboardingStatusPending {
+			s.markStep(state, name, OnboardingStatusSkipped, "")
+		}
+	}
+	state.Completed = true
+	return s.saveState(state)
+}
+
+func (s *OnboardingService) markStep(state *OnboardingState, name, status, message string) {
+	state.Steps[name] = &OnboardingStep{
+		Name:       name,
+		Status:     status,
+		Message:    message,
+		UpdateTime: time.Now().Format("2006-01-02 15:04:05"),
+	}
+}
+
+func (s *OnboardingService) loadState() *OnboardingState {
+	state := &OnboardingState{Steps: make(map[string]*OnboardingStep)}
+
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingOnboardingState).First(&setting).Error; err == nil {
+		json.Unmarshal([]byte(setting.Value), state)
+	}
+	if state.Steps == nil {
+		state.Steps = make(map[string]*OnboardingStep)
+	}
+	for _, name := range onboardingSteps {
+		if state.Steps[name] == nil {
+			state.Steps[name] = &OnboardingStep{Name: name, Status: OnboardingStatusPending}
+		}
+	}
+	return state
+}
+
+func (s *OnboardingService) saveState(state *OnboardingState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return saveSetting(SettingOnboardingState, string(data))
+}
+
+// saveSetting 写入或更新 sys_setting
+func saveSetting(key, value string) error {
+	db := database.GetDB()
+	var setting models.SysSetting
+	if err := db.Where("key = ?", key).First(&setting).Error; err != nil {
+		setting = models.SysSetting{
+			ID:    uuid.New().String(),
+			Key:   key,
+			Value: value,
+		}
+		return db.Create(&setting).Error
+	}
+	return db.Model(&setting).Update("value", value).Error
+}
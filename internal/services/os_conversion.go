@@ -0,0 +1,436 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	OSConversionUbuntu = "Canonical Ubuntu"
+
+	OSConversionRunning   = "running"
+	OSConversionFailed    = "failed"
+	OSConversionCompleted = "completed"
+	// 数据库中为 running 但当前进程未在执行，说明面板重启导致任务中断，可继续执行
+	OSConversionInterrupted = "interrupted"
+
+	osConversionTotalSteps = 6
+	// 等待实例状态变化、备份与替换引导卷的最长时间
+	osConversionWaitTimeout = 30 * time.Minute
+	// 替换引导卷时新引导卷的最小容量
+	minReplaceBootVolumeSizeGB = 50
+)
+
+// StartOSConversion 启动替换引导卷转换操作系统的任务，默认转换为最新的 Ubuntu，实例的 VNIC 与公网 IP 保持不变
+// 流程：检查并选择镜像 → 关机 → 备份原引导卷 → 替换引导卷 → 开机并验证 → 清理原引导卷
+func (s *InstanceService) StartOSConversion(userId, instanceId, targetVersion string, keepOldBootVolume bool) (*models.OSConversionJob, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	var count int64
+	database.GetDB().Model(&models.OSConversionJob{}).
+		Where("instance_id = ? AND status = ?", instanceId, OSConversionRunning).Count(&count)
+	if count > 0 {
+		return nil, fmt.Errorf("实例已有未完成的转换任务，请继续执行该任务")
+	}
+
+	job := &models.OSConversionJob{
+		ID:                uuid.New().String(),
+		ConfigID:          userId,
+		InstanceID:        instanceId,
+		TargetOS:          OSConversionUbuntu,
+		TargetVersion:     strings.TrimSpace(targetVersion),
+		KeepOldBootVolume: keepOldBootVolume,
+		TotalSteps:        osConversionTotalSteps,
+		Status:            OSConversionRunning,
+		Message:           "等待执行",
+	}
+	if err := database.GetDB().Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	s.conversionMu.Lock()
+	s.activeConversions[job.ID] = true
+	s.conversionMu.Unlock()
+	go s.runOSConversion(job, &user)
+	return job, nil
+}
+
+// ResumeOSConversion 从上次完成的步骤继续执行失败或中断的转换任务
+func (s *InstanceService) ResumeOSConversion(jobId string) (*models.OSConversionJob, error) {
+	var job models.OSConversionJob
+	if err := database.GetDB().Where("id = ?", jobId).First(&job).Error; err != nil {
+		return nil, fmt.Errorf("转换任务不存在")
+	}
+	if job.Status == OSConversionCompleted {
+		return nil, fmt.Errorf("转换任务已完成")
+	}
+
+	s.conversionMu.Lock()
+	if s.activeConversions[job.ID] {
+		s.conversionMu.Unlock()
+		return nil, fmt.Errorf("转换任务正在执行")
+	}
+	s.activeConversions[job.ID] = true
+	s.conversionMu.Unlock()
+
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", job.ConfigID).First(&user).Error; err != nil {
+		s.finishOSConversion(job.ID)
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	job.Status = OSConversionRunning
+	job.Error = ""
+	database.GetDB().Save(&job)
+	go s.runOSConversion(&job, &user)
+	return &job, nil
+}
+
+// GetOSConversionJob 获取转换任务进度
+func (s *InstanceService) GetOSConversionJob(jobId string) (*models.OSConversionJob, error) {
+	var job models.OSConversionJob
+	if err := database.GetDB().Where("id = ?", jobId).First(&job).Error; err != nil {
+		return nil, err
+	}
+	s.markInterrupted(&job)
+	return &job, nil
+}
+
+// ListOSConversionJobs 列出配置下的转换任务，最新的在前
+func (s *InstanceService) ListOSConversionJobs(userId string) ([]models.OSConversionJob, error) {
+	var jobs []models.OSConversionJob
+	if err := database.GetDB().Where("config_id = ?", userId).Order("create_time DESC").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	for i := range jobs {
+		s.markInterrupted(&jobs[i])
+	}
+	return jobs, nil
+}
+
+// markInterrupted 将已不在执行的 running 任务标记为中断，仅影响返回结果
+func (s *InstanceService) markInterrupted(job *models.OSConversionJob) {
+	if job.Status != OSConversionRunning {
+		return
+	}
+	s.conversionMu.Lock()
+	defer s.conversionMu.Unlock()
+	if !s.activeConversions[job.ID] {
+		job.Status = OSConversionInterrupted
+	}
+}
+
+func (s *InstanceService) finishOSConversion(jobId string) {
+	s.conversionMu.Lock()
+	delete(s.activeConversions, jobId)
+	s.conversionMu.Unlock()
+}
+
+// runOSConversion 从 job.Step 的下一步开始依次执行，每步完成后保存进度
+func (s *InstanceService) runOSConversion(job *models.OSConversionJob, user *models.OciUser) {
+	defer s.finishOSConversion(job.ID)
+	ctx := context.Background()
+	db := database.GetDB()
+
+	for job.Step < osConversionTotalSteps {
+		step := job.Step + 1
+		message, err := s.runOSConversionStep(ctx, user, job, step)
+		if err != nil {
+			job.Status = OSConversionFailed
+			job.Error = fmt.Sprintf("第 %d 步失败: %s", step, extractOCIErrorMessage(err))
+			db.Save(job)
+			log.Printf("[OSConversion] Job %s for instance %s failed: %s", job.ID, job.InstanceID, job.Error)
+			return
+		}
+		job.Step = step
+		job.Message = message
+		db.Save(job)
+	}
+
+	job.Status = OSConversionCompleted
+	db.Save(job)
+	log.Printf("[OSConversion] Job %s for instance %s completed", job.ID, job.InstanceID)
+}
+
+// runOSConversionStep 执行单个步骤，各步骤在中断后重复执行时不会重复创建资源
+func (s *InstanceService) runOSConversionStep(ctx context.Context, user *models.OciUser, job *models.OSConversionJob, step int) (string, error) {
+	computeClient, err := s.ociService.GetComputeClient(user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get compute client: %w", err)
+	}
+	blockClient, err := s.ociService.GetBlockstorageClient(user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get blockstorage client: %w", err)
+	}
+
+	updateMessage := func(message string) {
+		job.Message = message
+		database.GetDB().Model(job).Update("message", message)
+	}
+
+	switch step {
+	case 1:
+		updateMessage("正在检查实例并选择镜像...")
+		instance, err := s.ociService.GetInstance(ctx, user, job.InstanceID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get instance: %w", err)
+		}
+		if instance.LifecycleState == core.InstanceLifecycleStateTerminated || instance.LifecycleState == core.InstanceLifecycleStateTerminating {
+			return "", fmt.Errorf("实例已终止")
+		}
+		imageID, err := findConversionImage(ctx, computeClient, instance, job.TargetOS, job.TargetVersion)
+		if err != nil {
+			return "", err
+		}
+		if stringValue(instance.ImageId) == imageID {
+			return "", fmt.Errorf("实例已在使用目标镜像")
+		}
+		bootVolume, err := s.ociService.GetBootVolumeByInstanceId(user, job.InstanceID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get boot volume: %w", err)
+		}
+		size := int64(minReplaceBootVolumeSizeGB)
+		if bootVolume.SizeInGBs != nil && *bootVolume.SizeInGBs > size {
+			size = *bootVolume.SizeInGBs
+		}
+		job.InstanceName = stringValue(instance.DisplayName)
+		job.ImageID = imageID
+		job.OldImageID = stringValue(instance.ImageId)
+		job.OldBootVolumeID = stringValue(bootVolume.Id)
+		job.BootVolumeSizeGB = size
+		if vnic, err := s.ociService.getPrimaryVnic(ctx, user, job.InstanceID); err == nil {
+			job.OriginalPublicIP = stringValue(vnic.PublicIp)
+		}
+		return "已选择镜像 " + imageID, nil
+
+	case 2:
+		updateMessage("正在关机...")
+		instance, err := s.ociService.GetInstance(ctx, user, job.InstanceID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get instance: %w", err)
+		}
+		if instance.LifecycleState != core.InstanceLifecycleStateStopped {
+			if instance.LifecycleState != core.InstanceLifecycleStateStopping {
+				if _, err := computeClient.InstanceAction(ctx, core.InstanceActionRequest{
+					InstanceId: instance.Id,
+					Action:     core.InstanceActionActionStop,
+				}); err != nil {
+					return "", fmt.Errorf("failed to stop instance: %w", err)
+				}
+			}
+			if err := waitConversionInstanceState(ctx, computeClient, job.InstanceID, core.InstanceLifecycleStateStopped); err != nil {
+				return "", err
+			}
+		}
+		return "关机成功", nil
+
+	case 3:
+		updateMessage("正在备份原引导卷...")
+		if job.BackupID == "" {
+			backupName := fmt.Sprintf("%s-before-os-conversion", job.InstanceName)
+			backupResp, err := blockClient.CreateBootVolumeBackup(ctx, core.CreateBootVolumeBackupRequest{
+				CreateBootVolumeBackupDetails: core.CreateBootVolumeBackupDetails{
+					BootVolumeId: &job.OldBootVolumeID,
+					DisplayName:  &backupName,
+					Type:         core.CreateBootVolumeBackupDetailsTypeFull,
+				},
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to create boot volume backup: %w", err)
+			}
+			// 先保存备份 ID，中断后继续时等待同一个备份而不是重新创建
+			job.BackupID = stringValue(backupResp.Id)
+			database.GetDB().Model(job).Update("backup_id", job.BackupID)
+		}
+		deadline := time.Now().Add(osConversionWaitTimeout)
+		for {
+			resp, err := blockClient.GetBootVolumeBackup(ctx, core.GetBootVolumeBackupRequest{BootVolumeBackupId: &job.BackupID})
+			if err != nil {
+				return "", fmt.Errorf("failed to get backup status: %w", err)
+			}
+			if resp.LifecycleState == core.BootVolumeBackupLifecycleStateAvailable {
+				break
+			}
+			if resp.LifecycleState == core.BootVolumeBackupLifecycleStateFaulty || resp.LifecycleState == core.BootVolumeBackupLifecycleStateTerminated {
+				job.BackupID = ""
+				return "", fmt.Errorf("备份失败 (%s)", resp.LifecycleState)
+			}
+			if time.Now().After(deadline) {
+				return "", fmt.Errorf("等待备份完成超时")
+			}
+			time.Sleep(5 * time.Second)
+		}
+		return "备份原引导卷成功: " + job.BackupID, nil
+
+	case 4:
+		updateMessage("正在替换引导卷...")
+		instance, err := s.ociService.GetInstance(ctx, user, job.InstanceID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get instance: %w", err)
+		}
+		if stringValue(instance.ImageId) != job.ImageID {
+			preserve := true
+			_, err := computeClient.UpdateInstance(ctx, core.UpdateInstanceRequest{
+				InstanceId: &job.InstanceID,
+				UpdateInstanceDetails: core.UpdateInstanceDetails{
+					SourceDetails: core.UpdateInstanceSourceViaImageDetails{
+						ImageId:                     &job.ImageID,
+						BootVolumeSizeInGBs:         &job.BootVolumeSizeGB,
+						IsPreserveBootVolumeEnabled: &preserve,
+					},
+				},
+			})
+			// 中断前已提交的替换仍在进行时返回冲突，继续等待即可
+			if serviceErr, ok := common.IsServiceError(err); err != nil && !(ok && serviceErr.GetHTTPStatusCode() == 409) {
+				return "", fmt.Errorf("failed to replace boot volume: %w", err)
+			}
+		}
+		deadline := time.Now().Add(osConversionWaitTimeout)
+		for {
+			replaced, err := s.bootVolumeReplaced(ctx, computeClient, user, job)
+			if err != nil {
+				return "", err
+			}
+			if replaced {
+				break
+			}
+			if time.Now().After(deadline) {
+				return "", fmt.Errorf("等待替换引导卷超时")
+			}
+			time.Sleep(10 * time.Second)
+		}
+		return "替换引导卷成功", nil
+
+	case 5:
+		updateMessage("正在开机并验证...")
+		instance, err := s.ociService.GetInstance(ctx, user, job.InstanceID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get instance: %w", err)
+		}
+		if instance.LifecycleState != core.InstanceLifecycleStateRunning {
+			if instance.LifecycleState == core.InstanceLifecycleStateStopped {
+				if _, err := computeClient.InstanceAction(ctx, core.InstanceActionRequest{
+					InstanceId: instance.Id,
+					Action:     core.InstanceActionActionStart,
+				}); err != nil {
+					return "", fmt.Errorf("failed to start instance: %w", err)
+				}
+			}
+			if err := waitConversionInstanceState(ctx, computeClient, job.InstanceID, core.InstanceLifecycleStateRunning); err != nil {
+				return "", err
+			}
+		}
+		replaced, err := s.bootVolumeReplaced(ctx, computeClient, user, job)
+		if err != nil {
+			return "", err
+		}
+		if !replaced {
+			return "", fmt.Errorf("验证失败：实例未使用新镜像的引导卷")
+		}
+		vnic, err := s.ociService.getPrimaryVnic(ctx, user, job.InstanceID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get primary vnic: %w", err)
+		}
+		job.PublicIP = stringValue(vnic.PublicIp)
+		if job.OriginalPublicIP != "" && job.PublicIP != job.OriginalPublicIP {
+			return fmt.Sprintf("实例已启动，公网 IP 由 %s 变为 %s", job.OriginalPublicIP, job.PublicIP), nil
+		}
+		return "实例已启动，公网 IP 未变化: " + job.PublicIP, nil
+
+	case 6:
+		if job.KeepOldBootVolume || job.OldBootVolumeID == "" {
+			return "已保留原引导卷，备份 " + job.BackupID + " 可用于恢复", nil
+		}
+		updateMessage("正在删除原引导卷...")
+		_, err := blockClient.DeleteBootVolume(ctx, core.DeleteBootVolumeRequest{BootVolumeId: &job.OldBootVolumeID})
+		if serviceErr, ok := common.IsServiceError(err); err != nil && !(ok && serviceErr.GetHTTPStatusCode() == 404) {
+			return "", fmt.Errorf("failed to delete old boot volume: %w", err)
+		}
+		return "已删除原引导卷，备份 " + job.BackupID + " 可用于恢复", nil
+	}
+	return "", fmt.Errorf("未知步骤: %d", step)
+}
+
+// bootVolumeReplaced 判断实例是否已使用目标镜像且挂载了新的引导卷
+func (s *InstanceService) bootVolumeReplaced(ctx context.Context, client core.ComputeClient, user *models.OciUser, job *models.OSConversionJob) (bool, error) {
+	instance, err := s.ociService.GetInstance(ctx, user, job.InstanceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get instance: %w", err)
+	}
+	if stringValue(instance.ImageId) != job.ImageID {
+		return false, nil
+	}
+	resp, err := client.ListBootVolumeAttachments(ctx, core.ListBootVolumeAttachmentsRequest{
+		CompartmentId:      instance.CompartmentId,
+		AvailabilityDomain: instance.AvailabilityDomain,
+		InstanceId:         instance.Id,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list boot volume attachments: %w", err)
+	}
+	for _, attachment := range resp.Items {
+		if attachment.LifecycleState == core.BootVolumeAttachmentLifecycleStateAttached &&
+			stringValue(attachment.BootVolumeId) != job.OldBootVolumeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// waitConversionInstanceState 等待实例进入指定状态
+func waitConversionInstanceState(ctx context.Context, client core.ComputeClient, instanceID string, state core.InstanceLifecycleStateEnum) error {
+	deadline := time.Now().Add(osConversionWaitTimeout)
+	for {
+		resp, err := client.GetInstance(ctx, core.GetInstanceRequest{InstanceId: &instanceID})
+		if err != nil {
+			return fmt.Errorf("failed to get instance status: %w", err)
+		}
+		if resp.LifecycleState == state {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("等待实例状态变为 %s 超时", state)
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// findConversionImage 查找适配实例 Shape 的目标系统最新平台镜像，未指定版本时跳过 Minimal 镜像
+func findConversionImage(ctx context.Context, client core.ComputeClient, instance *core.Instance, operatingSystem, version string) (string, error) {
+	req := core.ListImagesRequest{
+		CompartmentId:   instance.CompartmentId,
+		OperatingSystem: &operatingSystem,
+		Shape:           instance.Shape,
+		SortBy:          core.ListImagesSortByTimecreated,
+		SortOrder:       core.ListImagesSortOrderDesc,
+	}
+	if version != "" {
+		req.OperatingSystemVersion = &version
+	}
+	resp, err := client.ListImages(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to list images: %w", err)
+	}
+	for _, image := range resp.Items {
+		if version == "" && strings.Contains(stringValue(image.OperatingSystemVersion), "Minimal") {
+			continue
+		}
+		return stringValue(image.Id), nil
+	}
+	if version != "" {
+		return "", fmt.Errorf("没有找到适配 %s 的 %s %s 镜像", stringValue(instance.Shape), operatingSystem, version)
+	}
+	return "", fmt.Errorf("没有找到适配 %s 的 %s 镜像", stringValue(instance.Shape), operatingSystem)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	passwordLoginTimeoutSeconds = 120
+	generatedPasswordLength     = 20
+
+	// 密码字符不包含引号、冒号与反斜杠，便于复制使用
+	passwordLower   = "abcdefghijkmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordDigits  = "23456789"
+	passwordSymbols = "@#%^*-_+="
+)
+
+var loginUsernamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// PasswordLogin 开启密码登录的结果，密码只在此时返回一次，面板不保存
+// 运行命令与 cloud-init 脚本中只包含 SHA-512 哈希，OCI 保存的命令内容与实例元数据中不会出现明文密码
+type PasswordLogin struct {
+	Username string              `json:"username"`
+	Password string              `json:"password"`
+	UserData string              `json:"userData,omitempty"` // 创建实例时使用的 cloud-init 脚本（base64）
+	Command  *AgentCommandResult `json:"command,omitempty"`
+}
+
+// GeneratePassword 生成包含大小写字母、数字与符号的随机密码
+func GeneratePassword(length int) (string, error) {
+	sets := []string{passwordLower, passwordUpper, passwordDigits, passwordSymbols}
+	all := strings.Join(sets, "")
+	password := make([]byte, length)
+	for i := range password {
+		// 前几位依次取自每类字符，保证每类至少出现一次
+		charset := all
+		if i < len(sets) {
+			charset = sets[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = charset[n.Int64()]
+	}
+	// 打乱顺序，避免固定位置的字符类别
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// normalizeLoginUsername 校验登录用户名，为空时使用 root
+func normalizeLoginUsername(username string) (string, error) {
+	if username == "" {
+		return "root", nil
+	}
+	if !loginUsernamePattern.MatchString(username) {
+		return "", fmt.Errorf("用户名格式不正确")
+	}
+	return username, nil
+}
+
+// passwordLoginCommands 以 crypt(3) 哈希设置用户密码并开启 SSH 密码登录的命令，用户不存在时创建
+// 同时修改 sshd_config.d 中的配置，Ubuntu 镜像在其中默认关闭了密码登录
+func passwordLoginCommands(username, passwordHash string) string {
+	var b strings.Builder
+	b.WriteString(`SUDO=""
+if [ "$(id -u)" != "0" ]; then SUDO="sudo -n"; fi
+`)
+	if username != "root" {
+		fmt.Fprintf(&b, "id %[1]s >/dev/null 2>&1 || $SUDO useradd -m -s /bin/bash %[1]s\n", username)
+	}
+	fmt.Fprintf(&b, "echo '%s:%s' | $SUDO chpasswd -e\n", username, passwordHash)
+
+	directives := []string{"PasswordAuthentication"}
+	if username == "root" {
+		directives = append(directives, "PermitRootLogin")
+	}
+	for _, directive := range directives {
+		fmt.Fprintf(&b, `for f in /etc/ssh/sshd_config /etc/ssh/sshd_config.d/*.conf; do
+  [ -f "$f" ] && $SUDO sed -i -E 's/^[#[:space:]]*%[1]s[[:space:]].*/%[1]s yes/' "$f"
+done
+grep -qE '^%[1]s yes' /etc/ssh/sshd_config || echo '%[1]s yes' | $SUDO tee -a /etc/ssh/sshd_config >/dev/null
+`, directive)
+	}
+	b.WriteString("$SUDO systemctl restart sshd 2>/dev/null || $SUDO systemctl restart ssh\n")
+	fmt.Fprintf(&b, "echo \"password login enabled for %s\"\n", username)
+	return b.String()
+}
+
+// passwordLoginUserData 生成创建实例时开启密码登录的 cloud-init 脚本
+// existing 为已有的 shell 脚本（base64 或明文）时在其后追加，cloud-config 格式无法合并
+func passwordLoginUserData(existing, username, passwordHash string) (string, error) {
+	script := "#!/bin/bash\n" + passwordLoginCommands(username, passwordHash)
+	normalized, err := NormalizeUserData(existing)
+	if err != nil {
+		return "", err
+	}
+	if normalized != "" {
+		decoded, _ := base64.StdEncoding.DecodeString(normalized)
+		if !strings.HasPrefix(string(decoded), "#!") {
+			return "", fmt.Errorf("已有的 cloud-init 脚本不是 shell 脚本，无法合并")
+		}
+		script = strings.TrimRight(string(decoded), "\n") + "\n\n" + passwordLoginCommands(username, passwordHash)
+	}
+	return NormalizeUserData(script)
+}
+
+// passwordLoginScript 生成运行命令使用的脚本，脚本中只包含密码哈希
+func passwordLoginScript(username, password string) (string, error) {
+	passwordHash, err := HashPasswordSHA512(password)
+	if err != nil {
+		return "", err
+	}
+	return "#!/bin/bash\nset -e\n" + passwordLoginCommands(username, passwordHash), nil
+}
+
+// EnablePasswordLogin 通过 Cloud Agent 的运行命令插件设置密码并开启 SSH 密码登录
+// 需要实例启用 Compute Instance Run Command 插件，并允许 ocarun 用户免密 sudo
+func (s *OCIService) EnablePasswordLogin(ctx context.Context, user *models.OciUser, instance *core.Instance, username, password string) (*AgentCommandResult, error) {
+	if instance.LifecycleState != core.InstanceLifecycleStateRunning {
+		return nil, fmt.Errorf("实例未运行，无法开启密码登录")
+	}
+	script, err := passwordLoginScript(username, password)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.RunAgentCommand(ctx, user, instance, "oci-panel-password-login", script, passwordLoginTimeoutSeconds)
+	if err != nil {
+		return result, err
+	}
+	if !result.Succeeded() {
+		return result, fmt.Errorf("开启密码登录失败 (%s, exit %d)", result.State, result.ExitCode)
+	}
+	return result, nil
+}
+
+// EnablePasswordLogin 为运行中的实例生成随机密码并开启 SSH 密码登录
+func (s *InstanceService) EnablePasswordLogin(userId, instanceId, username string) (*PasswordLogin, error) {
+	username, err := normalizeLoginUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	ctx := context.Background()
+	instance, err := s.ociService.GetInstance(ctx, &user, instanceId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+	password, err := GeneratePassword(generatedPasswordLength)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.ociService.EnablePasswordLogin(ctx, &user, instance, username, password)
+	if err != nil {
+		// 执行失败时只返回命令输出便于排查，密码未生效不返回
+		if result != nil {
+			return &PasswordLogin{Username: username, Command: result}, err
+		}
+		return nil, err
+	}
+	return &PasswordLogin{Username: username, Password: password, Command: result}, nil
+}
+
+// PasswordLoginUserData 生成随机密码及创建实例时开启密码登录的 cloud-init 脚本，可追加到已有的 shell 脚本后
+func PasswordLoginUserData(existing, username string) (*PasswordLogin, error) {
+	username, err := normalizeLoginUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	password, err := GeneratePassword(generatedPasswordLength)
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := HashPasswordSHA512(password)
+	if err != nil {
+		return nil, err
+	}
+	userData, err := passwordLoginUserData(existing, username, passwordHash)
+	if err != nil {
+		return nil, err
+	}
+	return &PasswordLogin{Username: username, Password: password, UserData: userData}, nil
+}
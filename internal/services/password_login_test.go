@@ -0,0 +1,131 @@
+package services
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		password, err := GeneratePassword(generatedPasswordLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(password) != generatedPasswordLength {
+			t.Fatalf("len = %d", len(password))
+		}
+		for _, charset := range []string{passwordLower, passwordUpper, passwordDigits, passwordSymbols} {
+			if !strings.ContainsAny(password, charset) {
+				t.Errorf("%q 缺少字符类别 %q", password, charset)
+			}
+		}
+		if strings.ContainsAny(password, `'":\`) {
+			t.Errorf("%q 包含无法写入脚本的字符", password)
+		}
+	}
+}
+
+func TestNormalizeLoginUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     string
+		wantErr  bool
+	}{
+		{"默认 root", "", "root", false},
+		{"普通用户", "ubuntu", "ubuntu", false},
+		{"包含空格", "a b", "", true},
+		{"命令注入", "root;reboot", "", true},
+		{"大写字母", "Admin", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeLoginUsername(tt.username)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("normalizeLoginUsername(%q) = %q, %v", tt.username, got, err)
+			}
+		})
+	}
+}
+
+// passwordHashInScript 取出脚本中 chpasswd -e 设置的用户与哈希
+func passwordHashInScript(t *testing.T, script string) (string, string) {
+	t.Helper()
+	for _, line := range strings.Split(script, "\n") {
+		if !strings.HasSuffix(line, "| $SUDO chpasswd -e") {
+			continue
+		}
+		entry := strings.TrimSuffix(strings.TrimPrefix(line, "echo '"), "' | $SUDO chpasswd -e")
+		username, hash, _ := strings.Cut(entry, ":")
+		return username, hash
+	}
+	t.Fatalf("脚本中没有 chpasswd -e:\n%s", script)
+	return "", ""
+}
+
+func TestPasswordLoginScript(t *testing.T) {
+	script, err := passwordLoginScript("root", "Secret-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(script, "Secret-123") {
+		t.Fatalf("脚本中不应包含明文密码:\n%s", script)
+	}
+	username, hash := passwordHashInScript(t, script)
+	if verified, _ := cryptSHA512("Secret-123", hash); username != "root" || verified != hash {
+		t.Errorf("哈希 %s 与密码不符", hash)
+	}
+	if !strings.Contains(script, "PermitRootLogin yes") || !strings.Contains(script, "PasswordAuthentication yes") {
+		t.Errorf("脚本未开启密码登录:\n%s", script)
+	}
+}
+
+func TestPasswordLoginUserData(t *testing.T) {
+	decode := func(t *testing.T, value string) string {
+		t.Helper()
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	t.Run("单独生成", func(t *testing.T) {
+		login, err := PasswordLoginUserData("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		script := decode(t, login.UserData)
+		if strings.Contains(script, login.Password) {
+			t.Fatalf("cloud-init 脚本中不应包含明文密码:\n%s", script)
+		}
+		username, hash := passwordHashInScript(t, script)
+		if verified, _ := cryptSHA512(login.Password, hash); username != "root" || verified != hash {
+			t.Errorf("哈希 %s 与返回的密码不符", hash)
+		}
+		if !strings.HasPrefix(script, "#!/bin/bash\n") || strings.Contains(script, "useradd") {
+			t.Errorf("root 脚本不正确:\n%s", script)
+		}
+	})
+
+	t.Run("追加到已有脚本", func(t *testing.T) {
+		userData, err := passwordLoginUserData("#!/bin/sh\napt-get update\n", "ubuntu", "$6$salt$hash")
+		if err != nil {
+			t.Fatal(err)
+		}
+		script := decode(t, userData)
+		if !strings.HasPrefix(script, "#!/bin/sh\napt-get update\n\n") || strings.Count(script, "#!") != 1 {
+			t.Errorf("应追加在原脚本之后:\n%s", script)
+		}
+		if !strings.Contains(script, "useradd -m -s /bin/bash ubuntu") || strings.Contains(script, "PermitRootLogin") {
+			t.Errorf("普通用户脚本不正确:\n%s", script)
+		}
+	})
+
+	t.Run("cloud-config 无法合并", func(t *testing.T) {
+		if _, err := passwordLoginUserData("#cloud-config\npackages: [htop]\n", "root", "$6$salt$hash"); err == nil {
+			t.Error("期望返回错误")
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// platformImageCacheTTL 平台镜像目录的缓存时间，Oracle 通常每月发布一次新镜像
+const platformImageCacheTTL = time.Hour
+
+// PlatformImageGroup 同一系统版本的平台镜像，Images 按发布时间从新到旧排列
+type PlatformImageGroup struct {
+	OperatingSystem        string      `json:"operatingSystem"`
+	OperatingSystemVersion string      `json:"operatingSystemVersion"`
+	LatestImageID          string      `json:"latestImageId"`
+	Images                 []ImageInfo `json:"images"`
+}
+
+type platformImageCacheEntry struct {
+	groups    []PlatformImageGroup
+	expiresAt time.Time
+}
+
+// platformImageCache 按租户、区域与 Shape 缓存平台镜像目录
+var (
+	platformImageCache   = make(map[string]platformImageCacheEntry)
+	platformImageCacheMu sync.Mutex
+)
+
+// ListPlatformImages 列出区域内支持指定 Shape 的平台镜像，按系统与版本分组，不含自定义镜像
+// shape 为空时使用开机任务对应架构的 Shape
+func (s *OCIService) ListPlatformImages(ctx context.Context, user *models.OciUser, region, shape, architecture string, clearCache bool) ([]PlatformImageGroup, error) {
+	if shape == "" {
+		shape = taskShape(architecture)
+	}
+	cacheKey := user.OciTenantID + "|" + region + "|" + shape
+	if !clearCache {
+		platformImageCacheMu.Lock()
+		entry, ok := platformImageCache[cacheKey]
+		platformImageCacheMu.Unlock()
+		if ok && time.Now().Before(entry.expiresAt) {
+			return entry.groups, nil
+		}
+	}
+
+	regionUser := *user
+	regionUser.OciRegion = region
+	client, err := s.GetComputeClient(&regionUser)
+	if err != nil {
+		return nil, fmt.Errorf("获取计算客户端失败: %w", err)
+	}
+
+	var images []core.Image
+	req := core.ListImagesRequest{
+		CompartmentId:  &user.OciTenantID,
+		Shape:          &shape,
+		LifecycleState: core.ImageLifecycleStateAvailable,
+		SortBy:         core.ListImagesSortByTimecreated,
+		SortOrder:      core.ListImagesSortOrderDesc,
+	}
+	for {
+		resp, err := client.ListImages(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("获取镜像列表失败: %s", extractOCIErrorMessage(err))
+		}
+		images = append(images, resp.Items...)
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+
+	groups := groupPlatformImages(images)
+	platformImageCacheMu.Lock()
+	platformImageCache[cacheKey] = platformImageCacheEntry{groups: groups, expiresAt: time.Now().Add(platformImageCacheTTL)}
+	platformImageCacheMu.Unlock()
+	return groups, nil
+}
+
+// groupPlatformImages 按系统与版本分组，自定义镜像（属于某个区间）被排除
+// 分组按系统名称排序，同一系统的新版本在前
+func groupPlatformImages(images []core.Image) []PlatformImageGroup {
+	index := make(map[string]int)
+	groups := []PlatformImageGroup{}
+	for _, img := range images {
+		if img.CompartmentId != nil || img.Id == nil {
+			continue
+		}
+		info := ImageInfo{
+			ID:                     *img.Id,
+			DisplayName:            stringValue(img.DisplayName),
+			OperatingSystem:        stringValue(img.OperatingSystem),
+			OperatingSystemVersion: stringValue(img.OperatingSystemVersion),
+		}
+		if img.SizeInMBs != nil {
+			info.SizeInMBs = *img.SizeInMBs
+		}
+		if img.TimeCreated != nil {
+			info.TimeCreated = img.TimeCreated.Format("2006-01-02 15:04:05")
+		}
+
+		key := info.OperatingSystem + "|" + info.OperatingSystemVersion
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, PlatformImageGroup{
+				OperatingSystem:        info.OperatingSystem,
+				OperatingSystemVersion: info.OperatingSystemVersion,
+			})
+		}
+		groups[i].Images = append(groups[i].Images, info)
+	}
+
+	for i := range groups {
+		sort.SliceStable(groups[i].Images, func(a, b int) bool {
+			return groups[i].Images[a].TimeCreated > groups[i].Images[b].TimeCreated
+		})
+		groups[i].LatestImageID = groups[i].Images[0].ID
+	}
+	sort.SliceStable(groups, func(a, b int) bool {
+		if groups[a].OperatingSystem != groups[b].OperatingSystem {
+			return groups[a].OperatingSystem < groups[b].OperatingSystem
+		}
+		return compareOSVersion(groups[a].OperatingSystemVersion, groups[b].OperatingSystemVersion) > 0
+	})
+	return groups
+}
+
+// compareOSVersion 按数字比较系统版本（如 22.04 与 9），无法解析的部分按字符串比较
+func compareOSVersion(a, b string) int {
+	partsA := strings.FieldsFunc(a, func(r rune) bool { return r == '.' || r == ' ' })
+	partsB := strings.FieldsFunc(b, func(r rune) bool { return r == '.' || r == ' ' })
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numA, errA := strconv.Atoi(partsA[i])
+		numB, errB := strconv.Atoi(partsB[i])
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				if numA > numB {
+					return 1
+				}
+				return -1
+			}
+		case partsA[i] != partsB[i]:
+			return strings.Compare(partsA[i], partsB[i])
+		}
+	}
+	return len(partsA) - len(partsB)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func TestCompareOSVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{"主版本", "24.04", "22.04", 1},
+		{"按数字而非字符串", "9", "10", -1},
+		{"相同", "8.10", "8.10", 0},
+		{"Minimal 变体", "22.04 Minimal", "22.04", 1},
+		{"次版本", "8.9", "8.10", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareOSVersion(tt.a, tt.b)
+			if (got > 0) != (tt.want > 0) || (got < 0) != (tt.want < 0) {
+				t.Errorf("compareOSVersion(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupPlatformImages(t *testing.T) {
+	image := func(id, os, version string, daysAgo int) core.Image {
+		return core.Image{
+			Id:                     common.String(id),
+			DisplayName:            common.String(id),
+			OperatingSystem:        common.String(os),
+			OperatingSystemVersion: common.String(version),
+			TimeCreated:            &common.SDKTime{Time: time.Now().AddDate(0, 0, -daysAgo)},
+		}
+	}
+	custom := image("custom", "Canonical Ubuntu", "22.04", 0)
+	custom.CompartmentId = common.String("ocid1.tenancy.oc1..a")
+
+	groups := groupPlatformImages([]core.Image{
+		image("ubuntu-22-old", "Canonical Ubuntu", "22.04", 60),
+		image("ol-8", "Oracle Linux", "8", 5),
+		image("ubuntu-24", "Canonical Ubuntu", "24.04", 10),
+		image("ubuntu-22-new", "Canonical Ubuntu", "22.04", 1),
+		custom,
+	})
+
+	want := []struct{ os, version, latest string }{
+		{"Canonical Ubuntu", "24.04", "ubuntu-24"},
+		{"Canonical Ubuntu", "22.04", "ubuntu-22-new"},
+		{"Oracle Linux", "8", "ol-8"},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("分组数 = %d, want %d: %+v", len(groups), len(want), groups)
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.OperatingSystem != w.os || g.OperatingSystemVersion != w.version || g.LatestImageID != w.latest {
+			t.Errorf("groups[%d] = %s %s %s, want %s %s %s", i, g.OperatingSystem, g.OperatingSystemVersion, g.LatestImageID, w.os, w.version, w.latest)
+		}
+	}
+	if len(groups[1].Images) != 2 {
+		t.Errorf("22.04 镜像数 = %d，自定义镜像应被排除", len(groups[1].Images))
+	}
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	PowerActionStart = "start"
+	PowerActionStop  = "stop"
+
+	PowerStatusSuccess = "success"
+	PowerStatusSkipped = "skipped"
+	PowerStatusFailed  = "failed"
+
+	// 服务暂停后补执行错过计划的最长时间
+	powerScheduleCatchUp = 10 * time.Minute
+	// 单个计划的执行超时
+	powerScheduleTimeout = 60 * time.Second
+)
+
+// PowerScheduleView 带下次执行时间的定时开关机计划
+type PowerScheduleView struct {
+	models.PowerSchedule
+	NextRunTime string `json:"nextRunTime"` // 已停用或表达式无效时为空
+}
+
+type PowerScheduleService struct {
+	ociService      *OCIService
+	telegramService *TelegramService
+	runner          *cronRunner[models.PowerSchedule]
+}
+
+func NewPowerScheduleService(ociService *OCIService, telegramService *TelegramService) *PowerScheduleService {
+	s := &PowerScheduleService{
+		ociService:      ociService,
+		telegramService: telegramService,
+	}
+	s.runner = newCronRunner("PowerSchedule", powerScheduleCatchUp,
+		func(schedule *models.PowerSchedule) (string, string) { return schedule.ID, schedule.Cron },
+		s.runDue)
+	return s
+}
+
+// Job 返回由作业框架调度的定时开关机作业，每分钟检查一次，只有存在到期计划时才会执行并记录
+func (s *PowerScheduleService) Job() Job {
+	return s.runner.job("power_schedule", "按计划定时开关机实例")
+}
+
+// runDue 执行到期的计划，失败时通过 Telegram 通知
+func (s *PowerScheduleService) runDue(ctx context.Context, due []models.PowerSchedule) (string, error) {
+	var succeeded, skipped, failed int
+	for i := range due {
+		schedule := &due[i]
+		status, message := s.execute(ctx, schedule)
+		now := time.Now()
+		updates := map[string]interface{}{
+			"last_run_time": now,
+			"last_status":   status,
+			"last_message":  message,
+		}
+		switch status {
+		case PowerStatusSuccess:
+			succeeded++
+		case PowerStatusSkipped:
+			skipped++
+		default:
+			failed++
+			s.notifyFailure(schedule, message)
+		}
+		database.GetDB().Model(&models.PowerSchedule{}).Where("id = ?", schedule.ID).Updates(updates)
+		log.Printf("[PowerSchedule] %s %s: %s %s", schedule.Action, schedule.InstanceName, status, message)
+	}
+
+	summary := fmt.Sprintf("执行 %d 个计划：成功 %d，跳过 %d，失败 %d", len(due), succeeded, skipped, failed)
+	if failed > 0 {
+		return "", fmt.Errorf("%s", summary)
+	}
+	return summary, nil
+}
+
+// execute 执行单个计划，实例已处于目标状态时跳过；实例已终止或不存在时停用计划
+func (s *PowerScheduleService) execute(ctx context.Context, schedule *models.PowerSchedule) (string, string) {
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", schedule.ConfigID).First(&user).Error; err != nil {
+		return PowerStatusFailed, "配置不存在"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, powerScheduleTimeout)
+	defer cancel()
+
+	instance, err := s.ociService.GetInstance(ctx, &user, schedule.InstanceID)
+	if err != nil {
+		if serviceErr, ok := common.IsServiceError(err); ok && serviceErr.GetHTTPStatusCode() == 404 {
+			db.Model(schedule).Update("enabled", false)
+			return PowerStatusFailed, "实例不存在，计划已停用"
+		}
+		return PowerStatusFailed, "获取实例失败: " + extractOCIErrorMessage(err)
+	}
+
+	state := instance.LifecycleState
+	var action string
+	switch schedule.Action {
+	case PowerActionStart:
+		switch state {
+		case core.InstanceLifecycleStateRunning, core.InstanceLifecycleStateStarting:
+			return PowerStatusSkipped, "实例已在运行"
+		case core.InstanceLifecycleStateStopped:
+			action = "START"
+		}
+	case PowerActionStop:
+		switch state {
+		case core.InstanceLifecycleStateStopped, core.InstanceLifecycleStateStopping:
+			return PowerStatusSkipped, "实例已关机"
+		case core.InstanceLifecycleStateRunning:
+			action = "STOP"
+		}
+	default:
+		return PowerStatusFailed, "不支持的操作: " + schedule.Action
+	}
+
+	if state == core.InstanceLifecycleStateTerminated || state == core.InstanceLifecycleStateTerminating {
+		db.Model(schedule).Update("enabled", false)
+		return PowerStatusFailed, "实例已终止，计划已停用"
+	}
+	if action == "" {
+		return PowerStatusFailed, fmt.Sprintf("实例当前状态为 %s，无法执行", state)
+	}
+
+	if err := s.ociService.InstanceAction(ctx, &user, schedule.InstanceID, action); err != nil {
+		return PowerStatusFailed, extractOCIErrorMessage(err)
+	}
+	return PowerStatusSuccess, ""
+}
+
+func (s *PowerScheduleService) notifyFailure(schedule *models.PowerSchedule, message string) {
+	if s.telegramService == nil {
+		return
+	}
+	tg := s.telegramService
+	var user models.OciUser
+	database.GetDB().Where("id = ?", schedule.ConfigID).First(&user)
+	text := tg.t("power_schedule_failed", user.Username, schedule.InstanceName,
+		tg.t("power_action_"+schedule.Action), schedule.Cron, message)
+	if err := tg.SendCriticalNotification(tg.t("power_schedule_failed_title"), text); err != nil {
+		log.Printf("[PowerSchedule] Failed to send notification: %v", err)
+	}
+}
+
+// validatePowerSchedule 校验操作与 cron 表达式，返回规范化后的表达式
+func validatePowerSchedule(action, cron string) (string, error) {
+	if action != PowerActionStart && action != PowerActionStop {
+		return "", fmt.Errorf("操作只能为 start 或 stop")
+	}
+	return normalizeCron(cron)
+}
+
+// CreatePowerSchedule 为实例创建定时开关机计划
+func (s *PowerScheduleService) CreatePowerSchedule(configID, instanceID, action, cron string) (*models.PowerSchedule, error) {
+	cron, err := validatePowerSchedule(action, cron)
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", configID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+	instance, err := s.ociService.GetInstance(context.Background(), &user, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+
+	schedule := models.PowerSchedule{
+		ID:           uuid.New().String(),
+		ConfigID:     configID,
+		InstanceID:   instanceID,
+		InstanceName: stringValue(instance.DisplayName),
+		Action:       action,
+		Cron:         cron,
+		Enabled:      true,
+	}
+	if err := db.Create(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ListPowerSchedules 获取定时开关机计划，参数为空时不按该条件过滤
+func ListPowerSchedules(configID, instanceID string) ([]PowerScheduleView, error) {
+	query := database.GetDB().Order("create_time DESC")
+	if configID != "" {
+		query = query.Where("config_id = ?", configID)
+	}
+	if instanceID != "" {
+		query = query.Where("instance_id = ?", instanceID)
+	}
+	var schedules []models.PowerSchedule
+	if err := query.Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := make([]PowerScheduleView, len(schedules))
+	for i, schedule := range schedules {
+		list[i].PowerSchedule = schedule
+		if schedule.Enabled {
+			list[i].NextRunTime = cronNextRunText(schedule.Cron, now)
+		}
+	}
+	return list, nil
+}
+
+// UpdatePowerSchedule 修改计划的操作、表达式与启用状态
+func UpdatePowerSchedule(id, action, cron string, enabled bool) error {
+	cron, err := validatePowerSchedule(action, cron)
+	if err != nil {
+		return err
+	}
+	result := database.GetDB().Model(&models.PowerSchedule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"action":  action,
+		"cron":    cron,
+		"enabled": enabled,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("计划不存在")
+	}
+	return nil
+}
+
+// DeletePowerSchedule 删除定时开关机计划
+func DeletePowerSchedule(id string) error {
+	return database.GetDB().Where("id = ?", id).Delete(&models.PowerSchedule{}).Error
+}
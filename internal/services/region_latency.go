@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+const (
+	regionLatencySamples = 3
+	regionLatencyTimeout = 10 * time.Second
+)
+
+// RegionLatency 面板主机到单个区域 API 端点的延迟
+type RegionLatency struct {
+	Region    string `json:"region"`
+	Endpoint  string `json:"endpoint"`
+	IsHome    bool   `json:"isHome"`
+	ConnectMs int64  `json:"connectMs"` // TCP 建连耗时，近似网络往返时间，失败时为 -1
+	APIMs     int64  `json:"apiMs"`     // 一次已签名 API 调用的耗时，包含服务端处理时间，失败时为 -1
+	Error     string `json:"error"`
+}
+
+// ProbeRegionLatency 并发测量面板主机到租户各已订阅区域端点的延迟，每项取多次采样的最小值
+// 结果按 TCP 延迟升序排列，不可达的区域排在最后
+func (s *OCIService) ProbeRegionLatency(ctx context.Context, user *models.OciUser) ([]*RegionLatency, error) {
+	regions, err := s.ListSubscribedRegions(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("获取已订阅区域失败: %w", err)
+	}
+
+	results := make([]*RegionLatency, len(regions))
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		wg.Add(1)
+		go func(i int, region string) {
+			defer wg.Done()
+			results[i] = s.probeRegionLatency(ctx, user, region)
+		}(i, region)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].ConnectMs, results[j].ConnectMs
+		if (a < 0) != (b < 0) {
+			return b < 0
+		}
+		return a < b
+	})
+	return results, nil
+}
+
+// probeRegionLatency 测量单个区域的 TCP 建连与 API 调用延迟
+func (s *OCIService) probeRegionLatency(ctx context.Context, user *models.OciUser, region string) *RegionLatency {
+	result := &RegionLatency{
+		Region:    region,
+		IsHome:    region == user.OciRegion,
+		ConnectMs: -1,
+		APIMs:     -1,
+	}
+
+	client, err := s.GetIdentityClient(user)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	client.SetRegion(region)
+	result.Endpoint = strings.TrimPrefix(client.Host, "https://")
+
+	var dialer net.Dialer
+	for i := 0; i < regionLatencySamples; i++ {
+		dialCtx, cancel := context.WithTimeout(ctx, regionLatencyTimeout)
+		start := time.Now()
+		conn, err := dialer.DialContext(dialCtx, "tcp", net.JoinHostPort(result.Endpoint, "443"))
+		elapsed := time.Since(start).Milliseconds()
+		cancel()
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		conn.Close()
+		if result.ConnectMs < 0 || elapsed < result.ConnectMs {
+			result.ConnectMs = elapsed
+		}
+	}
+
+	// 客户端复用连接，首次采样包含 TLS 握手，取最小值反映稳定状态下的调用耗时
+	for i := 0; i < regionLatencySamples; i++ {
+		callCtx, cancel := context.WithTimeout(ctx, regionLatencyTimeout)
+		start := time.Now()
+		_, err := client.ListAvailabilityDomains(callCtx, identity.ListAvailabilityDomainsRequest{CompartmentId: &user.OciTenantID})
+		elapsed := time.Since(start).Milliseconds()
+		cancel()
+		if err != nil {
+			result.Error = extractOCIErrorMessage(err)
+			return result
+		}
+		if result.APIMs < 0 || elapsed < result.APIMs {
+			result.APIMs = elapsed
+		}
+	}
+	return result
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+)
+
+const (
+	SettingReportExport        = "report_export"
+	SettingReportExportLastRun = "report_export_last_run"
+
+	// 报表导出间隔
+	ReportExportInterval = 7 * 24 * time.Hour
+	// 报表中按天统计流量的天数
+	reportTrafficDays = 30
+
+	ReportFormatJSON = "json"
+	ReportFormatHTML = "html"
+	ReportFormatBoth = "both"
+)
+
+// ReportExportSettings 定期导出报表的设置，使用 ConfigID 对应配置的凭据写入其租户下的存储桶
+type ReportExportSettings struct {
+	Enabled    bool   `json:"enabled"`
+	ConfigID   string `json:"configId"`
+	BucketName string `json:"bucketName"`
+	Prefix     string `json:"prefix"` // 对象名前缀，如 oci-panel/reports/
+	Format     string `json:"format"` // json / html / both
+}
+
+// ReportTrafficDay 所有配置单日的流量合计
+type ReportTrafficDay struct {
+	Day           string `json:"day"`
+	InboundBytes  int64  `json:"inboundBytes"`
+	OutboundBytes int64  `json:"outboundBytes"`
+}
+
+// PanelReport 面板概览与流量报表
+type PanelReport struct {
+	GeneratedAt   time.Time          `json:"generatedAt"`
+	TotalConfigs  int                `json:"totalConfigs"`
+	TotalTasks    int64              `json:"totalTasks"`
+	TaskResults   map[string]int64   `json:"taskResults"` // 最近 7 天任务日志按状态计数
+	Configs       []ConfigSummary    `json:"configs"`
+	FailedConfigs []string           `json:"failedConfigs"`
+	TrafficDays   []ReportTrafficDay `json:"trafficDays"`
+}
+
+type ReportExportService struct {
+	ociService *OCIService
+	runMutex   sync.Mutex
+}
+
+func NewReportExportService(ociService *OCIService) *ReportExportService {
+	return &ReportExportService{ociService: ociService}
+}
+
+// Job 返回由作业框架调度的报表导出作业，启用后每周导出一次
+func (s *ReportExportService) Job() Job {
+	return &FuncJob{
+		JobName:        "report_export",
+		JobDescription: "每周将概览与流量报表导出到对象存储",
+		CheckInterval:  time.Hour,
+		Due:            s.isDue,
+		RunFunc: func(ctx context.Context) (string, error) {
+			objects, err := s.Export(ctx)
+			if err != nil {
+				return "", err
+			}
+			return "已上传 " + strings.Join(objects, ", "), nil
+		},
+	}
+}
+
+func (s *ReportExportService) isDue() bool {
+	settings := GetReportExportSettings()
+	if !settings.Enabled {
+		return false
+	}
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingReportExportLastRun).First(&setting).Error; err != nil {
+		return true
+	}
+	lastRun, err := time.ParseInLocation("2006-01-02 15:04:05", setting.Value, time.Local)
+	if err != nil {
+		return true
+	}
+	return time.Since(lastRun) >= ReportExportInterval
+}
+
+// GetReportExportSettings 获取报表导出设置，未设置时返回默认值
+func GetReportExportSettings() *ReportExportSettings {
+	settings := &ReportExportSettings{Prefix: "oci-panel/reports/", Format: ReportFormatBoth}
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingReportExport).First(&setting).Error; err == nil {
+		_ = json.Unmarshal([]byte(setting.Value), settings)
+	}
+	return settings
+}
+
+// SaveReportExportSettings 保存报表导出设置
+func SaveReportExportSettings(settings *ReportExportSettings) error {
+	settings.ConfigID = strings.TrimSpace(settings.ConfigID)
+	settings.BucketName = strings.TrimSpace(settings.BucketName)
+	settings.Prefix = strings.TrimLeft(strings.TrimSpace(settings.Prefix), "/")
+	if settings.Format == "" {
+		settings.Format = ReportFormatBoth
+	}
+	if settings.Format != ReportFormatJSON && settings.Format != ReportFormatHTML && settings.Format != ReportFormatBoth {
+		return fmt.Errorf("不支持的格式: %s", settings.Format)
+	}
+	if settings.Enabled {
+		if settings.ConfigID == "" || settings.BucketName == "" {
+			return fmt.Errorf("启用导出需要选择配置并填写存储桶")
+		}
+		var count int64
+		database.GetDB().Model(&models.OciUser{}).Where("id = ?", settings.ConfigID).Count(&count)
+		if count == 0 {
+			return fmt.Errorf("配置不存在")
+		}
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return saveSetting(SettingReportExport, string(data))
+}
+
+// BuildReport 汇总所有配置的资源概览、本月流量、任务结果与最近 30 天的每日流量
+func (s *ReportExportService) BuildReport(ctx context.Context) (*PanelReport, error) {
+	db := database.GetDB()
+	var users []models.OciUser
+	if err := db.Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	report := &PanelReport{
+		GeneratedAt:   time.Now(),
+		TotalConfigs:  len(users),
+		TaskResults:   map[string]int64{},
+		Configs:       []ConfigSummary{},
+		FailedConfigs: []string{},
+		TrafficDays:   []ReportTrafficDay{},
+	}
+	db.Model(&models.OciCreateTask{}).Count(&report.TotalTasks)
+
+	var taskResults []struct {
+		Status string
+		Count  int64
+	}
+	db.Model(&models.TaskLog{}).Select("status, COUNT(*) AS count").
+		Where("execute_time >= ?", report.GeneratedAt.AddDate(0, 0, -7)).
+		Group("status").Scan(&taskResults)
+	for _, row := range taskResults {
+		report.TaskResults[row.Status] = row.Count
+	}
+
+	// 各配置并发汇总，单个配置失败时记录到 FailedConfigs
+	summaries := make([]*ConfigSummary, len(users))
+	failures := make([]string, len(users))
+	var wg sync.WaitGroup
+	for i := range users {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			summaryCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+			defer cancel()
+			summary, err := s.ociService.GetConfigSummary(summaryCtx, &users[i])
+			if err != nil {
+				failures[i] = users[i].Username + ": " + extractOCIErrorMessage(err)
+				return
+			}
+			summaries[i] = summary
+		}(i)
+	}
+	wg.Wait()
+	for i := range users {
+		if summaries[i] != nil {
+			report.Configs = append(report.Configs, *summaries[i])
+		} else {
+			report.FailedConfigs = append(report.FailedConfigs, failures[i])
+		}
+	}
+
+	// 每日流量读取 GetConfigSummary 刷新后的缓存
+	since := report.GeneratedAt.AddDate(0, 0, -reportTrafficDays).Format(trafficDayLayout)
+	db.Model(&models.TrafficDailyStat{}).
+		Select("day, SUM(inbound_bytes) AS inbound_bytes, SUM(outbound_bytes) AS outbound_bytes").
+		Where("day >= ?", since).Group("day").Order("day").Scan(&report.TrafficDays)
+
+	return report, nil
+}
+
+// Export 生成报表并按设置的格式上传到对象存储，返回上传的对象名
+func (s *ReportExportService) Export(ctx context.Context) ([]string, error) {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	settings := GetReportExportSettings()
+	if settings.ConfigID == "" || settings.BucketName == "" {
+		return nil, fmt.Errorf("未设置报表导出的配置与存储桶")
+	}
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", settings.ConfigID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	report, err := s.BuildReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type reportFile struct {
+		ext         string
+		contentType string
+		data        []byte
+	}
+	var files []reportFile
+	if settings.Format == ReportFormatJSON || settings.Format == ReportFormatBoth {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, reportFile{"json", "application/json", data})
+	}
+	if settings.Format == ReportFormatHTML || settings.Format == ReportFormatBoth {
+		data, err := renderReportHTML(report)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, reportFile{"html", "text/html; charset=utf-8", data})
+	}
+
+	client, err := s.ociService.GetObjectStorageClient(&user)
+	if err != nil {
+		return nil, err
+	}
+	nsResp, err := client.GetNamespace(ctx, objectstorage.GetNamespaceRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("获取对象存储命名空间失败: %s", extractOCIErrorMessage(err))
+	}
+
+	name := settings.Prefix + "report-" + report.GeneratedAt.Format("2006-01-02-150405")
+	var objects []string
+	for _, file := range files {
+		objectName := name + "." + file.ext
+		_, err := client.PutObject(ctx, objectstorage.PutObjectRequest{
+			NamespaceName: nsResp.Value,
+			BucketName:    common.String(settings.BucketName),
+			ObjectName:    common.String(objectName),
+			ContentLength: common.Int64(int64(len(file.data))),
+			ContentType:   common.String(file.contentType),
+			PutObjectBody: io.NopCloser(bytes.NewReader(file.data)),
+		})
+		if err != nil {
+			return objects, fmt.Errorf("上传 %s 失败: %s", objectName, extractOCIErrorMessage(err))
+		}
+		objects = append(objects, objectName)
+	}
+
+	saveSetting(SettingReportExportLastRun, report.GeneratedAt.Format("2006-01-02 15:04:05"))
+	log.Printf("[ReportExport] Uploaded %d objects to bucket %s", len(objects), settings.BucketName)
+	return objects, nil
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"bytes": FormatBytes,
+	"time":  func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>OCI Panel 报表 {{time .GeneratedAt}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",sans-serif;margin:24px;color:#222}
+table{border-collapse:collapse;margin-bottom:24px}
+th,td{border:1px solid #ddd;padding:6px 10px;text-align:left}
+th{background:#f5f5f5}
+.warn{color:#b45309}
+</style>
+</head>
+<body>
+<h1>OCI Panel 报表</h1>
+<p>生成时间：{{time .GeneratedAt}}　配置数：{{.TotalConfigs}}　开机任务数：{{.TotalTasks}}</p>
+{{if .TaskResults}}<h2>最近 7 天任务结果</h2>
+<table><tr><th>状态</th><th>次数</th></tr>
+{{range $status, $count := .TaskResults}}<tr><td>{{$status}}</td><td>{{$count}}</td></tr>
+{{end}}</table>{{end}}
+<h2>配置概览</h2>
+<table>
+<tr><th>配置</th><th>区域</th><th>实例</th><th>OCPU</th><th>内存 (GB)</th><th>A1 OCPU</th><th>存储 (GB)</th><th>本月入站</th><th>本月出站</th><th>提示</th></tr>
+{{range .Configs}}<tr><td>{{.Username}}</td><td>{{.Region}}</td><td>{{.InstanceCount}}</td><td>{{.TotalOcpus}}</td><td>{{.TotalMemoryGBs}}</td><td>{{.A1Ocpus.Used}} / {{.A1Ocpus.Limit}}</td><td>{{.StorageGBs.Used}} / {{.StorageGBs.Limit}}</td><td>{{bytes .InboundTraffic}}</td><td>{{bytes .OutboundTraffic}}</td><td class="warn">{{range .Warnings}}{{.}}<br>{{end}}</td></tr>
+{{end}}</table>
+{{if .FailedConfigs}}<h2>获取失败的配置</h2>
+<ul>{{range .FailedConfigs}}<li class="warn">{{.}}</li>{{end}}</ul>{{end}}
+<h2>每日流量</h2>
+<table><tr><th>日期</th><th>入站</th><th>出站</th></tr>
+{{range .TrafficDays}}<tr><td>{{.Day}}</td><td>{{bytes .InboundBytes}}</td><td>{{bytes .OutboundBytes}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// renderReportHTML 将报表渲染为可离线查看的 HTML 页面
+func renderReportHTML(report *PanelReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, report); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
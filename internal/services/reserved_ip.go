@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// ReservedPublicIPNew 开机成功后新建预留公网IP
+const ReservedPublicIPNew = "new"
+
+// ResolveReservedPublicIP 校验任务配置的预留公网IP，IP 地址会被转换为 OCID
+// value 为空表示使用临时公网IP，为 new 表示开机成功后新建预留IP
+func (s *OCIService) ResolveReservedPublicIP(ctx context.Context, user *models.OciUser, region, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == ReservedPublicIPNew {
+		return value, nil
+	}
+
+	regionUser := *user
+	regionUser.OciRegion = region
+	vnClient, err := s.GetVirtualNetworkClient(&regionUser)
+	if err != nil {
+		return "", err
+	}
+
+	var publicIP core.PublicIp
+	if net.ParseIP(value) != nil {
+		resp, err := vnClient.GetPublicIpByIpAddress(ctx, core.GetPublicIpByIpAddressRequest{
+			GetPublicIpByIpAddressDetails: core.GetPublicIpByIpAddressDetails{IpAddress: &value},
+		})
+		if err != nil {
+			return "", fmt.Errorf("预留公网IP不存在: %s", extractOCIErrorMessage(err))
+		}
+		publicIP = resp.PublicIp
+	} else {
+		resp, err := vnClient.GetPublicIp(ctx, core.GetPublicIpRequest{PublicIpId: &value})
+		if err != nil {
+			return "", fmt.Errorf("预留公网IP不存在: %s", extractOCIErrorMessage(err))
+		}
+		publicIP = resp.PublicIp
+	}
+
+	if publicIP.Lifetime != core.PublicIpLifetimeReserved {
+		return "", fmt.Errorf("公网IP %s 不是预留IP", value)
+	}
+	return *publicIP.Id, nil
+}
+
+// AssignReservedPublicIP 将实例主 VNIC 的临时公网IP替换为预留公网IP，返回绑定后的 IP 地址
+// 实例需处于运行状态；绑定失败时尽量恢复临时公网IP
+func (s *OCIService) AssignReservedPublicIP(ctx context.Context, user *models.OciUser, instanceId, reserved string) (string, error) {
+	vnClient, err := s.GetVirtualNetworkClient(user)
+	if err != nil {
+		return "", err
+	}
+
+	var reservedIP *core.PublicIp
+	if reserved != ReservedPublicIPNew {
+		resp, err := vnClient.GetPublicIp(ctx, core.GetPublicIpRequest{PublicIpId: &reserved})
+		if err != nil {
+			return "", fmt.Errorf("获取预留公网IP失败: %w", err)
+		}
+		if resp.PrivateIpId != nil && *resp.PrivateIpId != "" {
+			return "", fmt.Errorf("预留公网IP %s 已绑定到其它实例", *resp.IpAddress)
+		}
+		reservedIP = &resp.PublicIp
+	}
+
+	vnic, err := s.getPrimaryVnic(ctx, user, instanceId)
+	if err != nil {
+		return "", err
+	}
+	privateIpId, err := s.GetPrivateIpIdForVnic(ctx, user, *vnic.Id)
+	if err != nil {
+		return "", err
+	}
+
+	// 同一私有IP只能绑定一个公网IP，先释放临时公网IP
+	if err := deleteVnicPublicIP(ctx, vnClient, vnic); err != nil {
+		return "", err
+	}
+
+	var ipAddress *string
+	if reservedIP != nil {
+		var resp core.UpdatePublicIpResponse
+		resp, err = vnClient.UpdatePublicIp(ctx, core.UpdatePublicIpRequest{
+			PublicIpId:            reservedIP.Id,
+			UpdatePublicIpDetails: core.UpdatePublicIpDetails{PrivateIpId: &privateIpId},
+		})
+		ipAddress = resp.IpAddress
+	} else {
+		displayName := "reserved-" + instanceId[strings.LastIndex(instanceId, ".")+1:]
+		var resp core.CreatePublicIpResponse
+		resp, err = vnClient.CreatePublicIp(ctx, core.CreatePublicIpRequest{
+			CreatePublicIpDetails: core.CreatePublicIpDetails{
+				CompartmentId: &user.OciTenantID,
+				Lifetime:      core.CreatePublicIpDetailsLifetimeReserved,
+				DisplayName:   &displayName,
+				PrivateIpId:   &privateIpId,
+			},
+		})
+		ipAddress = resp.IpAddress
+	}
+	if err != nil {
+		return "", restoreEphemeralPublicIP(ctx, vnClient, user, privateIpId, err)
+	}
+	if ipAddress == nil {
+		return "", nil
+	}
+	return *ipAddress, nil
+}
+
+// restoreEphemeralPublicIP 绑定预留IP失败时重新分配临时公网IP，避免实例失去公网访问
+func restoreEphemeralPublicIP(ctx context.Context, vnClient core.VirtualNetworkClient, user *models.OciUser, privateIpId string, err error) error {
+	_, restoreErr := vnClient.CreatePublicIp(ctx, core.CreatePublicIpRequest{
+		CreatePublicIpDetails: core.CreatePublicIpDetails{
+			CompartmentId: &user.OciTenantID,
+			Lifetime:      core.CreatePublicIpDetailsLifetimeEphemeral,
+			PrivateIpId:   &privateIpId,
+		},
+	})
+	if restoreErr != nil {
+		return fmt.Errorf("绑定预留公网IP失败: %w（恢复临时公网IP失败: %v）", err, restoreErr)
+	}
+	return fmt.Errorf("绑定预留公网IP失败，已恢复临时公网IP: %w", err)
+}
+
+// getPrimaryVnic 获取实例的主 VNIC
+func (s *OCIService) getPrimaryVnic(ctx context.Context, user *models.OciUser, instanceId string) (*core.Vnic, error) {
+	instance, err := s.GetInstance(ctx, user, instanceId)
+	if err != nil {
+		return nil, err
+	}
+	computeClient, err := s.GetComputeClient(user)
+	if err != nil {
+		return nil, err
+	}
+	vnClient, err := s.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+		CompartmentId: instance.CompartmentId,
+		InstanceId:    instance.Id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取VNIC附件失败: %w", err)
+	}
+	for _, attachment := range resp.Items {
+		if attachment.VnicId == nil || attachment.LifecycleState != core.VnicAttachmentLifecycleStateAttached {
+			continue
+		}
+		vnicResp, err := vnClient.GetVnic(ctx, core.GetVnicRequest{VnicId: attachment.VnicId})
+		if err != nil {
+			return nil, fmt.Errorf("获取VNIC失败: %w", err)
+		}
+		if vnicResp.IsPrimary != nil && *vnicResp.IsPrimary {
+			return &vnicResp.Vnic, nil
+		}
+	}
+	return nil, fmt.Errorf("未找到实例的主VNIC")
+}
+
+// deleteVnicPublicIP 删除 VNIC 当前绑定的公网IP，未绑定时直接返回
+func deleteVnicPublicIP(ctx context.Context, vnClient core.VirtualNetworkClient, vnic *core.Vnic) error {
+	if vnic.PublicIp == nil || *vnic.PublicIp == "" {
+		return nil
+	}
+
+	// 通过IP地址获取Public IP的OCID
+	getPublicIpResp, err := vnClient.GetPublicIpByIpAddress(ctx, core.GetPublicIpByIpAddressRequest{
+		GetPublicIpByIpAddressDetails: core.GetPublicIpByIpAddressDetails{
+			IpAddress: vnic.PublicIp,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get public IP by address: %w", err)
+	}
+
+	if _, err := vnClient.DeletePublicIp(ctx, core.DeletePublicIpRequest{PublicIpId: getPublicIpResp.Id}); err != nil {
+		return fmt.Errorf("failed to delete public IP: %w", err)
+	}
+	return nil
+}
+
+// ResolveReservedPublicIP 校验创建任务时填写的预留公网IP
+func (s *TaskService) ResolveReservedPublicIP(user *models.OciUser, region, value string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return s.ociService.ResolveReservedPublicIP(ctx, user, region, value)
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// 可管理标签的资源类型
+const (
+	TagResourceInstance   = "instance"
+	TagResourceBootVolume = "bootVolume"
+	TagResourceVolume     = "volume"
+)
+
+// ResourceTags 资源的自由格式标签与定义标签
+// 更新时为 nil 的一项保持不变，传入空对象则清空该项
+type ResourceTags struct {
+	FreeformTags map[string]string                 `json:"freeformTags"`
+	DefinedTags  map[string]map[string]interface{} `json:"definedTags"`
+}
+
+// IsTagResource 检查资源类型是否支持标签管理
+func IsTagResource(resourceType string) bool {
+	switch resourceType {
+	case TagResourceInstance, TagResourceBootVolume, TagResourceVolume:
+		return true
+	}
+	return false
+}
+
+func newResourceTags(freeform map[string]string, defined map[string]map[string]interface{}) *ResourceTags {
+	tags := &ResourceTags{FreeformTags: freeform, DefinedTags: defined}
+	if tags.FreeformTags == nil {
+		tags.FreeformTags = map[string]string{}
+	}
+	if tags.DefinedTags == nil {
+		tags.DefinedTags = map[string]map[string]interface{}{}
+	}
+	return tags
+}
+
+// GetResourceTags 获取实例、引导卷或块存储卷的标签
+func (s *OCIService) GetResourceTags(ctx context.Context, user *models.OciUser, resourceType, resourceID string) (*ResourceTags, error) {
+	switch resourceType {
+	case TagResourceInstance:
+		instance, err := s.GetInstance(ctx, user, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		return newResourceTags(instance.FreeformTags, instance.DefinedTags), nil
+	case TagResourceBootVolume:
+		client, err := s.GetBlockstorageClient(user)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.GetBootVolume(ctx, core.GetBootVolumeRequest{BootVolumeId: &resourceID})
+		if err != nil {
+			return nil, err
+		}
+		return newResourceTags(resp.FreeformTags, resp.DefinedTags), nil
+	case TagResourceVolume:
+		client, err := s.GetBlockstorageClient(user)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.GetVolume(ctx, core.GetVolumeRequest{VolumeId: &resourceID})
+		if err != nil {
+			return nil, err
+		}
+		return newResourceTags(resp.FreeformTags, resp.DefinedTags), nil
+	}
+	return nil, fmt.Errorf("不支持的资源类型: %s", resourceType)
+}
+
+// UpdateResourceTags 更新资源的标签，返回更新后的全部标签
+func (s *OCIService) UpdateResourceTags(ctx context.Context, user *models.OciUser, resourceType, resourceID string, tags ResourceTags) (*ResourceTags, *core.Instance, error) {
+	switch resourceType {
+	case TagResourceInstance:
+		client, err := s.GetComputeClient(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, err := client.UpdateInstance(ctx, core.UpdateInstanceRequest{
+			InstanceId: &resourceID,
+			UpdateInstanceDetails: core.UpdateInstanceDetails{
+				FreeformTags: tags.FreeformTags,
+				DefinedTags:  tags.DefinedTags,
+			},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return newResourceTags(resp.FreeformTags, resp.DefinedTags), &resp.Instance, nil
+	case TagResourceBootVolume:
+		client, err := s.GetBlockstorageClient(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, err := client.UpdateBootVolume(ctx, core.UpdateBootVolumeRequest{
+			BootVolumeId: &resourceID,
+			UpdateBootVolumeDetails: core.UpdateBootVolumeDetails{
+				FreeformTags: tags.FreeformTags,
+				DefinedTags:  tags.DefinedTags,
+			},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return newResourceTags(resp.FreeformTags, resp.DefinedTags), nil, nil
+	case TagResourceVolume:
+		client, err := s.GetBlockstorageClient(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, err := client.UpdateVolume(ctx, core.UpdateVolumeRequest{
+			VolumeId: &resourceID,
+			UpdateVolumeDetails: core.UpdateVolumeDetails{
+				FreeformTags: tags.FreeformTags,
+				DefinedTags:  tags.DefinedTags,
+			},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return newResourceTags(resp.FreeformTags, resp.DefinedTags), nil, nil
+	}
+	return nil, nil, fmt.Errorf("不支持的资源类型: %s", resourceType)
+}
+
+// GetResourceTags 获取配置下资源的标签，region 为空时使用配置的主区域
+func (s *InstanceService) GetResourceTags(userId, region, resourceType, resourceID string) (*ResourceTags, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if region != "" {
+		user.OciRegion = region
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return s.ociService.GetResourceTags(ctx, &user, resourceType, resourceID)
+}
+
+// UpdateResourceTags 更新配置下资源的标签，实例标签变化后同步终止保护并使实例列表缓存失效
+func (s *InstanceService) UpdateResourceTags(userId, region, resourceType, resourceID string, tags ResourceTags) (*ResourceTags, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if region != "" {
+		user.OciRegion = region
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	updated, instance, err := s.ociService.UpdateResourceTags(ctx, &user, resourceType, resourceID, tags)
+	if err != nil {
+		return nil, err
+	}
+	if instance != nil {
+		if err := SyncInstanceProtection(user.ID, []core.Instance{*instance}); err != nil {
+			return nil, err
+		}
+		InvalidateInstanceSnapshots(user.ID)
+	}
+	return updated, nil
+}
+
+// instanceMatchesTag 检查实例是否带有指定标签，tag 为 key 或 key=value，值不区分大小写
+// 定义标签的 key 可写为 namespace.key，未指定命名空间时匹配任意命名空间
+func instanceMatchesTag(inst core.Instance, tag string) bool {
+	key, value, hasValue := strings.Cut(tag, "=")
+	matches := func(v string) bool {
+		return !hasValue || strings.EqualFold(v, value)
+	}
+
+	if v, exists := inst.FreeformTags[key]; exists && matches(v) {
+		return true
+	}
+	if namespace, name, ok := strings.Cut(key, "."); ok {
+		if v, exists := inst.DefinedTags[namespace][name]; exists && matches(fmt.Sprint(v)) {
+			return true
+		}
+	}
+	for _, tags := range inst.DefinedTags {
+		if v, exists := tags[key]; exists && matches(fmt.Sprint(v)) {
+			return true
+		}
+	}
+	return false
+}
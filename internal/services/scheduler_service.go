@@ -3,8 +3,10 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"math/rand"
+	"strconv"
 	"sync"
 	"time"
 
@@ -15,10 +17,22 @@ import (
 )
 
 const (
-	SettingCacheEnabled  = "cache_enabled"
-	SettingCacheInterval = "cache_interval"
+	SettingCacheEnabled      = "cache_enabled"
+	SettingCacheInterval     = "cache_interval"
+	SettingCacheStaleMinutes = "cache_stale_minutes"
+
+	// 缓存超过该时间未更新视为过期
+	DefaultCacheStaleMinutes = 120
 )
 
+// StaleConfig 缓存已过期的配置
+type StaleConfig struct {
+	ConfigID    string `json:"configId"`
+	Username    string `json:"username"`
+	LastUpdated string `json:"lastUpdated"` // 从未同步时为空
+	AgeMinutes  int    `json:"ageMinutes"`  // 从未同步时为 -1
+}
+
 type SchedulerService struct {
 	ociService *OCIService
 	stopChan   chan struct{}
@@ -155,7 +169,12 @@ func (s *SchedulerService) UpdateConfigCache(configID string) error {
 	}
 
 	instances, err := s.ociService.ListInstances(ctx, &user, compartmentId)
+	RecordConfigHealth(configID, err)
 	if err == nil {
+		if err := SyncInstanceProtection(configID, instances); err != nil {
+			log.Printf("Failed to sync instance protection for %s: %v", configID, err)
+		}
+
 		cache.InstanceCount = len(instances)
 		cache.RunningInstances = 0
 		for _, inst := range instances {
@@ -288,6 +307,87 @@ func (s *SchedulerService) SetCacheInterval(minutes int) error {
 	return db.Save(&setting).Error
 }
 
+// GetCacheStaleMinutes 获取缓存过期阈值（分钟）
+func (s *SchedulerService) GetCacheStaleMinutes() int {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingCacheStaleMinutes).First(&setting).Error; err != nil {
+		return DefaultCacheStaleMinutes
+	}
+	minutes, err := strconv.Atoi(setting.Value)
+	if err != nil || minutes <= 0 {
+		return DefaultCacheStaleMinutes
+	}
+	return minutes
+}
+
+// SetCacheStaleMinutes 设置缓存过期阈值（分钟）
+func (s *SchedulerService) SetCacheStaleMinutes(minutes int) error {
+	if minutes <= 0 {
+		return fmt.Errorf("过期阈值必须大于 0")
+	}
+	return saveSetting(SettingCacheStaleMinutes, strconv.Itoa(minutes))
+}
+
+// IsCacheStale 判断缓存是否超过过期阈值未更新，cache 为空表示从未同步
+func IsCacheStale(cache *models.OciConfigCache, staleMinutes int) bool {
+	return cache == nil || time.Since(cache.UpdateTime) > time.Duration(staleMinutes)*time.Minute
+}
+
+// GetStaleConfigs 获取缓存已过期或从未同步的配置
+func (s *SchedulerService) GetStaleConfigs() ([]StaleConfig, error) {
+	db := database.GetDB()
+	var users []models.OciUser
+	if err := db.Select("id", "username").Order("create_time DESC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	var caches []models.OciConfigCache
+	if err := db.Select("config_id", "update_time").Find(&caches).Error; err != nil {
+		return nil, err
+	}
+	cacheByConfig := make(map[string]*models.OciConfigCache, len(caches))
+	for i := range caches {
+		cacheByConfig[caches[i].ConfigID] = &caches[i]
+	}
+
+	staleMinutes := s.GetCacheStaleMinutes()
+	list := []StaleConfig{}
+	for _, user := range users {
+		cache := cacheByConfig[user.ID]
+		if !IsCacheStale(cache, staleMinutes) {
+			continue
+		}
+		item := StaleConfig{ConfigID: user.ID, Username: user.Username, AgeMinutes: -1}
+		if cache != nil {
+			item.LastUpdated = cache.UpdateTime.Format("2006-01-02 15:04:05")
+			item.AgeMinutes = int(time.Since(cache.UpdateTime).Minutes())
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// RefreshStaleCaches 在后台刷新所有已过期配置的缓存，返回需要刷新的配置数量
+func (s *SchedulerService) RefreshStaleCaches() (int, error) {
+	stale, err := s.GetStaleConfigs()
+	if err != nil {
+		return 0, err
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, len(stale))
+	for i, item := range stale {
+		ids[i] = item.ConfigID
+	}
+	var configs []models.OciUser
+	if err := database.GetDB().Where("id IN ?", ids).Find(&configs).Error; err != nil {
+		return 0, err
+	}
+	go s.updateAllCaches(configs)
+	return len(configs), nil
+}
+
 func (s *SchedulerService) GetConfigCache(configID string) (*models.OciConfigCache, error) {
 	db := database.GetDB()
 	var cache models.OciConfigCache
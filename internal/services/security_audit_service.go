@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	SettingSecurityAuditLastRun = "security_audit_last_run"
+
+	// 安全列表巡检间隔
+	SecurityAuditInterval = 7 * 24 * time.Hour
+
+	SecuritySeverityHigh   = "high"
+	SecuritySeverityMedium = "medium"
+	SecuritySeverityLow    = "low"
+
+	// 端口范围超过该数量时视为过宽
+	securityWidePortRange = 1000
+	// 通知中最多列出的新发现条数
+	securityNotifyLimit = 10
+)
+
+// securitySensitivePorts 不应对公网开放的端口
+var securitySensitivePorts = []struct {
+	Port int
+	Name string
+}{
+	{445, "SMB"},
+	{1433, "SQL Server"},
+	{1521, "Oracle DB"},
+	{2375, "Docker API"},
+	{3306, "MySQL"},
+	{3389, "RDP"},
+	{5432, "PostgreSQL"},
+	{5900, "VNC"},
+	{6379, "Redis"},
+	{9200, "Elasticsearch"},
+	{11211, "Memcached"},
+	{27017, "MongoDB"},
+}
+
+// SecurityAuditReport 一次安全列表巡检的结果
+type SecurityAuditReport struct {
+	Configs       int                      `json:"configs"`
+	Findings      []models.SecurityFinding `json:"findings"`
+	NewFindings   int                      `json:"newFindings"`
+	Resolved      int64                    `json:"resolved"`
+	FailedConfigs []string                 `json:"failedConfigs"`
+	DurationMs    int64                    `json:"durationMs"`
+	ExecuteTime   string                   `json:"executeTime"`
+}
+
+type SecurityAuditService struct {
+	ociService      *OCIService
+	telegramService *TelegramService
+	runMutex        sync.Mutex
+}
+
+func NewSecurityAuditService(ociService *OCIService, telegramService *TelegramService) *SecurityAuditService {
+	return &SecurityAuditService{
+		ociService:      ociService,
+		telegramService: telegramService,
+	}
+}
+
+// Job 返回由作业框架调度的安全列表巡检作业，到期后巡检并通知新发现
+func (s *SecurityAuditService) Job() Job {
+	return &FuncJob{
+		JobName:        "security_audit",
+		JobDescription: "每周巡检安全列表中对公网开放的规则",
+		CheckInterval:  time.Hour,
+		Due:            s.isDue,
+		RunFunc: func(ctx context.Context) (string, error) {
+			report, err := s.Run(true)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("巡检 %d 个配置，%d 条风险规则（新增 %d），%d 个配置失败",
+				report.Configs, len(report.Findings), report.NewFindings, len(report.FailedConfigs)), nil
+		},
+	}
+}
+
+// isDue 距上次巡检是否已超过巡检间隔
+func (s *SecurityAuditService) isDue() bool {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingSecurityAuditLastRun).First(&setting).Error; err != nil {
+		return true
+	}
+	lastRun, err := time.ParseInLocation("2006-01-02 15:04:05", setting.Value, time.Local)
+	if err != nil {
+		return true
+	}
+	return time.Since(lastRun) >= SecurityAuditInterval
+}
+
+// Run 巡检所有配置主区域的安全列表，更新发现记录；notify 为 true 时通过 Telegram 通知新发现
+// 已不存在的风险规则视为已修复并删除，巡检失败的配置保留原有记录
+func (s *SecurityAuditService) Run(notify bool) (*SecurityAuditReport, error) {
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+
+	start := time.Now()
+	db := database.GetDB()
+	var users []models.OciUser
+	if err := db.Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	report := &SecurityAuditReport{
+		Configs:       len(users),
+		Findings:      []models.SecurityFinding{},
+		FailedConfigs: []string{},
+	}
+	var newFindings []models.SecurityFinding
+	for i := range users {
+		user := &users[i]
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		findings, err := s.scanConfig(ctx, user)
+		cancel()
+		if err != nil {
+			log.Printf("[SecurityAudit] Failed to scan %s: %v", user.Username, err)
+			report.FailedConfigs = append(report.FailedConfigs, user.Username)
+			continue
+		}
+
+		var existing []models.SecurityFinding
+		db.Where("config_id = ?", user.ID).Find(&existing)
+		known := make(map[string]models.SecurityFinding, len(existing))
+		for _, finding := range existing {
+			known[finding.Fingerprint] = finding
+		}
+
+		fingerprints := make([]string, 0, len(findings))
+		for _, finding := range findings {
+			fingerprints = append(fingerprints, finding.Fingerprint)
+			if previous, ok := known[finding.Fingerprint]; ok {
+				finding.ID = previous.ID
+				finding.FirstSeen = previous.FirstSeen
+				db.Save(&finding)
+			} else {
+				db.Create(&finding)
+				newFindings = append(newFindings, finding)
+			}
+			report.Findings = append(report.Findings, finding)
+		}
+
+		resolved := db.Where("config_id = ?", user.ID)
+		if len(fingerprints) > 0 {
+			resolved = resolved.Where("fingerprint NOT IN ?", fingerprints)
+		}
+		report.Resolved += resolved.Delete(&models.SecurityFinding{}).RowsAffected
+	}
+	db.Where("config_id NOT IN (?)", db.Model(&models.OciUser{}).Select("id")).Delete(&models.SecurityFinding{})
+
+	report.NewFindings = len(newFindings)
+	report.DurationMs = time.Since(start).Milliseconds()
+	report.ExecuteTime = start.Format("2006-01-02 15:04:05")
+	saveSetting(SettingSecurityAuditLastRun, report.ExecuteTime)
+	log.Printf("[SecurityAudit] Scanned %d configs: %d findings, %d new, %d resolved",
+		report.Configs, len(report.Findings), report.NewFindings, report.Resolved)
+
+	if notify && len(newFindings) > 0 {
+		s.notifyNewFindings(newFindings)
+	}
+	return report, nil
+}
+
+// scanConfig 检查配置主区域内所有安全列表的入站规则
+func (s *SecurityAuditService) scanConfig(ctx context.Context, user *models.OciUser) ([]models.SecurityFinding, error) {
+	vnClient, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := vnClient.ListSecurityLists(ctx, core.ListSecurityListsRequest{CompartmentId: &user.OciTenantID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var findings []models.SecurityFinding
+	for _, secList := range resp.Items {
+		for _, rule := range secList.IngressSecurityRules {
+			finding, ok := auditIngressRule(rule)
+			if !ok {
+				continue
+			}
+			finding.ID = uuid.New().String()
+			finding.ConfigID = user.ID
+			finding.Username = user.Username
+			finding.Region = user.OciRegion
+			finding.VcnID = stringValue(secList.VcnId)
+			finding.SecurityListID = stringValue(secList.Id)
+			finding.SecurityListName = stringValue(secList.DisplayName)
+			finding.Fingerprint = securityRuleFingerprint(user.ID, finding.SecurityListID, rule)
+			finding.FirstSeen = now
+			finding.LastSeen = now
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// auditIngressRule 判断入站规则是否存在风险，仅检查来源为整个公网的规则
+func auditIngressRule(rule core.IngressSecurityRule) (models.SecurityFinding, bool) {
+	source := stringValue(rule.Source)
+	if source != "0.0.0.0/0" && source != "::/0" {
+		return models.SecurityFinding{}, false
+	}
+
+	finding := models.SecurityFinding{Source: source}
+	var portRange *core.PortRange
+	switch protocol := stringValue(rule.Protocol); protocol {
+	case "all":
+		finding.Protocol = "ALL"
+		finding.PortRange = "ALL"
+		finding.Severity = SecuritySeverityHigh
+		finding.Category = "all_open"
+		finding.Description = "所有协议与端口对公网开放"
+		finding.Suggestion = "删除该规则，仅放行业务需要的端口（如 80、443），管理端口限制来源IP"
+		return finding, true
+	case "6":
+		finding.Protocol = "TCP"
+		if rule.TcpOptions != nil {
+			portRange = rule.TcpOptions.DestinationPortRange
+		}
+	case "17":
+		finding.Protocol = "UDP"
+		if rule.UdpOptions != nil {
+			portRange = rule.UdpOptions.DestinationPortRange
+		}
+	default:
+		// ICMP 等协议不涉及端口，不视为风险
+		return models.SecurityFinding{}, false
+	}
+
+	if portRange == nil || portRange.Min == nil || portRange.Max == nil {
+		finding.PortRange = "ALL"
+		finding.Severity = SecuritySeverityHigh
+		finding.Category = "all_ports"
+		finding.Description = fmt.Sprintf("%s 全部端口对公网开放", finding.Protocol)
+		finding.Suggestion = "为规则指定目标端口，仅放行业务需要的端口"
+		return finding, true
+	}
+
+	minPort, maxPort := *portRange.Min, *portRange.Max
+	finding.PortRange = fmt.Sprintf("%d-%d", minPort, maxPort)
+	if minPort == maxPort {
+		finding.PortRange = fmt.Sprintf("%d", minPort)
+	}
+
+	var exposed []string
+	for _, port := range securitySensitivePorts {
+		if port.Port >= minPort && port.Port <= maxPort {
+			exposed = append(exposed, fmt.Sprintf("%s(%d)", port.Name, port.Port))
+		}
+	}
+	switch {
+	case len(exposed) > 0:
+		finding.Severity = SecuritySeverityHigh
+		finding.Category = "sensitive_port"
+		finding.Description = fmt.Sprintf("公网可访问 %s", strings.Join(exposed, "、"))
+		finding.Suggestion = "将来源限制为自己的IP，或删除该规则后通过 SSH 隧道/VPN 访问"
+	case maxPort-minPort+1 > securityWidePortRange:
+		finding.Severity = SecuritySeverityMedium
+		finding.Category = "wide_range"
+		finding.Description = fmt.Sprintf("%s 端口范围 %s 过宽", finding.Protocol, finding.PortRange)
+		finding.Suggestion = "缩小端口范围，仅放行业务需要的端口"
+	case finding.Protocol == "TCP" && minPort <= 22 && maxPort >= 22:
+		finding.Severity = SecuritySeverityLow
+		finding.Category = "ssh"
+		finding.Description = "SSH(22) 对公网开放"
+		finding.Suggestion = "限制来源IP，或确认已禁用密码登录"
+	default:
+		return models.SecurityFinding{}, false
+	}
+	return finding, true
+}
+
+// securityRuleFingerprint 计算入站规则的摘要，规则内容或所属安全列表变化时摘要随之变化
+func securityRuleFingerprint(configID, securityListID string, rule core.IngressSecurityRule) string {
+	data, _ := json.Marshal(rule)
+	sum := sha1.Sum([]byte(configID + "|" + securityListID + "|" + string(data)))
+	return hex.EncodeToString(sum[:])
+}
+
+// stringValue 返回字符串指针的值，nil 时返回空字符串
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// notifyNewFindings 通过 Telegram 发送新发现的风险规则
+func (s *SecurityAuditService) notifyNewFindings(findings []models.SecurityFinding) {
+	lines := make([]string, 0, securityNotifyLimit+1)
+	for i, finding := range findings {
+		if i == securityNotifyLimit {
+			lines = append(lines, s.telegramService.t("security_audit_more", len(findings)-securityNotifyLimit))
+			break
+		}
+		lines = append(lines, s.telegramService.t("security_audit_item",
+			finding.Username, finding.SecurityListName, finding.Description, finding.Source, finding.PortRange))
+	}
+	message := s.telegramService.t("security_audit_notify", len(findings)) + "\n\n" + strings.Join(lines, "\n")
+	if err := s.telegramService.SendCriticalNotification(s.telegramService.t("security_audit_notify_title"), message); err != nil {
+		log.Printf("[SecurityAudit] Failed to send notification: %v", err)
+	}
+}
+
+// ListSecurityFindings 获取已记录的风险规则，按严重程度与配置排序
+func ListSecurityFindings(configID string) ([]models.SecurityFinding, error) {
+	query := database.GetDB().
+		Order("CASE severity WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END, username, security_list_name")
+	if configID != "" {
+		query = query.Where("config_id = ?", configID)
+	}
+	var findings []models.SecurityFinding
+	err := query.Find(&findings).Error
+	return findings, err
+}
+
+// Remediate 修复一条风险规则：source 为空时删除规则，否则将规则来源改为指定的 CIDR
+func (s *SecurityAuditService) Remediate(findingID, source string) error {
+	source = strings.TrimSpace(source)
+	if source != "" {
+		if _, _, err := net.ParseCIDR(source); err != nil {
+			return fmt.Errorf("来源 CIDR 无效: %s", source)
+		}
+		if source == "0.0.0.0/0" || source == "::/0" {
+			return fmt.Errorf("来源不能为整个公网")
+		}
+	}
+
+	db := database.GetDB()
+	var finding models.SecurityFinding
+	if err := db.Where("id = ?", findingID).First(&finding).Error; err != nil {
+		return fmt.Errorf("记录不存在")
+	}
+	var user models.OciUser
+	if err := db.Where("id = ?", finding.ConfigID).First(&user).Error; err != nil {
+		return fmt.Errorf("配置不存在")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	vnClient, err := s.ociService.GetVirtualNetworkClient(&user)
+	if err != nil {
+		return err
+	}
+	resp, err := vnClient.GetSecurityList(ctx, core.GetSecurityListRequest{SecurityListId: &finding.SecurityListID})
+	if err != nil {
+		return fmt.Errorf("获取安全列表失败: %s", extractOCIErrorMessage(err))
+	}
+
+	rules := make([]core.IngressSecurityRule, 0, len(resp.IngressSecurityRules))
+	matched := false
+	for _, rule := range resp.IngressSecurityRules {
+		if matched || securityRuleFingerprint(user.ID, finding.SecurityListID, rule) != finding.Fingerprint {
+			rules = append(rules, rule)
+			continue
+		}
+		matched = true
+		if source != "" {
+			rule.Source = &source
+			rule.SourceType = core.IngressSecurityRuleSourceTypeCidrBlock
+			rules = append(rules, rule)
+		}
+	}
+	if !matched {
+		db.Delete(&finding)
+		return fmt.Errorf("规则已变更或已删除，请重新巡检")
+	}
+
+	if _, err := vnClient.UpdateSecurityList(ctx, core.UpdateSecurityListRequest{
+		SecurityListId:            &finding.SecurityListID,
+		UpdateSecurityListDetails: core.UpdateSecurityListDetails{IngressSecurityRules: rules},
+	}); err != nil {
+		return fmt.Errorf("更新安全列表失败: %s", extractOCIErrorMessage(err))
+	}
+	return db.Delete(&finding).Error
+}
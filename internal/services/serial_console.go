@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// 会话创建后等待浏览器连接的时间，超时未连接的会话会删除对应的控制台连接
+	serialConsoleAttachTimeout = 5 * time.Minute
+	// 连接 OCI 控制台服务的超时
+	serialConsoleDialTimeout = 20 * time.Second
+	// 创建控制台连接并等待其激活的超时
+	serialConsoleCreateTimeout = 90 * time.Second
+)
+
+var (
+	consoleProxyPattern = regexp.MustCompile(`(\S+)@(instance-console\.[^\s']+)`)
+	consolePortPattern  = regexp.MustCompile(`-p\s+(\d+)`)
+)
+
+// SerialConsoleSession 串口控制台会话，令牌仅能用于一次 WebSocket 连接
+type SerialConsoleSession struct {
+	Token        string    `json:"token"`
+	ConnectionID string    `json:"connectionId"`
+	InstanceID   string    `json:"instanceId"`
+	ExpireTime   time.Time `json:"expireTime"` // 须在此时间前连接 WebSocket
+
+	user             models.OciUser
+	connectionString string
+	signer           ssh.Signer
+	attached         bool
+}
+
+type SerialConsoleService struct {
+	ociService *OCIService
+	mu         sync.Mutex
+	sessions   map[string]*SerialConsoleSession
+}
+
+func NewSerialConsoleService(ociService *OCIService) *SerialConsoleService {
+	return &SerialConsoleService{
+		ociService: ociService,
+		sessions:   make(map[string]*SerialConsoleSession),
+	}
+}
+
+// CreateSession 使用临时生成的密钥对创建实例控制台连接，返回连接 WebSocket 所需的一次性令牌
+func (s *SerialConsoleService) CreateSession(userId, instanceId string) (*SerialConsoleSession, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	s.cleanupSessions()
+
+	// 控制台连接仅支持 RSA 公钥
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signer: %w", err)
+	}
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+
+	ctx, cancel := context.WithTimeout(context.Background(), serialConsoleCreateTimeout)
+	defer cancel()
+	connectionId, err := s.ociService.CreateConsoleConnection(ctx, &user, instanceId, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	connectionString, err := s.ociService.GetConsoleConnectionString(ctx, &user, connectionId)
+	if err != nil {
+		s.deleteConnection(&user, connectionId)
+		return nil, err
+	}
+
+	session := &SerialConsoleSession{
+		Token:            uuid.New().String(),
+		ConnectionID:     connectionId,
+		InstanceID:       instanceId,
+		ExpireTime:       time.Now().Add(serialConsoleAttachTimeout),
+		user:             user,
+		connectionString: connectionString,
+		signer:           signer,
+	}
+	s.mu.Lock()
+	s.sessions[session.Token] = session
+	s.mu.Unlock()
+	return session, nil
+}
+
+// CloseSession 结束会话并删除控制台连接
+func (s *SerialConsoleService) CloseSession(token string) error {
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	delete(s.sessions, token)
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("会话不存在或已结束")
+	}
+	s.deleteConnection(&session.user, session.ConnectionID)
+	return nil
+}
+
+// cleanupSessions 删除超时未连接的会话及其控制台连接
+func (s *SerialConsoleService) cleanupSessions() {
+	var expired []*SerialConsoleSession
+	s.mu.Lock()
+	for token, session := range s.sessions {
+		if !session.attached && time.Now().After(session.ExpireTime) {
+			expired = append(expired, session)
+			delete(s.sessions, token)
+		}
+	}
+	s.mu.Unlock()
+	for _, session := range expired {
+		s.deleteConnection(&session.user, session.ConnectionID)
+	}
+}
+
+func (s *SerialConsoleService) deleteConnection(user *models.OciUser, connectionId string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.ociService.DeleteConsoleConnection(ctx, user, connectionId); err != nil {
+		log.Printf("[SerialConsole] Failed to delete console connection %s: %v", connectionId, err)
+	}
+}
+
+// parseConsoleConnectionString 从 OCI 返回的 SSH 连接字符串中解析代理用户、代理地址与目标实例
+// 格式：ssh -o ProxyCommand='ssh -W %h:%p -p 443 <连接OCID>@instance-console.<区域>.oci.oraclecloud.com' <实例OCID>
+func parseConsoleConnectionString(connectionString string) (string, string, string, error) {
+	match := consoleProxyPattern.FindStringSubmatch(connectionString)
+	if match == nil {
+		return "", "", "", fmt.Errorf("无法解析控制台连接字符串")
+	}
+	port := "22"
+	if portMatch := consolePortPattern.FindStringSubmatch(connectionString); portMatch != nil {
+		port = portMatch[1]
+	}
+	fields := strings.Fields(connectionString)
+	target := strings.Trim(fields[len(fields)-1], "'\"")
+	return match[1], net.JoinHostPort(match[2], port), target, nil
+}
+
+// Attach 将 WebSocket 桥接到实例串口控制台，阻塞直到任一端断开，结束后删除控制台连接
+func (s *SerialConsoleService) Attach(token string, conn *websocket.Conn) error {
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	if !ok || session.attached || time.Now().After(session.ExpireTime) {
+		s.mu.Unlock()
+		return fmt.Errorf("会话不存在或已过期")
+	}
+	session.attached = true
+	s.mu.Unlock()
+	defer s.CloseSession(token)
+
+	proxyUser, proxyAddr, target, err := parseConsoleConnectionString(session.connectionString)
+	if err != nil {
+		return err
+	}
+
+	// OCI 控制台服务与实例串口的主机密钥未公开，身份由控制台连接 OCID 与临时密钥对保证
+	proxyConfig := &ssh.ClientConfig{
+		User:            proxyUser,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(session.signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         serialConsoleDialTimeout,
+	}
+	proxy, err := ssh.Dial("tcp", proxyAddr, proxyConfig)
+	if err != nil {
+		return fmt.Errorf("连接控制台服务失败: %w", err)
+	}
+	defer proxy.Close()
+
+	tunnel, err := proxy.Dial("tcp", net.JoinHostPort(target, "22"))
+	if err != nil {
+		return fmt.Errorf("连接实例串口失败: %w", err)
+	}
+	clientConn, chans, reqs, err := ssh.NewClientConn(tunnel, target, &ssh.ClientConfig{
+		User:            target,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(session.signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         serialConsoleDialTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("连接实例串口失败: %w", err)
+	}
+	client := ssh.NewClient(clientConn, chans, reqs)
+	defer client.Close()
+
+	return bridgeTerminal(conn, client, nil)
+}
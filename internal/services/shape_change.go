@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// ShapeChangeParams 同架构调整 Shape 参数
+type ShapeChangeParams struct {
+	InstanceID  string
+	TargetShape string // 为空表示只调整 OCPU/内存
+	Ocpus       float32
+	MemoryInGBs float32
+	AutoRestart bool // 调整完成后启动原本运行中的实例
+}
+
+// ShapeChangeResult 调整 Shape 的结果
+type ShapeChangeResult struct {
+	PreviousShape string `json:"previousShape"`
+	Shape         string `json:"shape"`
+	State         string `json:"state"`
+}
+
+// ShapeChangeJob 调整 Shape 任务
+type ShapeChangeJob struct {
+	ID          string               `json:"id"`
+	InstanceID  string               `json:"instanceId"`
+	TargetShape string               `json:"targetShape"`
+	Status      string               `json:"status"` // 见 AsyncJob 的状态
+	Steps       []AutoRescueProgress `json:"steps"`
+	Result      *ShapeChangeResult   `json:"result,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	CreateTime  string               `json:"createTime"`
+}
+
+// shapeUpdateDetails 生成 UpdateInstance 的 Shape 参数，固定规格的 Shape 不支持设置 OCPU/内存
+func shapeUpdateDetails(currentShape, targetShape string, ocpus, memoryInGBs float32) core.UpdateInstanceDetails {
+	var details core.UpdateInstanceDetails
+	if targetShape != currentShape {
+		details.Shape = &targetShape
+	}
+	if IsFlexShape(targetShape) {
+		details.ShapeConfig = &core.UpdateInstanceShapeConfigDetails{
+			Ocpus:       &ocpus,
+			MemoryInGBs: &memoryInGBs,
+		}
+	}
+	return details
+}
+
+// StartShapeChange 启动调整 Shape 任务，返回任务ID，调用前应先通过预检查
+// 与同步的 UpdateInstanceConfig 使用同一更新流程，任务中报告每一步的进度
+func (s *InstanceService) StartShapeChange(userId string, params ShapeChangeParams) (string, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return "", fmt.Errorf("user not found: %w", err)
+	}
+
+	job, err := s.asyncJobs.Run(AsyncJobShapeChange, userId, params.InstanceID, params,
+		func(ctx context.Context, progress chan<- AutoRescueProgress) (interface{}, error) {
+			return s.ociService.UpdateInstanceShape(ctx, &user, params.InstanceID, params.TargetShape, params.Ocpus, params.MemoryInGBs, params.AutoRestart, progress)
+		})
+	if err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// GetShapeChangeJob 获取调整 Shape 任务状态
+func (s *InstanceService) GetShapeChangeJob(jobId string) (*ShapeChangeJob, bool) {
+	job, ok := s.asyncJobs.getKind(jobId, AsyncJobShapeChange)
+	if !ok {
+		return nil, false
+	}
+	change := &ShapeChangeJob{
+		ID:         job.ID,
+		InstanceID: job.ResourceID,
+		Status:     job.Status,
+		Steps:      job.Steps,
+		Result:     asyncJobValue[ShapeChangeResult](job.Result),
+		Error:      job.Error,
+		CreateTime: job.CreateTime.Format("2006-01-02 15:04:05"),
+	}
+	if params := asyncJobValue[ShapeChangeParams](job.Params); params != nil {
+		change.TargetShape = params.TargetShape
+	}
+	return change, true
+}
@@ -0,0 +1,34 @@
+package services
+
+import "testing"
+
+func TestShapeUpdateDetails(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    string
+		target     string
+		wantShape  bool
+		wantConfig bool
+	}{
+		{"Flex 内调整配置", "VM.Standard.A1.Flex", "VM.Standard.A1.Flex", false, true},
+		{"固定规格转 Flex", "VM.Standard.E2.1.Micro", "VM.Standard.E4.Flex", true, true},
+		{"Flex 转固定规格", "VM.Standard.E4.Flex", "VM.Standard.E2.1.Micro", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := shapeUpdateDetails(tt.current, tt.target, 2, 12)
+			if (details.Shape != nil) != tt.wantShape {
+				t.Errorf("Shape = %v, want set %v", details.Shape, tt.wantShape)
+			}
+			if details.Shape != nil && *details.Shape != tt.target {
+				t.Errorf("Shape = %q, want %q", *details.Shape, tt.target)
+			}
+			if (details.ShapeConfig != nil) != tt.wantConfig {
+				t.Errorf("ShapeConfig = %v, want set %v", details.ShapeConfig, tt.wantConfig)
+			}
+			if details.ShapeConfig != nil && (*details.ShapeConfig.Ocpus != 2 || *details.ShapeConfig.MemoryInGBs != 12) {
+				t.Errorf("ShapeConfig = %+v", details.ShapeConfig)
+			}
+		})
+	}
+}
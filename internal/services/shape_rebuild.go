@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// ShapeArchitecture 根据 Shape 名称判断架构（ARM / AMD）
+func ShapeArchitecture(shape string) string {
+	if strings.Contains(shape, ".A1.") || strings.Contains(shape, ".A2.") || strings.Contains(shape, ".A4.") {
+		return "ARM"
+	}
+	return "AMD"
+}
+
+// IsFlexShape 是否为可自定义 OCPU/内存的 Flex Shape
+func IsFlexShape(shape string) bool {
+	return strings.HasSuffix(shape, ".Flex")
+}
+
+// CrossArchitectureError 跨架构调整 Shape 时返回，提示改用重建流程
+type CrossArchitectureError struct {
+	CurrentShape string
+	TargetShape  string
+}
+
+func (e *CrossArchitectureError) Error() string {
+	return fmt.Sprintf("无法将 %s (%s) 直接调整为 %s (%s)，跨架构需要重建实例",
+		e.CurrentShape, ShapeArchitecture(e.CurrentShape), e.TargetShape, ShapeArchitecture(e.TargetShape))
+}
+
+// ShapeRebuildParams 跨架构重建参数
+type ShapeRebuildParams struct {
+	InstanceID        string
+	TargetShape       string
+	Ocpus             float32
+	MemoryInGBs       float32
+	ImageID           string // 为空时自动选择与原系统相同、适配目标 Shape 的最新镜像
+	KeepOldBootVolume bool   // 保留原引导卷，便于挂载取回数据
+}
+
+// ShapeRebuildResult 跨架构重建结果
+type ShapeRebuildResult struct {
+	NewInstanceID   string `json:"newInstanceId"`
+	PublicIP        string `json:"publicIp"`
+	BackupID        string `json:"backupId"`
+	OldBootVolumeID string `json:"oldBootVolumeId,omitempty"`
+}
+
+// RebuildInstanceWithShape 跨架构"调整"Shape：备份并保留原引导卷，终止原实例后
+// 在相同可用域和子网中使用目标架构镜像重新创建实例
+func (s *OCIService) RebuildInstanceWithShape(ctx context.Context, user *models.OciUser, params ShapeRebuildParams, progressChan chan<- AutoRescueProgress) (*ShapeRebuildResult, error) {
+	const totalSteps = 7
+
+	if IsInstanceProtected(params.InstanceID) {
+		return nil, fmt.Errorf("实例已开启终止保护，无法重建")
+	}
+
+	sendProgress := func(step int, status, message string) {
+		if progressChan != nil {
+			progressChan <- AutoRescueProgress{
+				Step:       step,
+				TotalSteps: totalSteps,
+				Status:     status,
+				Message:    message,
+			}
+		}
+	}
+
+	computeClient, err := s.GetComputeClient(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute client: %w", err)
+	}
+	blockClient, err := s.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blockstorage client: %w", err)
+	}
+	vnClient, err := s.GetVirtualNetworkClient(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual network client: %w", err)
+	}
+
+	instance, err := s.GetInstanceById(user, params.InstanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance: %w", err)
+	}
+	bootVolume, err := s.GetBootVolumeByInstanceId(user, params.InstanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boot volume: %w", err)
+	}
+
+	// Step 1: 收集原实例网络与镜像信息
+	sendProgress(1, "running", "正在读取原实例配置...")
+	vnicAttachments, err := computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+		CompartmentId: instance.CompartmentId,
+		InstanceId:    instance.Id,
+	})
+	if err != nil || len(vnicAttachments.Items) == 0 || vnicAttachments.Items[0].SubnetId == nil {
+		return nil, fmt.Errorf("failed to get instance subnet: %v", err)
+	}
+	subnetID := *vnicAttachments.Items[0].SubnetId
+
+	imageID := params.ImageID
+	if imageID == "" {
+		imageID, err = s.findImageForShape(ctx, computeClient, instance, params.TargetShape)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sendProgress(1, "completed", "读取原实例配置成功")
+
+	// Step 2: 关机
+	sendProgress(2, "running", "正在关机...")
+	if instance.LifecycleState != core.InstanceLifecycleStateStopped {
+		_, err = computeClient.InstanceAction(ctx, core.InstanceActionRequest{
+			InstanceId: instance.Id,
+			Action:     core.InstanceActionActionStop,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to stop instance: %w", err)
+		}
+		for {
+			instResp, err := computeClient.GetInstance(ctx, core.GetInstanceRequest{InstanceId: instance.Id})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get instance status: %w", err)
+			}
+			if instResp.LifecycleState == core.InstanceLifecycleStateStopped {
+				break
+			}
+			time.Sleep(2 * time.Second)
+		}
+	}
+	sendProgress(2, "completed", "关机成功")
+
+	// Step 3: 备份原引导卷
+	sendProgress(3, "running", "正在备份原引导卷...")
+	backupName := fmt.Sprintf("%s-before-rebuild", *instance.DisplayName)
+	backupResp, err := blockClient.CreateBootVolumeBackup(ctx, core.CreateBootVolumeBackupRequest{
+		CreateBootVolumeBackupDetails: core.CreateBootVolumeBackupDetails{
+			BootVolumeId: bootVolume.Id,
+			DisplayName:  &backupName,
+			Type:         core.CreateBootVolumeBackupDetailsTypeFull,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create boot volume backup: %w", err)
+	}
+	if err := waitBootVolumeBackupAvailable(ctx, blockClient, *backupResp.Id); err != nil {
+		return nil, err
+	}
+	sendProgress(3, "completed", "备份原引导卷成功")
+
+	// Step 4: 终止原实例，按需保留引导卷
+	sendProgress(4, "running", "正在终止原实例...")
+	preserveBootVolume := params.KeepOldBootVolume
+	_, err = computeClient.TerminateInstance(ctx, core.TerminateInstanceRequest{
+		InstanceId:         instance.Id,
+		PreserveBootVolume: &preserveBootVolume,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to terminate instance: %w", err)
+	}
+	for {
+		instResp, err := computeClient.GetInstance(ctx, core.GetInstanceRequest{InstanceId: instance.Id})
+		if err != nil || instResp.LifecycleState == core.InstanceLifecycleStateTerminated {
+			break
+		}
+		time.Sleep(3 * time.Second)
+	}
+	sendProgress(4, "completed", "原实例已终止")
+
+	// Step 5: 使用目标 Shape 创建新实例
+	sendProgress(5, "running", fmt.Sprintf("正在使用 %s 创建新实例...", params.TargetShape))
+	sourceDetails := core.InstanceSourceViaImageDetails{ImageId: &imageID}
+	if bootVolume.SizeInGBs != nil {
+		sourceDetails.BootVolumeSizeInGBs = bootVolume.SizeInGBs
+	}
+	launchDetails := core.LaunchInstanceDetails{
+		CompartmentId:      instance.CompartmentId,
+		AvailabilityDomain: instance.AvailabilityDomain,
+		DisplayName:        instance.DisplayName,
+		SourceDetails:      sourceDetails,
+		Shape:              &params.TargetShape,
+		CreateVnicDetails:  &core.CreateVnicDetails{SubnetId: &subnetID},
+		Metadata:           instance.Metadata,
+	}
+	if IsFlexShape(params.TargetShape) {
+		launchDetails.ShapeConfig = &core.LaunchInstanceShapeConfigDetails{
+			Ocpus:       &params.Ocpus,
+			MemoryInGBs: &params.MemoryInGBs,
+		}
+	}
+	launchResp, err := computeClient.LaunchInstance(ctx, core.LaunchInstanceRequest{LaunchInstanceDetails: launchDetails})
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch new instance: %w", err)
+	}
+	sendProgress(5, "completed", "新实例已提交创建")
+
+	// Step 6: 等待新实例运行
+	sendProgress(6, "running", "正在等待新实例启动...")
+	for i := 0; i < 60; i++ {
+		instResp, err := computeClient.GetInstance(ctx, core.GetInstanceRequest{InstanceId: launchResp.Id})
+		if err == nil && instResp.LifecycleState == core.InstanceLifecycleStateRunning {
+			break
+		}
+		time.Sleep(5 * time.Second)
+	}
+	sendProgress(6, "completed", "新实例已启动")
+
+	result := &ShapeRebuildResult{
+		NewInstanceID: *launchResp.Id,
+		BackupID:      *backupResp.Id,
+	}
+	if params.KeepOldBootVolume {
+		result.OldBootVolumeID = *bootVolume.Id
+	}
+
+	// Step 7: 获取公网IP
+	newVnics, err := computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+		CompartmentId: instance.CompartmentId,
+		InstanceId:    launchResp.Id,
+	})
+	if err == nil && len(newVnics.Items) > 0 && newVnics.Items[0].VnicId != nil {
+		vnicResp, err := vnClient.GetVnic(ctx, core.GetVnicRequest{VnicId: newVnics.Items[0].VnicId})
+		if err == nil && vnicResp.PublicIp != nil {
+			result.PublicIP = *vnicResp.PublicIp
+		}
+	}
+
+	if progressChan != nil {
+		progressChan <- AutoRescueProgress{
+			Step:       totalSteps,
+			TotalSteps: totalSteps,
+			Status:     "completed",
+			Message:    "实例重建成功",
+			PublicIP:   result.PublicIP,
+		}
+	}
+
+	return result, nil
+}
+
+// findImageForShape 查找与原实例操作系统相同且适配目标 Shape 的最新平台镜像
+func (s *OCIService) findImageForShape(ctx context.Context, client core.ComputeClient, instance *core.Instance, targetShape string) (string, error) {
+	req := core.ListImagesRequest{
+		CompartmentId: instance.CompartmentId,
+		Shape:         &targetShape,
+		SortBy:        core.ListImagesSortByTimecreated,
+		SortOrder:     core.ListImagesSortOrderDesc,
+	}
+
+	if instance.ImageId != nil {
+		imageResp, err := client.GetImage(ctx, core.GetImageRequest{ImageId: instance.ImageId})
+		if err == nil {
+			req.OperatingSystem = imageResp.OperatingSystem
+			req.OperatingSystemVersion = imageResp.OperatingSystemVersion
+		}
+	}
+
+	resp, err := client.ListImages(ctx, req)
+	if err == nil && len(resp.Items) == 0 && req.OperatingSystemVersion != nil {
+		// 目标架构没有相同版本时退回到同系统最新版本
+		req.OperatingSystemVersion = nil
+		resp, err = client.ListImages(ctx, req)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to list images: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return "", fmt.Errorf("没有找到适配 %s 的镜像", targetShape)
+	}
+	return *resp.Items[0].Id, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	defaultTaskAnalyticsDays = 7
+	maxTaskAnalyticsDays     = 90
+)
+
+// taskErrorTypes 按顺序匹配的错误分类关键字，均不匹配时归为 other
+var taskErrorTypes = []struct {
+	Type     string
+	Keywords []string
+}{
+	{"capacity", []string{"out of host capacity", "out of capacity"}},
+	{"rate_limit", []string{"toomanyrequests", "too many requests"}},
+	{"limit", []string{"limitexceeded", "limit exceeded", "limits were exceeded", "service limit", "quota"}},
+	{"auth", []string{"notauthenticated", "notauthorized", "authentication", "authorization", "private key", "fingerprint"}},
+}
+
+// classifyTaskError 根据日志内容判断错误类型
+func classifyTaskError(message string) string {
+	msg := strings.ToLower(message)
+	for _, errorType := range taskErrorTypes {
+		for _, keyword := range errorType.Keywords {
+			if strings.Contains(msg, keyword) {
+				return errorType.Type
+			}
+		}
+	}
+	return "other"
+}
+
+// GetTaskAnalytics 汇总任务最近 days 天的执行日志：按小时的尝试次数、错误类型分布，以及首次成功耗时
+func (s *TaskService) GetTaskAnalytics(taskID string, days int) (*models.TaskAnalytics, error) {
+	if days <= 0 {
+		days = defaultTaskAnalyticsDays
+	}
+	if days > maxTaskAnalyticsDays {
+		days = maxTaskAnalyticsDays
+	}
+
+	db := database.GetDB()
+	var task models.OciCreateTask
+	if err := db.Where("id = ?", taskID).First(&task).Error; err != nil {
+		return nil, fmt.Errorf("任务不存在")
+	}
+
+	var logs []models.TaskLog
+	if err := db.Select("status, message, execute_time").
+		Where("task_id = ? AND status IN ? AND execute_time >= ?", taskID, []string{"success", "error"}, time.Now().AddDate(0, 0, -days)).
+		Order("execute_time ASC").
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	analytics := &models.TaskAnalytics{
+		TaskID:     taskID,
+		Days:       days,
+		ErrorTypes: []models.TaskErrorTypeStat{},
+		Timeline:   []models.TaskAttemptBucket{},
+	}
+	errorCounts := make(map[string]int64)
+	for _, entry := range logs {
+		bucketTime := entry.ExecuteTime.In(time.Local).Format("2006-01-02 15:00")
+		if n := len(analytics.Timeline); n == 0 || analytics.Timeline[n-1].Time != bucketTime {
+			analytics.Timeline = append(analytics.Timeline, models.TaskAttemptBucket{Time: bucketTime})
+		}
+		bucket := &analytics.Timeline[len(analytics.Timeline)-1]
+		bucket.Attempts++
+		analytics.Attempts++
+		if entry.Status == "success" {
+			bucket.Successes++
+			analytics.Successes++
+		} else {
+			bucket.Failures++
+			analytics.Failures++
+			errorCounts[classifyTaskError(entry.Message)]++
+		}
+	}
+	if analytics.Attempts > 0 {
+		analytics.SuccessRate = float64(analytics.Successes) * 100 / float64(analytics.Attempts)
+	}
+	typeNames := make([]string, 0, len(taskErrorTypes)+1)
+	for _, errorType := range taskErrorTypes {
+		typeNames = append(typeNames, errorType.Type)
+	}
+	for _, name := range append(typeNames, "other") {
+		if count := errorCounts[name]; count > 0 {
+			analytics.ErrorTypes = append(analytics.ErrorTypes, models.TaskErrorTypeStat{Type: name, Count: count})
+		}
+	}
+
+	// 首次成功不受统计范围限制，日志被清理后只能从剩余日志中推算
+	var firstSuccess models.TaskLog
+	if err := db.Where("task_id = ? AND status = ?", taskID, "success").
+		Order("execute_time ASC").
+		First(&firstSuccess).Error; err == nil {
+		analytics.FirstSuccessTime = firstSuccess.ExecuteTime.In(time.Local).Format("2006-01-02 15:04:05")
+		seconds := int64(firstSuccess.ExecuteTime.Sub(task.CreateTime).Seconds())
+		analytics.TimeToSuccessSeconds = &seconds
+		db.Model(&models.TaskLog{}).
+			Where("task_id = ? AND status IN ? AND execute_time <= ?", taskID, []string{"success", "error"}, firstSuccess.ExecuteTime).
+			Count(&analytics.AttemptsToSuccess)
+	}
+	return analytics, nil
+}
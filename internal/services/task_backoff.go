@@ -0,0 +1,90 @@
+package services
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	// 未设置退避上限时的默认值（秒）
+	DefaultTaskBackoffMax = 1800
+	// 最短执行间隔（秒），可通过硬性限制调高
+	minTaskInterval = 10
+)
+
+// capacityErrorKeywords 视为容量不足/限流、需要退避的错误关键字
+var capacityErrorKeywords = []string{
+	"out of host capacity",
+	"out of capacity",
+	"toomanyrequests",
+	"too many requests",
+}
+
+// isCapacityError 判断错误是否属于容量不足或限流
+func isCapacityError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, keyword := range capacityErrorKeywords {
+		if strings.Contains(msg, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// taskBackoffBounds 返回任务退避的起始值与上限（秒）
+func taskBackoffBounds(task *models.OciCreateTask) (int, int) {
+	minBackoff := task.BackoffMin
+	if minBackoff <= 0 {
+		minBackoff = task.Interval
+	}
+	if minInterval := effectiveMinTaskInterval(); minBackoff < minInterval {
+		minBackoff = minInterval
+	}
+	maxBackoff := task.BackoffMax
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultTaskBackoffMax
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	return minBackoff, maxBackoff
+}
+
+// updateTaskBackoff 根据本次执行结果调整退避：容量不足时指数递增，其它结果重置
+func updateTaskBackoff(task *models.OciCreateTask, err error) {
+	if !isCapacityError(err) {
+		task.CurrentBackoff = 0
+		return
+	}
+
+	minBackoff, maxBackoff := taskBackoffBounds(task)
+	if task.CurrentBackoff < minBackoff {
+		task.CurrentBackoff = minBackoff
+		return
+	}
+	task.CurrentBackoff *= 2
+	if task.CurrentBackoff > maxBackoff {
+		task.CurrentBackoff = maxBackoff
+	}
+}
+
+// taskDelay 计算下次执行的等待时间，退避期间在 [backoff, 1.5×backoff] 内随机抖动，不会早于正常间隔
+// 结果始终不低于硬性限制的最短执行间隔，退避值可能是在调高限制之前计算的
+func taskDelay(task *models.OciCreateTask) time.Duration {
+	delay := time.Duration(task.Interval) * time.Second
+	if task.CurrentBackoff > 0 {
+		backoff := time.Duration(task.CurrentBackoff) * time.Second
+		delay = backoff + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	}
+
+	if minInterval := time.Duration(effectiveMinTaskInterval()) * time.Second; delay < minInterval {
+		delay = minInterval
+	}
+	return delay
+}
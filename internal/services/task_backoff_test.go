@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+// withGuardRails 在测试期间使用指定的硬性限制
+func withGuardRails(t *testing.T, rails GuardRails) {
+	t.Helper()
+	previous := guardRailsValue.Load()
+	guardRailsValue.Store(&rails)
+	t.Cleanup(func() { guardRailsValue.Store(previous) })
+}
+
+func TestTaskDelay(t *testing.T) {
+	tests := []struct {
+		name            string
+		interval        int
+		backoff         int
+		minTaskInterval int
+		wantMin         time.Duration
+		wantMax         time.Duration
+	}{
+		{"正常间隔", 60, 0, 0, 60 * time.Second, 60 * time.Second},
+		{"间隔低于内置下限", 3, 0, 0, 10 * time.Second, 10 * time.Second},
+		{"间隔低于硬性限制", 60, 0, 120, 120 * time.Second, 120 * time.Second},
+		{"退避向上抖动", 60, 100, 0, 100 * time.Second, 150 * time.Second},
+		{"退避低于硬性限制", 60, 100, 300, 300 * time.Second, 300 * time.Second},
+		{"退避抖动后仍低于硬性限制", 60, 100, 140, 140 * time.Second, 150 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withGuardRails(t, GuardRails{MinTaskInterval: tt.minTaskInterval})
+			task := &models.OciCreateTask{Interval: tt.interval, CurrentBackoff: tt.backoff}
+			for i := 0; i < 200; i++ {
+				if got := taskDelay(task); got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("taskDelay = %v, want [%v, %v]", got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateTaskBackoff(t *testing.T) {
+	capacityErr := errors.New("Out of host capacity.")
+	tests := []struct {
+		name            string
+		task            models.OciCreateTask
+		err             error
+		minTaskInterval int
+		want            int
+	}{
+		{"成功时重置", models.OciCreateTask{Interval: 60, CurrentBackoff: 240}, nil, 0, 0},
+		{"其他错误时重置", models.OciCreateTask{Interval: 60, CurrentBackoff: 240}, errors.New("NotAuthorized"), 0, 0},
+		{"首次容量不足从间隔开始", models.OciCreateTask{Interval: 60}, capacityErr, 0, 60},
+		{"首次容量不足从起始值开始", models.OciCreateTask{Interval: 60, BackoffMin: 90}, capacityErr, 0, 90},
+		{"连续容量不足翻倍", models.OciCreateTask{Interval: 60, CurrentBackoff: 120}, capacityErr, 0, 240},
+		{"不超过上限", models.OciCreateTask{Interval: 60, BackoffMax: 300, CurrentBackoff: 240}, capacityErr, 0, 300},
+		{"未设置上限时使用默认上限", models.OciCreateTask{Interval: 60, CurrentBackoff: DefaultTaskBackoffMax}, capacityErr, 0, DefaultTaskBackoffMax},
+		{"起始值不低于硬性限制", models.OciCreateTask{Interval: 60}, capacityErr, 600, 600},
+		{"上限低于硬性限制时按硬性限制", models.OciCreateTask{Interval: 60, BackoffMax: 300, CurrentBackoff: 600}, capacityErr, 600, 600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withGuardRails(t, GuardRails{MinTaskInterval: tt.minTaskInterval})
+			task := tt.task
+			updateTaskBackoff(&task, tt.err)
+			if task.CurrentBackoff != tt.want {
+				t.Errorf("CurrentBackoff = %d, want %d", task.CurrentBackoff, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	SettingTaskConcurrency = "task_concurrency"
+
+	// 每个租户默认同时进行的开机请求数
+	DefaultTaskConcurrency = 2
+	MaxTaskConcurrency     = 20
+
+	SettingTaskRegionSpacing = "task_region_spacing_ms"
+
+	// 同一租户同一区域相邻两次开机请求的默认最小间隔（毫秒），为 0 时不限制
+	DefaultTaskRegionSpacingMs = 1000
+	MaxTaskRegionSpacingMs     = 60000
+)
+
+// tenantLimiter 按租户（OciUser）限制同时进行的开机请求数，避免多个任务同时触发 OCI 限流
+// 名额不足时按任务优先级从高到低放行，同优先级先到先得；maxTenants 大于 0 时还限制同时进行请求的租户数
+type tenantLimiter struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	limit      int
+	maxTenants int
+	active     map[string]int
+	waiting    map[string][]*limiterWaiter
+	seq        uint64
+}
+
+type limiterWaiter struct {
+	priority int
+	seq      uint64
+}
+
+func newTenantLimiter(limit int) *tenantLimiter {
+	l := &tenantLimiter{
+		limit:   limit,
+		active:  make(map[string]int),
+		waiting: make(map[string][]*limiterWaiter),
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire 等待租户的并发名额
+func (l *tenantLimiter) acquire(userID string, priority int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	w := &limiterWaiter{priority: priority, seq: l.seq}
+	l.waiting[userID] = append(l.waiting[userID], w)
+	for l.active[userID] >= l.limit || l.tenantsFull(userID) || l.nextWaiter(userID) != w {
+		l.cond.Wait()
+	}
+	l.removeWaiter(userID, w)
+	l.active[userID]++
+	// 名额可能仍有剩余，唤醒下一个等待者
+	l.cond.Broadcast()
+}
+
+// tenantsFull 租户当前没有进行中的请求，且进行请求的租户数已达上限
+func (l *tenantLimiter) tenantsFull(userID string) bool {
+	return l.maxTenants > 0 && l.active[userID] == 0 && len(l.active) >= l.maxTenants
+}
+
+// nextWaiter 返回租户下优先级最高、最早到达的等待者
+func (l *tenantLimiter) nextWaiter(userID string) *limiterWaiter {
+	var best *limiterWaiter
+	for _, w := range l.waiting[userID] {
+		if best == nil || w.priority > best.priority || (w.priority == best.priority && w.seq < best.seq) {
+			best = w
+		}
+	}
+	return best
+}
+
+func (l *tenantLimiter) removeWaiter(userID string, w *limiterWaiter) {
+	waiters := l.waiting[userID]
+	for i, item := range waiters {
+		if item == w {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(l.waiting, userID)
+	} else {
+		l.waiting[userID] = waiters
+	}
+}
+
+// release 归还租户的并发名额
+func (l *tenantLimiter) release(userID string) {
+	l.mu.Lock()
+	if l.active[userID]--; l.active[userID] <= 0 {
+		delete(l.active, userID)
+	}
+	l.mu.Unlock()
+	l.cond.Broadcast()
+}
+
+func (l *tenantLimiter) setLimit(limit int) {
+	l.mu.Lock()
+	l.limit = limit
+	l.mu.Unlock()
+	l.cond.Broadcast()
+}
+
+func (l *tenantLimiter) setMaxTenants(maxTenants int) {
+	l.mu.Lock()
+	l.maxTenants = maxTenants
+	l.mu.Unlock()
+	l.cond.Broadcast()
+}
+
+func (l *tenantLimiter) getLimit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// regionSpacer 让同一租户同一区域的开机请求按到达顺序排队，相邻请求至少间隔 spacing
+// 多个任务同一秒触发时容易一起收到 429，或在同一批容量上互相争抢
+type regionSpacer struct {
+	mu      sync.Mutex
+	spacing time.Duration
+	next    map[string]time.Time // 各区域下一个请求最早可发出的时间
+}
+
+func newRegionSpacer(spacing time.Duration) *regionSpacer {
+	return &regionSpacer{spacing: spacing, next: make(map[string]time.Time)}
+}
+
+// wait 预约租户区域的下一个时间槽并等待到该时间
+func (r *regionSpacer) wait(userID, region string) {
+	r.mu.Lock()
+	if r.spacing <= 0 {
+		r.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	for key, next := range r.next {
+		if next.Before(now) {
+			delete(r.next, key)
+		}
+	}
+	key := userID + "|" + region
+	slot := r.next[key]
+	if slot.Before(now) {
+		slot = now
+	}
+	r.next[key] = slot.Add(r.spacing)
+	r.mu.Unlock()
+
+	time.Sleep(time.Until(slot))
+}
+
+func (r *regionSpacer) setSpacing(spacing time.Duration) {
+	r.mu.Lock()
+	r.spacing = spacing
+	r.mu.Unlock()
+}
+
+func (r *regionSpacer) getSpacing() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.spacing
+}
+
+// loadTaskConcurrency 从系统设置加载租户并发上限
+func (s *TaskService) loadTaskConcurrency() {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingTaskConcurrency).First(&setting).Error; err != nil {
+		return
+	}
+	limit, err := strconv.Atoi(setting.Value)
+	if err != nil || limit <= 0 || limit > MaxTaskConcurrency {
+		log.Printf("Invalid task concurrency setting %q, using %d", setting.Value, DefaultTaskConcurrency)
+		return
+	}
+	s.limiter.setLimit(limit)
+}
+
+// loadTaskRegionSpacing 从系统设置加载同区域开机请求的最小间隔
+func (s *TaskService) loadTaskRegionSpacing() {
+	var setting models.SysSetting
+	if err := database.GetDB().Where("key = ?", SettingTaskRegionSpacing).First(&setting).Error; err != nil {
+		return
+	}
+	ms, err := strconv.Atoi(setting.Value)
+	if err != nil || ms < 0 || ms > MaxTaskRegionSpacingMs {
+		log.Printf("Invalid task region spacing setting %q, using %dms", setting.Value, DefaultTaskRegionSpacingMs)
+		return
+	}
+	s.regionSpacer.setSpacing(time.Duration(ms) * time.Millisecond)
+}
+
+// GetTaskConcurrency 获取每个租户同时进行的开机请求上限
+func (s *TaskService) GetTaskConcurrency() int {
+	return s.limiter.getLimit()
+}
+
+// SetTaskConcurrency 设置每个租户同时进行的开机请求上限
+func (s *TaskService) SetTaskConcurrency(limit int) error {
+	if err := saveSetting(SettingTaskConcurrency, strconv.Itoa(limit)); err != nil {
+		return err
+	}
+	s.limiter.setLimit(limit)
+	return nil
+}
+
+// GetTaskRegionSpacing 获取同一租户同一区域相邻开机请求的最小间隔（毫秒）
+func (s *TaskService) GetTaskRegionSpacing() int {
+	return int(s.regionSpacer.getSpacing() / time.Millisecond)
+}
+
+// SetTaskRegionSpacing 设置同一租户同一区域相邻开机请求的最小间隔（毫秒），为 0 时不限制
+func (s *TaskService) SetTaskRegionSpacing(ms int) error {
+	if err := saveSetting(SettingTaskRegionSpacing, strconv.Itoa(ms)); err != nil {
+		return err
+	}
+	s.regionSpacer.setSpacing(time.Duration(ms) * time.Millisecond)
+	return nil
+}
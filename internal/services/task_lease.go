@@ -0,0 +1,91 @@
+package services
+
+import (
+	"log"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// 租约至少覆盖一次执行的耗时，持有者在每次调度下次执行时续约
+	taskLeaseTTL = 10 * time.Minute
+	// 未取得租约的实例在租约过期后重新尝试，加入随机延迟避免同时抢占
+	taskLeaseJitter = 10 * time.Second
+)
+
+// defaultTaskNodeID 未配置实例标识时使用主机名，重启后保持不变以便继续持有原租约
+func defaultTaskNodeID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "oci-panel"
+	}
+	return host
+}
+
+// SetNodeID 设置当前实例标识，多个进程共享同一个数据库文件时每个实例的标识必须不同
+func (s *TaskService) SetNodeID(nodeID string) {
+	if nodeID != "" {
+		s.nodeID = nodeID
+	}
+}
+
+// acquireTaskLease 尝试取得或续约任务的执行权，失败时返回当前租约的过期时间
+func (s *TaskService) acquireTaskLease(taskID string) (bool, time.Time) {
+	db := database.GetDB()
+	now := time.Now()
+	expireAt := now.Add(taskLeaseTTL)
+
+	result := db.Model(&models.TaskLease{}).
+		Where("task_id = ? AND (owner = ? OR expire_at < ?)", taskID, s.nodeID, now).
+		Updates(map[string]interface{}{"owner": s.nodeID, "expire_at": expireAt})
+	if result.Error == nil && result.RowsAffected > 0 {
+		return true, expireAt
+	}
+
+	result = db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.TaskLease{TaskID: taskID, Owner: s.nodeID, ExpireAt: expireAt})
+	if result.Error == nil && result.RowsAffected > 0 {
+		return true, expireAt
+	}
+
+	var lease models.TaskLease
+	if err := db.Where("task_id = ?", taskID).First(&lease).Error; err != nil {
+		log.Printf("Failed to acquire lease for task %s: %v", taskID, err)
+		return false, expireAt
+	}
+	return false, lease.ExpireAt
+}
+
+// extendTaskLease 将当前实例持有的租约延长到 expireAt，未持有时不做任何修改
+func (s *TaskService) extendTaskLease(taskID string, expireAt time.Time) {
+	database.GetDB().Model(&models.TaskLease{}).
+		Where("task_id = ? AND owner = ?", taskID, s.nodeID).
+		Update("expire_at", expireAt)
+}
+
+// releaseTaskLease 释放当前实例持有的租约，使其它实例可以立即接管
+func (s *TaskService) releaseTaskLease(taskID string) {
+	database.GetDB().Where("task_id = ? AND owner = ?", taskID, s.nodeID).Delete(&models.TaskLease{})
+}
+
+// watchTaskLease 任务由其它实例执行时，在其租约过期后再次尝试，持有者宕机时由本实例接管
+func (s *TaskService) watchTaskLease(taskID string, expireAt time.Time) {
+	s.timerMutex.Lock()
+	defer s.timerMutex.Unlock()
+
+	if existingTimer, ok := s.taskTimers[taskID]; ok {
+		existingTimer.Stop()
+	}
+	delay := time.Until(expireAt) + time.Duration(rand.Int63n(int64(taskLeaseJitter)))
+	if delay < 0 {
+		delay = 0
+	}
+	s.taskTimers[taskID] = time.AfterFunc(delay, func() {
+		s.executeTask(taskID)
+	})
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	taskStopDeadline     = "deadline"
+	taskStopMaxExecution = "max_execute_count"
+)
+
+// taskTargetCount 任务需要创建的实例数
+func taskTargetCount(task *models.OciCreateTask) int {
+	if task.CreateNumbers < 1 {
+		return 1
+	}
+	return task.CreateNumbers
+}
+
+// taskStopCondition 判断任务是否已达到停止条件，未达到时返回空字符串
+func taskStopCondition(task *models.OciCreateTask, now time.Time) string {
+	if task.ExpireAt != nil && !now.Before(*task.ExpireAt) {
+		return taskStopDeadline
+	}
+	if task.MaxExecuteCount > 0 && task.ExecuteCount >= task.MaxExecuteCount {
+		return taskStopMaxExecution
+	}
+	return ""
+}
+
+// expireTask 将任务标记为已过期并停止调度，同时发送 Telegram 通知
+func (s *TaskService) expireTask(task *models.OciCreateTask, condition string) {
+	reason := fmt.Sprintf("已达到最大执行次数 %d", task.MaxExecuteCount)
+	if condition == taskStopDeadline {
+		reason = fmt.Sprintf("已到达截止时间 %s", task.ExpireAt.Format("2006-01-02 15:04:05"))
+	}
+
+	task.Status = "expired"
+	task.NextExecuteTime = nil
+	task.LastMessage = fmt.Sprintf("任务已自动停止：%s", reason)
+	database.GetDB().Save(task)
+
+	s.removeTaskTimer(task.ID)
+	s.logTaskExecution(task.ID, "expired", task.LastMessage)
+
+	if s.telegramService == nil {
+		return
+	}
+	tg := s.telegramService
+	detail := tg.t("task_expired_max_count", task.MaxExecuteCount)
+	if condition == taskStopDeadline {
+		detail = tg.t("task_expired_deadline", task.ExpireAt.Format("2006-01-02 15:04:05"))
+	}
+	message := tg.t("task_expired_notify", task.Username, task.OciRegion,
+		task.Ocpus, task.Memory, task.Disk, task.Architecture, task.ExecuteCount,
+		task.SuccessCount, taskTargetCount(task), detail)
+	if err := tg.SendNotification(tg.t("task_expired_notify_title"), message); err != nil {
+		log.Printf("Failed to send task expired notification for %s: %v", task.ID, err)
+	}
+}
+
+// notifyTaskCreated 任务成功创建实例后发送 Telegram 通知，附带创建进度
+func (s *TaskService) notifyTaskCreated(task *models.OciCreateTask, region string) {
+	if s.telegramService == nil {
+		return
+	}
+	tg := s.telegramService
+	message := tg.t("task_created_notify", task.Username, region,
+		task.Ocpus, task.Memory, task.Disk, task.Architecture, task.ExecuteCount,
+		task.SuccessCount, taskTargetCount(task))
+	if err := tg.SendNotification(tg.t("task_created_notify_title"), message); err != nil {
+		log.Printf("Failed to send task created notification for %s: %v", task.ID, err)
+	}
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	PostActionTypeWebhook = "webhook"
+	PostActionTypeScript  = "script"
+
+	defaultPostActionTimeout = 30
+	maxPostActionTimeout     = 600
+
+	// 等待实例进入运行状态后再绑定公网IP、收集实例信息
+	hookWaitRunningTimeout = 5 * time.Minute
+	hookWaitRunningPoll    = 10 * time.Second
+
+	// 写入任务日志的响应/输出最大长度
+	hookOutputLimit = 500
+)
+
+// TaskHookPayload 开机成功后发送给 Webhook 或脚本的实例信息
+type TaskHookPayload struct {
+	Event              string   `json:"event"`
+	TaskID             string   `json:"taskId"`
+	ConfigName         string   `json:"configName"`
+	Region             string   `json:"region"`
+	AvailabilityDomain string   `json:"availabilityDomain"`
+	InstanceID         string   `json:"instanceId"`
+	DisplayName        string   `json:"displayName"`
+	Shape              string   `json:"shape"`
+	State              string   `json:"state"`
+	Ocpus              float64  `json:"ocpus"`
+	Memory             float64  `json:"memory"`
+	Disk               int      `json:"disk"`
+	Architecture       string   `json:"architecture"`
+	OperationSystem    string   `json:"operationSystem"`
+	PublicIPs          []string `json:"publicIps"`
+	PrivateIPs         []string `json:"privateIps"`
+	SuccessCount       int      `json:"successCount"`
+	CreateNumbers      int      `json:"createNumbers"`
+	Time               string   `json:"time"`
+}
+
+// ValidateWebhookURL 校验 Webhook 地址，仅允许 http/https
+func ValidateWebhookURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Webhook地址无效")
+	}
+	return nil
+}
+
+// ValidatePostAction 校验开机后动作的配置
+func ValidatePostAction(action *models.PostProvisionAction) error {
+	switch action.Type {
+	case PostActionTypeWebhook:
+		if action.URL == "" {
+			return fmt.Errorf("Webhook地址不能为空")
+		}
+		if err := ValidateWebhookURL(action.URL); err != nil {
+			return err
+		}
+		if _, err := parseHookHeaders(action.Headers); err != nil {
+			return err
+		}
+	case PostActionTypeScript:
+		if strings.TrimSpace(action.Script) == "" {
+			return fmt.Errorf("脚本内容不能为空")
+		}
+	default:
+		return fmt.Errorf("不支持的动作类型: %s", action.Type)
+	}
+	if action.Timeout < 0 || action.Timeout > maxPostActionTimeout {
+		return fmt.Errorf("超时时间需在 0-%d 秒之间", maxPostActionTimeout)
+	}
+	return nil
+}
+
+// parseHookHeaders 解析每行一个的 "Key: Value" 请求头
+func parseHookHeaders(value string) (http.Header, error) {
+	headers := http.Header{}
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("请求头格式无效: %s", line)
+		}
+		headers.Add(strings.TrimSpace(key), strings.TrimSpace(val))
+	}
+	return headers, nil
+}
+
+// UpdateTaskHook 设置任务开机成功后的 Webhook 与动作
+func (s *TaskService) UpdateTaskHook(taskID, webhookURL, postActionID string) error {
+	if err := ValidateWebhookURL(webhookURL); err != nil {
+		return err
+	}
+	db := database.GetDB()
+	if postActionID != "" {
+		var action models.PostProvisionAction
+		if err := db.Where("id = ?", postActionID).First(&action).Error; err != nil {
+			return fmt.Errorf("开机后动作不存在")
+		}
+	}
+
+	result := db.Model(&models.OciCreateTask{}).Where("id = ?", taskID).Updates(map[string]interface{}{
+		"webhook_url":    webhookURL,
+		"post_action_id": postActionID,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("任务不存在")
+	}
+	return nil
+}
+
+// startPostCreateHooks 异步执行任务配置的开机后处理：绑定预留公网IP、调用 Webhook 与执行动作，结果写入任务日志
+func (s *TaskService) startPostCreateHooks(task models.OciCreateTask, user models.OciUser, region, ad string, instance *core.Instance) {
+	if instance == nil || instance.Id == nil || (task.WebhookURL == "" && task.PostActionID == "" && task.ReservedPublicIP == "") {
+		return
+	}
+
+	go func() {
+		regionUser := user
+		regionUser.OciRegion = region
+		state := s.waitInstanceRunning(&regionUser, *instance.Id)
+		running := state == core.InstanceLifecycleStateRunning
+
+		if task.ReservedPublicIP != "" {
+			if !running {
+				s.logHookResult(task.ID, "绑定预留公网IP", "", fmt.Errorf("实例未进入运行状态（%s）", state))
+			} else if task.ReservedPublicIP != ReservedPublicIPNew && region != task.OciRegion {
+				s.logHookResult(task.ID, "绑定预留公网IP", "", fmt.Errorf("预留公网IP属于区域 %s，实例位于 %s", task.OciRegion, region))
+			} else {
+				ip, err := s.ociService.AssignReservedPublicIP(context.Background(), &regionUser, *instance.Id, task.ReservedPublicIP)
+				s.logHookResult(task.ID, "绑定预留公网IP", ip, err)
+			}
+		}
+
+		if task.WebhookURL == "" && task.PostActionID == "" {
+			return
+		}
+		payload := s.buildHookPayload(&task, &regionUser, region, ad, instance, state)
+
+		if task.WebhookURL != "" {
+			status, err := sendHookWebhook(task.WebhookURL, nil, defaultPostActionTimeout, payload)
+			s.logHookResult(task.ID, "Webhook", status, err)
+		}
+
+		if task.PostActionID == "" {
+			return
+		}
+		var action models.PostProvisionAction
+		if err := database.GetDB().Where("id = ?", task.PostActionID).First(&action).Error; err != nil {
+			s.logHookResult(task.ID, "开机后动作", "", fmt.Errorf("动作不存在"))
+			return
+		}
+		name := fmt.Sprintf("开机后动作 %s", action.Name)
+		output, err := runPostAction(&action, payload)
+		s.logHookResult(task.ID, name, output, err)
+	}()
+}
+
+// waitInstanceRunning 等待实例进入运行状态，超时返回最后一次查询到的状态
+func (s *TaskService) waitInstanceRunning(user *models.OciUser, instanceId string) core.InstanceLifecycleStateEnum {
+	ctx, cancel := context.WithTimeout(context.Background(), hookWaitRunningTimeout)
+	defer cancel()
+
+	state := core.InstanceLifecycleStateProvisioning
+	for {
+		current, err := s.ociService.GetInstance(ctx, user, instanceId)
+		if err == nil {
+			state = current.LifecycleState
+			if state == core.InstanceLifecycleStateRunning {
+				return state
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return state
+		case <-time.After(hookWaitRunningPoll):
+		}
+	}
+}
+
+func (s *TaskService) logHookResult(taskID, name, output string, err error) {
+	if err != nil {
+		message := fmt.Sprintf("%s执行失败: %v", name, err)
+		if output != "" {
+			message = fmt.Sprintf("%s（%s）", message, output)
+		}
+		log.Printf("Task %s post-create hook failed: %s", taskID, message)
+		s.logTaskExecution(taskID, "hook_error", message)
+		return
+	}
+	message := fmt.Sprintf("%s执行成功", name)
+	if output != "" {
+		message = fmt.Sprintf("%s: %s", message, output)
+	}
+	s.logTaskExecution(taskID, "hook", message)
+}
+
+// buildHookPayload 收集实例信息，实例未运行时仅包含创建时返回的信息
+func (s *TaskService) buildHookPayload(task *models.OciCreateTask, user *models.OciUser, region, ad string, instance *core.Instance, state core.InstanceLifecycleStateEnum) *TaskHookPayload {
+	payload := &TaskHookPayload{
+		Event:              "instance_created",
+		TaskID:             task.ID,
+		ConfigName:         task.Username,
+		Region:             region,
+		AvailabilityDomain: ad,
+		InstanceID:         *instance.Id,
+		State:              string(state),
+		Ocpus:              task.Ocpus,
+		Memory:             task.Memory,
+		Disk:               task.Disk,
+		Architecture:       task.Architecture,
+		OperationSystem:    task.OperationSystem,
+		PublicIPs:          []string{},
+		PrivateIPs:         []string{},
+		SuccessCount:       task.SuccessCount,
+		CreateNumbers:      taskTargetCount(task),
+		Time:               time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if instance.DisplayName != nil {
+		payload.DisplayName = *instance.DisplayName
+	}
+	if instance.Shape != nil {
+		payload.Shape = *instance.Shape
+	}
+
+	if state != core.InstanceLifecycleStateRunning {
+		return payload
+	}
+	if details, err := s.ociService.GetInstanceDetails(context.Background(), user, *instance.Id); err == nil {
+		payload.PublicIPs = details.PublicIPs
+		payload.PrivateIPs = details.PrivateIPs
+	}
+	return payload
+}
+
+// runPostAction 执行开机后动作，返回响应状态或脚本输出
+func runPostAction(action *models.PostProvisionAction, payload *TaskHookPayload) (string, error) {
+	timeout := action.Timeout
+	if timeout <= 0 {
+		timeout = defaultPostActionTimeout
+	}
+	if action.Type == PostActionTypeScript {
+		return runHookScript(action.Script, timeout, payload)
+	}
+	headers, err := parseHookHeaders(action.Headers)
+	if err != nil {
+		return "", err
+	}
+	return sendHookWebhook(action.URL, headers, timeout, payload)
+}
+
+// sendHookWebhook 以 JSON POST 实例信息，非 2xx 响应视为失败
+func sendHookWebhook(target string, headers http.Header, timeout int, payload *TaskHookPayload) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, hookOutputLimit))
+	status := fmt.Sprintf("HTTP %d", resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return truncateHookOutput(string(respBody)), fmt.Errorf("%s", status)
+	}
+	return status, nil
+}
+
+// runHookScript 在面板所在主机执行脚本，实例信息通过环境变量与标准输入（JSON）传入
+func runHookScript(script string, timeout int, payload *TaskHookPayload) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", script)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", script)
+	}
+	cmd.Stdin = bytes.NewReader(body)
+	// 超时后子进程仍占用输出管道时不再无限等待
+	cmd.WaitDelay = 5 * time.Second
+	cmd.Env = append(os.Environ(),
+		"OCI_TASK_ID="+payload.TaskID,
+		"OCI_CONFIG_NAME="+payload.ConfigName,
+		"OCI_REGION="+payload.Region,
+		"OCI_INSTANCE_ID="+payload.InstanceID,
+		"OCI_INSTANCE_NAME="+payload.DisplayName,
+		"OCI_INSTANCE_STATE="+payload.State,
+		"OCI_PUBLIC_IP="+strings.Join(payload.PublicIPs, ","),
+		"OCI_PRIVATE_IP="+strings.Join(payload.PrivateIPs, ","),
+	)
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("执行超时（%d 秒）", timeout)
+	}
+	return truncateHookOutput(string(output)), err
+}
+
+func truncateHookOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if len([]rune(output)) > hookOutputLimit {
+		output = string([]rune(output)[:hookOutputLimit]) + "..."
+	}
+	return output
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"gorm.io/gorm"
+)
+
+// RecycledTask 回收站中的任务
+type RecycledTask struct {
+	ID           string  `json:"id"`
+	Username     string  `json:"username"`
+	OciRegion    string  `json:"ociRegion"`
+	Architecture string  `json:"architecture"`
+	Ocpus        float64 `json:"ocpus"`
+	Memory       float64 `json:"memory"`
+	GroupName    string  `json:"groupName"`
+	Status       string  `json:"status"`
+	ExecuteCount int     `json:"executeCount"`
+	SuccessCount int     `json:"successCount"`
+	LogCount     int64   `json:"logCount"`
+	CreateTime   string  `json:"createTime"`
+	DeleteTime   string  `json:"deleteTime"`
+	PurgeTime    string  `json:"purgeTime"` // 到期后由数据库维护彻底删除
+}
+
+// ListRecycledTasks 获取回收站中的任务，按删除时间倒序
+func (s *TaskService) ListRecycledTasks() ([]RecycledTask, error) {
+	db := database.GetDB()
+	var tasks []models.OciCreateTask
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	logCounts := make(map[string]int64)
+	if len(tasks) > 0 {
+		ids := make([]string, len(tasks))
+		for i, task := range tasks {
+			ids[i] = task.ID
+		}
+		var rows []struct {
+			TaskID string
+			Count  int64
+		}
+		db.Model(&models.TaskLog{}).Select("task_id, COUNT(*) AS count").
+			Where("task_id IN ?", ids).Group("task_id").Scan(&rows)
+		for _, row := range rows {
+			logCounts[row.TaskID] = row.Count
+		}
+	}
+
+	list := make([]RecycledTask, len(tasks))
+	for i, task := range tasks {
+		deleteTime := task.DeletedAt.Time
+		list[i] = RecycledTask{
+			ID:           task.ID,
+			Username:     task.Username,
+			OciRegion:    task.OciRegion,
+			Architecture: task.Architecture,
+			Ocpus:        task.Ocpus,
+			Memory:       task.Memory,
+			GroupName:    task.GroupName,
+			Status:       task.Status,
+			ExecuteCount: task.ExecuteCount,
+			SuccessCount: task.SuccessCount,
+			LogCount:     logCounts[task.ID],
+			CreateTime:   task.CreateTime.Format("2006-01-02 15:04:05"),
+			DeleteTime:   deleteTime.Format("2006-01-02 15:04:05"),
+			PurgeTime:    deleteTime.AddDate(0, 0, TaskRecycleRetentionDays).Format("2006-01-02 15:04:05"),
+		}
+	}
+	return list, nil
+}
+
+// RestoreTask 从回收站恢复任务，删除前处于运行状态的任务恢复后重新调度
+func (s *TaskService) RestoreTask(taskID string) error {
+	db := database.GetDB()
+	result := db.Unscoped().Model(&models.OciCreateTask{}).
+		Where("id = ? AND deleted_at IS NOT NULL", taskID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("回收站中不存在该任务")
+	}
+
+	var task models.OciCreateTask
+	if err := db.Where("id = ?", taskID).First(&task).Error; err != nil {
+		return err
+	}
+	// 已达到停止条件的任务在首次执行时自动标记为过期
+	if task.Status == "running" {
+		s.scheduleTask(task)
+	}
+	return nil
+}
+
+// PurgeTask 彻底删除回收站中的任务及其执行日志
+func (s *TaskService) PurgeTask(taskID string) error {
+	db := database.GetDB()
+	var count int64
+	db.Unscoped().Model(&models.OciCreateTask{}).Where("id = ? AND deleted_at IS NOT NULL", taskID).Count(&count)
+	if count == 0 {
+		return fmt.Errorf("回收站中不存在该任务")
+	}
+
+	db.Where("task_id = ?", taskID).Delete(&models.TaskLog{})
+	return db.Unscoped().Where("id = ?", taskID).Delete(&models.OciCreateTask{}).Error
+}
+
+// purgeRecycledTasks 彻底删除回收站中早于 cutoff 删除的任务及其执行日志
+func purgeRecycledTasks(db *gorm.DB, cutoff time.Time) (int64, error) {
+	expired := db.Unscoped().Model(&models.OciCreateTask{}).Where("deleted_at < ?", cutoff).Select("id")
+	if err := db.Where("task_id IN (?)", expired).Delete(&models.TaskLog{}).Error; err != nil {
+		return 0, err
+	}
+	result := db.Unscoped().Where("deleted_at < ?", cutoff).Delete(&models.OciCreateTask{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// 未设置切换阈值时，连续容量不足多少次后切换到下一个区域
+const DefaultRegionFailover = 10
+
+// ListSubscribedRegions 获取租户已订阅的区域
+func (s *OCIService) ListSubscribedRegions(ctx context.Context, user *models.OciUser) ([]string, error) {
+	identityClient, err := s.GetIdentityClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := identityClient.ListRegionSubscriptions(ctx, identity.ListRegionSubscriptionsRequest{TenancyId: &user.OciTenantID})
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []string
+	for _, region := range resp.Items {
+		if region.RegionName != nil && region.Status == identity.RegionSubscriptionStatusReady {
+			regions = append(regions, *region.RegionName)
+		}
+	}
+	return regions, nil
+}
+
+// ParseRegionList 解析逗号分隔的区域列表，去除空白与重复项
+func ParseRegionList(value string) []string {
+	var regions []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		region := strings.TrimSpace(part)
+		if region == "" || seen[region] {
+			continue
+		}
+		seen[region] = true
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// ValidateFallbackRegions 校验备用区域均已订阅，返回规范化后的区域列表
+func (s *TaskService) ValidateFallbackRegions(user *models.OciUser, primary, value string) (string, error) {
+	var regions []string
+	for _, region := range ParseRegionList(value) {
+		if region != primary {
+			regions = append(regions, region)
+		}
+	}
+	if len(regions) == 0 {
+		return "", nil
+	}
+
+	subscribed, err := s.ociService.ListSubscribedRegions(context.Background(), user)
+	if err != nil {
+		return "", fmt.Errorf("获取已订阅区域失败: %w", err)
+	}
+	subscribedSet := make(map[string]bool, len(subscribed))
+	for _, region := range subscribed {
+		subscribedSet[region] = true
+	}
+	for _, region := range regions {
+		if !subscribedSet[region] {
+			return "", fmt.Errorf("区域 %s 未订阅", region)
+		}
+	}
+	return strings.Join(regions, ","), nil
+}
+
+// taskRegions 返回任务的区域轮换顺序，第一个为主区域
+func taskRegions(task *models.OciCreateTask) []string {
+	return append([]string{task.OciRegion}, ParseRegionList(task.FallbackRegions)...)
+}
+
+// CurrentTaskRegion 返回任务当前轮换到的区域
+func CurrentTaskRegion(task *models.OciCreateTask) string {
+	regions := taskRegions(task)
+	return regions[task.RegionIndex%len(regions)]
+}
+
+// updateTaskRegion 记录当前区域的容量不足次数，达到阈值后切换到下一个区域，返回是否发生切换
+func updateTaskRegion(task *models.OciCreateTask, err error) bool {
+	if !isCapacityError(err) {
+		task.RegionFailures = 0
+		return false
+	}
+
+	regions := taskRegions(task)
+	if len(regions) < 2 {
+		return false
+	}
+
+	task.RegionFailures++
+	failover := task.RegionFailover
+	if failover <= 0 {
+		failover = DefaultRegionFailover
+	}
+	if task.RegionFailures < failover {
+		return false
+	}
+
+	task.RegionIndex = (task.RegionIndex + 1) % len(regions)
+	task.RegionFailures = 0
+	task.ADIndex = 0
+	task.CurrentBackoff = 0
+	return true
+}
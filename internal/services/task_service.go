@@ -11,6 +11,7 @@ import (
 	"github.com/adiecho/oci-panel/internal/database"
 	"github.com/adiecho/oci-panel/internal/models"
 	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/core"
 )
 
 // extractOCIErrorMessage 从 OCI 错误中提取 Message 部分
@@ -29,19 +30,27 @@ func extractOCIErrorMessage(err error) string {
 }
 
 type TaskService struct {
-	ociService *OCIService
-	stopChan   chan struct{}
-	running    bool
-	mutex      sync.Mutex
-	taskTimers map[string]*time.Timer
-	timerMutex sync.RWMutex
+	ociService      *OCIService
+	telegramService *TelegramService
+	stopChan        chan struct{}
+	running         bool
+	mutex           sync.Mutex
+	taskTimers      map[string]*time.Timer
+	timerMutex      sync.RWMutex
+	limiter         *tenantLimiter // 按租户限制并发开机请求
+	regionSpacer    *regionSpacer  // 同一租户同一区域的开机请求保持最小间隔
+	nodeID          string         // 当前实例标识，用于任务租约
 }
 
-func NewTaskService(ociService *OCIService) *TaskService {
+func NewTaskService(ociService *OCIService, telegramService *TelegramService) *TaskService {
 	return &TaskService{
-		ociService: ociService,
-		stopChan:   make(chan struct{}),
-		taskTimers: make(map[string]*time.Timer),
+		ociService:      ociService,
+		telegramService: telegramService,
+		stopChan:        make(chan struct{}),
+		taskTimers:      make(map[string]*time.Timer),
+		limiter:         newTenantLimiter(DefaultTaskConcurrency),
+		regionSpacer:    newRegionSpacer(DefaultTaskRegionSpacingMs * time.Millisecond),
+		nodeID:          defaultTaskNodeID(),
 	}
 }
 
@@ -55,6 +64,9 @@ func (s *TaskService) Start() {
 	s.stopChan = make(chan struct{})
 	s.mutex.Unlock()
 
+	s.loadTaskConcurrency()
+	s.loadTaskRegionSpacing()
+	s.loadGuardRails()
 	go s.loadAndStartTasks()
 	log.Println("Task service started")
 }
@@ -76,6 +88,7 @@ func (s *TaskService) Stop() {
 	}
 	s.taskTimers = make(map[string]*time.Timer)
 	s.timerMutex.Unlock()
+	database.GetDB().Where("owner = ?", s.nodeID).Delete(&models.TaskLease{})
 
 	log.Println("Task service stopped")
 }
@@ -83,14 +96,31 @@ func (s *TaskService) Stop() {
 func (s *TaskService) loadAndStartTasks() {
 	db := database.GetDB()
 	var tasks []models.OciCreateTask
-	db.Where("status = ?", "running").Find(&tasks)
+	// 高优先级任务先调度
+	db.Where("status = ?", "running").Order("priority DESC").Find(&tasks)
 
+	now := time.Now()
 	for _, task := range tasks {
+		// 沿用重启前保存的下次执行时间，避免每次重启都重新计算间隔；已错过的立即执行
+		if task.NextExecuteTime != nil {
+			nextExecuteTime := *task.NextExecuteTime
+			if nextExecuteTime.Before(now) {
+				nextExecuteTime = now
+			}
+			s.scheduleTaskAt(task, nextTaskWindowTime(&task, nextExecuteTime))
+			continue
+		}
 		s.scheduleTask(task)
 	}
 }
 
 func (s *TaskService) scheduleTask(task models.OciCreateTask) {
+	// 下次执行时间落在允许的时间段之外时，推迟到下一个时间段开始
+	s.scheduleTaskAt(task, nextTaskWindowTime(&task, time.Now().Add(taskDelay(&task))))
+}
+
+// scheduleTaskAt 在指定时间执行任务，并保存下次执行时间以便重启后恢复
+func (s *TaskService) scheduleTaskAt(task models.OciCreateTask, nextExecuteTime time.Time) {
 	s.timerMutex.Lock()
 	defer s.timerMutex.Unlock()
 
@@ -98,12 +128,14 @@ func (s *TaskService) scheduleTask(task models.OciCreateTask) {
 		existingTimer.Stop()
 	}
 
-	interval := time.Duration(task.Interval) * time.Second
-	if interval < 10*time.Second {
-		interval = 10 * time.Second
+	delay := time.Until(nextExecuteTime)
+	if delay < 0 {
+		delay = 0
 	}
+	database.GetDB().Model(&models.OciCreateTask{}).Where("id = ?", task.ID).Update("next_execute_time", nextExecuteTime)
+	s.extendTaskLease(task.ID, nextExecuteTime.Add(taskLeaseTTL))
 
-	timer := time.AfterFunc(interval, func() {
+	timer := time.AfterFunc(delay, func() {
 		s.executeTask(task.ID)
 	})
 	s.taskTimers[task.ID] = timer
@@ -121,58 +153,170 @@ func (s *TaskService) executeTask(taskID string) {
 		return
 	}
 
+	// 多个实例共享数据库时，只有持有租约的实例执行任务
+	if ok, expireAt := s.acquireTaskLease(taskID); !ok {
+		s.watchTaskLease(taskID, expireAt)
+		return
+	}
+
+	if condition := taskStopCondition(&task, time.Now()); condition != "" {
+		s.expireTask(&task, condition)
+		return
+	}
+
+	// 时间段可能在定时器注册后被修改，不在时间段内时跳过本次执行
+	if !taskInWindow(&task, time.Now()) {
+		s.scheduleTask(task)
+		return
+	}
+
 	var user models.OciUser
 	if err := db.Where("id = ?", task.UserID).First(&user).Error; err != nil {
 		s.logTaskExecution(taskID, "error", fmt.Sprintf("配置不存在: %v", err))
 		return
 	}
 
+	if task.ProbeOnly {
+		s.executeProbe(&task, &user)
+		return
+	}
+
 	var sshKey models.SSHKey
 	if err := db.Where("id = ?", task.SSHKeyID).First(&sshKey).Error; err != nil {
 		s.logTaskExecution(taskID, "error", fmt.Sprintf("SSH密钥不存在: %v", err))
 		return
 	}
 
+	// 镜像ID与区域相关，切换到备用区域后按操作系统重新选择镜像
+	region := CurrentTaskRegion(&task)
+	imageId := task.ImageId
+	if region != task.OciRegion {
+		imageId = ""
+	}
+
+	// 同一租户的开机请求排队执行，等待期间任务可能已被停止
+	s.limiter.acquire(task.UserID, task.Priority)
+	var current models.OciCreateTask
+	if err := db.Select("status").Where("id = ?", taskID).First(&current).Error; err != nil || current.Status != "running" {
+		s.limiter.release(task.UserID)
+		return
+	}
+	s.regionSpacer.wait(task.UserID, region)
+
 	ctx := context.Background()
-	err := s.ociService.CreateInstance(ctx, &user, task.OciRegion, task.Architecture, task.OperationSystem,
-		task.Ocpus, task.Memory, task.Disk, task.BootVolumeVpu, sshKey.PublicKey, task.ImageId)
+	ad, instance, err := s.ociService.CreateInstance(ctx, &user, region, task.Architecture, task.OperationSystem,
+		task.Ocpus, task.Memory, task.Disk, task.BootVolumeVpu, sshKey.PublicKey, task.UserData, imageId, task.CompartmentID, task.ADIndex)
+	s.limiter.release(task.UserID)
 
 	now := time.Now()
 	task.ExecuteCount++
 	task.LastExecuteTime = &now
+	updateTaskBackoff(&task, err)
+	if task.RotateAD {
+		task.ADIndex++
+	}
+	regionSwitched := updateTaskRegion(&task, err)
 
 	if err != nil {
 		errMsg := extractOCIErrorMessage(err)
+		if task.FallbackRegions != "" {
+			errMsg = fmt.Sprintf("[%s] %s", region, errMsg)
+		}
+		if regionSwitched {
+			errMsg = fmt.Sprintf("%s（切换到区域 %s）", errMsg, CurrentTaskRegion(&task))
+		} else if task.CurrentBackoff > 0 {
+			errMsg = fmt.Sprintf("%s（退避 %d 秒）", errMsg, task.CurrentBackoff)
+		}
 		task.LastMessage = errMsg
-		s.logTaskExecution(taskID, "error", errMsg)
+		s.logTaskAttempt(taskID, "error", errMsg, ad)
 	} else {
-		task.SuccessCount++
-		task.LastMessage = "创建成功"
-		task.Status = "completed"
-		s.logTaskExecution(taskID, "success", "实例创建成功")
+		s.recordTaskCreated(&task, user, region, ad, instance)
 	}
 
-	db.Save(&task)
+	// 排队与创建期间任务可能已被停止或修改，只更新本次执行改变的字段
+	s.saveTaskAttempt(&task, "running")
+	if err == nil {
+		s.notifyTaskCreated(&task, region)
+		s.startPostCreateHooks(task, user, region, ad, instance)
+	}
 
 	if task.Status == "running" {
-		s.scheduleTask(task)
+		// 按最新的任务状态与设置安排下次执行，已停止或已删除的任务不再执行
+		var latest models.OciCreateTask
+		if err := db.Where("id = ?", taskID).First(&latest).Error; err != nil || latest.Status != "running" {
+			return
+		}
+		if condition := taskStopCondition(&latest, time.Now()); condition != "" {
+			s.expireTask(&latest, condition)
+			return
+		}
+		s.scheduleTask(latest)
 	} else {
 		s.removeTaskTimer(taskID)
+		db.Model(&task).Update("next_execute_time", nil)
 	}
 }
 
+// recordTaskCreated 记录一次成功创建，达到目标数量时标记任务完成
+func (s *TaskService) recordTaskCreated(task *models.OciCreateTask, user models.OciUser, region, ad string, instance *core.Instance) {
+	task.SuccessCount++
+	progress := fmt.Sprintf("%d/%d", task.SuccessCount, taskTargetCount(task))
+	task.LastMessage = fmt.Sprintf("创建成功 %s", progress)
+	if ad != "" {
+		task.LastMessage = fmt.Sprintf("%s（%s）", task.LastMessage, ad)
+	}
+	if region != task.OciRegion {
+		task.LastMessage = fmt.Sprintf("%s [%s]", task.LastMessage, region)
+	}
+	if task.SuccessCount >= taskTargetCount(task) {
+		task.Status = "completed"
+	}
+	s.logTaskAttempt(task.ID, "success", fmt.Sprintf("实例创建成功 %s", progress), ad)
+	s.trackLaunchAttempt(task.ID, user, region, instance)
+}
+
+// saveTaskAttempt 只更新一次执行改变的字段，状态与执行前不同时才写入状态
+func (s *TaskService) saveTaskAttempt(task *models.OciCreateTask, previousStatus string) error {
+	columns := []string{"execute_count", "last_execute_time", "current_backoff", "ad_index",
+		"region_index", "region_failures", "success_count", "last_message"}
+	if task.Status != previousStatus {
+		columns = append(columns, "status")
+	}
+	return database.GetDB().Model(task).Select(columns).Updates(task).Error
+}
+
 func (s *TaskService) logTaskExecution(taskID, status, message string) {
+	s.logTaskAttempt(taskID, status, message, "")
+}
+
+// logTaskAttempt 记录一次执行日志，并标记本次尝试的可用域
+func (s *TaskService) logTaskAttempt(taskID, status, message, availabilityDomain string) {
 	db := database.GetDB()
 	logEntry := models.TaskLog{
-		ID:          uuid.New().String(),
-		TaskID:      taskID,
-		Status:      status,
-		Message:     message,
-		ExecuteTime: time.Now(),
+		ID:                 uuid.New().String(),
+		TaskID:             taskID,
+		Status:             status,
+		Message:            message,
+		AvailabilityDomain: availabilityDomain,
+		ExecuteTime:        time.Now(),
 	}
 	db.Create(&logEntry)
 }
 
+// GetTaskADStats 按可用域汇总任务的尝试、失败与成功次数
+func (s *TaskService) GetTaskADStats(taskID string) ([]models.TaskADStat, error) {
+	var stats []models.TaskADStat
+	err := database.GetDB().Model(&models.TaskLog{}).
+		Select("availability_domain, COUNT(*) AS attempts, "+
+			"SUM(CASE WHEN status = 'error' THEN 1 ELSE 0 END) AS failures, "+
+			"SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) AS successes").
+		Where("task_id = ? AND availability_domain <> ''", taskID).
+		Group("availability_domain").
+		Order("availability_domain").
+		Scan(&stats).Error
+	return stats, err
+}
+
 func (s *TaskService) removeTaskTimer(taskID string) {
 	s.timerMutex.Lock()
 	defer s.timerMutex.Unlock()
@@ -181,6 +325,19 @@ func (s *TaskService) removeTaskTimer(taskID string) {
 		timer.Stop()
 		delete(s.taskTimers, taskID)
 	}
+	s.releaseTaskLease(taskID)
+}
+
+// scheduledTaskIDs 返回当前已注册定时器的任务 ID
+func (s *TaskService) scheduledTaskIDs() map[string]bool {
+	s.timerMutex.RLock()
+	defer s.timerMutex.RUnlock()
+
+	ids := make(map[string]bool, len(s.taskTimers))
+	for id := range s.taskTimers {
+		ids[id] = true
+	}
+	return ids
 }
 
 func (s *TaskService) AddTask(task *models.OciCreateTask) error {
@@ -202,7 +359,12 @@ func (s *TaskService) StartTask(taskID string) error {
 		return err
 	}
 
+	if taskStopCondition(&task, time.Now()) != "" {
+		return fmt.Errorf("任务已达到停止条件，无法启动")
+	}
+
 	task.Status = "running"
+	task.CurrentBackoff = 0
 	if err := db.Save(&task).Error; err != nil {
 		return err
 	}
@@ -213,7 +375,10 @@ func (s *TaskService) StartTask(taskID string) error {
 
 func (s *TaskService) StopTask(taskID string) error {
 	db := database.GetDB()
-	if err := db.Model(&models.OciCreateTask{}).Where("id = ?", taskID).Update("status", "stopped").Error; err != nil {
+	if err := db.Model(&models.OciCreateTask{}).Where("id = ?", taskID).Updates(map[string]interface{}{
+		"status":            "stopped",
+		"next_execute_time": nil,
+	}).Error; err != nil {
 		return err
 	}
 
@@ -221,11 +386,12 @@ func (s *TaskService) StopTask(taskID string) error {
 	return nil
 }
 
+// DeleteTask 将任务移入回收站，执行日志保留到彻底删除时
 func (s *TaskService) DeleteTask(taskID string) error {
 	s.removeTaskTimer(taskID)
 
 	db := database.GetDB()
-	db.Where("task_id = ?", taskID).Delete(&models.TaskLog{})
+	db.Where("task_id = ?", taskID).Delete(&models.TaskLease{})
 	return db.Where("id = ?", taskID).Delete(&models.OciCreateTask{}).Error
 }
 
@@ -245,6 +411,55 @@ func (s *TaskService) GetTaskLogs(taskID string, page, pageSize int) ([]models.T
 	return logs, total, nil
 }
 
+// UpdateLogRetention 设置任务执行日志的保留天数，由数据库维护任务定期清理
+func (s *TaskService) UpdateLogRetention(taskID string, days int) error {
+	result := database.GetDB().Model(&models.OciCreateTask{}).Where("id = ?", taskID).Update("log_retention_days", days)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("任务不存在")
+	}
+	return nil
+}
+
+// UpdateTaskPriority 设置任务优先级
+func (s *TaskService) UpdateTaskPriority(taskID string, priority int) error {
+	result := database.GetDB().Model(&models.OciCreateTask{}).Where("id = ?", taskID).Update("priority", priority)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("任务不存在")
+	}
+	return nil
+}
+
+// GroupTaskIDs 获取分组内的任务ID
+func (s *TaskService) GroupTaskIDs(groupName string) ([]string, error) {
+	var ids []string
+	err := database.GetDB().Model(&models.OciCreateTask{}).Where("group_name = ?", groupName).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// SetTaskGroup 批量设置任务所属分组，groupName 为空时移出分组
+func (s *TaskService) SetTaskGroup(taskIDs []string, groupName string) error {
+	return database.GetDB().Model(&models.OciCreateTask{}).Where("id IN ?", taskIDs).Update("group_name", groupName).Error
+}
+
+// GetTaskGroupStats 按分组统计任务状态，未分组的任务不计入
+func GetTaskGroupStats() ([]models.TaskGroupStat, error) {
+	var stats []models.TaskGroupStat
+	err := database.GetDB().Model(&models.OciCreateTask{}).
+		Select("group_name, COUNT(*) AS total, " +
+			"SUM(CASE WHEN status = 'running' THEN 1 ELSE 0 END) AS running, " +
+			"SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END) AS completed").
+		Where("group_name <> ''").
+		Group("group_name").Order("group_name").
+		Scan(&stats).Error
+	return stats, err
+}
+
 func (s *TaskService) ClearTaskLogs(taskID string) error {
 	db := database.GetDB()
 	return db.Where("task_id = ?", taskID).Delete(&models.TaskLog{}).Error
@@ -264,33 +479,65 @@ func (s *TaskService) ExecuteTaskOnce(taskID string) error {
 		return fmt.Errorf("配置不存在: %w", err)
 	}
 
+	// 探测模式只记录一次容量情况，任务状态与定时调度保持不变
+	if task.ProbeOnly {
+		probes, err := s.probeTask(&task, &user)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		task.ExecuteCount++
+		task.LastExecuteTime = &now
+		task.LastMessage = fmt.Sprintf("[%s] %s", CurrentTaskRegion(&task), summarizeProbes(probes))
+		s.logTaskExecution(taskID, "probe", task.LastMessage)
+		return s.saveTaskAttempt(&task, task.Status)
+	}
+
 	var sshKey models.SSHKey
 	if err := db.Where("id = ?", task.SSHKeyID).First(&sshKey).Error; err != nil {
 		s.logTaskExecution(taskID, "error", fmt.Sprintf("SSH密钥不存在: %v", err))
 		return fmt.Errorf("SSH密钥不存在: %w", err)
 	}
 
+	// 与定时执行相同：使用备用区域切换后的当前区域，切换后按操作系统重新选择镜像
+	region := CurrentTaskRegion(&task)
+	imageId := task.ImageId
+	if region != task.OciRegion {
+		imageId = ""
+	}
+
 	ctx := context.Background()
-	err := s.ociService.CreateInstance(ctx, &user, task.OciRegion, task.Architecture, task.OperationSystem,
-		task.Ocpus, task.Memory, task.Disk, task.BootVolumeVpu, sshKey.PublicKey, task.ImageId)
+	s.limiter.acquire(task.UserID, task.Priority)
+	s.regionSpacer.wait(task.UserID, region)
+	ad, instance, err := s.ociService.CreateInstance(ctx, &user, region, task.Architecture, task.OperationSystem,
+		task.Ocpus, task.Memory, task.Disk, task.BootVolumeVpu, sshKey.PublicKey, task.UserData, imageId, task.CompartmentID, task.ADIndex)
+	s.limiter.release(task.UserID)
 
 	now := time.Now()
+	previousStatus := task.Status
 	task.ExecuteCount++
 	task.LastExecuteTime = &now
 
 	if err != nil {
 		errMsg := extractOCIErrorMessage(err)
+		if task.FallbackRegions != "" {
+			errMsg = fmt.Sprintf("[%s] %s", region, errMsg)
+		}
 		task.LastMessage = errMsg
 		task.Status = "error"
-		s.logTaskExecution(taskID, "error", errMsg)
-		db.Save(&task)
+		s.logTaskAttempt(taskID, "error", errMsg, ad)
+		s.saveTaskAttempt(&task, previousStatus)
 		return fmt.Errorf("%s", errMsg)
 	}
 
-	task.SuccessCount++
-	task.Status = "completed"
-	task.LastMessage = "创建成功"
-	s.logTaskExecution(taskID, "success", "创建成功")
-	db.Save(&task)
+	// 与定时执行相同：按创建数量计算进度，达到目标时才完成，并发送通知、执行创建后动作
+	s.recordTaskCreated(&task, user, region, ad, instance)
+	s.saveTaskAttempt(&task, previousStatus)
+	s.notifyTaskCreated(&task, region)
+	s.startPostCreateHooks(task, user, region, ad, instance)
+	if task.Status == "completed" {
+		s.removeTaskTimer(taskID)
+		db.Model(&task).Update("next_execute_time", nil)
+	}
 	return nil
 }
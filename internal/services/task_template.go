@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// taskOperationSystems 镜像操作系统名称与任务操作系统的对应关系
+var taskOperationSystems = map[string]string{
+	"Canonical Ubuntu": "Ubuntu",
+	"CentOS":           "CentOS",
+	"Oracle Linux":     "Oracle Linux",
+}
+
+// TaskTemplateFromInstance 以现有实例的规格生成一个未保存的开机任务，用于快速补开被回收的机器
+// 实例已终止时引导卷与镜像可能已不存在，此时对应字段留空，由创建任务时的默认值补齐
+func (s *TaskService) TaskTemplateFromInstance(user *models.OciUser, instanceId string) (*models.OciCreateTask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	instance, err := s.ociService.GetInstance(ctx, user, instanceId)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+
+	task := &models.OciCreateTask{
+		UserID:    user.ID,
+		Username:  user.Username,
+		OciRegion: user.OciRegion,
+	}
+	switch shape := *instance.Shape; shape {
+	case taskShape("ARM"):
+		task.Architecture = "ARM"
+	case taskShape("AMD"):
+		task.Architecture = "AMD"
+	default:
+		return nil, fmt.Errorf("开机任务暂不支持实例规格 %s", shape)
+	}
+	if instance.ShapeConfig != nil {
+		if instance.ShapeConfig.Ocpus != nil {
+			task.Ocpus = float64(*instance.ShapeConfig.Ocpus)
+		}
+		if instance.ShapeConfig.MemoryInGBs != nil {
+			task.Memory = float64(*instance.ShapeConfig.MemoryInGBs)
+		}
+	}
+	if instance.CompartmentId != nil && *instance.CompartmentId != user.OciTenantID {
+		task.CompartmentID = *instance.CompartmentId
+	}
+
+	if bootVolume, err := s.ociService.GetBootVolumeByInstanceId(user, instanceId); err == nil {
+		if bootVolume.SizeInGBs != nil {
+			task.Disk = int(*bootVolume.SizeInGBs)
+		}
+		if bootVolume.VpusPerGB != nil {
+			task.BootVolumeVpu = *bootVolume.VpusPerGB
+		}
+	}
+
+	imageId := ""
+	if source, ok := instance.SourceDetails.(core.InstanceSourceViaImageDetails); ok && source.ImageId != nil {
+		imageId = *source.ImageId
+	} else if instance.ImageId != nil {
+		imageId = *instance.ImageId
+	}
+	if imageId != "" {
+		if computeClient, err := s.ociService.GetComputeClient(user); err == nil {
+			if resp, err := computeClient.GetImage(ctx, core.GetImageRequest{ImageId: &imageId}); err == nil &&
+				resp.LifecycleState == core.ImageLifecycleStateAvailable {
+				task.ImageId = imageId
+				if resp.OperatingSystem != nil {
+					task.OperationSystem = taskOperationSystems[*resp.OperatingSystem]
+				}
+			}
+		}
+	}
+
+	task.SSHKeyID = matchInstanceSSHKey(instance.Metadata)
+	if userData, ok := instance.Metadata["user_data"]; ok {
+		task.UserData = userData
+	}
+	return task, nil
+}
+
+// matchInstanceSSHKey 在已保存的 SSH 密钥中查找与实例公钥一致的密钥，未找到时返回空
+func matchInstanceSSHKey(metadata map[string]string) string {
+	authorized := strings.Fields(metadata["ssh_authorized_keys"])
+	if len(authorized) < 2 {
+		return ""
+	}
+
+	var keys []models.SSHKey
+	if err := database.GetDB().Find(&keys).Error; err != nil {
+		return ""
+	}
+	for _, key := range keys {
+		// 只比较类型与密钥内容，忽略末尾的注释
+		fields := strings.Fields(key.PublicKey)
+		if len(fields) >= 2 && fields[0] == authorized[0] && fields[1] == authorized[1] {
+			return key.ID
+		}
+	}
+	return ""
+}
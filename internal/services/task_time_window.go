@@ -0,0 +1,131 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+// taskWindow 每日允许执行的时间段，按当天分钟数表示，End 小于 Start 时表示跨越午夜
+type taskWindow struct {
+	Start int
+	End   int
+}
+
+// contains 判断某个当天分钟数是否落在时间段内（含开始，不含结束）
+func (w taskWindow) contains(minute int) bool {
+	if w.Start < w.End {
+		return minute >= w.Start && minute < w.End
+	}
+	return minute >= w.Start || minute < w.End
+}
+
+// parseTaskWindows 解析执行时间段，格式如 "02:00-07:00,22:00-01:00"，使用服务器本地时间
+func parseTaskWindows(value string) ([]taskWindow, error) {
+	var windows []taskWindow
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		startText, endText, ok := strings.Cut(item, "-")
+		if !ok {
+			return nil, fmt.Errorf("时间段格式无效: %s", item)
+		}
+		start, err := parseWindowMinute(startText)
+		if err != nil {
+			return nil, fmt.Errorf("时间段格式无效: %s", item)
+		}
+		end, err := parseWindowMinute(endText)
+		if err != nil {
+			return nil, fmt.Errorf("时间段格式无效: %s", item)
+		}
+		if start == end {
+			return nil, fmt.Errorf("时间段开始与结束不能相同: %s", item)
+		}
+		windows = append(windows, taskWindow{Start: start, End: end})
+	}
+	return windows, nil
+}
+
+func parseWindowMinute(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// NormalizeTaskWindows 校验执行时间段并格式化为统一写法
+func NormalizeTaskWindows(value string) (string, error) {
+	windows, err := parseTaskWindows(value)
+	if err != nil {
+		return "", err
+	}
+	items := make([]string, 0, len(windows))
+	for _, w := range windows {
+		items = append(items, fmt.Sprintf("%02d:%02d-%02d:%02d", w.Start/60, w.Start%60, w.End/60, w.End%60))
+	}
+	return strings.Join(items, ","), nil
+}
+
+// nextTaskWindowTime 返回不早于 t 的最近可执行时间，未设置时间段时直接返回 t
+func nextTaskWindowTime(task *models.OciCreateTask, t time.Time) time.Time {
+	windows, err := parseTaskWindows(task.ExecuteWindows)
+	if err != nil || len(windows) == 0 {
+		return t
+	}
+
+	t = t.In(time.Local)
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range windows {
+		if w.contains(minute) {
+			return t
+		}
+	}
+
+	// 取今天或明天最早到来的时间段开始时间
+	dayStart := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	var next time.Time
+	for _, w := range windows {
+		start := dayStart.Add(time.Duration(w.Start) * time.Minute)
+		if !start.After(t) {
+			start = dayStart.AddDate(0, 0, 1).Add(time.Duration(w.Start) * time.Minute)
+		}
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return next
+}
+
+// taskInWindow 判断任务当前是否处于允许执行的时间段内
+func taskInWindow(task *models.OciCreateTask, now time.Time) bool {
+	return !nextTaskWindowTime(task, now).After(now)
+}
+
+// UpdateExecuteWindows 设置任务的执行时间段，为空表示全天执行，运行中的任务按新时间段重新调度
+func (s *TaskService) UpdateExecuteWindows(taskID, windows string) error {
+	normalized, err := NormalizeTaskWindows(windows)
+	if err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+	var task models.OciCreateTask
+	if err := db.Where("id = ?", taskID).First(&task).Error; err != nil {
+		return fmt.Errorf("任务不存在")
+	}
+	task.ExecuteWindows = normalized
+	if err := db.Model(&task).Update("execute_windows", normalized).Error; err != nil {
+		return err
+	}
+
+	if task.Status == "running" {
+		s.scheduleTask(task)
+	}
+	return nil
+}
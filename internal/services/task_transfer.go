@@ -0,0 +1,282 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+)
+
+// TaskExportVersion 任务导出格式版本
+const TaskExportVersion = 1
+
+// TaskExport 开机任务导出文件
+type TaskExport struct {
+	Version    int              `json:"version"`
+	ExportTime string           `json:"exportTime"`
+	Tasks      []TaskExportItem `json:"tasks"`
+}
+
+// TaskExportItem 导出的任务定义，配置与 SSH 密钥按名称/租户/公钥引用，不包含私钥等敏感信息
+type TaskExportItem struct {
+	ConfigName       string  `json:"configName"`
+	TenantID         string  `json:"tenantId"`
+	OciRegion        string  `json:"ociRegion"`
+	Ocpus            float64 `json:"ocpus"`
+	Memory           float64 `json:"memory"`
+	Disk             int     `json:"disk"`
+	BootVolumeVpu    int64   `json:"bootVolumeVpu"`
+	Architecture     string  `json:"architecture"`
+	OperationSystem  string  `json:"operationSystem"`
+	ImageId          string  `json:"imageId"`
+	CompartmentID    string  `json:"compartmentId"`
+	SSHKeyName       string  `json:"sshKeyName"`
+	SSHPublicKey     string  `json:"sshPublicKey"`
+	Interval         int     `json:"interval"`
+	BackoffMin       int     `json:"backoffMin"`
+	BackoffMax       int     `json:"backoffMax"`
+	CreateNumbers    int     `json:"createNumbers"`
+	SuccessCount     int     `json:"successCount"`
+	RotateAD         bool    `json:"rotateAd"`
+	FallbackRegions  string  `json:"fallbackRegions"`
+	RegionFailover   int     `json:"regionFailover"`
+	MaxExecuteCount  int     `json:"maxExecuteCount"`
+	ExpireAt         string  `json:"expireAt"`
+	LogRetentionDays int     `json:"logRetentionDays"`
+	GroupName        string  `json:"groupName"`
+	Priority         int     `json:"priority"`
+	ExecuteWindows   string  `json:"executeWindows"`
+	WebhookURL       string  `json:"webhookUrl"`
+	UserData         string  `json:"userData"`
+	ReservedPublicIP string  `json:"reservedPublicIp"`
+	ProbeOnly        bool    `json:"probeOnly"`
+	Status           string  `json:"status"`
+}
+
+// TaskImportResult 任务导入结果
+type TaskImportResult struct {
+	Imported int      `json:"imported"`
+	Skipped  []string `json:"skipped"`
+}
+
+// ExportTasks 导出所有开机任务的定义
+func (s *TaskService) ExportTasks() (*TaskExport, error) {
+	db := database.GetDB()
+
+	var tasks []models.OciCreateTask
+	if err := db.Order("create_time ASC").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]models.OciUser)
+	sshKeys := make(map[string]models.SSHKey)
+	export := &TaskExport{
+		Version:    TaskExportVersion,
+		ExportTime: time.Now().Format("2006-01-02 15:04:05"),
+		Tasks:      make([]TaskExportItem, 0, len(tasks)),
+	}
+	for _, task := range tasks {
+		user, ok := users[task.UserID]
+		if !ok {
+			db.Where("id = ?", task.UserID).First(&user)
+			users[task.UserID] = user
+		}
+		sshKey, ok := sshKeys[task.SSHKeyID]
+		if !ok {
+			db.Where("id = ?", task.SSHKeyID).First(&sshKey)
+			sshKeys[task.SSHKeyID] = sshKey
+		}
+
+		item := TaskExportItem{
+			ConfigName:       task.Username,
+			TenantID:         user.OciTenantID,
+			OciRegion:        task.OciRegion,
+			Ocpus:            task.Ocpus,
+			Memory:           task.Memory,
+			Disk:             task.Disk,
+			BootVolumeVpu:    task.BootVolumeVpu,
+			Architecture:     task.Architecture,
+			OperationSystem:  task.OperationSystem,
+			ImageId:          task.ImageId,
+			CompartmentID:    task.CompartmentID,
+			SSHKeyName:       sshKey.Name,
+			SSHPublicKey:     sshKey.PublicKey,
+			Interval:         task.Interval,
+			BackoffMin:       task.BackoffMin,
+			BackoffMax:       task.BackoffMax,
+			CreateNumbers:    task.CreateNumbers,
+			SuccessCount:     task.SuccessCount,
+			RotateAD:         task.RotateAD,
+			FallbackRegions:  task.FallbackRegions,
+			RegionFailover:   task.RegionFailover,
+			MaxExecuteCount:  task.MaxExecuteCount,
+			LogRetentionDays: task.LogRetentionDays,
+			GroupName:        task.GroupName,
+			Priority:         task.Priority,
+			ExecuteWindows:   task.ExecuteWindows,
+			WebhookURL:       task.WebhookURL,
+			UserData:         task.UserData,
+			ReservedPublicIP: task.ReservedPublicIP,
+			ProbeOnly:        task.ProbeOnly,
+			Status:           task.Status,
+		}
+		if task.ExpireAt != nil {
+			item.ExpireAt = task.ExpireAt.Format("2006-01-02 15:04:05")
+		}
+		export.Tasks = append(export.Tasks, item)
+	}
+	return export, nil
+}
+
+// ImportTasks 导入任务定义，按租户ID与配置名匹配本地配置，按公钥匹配或创建 SSH 密钥
+// start 为 true 时导出前处于运行中的任务导入后立即开始执行，否则一律导入为已停止
+func (s *TaskService) ImportTasks(data *TaskExport, start bool) (*TaskImportResult, error) {
+	if data.Version != TaskExportVersion {
+		return nil, fmt.Errorf("不支持的导出文件版本: %d", data.Version)
+	}
+
+	result := &TaskImportResult{Skipped: []string{}}
+	for i, item := range data.Tasks {
+		label := fmt.Sprintf("#%d %s/%s", i+1, item.ConfigName, item.OciRegion)
+
+		user, err := matchImportConfig(item)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+
+		sshKeyID, err := matchImportSSHKey(item, user)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+
+		executeWindows, err := NormalizeTaskWindows(item.ExecuteWindows)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+
+		userData, err := NormalizeUserData(item.UserData)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+
+		var expireAt *time.Time
+		if item.ExpireAt != "" {
+			if t, err := time.ParseInLocation("2006-01-02 15:04:05", item.ExpireAt, time.Local); err == nil {
+				expireAt = &t
+			}
+		}
+
+		// 已结束的任务保留原状态，便于查看历史
+		status := "stopped"
+		switch {
+		case start && item.Status == "running":
+			status = "running"
+		case item.Status == "completed" || item.Status == "expired":
+			status = item.Status
+		}
+		if item.Interval < minTaskInterval {
+			item.Interval = 60
+		}
+
+		task := &models.OciCreateTask{
+			ID:               uuid.New().String(),
+			UserID:           user.ID,
+			Username:         user.Username,
+			OciRegion:        item.OciRegion,
+			Ocpus:            item.Ocpus,
+			Memory:           item.Memory,
+			Disk:             item.Disk,
+			BootVolumeVpu:    item.BootVolumeVpu,
+			Architecture:     item.Architecture,
+			OperationSystem:  item.OperationSystem,
+			ImageId:          item.ImageId,
+			CompartmentID:    item.CompartmentID,
+			SSHKeyID:         sshKeyID,
+			Interval:         item.Interval,
+			BackoffMin:       item.BackoffMin,
+			BackoffMax:       item.BackoffMax,
+			CreateNumbers:    item.CreateNumbers,
+			SuccessCount:     item.SuccessCount,
+			RotateAD:         item.RotateAD,
+			FallbackRegions:  item.FallbackRegions,
+			RegionFailover:   item.RegionFailover,
+			MaxExecuteCount:  item.MaxExecuteCount,
+			ExpireAt:         expireAt,
+			LogRetentionDays: item.LogRetentionDays,
+			GroupName:        item.GroupName,
+			Priority:         item.Priority,
+			ExecuteWindows:   executeWindows,
+			WebhookURL:       item.WebhookURL,
+			UserData:         userData,
+			ReservedPublicIP: item.ReservedPublicIP,
+			ProbeOnly:        item.ProbeOnly,
+			Status:           status,
+			CreateTime:       time.Now(),
+		}
+		if err := s.AddTask(task); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+		result.Imported++
+	}
+	return result, nil
+}
+
+// matchImportConfig 按租户ID与配置名查找本地配置，配置名不一致时回退到同租户的第一个配置
+func matchImportConfig(item TaskExportItem) (*models.OciUser, error) {
+	db := database.GetDB()
+	var users []models.OciUser
+	if item.TenantID != "" {
+		db.Where("oci_tenant_id = ?", item.TenantID).Find(&users)
+	} else {
+		db.Where("username = ?", item.ConfigName).Find(&users)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("未找到对应的配置")
+	}
+	for i := range users {
+		if users[i].Username == item.ConfigName {
+			return &users[i], nil
+		}
+	}
+	return &users[0], nil
+}
+
+// matchImportSSHKey 按公钥查找本地 SSH 密钥，不存在时以公钥创建独立密钥，未导出公钥时使用配置的默认密钥
+func matchImportSSHKey(item TaskExportItem, user *models.OciUser) (string, error) {
+	db := database.GetDB()
+	publicKey := strings.TrimSpace(item.SSHPublicKey)
+	if publicKey == "" {
+		if user.DefaultSSHKeyID == "" {
+			return "", fmt.Errorf("缺少SSH公钥且配置未设置默认SSH密钥")
+		}
+		return user.DefaultSSHKeyID, nil
+	}
+
+	var sshKey models.SSHKey
+	if err := db.Where("public_key = ?", publicKey).First(&sshKey).Error; err == nil {
+		return sshKey.ID, nil
+	}
+
+	name := item.SSHKeyName
+	if name == "" {
+		name = "imported-" + time.Now().Format("20060102150405")
+	}
+	sshKey = models.SSHKey{
+		ID:        uuid.New().String(),
+		Name:      name,
+		PublicKey: publicKey,
+		KeyType:   "standalone",
+	}
+	if err := db.Create(&sshKey).Error; err != nil {
+		return "", err
+	}
+	return sshKey.ID, nil
+}
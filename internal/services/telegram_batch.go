@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	tgCallbackStopAll        = "stop_all"
+	tgCallbackStopAllConfirm = "stop_all_confirm"
+
+	// 结果中最多列出的失败实例数
+	tgStopAllFailedItems = 10
+)
+
+// handleBatchCallback 处理一键停止所有实例的按钮回调，返回是否已处理
+func (s *TelegramService) handleBatchCallback(chatID string, messageID int, data string) bool {
+	switch data {
+	case tgCallbackStopAll:
+		text, keyboard := s.getStopAllConfirm()
+		s.editMessage(chatID, messageID, text, keyboard)
+	case tgCallbackStopAllConfirm:
+		s.editMessage(chatID, messageID, s.stopAllInstances(), s.getMainKeyboard())
+	default:
+		return false
+	}
+	return true
+}
+
+// configUsernames 配置 ID 到配置名的映射
+func configUsernames() map[string]string {
+	var users []models.OciUser
+	database.GetDB().Select("id", "username").Find(&users)
+	names := make(map[string]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Username
+	}
+	return names
+}
+
+// getStopAllConfirm 列出各配置运行中的实例数，确认后才执行停止
+func (s *TelegramService) getStopAllConfirm() (string, *InlineKeyboardMarkup) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	targets, failed, err := s.ociService.ListRunningInstanceTargets(ctx)
+	if err != nil {
+		return s.t("stop_all_title") + "\n\n" + s.t("get_config_failed"), s.getMainKeyboard()
+	}
+
+	var lines []string
+	if len(failed) > 0 {
+		lines = append(lines, s.t("stop_all_list_failed", html.EscapeString(strings.Join(failed, ", "))))
+	}
+	if len(targets) == 0 {
+		lines = append([]string{s.t("stop_all_none")}, lines...)
+		return s.t("stop_all_title") + "\n\n" + strings.Join(lines, "\n\n"), s.getMainKeyboard()
+	}
+
+	names := configUsernames()
+	counts := make(map[string]int)
+	for _, target := range targets {
+		counts[names[target.UserId]]++
+	}
+	configs := make([]string, 0, len(counts))
+	for name := range counts {
+		configs = append(configs, name)
+	}
+	sort.Strings(configs)
+	items := make([]string, len(configs))
+	for i, name := range configs {
+		items[i] = s.t("stop_all_item", html.EscapeString(name), counts[name])
+	}
+	lines = append([]string{s.t("stop_all_confirm", len(targets), strings.Join(items, "\n"))}, lines...)
+
+	keyboard := &InlineKeyboardMarkup{
+		InlineKeyboard: [][]InlineKeyboardButton{
+			{{Text: s.t("btn_stop_all_confirm"), CallbackData: tgCallbackStopAllConfirm}},
+			{{Text: s.t("btn_back"), CallbackData: "back_main"}},
+		},
+	}
+	return s.t("stop_all_title") + "\n\n" + strings.Join(lines, "\n\n"), keyboard
+}
+
+// stopAllInstances 停止所有配置中运行中的实例，执行时重新获取实例列表
+func (s *TelegramService) stopAllInstances() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	targets, _, err := s.ociService.ListRunningInstanceTargets(ctx)
+	if err != nil {
+		return s.t("stop_all_title") + "\n\n" + s.t("get_config_failed")
+	}
+	if len(targets) == 0 {
+		return s.t("stop_all_title") + "\n\n" + s.t("stop_all_none")
+	}
+
+	// 超过单次上限时分批执行
+	report := &BatchInstanceReport{Action: BatchActionStop}
+	for start := 0; start < len(targets); start += MaxBatchInstances {
+		batch, err := s.ociService.BatchInstanceAction(ctx, BatchActionStop, targets[start:min(start+MaxBatchInstances, len(targets))])
+		if err != nil {
+			return s.t("stop_all_title") + "\n\n❌ " + html.EscapeString(err.Error())
+		}
+		report.Total += batch.Total
+		report.Succeeded += batch.Succeeded
+		report.Failed += batch.Failed
+		report.Results = append(report.Results, batch.Results...)
+	}
+
+	text := s.t("stop_all_title") + "\n\n" + s.t("stop_all_result", report.Succeeded, report.Failed)
+	names := configUsernames()
+	var failedLines []string
+	for _, result := range report.Results {
+		if result.Status != "failed" {
+			continue
+		}
+		if len(failedLines) == tgStopAllFailedItems {
+			failedLines = append(failedLines, "...")
+			break
+		}
+		failedLines = append(failedLines, fmt.Sprintf("❌ %s: %s", html.EscapeString(names[result.UserId]), html.EscapeString(result.Error)))
+	}
+	if len(failedLines) > 0 {
+		text += "\n\n" + strings.Join(failedLines, "\n")
+	}
+	return text
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	tgCallbackConfigSummary = "cfg_sum:"
+	tgCallbackRegionLatency = "cfg_lat:"
+)
+
+// getConfigListKeyboard 配置列表的键盘，每个配置一个查看概览的按钮
+func (s *TelegramService) getConfigListKeyboard() *InlineKeyboardMarkup {
+	var users []models.OciUser
+	if err := database.GetDB().Find(&users).Error; err != nil || len(users) == 0 {
+		return s.getMainKeyboard()
+	}
+
+	// 收藏的配置排在最前
+	favorites := favoriteIDs("", FavoriteTypeConfig)
+	sortFavoritesFirst(users, func(u models.OciUser) bool { return favorites[u.ID] })
+
+	var rows [][]InlineKeyboardButton
+	for _, user := range users {
+		icon := "🔑 "
+		if favorites[user.ID] {
+			icon = "⭐ "
+		}
+		rows = append(rows, []InlineKeyboardButton{
+			{Text: icon + user.Username, CallbackData: tgCallbackConfigSummary + user.ID},
+		})
+	}
+	rows = append(rows, []InlineKeyboardButton{{Text: s.t("btn_back"), CallbackData: "back_main"}})
+	return &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// handleConfigSummaryCallback 处理配置概览的按钮回调，返回是否已处理
+func (s *TelegramService) handleConfigSummaryCallback(chatID string, messageID int, data string) bool {
+	switch {
+	case strings.HasPrefix(data, tgCallbackConfigSummary):
+		configID := strings.TrimPrefix(data, tgCallbackConfigSummary)
+		keyboard := &InlineKeyboardMarkup{
+			InlineKeyboard: [][]InlineKeyboardButton{
+				{
+					{Text: s.t("btn_refresh"), CallbackData: data},
+					{Text: s.t("btn_region_latency"), CallbackData: tgCallbackRegionLatency + configID},
+				},
+				{{Text: s.t("btn_back"), CallbackData: "config_list"}},
+			},
+		}
+		s.editMessage(chatID, messageID, s.getConfigSummary(configID), keyboard)
+	case strings.HasPrefix(data, tgCallbackRegionLatency):
+		configID := strings.TrimPrefix(data, tgCallbackRegionLatency)
+		keyboard := &InlineKeyboardMarkup{
+			InlineKeyboard: [][]InlineKeyboardButton{
+				{{Text: s.t("btn_refresh"), CallbackData: data}},
+				{{Text: s.t("btn_back"), CallbackData: tgCallbackConfigSummary + configID}},
+			},
+		}
+		s.editMessage(chatID, messageID, s.getRegionLatency(configID), keyboard)
+	default:
+		return false
+	}
+	return true
+}
+
+// getRegionLatency 生成配置各已订阅区域的延迟文本，主区域以 🏠 标记
+func (s *TelegramService) getRegionLatency(configID string) string {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", configID).First(&user).Error; err != nil {
+		return s.t("traffic_alert_config_not_found", configID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	results, err := s.ociService.ProbeRegionLatency(ctx, &user)
+	if err != nil {
+		return s.t("region_latency_title") + "\n\n" + s.t("region_latency_failed", err.Error())
+	}
+
+	formatMs := func(ms int64) string {
+		if ms < 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%dms", ms)
+	}
+	lines := make([]string, 0, len(results))
+	for _, result := range results {
+		mark := "🌐"
+		if result.IsHome {
+			mark = "🏠"
+		}
+		line := s.t("region_latency_item", mark, result.Region, formatMs(result.ConnectMs), formatMs(result.APIMs))
+		if result.Error != "" {
+			line += "\n   ⚠️ " + result.Error
+		}
+		lines = append(lines, line)
+	}
+
+	return s.t("region_latency_title") + "\n\n" +
+		s.t("time_line", time.Now().Format("2006-01-02 15:04:05")) + "\n\n" +
+		strings.Join(lines, "\n")
+}
+
+// getConfigSummary 生成单个配置的资源概览文本
+func (s *TelegramService) getConfigSummary(configID string) string {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", configID).First(&user).Error; err != nil {
+		return s.t("traffic_alert_config_not_found", configID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	summary, err := s.ociService.GetConfigSummary(ctx, &user)
+	if err != nil {
+		return s.t("config_summary_title") + "\n\n" + s.t("fetch_failed", user.Username)
+	}
+
+	states := make([]string, 0, len(summary.States))
+	for state, count := range summary.States {
+		states = append(states, fmt.Sprintf("%s: %d", state, count))
+	}
+	sort.Strings(states)
+	stateText := "-"
+	if len(states) > 0 {
+		stateText = strings.Join(states, ", ")
+	}
+
+	text := s.t("config_summary_title") + "\n\n" +
+		s.t("time_line", time.Now().Format("2006-01-02 15:04:05")) + "\n\n" +
+		s.t("config_summary",
+			summary.Username, summary.Region,
+			summary.InstanceCount, stateText,
+			summary.TotalOcpus, summary.TotalMemoryGBs,
+			summary.A1Ocpus.Used, summary.A1Ocpus.Limit,
+			summary.A1MemoryGBs.Used, summary.A1MemoryGBs.Limit,
+			summary.MicroInstances.Used, summary.MicroInstances.Limit,
+			summary.StorageGBs.Used, summary.StorageGBs.Limit,
+			FormatBytes(summary.InboundTraffic), FormatBytes(summary.OutboundTraffic))
+	if len(summary.Warnings) > 0 {
+		text += "\n\n⚠️ " + strings.Join(summary.Warnings, "\n⚠️ ")
+	}
+	return text
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// TelegramDeliveryStatus 最近一次发送消息的结果，用于排查 Bot 不再推送的原因
+type TelegramDeliveryStatus struct {
+	LastSendTime        string `json:"lastSendTime"`
+	LastSuccess         bool   `json:"lastSuccess"`
+	LastSuccessTime     string `json:"lastSuccessTime"`
+	LastErrorTime       string `json:"lastErrorTime"`
+	LastError           string `json:"lastError"`
+	LastHTTPStatus      int    `json:"lastHttpStatus"`      // Bot API 返回的 HTTP 状态码，请求未送达时为 0
+	LastDescription     string `json:"lastDescription"`     // Bot API 返回的错误描述，如 Forbidden: bot was blocked by the user
+	ConsecutiveFailures int    `json:"consecutiveFailures"` // 连续发送失败次数
+}
+
+// telegramDelivery 记录发送结果，进程重启后清空
+type telegramDelivery struct {
+	mu     sync.Mutex
+	status TelegramDeliveryStatus
+}
+
+// record 记录一次发送的结果
+func (d *telegramDelivery) record(httpStatus int, description string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now().Format("2006-01-02 15:04:05")
+	d.status.LastSendTime = now
+	d.status.LastHTTPStatus = httpStatus
+	d.status.LastDescription = description
+	if err != nil {
+		d.status.LastSuccess = false
+		d.status.LastErrorTime = now
+		d.status.LastError = err.Error()
+		d.status.ConsecutiveFailures++
+		return
+	}
+	d.status.LastSuccess = true
+	d.status.LastSuccessTime = now
+	d.status.ConsecutiveFailures = 0
+}
+
+func (d *telegramDelivery) snapshot() TelegramDeliveryStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status
+}
+
+// GetDeliveryStatus 获取最近一次发送消息的结果
+func (s *TelegramService) GetDeliveryStatus() TelegramDeliveryStatus {
+	return s.delivery.snapshot()
+}
@@ -0,0 +1,282 @@
+package services
+
+import "fmt"
+
+const (
+	TgLangZh = "zh"
+	TgLangEn = "en"
+)
+
+// tgMessages Bot 文案，按语言区分；缺失的键回退到中文
+var tgMessages = map[string]map[string]string{
+	TgLangZh: {
+		"no_permission":                  "❌ 无权限操作此机器人🤖\n项目地址: https://github.com/adiecho/oci-panel",
+		"rate_limited":                   "⏳ 操作过于频繁，请稍后再试",
+		"choose_action":                  "请选择需要执行的操作：",
+		"btn_check_alive":                "🔍 一键测活",
+		"btn_task_details":               "📋 任务详情",
+		"btn_instance_stats":             "🖥️ 实例统计",
+		"btn_config_list":                "📂 配置列表",
+		"btn_version_info":               "ℹ️ 版本信息",
+		"btn_traffic_stats":              "📊 流量统计",
+		"btn_star":                       "⭐ 开源地址（欢迎Star）",
+		"btn_cancel":                     "❌ 关闭窗口",
+		"get_config_failed":              "❌ 获取配置失败",
+		"get_task_failed":                "❌ 获取任务失败",
+		"no_config":                      "暂无配置",
+		"fetch_failed":                   "❌ %s: 获取失败",
+		"time_line":                      "🕐 时间：%s",
+		"alive_title":                    "【API测活结果】",
+		"alive_summary":                  "✅ 有效配置数：%d\n❌ 失效配置数：%d\n🔑 总配置数：%d",
+		"alive_invalid":                  "⚠️ 失效配置：\n%s",
+		"task_title":                     "【任务详情】",
+		"task_none":                      "🛎 正在执行的开机任务：无",
+		"task_list":                      "🛎 正在执行的开机任务：\n%s",
+		"task_item":                      "[%s] [%s] [%.0f核/%.0fGB/%dGB] [%d/%d台] [%s] [执行%d次]",
+		"task_group_list":                "📁 任务分组：\n%s",
+		"task_group_item":                "[%s] 运行中 %d / 已完成 %d / 共 %d",
+		"instance_title":                 "【实例统计】",
+		"instance_summary":               "📊 总实例数：%d\n🟢 运行中：%d",
+		"instance_item":                  "🔑 %s [%s]: %d台 (运行中: %d)",
+		"config_title":                   "【配置列表】",
+		"config_total":                   "🔑 总配置数：%d",
+		"config_item":                    "%d. %s\n   区域: %s\n   租户: %s",
+		"config_summary_title":           "【配置概览】",
+		"config_summary":                 "🔑 配置名：【%s】\n🌏 主区域：【%s】\n🖥️ 实例：%d 台 (%s)\n⚙️ 总算力：%.0f 核 / %.0fGB\n🆓 A1 OCPU：%.0f / %.0f\n🆓 A1 内存：%.0fGB / %.0fGB\n🆓 Micro 实例：%.0f / %.0f 台\n💾 块存储：%.0fGB / %.0fGB\n⬇️ 本月入站流量：%s\n⬆️ 本月出站流量：%s",
+		"region_latency_title":           "【区域延迟】",
+		"region_latency_item":            "%s %s：TCP %s / API %s",
+		"region_latency_failed":          "❌ 测量失败：%s",
+		"btn_region_latency":             "📶 区域延迟",
+		"version_info":                   "【版本信息】\n\n📦 应用名称：OCI Panel\n🏷️ 当前版本：v1.0.0\n🔧 后端框架：Gin (Go)\n🎨 前端框架：Vue 3 + Vite\n💾 数据库：SQLite\n\n🕐 查询时间：%s",
+		"traffic_title":                  "【流量统计】",
+		"traffic_item":                   "🔑 配置名：【%s】\n🌏 主区域：【%s】\n🖥️ 实例数量：【%d】台\n⬇️ 本月入站流量：%s\n⬆️ 本月出站流量：%s",
+		"btn_traffic_alert":              "🔔 流量告警",
+		"btn_traffic_alert_off":          "🔕 关闭告警",
+		"btn_back":                       "⬅️ 返回",
+		"btn_refresh":                    "🔄 刷新",
+		"btn_idle_risk":                  "💤 回收风险",
+		"btn_activity":                   "📰 最近动态",
+		"btn_stop_all":                   "⏹️ 全部停止",
+		"btn_stop_all_confirm":           "⚠️ 确认停止",
+		"stop_all_title":                 "【停止所有实例】",
+		"stop_all_none":                  "没有运行中的实例",
+		"stop_all_confirm":               "将停止 %d 个运行中的实例：\n%s\n\n确认执行？",
+		"stop_all_item":                  "🔑 %s：%d 台",
+		"stop_all_list_failed":           "⚠️ 以下配置获取实例失败，不会停止：%s",
+		"stop_all_result":                "✅ 已停止 %d 个，❌ 失败 %d 个",
+		"favorite_instances":             "⭐ 收藏的实例：\n%s",
+		"favorite_instance_item":         "• %s（%s）：%s",
+		"activity_title":                 "【最近动态】",
+		"activity_none":                  "暂无动态",
+		"activity_failed":                "❌ 获取动态失败",
+		"activity_item":                  "%s %s %s\n   %s",
+		"activity_type_audit":            "🤖",
+		"activity_type_task":             "🚀",
+		"activity_type_job":              "⚙️",
+		"activity_type_security":         "🛡️",
+		"activity_type_event":            "📣",
+		"idle_risk_title":                "【闲置回收风险】",
+		"idle_risk_config":               "🔑 配置名：【%s】 🌏 %s",
+		"idle_risk_none":                 "   无运行中的 Always Free 实例",
+		"idle_risk_item":                 "   %s %s (%d)\n   CPU %.1f%%，网络 %.1f%%%s",
+		"idle_risk_unknown_item":         "   %s %s\n   %s",
+		"idle_risk_high":                 "🔴 高",
+		"idle_risk_medium":               "🟡 中",
+		"idle_risk_low":                  "🟢 低",
+		"idle_risk_unknown":              "⚪ 未知",
+		"idle_risk_hint":                 "按最近 7 天的 95 百分位利用率评估，CPU、网络与内存（仅 A1）均低于 20% 时可能被回收",
+		"traffic_alert_title":            "【流量告警】",
+		"traffic_alert_usage":            "自定义：/traffic_alert 配置名 上限TB 阈值\n例如：/traffic_alert myoci 10 80,95\n关闭：/traffic_alert myoci off",
+		"traffic_alert_none":             "未设置告警",
+		"traffic_alert_rule":             "出站上限 %s，告警阈值 %s",
+		"traffic_alert_saved":            "✅ 已保存：%s",
+		"traffic_alert_disabled":         "🔕 已关闭流量告警",
+		"traffic_alert_save_failed":      "❌ 保存失败：%s",
+		"traffic_alert_config_not_found": "❌ 未找到配置：%s",
+		"traffic_alert_notify_title":     "⚠️ 流量告警",
+		"traffic_alert_notify":           "🔑 配置：%s\n🌏 区域：%s\n⬆️ 本月出站流量：%s / %s (%.1f%%)\n已超过告警阈值 %d%%",
+		"security_audit_notify_title":    "🛡️ 安全巡检",
+		"security_audit_notify":          "发现 %d 条新的风险入站规则：",
+		"security_audit_item":            "🔑 %s [%s]\n   %s（%s，端口 %s）",
+		"security_audit_more":            "…… 另有 %d 条，请在面板中查看",
+		"power_schedule_failed_title":    "⏰ 定时开关机失败",
+		"power_schedule_failed":          "🔑 配置：%s\n💻 实例：%s\n⚙️ 操作：%s（%s）\n❌ %s",
+		"power_action_start":             "开机",
+		"power_action_stop":              "关机",
+		"bandwidth_500mbps_title":        "🚀 500Mbps",
+		"bandwidth_500mbps_enable":       "开启",
+		"bandwidth_500mbps_disable":      "关闭",
+		"bandwidth_500mbps_enabled":      "🔑 配置：%s\n💻 实例：%s\n✅ 已开启500Mbps，新公网 IP：%s",
+		"bandwidth_500mbps_disabled":     "🔑 配置：%s\n💻 实例：%s\n✅ 已关闭500Mbps，需重新分配公网 IP",
+		"bandwidth_500mbps_failed":       "🔑 配置：%s\n💻 实例：%s\n❌ %s500Mbps失败：%s",
+		"ip_rotation_success_title":      "🔄 公网 IP 已更换",
+		"ip_rotation_success":            "🔑 配置：%s\n💻 实例：%s\n📤 原 IP：%s\n📥 新 IP：%s",
+		"ip_rotation_failed_title":       "🔄 公网 IP 定时更换失败",
+		"ip_rotation_failed":             "🔑 配置：%s\n💻 实例：%s\n❌ %s",
+		"backup_schedule_failed_title":   "💾 定时备份失败",
+		"backup_schedule_failed":         "🔑 配置：%s\n💻 实例：%s\n❌ %s",
+		"traffic_quota_warning_title":    "⚠️ 流量配额即将用尽",
+		"traffic_quota_warning":          "🔑 配置：%s\n⬆️ 本月出站流量：%s / %s (%.1f%%)\n达到 100%% 时将执行：%s",
+		"traffic_quota_enforced_title":   "⛔ 流量配额已用尽",
+		"traffic_quota_enforced":         "🔑 配置：%s\n⬆️ 本月出站流量：%s / %s (%.1f%%)\n已执行：%s\n%s",
+		"idle_keepalive_title":           "💤 实例可能被闲置回收",
+		"idle_keepalive_notify":          "🔑 配置：%s\n以下实例近 7 天利用率低于 Oracle 回收阈值 (20%%)：\n%s",
+		"idle_keepalive_item":            "💻 %s：CPU %.1f%%，网络 %.1f%%%s",
+		"idle_keepalive_memory":          "，内存 %.1f%%",
+		"idle_keepalive_started":         "\n   ▶️ 已启动保活负载",
+		"idle_keepalive_start_failed":    "\n   ❌ 启动保活负载失败：%s",
+		"task_expired_notify_title":      "⏹ 开机任务已自动停止",
+		"task_expired_notify":            "🔑 配置：%s\n🌏 区域：%s\n⚙️ 规格：%.0f核/%.0fGB/%dGB [%s]\n🔁 已执行：%d 次\n📦 已创建：%d/%d 台\n⏹ %s",
+		"task_expired_deadline":          "已到达截止时间 %s",
+		"task_created_notify_title":      "🎉 开机任务创建实例成功",
+		"task_created_notify":            "🔑 配置：%s\n🌏 区域：%s\n⚙️ 规格：%.0f核/%.0fGB/%dGB [%s]\n🔁 已执行：%d 次\n📦 进度：%d/%d 台",
+		"task_expired_max_count":         "已达到最大执行次数 %d",
+	},
+	TgLangEn: {
+		"no_permission":                  "❌ You are not allowed to use this bot 🤖\nProject: https://github.com/adiecho/oci-panel",
+		"rate_limited":                   "⏳ Too many requests, please try again later",
+		"choose_action":                  "Please choose an action:",
+		"btn_check_alive":                "🔍 Check Alive",
+		"btn_task_details":               "📋 Tasks",
+		"btn_instance_stats":             "🖥️ Instances",
+		"btn_config_list":                "📂 Configs",
+		"btn_version_info":               "ℹ️ Version",
+		"btn_traffic_stats":              "📊 Traffic",
+		"btn_star":                       "⭐ Source Code (Star welcome)",
+		"btn_cancel":                     "❌ Close",
+		"get_config_failed":              "❌ Failed to load configs",
+		"get_task_failed":                "❌ Failed to load tasks",
+		"no_config":                      "No configs yet",
+		"fetch_failed":                   "❌ %s: failed to fetch",
+		"time_line":                      "🕐 Time: %s",
+		"alive_title":                    "【API Check Result】",
+		"alive_summary":                  "✅ Valid configs: %d\n❌ Invalid configs: %d\n🔑 Total configs: %d",
+		"alive_invalid":                  "⚠️ Invalid configs:\n%s",
+		"task_title":                     "【Task Details】",
+		"task_none":                      "🛎 Running creation tasks: none",
+		"task_list":                      "🛎 Running creation tasks:\n%s",
+		"task_item":                      "[%s] [%s] [%.0f OCPU/%.0fGB/%dGB] [%d/%d] [%s] [%d runs]",
+		"task_group_list":                "📁 Task groups:\n%s",
+		"task_group_item":                "[%s] running %d / completed %d / total %d",
+		"instance_title":                 "【Instance Stats】",
+		"instance_summary":               "📊 Total instances: %d\n🟢 Running: %d",
+		"instance_item":                  "🔑 %s [%s]: %d (running: %d)",
+		"config_title":                   "【Config List】",
+		"config_total":                   "🔑 Total configs: %d",
+		"config_item":                    "%d. %s\n   Region: %s\n   Tenant: %s",
+		"config_summary_title":           "【Config Summary】",
+		"config_summary":                 "🔑 Config: 【%s】\n🌏 Home region: 【%s】\n🖥️ Instances: %d (%s)\n⚙️ Total compute: %.0f OCPU / %.0fGB\n🆓 A1 OCPU: %.0f / %.0f\n🆓 A1 memory: %.0fGB / %.0fGB\n🆓 Micro instances: %.0f / %.0f\n💾 Block storage: %.0fGB / %.0fGB\n⬇️ Inbound this month: %s\n⬆️ Outbound this month: %s",
+		"region_latency_title":           "【Region Latency】",
+		"region_latency_item":            "%s %s: TCP %s / API %s",
+		"region_latency_failed":          "❌ Measurement failed: %s",
+		"btn_region_latency":             "📶 Region Latency",
+		"version_info":                   "【Version Info】\n\n📦 App: OCI Panel\n🏷️ Version: v1.0.0\n🔧 Backend: Gin (Go)\n🎨 Frontend: Vue 3 + Vite\n💾 Database: SQLite\n\n🕐 Queried at: %s",
+		"traffic_title":                  "【Traffic Stats】",
+		"traffic_item":                   "🔑 Config: 【%s】\n🌏 Home region: 【%s】\n🖥️ Instances: 【%d】\n⬇️ Inbound this month: %s\n⬆️ Outbound this month: %s",
+		"btn_traffic_alert":              "🔔 Traffic Alerts",
+		"btn_traffic_alert_off":          "🔕 Disable Alerts",
+		"btn_back":                       "⬅️ Back",
+		"btn_refresh":                    "🔄 Refresh",
+		"btn_idle_risk":                  "💤 Reclaim Risk",
+		"btn_activity":                   "📰 Recent Activity",
+		"btn_stop_all":                   "⏹️ Stop All",
+		"btn_stop_all_confirm":           "⚠️ Confirm Stop",
+		"stop_all_title":                 "【Stop All Instances】",
+		"stop_all_none":                  "No running instances",
+		"stop_all_confirm":               "%d running instances will be stopped:\n%s\n\nProceed?",
+		"stop_all_item":                  "🔑 %s: %d",
+		"stop_all_list_failed":           "⚠️ Failed to list instances for these configs, they will not be stopped: %s",
+		"stop_all_result":                "✅ Stopped %d, ❌ failed %d",
+		"favorite_instances":             "⭐ Favorite instances:\n%s",
+		"favorite_instance_item":         "• %s (%s): %s",
+		"activity_title":                 "【Recent Activity】",
+		"activity_none":                  "No recent activity",
+		"activity_failed":                "❌ Failed to load activity",
+		"activity_item":                  "%s %s %s\n   %s",
+		"activity_type_audit":            "🤖",
+		"activity_type_task":             "🚀",
+		"activity_type_job":              "⚙️",
+		"activity_type_security":         "🛡️",
+		"activity_type_event":            "📣",
+		"idle_risk_title":                "【Idle Reclamation Risk】",
+		"idle_risk_config":               "🔑 Config: 【%s】 🌏 %s",
+		"idle_risk_none":                 "   no running Always Free instances",
+		"idle_risk_item":                 "   %s %s (%d)\n   CPU %.1f%%, network %.1f%%%s",
+		"idle_risk_unknown_item":         "   %s %s\n   %s",
+		"idle_risk_high":                 "🔴 high",
+		"idle_risk_medium":               "🟡 medium",
+		"idle_risk_low":                  "🟢 low",
+		"idle_risk_unknown":              "⚪ unknown",
+		"idle_risk_hint":                 "Based on 7-day p95 utilization; instances may be reclaimed when CPU, network and memory (A1 only) are all below 20%",
+		"traffic_alert_title":            "【Traffic Alerts】",
+		"traffic_alert_usage":            "Custom: /traffic_alert config limitTB thresholds\nExample: /traffic_alert myoci 10 80,95\nDisable: /traffic_alert myoci off",
+		"traffic_alert_none":             "no alert configured",
+		"traffic_alert_rule":             "egress limit %s, alert at %s",
+		"traffic_alert_saved":            "✅ Saved: %s",
+		"traffic_alert_disabled":         "🔕 Traffic alerts disabled",
+		"traffic_alert_save_failed":      "❌ Failed to save: %s",
+		"traffic_alert_config_not_found": "❌ Config not found: %s",
+		"traffic_alert_notify_title":     "⚠️ Traffic Alert",
+		"traffic_alert_notify":           "🔑 Config: %s\n🌏 Region: %s\n⬆️ Outbound this month: %s / %s (%.1f%%)\nExceeded the %d%% threshold",
+		"security_audit_notify_title":    "🛡️ Security Audit",
+		"security_audit_notify":          "Found %d new risky ingress rules:",
+		"security_audit_item":            "🔑 %s [%s]\n   %s (%s, ports %s)",
+		"security_audit_more":            "... and %d more, see the panel for details",
+		"power_schedule_failed_title":    "⏰ Power Schedule Failed",
+		"power_schedule_failed":          "🔑 Config: %s\n💻 Instance: %s\n⚙️ Action: %s (%s)\n❌ %s",
+		"power_action_start":             "Start",
+		"power_action_stop":              "Stop",
+		"bandwidth_500mbps_title":        "🚀 500Mbps",
+		"bandwidth_500mbps_enable":       "Enable",
+		"bandwidth_500mbps_disable":      "Disable",
+		"bandwidth_500mbps_enabled":      "🔑 Config: %s\n💻 Instance: %s\n✅ 500Mbps enabled, new public IP: %s",
+		"bandwidth_500mbps_disabled":     "🔑 Config: %s\n💻 Instance: %s\n✅ 500Mbps disabled, reassign a public IP to regain access",
+		"bandwidth_500mbps_failed":       "🔑 Config: %s\n💻 Instance: %s\n❌ %s 500Mbps failed: %s",
+		"ip_rotation_success_title":      "🔄 Public IP Rotated",
+		"ip_rotation_success":            "🔑 Config: %s\n💻 Instance: %s\n📤 Old IP: %s\n📥 New IP: %s",
+		"ip_rotation_failed_title":       "🔄 Public IP Rotation Failed",
+		"ip_rotation_failed":             "🔑 Config: %s\n💻 Instance: %s\n❌ %s",
+		"backup_schedule_failed_title":   "💾 Scheduled Backup Failed",
+		"backup_schedule_failed":         "🔑 Config: %s\n💻 Instance: %s\n❌ %s",
+		"traffic_quota_warning_title":    "⚠️ Traffic Quota Almost Used",
+		"traffic_quota_warning":          "🔑 Config: %s\n⬆️ Outbound this month: %s / %s (%.1f%%)\nAt 100%% the panel will: %s",
+		"traffic_quota_enforced_title":   "⛔ Traffic Quota Exhausted",
+		"traffic_quota_enforced":         "🔑 Config: %s\n⬆️ Outbound this month: %s / %s (%.1f%%)\nAction taken: %s\n%s",
+		"idle_keepalive_title":           "💤 Instances At Risk Of Idle Reclamation",
+		"idle_keepalive_notify":          "🔑 Config: %s\nThese instances are below Oracle's reclamation threshold (20%%) over the last 7 days:\n%s",
+		"idle_keepalive_item":            "💻 %s: CPU %.1f%%, network %.1f%%%s",
+		"idle_keepalive_memory":          ", memory %.1f%%",
+		"idle_keepalive_started":         "\n   ▶️ Keep-alive workload started",
+		"idle_keepalive_start_failed":    "\n   ❌ Failed to start keep-alive workload: %s",
+		"task_expired_notify_title":      "⏹ Creation Task Stopped",
+		"task_expired_notify":            "🔑 Config: %s\n🌏 Region: %s\n⚙️ Shape: %.0f OCPU/%.0fGB/%dGB [%s]\n🔁 Runs: %d\n📦 Created: %d/%d\n⏹ %s",
+		"task_expired_deadline":          "deadline %s reached",
+		"task_created_notify_title":      "🎉 Instance Created",
+		"task_created_notify":            "🔑 Config: %s\n🌏 Region: %s\n⚙️ Shape: %.0f OCPU/%.0fGB/%dGB [%s]\n🔁 Runs: %d\n📦 Progress: %d/%d",
+		"task_expired_max_count":         "max attempts (%d) reached",
+	},
+}
+
+// normalizeTgLanguage 规范化语言设置，未知语言回退到中文
+func normalizeTgLanguage(lang string) string {
+	if _, ok := tgMessages[lang]; ok {
+		return lang
+	}
+	return TgLangZh
+}
+
+// t 按当前语言获取 Bot 文案
+func (s *TelegramService) t(key string, args ...interface{}) string {
+	s.mu.RLock()
+	lang := s.language
+	s.mu.RUnlock()
+
+	text, ok := tgMessages[lang][key]
+	if !ok {
+		text = tgMessages[TgLangZh][key]
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	// 容器镜像中可能没有时区数据，内置一份保证时区名称可用
+	_ "time/tzdata"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	SettingNotifyQuietWindows  = "notify_quiet_windows"
+	SettingNotifyQuietTimezone = "notify_quiet_timezone"
+	SettingNotifyQuietMode     = "notify_quiet_mode"
+
+	// 免打扰期间静默发送（不响铃）或不发送
+	NotifyQuietModeSilent = "silent"
+	NotifyQuietModeMute   = "mute"
+)
+
+// NotifyQuietHours 通知免打扰设置，与开机任务的执行时间段相互独立，重要告警不受影响
+type NotifyQuietHours struct {
+	Windows  string `json:"windows"`  // 免打扰时间段，如 "23:00-07:00"，为空时关闭
+	Timezone string `json:"timezone"` // IANA 时区名称，如 Asia/Shanghai，为空时使用服务器时区
+	Mode     string `json:"mode"`     // silent / mute，默认 silent
+}
+
+// GetNotifyQuietHours 获取通知免打扰设置
+func GetNotifyQuietHours() NotifyQuietHours {
+	var settings []models.SysSetting
+	database.GetDB().Where("key IN ?", []string{SettingNotifyQuietWindows, SettingNotifyQuietTimezone, SettingNotifyQuietMode}).
+		Find(&settings)
+	quiet := NotifyQuietHours{Mode: NotifyQuietModeSilent}
+	for _, setting := range settings {
+		switch setting.Key {
+		case SettingNotifyQuietWindows:
+			quiet.Windows = setting.Value
+		case SettingNotifyQuietTimezone:
+			quiet.Timezone = setting.Value
+		case SettingNotifyQuietMode:
+			if setting.Value == NotifyQuietModeMute {
+				quiet.Mode = NotifyQuietModeMute
+			}
+		}
+	}
+	return quiet
+}
+
+// SetNotifyQuietHours 校验并保存通知免打扰设置，返回格式化后的设置
+func SetNotifyQuietHours(quiet NotifyQuietHours) (NotifyQuietHours, error) {
+	windows, err := NormalizeTaskWindows(quiet.Windows)
+	if err != nil {
+		return quiet, err
+	}
+	quiet.Windows = windows
+	if quiet.Timezone != "" {
+		if _, err := time.LoadLocation(quiet.Timezone); err != nil {
+			return quiet, fmt.Errorf("无效的时区: %s", quiet.Timezone)
+		}
+	}
+	switch quiet.Mode {
+	case "":
+		quiet.Mode = NotifyQuietModeSilent
+	case NotifyQuietModeSilent, NotifyQuietModeMute:
+	default:
+		return quiet, fmt.Errorf("免打扰方式只能为 silent 或 mute")
+	}
+
+	if err := saveSetting(SettingNotifyQuietWindows, quiet.Windows); err != nil {
+		return quiet, err
+	}
+	if err := saveSetting(SettingNotifyQuietTimezone, quiet.Timezone); err != nil {
+		return quiet, err
+	}
+	if err := saveSetting(SettingNotifyQuietMode, quiet.Mode); err != nil {
+		return quiet, err
+	}
+	return quiet, nil
+}
+
+// active 判断 t 是否落在免打扰时间段内，按设置的时区计算当天分钟数
+func (q NotifyQuietHours) active(t time.Time) bool {
+	windows, err := parseTaskWindows(q.Windows)
+	if err != nil || len(windows) == 0 {
+		return false
+	}
+	loc := time.Local
+	if q.Timezone != "" {
+		if l, err := time.LoadLocation(q.Timezone); err == nil {
+			loc = l
+		}
+	}
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range windows {
+		if w.contains(minute) {
+			return true
+		}
+	}
+	return false
+}
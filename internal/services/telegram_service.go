@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"log"
 	"net/http"
 	"net/url"
@@ -13,24 +14,66 @@ import (
 
 	"github.com/adiecho/oci-panel/internal/database"
 	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/common"
 )
 
 const (
-	TelegramAPIURL = "https://api.telegram.org/bot%s/%s"
+	TelegramAPIURL         = "%s/bot%s/%s"
+	TelegramDefaultAPIBase = "https://api.telegram.org"
 
 	SettingKeyTgBotToken = "tg_bot_token"
 	SettingKeyTgChatID   = "tg_chat_id"
 	SettingKeyTgEnabled  = "tg_enabled"
+	SettingKeyTgProxyURL = "tg_proxy_url"
+	SettingKeyTgAPIBase  = "tg_api_base"
+	SettingKeyTgLanguage = "tg_language"
+
+	// 同一用户两次按钮回调之间的最小间隔，避免频繁调用 OCI API
+	TgCallbackRateLimit = 3 * time.Second
+	// 同一未授权用户两次记录审计与回复之间的最小间隔，避免刷屏写满审计日志
+	TgDeniedAuditInterval = time.Minute
+
+	TgAuditResultSuccess     = "success"
+	TgAuditResultFailed      = "failed"
+	TgAuditResultIgnored     = "ignored"
+	TgAuditResultDenied      = "denied"
+	TgAuditResultRateLimited = "rate_limited"
+
+	tgCallbackRefresh = "refresh:"
+
+	// 最近动态展示的条数与每条消息的最大字符数
+	tgActivityItems        = 10
+	tgActivityMessageRunes = 80
 )
 
+// tgRefreshableActions 带刷新按钮的统计消息
+var tgRefreshableActions = map[string]bool{
+	"task_details":   true,
+	"instance_stats": true,
+	"traffic_stats":  true,
+	"idle_risk":      true,
+	"activity":       true,
+}
+
 type TelegramService struct {
 	botToken   string
 	chatID     string
 	enabled    bool
+	proxyURL   string
+	apiBase    string
+	language   string
+	httpClient *http.Client
 	ociService *OCIService
 	mu         sync.RWMutex
 	stopChan   chan struct{}
 	running    bool
+
+	lastCallback   map[int64]time.Time
+	lastDenied     map[int64]time.Time
+	lastCallbackMu sync.Mutex
+
+	delivery telegramDelivery
 }
 
 type TelegramUpdate struct {
@@ -49,19 +92,22 @@ type TelegramUpdate struct {
 		Date int    `json:"date"`
 		Text string `json:"text"`
 	} `json:"message"`
-	CallbackQuery *struct {
-		ID   string `json:"id"`
-		From struct {
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query"`
+}
+
+type TelegramCallbackQuery struct {
+	ID   string `json:"id"`
+	From struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	} `json:"from"`
+	Message struct {
+		MessageID int `json:"message_id"`
+		Chat      struct {
 			ID int64 `json:"id"`
-		} `json:"from"`
-		Message struct {
-			MessageID int `json:"message_id"`
-			Chat      struct {
-				ID int64 `json:"id"`
-			} `json:"chat"`
-		} `json:"message"`
-		Data string `json:"data"`
-	} `json:"callback_query"`
+		} `json:"chat"`
+	} `json:"message"`
+	Data string `json:"data"`
 }
 
 type TelegramResponse struct {
@@ -81,8 +127,13 @@ type InlineKeyboardMarkup struct {
 
 func NewTelegramService(ociService *OCIService) *TelegramService {
 	ts := &TelegramService{
-		ociService: ociService,
-		stopChan:   make(chan struct{}),
+		ociService:   ociService,
+		stopChan:     make(chan struct{}),
+		lastCallback: make(map[int64]time.Time),
+		lastDenied:   make(map[int64]time.Time),
+		apiBase:      TelegramDefaultAPIBase,
+		language:     TgLangZh,
+		httpClient:   &http.Client{Timeout: 60 * time.Second},
 	}
 	ts.loadConfig()
 	return ts
@@ -91,16 +142,158 @@ func NewTelegramService(ociService *OCIService) *TelegramService {
 func (s *TelegramService) loadConfig() {
 	db := database.GetDB()
 
-	var tokenSetting, chatIDSetting, enabledSetting models.SysSetting
+	var tokenSetting, chatIDSetting, enabledSetting, proxySetting, apiBaseSetting, languageSetting models.SysSetting
 	db.Where("key = ?", SettingKeyTgBotToken).First(&tokenSetting)
 	db.Where("key = ?", SettingKeyTgChatID).First(&chatIDSetting)
 	db.Where("key = ?", SettingKeyTgEnabled).First(&enabledSetting)
+	db.Where("key = ?", SettingKeyTgProxyURL).First(&proxySetting)
+	db.Where("key = ?", SettingKeyTgAPIBase).First(&apiBaseSetting)
+	db.Where("key = ?", SettingKeyTgLanguage).First(&languageSetting)
+
+	client, err := newTelegramHTTPClient(proxySetting.Value)
+	if err != nil {
+		log.Printf("Invalid telegram proxy %q, falling back to direct connection: %v", proxySetting.Value, err)
+		client, _ = newTelegramHTTPClient("")
+	}
 
 	s.mu.Lock()
 	s.botToken = tokenSetting.Value
 	s.chatID = chatIDSetting.Value
 	s.enabled = enabledSetting.Value == "true"
+	s.proxyURL = proxySetting.Value
+	s.apiBase = normalizeTelegramAPIBase(apiBaseSetting.Value)
+	s.language = normalizeTgLanguage(languageSetting.Value)
+	s.httpClient = client
+	s.mu.Unlock()
+}
+
+// newTelegramHTTPClient 创建 Telegram API 使用的 HTTP 客户端，支持 http/https/socks5 代理
+func newTelegramHTTPClient(proxyURL string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		switch parsed.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme: %s", parsed.Scheme)
+		}
+		if parsed.Host == "" {
+			return nil, fmt.Errorf("invalid proxy url: missing host")
+		}
+		transport.Proxy = http.ProxyURL(parsed)
+	}
+
+	// getUpdates 使用 30 秒长轮询，超时需大于该值
+	return &http.Client{Transport: transport, Timeout: 60 * time.Second}, nil
+}
+
+// normalizeTelegramAPIBase 规范化自定义 API 地址，为空时使用官方地址
+func normalizeTelegramAPIBase(apiBase string) string {
+	apiBase = strings.TrimRight(strings.TrimSpace(apiBase), "/")
+	if apiBase == "" {
+		return TelegramDefaultAPIBase
+	}
+	return apiBase
+}
+
+// UpdateProxyConfig 更新 Telegram API 代理和自定义 API 地址
+func (s *TelegramService) UpdateProxyConfig(proxyURL, apiBase string) error {
+	proxyURL = strings.TrimSpace(proxyURL)
+	apiBase = strings.TrimSpace(apiBase)
+
+	client, err := newTelegramHTTPClient(proxyURL)
+	if err != nil {
+		return err
+	}
+	if apiBase != "" {
+		parsed, err := url.Parse(apiBase)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid api base url: %s", apiBase)
+		}
+	}
+
+	db := database.GetDB()
+	settings := []models.SysSetting{
+		{Key: SettingKeyTgProxyURL, Value: proxyURL},
+		{Key: SettingKeyTgAPIBase, Value: apiBase},
+	}
+	for _, setting := range settings {
+		var existing models.SysSetting
+		if err := db.Where("key = ?", setting.Key).First(&existing).Error; err != nil {
+			setting.ID = fmt.Sprintf("%d", time.Now().UnixNano())
+			if err := db.Create(&setting).Error; err != nil {
+				return err
+			}
+		} else {
+			if err := db.Model(&existing).Update("value", setting.Value).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.proxyURL = proxyURL
+	s.apiBase = normalizeTelegramAPIBase(apiBase)
+	s.httpClient = client
 	s.mu.Unlock()
+
+	return nil
+}
+
+// GetProxyConfig 获取 Telegram API 代理和自定义 API 地址
+func (s *TelegramService) GetProxyConfig() (proxyURL, apiBase string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.apiBase == TelegramDefaultAPIBase {
+		return s.proxyURL, ""
+	}
+	return s.proxyURL, s.apiBase
+}
+
+// UpdateLanguage 更新 Bot 显示语言
+func (s *TelegramService) UpdateLanguage(lang string) error {
+	if _, ok := tgMessages[lang]; !ok {
+		return fmt.Errorf("unsupported language: %s", lang)
+	}
+
+	db := database.GetDB()
+	var existing models.SysSetting
+	if err := db.Where("key = ?", SettingKeyTgLanguage).First(&existing).Error; err != nil {
+		setting := models.SysSetting{
+			ID:    fmt.Sprintf("%d", time.Now().UnixNano()),
+			Key:   SettingKeyTgLanguage,
+			Value: lang,
+		}
+		if err := db.Create(&setting).Error; err != nil {
+			return err
+		}
+	} else {
+		if err := db.Model(&existing).Update("value", lang).Error; err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.language = lang
+	s.mu.Unlock()
+
+	return nil
+}
+
+// GetLanguage 获取 Bot 显示语言
+func (s *TelegramService) GetLanguage() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.language
+}
+
+func (s *TelegramService) client() *http.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.httpClient
 }
 
 func (s *TelegramService) UpdateConfig(botToken, chatID string, enabled bool) error {
@@ -162,41 +355,64 @@ func (s *TelegramService) SendMessage(message string) error {
 }
 
 func (s *TelegramService) doSendMessage(chatID, text string, replyMarkup *InlineKeyboardMarkup) error {
+	return s.postMessage(chatID, text, replyMarkup, false)
+}
+
+// postMessage 发送消息，silent 为 true 时客户端收到消息不响铃
+func (s *TelegramService) postMessage(chatID, text string, replyMarkup *InlineKeyboardMarkup, silent bool) error {
 	s.mu.RLock()
 	botToken := s.botToken
+	apiBase := s.apiBase
 	s.mu.RUnlock()
 
-	apiURL := fmt.Sprintf(TelegramAPIURL, botToken, "sendMessage")
+	apiURL := fmt.Sprintf(TelegramAPIURL, apiBase, botToken, "sendMessage")
 
 	params := url.Values{}
 	params.Set("chat_id", chatID)
 	params.Set("text", text)
 	params.Set("parse_mode", "HTML")
+	if silent {
+		params.Set("disable_notification", "true")
+	}
 
 	if replyMarkup != nil {
 		markupJSON, _ := json.Marshal(replyMarkup)
 		params.Set("reply_markup", string(markupJSON))
 	}
 
-	resp, err := http.PostForm(apiURL, params)
+	resp, err := s.client().PostForm(apiURL, params)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		err = fmt.Errorf("failed to send message: %w", err)
+		s.delivery.record(0, "", err)
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API returned status: %d", resp.StatusCode)
+		// Bot API 出错时在 description 中说明原因
+		var result struct {
+			Description string `json:"description"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		err := fmt.Errorf("telegram API returned status: %d", resp.StatusCode)
+		if result.Description != "" {
+			err = fmt.Errorf("telegram API returned status: %d: %s", resp.StatusCode, result.Description)
+		}
+		s.delivery.record(resp.StatusCode, result.Description, err)
+		return err
 	}
 
+	s.delivery.record(resp.StatusCode, "", nil)
 	return nil
 }
 
 func (s *TelegramService) editMessage(chatID string, messageID int, text string, replyMarkup *InlineKeyboardMarkup) error {
 	s.mu.RLock()
 	botToken := s.botToken
+	apiBase := s.apiBase
 	s.mu.RUnlock()
 
-	apiURL := fmt.Sprintf(TelegramAPIURL, botToken, "editMessageText")
+	apiURL := fmt.Sprintf(TelegramAPIURL, apiBase, botToken, "editMessageText")
 
 	params := url.Values{}
 	params.Set("chat_id", chatID)
@@ -209,7 +425,7 @@ func (s *TelegramService) editMessage(chatID string, messageID int, text string,
 		params.Set("reply_markup", string(markupJSON))
 	}
 
-	resp, err := http.PostForm(apiURL, params)
+	resp, err := s.client().PostForm(apiURL, params)
 	if err != nil {
 		return err
 	}
@@ -221,15 +437,16 @@ func (s *TelegramService) editMessage(chatID string, messageID int, text string,
 func (s *TelegramService) deleteMessage(chatID string, messageID int) error {
 	s.mu.RLock()
 	botToken := s.botToken
+	apiBase := s.apiBase
 	s.mu.RUnlock()
 
-	apiURL := fmt.Sprintf(TelegramAPIURL, botToken, "deleteMessage")
+	apiURL := fmt.Sprintf(TelegramAPIURL, apiBase, botToken, "deleteMessage")
 
 	params := url.Values{}
 	params.Set("chat_id", chatID)
 	params.Set("message_id", fmt.Sprintf("%d", messageID))
 
-	resp, err := http.PostForm(apiURL, params)
+	resp, err := s.client().PostForm(apiURL, params)
 	if err != nil {
 		return err
 	}
@@ -238,17 +455,21 @@ func (s *TelegramService) deleteMessage(chatID string, messageID int) error {
 	return nil
 }
 
-func (s *TelegramService) answerCallbackQuery(callbackQueryID string) error {
+func (s *TelegramService) answerCallbackQuery(callbackQueryID, text string) error {
 	s.mu.RLock()
 	botToken := s.botToken
+	apiBase := s.apiBase
 	s.mu.RUnlock()
 
-	apiURL := fmt.Sprintf(TelegramAPIURL, botToken, "answerCallbackQuery")
+	apiURL := fmt.Sprintf(TelegramAPIURL, apiBase, botToken, "answerCallbackQuery")
 
 	params := url.Values{}
 	params.Set("callback_query_id", callbackQueryID)
+	if text != "" {
+		params.Set("text", text)
+	}
 
-	resp, err := http.PostForm(apiURL, params)
+	resp, err := s.client().PostForm(apiURL, params)
 	if err != nil {
 		return err
 	}
@@ -317,15 +538,16 @@ func (s *TelegramService) pollUpdates() {
 func (s *TelegramService) getUpdates(offset int) ([]TelegramUpdate, error) {
 	s.mu.RLock()
 	botToken := s.botToken
+	apiBase := s.apiBase
 	s.mu.RUnlock()
 
-	apiURL := fmt.Sprintf(TelegramAPIURL, botToken, "getUpdates")
+	apiURL := fmt.Sprintf(TelegramAPIURL, apiBase, botToken, "getUpdates")
 
 	params := url.Values{}
 	params.Set("offset", fmt.Sprintf("%d", offset))
 	params.Set("timeout", "30")
 
-	resp, err := http.PostForm(apiURL, params)
+	resp, err := s.client().PostForm(apiURL, params)
 	if err != nil {
 		return nil, err
 	}
@@ -349,137 +571,281 @@ func (s *TelegramService) handleUpdate(update TelegramUpdate) {
 	s.mu.RUnlock()
 
 	if update.Message != nil {
-		if fmt.Sprintf("%d", update.Message.Chat.ID) != chatID {
-			s.doSendMessage(fmt.Sprintf("%d", update.Message.Chat.ID),
-				"❌ 无权限操作此机器人🤖\n项目地址: https://github.com/adiecho/oci-panel", nil)
+		msg := update.Message
+		if fmt.Sprintf("%d", msg.Chat.ID) != chatID {
+			if s.allowDeniedAudit(msg.From.ID) {
+				s.recordAudit(msg.From.ID, msg.From.Username, msg.Chat.ID, "command", msg.Text, TgAuditResultDenied, nil)
+				s.doSendMessage(fmt.Sprintf("%d", msg.Chat.ID), s.t("no_permission"), nil)
+			}
 			return
 		}
 
-		if update.Message.Text == "/start" {
-			s.handleStartCommand(update.Message.Chat.ID)
-		}
+		result, err := runBotHandler(func() (bool, error) {
+			return s.handleCommand(msg.Chat.ID, msg.Text)
+		})
+		s.recordAudit(msg.From.ID, msg.From.Username, msg.Chat.ID, "command", msg.Text, result, err)
 	}
 
 	if update.CallbackQuery != nil {
-		if fmt.Sprintf("%d", update.CallbackQuery.From.ID) != chatID {
-			s.answerCallbackQuery(update.CallbackQuery.ID)
+		cb := update.CallbackQuery
+		if fmt.Sprintf("%d", cb.From.ID) != chatID {
+			if s.allowDeniedAudit(cb.From.ID) {
+				s.recordAudit(cb.From.ID, cb.From.Username, cb.Message.Chat.ID, "callback", cb.Data, TgAuditResultDenied, nil)
+			}
+			s.answerCallbackQuery(cb.ID, "")
 			return
 		}
 
-		s.answerCallbackQuery(update.CallbackQuery.ID)
-		s.handleCallback(update.CallbackQuery)
+		// 关闭窗口不涉及 OCI 调用，不做限流
+		if cb.Data != "cancel" && !s.allowCallback(cb.From.ID) {
+			s.recordAudit(cb.From.ID, cb.From.Username, cb.Message.Chat.ID, "callback", cb.Data, TgAuditResultRateLimited, nil)
+			s.answerCallbackQuery(cb.ID, s.t("rate_limited"))
+			return
+		}
+
+		s.answerCallbackQuery(cb.ID, "")
+		result, err := runBotHandler(func() (bool, error) {
+			return s.handleCallback(cb)
+		})
+		s.recordAudit(cb.From.ID, cb.From.Username, cb.Message.Chat.ID, "callback", cb.Data, result, err)
+	}
+}
+
+// handleCommand 处理文本命令，返回命令是否被识别
+func (s *TelegramService) handleCommand(chatID int64, text string) (bool, error) {
+	switch {
+	case text == "/start":
+		return true, s.handleStartCommand(chatID)
+	case text == "/traffic_alert" || strings.HasPrefix(text, "/traffic_alert "):
+		return true, s.handleTrafficAlertCommand(chatID, text)
+	}
+	return false, nil
+}
+
+// runBotHandler 执行命令或回调并返回审计结果，处理过程中 panic 时记为失败
+func runBotHandler(handle func() (bool, error)) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = TgAuditResultFailed, fmt.Errorf("处理异常: %v", r)
+		}
+	}()
+
+	handled, err := handle()
+	switch {
+	case err != nil:
+		return TgAuditResultFailed, err
+	case !handled:
+		return TgAuditResultIgnored, nil
+	}
+	return TgAuditResultSuccess, nil
+}
+
+// allowCallback 检查用户回调是否超出频率限制
+func (s *TelegramService) allowCallback(userID int64) bool {
+	s.lastCallbackMu.Lock()
+	defer s.lastCallbackMu.Unlock()
+
+	now := time.Now()
+	if last, ok := s.lastCallback[userID]; ok && now.Sub(last) < TgCallbackRateLimit {
+		return false
+	}
+	s.lastCallback[userID] = now
+	return true
+}
+
+// allowDeniedAudit 检查是否记录未授权用户的本次操作，同一用户在间隔内只记录一次
+func (s *TelegramService) allowDeniedAudit(userID int64) bool {
+	s.lastCallbackMu.Lock()
+	defer s.lastCallbackMu.Unlock()
+
+	now := time.Now()
+	if last, ok := s.lastDenied[userID]; ok && now.Sub(last) < TgDeniedAuditInterval {
+		return false
+	}
+	// 大量不同用户刷屏时清理已过间隔的记录，避免无限增长
+	if len(s.lastDenied) >= 1000 {
+		for id, last := range s.lastDenied {
+			if now.Sub(last) >= TgDeniedAuditInterval {
+				delete(s.lastDenied, id)
+			}
+		}
+	}
+	s.lastDenied[userID] = now
+	return true
+}
+
+// recordAudit 记录 Bot 命令/回调审计日志，处理失败时记录错误信息
+func (s *TelegramService) recordAudit(userID int64, username string, chatID int64, actionType, action, result string, err error) {
+	db := database.GetDB()
+	entry := models.TelegramAuditLog{
+		ID:         uuid.New().String(),
+		TgUserID:   userID,
+		TgUsername: username,
+		ChatID:     chatID,
+		Type:       actionType,
+		Action:     action,
+		Result:     result,
+		CreateTime: time.Now(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := db.Create(&entry).Error; err != nil {
+		log.Printf("Failed to record telegram audit log: %v", err)
+	}
+}
+
+// GetAuditLogs 分页查询 Bot 审计日志
+func (s *TelegramService) GetAuditLogs(page, pageSize int, tgUserID int64, result string) ([]models.TelegramAuditLog, int64, error) {
+	db := database.GetDB()
+	var logs []models.TelegramAuditLog
+	var total int64
+
+	query := db.Model(&models.TelegramAuditLog{})
+	if tgUserID != 0 {
+		query = query.Where("tg_user_id = ?", tgUserID)
 	}
+	if result != "" {
+		query = query.Where("result = ?", result)
+	}
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("create_time DESC").Limit(pageSize).Offset(offset).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
 }
 
-func (s *TelegramService) handleStartCommand(chatID int64) {
+func (s *TelegramService) handleStartCommand(chatID int64) error {
 	keyboard := s.getMainKeyboard()
-	s.doSendMessage(fmt.Sprintf("%d", chatID), "请选择需要执行的操作：", keyboard)
+	return s.doSendMessage(fmt.Sprintf("%d", chatID), s.t("choose_action"), keyboard)
 }
 
 func (s *TelegramService) getMainKeyboard() *InlineKeyboardMarkup {
 	return &InlineKeyboardMarkup{
 		InlineKeyboard: [][]InlineKeyboardButton{
 			{
-				{Text: "🔍 一键测活", CallbackData: "check_alive"},
-				{Text: "📋 任务详情", CallbackData: "task_details"},
+				{Text: s.t("btn_check_alive"), CallbackData: "check_alive"},
+				{Text: s.t("btn_task_details"), CallbackData: "task_details"},
+			},
+			{
+				{Text: s.t("btn_instance_stats"), CallbackData: "instance_stats"},
+				{Text: s.t("btn_config_list"), CallbackData: "config_list"},
+			},
+			{
+				{Text: s.t("btn_version_info"), CallbackData: "version_info"},
+				{Text: s.t("btn_traffic_stats"), CallbackData: "traffic_stats"},
 			},
 			{
-				{Text: "🖥️ 实例统计", CallbackData: "instance_stats"},
-				{Text: "📂 配置列表", CallbackData: "config_list"},
+				{Text: s.t("btn_traffic_alert"), CallbackData: tgCallbackTrafficAlert},
+				{Text: s.t("btn_idle_risk"), CallbackData: "idle_risk"},
 			},
 			{
-				{Text: "ℹ️ 版本信息", CallbackData: "version_info"},
-				{Text: "📊 流量统计", CallbackData: "traffic_stats"},
+				{Text: s.t("btn_activity"), CallbackData: "activity"},
+				{Text: s.t("btn_stop_all"), CallbackData: tgCallbackStopAll},
 			},
 			{
-				{Text: "⭐ 开源地址（欢迎Star）", URL: "https://github.com/adiecho/oci-panel"},
+				{Text: s.t("btn_star"), URL: "https://github.com/adiecho/oci-panel"},
 			},
 			{
-				{Text: "❌ 关闭窗口", CallbackData: "cancel"},
+				{Text: s.t("btn_cancel"), CallbackData: "cancel"},
 			},
 		},
 	}
 }
 
-func (s *TelegramService) handleCallback(callback *struct {
-	ID   string `json:"id"`
-	From struct {
-		ID int64 `json:"id"`
-	} `json:"from"`
-	Message struct {
-		MessageID int `json:"message_id"`
-		Chat      struct {
-			ID int64 `json:"id"`
-		} `json:"chat"`
-	} `json:"message"`
-	Data string `json:"data"`
-}) {
+// getStatsKeyboard 统计类消息的键盘，在主菜单上方附加刷新按钮
+func (s *TelegramService) getStatsKeyboard(action string) *InlineKeyboardMarkup {
+	rows := [][]InlineKeyboardButton{
+		{{Text: s.t("btn_refresh"), CallbackData: tgCallbackRefresh + action}},
+	}
+	rows = append(rows, s.getMainKeyboard().InlineKeyboard...)
+	return &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func (s *TelegramService) handleCallback(callback *TelegramCallbackQuery) (bool, error) {
 	chatID := fmt.Sprintf("%d", callback.Message.Chat.ID)
 	messageID := callback.Message.MessageID
 
-	switch callback.Data {
+	data := callback.Data
+	if action := strings.TrimPrefix(data, tgCallbackRefresh); action != data && tgRefreshableActions[action] {
+		// 刷新按钮重新执行对应查询并原地更新消息
+		data = action
+	}
+
+	switch data {
 	case "check_alive":
 		text := s.checkAlive()
-		s.editMessage(chatID, messageID, text, s.getMainKeyboard())
+		return true, s.editMessage(chatID, messageID, text, s.getMainKeyboard())
 
 	case "task_details":
 		text := s.getTaskDetails()
-		s.editMessage(chatID, messageID, text, s.getMainKeyboard())
+		return true, s.editMessage(chatID, messageID, text, s.getStatsKeyboard(data))
 
 	case "instance_stats":
 		text := s.getInstanceStats()
-		s.editMessage(chatID, messageID, text, s.getMainKeyboard())
+		return true, s.editMessage(chatID, messageID, text, s.getStatsKeyboard(data))
 
 	case "config_list":
 		text := s.getConfigList()
-		s.editMessage(chatID, messageID, text, s.getMainKeyboard())
+		return true, s.editMessage(chatID, messageID, text, s.getConfigListKeyboard())
 
 	case "version_info":
 		text := s.getVersionInfo()
-		s.editMessage(chatID, messageID, text, s.getMainKeyboard())
+		return true, s.editMessage(chatID, messageID, text, s.getMainKeyboard())
 
 	case "traffic_stats":
 		text := s.getTrafficStats()
-		s.editMessage(chatID, messageID, text, s.getMainKeyboard())
+		return true, s.editMessage(chatID, messageID, text, s.getStatsKeyboard(data))
+
+	case "idle_risk":
+		text := s.getIdleRiskReport()
+		return true, s.editMessage(chatID, messageID, text, s.getStatsKeyboard(data))
+
+	case "activity":
+		text := s.getRecentActivity()
+		return true, s.editMessage(chatID, messageID, text, s.getStatsKeyboard(data))
+
+	case "back_main":
+		return true, s.editMessage(chatID, messageID, s.t("choose_action"), s.getMainKeyboard())
 
 	case "cancel":
-		s.deleteMessage(chatID, messageID)
+		return true, s.deleteMessage(chatID, messageID)
+
 	}
+	handled := s.handleTrafficAlertCallback(chatID, messageID, data) || s.handleBatchCallback(chatID, messageID, data) ||
+		s.handleConfigSummaryCallback(chatID, messageID, data)
+	return handled, nil
 }
 
 func (s *TelegramService) checkAlive() string {
-	db := database.GetDB()
-
-	var users []models.OciUser
-	if err := db.Find(&users).Error; err != nil {
-		return "❌ 获取配置失败"
+	results, err := s.ociService.CheckConfigsAlive(context.Background(), nil)
+	if err != nil {
+		return s.t("get_config_failed")
 	}
 
-	if len(users) == 0 {
-		return "【API测活结果】\n\n暂无配置"
+	if len(results) == 0 {
+		return s.t("alive_title") + "\n\n" + s.t("no_config")
 	}
 
 	var validCount, invalidCount int
 	var invalidNames []string
 
-	for _, user := range users {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		_, err := s.ociService.ListInstances(ctx, &user, user.OciTenantID)
-		cancel()
-
-		if err != nil {
+	for _, r := range results {
+		if !r.Alive {
 			invalidCount++
-			invalidNames = append(invalidNames, user.Username)
+			invalidNames = append(invalidNames, r.Username)
 		} else {
 			validCount++
 		}
 	}
 
-	result := fmt.Sprintf("【API测活结果】\n\n✅ 有效配置数：%d\n❌ 失效配置数：%d\n🔑 总配置数：%d",
-		validCount, invalidCount, len(users))
+	result := s.t("alive_title") + "\n\n" + s.t("alive_summary", validCount, invalidCount, len(results))
 
 	if len(invalidNames) > 0 {
-		result += fmt.Sprintf("\n\n⚠️ 失效配置：\n%s", strings.Join(invalidNames, "\n"))
+		result += "\n\n" + s.t("alive_invalid", strings.Join(invalidNames, "\n"))
 	}
 
 	return result
@@ -490,25 +856,34 @@ func (s *TelegramService) getTaskDetails() string {
 
 	var tasks []models.OciCreateTask
 	if err := db.Find(&tasks).Error; err != nil {
-		return "❌ 获取任务失败"
+		return s.t("get_task_failed")
 	}
 
 	if len(tasks) == 0 {
-		return "【任务详情】\n\n🕐 时间：" + time.Now().Format("2006-01-02 15:04:05") + "\n\n🛎 正在执行的开机任务：无"
+		return s.t("task_title") + "\n\n" + s.t("time_line", time.Now().Format("2006-01-02 15:04:05")) + "\n\n" + s.t("task_none")
 	}
 
 	var taskInfos []string
 	for _, task := range tasks {
-		info := fmt.Sprintf("[%s] [%s] [%.0f核/%.0fGB/%dGB] [%d台] [%s] [执行%d次]",
+		info := s.t("task_item",
 			task.Username, task.Architecture,
 			task.Ocpus, task.Memory, task.Disk,
-			task.CreateNumbers, task.Status, task.ExecuteCount)
+			task.SuccessCount, taskTargetCount(&task), task.Status, task.ExecuteCount)
 		taskInfos = append(taskInfos, info)
 	}
 
-	return fmt.Sprintf("【任务详情】\n\n🕐 时间：%s\n\n🛎 正在执行的开机任务：\n%s",
-		time.Now().Format("2006-01-02 15:04:05"),
-		strings.Join(taskInfos, "\n"))
+	text := s.t("task_title") + "\n\n" +
+		s.t("time_line", time.Now().Format("2006-01-02 15:04:05")) + "\n\n" +
+		s.t("task_list", strings.Join(taskInfos, "\n"))
+
+	if groups, err := GetTaskGroupStats(); err == nil && len(groups) > 0 {
+		var groupInfos []string
+		for _, group := range groups {
+			groupInfos = append(groupInfos, s.t("task_group_item", group.GroupName, group.Running, group.Completed, group.Total))
+		}
+		text += "\n\n" + s.t("task_group_list", strings.Join(groupInfos, "\n"))
+	}
+	return text
 }
 
 func (s *TelegramService) getInstanceStats() string {
@@ -516,23 +891,32 @@ func (s *TelegramService) getInstanceStats() string {
 
 	var users []models.OciUser
 	if err := db.Find(&users).Error; err != nil {
-		return "❌ 获取配置失败"
+		return s.t("get_config_failed")
 	}
 
 	if len(users) == 0 {
-		return "【实例统计】\n\n暂无配置"
+		return s.t("instance_title") + "\n\n" + s.t("no_config")
 	}
 
 	var totalInstances, runningInstances int
-	var stats []string
+	var stats, favorites []string
+
+	// 收藏的配置排在最前，收藏的实例单独列出
+	favoriteConfigs := favoriteIDs("", FavoriteTypeConfig)
+	favoriteInstances := favoriteIDs("", FavoriteTypeInstance)
+	sortFavoritesFirst(users, func(u models.OciUser) bool { return favoriteConfigs[u.ID] })
 
 	for _, user := range users {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		instances, err := s.ociService.ListInstances(ctx, &user, user.OciTenantID)
 		cancel()
 
+		name := user.Username
+		if favoriteConfigs[user.ID] {
+			name = "⭐ " + name
+		}
 		if err != nil {
-			stats = append(stats, fmt.Sprintf("❌ %s: 获取失败", user.Username))
+			stats = append(stats, s.t("fetch_failed", name))
 			continue
 		}
 
@@ -541,18 +925,25 @@ func (s *TelegramService) getInstanceStats() string {
 			if inst.LifecycleState == "RUNNING" {
 				running++
 			}
+			if inst.Id != nil && favoriteInstances[*inst.Id] {
+				favorites = append(favorites, s.t("favorite_instance_item",
+					html.EscapeString(common.PointerString(inst.DisplayName)), html.EscapeString(user.Username), inst.LifecycleState))
+			}
 		}
 
 		totalInstances += len(instances)
 		runningInstances += running
-		stats = append(stats, fmt.Sprintf("🔑 %s [%s]: %d台 (运行中: %d)",
-			user.Username, user.OciRegion, len(instances), running))
+		stats = append(stats, s.t("instance_item",
+			name, user.OciRegion, len(instances), running))
 	}
 
-	return fmt.Sprintf("【实例统计】\n\n🕐 时间：%s\n📊 总实例数：%d\n🟢 运行中：%d\n\n%s",
-		time.Now().Format("2006-01-02 15:04:05"),
-		totalInstances, runningInstances,
-		strings.Join(stats, "\n"))
+	text := s.t("instance_title") + "\n\n" +
+		s.t("time_line", time.Now().Format("2006-01-02 15:04:05")) + "\n" +
+		s.t("instance_summary", totalInstances, runningInstances) + "\n\n"
+	if len(favorites) > 0 {
+		text += s.t("favorite_instances", strings.Join(favorites, "\n")) + "\n\n"
+	}
+	return text + strings.Join(stats, "\n")
 }
 
 func (s *TelegramService) getConfigList() string {
@@ -560,26 +951,24 @@ func (s *TelegramService) getConfigList() string {
 
 	var users []models.OciUser
 	if err := db.Find(&users).Error; err != nil {
-		return "❌ 获取配置失败"
+		return s.t("get_config_failed")
 	}
 
 	if len(users) == 0 {
-		return "【配置列表】\n\n暂无配置"
+		return s.t("config_title") + "\n\n" + s.t("no_config")
 	}
 
 	var configs []string
 	for i, user := range users {
-		configs = append(configs, fmt.Sprintf("%d. %s\n   区域: %s\n   租户: %s",
+		configs = append(configs, s.t("config_item",
 			i+1, user.Username, user.OciRegion, user.TenantName))
 	}
 
-	return fmt.Sprintf("【配置列表】\n\n🔑 总配置数：%d\n\n%s",
-		len(users), strings.Join(configs, "\n\n"))
+	return s.t("config_title") + "\n\n" + s.t("config_total", len(users)) + "\n\n" + strings.Join(configs, "\n\n")
 }
 
 func (s *TelegramService) getVersionInfo() string {
-	return fmt.Sprintf("【版本信息】\n\n📦 应用名称：OCI Panel\n🏷️ 当前版本：v1.0.0\n🔧 后端框架：Gin (Go)\n🎨 前端框架：Vue 3 + Vite\n💾 数据库：SQLite\n\n🕐 查询时间：%s",
-		time.Now().Format("2006-01-02 15:04:05"))
+	return s.t("version_info", time.Now().Format("2006-01-02 15:04:05"))
 }
 
 func (s *TelegramService) getTrafficStats() string {
@@ -587,52 +976,200 @@ func (s *TelegramService) getTrafficStats() string {
 
 	var users []models.OciUser
 	if err := db.Find(&users).Error; err != nil {
-		return "❌ 获取配置失败"
+		return s.t("get_config_failed")
 	}
 
 	if len(users) == 0 {
-		return "【流量统计】\n\n暂无配置"
+		return s.t("traffic_title") + "\n\n" + s.t("no_config")
 	}
 
-	var stats []string
-	for _, user := range users {
-		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-		trafficStats, err := s.ociService.GetMonthlyTrafficStats(ctx, &user)
-		cancel()
+	// 各配置并发查询，命中缓存的配置无需等待其它配置刷新
+	stats := make([]string, len(users))
+	var wg sync.WaitGroup
+	for i := range users {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := users[i]
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			trafficStats, err := s.ociService.GetMonthlyTrafficStats(ctx, &user)
+			cancel()
 
-		if err != nil {
-			stats = append(stats, fmt.Sprintf("❌ %s: 获取失败", user.Username))
+			if err != nil {
+				stats[i] = s.t("fetch_failed", user.Username)
+				return
+			}
+
+			stats[i] = s.t("traffic_item",
+				user.Username, user.OciRegion, trafficStats.InstanceCount,
+				FormatBytes(trafficStats.InboundTraffic),
+				FormatBytes(trafficStats.OutboundTraffic))
+		}(i)
+	}
+	wg.Wait()
+
+	return s.t("traffic_title") + "\n\n" +
+		s.t("time_line", time.Now().Format("2006-01-02 15:04:05")) + "\n\n" +
+		strings.Join(stats, "\n\n")
+}
+
+// getIdleRiskReport 所有配置下 Always Free 实例的闲置回收风险
+func (s *TelegramService) getIdleRiskReport() string {
+	var users []models.OciUser
+	if err := database.GetDB().Find(&users).Error; err != nil {
+		return s.t("get_config_failed")
+	}
+	if len(users) == 0 {
+		return s.t("idle_risk_title") + "\n\n" + s.t("no_config")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), IdleRiskReportTimeout)
+	defer cancel()
+
+	sections := make([]string, 0, len(users))
+	for _, report := range s.ociService.GetIdleRiskReports(ctx, users) {
+		if report.Error != "" {
+			sections = append(sections, s.t("fetch_failed", report.Username))
 			continue
 		}
+		lines := []string{s.t("idle_risk_config", report.Username, report.Region)}
+		if len(report.Instances) == 0 {
+			lines = append(lines, s.t("idle_risk_none"))
+		}
+		for _, item := range report.Instances {
+			level := s.t("idle_risk_" + item.RiskLevel)
+			if item.RiskLevel == IdleRiskUnknown {
+				lines = append(lines, s.t("idle_risk_unknown_item", level, item.InstanceName, item.Error))
+				continue
+			}
+			memory := ""
+			if item.HasMemory {
+				memory = s.t("idle_keepalive_memory", item.MemoryP95)
+			}
+			lines = append(lines, s.t("idle_risk_item", level, item.InstanceName, item.RiskScore, item.CPUP95, item.NetworkP95, memory))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
 
-		stats = append(stats, fmt.Sprintf("🔑 配置名：【%s】\n🌏 主区域：【%s】\n🖥️ 实例数量：【%d】台\n⬇️ 本月入站流量：%s\n⬆️ 本月出站流量：%s",
-			user.Username, user.OciRegion, trafficStats.InstanceCount,
-			FormatBytes(trafficStats.InboundTraffic),
-			FormatBytes(trafficStats.OutboundTraffic)))
+	return s.t("idle_risk_title") + "\n\n" +
+		s.t("time_line", time.Now().Format("2006-01-02 15:04:05")) + "\n\n" +
+		strings.Join(sections, "\n\n") + "\n\n" + s.t("idle_risk_hint")
+}
+
+// getRecentActivity 最近的审计、任务、作业与告警动态
+func (s *TelegramService) getRecentActivity() string {
+	feed, err := GetActivityFeed(1, tgActivityItems, nil)
+	if err != nil {
+		return s.t("activity_title") + "\n\n" + s.t("activity_failed")
+	}
+	if len(feed.Items) == 0 {
+		return s.t("activity_title") + "\n\n" + s.t("activity_none")
 	}
 
-	return fmt.Sprintf("【流量统计】\n\n🕐 时间：%s\n\n%s",
-		time.Now().Format("2006-01-02 15:04:05"),
-		strings.Join(stats, "\n\n"))
+	lines := make([]string, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		message := []rune(strings.TrimSpace(item.Message))
+		if len(message) > tgActivityMessageRunes {
+			message = append(message[:tgActivityMessageRunes], []rune("...")...)
+		}
+		// 消息以 HTML 模式发送，日志内容需要转义
+		lines = append(lines, s.t("activity_item", s.t("activity_type_"+item.Type), item.Time.Format("01-02 15:04"),
+			html.EscapeString(item.Subject), html.EscapeString(string(message))))
+	}
+	return s.t("activity_title") + "\n\n" + strings.Join(lines, "\n\n")
 }
 
+// SendNotification 发送通知，免打扰时间段内按设置静默发送或不发送
 func (s *TelegramService) SendNotification(title, message string) error {
+	quiet := GetNotifyQuietHours()
+	if !quiet.active(time.Now()) {
+		return s.sendNotification(title, message, false)
+	}
+	if quiet.Mode == NotifyQuietModeMute {
+		log.Printf("Notification %q suppressed during quiet hours", title)
+		recordNotification(title, message, "muted", nil)
+		return nil
+	}
+	return s.sendNotification(title, message, true)
+}
+
+// SendCriticalNotification 发送重要告警，不受免打扰时间段影响
+func (s *TelegramService) SendCriticalNotification(title, message string) error {
+	return s.sendNotification(title, message, false)
+}
+
+func (s *TelegramService) sendNotification(title, message string, silent bool) error {
+	s.mu.RLock()
+	botToken := s.botToken
+	chatID := s.chatID
+	enabled := s.enabled
+	s.mu.RUnlock()
+
+	if !enabled || botToken == "" || chatID == "" {
+		return fmt.Errorf("telegram not configured or disabled")
+	}
+
 	text := fmt.Sprintf("<b>%s</b>\n\n%s\n\n🕐 %s",
 		title, message, time.Now().Format("2006-01-02 15:04:05"))
-	return s.SendMessage(text)
+	err := s.postMessage(chatID, text, nil, silent)
+	result := "sent"
+	if silent {
+		result = "silent"
+	}
+	recordNotification(title, message, result, err)
+	return err
+}
+
+// recordNotification 记录通知发送结果，由数据库维护按保留天数与条数上限清理
+func recordNotification(title, message, result string, err error) {
+	entry := models.NotificationLog{
+		ID:         uuid.New().String(),
+		Title:      title,
+		Message:    message,
+		Result:     result,
+		CreateTime: time.Now(),
+	}
+	if err != nil {
+		entry.Result = "failed"
+		entry.Error = err.Error()
+	}
+	if err := database.GetDB().Create(&entry).Error; err != nil {
+		log.Printf("Failed to record notification log: %v", err)
+	}
+}
+
+// GetNotificationLogs 分页查询通知发送记录
+func (s *TelegramService) GetNotificationLogs(page, pageSize int, result string) ([]models.NotificationLog, int64, error) {
+	db := database.GetDB()
+	var logs []models.NotificationLog
+	var total int64
+
+	query := db.Model(&models.NotificationLog{})
+	if result != "" {
+		query = query.Where("result = ?", result)
+	}
+	query.Count(&total)
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("create_time DESC").Limit(pageSize).Offset(offset).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
 }
 
 func (s *TelegramService) TestConnection() error {
 	s.mu.RLock()
 	botToken := s.botToken
+	apiBase := s.apiBase
 	s.mu.RUnlock()
 
 	if botToken == "" {
 		return fmt.Errorf("bot token not configured")
 	}
 
-	apiURL := fmt.Sprintf(TelegramAPIURL, botToken, "getMe")
-	resp, err := http.Get(apiURL)
+	apiURL := fmt.Sprintf(TelegramAPIURL, apiBase, botToken, "getMe")
+	resp, err := s.client().Get(apiURL)
 	if err != nil {
 		return fmt.Errorf("connection failed: %w", err)
 	}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+)
+
+const (
+	tgCallbackTrafficAlert    = "traffic_alert"
+	tgCallbackTrafficAlertCfg = "ta_cfg:"
+	tgCallbackTrafficAlertSet = "ta_set:"
+
+	tbBytes = int64(1024 * 1024 * 1024 * 1024)
+)
+
+// trafficAlertPreset Bot 中可一键选择的告警方案
+type trafficAlertPreset struct {
+	Code       string
+	LimitTB    int64
+	Thresholds string
+}
+
+var trafficAlertPresets = []trafficAlertPreset{
+	{Code: "a", LimitTB: 10, Thresholds: "80,95"},
+	{Code: "b", LimitTB: 10, Thresholds: "50,80,95"},
+	{Code: "c", LimitTB: 5, Thresholds: "80,95"},
+	{Code: "d", LimitTB: 1, Thresholds: "80,95"},
+}
+
+// handleTrafficAlertCallback 处理流量告警相关的按钮回调，返回是否已处理
+func (s *TelegramService) handleTrafficAlertCallback(chatID string, messageID int, data string) bool {
+	switch {
+	case data == tgCallbackTrafficAlert:
+		text, keyboard := s.getTrafficAlertMenu()
+		s.editMessage(chatID, messageID, text, keyboard)
+	case strings.HasPrefix(data, tgCallbackTrafficAlertCfg):
+		configID := strings.TrimPrefix(data, tgCallbackTrafficAlertCfg)
+		text, keyboard := s.getTrafficAlertConfigMenu(configID)
+		s.editMessage(chatID, messageID, text, keyboard)
+	case strings.HasPrefix(data, tgCallbackTrafficAlertSet):
+		parts := strings.SplitN(strings.TrimPrefix(data, tgCallbackTrafficAlertSet), ":", 2)
+		if len(parts) != 2 {
+			return true
+		}
+		text := s.applyTrafficAlertPreset(parts[0], parts[1])
+		_, keyboard := s.getTrafficAlertConfigMenu(parts[0])
+		s.editMessage(chatID, messageID, text, keyboard)
+	default:
+		return false
+	}
+	return true
+}
+
+// getTrafficAlertMenu 列出所有配置及其当前告警规则
+func (s *TelegramService) getTrafficAlertMenu() (string, *InlineKeyboardMarkup) {
+	db := database.GetDB()
+
+	var users []models.OciUser
+	if err := db.Find(&users).Error; err != nil {
+		return s.t("get_config_failed"), s.getMainKeyboard()
+	}
+	if len(users) == 0 {
+		return s.t("traffic_alert_title") + "\n\n" + s.t("no_config"), s.getMainKeyboard()
+	}
+
+	var lines []string
+	var rows [][]InlineKeyboardButton
+	for _, user := range users {
+		lines = append(lines, fmt.Sprintf("🔑 %s: %s", user.Username, s.describeTrafficAlertRule(user.ID)))
+		rows = append(rows, []InlineKeyboardButton{
+			{Text: "⚙️ " + user.Username, CallbackData: tgCallbackTrafficAlertCfg + user.ID},
+		})
+	}
+	rows = append(rows, []InlineKeyboardButton{{Text: s.t("btn_back"), CallbackData: "back_main"}})
+
+	text := s.t("traffic_alert_title") + "\n\n" + strings.Join(lines, "\n") + "\n\n" + s.t("traffic_alert_usage")
+	return text, &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// getTrafficAlertConfigMenu 单个配置的告警方案选择
+func (s *TelegramService) getTrafficAlertConfigMenu(configID string) (string, *InlineKeyboardMarkup) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", configID).First(&user).Error; err != nil {
+		return s.t("get_config_failed"), s.getMainKeyboard()
+	}
+
+	var rows [][]InlineKeyboardButton
+	for _, preset := range trafficAlertPresets {
+		rows = append(rows, []InlineKeyboardButton{{
+			Text:         fmt.Sprintf("%dTB · %s%%", preset.LimitTB, strings.ReplaceAll(preset.Thresholds, ",", "%/")),
+			CallbackData: tgCallbackTrafficAlertSet + configID + ":" + preset.Code,
+		}})
+	}
+	rows = append(rows,
+		[]InlineKeyboardButton{{Text: s.t("btn_traffic_alert_off"), CallbackData: tgCallbackTrafficAlertSet + configID + ":off"}},
+		[]InlineKeyboardButton{{Text: s.t("btn_back"), CallbackData: tgCallbackTrafficAlert}},
+	)
+
+	text := s.t("traffic_alert_title") + "\n\n" +
+		fmt.Sprintf("🔑 %s [%s]\n%s", user.Username, user.OciRegion, s.describeTrafficAlertRule(user.ID))
+	return text, &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// applyTrafficAlertPreset 应用按钮选择的告警方案
+func (s *TelegramService) applyTrafficAlertPreset(configID, code string) string {
+	if code == "off" {
+		if err := SaveTrafficAlertRule(configID, 0, "", false); err != nil {
+			return s.t("traffic_alert_save_failed", err.Error())
+		}
+		return s.t("traffic_alert_disabled")
+	}
+
+	for _, preset := range trafficAlertPresets {
+		if preset.Code == code {
+			if err := SaveTrafficAlertRule(configID, preset.LimitTB*tbBytes, preset.Thresholds, true); err != nil {
+				return s.t("traffic_alert_save_failed", err.Error())
+			}
+			return s.t("traffic_alert_saved", s.describeTrafficAlertRule(configID))
+		}
+	}
+	return s.t("traffic_alert_save_failed", code)
+}
+
+// handleTrafficAlertCommand 处理 /traffic_alert <配置名> <上限TB> <阈值,阈值>，保存规则失败时返回错误
+func (s *TelegramService) handleTrafficAlertCommand(chatID int64, text string) error {
+	chat := fmt.Sprintf("%d", chatID)
+	fields := strings.Fields(text)
+	if len(fields) == 1 {
+		menu, keyboard := s.getTrafficAlertMenu()
+		return s.doSendMessage(chat, menu, keyboard)
+	}
+
+	if len(fields) < 3 {
+		return s.doSendMessage(chat, s.t("traffic_alert_usage"), nil)
+	}
+
+	var user models.OciUser
+	if err := database.GetDB().Where("username = ?", fields[1]).First(&user).Error; err != nil {
+		return s.doSendMessage(chat, s.t("traffic_alert_config_not_found", fields[1]), nil)
+	}
+
+	if fields[2] == "off" {
+		if err := SaveTrafficAlertRule(user.ID, 0, "", false); err != nil {
+			s.doSendMessage(chat, s.t("traffic_alert_save_failed", err.Error()), nil)
+			return err
+		}
+		return s.doSendMessage(chat, s.t("traffic_alert_disabled"), nil)
+	}
+
+	limitTB, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToUpper(fields[2]), "TB"), 64)
+	if err != nil || limitTB <= 0 {
+		return s.doSendMessage(chat, s.t("traffic_alert_usage"), nil)
+	}
+	thresholds := DefaultTrafficThresholds
+	if len(fields) >= 4 {
+		thresholds = fields[3]
+	}
+
+	if err := SaveTrafficAlertRule(user.ID, int64(limitTB*float64(tbBytes)), thresholds, true); err != nil {
+		s.doSendMessage(chat, s.t("traffic_alert_save_failed", err.Error()), nil)
+		return err
+	}
+	return s.doSendMessage(chat, s.t("traffic_alert_saved", s.describeTrafficAlertRule(user.ID)), nil)
+}
+
+// describeTrafficAlertRule 描述配置当前的告警规则
+func (s *TelegramService) describeTrafficAlertRule(configID string) string {
+	rule, err := GetTrafficAlertRule(configID)
+	if err != nil || !rule.Enabled {
+		return s.t("traffic_alert_none")
+	}
+	return s.t("traffic_alert_rule", FormatBytes(rule.LimitBytes), strings.ReplaceAll(rule.Thresholds, ",", "%/")+"%")
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	terminalDefaultCols = 80
+	terminalDefaultRows = 24
+)
+
+// terminalControl 浏览器通过文本消息发送的控制指令，其余消息作为键盘输入
+type terminalControl struct {
+	Type string `json:"type"` // resize
+	Cols int    `json:"cols"`
+	Rows int    `json:"rows"`
+}
+
+// terminalWriter 将终端输出以二进制消息写入 WebSocket，stdout 与 stderr 共用需加锁
+// recorder 不为空时同时写入会话录像
+type terminalWriter struct {
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	recorder *terminalRecorder
+}
+
+func (w *terminalWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	if w.recorder != nil {
+		w.recorder.Output(p)
+	}
+	return len(p), nil
+}
+
+// bridgeTerminal 在 SSH 连接上打开交互式 shell 并与 WebSocket 双向桥接，阻塞直到任一端断开
+// 二进制与非控制文本消息作为键盘输入，{"type":"resize"} 文本消息调整终端大小，输出以二进制消息返回
+func bridgeTerminal(conn *websocket.Conn, client *ssh.Client, recorder *terminalRecorder) error {
+	sshSession, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("创建终端会话失败: %w", err)
+	}
+	defer sshSession.Close()
+
+	writer := &terminalWriter{conn: conn, recorder: recorder}
+	sshSession.Stdout = writer
+	sshSession.Stderr = writer
+	stdin, err := sshSession.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := sshSession.RequestPty("xterm-256color", terminalDefaultRows, terminalDefaultCols, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+		return fmt.Errorf("请求终端失败: %w", err)
+	}
+	if err := sshSession.Shell(); err != nil {
+		return fmt.Errorf("打开终端失败: %w", err)
+	}
+
+	// 会话结束时关闭 WebSocket，使下面的读取循环退出
+	go func() {
+		_ = sshSession.Wait()
+		_ = conn.Close()
+	}()
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			return nil
+		}
+		if messageType == websocket.TextMessage {
+			var control terminalControl
+			if json.Unmarshal(data, &control) == nil && control.Type == "resize" {
+				if control.Cols > 0 && control.Rows > 0 {
+					_ = sshSession.WindowChange(control.Rows, control.Cols)
+					if recorder != nil {
+						recorder.Resize(control.Cols, control.Rows)
+					}
+				}
+				continue
+			}
+		}
+		if _, err := stdin.Write(data); err != nil && err != io.EOF {
+			return nil
+		}
+	}
+}
+
+// terminalRecorder 以 asciicast v2 格式记录终端输出，可用 asciinema 播放
+// 只记录输出不记录键盘输入，避免保存输入的密码
+type terminalRecorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	start   time.Time
+	size    int64
+	pending []byte // 上一段输出末尾不完整的 UTF-8 字符
+}
+
+// newTerminalRecorder 创建录像文件并写入文件头
+func newTerminalRecorder(path, title string) (*terminalRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	r := &terminalRecorder{file: file, start: time.Now()}
+	header, _ := json.Marshal(map[string]interface{}{
+		"version":   2,
+		"width":     terminalDefaultCols,
+		"height":    terminalDefaultRows,
+		"timestamp": r.start.Unix(),
+		"title":     title,
+		"env":       map[string]string{"TERM": "xterm-256color"},
+	})
+	r.write(append(header, '\n'))
+	return r, nil
+}
+
+func (r *terminalRecorder) write(line []byte) {
+	n, _ := r.file.Write(line)
+	r.size += int64(n)
+}
+
+func (r *terminalRecorder) event(code, data string) {
+	line, _ := json.Marshal([]interface{}{time.Since(r.start).Seconds(), code, data})
+	r.write(append(line, '\n'))
+}
+
+// Output 记录一段终端输出，输出可能在多字节字符中间被截断，不完整的部分留到下一段
+func (r *terminalRecorder) Output(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data := append(r.pending, p...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	r.pending = append([]byte(nil), data[cut:]...)
+	if cut > 0 {
+		r.event("o", string(data[:cut]))
+	}
+}
+
+// Resize 记录终端大小变化
+func (r *terminalRecorder) Resize(cols, rows int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.event("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// Close 写入剩余输出并关闭文件，返回录像文件大小
+func (r *terminalRecorder) Close() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) > 0 {
+		r.event("o", string(r.pending))
+		r.pending = nil
+	}
+	_ = r.file.Close()
+	return r.size
+}
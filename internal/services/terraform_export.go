@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// TerraformExport 导出的 Terraform/OpenTofu 配置
+type TerraformExport struct {
+	FileName string `json:"fileName"`
+	Content  string `json:"content"`
+}
+
+var terraformNamePattern = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// terraformName 将显示名称转换为合法的资源名称
+func terraformName(displayName, fallback string) string {
+	name := strings.Trim(terraformNamePattern.ReplaceAllString(strings.ToLower(displayName), "_"), "_")
+	if name == "" {
+		name = fallback
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "r_" + name
+	}
+	return name
+}
+
+// hclQuote 生成 HCL 字符串字面量，转义插值语法避免被解析为表达式
+func hclQuote(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "${", "$${", "%{", "%%{")
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// hclWriter 按缩进输出 HCL 块与属性
+type hclWriter struct {
+	b      strings.Builder
+	indent int
+}
+
+func (w *hclWriter) line(format string, args ...interface{}) {
+	if format == "" {
+		w.b.WriteString("\n")
+		return
+	}
+	w.b.WriteString(strings.Repeat("  ", w.indent))
+	fmt.Fprintf(&w.b, format, args...)
+	w.b.WriteString("\n")
+}
+
+func (w *hclWriter) open(format string, args ...interface{}) {
+	w.line(format+" {", args...)
+	w.indent++
+}
+
+func (w *hclWriter) close() {
+	w.indent--
+	w.line("}")
+}
+
+// attr 输出字符串属性，值为空时省略
+func (w *hclWriter) attr(name, value string) {
+	if value != "" {
+		w.line("%s = %s", name, hclQuote(value))
+	}
+}
+
+// ExportInstanceTerraform 生成实例及其引导卷、主 VNIC、块存储卷的 Terraform 配置
+// 配置附带 import 块（Terraform 1.5+/OpenTofu），执行 plan 时纳管现有资源而不是重新创建
+func (s *OCIService) ExportInstanceTerraform(ctx context.Context, user *models.OciUser, instanceId string) (*TerraformExport, error) {
+	instance, err := s.GetInstance(ctx, user, instanceId)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例失败: %w", err)
+	}
+	computeClient, err := s.GetComputeClient(user)
+	if err != nil {
+		return nil, err
+	}
+	blockClient, err := s.GetBlockstorageClient(user)
+	if err != nil {
+		return nil, err
+	}
+
+	name := terraformName(stringValue(instance.DisplayName), "instance")
+	w := &hclWriter{}
+	w.line("# 由 OCI Panel 于 %s 导出", time.Now().Format("2006-01-02 15:04:05"))
+	w.line("# 配置：%s，区域：%s", user.Username, user.OciRegion)
+	w.line("")
+
+	w.open("import")
+	w.line("to = oci_core_instance.%s", name)
+	w.attr("id", instanceId)
+	w.close()
+	w.line("")
+
+	w.open(`resource "oci_core_instance" %s`, hclQuote(name))
+	w.attr("availability_domain", stringValue(instance.AvailabilityDomain))
+	w.attr("compartment_id", stringValue(instance.CompartmentId))
+	w.attr("display_name", stringValue(instance.DisplayName))
+	w.attr("shape", stringValue(instance.Shape))
+	if instance.ShapeConfig != nil && IsFlexShape(stringValue(instance.Shape)) {
+		w.line("")
+		w.open("shape_config")
+		if instance.ShapeConfig.Ocpus != nil {
+			w.line("ocpus = %g", *instance.ShapeConfig.Ocpus)
+		}
+		if instance.ShapeConfig.MemoryInGBs != nil {
+			w.line("memory_in_gbs = %g", *instance.ShapeConfig.MemoryInGBs)
+		}
+		w.close()
+	}
+
+	w.line("")
+	w.open("source_details")
+	if source, ok := instance.SourceDetails.(core.InstanceSourceViaImageDetails); ok {
+		w.attr("source_type", "image")
+		w.attr("source_id", stringValue(source.ImageId))
+	} else if source, ok := instance.SourceDetails.(core.InstanceSourceViaBootVolumeDetails); ok {
+		w.attr("source_type", "bootVolume")
+		w.attr("source_id", stringValue(source.BootVolumeId))
+	}
+	if bootVolume, err := s.GetBootVolumeByInstanceId(user, instanceId); err == nil {
+		if bootVolume.SizeInGBs != nil {
+			w.line("boot_volume_size_in_gbs = %d", *bootVolume.SizeInGBs)
+		}
+		if bootVolume.VpusPerGB != nil {
+			w.line("boot_volume_vpus_per_gb = %d", *bootVolume.VpusPerGB)
+		}
+	}
+	w.close()
+
+	if vnic, err := s.getPrimaryVnic(ctx, user, instanceId); err == nil {
+		w.line("")
+		w.open("create_vnic_details")
+		w.attr("subnet_id", stringValue(vnic.SubnetId))
+		w.attr("display_name", stringValue(vnic.DisplayName))
+		w.attr("hostname_label", stringValue(vnic.HostnameLabel))
+		w.attr("private_ip", stringValue(vnic.PrivateIp))
+		w.line("assign_public_ip = %t", vnic.PublicIp != nil && *vnic.PublicIp != "")
+		if vnic.SkipSourceDestCheck != nil {
+			w.line("skip_source_dest_check = %t", *vnic.SkipSourceDestCheck)
+		}
+		w.close()
+	}
+
+	if keys := instance.Metadata["ssh_authorized_keys"]; keys != "" {
+		w.line("")
+		w.open("metadata =")
+		w.line("ssh_authorized_keys = %s", hclQuote(keys))
+		w.close()
+	}
+
+	// 镜像更新或元数据变化不应导致实例被重建
+	w.line("")
+	w.open("lifecycle")
+	w.line("ignore_changes = [source_details[0].source_id, metadata, create_vnic_details[0].assign_public_ip]")
+	w.close()
+	w.close()
+
+	attachResp, err := computeClient.ListVolumeAttachments(ctx, core.ListVolumeAttachmentsRequest{
+		CompartmentId: instance.CompartmentId,
+		InstanceId:    instance.Id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取块存储卷附件失败: %w", err)
+	}
+	usedNames := make(map[string]bool)
+	for i, attachment := range attachResp.Items {
+		if attachment.GetLifecycleState() != core.VolumeAttachmentLifecycleStateAttached {
+			continue
+		}
+		volumeResp, err := blockClient.GetVolume(ctx, core.GetVolumeRequest{VolumeId: attachment.GetVolumeId()})
+		if err != nil {
+			return nil, fmt.Errorf("获取块存储卷失败: %w", err)
+		}
+		volume := volumeResp.Volume
+		volumeName := terraformName(stringValue(volume.DisplayName), fmt.Sprintf("%s_volume_%d", name, i+1))
+		if usedNames[volumeName] {
+			volumeName = fmt.Sprintf("%s_%d", volumeName, i+1)
+		}
+		usedNames[volumeName] = true
+		attachType := "paravirtualized"
+		if _, ok := attachment.(core.IScsiVolumeAttachment); ok {
+			attachType = "iscsi"
+		}
+
+		w.line("")
+		w.open("import")
+		w.line("to = oci_core_volume.%s", volumeName)
+		w.attr("id", stringValue(volume.Id))
+		w.close()
+		w.line("")
+		w.open("import")
+		w.line("to = oci_core_volume_attachment.%s", volumeName)
+		w.attr("id", stringValue(attachment.GetId()))
+		w.close()
+		w.line("")
+
+		w.open(`resource "oci_core_volume" %s`, hclQuote(volumeName))
+		w.attr("availability_domain", stringValue(volume.AvailabilityDomain))
+		w.attr("compartment_id", stringValue(volume.CompartmentId))
+		w.attr("display_name", stringValue(volume.DisplayName))
+		if volume.SizeInGBs != nil {
+			w.line("size_in_gbs = %d", *volume.SizeInGBs)
+		}
+		if volume.VpusPerGB != nil {
+			w.line("vpus_per_gb = %d", *volume.VpusPerGB)
+		}
+		w.close()
+		w.line("")
+
+		w.open(`resource "oci_core_volume_attachment" %s`, hclQuote(volumeName))
+		w.attr("attachment_type", attachType)
+		w.line("instance_id = oci_core_instance.%s.id", name)
+		w.line("volume_id = oci_core_volume.%s.id", volumeName)
+		if readOnly := attachment.GetIsReadOnly(); readOnly != nil && *readOnly {
+			w.line("is_read_only = true")
+		}
+		w.close()
+	}
+
+	return &TerraformExport{
+		FileName: name + ".tf",
+		Content:  w.b.String(),
+	}, nil
+}
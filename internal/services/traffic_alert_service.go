@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// 流量告警检查间隔，月度指标按天汇总，无需频繁查询
+	TrafficAlertCheckInterval = 1 * time.Hour
+
+	// 未指定阈值时的默认告警百分比
+	DefaultTrafficThresholds = "80,95"
+)
+
+type TrafficAlertService struct {
+	ociService      *OCIService
+	telegramService *TelegramService
+	stopChan        chan struct{}
+	running         bool
+	mutex           sync.Mutex
+}
+
+func NewTrafficAlertService(ociService *OCIService, telegramService *TelegramService) *TrafficAlertService {
+	return &TrafficAlertService{
+		ociService:      ociService,
+		telegramService: telegramService,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+func (s *TrafficAlertService) Start() {
+	s.mutex.Lock()
+	if s.running {
+		s.mutex.Unlock()
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	s.mutex.Unlock()
+
+	go s.run()
+	log.Println("Traffic alert service started")
+}
+
+func (s *TrafficAlertService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+	log.Println("Traffic alert service stopped")
+}
+
+func (s *TrafficAlertService) run() {
+	ticker := time.NewTicker(TrafficAlertCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.CheckAll()
+		}
+	}
+}
+
+// CheckAll 检查所有已启用的告警规则，达到新的阈值时发送通知
+func (s *TrafficAlertService) CheckAll() {
+	db := database.GetDB()
+	var rules []models.TrafficAlertRule
+	if err := db.Where("enabled = ?", true).Find(&rules).Error; err != nil {
+		log.Printf("[TrafficAlert] Failed to load rules: %v", err)
+		return
+	}
+
+	month := time.Now().Format("2006-01")
+	for _, rule := range rules {
+		var user models.OciUser
+		if err := db.Where("id = ?", rule.ConfigID).First(&user).Error; err != nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		stats, err := s.ociService.GetMonthlyTrafficStats(ctx, &user)
+		cancel()
+		if err != nil {
+			log.Printf("[TrafficAlert] Failed to get traffic for %s: %v", user.Username, err)
+			continue
+		}
+
+		if rule.LastAlertMonth != month {
+			rule.LastAlertMonth = month
+			rule.LastAlertLevel = 0
+		}
+
+		percent := trafficPercent(stats.OutboundTraffic, rule.LimitBytes)
+		level := 0
+		for _, threshold := range ParseTrafficThresholds(rule.Thresholds) {
+			if percent >= float64(threshold) && threshold > level {
+				level = threshold
+			}
+		}
+
+		if level > rule.LastAlertLevel {
+			message := s.telegramService.t("traffic_alert_notify", user.Username, user.OciRegion,
+				FormatBytes(stats.OutboundTraffic), FormatBytes(rule.LimitBytes), percent, level)
+			if err := s.telegramService.SendNotification(s.telegramService.t("traffic_alert_notify_title"), message); err != nil {
+				log.Printf("[TrafficAlert] Failed to send alert for %s: %v", user.Username, err)
+				continue
+			}
+			rule.LastAlertLevel = level
+		}
+
+		db.Model(&models.TrafficAlertRule{}).Where("id = ?", rule.ID).Updates(map[string]interface{}{
+			"last_alert_month": rule.LastAlertMonth,
+			"last_alert_level": rule.LastAlertLevel,
+		})
+	}
+}
+
+func trafficPercent(used, limit int64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(used) * 100 / float64(limit)
+}
+
+// ParseTrafficThresholds 解析逗号分隔的告警百分比，忽略无效值并升序去重
+func ParseTrafficThresholds(value string) []int {
+	seen := make(map[int]bool)
+	var thresholds []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(part), "%")))
+		if err != nil || n <= 0 || n > 100 || seen[n] {
+			continue
+		}
+		seen[n] = true
+		thresholds = append(thresholds, n)
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+// SaveTrafficAlertRule 创建或更新配置的流量告警规则
+func SaveTrafficAlertRule(configID string, limitBytes int64, thresholds string, enabled bool) error {
+	parsed := ParseTrafficThresholds(thresholds)
+	if enabled && (limitBytes <= 0 || len(parsed) == 0) {
+		return fmt.Errorf("invalid traffic limit or thresholds")
+	}
+	parts := make([]string, len(parsed))
+	for i, n := range parsed {
+		parts[i] = strconv.Itoa(n)
+	}
+
+	db := database.GetDB()
+	var rule models.TrafficAlertRule
+	if err := db.Where("config_id = ?", configID).First(&rule).Error; err != nil {
+		rule = models.TrafficAlertRule{
+			ID:         uuid.New().String(),
+			ConfigID:   configID,
+			LimitBytes: limitBytes,
+			Thresholds: strings.Join(parts, ","),
+			Enabled:    enabled,
+		}
+		return db.Create(&rule).Error
+	}
+
+	updates := map[string]interface{}{"enabled": enabled}
+	if enabled {
+		updates["limit_bytes"] = limitBytes
+		updates["thresholds"] = strings.Join(parts, ",")
+		// 规则变化后重新计算本月告警级别
+		updates["last_alert_level"] = 0
+	}
+	return db.Model(&rule).Updates(updates).Error
+}
+
+// GetTrafficAlertRule 获取配置的流量告警规则
+func GetTrafficAlertRule(configID string) (*models.TrafficAlertRule, error) {
+	var rule models.TrafficAlertRule
+	if err := database.GetDB().Where("config_id = ?", configID).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+const (
+	TrafficQuotaActionNotify    = "notify"
+	TrafficQuotaActionStop      = "stop"
+	TrafficQuotaActionReleaseIP = "release_ip"
+
+	// 未指定时的默认预警百分比
+	DefaultTrafficQuotaWarnPercent = 80
+	// 单个配置检查与执行动作的超时
+	trafficQuotaTimeout = 5 * time.Minute
+)
+
+type TrafficQuotaService struct {
+	ociService      *OCIService
+	telegramService *TelegramService
+}
+
+func NewTrafficQuotaService(ociService *OCIService, telegramService *TelegramService) *TrafficQuotaService {
+	return &TrafficQuotaService{
+		ociService:      ociService,
+		telegramService: telegramService,
+	}
+}
+
+// Job 返回由作业框架调度的流量配额检查作业，月度流量按天汇总，每小时检查一次即可
+func (s *TrafficQuotaService) Job() Job {
+	return &FuncJob{
+		JobName:        "traffic_quota",
+		JobDescription: "检查月度出站流量配额，用尽后关机或释放公网 IP",
+		CheckInterval:  time.Hour,
+		RunFunc:        s.checkAll,
+	}
+}
+
+// checkAll 检查所有已启用的配额
+func (s *TrafficQuotaService) checkAll(ctx context.Context) (string, error) {
+	var quotas []models.TrafficQuota
+	if err := database.GetDB().Where("enabled = ?", true).Find(&quotas).Error; err != nil {
+		return "", err
+	}
+
+	var warned, enforced, failed int
+	for i := range quotas {
+		level, err := s.check(ctx, &quotas[i])
+		switch {
+		case err != nil:
+			failed++
+			log.Printf("[TrafficQuota] Config %s failed: %v", quotas[i].ConfigID, err)
+		case level >= 100:
+			enforced++
+		case level > 0:
+			warned++
+		}
+	}
+
+	summary := fmt.Sprintf("检查 %d 个配额：预警 %d，执行 %d，失败 %d", len(quotas), warned, enforced, failed)
+	if failed > 0 {
+		return "", fmt.Errorf("%s", summary)
+	}
+	return summary, nil
+}
+
+// check 检查单个配额，返回本次新处理的级别（0 表示无变化）
+// 执行动作有失败时不记录 100 级别，下次检查会重试
+func (s *TrafficQuotaService) check(ctx context.Context, quota *models.TrafficQuota) (int, error) {
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", quota.ConfigID).First(&user).Error; err != nil {
+		return 0, fmt.Errorf("配置不存在")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, trafficQuotaTimeout)
+	defer cancel()
+
+	stats, err := s.ociService.GetMonthlyTrafficStats(ctx, &user)
+	if err != nil {
+		return 0, fmt.Errorf("获取流量失败: %s", extractOCIErrorMessage(err))
+	}
+
+	now := time.Now()
+	month := now.Format("2006-01")
+	if quota.LastCheckMonth != month {
+		quota.LastCheckMonth = month
+		quota.LastLevel = 0
+	}
+	percent := trafficPercent(stats.OutboundTraffic, quota.LimitBytes)
+	updates := map[string]interface{}{
+		"last_check_month": month,
+		"last_check_time":  now,
+		"last_percent":     percent,
+	}
+
+	level := 0
+	var checkErr error
+	used, limit := FormatBytes(stats.OutboundTraffic), FormatBytes(quota.LimitBytes)
+	switch {
+	case percent >= 100 && quota.LastLevel < 100:
+		results, err := s.enforce(ctx, &user, quota)
+		message := strings.Join(results, "\n")
+		if err != nil {
+			checkErr = err
+			message = strings.TrimSpace(message + "\n" + err.Error())
+		} else {
+			level = 100
+			quota.LastLevel = 100
+		}
+		updates["last_message"] = message
+		s.notify("traffic_quota_enforced", user.Username, used, limit, percent, trafficQuotaActionText(quota.Action), message)
+	case percent >= float64(quota.WarnPercent) && quota.LastLevel < quota.WarnPercent:
+		level = quota.WarnPercent
+		quota.LastLevel = quota.WarnPercent
+		s.notify("traffic_quota_warning", user.Username, used, limit, percent, trafficQuotaActionText(quota.Action))
+	}
+	updates["last_level"] = quota.LastLevel
+
+	db.Model(&models.TrafficQuota{}).Where("id = ?", quota.ID).Updates(updates)
+	return level, checkErr
+}
+
+// enforce 对配额范围内运行中的实例执行关机或释放公网 IP，返回每个实例的处理结果
+func (s *TrafficQuotaService) enforce(ctx context.Context, user *models.OciUser, quota *models.TrafficQuota) ([]string, error) {
+	if quota.Action != TrafficQuotaActionStop && quota.Action != TrafficQuotaActionReleaseIP {
+		return nil, nil
+	}
+
+	targets, err := s.targetInstances(ctx, user, quota)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例失败: %s", extractOCIErrorMessage(err))
+	}
+
+	var results []string
+	var failed int
+	for _, instance := range targets {
+		name := stringValue(instance.DisplayName)
+		var err error
+		if quota.Action == TrafficQuotaActionStop {
+			err = s.ociService.InstanceAction(ctx, user, *instance.Id, "STOP")
+		} else {
+			err = s.releasePublicIP(ctx, user, *instance.Id)
+		}
+		if err != nil {
+			failed++
+			results = append(results, fmt.Sprintf("❌ %s: %s", name, extractOCIErrorMessage(err)))
+			continue
+		}
+		results = append(results, "✅ "+name)
+	}
+	log.Printf("[TrafficQuota] Enforced %s on %d instances for %s, %d failed", quota.Action, len(targets), user.Username, failed)
+	if failed > 0 {
+		return results, fmt.Errorf("%d 个实例处理失败", failed)
+	}
+	return results, nil
+}
+
+// targetInstances 返回配额范围内运行中的实例
+func (s *TrafficQuotaService) targetInstances(ctx context.Context, user *models.OciUser, quota *models.TrafficQuota) ([]core.Instance, error) {
+	instances, err := s.ociService.ListInstances(ctx, user, user.OciTenantID)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool)
+	for _, id := range strings.Split(quota.InstanceIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			selected[id] = true
+		}
+	}
+
+	var targets []core.Instance
+	for _, instance := range instances {
+		if instance.Id == nil || instance.LifecycleState != core.InstanceLifecycleStateRunning {
+			continue
+		}
+		if len(selected) > 0 && !selected[*instance.Id] {
+			continue
+		}
+		targets = append(targets, instance)
+	}
+	return targets, nil
+}
+
+// releasePublicIP 删除实例主 VNIC 的临时公网 IP，之后可通过更换 IP 重新分配
+func (s *TrafficQuotaService) releasePublicIP(ctx context.Context, user *models.OciUser, instanceID string) error {
+	vnic, err := s.ociService.getPrimaryVnic(ctx, user, instanceID)
+	if err != nil {
+		return err
+	}
+	vnClient, err := s.ociService.GetVirtualNetworkClient(user)
+	if err != nil {
+		return err
+	}
+	return deleteVnicPublicIP(ctx, vnClient, vnic)
+}
+
+func trafficQuotaActionText(action string) string {
+	switch action {
+	case TrafficQuotaActionStop:
+		return "关机"
+	case TrafficQuotaActionReleaseIP:
+		return "释放公网 IP"
+	default:
+		return "仅通知"
+	}
+}
+
+// notify 推送配额通知，配额动作会影响实例运行，作为重要告警不受免打扰影响，key 对应的标题为 key + "_title"
+func (s *TrafficQuotaService) notify(key string, args ...interface{}) {
+	if s.telegramService == nil {
+		return
+	}
+	tg := s.telegramService
+	if err := tg.SendCriticalNotification(tg.t(key+"_title"), tg.t(key, args...)); err != nil {
+		log.Printf("[TrafficQuota] Failed to send notification: %v", err)
+	}
+}
+
+// GetTrafficQuota 获取配置的流量配额，未设置时返回 nil
+func GetTrafficQuota(configID string) (*models.TrafficQuota, error) {
+	var quota models.TrafficQuota
+	result := database.GetDB().Where("config_id = ?", configID).Limit(1).Find(&quota)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &quota, nil
+}
+
+// SaveTrafficQuota 创建或更新配置的流量配额，修改后重新计算本月已处理的级别
+func SaveTrafficQuota(configID string, limitBytes int64, warnPercent int, action string, instanceIDs []string, enabled bool) (*models.TrafficQuota, error) {
+	if limitBytes <= 0 {
+		return nil, fmt.Errorf("流量上限必须大于 0")
+	}
+	if warnPercent == 0 {
+		warnPercent = DefaultTrafficQuotaWarnPercent
+	}
+	if warnPercent < 1 || warnPercent > 99 {
+		return nil, fmt.Errorf("预警百分比需在 1-99 之间")
+	}
+	switch action {
+	case TrafficQuotaActionNotify, TrafficQuotaActionStop, TrafficQuotaActionReleaseIP:
+	default:
+		return nil, fmt.Errorf("不支持的动作: %s", action)
+	}
+
+	db := database.GetDB()
+	var user models.OciUser
+	if err := db.Where("id = ?", configID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("配置不存在")
+	}
+
+	quota, err := GetTrafficQuota(configID)
+	if err != nil {
+		return nil, err
+	}
+	if quota == nil {
+		quota = &models.TrafficQuota{ID: uuid.New().String(), ConfigID: configID}
+	}
+	var ids []string
+	for _, id := range instanceIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	quota.LimitBytes = limitBytes
+	quota.WarnPercent = warnPercent
+	quota.Action = action
+	quota.InstanceIDs = strings.Join(ids, ",")
+	quota.Enabled = enabled
+	quota.LastLevel = 0
+	if err := db.Save(quota).Error; err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// DeleteTrafficQuota 删除配置的流量配额
+func DeleteTrafficQuota(configID string) error {
+	return database.GetDB().Where("config_id = ?", configID).Delete(&models.TrafficQuota{}).Error
+}
+
+// CheckTrafficQuota 立即检查配置的流量配额，达到阈值时与定时检查一样通知或执行动作
+func (s *TrafficQuotaService) CheckTrafficQuota(configID string) (*models.TrafficQuota, error) {
+	quota, err := GetTrafficQuota(configID)
+	if err != nil {
+		return nil, err
+	}
+	if quota == nil {
+		return nil, fmt.Errorf("配置未设置流量配额")
+	}
+	if _, err := s.check(context.Background(), quota); err != nil {
+		return nil, err
+	}
+	return GetTrafficQuota(configID)
+}
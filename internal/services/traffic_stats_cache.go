@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	trafficDayLayout = "2006-01-02"
+
+	// 当天流量缓存有效期，过期后增量刷新
+	TrafficStatsCacheTTL = 30 * time.Minute
+)
+
+// GetMonthlyTrafficStats 获取指定配置的月度流量统计
+// 已结束的日期直接读取缓存，仅增量查询缺失的日期以及昨天、今天的数据
+func (s *OCIService) GetMonthlyTrafficStats(ctx context.Context, user *models.OciUser) (*MonthlyTrafficStats, error) {
+	db := database.GetDB()
+
+	now := time.Now()
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	today := startOfToday.Format(trafficDayLayout)
+
+	var cached []models.TrafficDailyStat
+	if err := db.Where("config_id = ? AND day >= ? AND day <= ?", user.ID, startOfMonth.Format(trafficDayLayout), today).
+		Find(&cached).Error; err != nil {
+		return nil, err
+	}
+	byDay := make(map[string]models.TrafficDailyStat, len(cached))
+	for _, stat := range cached {
+		byDay[stat.Day] = stat
+	}
+
+	// 监控数据有延迟，昨天的数据也视为未定稿
+	queryFrom := startOfToday.AddDate(0, 0, -1)
+	if queryFrom.Before(startOfMonth) {
+		queryFrom = startOfMonth
+	}
+	for d := startOfMonth; d.Before(queryFrom); d = d.AddDate(0, 0, 1) {
+		if _, ok := byDay[d.Format(trafficDayLayout)]; !ok {
+			queryFrom = d
+			break
+		}
+	}
+
+	todayStat, ok := byDay[today]
+	if !ok || now.Sub(todayStat.UpdateTime) >= TrafficStatsCacheTTL {
+		days, instanceCount, err := s.queryDailyTraffic(ctx, user, queryFrom, now)
+		if err != nil {
+			return nil, err
+		}
+
+		for d := queryFrom; !d.After(startOfToday); d = d.AddDate(0, 0, 1) {
+			key := d.Format(trafficDayLayout)
+			stat, exists := byDay[key]
+			if !exists {
+				stat = models.TrafficDailyStat{
+					ID:       uuid.New().String(),
+					ConfigID: user.ID,
+					Day:      key,
+				}
+			}
+			if traffic := days[key]; traffic != nil {
+				stat.InboundBytes = traffic.Inbound
+				stat.OutboundBytes = traffic.Outbound
+			} else {
+				stat.InboundBytes = 0
+				stat.OutboundBytes = 0
+			}
+			stat.InstanceCount = instanceCount
+			if err := db.Save(&stat).Error; err != nil {
+				return nil, err
+			}
+			byDay[key] = stat
+		}
+		todayStat = byDay[today]
+	}
+
+	stats := &MonthlyTrafficStats{InstanceCount: todayStat.InstanceCount}
+	for _, stat := range byDay {
+		stats.InboundTraffic += stat.InboundBytes
+		stats.OutboundTraffic += stat.OutboundBytes
+	}
+	return stats, nil
+}
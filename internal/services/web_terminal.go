@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// 会话创建后等待浏览器连接的时间，凭据仅在此期间保存在内存中
+	webTerminalAttachTimeout = 5 * time.Minute
+	webTerminalDialTimeout   = 20 * time.Second
+	// TerminalRecordingDir 终端录像文件目录
+	TerminalRecordingDir = "./recordings"
+)
+
+// WebTerminalOptions 创建 Web SSH 终端会话的参数
+type WebTerminalOptions struct {
+	SSHConnectOptions
+	Record bool // 是否录制会话
+}
+
+// WebTerminalSession Web SSH 终端会话，令牌仅能用于一次 WebSocket 连接
+type WebTerminalSession struct {
+	Token      string    `json:"token"`
+	InstanceID string    `json:"instanceId"`
+	Host       string    `json:"host"`
+	Port       int       `json:"port"`
+	Username   string    `json:"username"`
+	Record     bool      `json:"record"`
+	ExpireTime time.Time `json:"expireTime"` // 须在此时间前连接 WebSocket
+
+	configID string
+	target   *sshTarget
+	attached bool
+	conn     *websocket.Conn
+}
+
+type WebTerminalService struct {
+	ociService *OCIService
+	mu         sync.Mutex
+	sessions   map[string]*WebTerminalSession
+}
+
+func NewWebTerminalService(ociService *OCIService) *WebTerminalService {
+	return &WebTerminalService{
+		ociService: ociService,
+		sessions:   make(map[string]*WebTerminalSession),
+	}
+}
+
+// CreateSession 校验凭据并确定连接地址，返回连接 /ws/terminal 所需的一次性令牌
+func (s *WebTerminalService) CreateSession(userId, instanceId string, opts WebTerminalOptions) (*WebTerminalSession, error) {
+	var user models.OciUser
+	if err := database.GetDB().Where("id = ?", userId).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	s.cleanupSessions()
+
+	target, err := s.ociService.resolveSSHTarget(context.Background(), &user, instanceId, opts.SSHConnectOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &WebTerminalSession{
+		Token:      uuid.New().String(),
+		InstanceID: instanceId,
+		Host:       target.Host,
+		Port:       target.Port,
+		Username:   target.Username,
+		Record:     opts.Record,
+		ExpireTime: time.Now().Add(webTerminalAttachTimeout),
+		configID:   userId,
+		target:     target,
+	}
+	s.mu.Lock()
+	s.sessions[session.Token] = session
+	s.mu.Unlock()
+	return session, nil
+}
+
+// CloseSession 结束会话，已连接时断开 WebSocket
+func (s *WebTerminalService) CloseSession(token string) error {
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	delete(s.sessions, token)
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("会话不存在或已结束")
+	}
+	if session.conn != nil {
+		_ = session.conn.Close()
+	}
+	return nil
+}
+
+// cleanupSessions 删除超时未连接的会话
+func (s *WebTerminalService) cleanupSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, session := range s.sessions {
+		if !session.attached && time.Now().After(session.ExpireTime) {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+// Attach 将 WebSocket 桥接到实例 SSH，阻塞直到任一端断开
+// 连接成功后先发送 {"type":"hostKey"} 文本消息告知主机密钥指纹，供用户核对
+func (s *WebTerminalService) Attach(token string, conn *websocket.Conn) error {
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	if !ok || session.attached || time.Now().After(session.ExpireTime) {
+		s.mu.Unlock()
+		return fmt.Errorf("会话不存在或已过期")
+	}
+	session.attached = true
+	session.conn = conn
+	target := session.target
+	session.target = nil
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.sessions, token)
+		s.mu.Unlock()
+	}()
+
+	client, fingerprint, err := target.dial(webTerminalDialTimeout)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	hostKey, _ := json.Marshal(map[string]string{"type": "hostKey", "fingerprint": fingerprint})
+	_ = conn.WriteMessage(websocket.TextMessage, hostKey)
+
+	var recorder *terminalRecorder
+	if session.Record {
+		recording, r, err := startTerminalRecording(session)
+		if err != nil {
+			log.Printf("[WebTerminal] Failed to start recording: %v", err)
+			return fmt.Errorf("创建会话录像失败: %w", err)
+		}
+		recorder = r
+		defer finishTerminalRecording(recording, recorder)
+	}
+
+	return bridgeTerminal(conn, client, recorder)
+}
+
+// startTerminalRecording 创建录像文件与录像记录
+func startTerminalRecording(session *WebTerminalSession) (*models.TerminalRecording, *terminalRecorder, error) {
+	if err := os.MkdirAll(TerminalRecordingDir, 0700); err != nil {
+		return nil, nil, err
+	}
+	recording := &models.TerminalRecording{
+		ID:         uuid.New().String(),
+		ConfigID:   session.configID,
+		InstanceID: session.InstanceID,
+		Host:       session.Host,
+		Username:   session.Username,
+		StartTime:  time.Now(),
+	}
+	recording.FilePath = filepath.Join(TerminalRecordingDir, recording.ID+".cast")
+	recorder, err := newTerminalRecorder(recording.FilePath, session.Username+"@"+session.Host)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := database.GetDB().Create(recording).Error; err != nil {
+		recorder.Close()
+		_ = os.Remove(recording.FilePath)
+		return nil, nil, err
+	}
+	return recording, recorder, nil
+}
+
+func finishTerminalRecording(recording *models.TerminalRecording, recorder *terminalRecorder) {
+	now := time.Now()
+	recording.Size = recorder.Close()
+	recording.EndTime = &now
+	if err := database.GetDB().Save(recording).Error; err != nil {
+		log.Printf("[WebTerminal] Failed to save recording %s: %v", recording.ID, err)
+	}
+}
+
+// GetTerminalRecordings 分页获取终端录像记录，参数为空时不按该条件过滤
+func GetTerminalRecordings(configID, instanceID string, page, pageSize int) ([]models.TerminalRecording, int64, error) {
+	query := database.GetDB().Model(&models.TerminalRecording{})
+	if configID != "" {
+		query = query.Where("config_id = ?", configID)
+	}
+	if instanceID != "" {
+		query = query.Where("instance_id = ?", instanceID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var records []models.TerminalRecording
+	err := query.Order("start_time DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&records).Error
+	return records, total, err
+}
+
+// GetTerminalRecording 获取终端录像记录
+func GetTerminalRecording(id string) (*models.TerminalRecording, error) {
+	var recording models.TerminalRecording
+	if err := database.GetDB().Where("id = ?", id).First(&recording).Error; err != nil {
+		return nil, fmt.Errorf("录像不存在")
+	}
+	return &recording, nil
+}
+
+// DeleteTerminalRecording 删除终端录像记录及文件
+func DeleteTerminalRecording(id string) error {
+	recording, err := GetTerminalRecording(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(recording.FilePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return database.GetDB().Delete(recording).Error
+}
+
+// DeleteTerminalRecordings 删除配置下的所有终端录像记录及文件
+func DeleteTerminalRecordings(configIDs []string) {
+	var recordings []models.TerminalRecording
+	database.GetDB().Where("config_id IN ?", configIDs).Find(&recordings)
+	for _, recording := range recordings {
+		if err := os.Remove(recording.FilePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WebTerminal] Failed to remove recording file %s: %v", recording.FilePath, err)
+		}
+	}
+	database.GetDB().Where("config_id IN ?", configIDs).Delete(&models.TerminalRecording{})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/adiecho/oci-panel/internal/config"
 	"github.com/adiecho/oci-panel/internal/database"
+	"github.com/adiecho/oci-panel/internal/middleware"
 	"github.com/adiecho/oci-panel/internal/router"
 	"github.com/gin-gonic/gin"
 )
@@ -16,7 +17,15 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
-	r := gin.Default()
+	gin.SetMode(cfg.GinMode())
+	r := gin.New()
+	if err := cfg.ApplyProxySettings(r); err != nil {
+		log.Fatalf("Invalid proxy settings: %v", err)
+	}
+	if cfg.AccessLogEnabled() {
+		r.Use(middleware.AccessLogger(cfg.Logging.Format))
+	}
+	r.Use(gin.Recovery())
 	services := router.Setup(r, cfg)
 
 	// 启动定时任务服务
@@ -27,6 +36,17 @@ func main() {
 	services.Task.Start()
 	defer services.Task.Stop()
 
+	// 标记上次退出时中断的后台操作（克隆、重建、恢复备份等）
+	services.AsyncJobs.Start()
+
+	// 启动后台作业（数据库维护、日志清理、安全列表巡检等）
+	services.Jobs.Start()
+	defer services.Jobs.Stop()
+
+	// 启动流量告警服务
+	services.TrafficAlert.Start()
+	defer services.TrafficAlert.Stop()
+
 	// 启动 Telegram Bot（如果已配置并启用）
 	_, _, tgEnabled := services.Telegram.GetConfig()
 	if tgEnabled {
@@ -34,6 +54,9 @@ func main() {
 		defer services.Telegram.StopBot()
 	}
 
+	// 启动自检，问题写入日志并可通过 /api/sys/diagnostics 查看
+	go services.Diagnostics.RunStartupChecks()
+
 	log.Printf("Server starting on port %s", cfg.Server.Port)
 	if err := r.Run(":" + cfg.Server.Port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
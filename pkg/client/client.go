@@ -0,0 +1,167 @@
+// Package client 面板 HTTP API 的 Go 客户端，供定时脚本、外部监控等程序调用
+//
+// 请求与响应类型及大部分接口方法由 api/openapi.json 生成（zz_generated.go），新增或修改接口时
+// 先更新规范，再在本目录执行 go generate；规范中标记 x-go-manual 的接口在客户端中手写
+//
+//	c := client.New("http://localhost:8999")
+//	if err := c.Login(ctx, "admin", "password"); err != nil {
+//		log.Fatal(err)
+//	}
+//	page, err := c.ListConfigs(ctx, client.ConfigPageRequest{Page: 1, PageSize: 20})
+package client
+
+//go:generate go run ./internal/clientgen -spec ../../api/openapi.json -out zz_generated.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client 面板 API 客户端，可在多个 goroutine 中共用
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithHTTPClient 使用自定义的 http.Client，如需代理或自定义超时
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken 使用已有的登录令牌，无需再调用 Login
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New 创建客户端，baseURL 为面板地址，如 http://localhost:8999
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token 当前使用的登录令牌
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// SetToken 替换登录令牌，令牌有效期为 12 小时，过期后需重新登录
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+// ErrVerificationRequired 面板开启了 MFA 或通行密钥，账号密码登录后还需要额外验证
+var ErrVerificationRequired = errors.New("面板已开启额外验证，请使用 CheckMfaCode 完成登录")
+
+// APIError 接口返回的错误，StatusCode 为 HTTP 状态码，Code 与 Message 为响应体中的内容
+type APIError struct {
+	StatusCode int
+	Code       int
+	Message    string
+	// Data 部分接口在失败时仍返回的数据，如报表导出时已上传的对象
+	Data json.RawMessage
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("oci-panel: %d %s", e.Code, e.Message)
+}
+
+// IsUnauthorized 判断错误是否为未登录或令牌已过期
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// response 面板统一的响应格式
+type response struct {
+	Code        int             `json:"code"`
+	Message     string          `json:"message"`
+	Data        json.RawMessage `json:"data"`
+	LastUpdated string          `json:"lastUpdated,omitempty"`
+}
+
+// Do 以 JSON 调用任意接口，path 如 /api/task/list，body 为 nil 时发送空请求体
+// out 不为 nil 时将响应中的 data 解析到 out，返回响应中的提示信息
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) (string, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return "", err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if token := c.Token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var result response
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", &APIError{StatusCode: resp.StatusCode, Code: resp.StatusCode, Message: strings.TrimSpace(string(raw))}
+	}
+	if resp.StatusCode != http.StatusOK || result.Code != http.StatusOK {
+		return "", &APIError{StatusCode: resp.StatusCode, Code: result.Code, Message: result.Message, Data: result.Data}
+	}
+
+	if out != nil && len(result.Data) > 0 && string(result.Data) != "null" {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return result.Message, fmt.Errorf("解析响应失败: %w", err)
+		}
+	}
+	return result.Message, nil
+}
+
+// post 以 POST 调用接口，面板中的大部分接口都使用 POST
+func (c *Client) post(ctx context.Context, path string, body, out interface{}) error {
+	if body == nil {
+		body = struct{}{}
+	}
+	_, err := c.Do(ctx, http.MethodPost, path, body, out)
+	return err
+}
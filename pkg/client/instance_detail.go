@@ -0,0 +1,22 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetInstanceDetail 获取实例的网卡、存储卷、镜像与标签，region 为空时使用配置的主区域
+func (c *Client) GetInstanceDetail(ctx context.Context, target InstanceTarget, region string) (*InstanceDetail, error) {
+	query := url.Values{}
+	query.Set("userId", target.UserID)
+	query.Set("instanceId", target.InstanceID)
+	if region != "" {
+		query.Set("region", region)
+	}
+	var detail InstanceDetail
+	if _, err := c.Do(ctx, http.MethodGet, "/api/instance/detail?"+query.Encode(), nil, &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
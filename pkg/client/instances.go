@@ -0,0 +1,48 @@
+package client
+
+import "context"
+
+// 实例列表的附加数据
+const (
+	IncludeBootVolume = "bootVolume"
+	IncludeIPv6       = "ipv6"
+	IncludeImage      = "image"
+	IncludeTraffic    = "traffic"
+)
+
+// 批量操作
+const (
+	BatchStart     = "start"
+	BatchStop      = "stop"
+	BatchReboot    = "reboot"
+	BatchTerminate = "terminate"
+)
+
+// ListInstances 获取配置下符合条件的全部实例
+func (c *Client) ListInstances(ctx context.Context, req ListInstancesRequest) ([]Instance, error) {
+	var instances []Instance
+	if err := c.post(ctx, "/api/instance/list", req, &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// ListInstancesPage 分页获取配置下的实例，page 从 1 开始，pageSize 最大为 100
+func (c *Client) ListInstancesPage(ctx context.Context, req ListInstancesRequest, page, pageSize int) (*InstancePage, error) {
+	body := ListInstancesPageRequest{ListInstancesRequest: req, Page: page, PageSize: pageSize}
+	var result InstancePage
+	if err := c.post(ctx, "/api/instance/list", body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BatchInstanceAction 对多个实例执行启动、停止、重启或终止，单次最多 100 个实例
+func (c *Client) BatchInstanceAction(ctx context.Context, action string, targets []InstanceTarget) (*BatchInstanceReport, error) {
+	var report BatchInstanceReport
+	body := BatchInstanceRequest{Action: action, Items: targets}
+	if err := c.post(ctx, "/api/instance/batch", body, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
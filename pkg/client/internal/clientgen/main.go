@@ -0,0 +1,354 @@
+// clientgen 根据 api/openapi.json 生成 pkg/client 的请求、响应类型与接口方法
+//
+// 在 pkg/client 目录下执行 go generate，标记 x-go-manual 的接口只生成类型，方法在客户端中手写
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type schema struct {
+	Ref                  string     `json:"$ref"`
+	Type                 string     `json:"type"`
+	Format               string     `json:"format"`
+	Description          string     `json:"description"`
+	Nullable             bool       `json:"nullable"`
+	Items                *schema    `json:"items"`
+	AdditionalProperties *schema    `json:"additionalProperties"`
+	Properties           properties `json:"properties"`
+	AllOf                []*schema  `json:"allOf"`
+	GoName               string     `json:"x-go-name"`
+	OmitEmpty            bool       `json:"x-omitempty"`
+}
+
+type property struct {
+	Name   string
+	Schema *schema
+}
+
+// properties 保留字段在规范中的顺序，生成的结构体字段按该顺序排列
+type properties []property
+
+func (p *properties) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var s schema
+		if err := dec.Decode(&s); err != nil {
+			return err
+		}
+		*p = append(*p, property{Name: token.(string), Schema: &s})
+	}
+	return nil
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type operation struct {
+	OperationID string `json:"operationId"`
+	Summary     string `json:"summary"`
+	RequestBody *struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"responses"`
+	Manual bool `json:"x-go-manual"`
+}
+
+type document struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// initialisms 字段名中按 Go 习惯全部大写的缩写
+var initialisms = map[string]string{
+	"ad": "AD", "id": "ID", "ids": "IDs", "ip": "IP", "ips": "IPs", "ipv6": "IPv6", "ipv6s": "IPv6s",
+	"mfa": "MFA", "ssh": "SSH", "url": "URL",
+}
+
+// goName 将 JSON 字段名转换为导出的 Go 字段名，如 reservedPublicIp -> ReservedPublicIP
+func goName(name string) string {
+	var words []string
+	start := 0
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	words = append(words, name[start:])
+
+	var b strings.Builder
+	for _, word := range words {
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(initialism)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+// goType 返回字段的 Go 类型，nullable 的字段使用指针
+func goType(s *schema) (string, error) {
+	if len(s.AllOf) == 1 && s.AllOf[0].Ref != "" {
+		t := refName(s.AllOf[0].Ref)
+		if s.Nullable {
+			t = "*" + t
+		}
+		return t, nil
+	}
+	if s.Ref != "" {
+		return refName(s.Ref), nil
+	}
+
+	var t string
+	switch s.Type {
+	case "string":
+		t = "string"
+	case "boolean":
+		t = "bool"
+	case "integer":
+		t = "int"
+		if s.Format == "int64" {
+			t = "int64"
+		}
+	case "number":
+		t = "float64"
+		if s.Format == "float" {
+			t = "float32"
+		}
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array 缺少 items")
+		}
+		item, err := goType(s.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object", "":
+		if s.AdditionalProperties == nil {
+			if s.Type == "" || len(s.Properties) == 0 {
+				return "interface{}", nil
+			}
+			return "", fmt.Errorf("内联对象需定义为 components.schemas")
+		}
+		value, err := goType(s.AdditionalProperties)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + value, nil
+	default:
+		return "", fmt.Errorf("不支持的类型 %q", s.Type)
+	}
+	if s.Nullable {
+		t = "*" + t
+	}
+	return t, nil
+}
+
+// responseData 从 allOf [Response, {data}] 形式的响应中取出 data 的结构
+func responseData(s *schema) *schema {
+	for _, part := range s.AllOf {
+		for _, prop := range part.Properties {
+			if prop.Name == "data" {
+				return prop.Schema
+			}
+		}
+	}
+	return nil
+}
+
+func writeComment(b *bytes.Buffer, indent, name, description string) {
+	for i, line := range strings.Split(strings.TrimSpace(description), "\n") {
+		if i == 0 {
+			line = name + " " + line
+		}
+		fmt.Fprintf(b, "%s// %s\n", indent, line)
+	}
+}
+
+func writeSchemas(b *bytes.Buffer, doc *document) error {
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		// Response 为统一的响应格式，由客户端解析
+		if name != "Response" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := doc.Components.Schemas[name]
+		// allOf 中引用的结构嵌入到生成的结构体，内联对象的字段直接展开
+		props := s.Properties
+		var embedded []string
+		for _, part := range s.AllOf {
+			if part.Ref != "" {
+				embedded = append(embedded, refName(part.Ref))
+			} else {
+				props = append(props, part.Properties...)
+			}
+		}
+		if s.Type != "object" && len(s.AllOf) == 0 {
+			return fmt.Errorf("%s: 只支持 object 类型", name)
+		}
+		if s.Description != "" {
+			writeComment(b, "", name, s.Description)
+		}
+		fmt.Fprintf(b, "type %s struct {\n", name)
+		for _, t := range embedded {
+			fmt.Fprintf(b, "\t%s\n", t)
+		}
+		for _, prop := range props {
+			t, err := goType(prop.Schema)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", name, prop.Name, err)
+			}
+			field := prop.Schema.GoName
+			if field == "" {
+				field = goName(prop.Name)
+			}
+			tag := prop.Name
+			if prop.Schema.OmitEmpty {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(b, "\t%s %s `json:%q`", field, t, tag)
+			if prop.Schema.Description != "" {
+				fmt.Fprintf(b, " // %s", prop.Schema.Description)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n\n")
+	}
+	return nil
+}
+
+func writeOperations(b *bytes.Buffer, doc *document) error {
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		methods := make([]string, 0, len(doc.Paths[path]))
+		for method := range doc.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			op := doc.Paths[path][method]
+			if op.Manual {
+				continue
+			}
+			if method != "post" {
+				return fmt.Errorf("%s %s: 只能生成 POST 接口，其他接口需标记 x-go-manual", method, path)
+			}
+
+			params, body := "", "nil"
+			if op.RequestBody != nil {
+				req := op.RequestBody.Content["application/json"].Schema
+				if req == nil || req.Ref == "" {
+					return fmt.Errorf("%s: 请求体需引用 components.schemas", path)
+				}
+				params, body = ", req "+refName(req.Ref), "req"
+			}
+
+			var data *schema
+			if resp := op.Responses["200"].Content["application/json"].Schema; resp != nil {
+				data = responseData(resp)
+			}
+
+			if op.Summary != "" {
+				writeComment(b, "", op.OperationID, op.Summary)
+			}
+			switch {
+			case data == nil:
+				fmt.Fprintf(b, "func (c *Client) %s(ctx context.Context%s) error {\n", op.OperationID, params)
+				fmt.Fprintf(b, "\treturn c.post(ctx, %q, %s, nil)\n}\n\n", path, body)
+			case data.Type == "array":
+				t, err := goType(data)
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				fmt.Fprintf(b, "func (c *Client) %s(ctx context.Context%s) (%s, error) {\n", op.OperationID, params, t)
+				fmt.Fprintf(b, "\tvar resp %s\n", t)
+				fmt.Fprintf(b, "\tif err := c.post(ctx, %q, %s, &resp); err != nil {\n\t\treturn nil, err\n\t}\n", path, body)
+				b.WriteString("\treturn resp, nil\n}\n\n")
+			case data.Ref != "":
+				t := refName(data.Ref)
+				fmt.Fprintf(b, "func (c *Client) %s(ctx context.Context%s) (*%s, error) {\n", op.OperationID, params, t)
+				fmt.Fprintf(b, "\tvar resp %s\n", t)
+				fmt.Fprintf(b, "\tif err := c.post(ctx, %q, %s, &resp); err != nil {\n\t\treturn nil, err\n\t}\n", path, body)
+				b.WriteString("\treturn &resp, nil\n}\n\n")
+			default:
+				return fmt.Errorf("%s: 响应数据需引用 components.schemas 或为数组", path)
+			}
+		}
+	}
+	return nil
+}
+
+// generate 解析规范并返回格式化后的 Go 代码
+func generate(spec []byte) ([]byte, error) {
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("解析规范失败: %w", err)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by clientgen from api/openapi.json. DO NOT EDIT.\n\n")
+	b.WriteString("package client\n\nimport \"context\"\n\n")
+	if err := writeSchemas(&b, &doc); err != nil {
+		return nil, err
+	}
+	if err := writeOperations(&b, &doc); err != nil {
+		return nil, err
+	}
+	return format.Source(b.Bytes())
+}
+
+func main() {
+	specPath := flag.String("spec", "../../api/openapi.json", "OpenAPI 规范文件")
+	outPath := flag.String("out", "zz_generated.go", "输出文件")
+	flag.Parse()
+
+	spec, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	code, err := generate(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*outPath, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
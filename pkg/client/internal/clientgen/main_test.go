@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestGeneratedUpToDate 修改规范后需执行 go generate 更新 zz_generated.go
+func TestGeneratedUpToDate(t *testing.T) {
+	spec, err := os.ReadFile("../../../../api/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := generate(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../../zz_generated.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("pkg/client/zz_generated.go 与 api/openapi.json 不一致，请在 pkg/client 目录执行 go generate")
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"id", "ID"},
+		{"userId", "UserID"},
+		{"reservedPublicIp", "ReservedPublicIP"},
+		{"privateIps", "PrivateIPs"},
+		{"nsgIds", "NsgIDs"},
+		{"ipv6Addresses", "IPv6Addresses"},
+		{"rotateAd", "RotateAD"},
+		{"needMfa", "NeedMFA"},
+		{"webhookUrl", "WebhookURL"},
+		{"sizeInGBs", "SizeInGBs"},
+		{"pageSize", "PageSize"},
+	}
+	for _, tt := range tests {
+		if got := goName(tt.name); got != tt.want {
+			t.Errorf("goName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
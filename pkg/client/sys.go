@@ -0,0 +1,26 @@
+package client
+
+import "context"
+
+// Login 使用账号密码登录并保存令牌，面板开启了额外验证时返回 ErrVerificationRequired
+func (c *Client) Login(ctx context.Context, account, password string) error {
+	var resp LoginResponse
+	if err := c.post(ctx, "/api/sys/login", LoginRequest{Account: account, Password: password}, &resp); err != nil {
+		return err
+	}
+	if resp.Token == "" {
+		return ErrVerificationRequired
+	}
+	c.SetToken(resp.Token)
+	return nil
+}
+
+// CheckMfaCode 使用 MFA 验证码完成登录并保存令牌
+func (c *Client) CheckMfaCode(ctx context.Context, code string) error {
+	var resp LoginResponse
+	if err := c.post(ctx, "/api/sys/checkMfaCode", CheckMfaCodeRequest{Code: code}, &resp); err != nil {
+		return err
+	}
+	c.SetToken(resp.Token)
+	return nil
+}
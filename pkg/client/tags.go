@@ -0,0 +1,23 @@
+package client
+
+import "context"
+
+// 可管理标签的资源类型
+const (
+	TagResourceInstance   = "instance"
+	TagResourceBootVolume = "bootVolume"
+	TagResourceVolume     = "volume"
+)
+
+// UpdateResourceTags 更新实例或卷的标签，返回更新后的全部标签
+func (c *Client) UpdateResourceTags(ctx context.Context, target ResourceTarget, tags ResourceTags) (*ResourceTags, error) {
+	body := struct {
+		ResourceTarget
+		ResourceTags
+	}{ResourceTarget: target, ResourceTags: tags}
+	var updated ResourceTags
+	if err := c.post(ctx, "/api/instance/updateTags", body, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
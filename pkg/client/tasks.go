@@ -0,0 +1,13 @@
+package client
+
+import "context"
+
+// StartTask 启动开机任务
+func (c *Client) StartTask(ctx context.Context, taskID string) error {
+	return c.post(ctx, "/api/task/start", TaskTarget{TaskID: taskID}, nil)
+}
+
+// StopTask 停止开机任务
+func (c *Client) StopTask(ctx context.Context, taskID string) error {
+	return c.post(ctx, "/api/task/stop", TaskTarget{TaskID: taskID}, nil)
+}
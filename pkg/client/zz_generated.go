@@ -0,0 +1,347 @@
+// Code generated by clientgen from api/openapi.json. DO NOT EDIT.
+
+package client
+
+import "context"
+
+// BatchInstanceReport 批量操作结果，Results 与请求中实例的顺序一致
+type BatchInstanceReport struct {
+	Action    string                `json:"action"`
+	Total     int                   `json:"total"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []BatchInstanceResult `json:"results"`
+}
+
+// BatchInstanceRequest 批量操作实例，单次最多 100 个实例
+type BatchInstanceRequest struct {
+	Action string           `json:"action"` // start / stop / reboot / terminate
+	Items  []InstanceTarget `json:"items"`
+}
+
+// BatchInstanceResult 批量操作中单个实例的结果
+type BatchInstanceResult struct {
+	UserID     string `json:"userId"`
+	InstanceID string `json:"instanceId"`
+	Status     string `json:"status"` // success, failed
+	Error      string `json:"error,omitempty"`
+}
+
+// CheckMfaCodeRequest MFA 验证码
+type CheckMfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// Config 配置列表中的一项
+type Config struct {
+	ID               string `json:"id"`
+	Username         string `json:"username"`
+	TenantName       string `json:"tenantName"`
+	TenantCreateTime string `json:"tenantCreateTime"`
+	OciTenantID      string `json:"ociTenantId"`
+	OciRegion        string `json:"ociRegion"`
+	CreateTime       string `json:"createTime"`
+	InstanceCount    int    `json:"instanceCount"`
+	RunningInstances int    `json:"runningInstances"`
+	LastUpdated      string `json:"lastUpdated"` // 实例数量的更新时间，从未同步时为空
+	Stale            bool   `json:"stale"`       // 缓存超过过期阈值未更新
+	Favorite         bool   `json:"favorite"`    // 已收藏，收藏的配置排在最前
+}
+
+// ConfigPage 配置分页结果
+type ConfigPage struct {
+	List     []Config `json:"list"`
+	Total    int64    `json:"total"`
+	Page     int      `json:"page"`
+	PageSize int      `json:"pageSize"`
+}
+
+// ConfigPageRequest 分页查询配置，PageSize 最大为 100
+type ConfigPageRequest struct {
+	Page     int    `json:"page"`
+	PageSize int    `json:"pageSize"`
+	Username string `json:"username,omitempty"` // 按配置名模糊匹配
+}
+
+// Glance 面板概览
+type Glance struct {
+	TotalConfigs      int64         `json:"totalConfigs"`
+	TotalTasks        int64         `json:"totalTasks"`
+	CacheStaleMinutes int           `json:"cacheStaleMinutes"`
+	StaleConfigs      []StaleConfig `json:"staleConfigs"`
+}
+
+// Instance 实例信息，附加数据仅在请求 Include 对应项时返回
+type Instance struct {
+	ID                 string                            `json:"id"`
+	DisplayName        string                            `json:"displayName"`
+	State              string                            `json:"state"`
+	AvailabilityDomain string                            `json:"availabilityDomain"`
+	Shape              string                            `json:"shape"`
+	TimeCreated        string                            `json:"timeCreated"`
+	PublicIP           string                            `json:"publicIp"`
+	PrivateIP          string                            `json:"privateIp"`
+	Favorite           bool                              `json:"favorite"` // 已收藏，收藏的实例排在最前
+	FreeformTags       map[string]string                 `json:"freeformTags,omitempty"`
+	DefinedTags        map[string]map[string]interface{} `json:"definedTags,omitempty"`
+	BootVolumeSizeGB   *int64                            `json:"bootVolumeSizeGb,omitempty"`
+	IPv6Addresses      []string                          `json:"ipv6Addresses,omitempty"`
+	ImageName          string                            `json:"imageName,omitempty"`
+	Traffic            *InstanceTraffic                  `json:"traffic,omitempty"`
+	EnrichErrors       map[string]string                 `json:"enrichErrors,omitempty"`
+}
+
+// InstanceDetail 实例的完整信息
+type InstanceDetail struct {
+	ID                 string                            `json:"id"`
+	DisplayName        string                            `json:"displayName"`
+	State              string                            `json:"state"`
+	Shape              string                            `json:"shape"`
+	Ocpus              float32                           `json:"ocpus"`
+	Memory             float32                           `json:"memory"`
+	Region             string                            `json:"region"`
+	CompartmentID      string                            `json:"compartmentId"`
+	AvailabilityDomain string                            `json:"availabilityDomain"`
+	FaultDomain        string                            `json:"faultDomain"`
+	TimeCreated        string                            `json:"timeCreated"`
+	ImageID            string                            `json:"imageId"`
+	ImageName          string                            `json:"imageName"`
+	OperatingSystem    string                            `json:"operatingSystem"`
+	Protected          bool                              `json:"protected"`
+	SSHPort            int                               `json:"sshPort"`
+	Vnics              []InstanceVnic                    `json:"vnics"`
+	BootVolume         *InstanceVolume                   `json:"bootVolume"`
+	BlockVolumes       []InstanceVolume                  `json:"blockVolumes"`
+	FreeformTags       map[string]string                 `json:"freeformTags"`
+	DefinedTags        map[string]map[string]interface{} `json:"definedTags"`
+	Warnings           []string                          `json:"warnings,omitempty"` // 获取失败的部分
+}
+
+// InstanceIP 私有 IP 及其绑定的公网 IP
+type InstanceIP struct {
+	PrivateIP      string `json:"privateIp"`
+	IsPrimary      bool   `json:"isPrimary"`
+	PublicIP       string `json:"publicIp"`
+	PublicLifetime string `json:"publicLifetime"` // EPHEMERAL / RESERVED
+}
+
+// InstancePage 实例分页结果
+type InstancePage struct {
+	List     []Instance `json:"list"`
+	Total    int        `json:"total"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"pageSize"`
+}
+
+// InstanceTarget 指定配置下的一个实例
+type InstanceTarget struct {
+	UserID     string `json:"userId"`
+	InstanceID string `json:"instanceId"`
+}
+
+// InstanceTraffic 实例本月流量
+type InstanceTraffic struct {
+	InboundBytes  int64 `json:"inboundBytes"`
+	OutboundBytes int64 `json:"outboundBytes"`
+}
+
+// InstanceVnic 实例网卡
+type InstanceVnic struct {
+	VnicID        string       `json:"vnicId"`
+	Name          string       `json:"name"`
+	IsPrimary     bool         `json:"isPrimary"`
+	SubnetID      string       `json:"subnetId"`
+	MacAddress    string       `json:"macAddress"`
+	HostnameLabel string       `json:"hostnameLabel"`
+	PrivateIPs    []InstanceIP `json:"privateIps"`
+	IPv6s         []string     `json:"ipv6s"`
+	NsgIDs        []string     `json:"nsgIds"`
+}
+
+// InstanceVolume 引导卷或块存储卷
+type InstanceVolume struct {
+	ID             string `json:"id"`
+	DisplayName    string `json:"displayName"`
+	SizeInGBs      int64  `json:"sizeInGBs"`
+	VpusPerGB      int64  `json:"vpusPerGB"`
+	State          string `json:"state"`
+	AttachmentID   string `json:"attachmentId"`
+	AttachmentType string `json:"attachmentType"`
+	Device         string `json:"device"`
+	ReadOnly       bool   `json:"readOnly"`
+}
+
+// ListInstancesPageRequest 分页查询实例列表，page 从 1 开始，pageSize 最大为 100
+type ListInstancesPageRequest struct {
+	ListInstancesRequest
+	Page     int `json:"page"`
+	PageSize int `json:"pageSize"`
+}
+
+// ListInstancesRequest 查询实例列表，CompartmentID 为根区间时可传租户 OCID
+type ListInstancesRequest struct {
+	UserID        string   `json:"userId"`
+	CompartmentID string   `json:"compartmentId"`
+	Include       []string `json:"include,omitempty"`   // 附加数据，见 Include* 常量
+	State         string   `json:"state,omitempty"`     // 生命周期状态，如 RUNNING
+	Shape         string   `json:"shape,omitempty"`     // 规格，如 VM.Standard.A1.Flex
+	Region        string   `json:"region,omitempty"`    // 为空时使用配置的主区域
+	Name          string   `json:"name,omitempty"`      // 名称包含的子串
+	Tag           string   `json:"tag,omitempty"`       // 标签，key 或 key=value，定义标签可写为 namespace.key
+	SortBy        string   `json:"sortBy,omitempty"`    // name / state / shape / timeCreated
+	SortOrder     string   `json:"sortOrder,omitempty"` // asc / desc
+	Refresh       bool     `json:"refresh,omitempty"`   // 跳过面板的实例列表缓存
+}
+
+// LoginRequest 账号密码登录
+type LoginRequest struct {
+	Account  string `json:"account"`
+	Password string `json:"password"`
+}
+
+// LoginResponse 登录结果，NeedMFA 或 NeedPasskey 为 true 时 Token 为空
+type LoginResponse struct {
+	Token          string `json:"token"`
+	Username       string `json:"username"`
+	NeedMFA        bool   `json:"needMfa"`
+	NeedPasskey    bool   `json:"needPasskey"`
+	PasskeyEnabled bool   `json:"passkeyEnabled"`
+}
+
+// ResourceTags 资源的自由格式标签与定义标签，更新时为 nil 的一项保持不变
+type ResourceTags struct {
+	FreeformTags map[string]string                 `json:"freeformTags"`
+	DefinedTags  map[string]map[string]interface{} `json:"definedTags"`
+}
+
+// ResourceTarget 指定配置下的一个实例或卷，ResourceType 为空时为实例
+type ResourceTarget struct {
+	UserID       string `json:"userId"`
+	ResourceID   string `json:"resourceId"`
+	ResourceType string `json:"resourceType,omitempty"` // instance / bootVolume / volume
+	Region       string `json:"region,omitempty"`
+}
+
+// StaleConfig 缓存已过期的配置
+type StaleConfig struct {
+	ConfigID    string `json:"configId"`
+	Username    string `json:"username"`
+	LastUpdated string `json:"lastUpdated"` // 从未同步时为空
+	AgeMinutes  int    `json:"ageMinutes"`  // 从未同步时为 -1
+}
+
+// Task 开机任务
+type Task struct {
+	ID               string  `json:"id"`
+	UserID           string  `json:"userId"`
+	Username         string  `json:"username"`
+	OciRegion        string  `json:"ociRegion"`
+	Ocpus            float64 `json:"ocpus"`
+	Memory           float64 `json:"memory"`
+	Disk             int     `json:"disk"`
+	Architecture     string  `json:"architecture"`
+	Interval         int     `json:"interval"`
+	BackoffMin       int     `json:"backoffMin"`
+	BackoffMax       int     `json:"backoffMax"`
+	CurrentBackoff   int     `json:"currentBackoff"`
+	RotateAD         bool    `json:"rotateAd"`
+	FallbackRegions  string  `json:"fallbackRegions"`
+	CurrentRegion    string  `json:"currentRegion"`
+	OperationSystem  string  `json:"operationSystem"`
+	Status           string  `json:"status"`
+	ExecuteCount     int     `json:"executeCount"`
+	SuccessCount     int     `json:"successCount"`
+	CreateNumbers    int     `json:"createNumbers"`
+	LogRetentionDays int     `json:"logRetentionDays"`
+	GroupName        string  `json:"groupName"`
+	Priority         int     `json:"priority"`
+	ExecuteWindows   string  `json:"executeWindows"`
+	WebhookURL       string  `json:"webhookUrl"`
+	PostActionID     string  `json:"postActionId"`
+	UserData         string  `json:"userData"`
+	ReservedPublicIP string  `json:"reservedPublicIp"`
+	ProbeOnly        bool    `json:"probeOnly"`
+	MaxExecuteCount  int     `json:"maxExecuteCount"`
+	ExpireAt         string  `json:"expireAt"`
+	LastExecuteTime  string  `json:"lastExecuteTime"`
+	NextExecuteTime  string  `json:"nextExecuteTime"`
+	LastMessage      string  `json:"lastMessage"`
+	CreateTime       string  `json:"createTime"`
+}
+
+// TaskPage 任务分页结果
+type TaskPage struct {
+	List     []Task `json:"list"`
+	Total    int64  `json:"total"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"pageSize"`
+}
+
+// TaskPageRequest 分页查询开机任务，PageSize 最大为 100
+type TaskPageRequest struct {
+	Page      int    `json:"page"`
+	PageSize  int    `json:"pageSize"`
+	Status    string `json:"status,omitempty"` // running, stopped, completed, error, expired
+	GroupName string `json:"groupName,omitempty"`
+}
+
+// TaskTarget 指定一个开机任务
+type TaskTarget struct {
+	TaskID string `json:"taskId"`
+}
+
+// RebootInstance 重启实例
+func (c *Client) RebootInstance(ctx context.Context, req InstanceTarget) error {
+	return c.post(ctx, "/api/instance/reboot", req, nil)
+}
+
+// StartInstance 启动实例
+func (c *Client) StartInstance(ctx context.Context, req InstanceTarget) error {
+	return c.post(ctx, "/api/instance/start", req, nil)
+}
+
+// StopInstance 停止实例
+func (c *Client) StopInstance(ctx context.Context, req InstanceTarget) error {
+	return c.post(ctx, "/api/instance/stop", req, nil)
+}
+
+// GetResourceTags 获取实例或卷的标签
+func (c *Client) GetResourceTags(ctx context.Context, req ResourceTarget) (*ResourceTags, error) {
+	var resp ResourceTags
+	if err := c.post(ctx, "/api/instance/tags", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TerminateInstance 终止实例，开启了终止保护的实例会返回错误
+func (c *Client) TerminateInstance(ctx context.Context, req InstanceTarget) error {
+	return c.post(ctx, "/api/instance/terminate", req, nil)
+}
+
+// ListConfigs 分页获取配置列表
+func (c *Client) ListConfigs(ctx context.Context, req ConfigPageRequest) (*ConfigPage, error) {
+	var resp ConfigPage
+	if err := c.post(ctx, "/api/oci/userPage", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetGlance 获取面板概览，可用于外部监控检查面板是否可用
+func (c *Client) GetGlance(ctx context.Context) (*Glance, error) {
+	var resp Glance
+	if err := c.post(ctx, "/api/sys/getGlance", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListTasks 分页获取开机任务
+func (c *Client) ListTasks(ctx context.Context, req TaskPageRequest) (*TaskPage, error) {
+	var resp TaskPage
+	if err := c.post(ctx, "/api/task/list", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}